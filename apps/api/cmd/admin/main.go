@@ -0,0 +1,396 @@
+// bulktrack-admin は運用者向けの管理コマンド
+// サーバーと同じ設定 (DATABASE_URL など) とサービスを使い、データベースを直接操作する
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"flag"
+	"fmt"
+	"io"
+	"log/slog"
+	"os"
+	"os/signal"
+	"sort"
+	"syscall"
+	"text/tabwriter"
+	_ "time/tzdata" // JST の週の計算用 (タイムゾーンデータのないコンテナイメージでも動くよう埋め込む)
+
+	"github.com/aiirononeko/bulktrack/apps/api/internal/cache"
+	"github.com/aiirononeko/bulktrack/apps/api/internal/config"
+	"github.com/aiirononeko/bulktrack/apps/api/internal/db"
+	"github.com/aiirononeko/bulktrack/apps/api/internal/interfaces/service"
+	"github.com/aiirononeko/bulktrack/apps/api/internal/logging"
+	"github.com/jackc/pgx/v5/pgxpool"
+)
+
+const usage = `usage: bulktrack-admin <command> [flags]
+
+commands:
+  volumes backfill --from DATE --to DATE [--user ID] [--batch-size N] [--dry-run]
+                     期間内の週間ボリュームを再計算する (DATE は YYYY-MM-DD、JST の週単位)
+  volumes reconcile [--user ID] [--dry-run]
+                     週間ボリュームをセットから集計し直した値と照合し、差分を修復する
+                     (--dry-run で差分がある場合は終了コード 2)
+  check [--user ID]  データの整合性を確認する (問題がある場合は終了コード 2)
+  seed [--dry-run]   組み込み種目・部位・計測項目・テンプレートメニューの初期データを適用する
+  user show ID       ユーザーのデータの件数を表示する
+  user export ID [--from DATE] [--to DATE] [-o FILE]
+                     ユーザーのデータを JSON でエクスポートする (既定は標準出力)
+  user import ID -i FILE [--dry-run]
+                     JSON のエクスポートをユーザーのデータとして取り込む
+  deletions purge [--dry-run]
+                     削除期限を過ぎたアカウントのデータを削除する
+
+--dry-run の場合は変更せずに対象の件数や結果のみ表示する
+結果は標準出力、ログと進捗は標準エラー出力に出力する`
+
+// errIssuesFound は check や volumes reconcile --dry-run で問題が見つかったことを表す (終了コード 2)
+var errIssuesFound = errors.New("integrity issues found")
+
+func main() {
+	cfg := config.NewConfig()
+
+	// ログは結果と混ざらないよう標準エラー出力に出力する
+	logLevels, err := logging.ParseLevels(cfg.LogLevel, cfg.LogLevels)
+	if err != nil {
+		logLevels = &logging.Levels{Default: slog.LevelInfo}
+	}
+	logger := slog.New(logging.NewHandler(slog.NewTextHandler(os.Stderr, &slog.HandlerOptions{
+		Level: logLevels.Minimum(),
+	}), logLevels, logging.NewRedactPolicy(cfg.LogRedactKeys, cfg.LogMaxValueBytes)))
+	slog.SetDefault(logger)
+
+	if len(os.Args) < 2 || os.Args[1] == "-h" || os.Args[1] == "--help" || os.Args[1] == "help" {
+		fmt.Fprintln(os.Stderr, usage)
+		os.Exit(2)
+	}
+
+	ctx, stop := signal.NotifyContext(context.Background(), syscall.SIGINT, syscall.SIGTERM)
+	defer stop()
+
+	pool, err := db.New()
+	if err != nil {
+		logger.Error("Failed to connect to database", slog.Any("error", err))
+		os.Exit(1)
+	}
+	defer pool.Close()
+
+	// 週間ボリュームの再計算・修復と種目のシード・取り込みでサーバーの共有のキャッシュを無効にする
+	// (メモリ上のキャッシュはサーバーのプロセスごとのため、変更は各サーバーで TTL の経過後に反映される)
+	if cfg.CacheStore == "redis" && cfg.CacheRedisURL != "" {
+		cacheStore, err := cache.NewRedisStore(cfg.CacheRedisURL)
+		if err != nil {
+			logger.Error("Failed to set up cache", slog.Any("error", err))
+			os.Exit(1)
+		}
+		defer cacheStore.Close()
+		service.SetCache(cache.New(cacheStore, logger))
+	}
+
+	err = run(ctx, pool, os.Args[1:], os.Stdout, logger)
+	switch {
+	case errors.Is(err, errIssuesFound):
+		stop()
+		pool.Close()
+		os.Exit(2)
+	case err != nil:
+		logger.Error("Command failed", slog.Any("error", err))
+		stop()
+		pool.Close()
+		os.Exit(1)
+	}
+}
+
+// run はサブコマンドを実行する
+func run(ctx context.Context, pool *pgxpool.Pool, args []string, out io.Writer, logger *slog.Logger) error {
+	command := args[0]
+	if len(args) > 1 && (command == "volumes" || command == "user" || command == "deletions") {
+		command += " " + args[1]
+		args = args[1:]
+	}
+	args = args[1:]
+
+	switch command {
+	case "volumes backfill":
+		return runVolumesBackfill(ctx, service.NewAdminService(pool, logger), args, out)
+	case "volumes reconcile":
+		return runVolumesReconcile(ctx, service.NewVolumeReconcileService(pool, logger), args, out)
+	case "check":
+		return runCheck(ctx, service.NewAdminService(pool, logger), args, out)
+	case "seed":
+		return runSeed(ctx, service.NewAdminService(pool, logger), args, out)
+	case "user show":
+		return runUserShow(ctx, service.NewAdminService(pool, logger), args, out)
+	case "user export":
+		return runUserExport(ctx, service.NewExportService(pool, logger), args, out)
+	case "user import":
+		return runUserImport(ctx, service.NewAdminService(pool, logger), args, out)
+	case "deletions purge":
+		return runDeletionsPurge(ctx, service.NewAccountDeletionService(pool, logger), args, out)
+	default:
+		return fmt.Errorf("unknown command %q\n%s", command, usage)
+	}
+}
+
+// newFlagSet はサブコマンドのフラグを作成する (エラーは run の呼び出し元に返す)
+func newFlagSet(name string) *flag.FlagSet {
+	fs := flag.NewFlagSet(name, flag.ContinueOnError)
+	fs.SetOutput(os.Stderr)
+	return fs
+}
+
+// parseArgs はフラグと位置引数を解析する (位置引数はフラグの前後どちらに置いてもよい)
+func parseArgs(fs *flag.FlagSet, args []string, positional int) ([]string, error) {
+	var rest []string
+	for {
+		if err := fs.Parse(args); err != nil {
+			return nil, err
+		}
+		if fs.NArg() == 0 {
+			break
+		}
+		rest = append(rest, fs.Arg(0))
+		args = fs.Args()[1:]
+	}
+	if len(rest) != positional {
+		return nil, fmt.Errorf("%s: expected %d argument(s), got %d\n%s", fs.Name(), positional, len(rest), usage)
+	}
+	return rest, nil
+}
+
+func runVolumesBackfill(ctx context.Context, admin *service.AdminService, args []string, out io.Writer) error {
+	fs := newFlagSet("volumes backfill")
+	var opts service.VolumeBackfillOptions
+	fs.StringVar(&opts.From, "from", "", "開始日 (YYYY-MM-DD)")
+	fs.StringVar(&opts.To, "to", "", "終了日 (YYYY-MM-DD)")
+	fs.StringVar(&opts.UserID, "user", "", "対象のユーザーID (省略時は全ユーザー)")
+	fs.IntVar(&opts.BatchSize, "batch-size", service.DefaultVolumeBackfillBatchSize, "1つのトランザクションで再計算するユーザー週の数")
+	fs.BoolVar(&opts.DryRun, "dry-run", false, "対象の件数のみ表示する")
+	if _, err := parseArgs(fs, args, 0); err != nil {
+		return err
+	}
+	if opts.From == "" || opts.To == "" {
+		return fmt.Errorf("volumes backfill: --from and --to are required\n%s", usage)
+	}
+
+	result, err := admin.BackfillWeeklyVolumes(ctx, opts, func(p service.VolumeBackfillProgress) {
+		fmt.Fprintf(os.Stderr, "recalculated %d/%d week(s)\n", p.Done, p.Total)
+	})
+	if err != nil {
+		return err
+	}
+	if opts.DryRun {
+		fmt.Fprintf(out, "would recalculate %d week(s)\n", result.Total)
+		return nil
+	}
+	fmt.Fprintf(out, "recalculated %d week(s)\n", result.Done)
+	return nil
+}
+
+func runVolumesReconcile(ctx context.Context, reconciler *service.VolumeReconcileService, args []string, out io.Writer) error {
+	fs := newFlagSet("volumes reconcile")
+	userID := fs.String("user", "", "対象のユーザーID (省略時は全ユーザー)")
+	dryRun := fs.Bool("dry-run", false, "差分を表示するのみで修復しない")
+	if _, err := parseArgs(fs, args, 0); err != nil {
+		return err
+	}
+
+	w := tabwriter.NewWriter(out, 0, 0, 2, ' ', 0)
+	fmt.Fprintln(w, "USER\tWEEK\tSTORED VOLUME\tFRESH VOLUME\tSTORED 1RM\tFRESH 1RM\tSTORED SETS\tFRESH SETS")
+	printDrifts := func(drifts []service.WeeklyVolumeDrift) {
+		for _, d := range drifts {
+			fmt.Fprintf(w, "%s\t%s\t%.2f\t%.2f\t%.2f\t%.2f\t%d\t%d\n", d.UserID, d.WeekStartDate,
+				d.Stored.TotalVolume, d.Fresh.TotalVolume, d.Stored.EstOneRM, d.Fresh.EstOneRM, d.Stored.SetCount, d.Fresh.SetCount)
+		}
+	}
+
+	var summary service.VolumeReconcileSummary
+	if *userID != "" {
+		drifts, err := reconciler.ReconcileUser(ctx, *userID, !*dryRun)
+		if err != nil {
+			return err
+		}
+		printDrifts(drifts)
+		summary = service.VolumeReconcileSummary{CheckedUsers: 1, DriftedWeeks: int64(len(drifts))}
+		if !*dryRun {
+			summary.RepairedWeeks = summary.DriftedWeeks
+		}
+	} else {
+		var err error
+		summary, err = reconciler.ReconcileAll(ctx, !*dryRun, func(drifts []service.WeeklyVolumeDrift) {
+			printDrifts(drifts)
+			fmt.Fprintf(os.Stderr, "found %d drifted week(s) of %s\n", len(drifts), drifts[0].UserID)
+		})
+		if err != nil {
+			return err
+		}
+	}
+	if err := w.Flush(); err != nil {
+		return err
+	}
+
+	fmt.Fprintf(out, "checked %d user(s), %d drifted week(s), %d repaired\n", summary.CheckedUsers, summary.DriftedWeeks, summary.RepairedWeeks)
+	if *dryRun && summary.DriftedWeeks > 0 {
+		return errIssuesFound
+	}
+	return nil
+}
+
+func runCheck(ctx context.Context, admin *service.AdminService, args []string, out io.Writer) error {
+	fs := newFlagSet("check")
+	userID := fs.String("user", "", "対象のユーザーID (省略時は全ユーザー)")
+	if _, err := parseArgs(fs, args, 0); err != nil {
+		return err
+	}
+
+	checks, err := admin.CheckIntegrity(ctx, *userID)
+	if err != nil {
+		return err
+	}
+	w := tabwriter.NewWriter(out, 0, 0, 2, ' ', 0)
+	fmt.Fprintln(w, "CHECK\tISSUES\tDESCRIPTION")
+	issues := int64(0)
+	for _, check := range checks {
+		fmt.Fprintf(w, "%s\t%d\t%s\n", check.Name, check.IssueCount, check.Description)
+		issues += check.IssueCount
+	}
+	if err := w.Flush(); err != nil {
+		return err
+	}
+	if issues > 0 {
+		return errIssuesFound
+	}
+	return nil
+}
+
+func runSeed(ctx context.Context, admin *service.AdminService, args []string, out io.Writer) error {
+	fs := newFlagSet("seed")
+	dryRun := fs.Bool("dry-run", false, "追加される行数のみ表示する")
+	if _, err := parseArgs(fs, args, 0); err != nil {
+		return err
+	}
+
+	added, err := admin.Seed(ctx, *dryRun)
+	if err != nil {
+		return err
+	}
+	tables := make([]string, 0, len(added))
+	for table := range added {
+		tables = append(tables, table)
+	}
+	sort.Strings(tables)
+	w := tabwriter.NewWriter(out, 0, 0, 2, ' ', 0)
+	header := "TABLE\tADDED"
+	if *dryRun {
+		header = "TABLE\tWOULD ADD"
+	}
+	fmt.Fprintln(w, header)
+	for _, table := range tables {
+		fmt.Fprintf(w, "%s\t%d\n", table, added[table])
+	}
+	return w.Flush()
+}
+
+func runUserShow(ctx context.Context, admin *service.AdminService, args []string, out io.Writer) error {
+	rest, err := parseArgs(newFlagSet("user show"), args, 1)
+	if err != nil {
+		return err
+	}
+	summary, err := admin.GetUserDataSummary(ctx, rest[0])
+	if err != nil {
+		return err
+	}
+	return writeJSON(out, summary)
+}
+
+func runUserExport(ctx context.Context, exports *service.ExportService, args []string, out io.Writer) error {
+	fs := newFlagSet("user export")
+	from := fs.String("from", "", "開始日 (YYYY-MM-DD)")
+	to := fs.String("to", "", "終了日 (YYYY-MM-DD)")
+	output := fs.String("o", "", "出力先のファイル (省略時は標準出力)")
+	rest, err := parseArgs(fs, args, 1)
+	if err != nil {
+		return err
+	}
+
+	plan, err := exports.PrepareFullExport(rest[0], *from, *to)
+	if err != nil {
+		return err
+	}
+	if *output == "" {
+		return exports.WriteExport(ctx, out, plan)
+	}
+	f, err := os.Create(*output)
+	if err != nil {
+		return err
+	}
+	if err := exports.WriteExport(ctx, f, plan); err != nil {
+		f.Close()
+		return err
+	}
+	if err := f.Close(); err != nil {
+		return err
+	}
+	fmt.Fprintf(os.Stderr, "exported %s to %s\n", rest[0], *output)
+	return nil
+}
+
+func runUserImport(ctx context.Context, admin *service.AdminService, args []string, out io.Writer) error {
+	fs := newFlagSet("user import")
+	input := fs.String("i", "", "取り込む JSON のエクスポート (- の場合は標準入力)")
+	dryRun := fs.Bool("dry-run", false, "取り込み結果のみ表示する (変更はロールバックする)")
+	rest, err := parseArgs(fs, args, 1)
+	if err != nil {
+		return err
+	}
+	if *input == "" {
+		return fmt.Errorf("user import: -i is required\n%s", usage)
+	}
+
+	r := io.Reader(os.Stdin)
+	if *input != "-" {
+		f, err := os.Open(*input)
+		if err != nil {
+			return err
+		}
+		defer f.Close()
+		r = f
+	}
+	result, err := admin.ImportUserData(ctx, rest[0], r, *dryRun)
+	if err != nil {
+		return err
+	}
+	return writeJSON(out, result)
+}
+
+func runDeletionsPurge(ctx context.Context, deletions *service.AccountDeletionService, args []string, out io.Writer) error {
+	fs := newFlagSet("deletions purge")
+	dryRun := fs.Bool("dry-run", false, "削除期限を過ぎた予約の件数のみ表示する")
+	if _, err := parseArgs(fs, args, 0); err != nil {
+		return err
+	}
+
+	if *dryRun {
+		count, err := deletions.CountDueDeletions(ctx)
+		if err != nil {
+			return err
+		}
+		fmt.Fprintf(out, "would purge %d account(s)\n", count)
+		return nil
+	}
+	purged, err := deletions.PurgeDueDeletions(ctx)
+	if err != nil {
+		return fmt.Errorf("purged %d account(s) before failing: %w", purged, err)
+	}
+	fmt.Fprintf(out, "purged %d account(s)\n", purged)
+	return nil
+}
+
+// writeJSON は結果をインデントした JSON で出力する
+func writeJSON(out io.Writer, v any) error {
+	enc := json.NewEncoder(out)
+	enc.SetIndent("", "  ")
+	return enc.Encode(v)
+}
@@ -0,0 +1,358 @@
+package main
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"io"
+	"log/slog"
+	"os"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/aiirononeko/bulktrack/apps/api/internal/interfaces/http/dto"
+	"github.com/aiirononeko/bulktrack/apps/api/internal/interfaces/service"
+	"github.com/aiirononeko/bulktrack/apps/api/internal/testdb"
+	"github.com/google/uuid"
+)
+
+func TestParseArgs(t *testing.T) {
+	tests := []struct {
+		name       string
+		args       []string
+		positional int
+		wantRest   []string
+		wantInput  string
+		wantDryRun bool
+		wantErr    bool
+	}{
+		{name: "flags after the argument", args: []string{"user_1", "-i", "export.json", "--dry-run"}, positional: 1, wantRest: []string{"user_1"}, wantInput: "export.json", wantDryRun: true},
+		{name: "flags before the argument", args: []string{"-i", "export.json", "user_1"}, positional: 1, wantRest: []string{"user_1"}, wantInput: "export.json"},
+		{name: "missing argument", args: []string{"-i", "export.json"}, positional: 1, wantErr: true},
+		{name: "extra argument", args: []string{"user_1", "user_2"}, positional: 1, wantErr: true},
+		{name: "unknown flag", args: []string{"user_1", "--force"}, positional: 1, wantErr: true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			fs := newFlagSet("user import")
+			fs.SetOutput(io.Discard)
+			input := fs.String("i", "", "")
+			dryRun := fs.Bool("dry-run", false, "")
+			rest, err := parseArgs(fs, tt.args, tt.positional)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("parseArgs(%v) error = %v, wantErr %v", tt.args, err, tt.wantErr)
+			}
+			if tt.wantErr {
+				return
+			}
+			if !reflect.DeepEqual(rest, tt.wantRest) || *input != tt.wantInput || *dryRun != tt.wantDryRun {
+				t.Errorf("parseArgs(%v) = %v, -i %q, --dry-run %v, want %v, -i %q, --dry-run %v", tt.args, rest, *input, *dryRun, tt.wantRest, tt.wantInput, tt.wantDryRun)
+			}
+		})
+	}
+}
+
+func TestRunRejectsUnknownCommand(t *testing.T) {
+	logger := slog.New(slog.NewTextHandler(io.Discard, nil))
+	for _, args := range [][]string{{"vacuum"}, {"user", "delete", "user_1"}} {
+		if err := run(context.Background(), nil, args, io.Discard, logger); err == nil || !strings.Contains(err.Error(), "unknown command") {
+			t.Errorf("run(%v) error = %v, want an unknown command error", args, err)
+		}
+	}
+}
+
+// user export で書き出したデータを別のユーザーに user import で取り込むと、同じ内容をエクスポートできる
+// 同じファイルを再度取り込んでも重複して作成しない
+func TestUserExportImportRoundTrip(t *testing.T) {
+	ctx := context.Background()
+	logger := slog.New(slog.NewTextHandler(io.Discard, nil))
+	pool := testdb.New(t, "admin")
+
+	const (
+		sourceID = "user_admin_export_source"
+		targetID = "user_admin_export_target"
+	)
+	// command は管理コマンドを実行し、標準出力を返す
+	command := func(args ...string) []byte {
+		t.Helper()
+		var out bytes.Buffer
+		if err := run(ctx, pool, args, &out, logger); err != nil {
+			t.Fatalf("bulktrack-admin %s error = %v", strings.Join(args, " "), err)
+		}
+		return out.Bytes()
+	}
+	importResult := func(args ...string) service.UserImportResult {
+		t.Helper()
+		var result service.UserImportResult
+		if err := json.Unmarshal(command(args...), &result); err != nil {
+			t.Fatalf("failed to decode import result: %v", err)
+		}
+		return result
+	}
+	summary := func(userID string) service.UserDataSummary {
+		t.Helper()
+		var s service.UserDataSummary
+		if err := json.Unmarshal(command("user", "show", userID), &s); err != nil {
+			t.Fatalf("failed to decode user summary: %v", err)
+		}
+		return s
+	}
+
+	command("seed")
+
+	// 元のユーザー: 組み込み種目と自重のカスタム種目のメニュー、2週分のワークアウト (ウォームアップ・重量なし・有酸素のセットを含む)
+	if _, err := pool.Exec(ctx, `
+		WITH builtin AS (SELECT id FROM exercises WHERE created_by_user_id IS NULL AND metric_type = 'weight_reps' AND load_type = 'external' ORDER BY name LIMIT 1),
+		     cardio AS (SELECT id FROM exercises WHERE created_by_user_id IS NULL AND metric_type = 'distance_time' ORDER BY name LIMIT 1),
+		     custom AS (
+		         INSERT INTO exercises (name, is_custom, created_by_user_id, load_type) VALUES ('Ring Dip', TRUE, $1, 'bodyweight') RETURNING id
+		     ),
+		     menu AS (INSERT INTO menus (user_id, name, description) VALUES ($1, 'Push', 'Chest day') RETURNING id),
+		     items AS (
+		         INSERT INTO menu_items (menu_id, exercise_id, set_order, planned_sets, planned_reps, group_key)
+		         SELECT menu.id, builtin.id, 1, 3, 10, 'A' FROM menu, builtin
+		         UNION ALL
+		         SELECT menu.id, custom.id, 2, 3, NULL, 'A' FROM menu, custom
+		     ),
+		     w1 AS (INSERT INTO workouts (user_id, menu_id, started_at, note) SELECT $1, id, '2025-05-05 07:00+09', 'Felt strong' FROM menu RETURNING id),
+		     w2 AS (INSERT INTO workouts (user_id, started_at) VALUES ($1, '2025-05-12 20:00+09') RETURNING id)
+		INSERT INTO sets (workout_id, exercise_id, set_order, weight_kg, reps, rir, set_type, group_key, duration_seconds, distance_m, avg_heart_rate)
+		SELECT w1.id, builtin.id, 1, 20, 10, NULL, 'warmup', 'A', NULL, NULL, NULL FROM w1, builtin
+		UNION ALL SELECT w1.id, builtin.id, 2, 60, 10, 2, 'working', 'A', NULL, NULL, NULL FROM w1, builtin
+		UNION ALL SELECT w1.id, custom.id, 3, NULL, 12, NULL, 'working', 'A', NULL, NULL, NULL FROM w1, custom
+		UNION ALL SELECT w2.id, builtin.id, 1, 62.5, 8, NULL, 'failure', NULL, NULL, NULL, NULL FROM w2, builtin
+		UNION ALL SELECT w2.id, cardio.id, 2, NULL, 0, NULL, 'working', NULL, 1500, 5000, 150 FROM w2, cardio`, sourceID); err != nil {
+		t.Fatalf("failed to insert source data: %v", err)
+	}
+	command("volumes", "backfill", "--from", "2025-05-01", "--to", "2025-05-31", "--user", sourceID)
+
+	exportFile := filepath.Join(t.TempDir(), "export.json")
+	command("user", "export", sourceID, "-o", exportFile)
+
+	// --dry-run は取り込み結果のみ返し、データは変更しない
+	want := service.UserImportResult{CreatedExercises: 1, CreatedMenus: 1, ImportedWorkouts: 2, ImportedSets: 5, RecalculatedWeeks: 2}
+	wantDryRun := want
+	wantDryRun.DryRun = true
+	if got := importResult("user", "import", targetID, "-i", exportFile, "--dry-run"); got != wantDryRun {
+		t.Errorf("import --dry-run = %+v, want %+v", got, wantDryRun)
+	}
+	if s := summary(targetID); s.WorkoutCount != 0 || s.MenuCount != 0 || s.CustomExerciseCount != 0 || s.WeeklyVolumeCount != 0 {
+		t.Errorf("target after --dry-run = %+v, want no data", s)
+	}
+
+	if got := importResult("user", "import", targetID, "-i", exportFile); got != want {
+		t.Errorf("import = %+v, want %+v", got, want)
+	}
+
+	// 取り込んだユーザーのエクスポートは、ID と作成日時を除いて元のユーザーのエクスポートと一致する
+	// (週間ボリュームは取り込み時に再計算した値。どちらのユーザーも体重の記録はないため、自重の種目の負荷も一致する)
+	var source, target dto.ExportDocument
+	if err := json.Unmarshal(readFile(t, exportFile), &source); err != nil {
+		t.Fatalf("failed to decode source export: %v", err)
+	}
+	if err := json.Unmarshal(command("user", "export", targetID), &target); err != nil {
+		t.Fatalf("failed to decode target export: %v", err)
+	}
+	if len(source.Workouts) != 2 || len(source.Menus) != 1 || len(source.CustomExercises) != 1 || len(source.WeeklyVolumes) != 2 {
+		t.Fatalf("source export = %d workouts, %d menus, %d custom exercises, %d weekly volumes, want 2, 1, 1, 2",
+			len(source.Workouts), len(source.Menus), len(source.CustomExercises), len(source.WeeklyVolumes))
+	}
+	if !reflect.DeepEqual(withoutIDs(source), withoutIDs(target)) {
+		t.Errorf("target export differs from the source export\nsource: %+v\ntarget: %+v", withoutIDs(source), withoutIDs(target))
+	}
+	var customOwner string
+	if err := pool.QueryRow(ctx, "SELECT created_by_user_id FROM exercises WHERE id = $1", target.CustomExercises[0].ID).Scan(&customOwner); err != nil || customOwner != targetID {
+		t.Errorf("imported custom exercise is owned by %q, %v, want %s", customOwner, err, targetID)
+	}
+
+	// 同じファイルを再度取り込んでも、同じ名前のメニュー・同じ開始日時のワークアウトは読み飛ばし、カスタム種目は再利用する
+	again := importResult("user", "import", targetID, "-i", exportFile)
+	if wantAgain := (service.UserImportResult{SkippedMenus: 1, SkippedWorkouts: 2}); again != wantAgain {
+		t.Errorf("second import = %+v, want %+v", again, wantAgain)
+	}
+	if s := summary(targetID); s.WorkoutCount != 2 || s.SetCount != 5 || s.MenuCount != 1 || s.CustomExerciseCount != 1 || s.WeeklyVolumeCount != 2 {
+		t.Errorf("target after the second import = %+v, want the same data as after the first import", s)
+	}
+	// 取り込んだデータに整合性の問題はない (問題がある場合は errIssuesFound)
+	command("check", "--user", targetID)
+}
+
+// volumes reconcile は集計キューを処理した後の weekly_volumes とセットからの集計の差分 (値の違い・行の欠落・残った行) を見つけて修復する
+func TestVolumesReconcile(t *testing.T) {
+	ctx := context.Background()
+	logger := slog.New(slog.NewTextHandler(io.Discard, nil))
+	pool := testdb.New(t, "admin")
+	if err := run(ctx, pool, []string{"seed"}, io.Discard, logger); err != nil {
+		t.Fatalf("seed error = %v", err)
+	}
+
+	const (
+		userID      = "user_reconcile"
+		otherUserID = "user_reconcile_other"
+	)
+	reconciler := service.NewVolumeReconcileService(pool, logger)
+	exec := func(query string, args ...any) {
+		t.Helper()
+		if _, err := pool.Exec(ctx, query, args...); err != nil {
+			t.Fatalf("failed to execute %q: %v", query, err)
+		}
+	}
+	// addWorkout は外部負荷の種目の1セット (重量 x 10回) のワークアウトを記録する (週は集計キューに入る)
+	addWorkout := func(userID, startedAt string, weightKg float64) {
+		t.Helper()
+		exec(`
+			WITH w AS (INSERT INTO workouts (user_id, started_at) VALUES ($1, $2) RETURNING id)
+			INSERT INTO sets (workout_id, exercise_id, set_order, weight_kg, reps)
+			SELECT w.id, e.id, 1, $3, 10 FROM w, (
+				SELECT id FROM exercises WHERE created_by_user_id IS NULL AND metric_type = 'weight_reps' AND load_type = 'external' ORDER BY name LIMIT 1
+			) e`, userID, startedAt, weightKg)
+	}
+
+	// 推定1RM は重量 x (30 + 10) / 30
+	addWorkout(userID, "2025-05-05 07:00+09", 60)
+	addWorkout(userID, "2025-05-12 07:00+09", 45)
+	addWorkout(userID, "2025-05-19 07:00+09", 30)
+	addWorkout(otherUserID, "2025-05-05 07:00+09", 100)
+
+	// 集計キューに残っている週は照合の前に集計するため差分にならない
+	if drifts, err := reconciler.ReconcileUser(ctx, userID, false); err != nil || len(drifts) != 0 {
+		t.Fatalf("ReconcileUser() before tampering = %+v, %v, want no drift", drifts, err)
+	}
+	if drifts, err := reconciler.ReconcileUser(ctx, otherUserID, false); err != nil || len(drifts) != 0 {
+		t.Fatalf("ReconcileUser() of the other user = %+v, %v, want no drift", drifts, err)
+	}
+
+	// 集計キューを通さずに変更する: 値の書き換え、行の削除、セットの削除 (週間ボリュームの行だけ残る)
+	exec("UPDATE weekly_volumes SET total_volume = total_volume + 100 WHERE user_id = $1 AND week_start_date = '2025-05-05'", userID)
+	exec("DELETE FROM weekly_volumes WHERE user_id = $1 AND week_start_date = '2025-05-12'", userID)
+	tx, err := pool.Begin(ctx)
+	if err != nil {
+		t.Fatalf("failed to begin transaction: %v", err)
+	}
+	if _, err := tx.Exec(ctx, "SELECT set_config('bulktrack.skip_weekly_volume_trigger', 'on', true)"); err != nil {
+		t.Fatalf("failed to skip the weekly volume trigger: %v", err)
+	}
+	if _, err := tx.Exec(ctx, "DELETE FROM sets WHERE workout_id IN (SELECT id FROM workouts WHERE user_id = $1 AND started_at = '2025-05-19 07:00+09')", userID); err != nil {
+		t.Fatalf("failed to delete sets: %v", err)
+	}
+	if err := tx.Commit(ctx); err != nil {
+		t.Fatalf("failed to commit: %v", err)
+	}
+	exec("UPDATE weekly_volumes SET set_count = 2 WHERE user_id = $1", otherUserID)
+	// 集計キューに入った変更は差分として報告しない
+	addWorkout(userID, "2025-05-26 07:00+09", 20)
+
+	want := []service.WeeklyVolumeDrift{
+		{
+			UserID: userID, WeekStartDate: "2025-05-05",
+			Stored: service.WeeklyVolumeTotals{TotalVolume: 700, EstOneRM: 80, ExerciseCount: 1, SetCount: 1},
+			Fresh:  service.WeeklyVolumeTotals{TotalVolume: 600, EstOneRM: 80, ExerciseCount: 1, SetCount: 1},
+		},
+		{
+			UserID: userID, WeekStartDate: "2025-05-12",
+			Fresh: service.WeeklyVolumeTotals{TotalVolume: 450, EstOneRM: 60, ExerciseCount: 1, SetCount: 1},
+		},
+		{
+			UserID: userID, WeekStartDate: "2025-05-19",
+			Stored: service.WeeklyVolumeTotals{TotalVolume: 300, EstOneRM: 40, ExerciseCount: 1, SetCount: 1},
+		},
+	}
+	drifts, err := reconciler.ReconcileUser(ctx, userID, false)
+	if err != nil {
+		t.Fatalf("ReconcileUser() error = %v", err)
+	}
+	if !reflect.DeepEqual(drifts, want) {
+		t.Fatalf("ReconcileUser() = %+v, want %+v", drifts, want)
+	}
+
+	// --dry-run は修復せず、差分がある場合は errIssuesFound を返す
+	var out bytes.Buffer
+	if err := run(ctx, pool, []string{"volumes", "reconcile", "--user", userID, "--dry-run"}, &out, logger); !errors.Is(err, errIssuesFound) {
+		t.Fatalf("volumes reconcile --dry-run error = %v, want errIssuesFound", err)
+	}
+	if !strings.Contains(out.String(), "checked 1 user(s), 3 drifted week(s), 0 repaired") {
+		t.Errorf("volumes reconcile --dry-run output = %s", out.String())
+	}
+	if drifts, err := reconciler.ReconcileUser(ctx, userID, false); err != nil || len(drifts) != len(want) {
+		t.Fatalf("ReconcileUser() after --dry-run = %+v, %v, want the same drift", drifts, err)
+	}
+
+	// 全ユーザーの修復は差分のあるユーザーのみ修復し、修復後は差分がなくなる
+	out.Reset()
+	if err := run(ctx, pool, []string{"volumes", "reconcile"}, &out, logger); err != nil {
+		t.Fatalf("volumes reconcile error = %v", err)
+	}
+	if !strings.Contains(out.String(), "checked 2 user(s), 4 drifted week(s), 4 repaired") {
+		t.Errorf("volumes reconcile output = %s", out.String())
+	}
+	for _, id := range []string{userID, otherUserID} {
+		if drifts, err := reconciler.ReconcileUser(ctx, id, false); err != nil || len(drifts) != 0 {
+			t.Errorf("ReconcileUser(%s) after repair = %+v, %v, want no drift", id, drifts, err)
+		}
+	}
+	// セットのなくなった週は 0 の行を残さずに削除する (集計キューの集計と同じ)
+	var weeks []string
+	rows, err := pool.Query(ctx, "SELECT week_start_date::text FROM weekly_volumes WHERE user_id = $1 ORDER BY week_start_date", userID)
+	if err != nil {
+		t.Fatalf("failed to list weekly volumes: %v", err)
+	}
+	for rows.Next() {
+		var week string
+		if err := rows.Scan(&week); err != nil {
+			t.Fatalf("failed to scan weekly volume: %v", err)
+		}
+		weeks = append(weeks, week)
+	}
+	if err := rows.Err(); err != nil {
+		t.Fatalf("failed to list weekly volumes: %v", err)
+	}
+	if want := []string{"2025-05-05", "2025-05-12", "2025-05-26"}; !reflect.DeepEqual(weeks, want) {
+		t.Errorf("weekly volumes after repair = %v, want %v", weeks, want)
+	}
+	var total float64
+	if err := pool.QueryRow(ctx, "SELECT COALESCE(SUM(total_volume), 0)::float8 FROM weekly_volumes WHERE user_id = $1", userID).Scan(&total); err != nil || total != 600+450+200 {
+		t.Errorf("total volume after repair = %v, %v, want %v", total, err, 600+450+200)
+	}
+}
+
+// withoutIDs はエクスポートから環境・ユーザーごとに異なる ID と作成日時を取り除く (種目は名前で比較する)
+func withoutIDs(doc dto.ExportDocument) dto.ExportDocument {
+	doc.ExportedAt = ""
+	workouts := make([]dto.ExportWorkout, len(doc.Workouts))
+	for i, w := range doc.Workouts {
+		w.ID = uuid.Nil
+		sets := make([]dto.ExportSet, len(w.Sets))
+		for j, s := range w.Sets {
+			s.ID, s.ExerciseID = uuid.Nil, uuid.Nil
+			sets[j] = s
+		}
+		w.Sets = sets
+		workouts[i] = w
+	}
+	doc.Workouts = workouts
+	menus := make([]dto.ExportMenu, len(doc.Menus))
+	for i, m := range doc.Menus {
+		m.ID, m.CreatedAt = uuid.Nil, nil
+		menus[i] = m
+	}
+	doc.Menus = menus
+	exercises := make([]dto.ExportCustomExercise, len(doc.CustomExercises))
+	for i, e := range doc.CustomExercises {
+		e.ID, e.CreatedAt = uuid.Nil, nil
+		exercises[i] = e
+	}
+	doc.CustomExercises = exercises
+	return doc
+}
+
+func readFile(t *testing.T, name string) []byte {
+	t.Helper()
+	data, err := os.ReadFile(name)
+	if err != nil {
+		t.Fatalf("failed to read %s: %v", name, err)
+	}
+	return data
+}
@@ -0,0 +1,28 @@
+package main
+
+import (
+	"errors"
+	"log/slog"
+
+	"github.com/aiirononeko/bulktrack/apps/api/internal/cache"
+	"github.com/aiirononeko/bulktrack/apps/api/internal/config"
+)
+
+// newCacheStore は CACHE_STORE のキャッシュのストアを作成する (off の場合は nil でキャッシュしない)
+func newCacheStore(cfg *config.Config, logger *slog.Logger) (cache.Store, error) {
+	switch cfg.CacheStore {
+	case "off":
+		logger.Warn("Response cache is disabled")
+		return nil, nil
+	case "redis":
+		if cfg.CacheRedisURL == "" {
+			return nil, errors.New("CACHE_REDIS_URL is required when CACHE_STORE=redis")
+		}
+		return cache.NewRedisStore(cfg.CacheRedisURL)
+	case "memory":
+		return cache.NewMemoryStore(cfg.CacheMemoryEntries), nil
+	default:
+		logger.Error("Unknown CACHE_STORE, using memory", slog.String("store", cfg.CacheStore))
+		return cache.NewMemoryStore(cfg.CacheMemoryEntries), nil
+	}
+}
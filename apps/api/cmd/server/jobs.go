@@ -0,0 +1,52 @@
+package main
+
+import (
+	"log/slog"
+	"net/http"
+
+	"github.com/aiirononeko/bulktrack/apps/api/internal/config"
+	"github.com/aiirononeko/bulktrack/apps/api/internal/handler"
+	"github.com/aiirononeko/bulktrack/apps/api/internal/interfaces/service"
+	"github.com/aiirononeko/bulktrack/apps/api/internal/jobs"
+	"github.com/aiirononeko/bulktrack/apps/api/internal/telemetry"
+	"github.com/jackc/pgx/v5/pgxpool"
+	"github.com/prometheus/client_golang/prometheus"
+)
+
+// newJobRunner はバックグラウンドジョブのハンドラーと定期実行のジョブを登録した Runner を作成する
+func newJobRunner(cfg *config.Config, pool *pgxpool.Pool, jobService *service.JobService, logger *slog.Logger) *jobs.Runner {
+	runner := jobs.NewRunner(jobService, logger, jobs.RunnerConfig{Workers: cfg.JobWorkers})
+
+	// 非同期エクスポートの ZIP アーカイブの作成
+	service.NewExportService(pool, logger).RegisterJobs(runner)
+
+	// 削除期限を過ぎたアカウントのデータ削除
+	accountDeletionService := service.NewAccountDeletionService(pool, logger)
+	accountDeletionService.RegisterJobs(runner)
+
+	// 処理に失敗した Clerk の Webhook イベントの再試行と、処理済みの古いイベントの削除
+	service.NewClerkWebhookService(pool, accountDeletionService, logger).RegisterJobs(runner)
+
+	// セット・ワークアウト・体重の変更で集計キューに入った週の週間ボリュームの集計
+	service.NewWeeklyVolumeAggregator(pool, logger).RegisterJobs(runner)
+
+	// 週間ボリュームとセットの集計を定期的に照合して修復 (VOLUME_RECONCILE_INTERVAL=0 の場合は行わない)
+	if cfg.VolumeReconcileInterval > 0 {
+		service.NewVolumeReconcileService(pool, logger).RegisterJobs(runner, cfg.VolumeReconcileInterval)
+	}
+
+	// インスタンス間で共有するレート制限のバケットのうち、使われていないものの削除
+	if cfg.RateLimitStore == "postgres" {
+		service.NewRateLimitService(pool, logger).RegisterJobs(runner)
+	}
+
+	return runner
+}
+
+// newAdminHandler は管理用ポートのハンドラーを作成する (メトリクス・ヘルスチェックと失敗したジョブの管理)
+func newAdminHandler(registry *prometheus.Registry, jobService *service.JobService, logger *slog.Logger) http.Handler {
+	mux := http.NewServeMux()
+	mux.Handle("/", telemetry.NewAdminHandler(registry))
+	handler.NewJobHandler(jobService, logger).RegisterAdminRoutes(mux)
+	return mux
+}
@@ -9,41 +9,77 @@ import (
 	"os/signal"
 	"syscall"
 	"time"
+	_ "time/tzdata" // tz パラメータ用 (タイムゾーンデータのないコンテナイメージでも動くよう埋め込む)
 
+	"github.com/aiirononeko/bulktrack/apps/api/internal/cache"
 	"github.com/aiirononeko/bulktrack/apps/api/internal/config"
 	"github.com/aiirononeko/bulktrack/apps/api/internal/db"
 	"github.com/aiirononeko/bulktrack/apps/api/internal/di"
 	httpError "github.com/aiirononeko/bulktrack/apps/api/internal/http"
 	"github.com/aiirononeko/bulktrack/apps/api/internal/interfaces/http/handler"
+	"github.com/aiirononeko/bulktrack/apps/api/internal/interfaces/service"
+	"github.com/aiirononeko/bulktrack/apps/api/internal/logging"
+	"github.com/aiirononeko/bulktrack/apps/api/internal/telemetry"
 	"github.com/aiirononeko/bulktrack/apps/api/internal/validation"
+	"github.com/prometheus/client_golang/prometheus"
 )
 
 func main() {
+	// 設定読み込み (NewConfig を使用)
+	cfg := config.NewConfig()
+
 	// --- Logger 初期化 ---
-	logLevel := slog.LevelInfo // デフォルトは Info
-	if os.Getenv("LOG_LEVEL") == "DEBUG" {
-		logLevel = slog.LevelDebug
-	}
-	logger := slog.New(slog.NewJSONHandler(os.Stdout, &slog.HandlerOptions{
-		AddSource: true,     // ソースコードの位置情報を追加
-		Level:     logLevel, // 環境変数でレベルを設定可能に
-	}))
+	// パッケージごとのレベル (LOG_LEVEL / LOG_LEVELS)。不正な場合は Info で起動する
+	logLevels, logLevelErr := logging.ParseLevels(cfg.LogLevel, cfg.LogLevels)
+	if logLevelErr != nil {
+		logLevels = &logging.Levels{Default: slog.LevelInfo}
+	}
+	// リクエストID・ユーザーIDを付け、機密情報や大きな値を伏字にする
+	// その後でトレースIDを付け、エラーログをスパンに記録する
+	logger := slog.New(logging.NewHandler(telemetry.NewLogHandler(slog.NewJSONHandler(os.Stdout, &slog.HandlerOptions{
+		AddSource: true,                // ソースコードの位置情報を追加
+		Level:     logLevels.Minimum(), // パッケージごとのレベルは logging.Handler で絞り込む
+	})), logLevels, logging.NewRedactPolicy(cfg.LogRedactKeys, cfg.LogMaxValueBytes)))
 	slog.SetDefault(logger) // デフォルトロガーにも設定
+	if logLevelErr != nil {
+		logger.Warn("Invalid log level configuration, using INFO", slog.Any("error", logLevelErr))
+	}
 	// --- Logger 初期化 完了 ---
 
 	ctx, stop := signal.NotifyContext(context.Background(), syscall.SIGINT, syscall.SIGTERM)
 	defer stop()
 
+	// マイグレーションのサブコマンド (server migrate <command>)
+	if len(os.Args) > 1 && os.Args[1] == "migrate" {
+		os.Exit(migrateMain(ctx, os.Args[2:], logger))
+	}
+
 	logger.Info("Starting server...") // ログ出力例
 
-	// 設定読み込み (NewConfig を使用)
-	cfg := config.NewConfig()
 	if cfg.DatabaseURL == "" { // 簡単なバリデーション例
 		logger.Error("Database URL is not configured")
 		os.Exit(1)
 	}
 	logger.Info("Configuration loaded", slog.String("port", cfg.Port))
 
+	// Prometheus のメトリクス (管理用ポートで公開する)
+	var promRegistry *prometheus.Registry
+	if cfg.MetricsPort != "" {
+		promRegistry = telemetry.NewPrometheusRegistry()
+	}
+
+	// OpenTelemetry のトレースとメトリクス (データベースの接続より前に設定する)
+	shutdownTelemetry, err := telemetry.Setup(ctx, telemetry.Options{
+		ServiceName:  cfg.OTelServiceName,
+		OTLPEndpoint: cfg.OTelOTLPEndpoint,
+		Disabled:     cfg.OTelDisabled,
+		Prometheus:   promRegistry,
+	}, logger)
+	if err != nil {
+		logger.Error("Failed to set up telemetry", slog.Any("error", err))
+		os.Exit(1)
+	}
+
 	// Connect to the database using the New function which reads DATABASE_URL env var
 	dbConn, err := db.New() // db.Connect から db.New() に変更し、引数を削除
 	if err != nil {
@@ -54,12 +90,45 @@ func main() {
 	defer dbConn.Close()
 	logger.Info("Successfully connected to database")
 
+	// 起動時のマイグレーション (AUTO_MIGRATE=true の場合)
+	if cfg.AutoMigrate {
+		migrator, err := newMigrator(dbConn, logger)
+		if err == nil {
+			_, err = migrator.Up(ctx)
+		}
+		if err != nil {
+			logger.Error("Failed to apply migrations", slog.Any("error", err))
+			os.Exit(1)
+		}
+	}
+
+	if err := telemetry.RegisterPoolMetrics(dbConn); err != nil {
+		logger.Error("Failed to register connection pool metrics", slog.Any("error", err))
+	}
+
+	// 種目の一覧・メニュー・週間ボリュームのキャッシュ (サービスを使い始める前に設定する)
+	cacheStore, err := newCacheStore(cfg, logger)
+	if err != nil {
+		logger.Error("Failed to set up cache", slog.Any("error", err))
+		os.Exit(1)
+	}
+	if redisStore, ok := cacheStore.(*cache.RedisStore); ok {
+		defer redisStore.Close()
+	}
+	service.SetCache(cache.New(cacheStore, logger))
+
 	// DIコンテナ作成 (NewContainer の引数を修正)
 	container := di.NewContainer(cfg, dbConn, logger)
 
 	// バリデータの作成と設定
 	container.Validator = validation.New()
 
+	// バックグラウンドジョブ (エクスポート・アカウントの削除・週間ボリュームの集計と照合・Webhook の再試行など) の実行
+	// シャットダウンでは新しいジョブの取り出しを止め、実行中のジョブの終了を待つ (終わらないジョブは実行待ちに戻す)
+	jobService := service.NewJobService(dbConn, logger)
+	jobRunner := newJobRunner(cfg, dbConn, jobService, logger)
+	jobRunner.Start(ctx)
+
 	// HTTPサーバーハンドラ作成
 	serverHandler := handler.NewServer(container) // NewServer に Container を渡す
 
@@ -85,6 +154,24 @@ func main() {
 		}
 	}()
 
+	// 管理用サーバー (/metrics, /jobs) を非同期で起動
+	var adminSrv *http.Server
+	if promRegistry != nil {
+		adminSrv = &http.Server{
+			Addr:         ":" + cfg.MetricsPort,
+			Handler:      newAdminHandler(promRegistry, jobService, logger),
+			ReadTimeout:  5 * time.Second,
+			WriteTimeout: 10 * time.Second,
+		}
+		go func() {
+			logger.Info("Admin server listening", slog.String("address", adminSrv.Addr))
+			if err := adminSrv.ListenAndServe(); err != nil && !errors.Is(err, http.ErrServerClosed) {
+				logger.Error("Admin server failed to start", slog.Any("error", err))
+				stop()
+			}
+		}()
+	}
+
 	// シャットダウンシグナル待機
 	<-ctx.Done()
 
@@ -104,6 +191,19 @@ func main() {
 	if err := srv.Shutdown(shutdownCtx); err != nil {
 		logger.Error("Server forced to shutdown", slog.Any("error", err))
 	}
+	if err := jobRunner.Stop(shutdownCtx); err != nil {
+		logger.Error("Job runner forced to stop", slog.Any("error", err))
+	}
+	if adminSrv != nil {
+		if err := adminSrv.Shutdown(shutdownCtx); err != nil {
+			logger.Error("Admin server forced to shutdown", slog.Any("error", err))
+		}
+	}
+
+	// 残っているスパンとメトリクスを送信
+	if err := shutdownTelemetry(shutdownCtx); err != nil {
+		logger.Error("Failed to shut down telemetry", slog.Any("error", err))
+	}
 
 	logger.Info("Server exiting")
 }
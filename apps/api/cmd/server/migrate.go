@@ -0,0 +1,111 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"log/slog"
+	"os"
+	"strconv"
+	"text/tabwriter"
+	"time"
+
+	"github.com/aiirononeko/bulktrack/apps/api/internal/db"
+	"github.com/aiirononeko/bulktrack/apps/api/internal/migrate"
+	"github.com/aiirononeko/bulktrack/apps/api/migrations"
+	"github.com/jackc/pgx/v5/pgxpool"
+)
+
+const migrateUsage = `usage: server migrate <command>
+
+commands:
+  up                 未適用のマイグレーションをすべて適用する
+  down [N]           適用済みのマイグレーションを新しい方から N 件 (既定は1件) ロールバックする
+  status             マイグレーションの適用状況を表示する
+  baseline VERSION   VERSION 以下のマイグレーションを実行せずに適用済みとして記録する
+                     (マイグレーションの導入前に schema.sql で作成したデータベースで一度だけ使う)`
+
+// migrateMain は migrate サブコマンドのエントリポイント (終了コードを返す)
+func migrateMain(ctx context.Context, args []string, logger *slog.Logger) int {
+	pool, err := db.New()
+	if err != nil {
+		logger.Error("Failed to connect to database", slog.Any("error", err))
+		return 1
+	}
+	defer pool.Close()
+
+	if err := runMigrate(ctx, pool, args, os.Stdout, logger); err != nil {
+		logger.Error("Migration failed", slog.Any("error", err))
+		return 1
+	}
+	return 0
+}
+
+// newMigrator は埋め込んだマイグレーションの Migrator を作成する
+func newMigrator(pool *pgxpool.Pool, logger *slog.Logger) (*migrate.Migrator, error) {
+	all, err := migrate.Load(migrations.FS)
+	if err != nil {
+		return nil, err
+	}
+	return migrate.New(pool, all, logger), nil
+}
+
+// runMigrate は migrate サブコマンドを実行する
+func runMigrate(ctx context.Context, pool *pgxpool.Pool, args []string, out io.Writer, logger *slog.Logger) error {
+	if len(args) == 0 {
+		return fmt.Errorf("missing command\n%s", migrateUsage)
+	}
+	migrator, err := newMigrator(pool, logger)
+	if err != nil {
+		return err
+	}
+
+	switch command := args[0]; {
+	case command == "up" && len(args) == 1:
+		applied, err := migrator.Up(ctx)
+		if err != nil {
+			return err
+		}
+		fmt.Fprintf(out, "applied %d migration(s)\n", applied)
+	case command == "down" && len(args) <= 2:
+		steps := 1
+		if len(args) == 2 {
+			if steps, err = strconv.Atoi(args[1]); err != nil || steps < 1 {
+				return fmt.Errorf("invalid number of migrations %q\n%s", args[1], migrateUsage)
+			}
+		}
+		reverted, err := migrator.Down(ctx, steps)
+		if err != nil {
+			return err
+		}
+		fmt.Fprintf(out, "reverted %d migration(s)\n", reverted)
+	case command == "status" && len(args) == 1:
+		statuses, err := migrator.Status(ctx)
+		if err != nil {
+			return err
+		}
+		w := tabwriter.NewWriter(out, 0, 0, 2, ' ', 0)
+		fmt.Fprintln(w, "VERSION\tNAME\tAPPLIED AT")
+		for _, status := range statuses {
+			appliedAt := "pending"
+			if status.AppliedAt != nil {
+				appliedAt = status.AppliedAt.Format(time.RFC3339)
+			}
+			fmt.Fprintf(w, "%d\t%s\t%s\n", status.Version, status.Name, appliedAt)
+		}
+		return w.Flush()
+	case command == "baseline" && len(args) == 2:
+		version, err := strconv.ParseInt(args[1], 10, 64)
+		if err != nil {
+			return fmt.Errorf("invalid version %q\n%s", args[1], migrateUsage)
+		}
+		marked, err := migrator.Baseline(ctx, version)
+		if err != nil {
+			return err
+		}
+		fmt.Fprintf(out, "marked %d migration(s) as applied\n", marked)
+	default:
+		return fmt.Errorf("invalid command %q\n%s", args, migrateUsage)
+	}
+	return nil
+}
@@ -7,12 +7,18 @@ import (
 
 	"github.com/aiirononeko/bulktrack/apps/api/internal/infrastructure/sqlc"
 	"github.com/aiirononeko/bulktrack/apps/api/internal/interfaces/http/dto"
+	"github.com/aiirononeko/bulktrack/apps/api/internal/telemetry"
 	"github.com/google/uuid"
 	"github.com/jackc/pgx/v5"
 	"github.com/jackc/pgx/v5/pgtype"
 	"github.com/jackc/pgx/v5/pgxpool"
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/trace"
 )
 
+var tracer = otel.Tracer(telemetry.InstrumentationName + "/query")
+
 // LatestSetQueryService は種目の最新セット情報を取得するためのサービスインターフェース
 type LatestSetQueryService interface {
 	ListByMenu(ctx context.Context, userID string, menuID uuid.UUID) ([]dto.ExerciseLastRecord, error)
@@ -36,6 +42,12 @@ func NewLatestSetQueryService(pool *pgxpool.Pool, logger *slog.Logger) LatestSet
 
 // ListByMenu はメニューに紐づく各種目の**最新ワークアウトの全セット情報**を取得する
 func (s *latestSetQueryService) ListByMenu(ctx context.Context, userID string, menuID uuid.UUID) ([]dto.ExerciseLastRecord, error) {
+	ctx, span := tracer.Start(ctx, "LatestSetQueryService.ListByMenu", trace.WithAttributes(
+		attribute.String("user_id", userID),
+		attribute.String("menu_id", menuID.String()),
+	))
+	defer span.End()
+
 	// メニューIDをpgtype.UUIDに変換
 	pgMenuID := pgtype.UUID{Bytes: menuID, Valid: true}
 
@@ -101,6 +113,22 @@ func (s *latestSetQueryService) ListByMenu(ctx context.Context, userID string, m
 		recordData := dto.LastRecordData{
 			SetOrder: set.SetOrder,
 			Date:     set.StartedAt.Time,
+			SetType:  set.SetType,
+		}
+		if set.GroupKey.Valid {
+			groupKey := set.GroupKey.String
+			recordData.GroupKey = &groupKey
+		}
+		if set.DurationSeconds.Valid {
+			durationSeconds := set.DurationSeconds.Int32
+			recordData.DurationSeconds = &durationSeconds
+		}
+		if set.DistanceM.Valid {
+			dVal, errConv := set.DistanceM.Float64Value()
+			if errConv == nil && dVal.Valid {
+				distanceM := dVal.Float64
+				recordData.DistanceM = &distanceM
+			}
 		}
 		if set.WeightKg.Valid {
 			wVal, errConv := set.WeightKg.Float64Value()
@@ -108,6 +136,13 @@ func (s *latestSetQueryService) ListByMenu(ctx context.Context, userID string, m
 				recordData.WeightKg = wVal.Float64
 			}
 		}
+		if set.EffectiveLoadKg.Valid {
+			loadVal, errConv := set.EffectiveLoadKg.Float64Value()
+			if errConv == nil && loadVal.Valid {
+				effectiveLoadKg := loadVal.Float64
+				recordData.EffectiveLoadKg = &effectiveLoadKg
+			}
+		}
 		recordData.Reps = set.Reps
 		if set.Rir.Valid {
 			rirVal, errConv := set.Rir.Float64Value()
@@ -139,8 +174,13 @@ func (s *latestSetQueryService) ListByMenu(ctx context.Context, userID string, m
 		record := dto.ExerciseLastRecord{
 			ExerciseID:   exerciseUUID,
 			ExerciseName: item.ExerciseName,
+			MetricType:   item.MetricType,
 			LastRecord:   lastRecords,
 		}
+		if item.GroupKey.Valid {
+			groupKey := item.GroupKey.String
+			record.GroupKey = &groupKey
+		}
 		result = append(result, record)
 	}
 
@@ -0,0 +1,74 @@
+package auth
+
+import (
+	"errors"
+
+	"github.com/google/uuid"
+)
+
+// CoachAthleteHeader はコーチがアスリートのデータにアクセスする際に、対象のアスリートのユーザーIDを指定するヘッダー
+const CoachAthleteHeader = "X-Athlete-ID"
+
+// コーチに与える権限 (write は read を含む)
+const (
+	CoachPermissionRead  = "read"
+	CoachPermissionWrite = "write"
+)
+
+// coachRoutePermissions はコーチがアスリートのデータに対して呼び出せるルートと必要な権限
+// ワークアウト・ボリューム・身体計測 (進捗) の閲覧と、メニューの作成・編集 (共有されたメニューの複製を含む) のみ許可する
+// アスリートのメニューの共有 (共有コードの発行) はアスリート本人のみ行える
+var coachRoutePermissions = map[string]string{
+	"GET /workouts":                          CoachPermissionRead,
+	"GET /workouts/{id}":                     CoachPermissionRead,
+	"GET /history":                           CoachPermissionRead,
+	"GET /history/calendar":                  CoachPermissionRead,
+	"GET /menus":                             CoachPermissionRead,
+	"GET /menus/{id}":                        CoachPermissionRead,
+	"GET /menus/{id}/exercises/last-records": CoachPermissionRead,
+	"GET /exercises":                         CoachPermissionRead,
+	"GET /shared-menus/{code}":               CoachPermissionRead,
+	"GET /menu-templates":                    CoachPermissionRead,
+	"GET /v1/weekly-volume":                  CoachPermissionRead,
+	"GET /v1/weekly-volume/stats":            CoachPermissionRead,
+	"GET /v1/weekly-volume/{week}":           CoachPermissionRead,
+	"GET /measurement-metrics":               CoachPermissionRead,
+	"GET /measurements":                      CoachPermissionRead,
+	"GET /measurements/series":               CoachPermissionRead,
+	"GET /measurements/relative-strength":    CoachPermissionRead,
+	"GET /body-weights":                      CoachPermissionRead,
+	"POST /menus":                            CoachPermissionWrite,
+	"PUT /menus/{id}":                        CoachPermissionWrite,
+	"DELETE /menus/{id}":                     CoachPermissionWrite,
+	"POST /shared-menus/{code}/clone":        CoachPermissionWrite,
+}
+
+// IsValidCoachPermission は権限が与えられるものかを返す
+func IsValidCoachPermission(permission string) bool {
+	return permission == CoachPermissionRead || permission == CoachPermissionWrite
+}
+
+// RequiredCoachPermission はコーチがルートを呼び出すのに必要な権限を返す (コーチが呼び出せない場合は ok が false)
+func RequiredCoachPermission(pattern string) (permission string, ok bool) {
+	permission, ok = coachRoutePermissions[pattern]
+	return permission, ok
+}
+
+// CoachPermissionAllows は与えられた権限 (granted) で必要な権限 (required) の操作ができるかを返す
+func CoachPermissionAllows(granted, required string) bool {
+	return granted == CoachPermissionWrite || granted == required
+}
+
+// ErrCoachAccessDenied はコーチにアスリートのデータへのアクセス権がないことを表す
+var ErrCoachAccessDenied = errors.New("coach access denied")
+
+// CoachAction はコーチがアスリートのデータに対して行った操作 (監査ログ) を表す
+type CoachAction struct {
+	GrantID       uuid.UUID
+	CoachUserID   string
+	AthleteUserID string
+	Method        string
+	Route         string // ServeMux のパターン
+	Path          string
+	StatusCode    int
+}
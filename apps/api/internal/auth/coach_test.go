@@ -0,0 +1,28 @@
+package auth
+
+import "testing"
+
+func TestCoachRoutePermissionsAreValid(t *testing.T) {
+	for pattern, permission := range coachRoutePermissions {
+		if !IsValidCoachPermission(permission) {
+			t.Errorf("route %q requires unknown permission %q", pattern, permission)
+		}
+	}
+}
+
+func TestCoachPermissionAllows(t *testing.T) {
+	tests := []struct {
+		granted, required string
+		want              bool
+	}{
+		{CoachPermissionRead, CoachPermissionRead, true},
+		{CoachPermissionRead, CoachPermissionWrite, false},
+		{CoachPermissionWrite, CoachPermissionRead, true},
+		{CoachPermissionWrite, CoachPermissionWrite, true},
+	}
+	for _, tt := range tests {
+		if got := CoachPermissionAllows(tt.granted, tt.required); got != tt.want {
+			t.Errorf("CoachPermissionAllows(%q, %q) = %v, want %v", tt.granted, tt.required, got, tt.want)
+		}
+	}
+}
@@ -0,0 +1,457 @@
+// Package auth は JWT (セッショントークン) の検証を提供する
+package auth
+
+import (
+	"context"
+	"crypto"
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rsa"
+	"crypto/sha256"
+	"crypto/sha512"
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"hash"
+	"io"
+	"math/big"
+	"net/http"
+	"os"
+	"slices"
+	"strings"
+	"sync"
+	"time"
+)
+
+// 検証エラー
+var (
+	ErrTokenMalformed          = errors.New("token is malformed")
+	ErrUnsupportedAlgorithm    = errors.New("unsupported signing algorithm")
+	ErrUnknownKey              = errors.New("signing key not found in JWKS")
+	ErrInvalidSignature        = errors.New("invalid token signature")
+	ErrTokenExpired            = errors.New("token is expired")
+	ErrTokenNotYetValid        = errors.New("token is not valid yet")
+	ErrInvalidIssuer           = errors.New("invalid issuer")
+	ErrInvalidAudience         = errors.New("invalid audience")
+	ErrInvalidAuthorizedParty  = errors.New("invalid authorized party")
+	ErrMissingSubject          = errors.New("token has no subject")
+	errJWKSSourceNotConfigured = errors.New("JWKS URL or file is required")
+)
+
+// 鍵の再取得の間隔
+const (
+	defaultJWKSCacheTTL        = time.Hour        // キャッシュした鍵を定期的に再取得する間隔
+	defaultJWKSMinRefreshDelay = 30 * time.Second // 未知の kid による再取得の最小間隔 (不正なトークンで JWKS を連続取得させない)
+	defaultClockSkew           = time.Minute
+)
+
+// maxJWKSBytes は JWKS の最大サイズ
+const maxJWKSBytes = 1 << 20
+
+// minRSAKeyBits は受け付ける RSA 鍵の最小ビット数
+const minRSAKeyBits = 2048
+
+// ecdsaCurves は ES* の alg に対応する曲線
+var ecdsaCurves = map[string]elliptic.Curve{
+	"ES256": elliptic.P256(),
+	"ES384": elliptic.P384(),
+	"ES512": elliptic.P521(),
+}
+
+// Options は JWKSVerifier の設定を表す
+type Options struct {
+	JWKSURL           string      // JWKS を取得する URL (JWKSFile とどちらかを指定)
+	JWKSFile          string      // JWKS のローカルファイル (自前の発行者・結合テスト用)
+	JWKSHeader        http.Header // JWKS の取得時に付けるヘッダー (Clerk Backend API の Authorization など)
+	HTTPClient        *http.Client
+	Issuer            string        // 空の場合は検証しない
+	Audiences         []string      // いずれかを含むこと (空の場合は検証しない)
+	AuthorizedParties []string      // azp がいずれかであること (空の場合は検証しない)
+	ClockSkew         time.Duration // exp / nbf / iat の許容誤差 (0 の場合は1分)
+	CacheTTL          time.Duration // 0 の場合は1時間
+}
+
+// Claims は検証済みトークンのクレームを表す
+type Claims struct {
+	Subject         string   `json:"sub"`
+	Issuer          string   `json:"iss"`
+	Audience        audience `json:"aud"`
+	ExpiresAt       *int64   `json:"exp"`
+	NotBefore       *int64   `json:"nbf"`
+	IssuedAt        *int64   `json:"iat"`
+	AuthorizedParty string   `json:"azp"`
+	SessionID       string   `json:"sid"` // Clerk のセッションID
+}
+
+// audience は文字列と文字列の配列のどちらでも表せる aud クレーム
+type audience []string
+
+// UnmarshalJSON は aud を文字列または配列から読み込む
+func (a *audience) UnmarshalJSON(data []byte) error {
+	var single string
+	if err := json.Unmarshal(data, &single); err == nil {
+		*a = audience{single}
+		return nil
+	}
+	var multiple []string
+	if err := json.Unmarshal(data, &multiple); err != nil {
+		return err
+	}
+	*a = multiple
+	return nil
+}
+
+// JWKSVerifier は JWKS の公開鍵で JWT を検証する
+// 鍵は kid ごとにキャッシュし、未知の kid のトークンを受け取った場合 (鍵のローテーション) は JWKS を再取得する
+type JWKSVerifier struct {
+	opts Options
+	now  func() time.Time
+
+	mu          sync.RWMutex
+	keys        map[string]crypto.PublicKey
+	fetchedAt   time.Time  // 最後に JWKS を取得できた時刻
+	attemptedAt time.Time  // 最後に JWKS の取得を試みた時刻 (失敗を含む)
+	refreshMu   sync.Mutex // 再取得を1つに絞る
+}
+
+// NewJWKSVerifier は JWKSVerifier を作成する
+// JWKS は最初の検証時に読み込む (起動時に発行者へ接続できなくてもサーバーは起動する)
+func NewJWKSVerifier(opts Options) (*JWKSVerifier, error) {
+	if opts.JWKSURL == "" && opts.JWKSFile == "" {
+		return nil, errJWKSSourceNotConfigured
+	}
+	if opts.HTTPClient == nil {
+		opts.HTTPClient = &http.Client{Timeout: 10 * time.Second}
+	}
+	if opts.ClockSkew == 0 {
+		opts.ClockSkew = defaultClockSkew
+	}
+	if opts.CacheTTL == 0 {
+		opts.CacheTTL = defaultJWKSCacheTTL
+	}
+	return &JWKSVerifier{opts: opts, now: time.Now}, nil
+}
+
+// Verify はトークンの署名とクレーム (iss / aud / exp / nbf / iat / azp) を検証する
+func (v *JWKSVerifier) Verify(ctx context.Context, token string) (*Claims, error) {
+	parts := strings.Split(token, ".")
+	if len(parts) != 3 {
+		return nil, ErrTokenMalformed
+	}
+
+	var header struct {
+		Alg string `json:"alg"`
+		Kid string `json:"kid"`
+	}
+	if err := decodeSegment(parts[0], &header); err != nil {
+		return nil, ErrTokenMalformed
+	}
+	signature, err := base64.RawURLEncoding.DecodeString(parts[2])
+	if err != nil {
+		return nil, ErrTokenMalformed
+	}
+
+	key, err := v.key(ctx, header.Kid)
+	if err != nil {
+		return nil, err
+	}
+	if err := verifySignature(header.Alg, key, parts[0]+"."+parts[1], signature); err != nil {
+		return nil, err
+	}
+
+	var claims Claims
+	if err := decodeSegment(parts[1], &claims); err != nil {
+		return nil, ErrTokenMalformed
+	}
+	if err := v.validateClaims(&claims); err != nil {
+		return nil, err
+	}
+	return &claims, nil
+}
+
+// validateClaims は登録済みクレームを検証する
+func (v *JWKSVerifier) validateClaims(claims *Claims) error {
+	now := v.now()
+	skew := v.opts.ClockSkew
+	if claims.ExpiresAt == nil || now.After(time.Unix(*claims.ExpiresAt, 0).Add(skew)) {
+		return ErrTokenExpired
+	}
+	if claims.NotBefore != nil && now.Before(time.Unix(*claims.NotBefore, 0).Add(-skew)) {
+		return ErrTokenNotYetValid
+	}
+	if claims.IssuedAt != nil && now.Before(time.Unix(*claims.IssuedAt, 0).Add(-skew)) {
+		return ErrTokenNotYetValid
+	}
+	if v.opts.Issuer != "" && claims.Issuer != v.opts.Issuer {
+		return fmt.Errorf("%w: %s", ErrInvalidIssuer, claims.Issuer)
+	}
+	if len(v.opts.Audiences) > 0 && !slices.ContainsFunc(claims.Audience, func(aud string) bool {
+		return slices.Contains(v.opts.Audiences, aud)
+	}) {
+		return ErrInvalidAudience
+	}
+	if len(v.opts.AuthorizedParties) > 0 && claims.AuthorizedParty != "" && !slices.Contains(v.opts.AuthorizedParties, claims.AuthorizedParty) {
+		// Clerk は azp にトークンを発行したフロントエンドのオリジンを入れる (azp がないトークンは許可する)
+		return fmt.Errorf("%w: %s", ErrInvalidAuthorizedParty, claims.AuthorizedParty)
+	}
+	if claims.Subject == "" {
+		return ErrMissingSubject
+	}
+	return nil
+}
+
+// key は kid の公開鍵を返す
+// キャッシュの期限切れ、または未知の kid の場合は JWKS を再取得する
+func (v *JWKSVerifier) key(ctx context.Context, kid string) (crypto.PublicKey, error) {
+	v.mu.RLock()
+	key, ok := v.lookup(kid)
+	stale := v.now().Sub(v.fetchedAt) > v.opts.CacheTTL
+	canRefresh := v.now().Sub(v.attemptedAt) > defaultJWKSMinRefreshDelay
+	v.mu.RUnlock()
+
+	if ok && !stale {
+		return key, nil
+	}
+	if !ok && !canRefresh {
+		return nil, ErrUnknownKey
+	}
+	if err := v.refresh(ctx); err != nil {
+		if ok {
+			// 再取得に失敗しても、キャッシュにある鍵で検証を続ける
+			return key, nil
+		}
+		return nil, fmt.Errorf("%w: %v", ErrUnknownKey, err)
+	}
+
+	v.mu.RLock()
+	defer v.mu.RUnlock()
+	if key, ok := v.lookup(kid); ok {
+		return key, nil
+	}
+	return nil, ErrUnknownKey
+}
+
+// lookup はキャッシュから鍵を探す (kid がないトークンは鍵が1つの場合のみ許可する)。mu を取得して呼び出す
+func (v *JWKSVerifier) lookup(kid string) (crypto.PublicKey, bool) {
+	if kid == "" && len(v.keys) == 1 {
+		for _, key := range v.keys {
+			return key, true
+		}
+	}
+	key, ok := v.keys[kid]
+	return key, ok
+}
+
+// refresh は JWKS を取得してキャッシュを置き換える
+func (v *JWKSVerifier) refresh(ctx context.Context) error {
+	v.refreshMu.Lock()
+	defer v.refreshMu.Unlock()
+
+	// 待っている間に他のリクエストが再取得した場合はそれを使う
+	v.mu.Lock()
+	recent := !v.attemptedAt.IsZero() && v.now().Sub(v.attemptedAt) < defaultJWKSMinRefreshDelay
+	if !recent {
+		v.attemptedAt = v.now()
+	}
+	v.mu.Unlock()
+	if recent {
+		return nil
+	}
+
+	data, err := v.load(ctx)
+	if err != nil {
+		return err
+	}
+	keys, err := parseJWKS(data)
+	if err != nil {
+		return err
+	}
+
+	v.mu.Lock()
+	v.keys = keys
+	v.fetchedAt = v.now()
+	v.mu.Unlock()
+	return nil
+}
+
+// load は JWKS を URL またはファイルから読み込む
+func (v *JWKSVerifier) load(ctx context.Context) ([]byte, error) {
+	if v.opts.JWKSFile != "" {
+		data, err := os.ReadFile(v.opts.JWKSFile)
+		if err != nil {
+			return nil, fmt.Errorf("failed to read JWKS file: %w", err)
+		}
+		return data, nil
+	}
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, v.opts.JWKSURL, nil)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create JWKS request: %w", err)
+	}
+	for name, values := range v.opts.JWKSHeader {
+		for _, value := range values {
+			req.Header.Add(name, value)
+		}
+	}
+	req.Header.Set("Accept", "application/json")
+	resp, err := v.opts.HTTPClient.Do(req)
+	if err != nil {
+		return nil, fmt.Errorf("failed to fetch JWKS: %w", err)
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("failed to fetch JWKS: status %d", resp.StatusCode)
+	}
+	data, err := io.ReadAll(io.LimitReader(resp.Body, maxJWKSBytes))
+	if err != nil {
+		return nil, fmt.Errorf("failed to read JWKS: %w", err)
+	}
+	return data, nil
+}
+
+// jwk は JWKS の鍵 (RSA / EC の公開鍵) を表す
+type jwk struct {
+	Kty string `json:"kty"`
+	Kid string `json:"kid"`
+	Use string `json:"use"`
+	N   string `json:"n"`
+	E   string `json:"e"`
+	Crv string `json:"crv"`
+	X   string `json:"x"`
+	Y   string `json:"y"`
+}
+
+// parseJWKS は JWKS から署名用の公開鍵を kid ごとに読み込む (未対応の鍵と 2048 ビット未満の RSA 鍵は無視する)
+func parseJWKS(data []byte) (map[string]crypto.PublicKey, error) {
+	var set struct {
+		Keys []jwk `json:"keys"`
+	}
+	if err := json.Unmarshal(data, &set); err != nil {
+		return nil, fmt.Errorf("failed to parse JWKS: %w", err)
+	}
+
+	keys := make(map[string]crypto.PublicKey, len(set.Keys))
+	for _, k := range set.Keys {
+		if k.Use != "" && k.Use != "sig" {
+			continue
+		}
+		key, err := k.publicKey()
+		if err != nil {
+			continue
+		}
+		keys[k.Kid] = key
+	}
+	if len(keys) == 0 {
+		return nil, errors.New("JWKS has no usable signing keys")
+	}
+	return keys, nil
+}
+
+// publicKey は JWK を公開鍵に変換する
+func (k jwk) publicKey() (crypto.PublicKey, error) {
+	switch k.Kty {
+	case "RSA":
+		n, err := decodeBigInt(k.N)
+		if err != nil {
+			return nil, err
+		}
+		if n.BitLen() < minRSAKeyBits {
+			return nil, fmt.Errorf("RSA key is shorter than %d bits", minRSAKeyBits)
+		}
+		e, err := decodeBigInt(k.E)
+		if err != nil || !e.IsInt64() {
+			return nil, errors.New("invalid RSA exponent")
+		}
+		return &rsa.PublicKey{N: n, E: int(e.Int64())}, nil
+	case "EC":
+		var curve elliptic.Curve
+		switch k.Crv {
+		case "P-256":
+			curve = elliptic.P256()
+		case "P-384":
+			curve = elliptic.P384()
+		case "P-521":
+			curve = elliptic.P521()
+		default:
+			return nil, fmt.Errorf("unsupported curve: %s", k.Crv)
+		}
+		x, err := decodeBigInt(k.X)
+		if err != nil {
+			return nil, err
+		}
+		y, err := decodeBigInt(k.Y)
+		if err != nil {
+			return nil, err
+		}
+		if !curve.IsOnCurve(x, y) {
+			return nil, errors.New("EC point is not on the curve")
+		}
+		return &ecdsa.PublicKey{Curve: curve, X: x, Y: y}, nil
+	default:
+		return nil, fmt.Errorf("unsupported key type: %s", k.Kty)
+	}
+}
+
+// verifySignature は alg に応じて署名を検証する (none や HMAC は受け付けない)
+func verifySignature(alg string, key crypto.PublicKey, signingInput string, signature []byte) error {
+	var h hash.Hash
+	var hashFunc crypto.Hash
+	switch alg {
+	case "RS256", "ES256":
+		h, hashFunc = sha256.New(), crypto.SHA256
+	case "RS384", "ES384":
+		h, hashFunc = sha512.New384(), crypto.SHA384
+	case "RS512", "ES512":
+		h, hashFunc = sha512.New(), crypto.SHA512
+	default:
+		return fmt.Errorf("%w: %s", ErrUnsupportedAlgorithm, alg)
+	}
+	h.Write([]byte(signingInput))
+	digest := h.Sum(nil)
+
+	switch key := key.(type) {
+	case *rsa.PublicKey:
+		if !strings.HasPrefix(alg, "RS") {
+			return fmt.Errorf("%w: %s for RSA key", ErrUnsupportedAlgorithm, alg)
+		}
+		if err := rsa.VerifyPKCS1v15(key, hashFunc, digest, signature); err != nil {
+			return ErrInvalidSignature
+		}
+	case *ecdsa.PublicKey:
+		if ecdsaCurves[alg] != key.Curve {
+			// ES256 は P-256、ES384 は P-384、ES512 は P-521 の鍵のみ
+			return fmt.Errorf("%w: %s for %s key", ErrUnsupportedAlgorithm, alg, key.Curve.Params().Name)
+		}
+		size := (key.Curve.Params().BitSize + 7) / 8
+		if len(signature) != 2*size {
+			return ErrInvalidSignature
+		}
+		r := new(big.Int).SetBytes(signature[:size])
+		s := new(big.Int).SetBytes(signature[size:])
+		if !ecdsa.Verify(key, digest, r, s) {
+			return ErrInvalidSignature
+		}
+	default:
+		return ErrUnsupportedAlgorithm
+	}
+	return nil
+}
+
+// decodeSegment は base64url の JSON を読み込む
+func decodeSegment(segment string, v any) error {
+	data, err := base64.RawURLEncoding.DecodeString(segment)
+	if err != nil {
+		return err
+	}
+	return json.Unmarshal(data, v)
+}
+
+// decodeBigInt は base64url の整数を読み込む
+func decodeBigInt(s string) (*big.Int, error) {
+	data, err := base64.RawURLEncoding.DecodeString(s)
+	if err != nil || len(data) == 0 {
+		return nil, errors.New("invalid base64url integer")
+	}
+	return new(big.Int).SetBytes(data), nil
+}
@@ -0,0 +1,258 @@
+package auth
+
+import (
+	"context"
+	"crypto"
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/rsa"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"math/big"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+// testNow はテストで使う現在時刻
+var testNow = time.Date(2025, 5, 12, 9, 0, 0, 0, time.UTC)
+
+// testKey はテスト用の署名鍵
+type testKey struct {
+	kid     string
+	alg     string
+	private crypto.Signer
+}
+
+func newRSAKey(t *testing.T, kid string) testKey {
+	t.Helper()
+	key, err := rsa.GenerateKey(rand.Reader, 2048)
+	if err != nil {
+		t.Fatalf("failed to generate RSA key: %v", err)
+	}
+	return testKey{kid: kid, alg: "RS256", private: key}
+}
+
+func newECKey(t *testing.T, kid string) testKey {
+	t.Helper()
+	key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	if err != nil {
+		t.Fatalf("failed to generate EC key: %v", err)
+	}
+	return testKey{kid: kid, alg: "ES256", private: key}
+}
+
+// jwk はテスト用の鍵を JWK に変換する
+func (k testKey) jwk() map[string]string {
+	encode := func(b []byte) string { return base64.RawURLEncoding.EncodeToString(b) }
+	switch pub := k.private.Public().(type) {
+	case *rsa.PublicKey:
+		return map[string]string{"kty": "RSA", "kid": k.kid, "use": "sig", "alg": k.alg,
+			"n": encode(pub.N.Bytes()), "e": encode(big.NewInt(int64(pub.E)).Bytes())}
+	case *ecdsa.PublicKey:
+		return map[string]string{"kty": "EC", "kid": k.kid, "use": "sig", "alg": k.alg, "crv": "P-256",
+			"x": encode(pub.X.FillBytes(make([]byte, 32))), "y": encode(pub.Y.FillBytes(make([]byte, 32)))}
+	}
+	return nil
+}
+
+// sign はクレームに署名したトークンを作成する
+func (k testKey) sign(t *testing.T, claims map[string]any) string {
+	t.Helper()
+	header, _ := json.Marshal(map[string]string{"alg": k.alg, "typ": "JWT", "kid": k.kid})
+	payload, _ := json.Marshal(claims)
+	input := base64.RawURLEncoding.EncodeToString(header) + "." + base64.RawURLEncoding.EncodeToString(payload)
+	digest := sha256.Sum256([]byte(input))
+
+	var signature []byte
+	switch key := k.private.(type) {
+	case *rsa.PrivateKey:
+		sig, err := rsa.SignPKCS1v15(rand.Reader, key, crypto.SHA256, digest[:])
+		if err != nil {
+			t.Fatalf("failed to sign token: %v", err)
+		}
+		signature = sig
+	case *ecdsa.PrivateKey:
+		r, s, err := ecdsa.Sign(rand.Reader, key, digest[:])
+		if err != nil {
+			t.Fatalf("failed to sign token: %v", err)
+		}
+		signature = append(r.FillBytes(make([]byte, 32)), s.FillBytes(make([]byte, 32))...)
+	}
+	return input + "." + base64.RawURLEncoding.EncodeToString(signature)
+}
+
+func jwksJSON(keys ...testKey) []byte {
+	set := map[string][]map[string]string{"keys": {}}
+	for _, k := range keys {
+		set["keys"] = append(set["keys"], k.jwk())
+	}
+	data, _ := json.Marshal(set)
+	return data
+}
+
+func validClaims() map[string]any {
+	return map[string]any{
+		"sub": "user_123",
+		"iss": "https://issuer.example.com",
+		"aud": "bulktrack",
+		"azp": "https://app.example.com",
+		"sid": "sess_1",
+		"iat": testNow.Add(-time.Minute).Unix(),
+		"nbf": testNow.Add(-time.Minute).Unix(),
+		"exp": testNow.Add(time.Minute).Unix(),
+	}
+}
+
+func newTestVerifier(t *testing.T, opts Options) *JWKSVerifier {
+	t.Helper()
+	v, err := NewJWKSVerifier(opts)
+	if err != nil {
+		t.Fatalf("NewJWKSVerifier() error = %v", err)
+	}
+	v.now = func() time.Time { return testNow }
+	return v
+}
+
+func TestJWKSVerifier_Verify(t *testing.T) {
+	rsaKey := newRSAKey(t, "rsa-1")
+	ecKey := newECKey(t, "ec-1")
+	otherKey := newRSAKey(t, "rsa-1") // 同じ kid の別の鍵 (なりすまし)
+	weak, err := rsa.GenerateKey(rand.Reader, 1024)
+	if err != nil {
+		t.Fatalf("failed to generate RSA key: %v", err)
+	}
+	weakKey := testKey{kid: "rsa-weak", alg: "RS256", private: weak}
+	// alg と鍵の曲線が一致しない (P-256 の鍵で ES384)
+	curveMismatch := ecKey
+	curveMismatch.alg = "ES384"
+	// RSA の alg で EC の鍵を指定する
+	typeMismatch := ecKey
+	typeMismatch.alg = "RS256"
+
+	file := filepath.Join(t.TempDir(), "jwks.json")
+	if err := os.WriteFile(file, jwksJSON(rsaKey, ecKey, weakKey), 0o600); err != nil {
+		t.Fatalf("failed to write JWKS: %v", err)
+	}
+	v := newTestVerifier(t, Options{
+		JWKSFile:          file,
+		Issuer:            "https://issuer.example.com",
+		Audiences:         []string{"bulktrack"},
+		AuthorizedParties: []string{"https://app.example.com"},
+		ClockSkew:         30 * time.Second,
+	})
+
+	with := func(key string, value any) map[string]any {
+		claims := validClaims()
+		if value == nil {
+			delete(claims, key)
+		} else {
+			claims[key] = value
+		}
+		return claims
+	}
+
+	tests := []struct {
+		name    string
+		token   string
+		wantErr error
+	}{
+		{"RS256", rsaKey.sign(t, validClaims()), nil},
+		{"ES256", ecKey.sign(t, validClaims()), nil},
+		{"aud の配列", rsaKey.sign(t, with("aud", []string{"other", "bulktrack"})), nil},
+		{"azp なし", rsaKey.sign(t, with("azp", nil)), nil},
+		{"許容誤差内の期限切れ", rsaKey.sign(t, with("exp", testNow.Add(-20*time.Second).Unix())), nil},
+		{"許容誤差内の nbf", rsaKey.sign(t, with("nbf", testNow.Add(20*time.Second).Unix())), nil},
+		{"期限切れ", rsaKey.sign(t, with("exp", testNow.Add(-time.Minute).Unix())), ErrTokenExpired},
+		{"exp なし", rsaKey.sign(t, with("exp", nil)), ErrTokenExpired},
+		{"nbf より前", rsaKey.sign(t, with("nbf", testNow.Add(time.Minute).Unix())), ErrTokenNotYetValid},
+		{"iss が違う", rsaKey.sign(t, with("iss", "https://evil.example.com")), ErrInvalidIssuer},
+		{"aud が違う", rsaKey.sign(t, with("aud", "other")), ErrInvalidAudience},
+		{"azp が違う", rsaKey.sign(t, with("azp", "https://evil.example.com")), ErrInvalidAuthorizedParty},
+		{"sub なし", rsaKey.sign(t, with("sub", nil)), ErrMissingSubject},
+		{"署名が違う", otherKey.sign(t, validClaims()), ErrInvalidSignature},
+		{"alg none", noneToken(validClaims()), ErrUnsupportedAlgorithm},
+		{"ES384 に P-256 の鍵", curveMismatch.sign(t, validClaims()), ErrUnsupportedAlgorithm},
+		{"RS256 に EC の鍵", typeMismatch.sign(t, validClaims()), ErrUnsupportedAlgorithm},
+		{"2048 ビット未満の RSA 鍵", weakKey.sign(t, validClaims()), ErrUnknownKey},
+		{"形式が不正", "not-a-token", ErrTokenMalformed},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			claims, err := v.Verify(context.Background(), tt.token)
+			if !errors.Is(err, tt.wantErr) {
+				t.Fatalf("Verify() error = %v, want %v", err, tt.wantErr)
+			}
+			if tt.wantErr == nil && claims.Subject != "user_123" {
+				t.Errorf("Subject = %q, want user_123", claims.Subject)
+			}
+		})
+	}
+}
+
+func TestJWKSVerifier_KeyRotation(t *testing.T) {
+	oldKey := newRSAKey(t, "old")
+	newKey := newRSAKey(t, "new")
+
+	var current atomic.Value
+	current.Store(jwksJSON(oldKey))
+	var fetches atomic.Int32
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		fetches.Add(1)
+		w.Write(current.Load().([]byte))
+	}))
+	defer server.Close()
+
+	now := testNow
+	v := newTestVerifier(t, Options{JWKSURL: server.URL})
+	v.now = func() time.Time { return now }
+	ctx := context.Background()
+
+	if _, err := v.Verify(ctx, oldKey.sign(t, validClaims())); err != nil {
+		t.Fatalf("Verify(old) error = %v", err)
+	}
+	if _, err := v.Verify(ctx, oldKey.sign(t, validClaims())); err != nil {
+		t.Fatalf("Verify(old) error = %v", err)
+	}
+	if got := fetches.Load(); got != 1 {
+		t.Fatalf("fetches = %d, want 1 (keys should be cached)", got)
+	}
+
+	// 鍵のローテーション: 直後の未知の kid では再取得しない
+	current.Store(jwksJSON(oldKey, newKey))
+	if _, err := v.Verify(ctx, newKey.sign(t, validClaims())); !errors.Is(err, ErrUnknownKey) {
+		t.Fatalf("Verify(new) error = %v, want %v", err, ErrUnknownKey)
+	}
+
+	// 最小間隔の後は未知の kid で再取得する
+	now = now.Add(time.Minute)
+	v.now = func() time.Time { return now }
+	claims := validClaims()
+	claims["exp"] = now.Add(time.Minute).Unix()
+	if _, err := v.Verify(ctx, newKey.sign(t, claims)); err != nil {
+		t.Fatalf("Verify(new) after rotation error = %v", err)
+	}
+	if got := fetches.Load(); got != 2 {
+		t.Fatalf("fetches = %d, want 2", got)
+	}
+}
+
+func TestNewJWKSVerifier_RequiresSource(t *testing.T) {
+	if _, err := NewJWKSVerifier(Options{}); err == nil {
+		t.Fatal("NewJWKSVerifier() error = nil, want error")
+	}
+}
+
+// noneToken は署名のないトークン (alg: none) を作成する
+func noneToken(claims map[string]any) string {
+	header, _ := json.Marshal(map[string]string{"alg": "none", "kid": "rsa-1"})
+	payload, _ := json.Marshal(claims)
+	return base64.RawURLEncoding.EncodeToString(header) + "." + base64.RawURLEncoding.EncodeToString(payload) + "."
+}
@@ -0,0 +1,95 @@
+package auth
+
+import (
+	"errors"
+	"slices"
+)
+
+// PersonalAccessTokenPrefix はパーソナルアクセストークンの接頭辞 (JWT と見分けるため)
+const PersonalAccessTokenPrefix = "btpat_"
+
+// ErrInvalidPersonalAccessToken は存在しない・失効した・期限切れのパーソナルアクセストークンを表す
+var ErrInvalidPersonalAccessToken = errors.New("invalid personal access token")
+
+// パーソナルアクセストークンのスコープ
+const (
+	ScopeReadWorkouts      = "read:workouts"
+	ScopeWriteWorkouts     = "write:workouts"
+	ScopeWriteSets         = "write:sets"
+	ScopeReadMenus         = "read:menus"
+	ScopeWriteMenus        = "write:menus"
+	ScopeReadExercises     = "read:exercises"
+	ScopeReadVolume        = "read:volume"
+	ScopeWriteVolume       = "write:volume"
+	ScopeReadMeasurements  = "read:measurements"
+	ScopeWriteMeasurements = "write:measurements"
+	ScopeReadExport        = "read:export"
+)
+
+// Scopes は付与できるスコープの一覧
+var Scopes = []string{
+	ScopeReadWorkouts,
+	ScopeWriteWorkouts,
+	ScopeWriteSets,
+	ScopeReadMenus,
+	ScopeWriteMenus,
+	ScopeReadExercises,
+	ScopeReadVolume,
+	ScopeWriteVolume,
+	ScopeReadMeasurements,
+	ScopeWriteMeasurements,
+	ScopeReadExport,
+}
+
+// routeScopes はルート (ServeMux のパターン) ごとに必要なスコープ
+// ここにないルート (アカウント削除やトークンの管理など) はパーソナルアクセストークンでは呼び出せない
+var routeScopes = map[string]string{
+	"GET /workouts":                          ScopeReadWorkouts,
+	"GET /workouts/{id}":                     ScopeReadWorkouts,
+	"GET /history":                           ScopeReadWorkouts,
+	"GET /history/calendar":                  ScopeReadWorkouts,
+	"GET /menus/{id}/exercises/last-records": ScopeReadWorkouts,
+	"POST /workouts":                         ScopeWriteWorkouts,
+	"POST /imports":                          ScopeWriteWorkouts,
+	"PATCH /sets/{id}":                       ScopeWriteSets,
+	"GET /menus":                             ScopeReadMenus,
+	"GET /menus/{id}":                        ScopeReadMenus,
+	"POST /menus":                            ScopeWriteMenus,
+	"PUT /menus/{id}":                        ScopeWriteMenus,
+	"DELETE /menus/{id}":                     ScopeWriteMenus,
+	"GET /shared-menus/{code}":               ScopeReadMenus,
+	"GET /menu-templates":                    ScopeReadMenus,
+	"POST /menus/{id}/share":                 ScopeWriteMenus,
+	"DELETE /menus/{id}/share":               ScopeWriteMenus,
+	"POST /shared-menus/{code}/clone":        ScopeWriteMenus,
+	"GET /exercises":                         ScopeReadExercises,
+	"GET /v1/weekly-volume":                  ScopeReadVolume,
+	"GET /v1/weekly-volume/stats":            ScopeReadVolume,
+	"GET /v1/weekly-volume/{week}":           ScopeReadVolume,
+	"POST /v1/weekly-volume/recalculate":     ScopeWriteVolume,
+	"GET /measurement-metrics":               ScopeReadMeasurements,
+	"GET /measurements":                      ScopeReadMeasurements,
+	"GET /measurements/series":               ScopeReadMeasurements,
+	"GET /measurements/relative-strength":    ScopeReadMeasurements,
+	"POST /measurements":                     ScopeWriteMeasurements,
+	"PATCH /measurements/{id}":               ScopeWriteMeasurements,
+	"DELETE /measurements/{id}":              ScopeWriteMeasurements,
+	"GET /body-weights":                      ScopeReadMeasurements,
+	"POST /body-weights":                     ScopeWriteMeasurements,
+	"DELETE /body-weights/{date}":            ScopeWriteMeasurements,
+	"GET /me/export":                         ScopeReadExport,
+	"POST /me/exports":                       ScopeReadExport,
+	"GET /me/exports/{id}":                   ScopeReadExport,
+	"GET /me/exports/{id}/download":          ScopeReadExport,
+}
+
+// IsValidScope はスコープが付与できるものかを返す
+func IsValidScope(scope string) bool {
+	return slices.Contains(Scopes, scope)
+}
+
+// RequiredScope はルートの呼び出しに必要なスコープを返す (パーソナルアクセストークンで呼び出せない場合は ok が false)
+func RequiredScope(pattern string) (scope string, ok bool) {
+	scope, ok = routeScopes[pattern]
+	return scope, ok
+}
@@ -0,0 +1,23 @@
+package auth
+
+import "testing"
+
+func TestRouteScopesAreValid(t *testing.T) {
+	for pattern, scope := range routeScopes {
+		if !IsValidScope(scope) {
+			t.Errorf("route %q requires unknown scope %q", pattern, scope)
+		}
+	}
+}
+
+func TestRequiredScope(t *testing.T) {
+	if scope, ok := RequiredScope("PATCH /sets/{id}"); !ok || scope != ScopeWriteSets {
+		t.Errorf("RequiredScope(PATCH /sets/{id}) = %q, %v", scope, ok)
+	}
+	// アカウント削除やトークンの管理はパーソナルアクセストークンで呼び出せない
+	for _, pattern := range []string{"DELETE /me", "POST /me/tokens", "DELETE /me/tokens/{id}"} {
+		if _, ok := RequiredScope(pattern); ok {
+			t.Errorf("RequiredScope(%q) ok = true, want false", pattern)
+		}
+	}
+}
@@ -0,0 +1,161 @@
+// Package cache は読み取りの多いデータ (種目の一覧・メニュー・週間ボリューム) のキャッシュを提供する
+//
+// 値はスコープ (ユーザーやメニューなど、まとめて無効にする単位) ごとのトークンを含むキーで保存する。
+// Invalidate はスコープのトークンを新しくするため、それまでの値は読まれなくなり TTL で消える。
+// トークンがストアから消えた場合 (LRU で追い出された場合など) も新しいトークンを作るため、無効にした値を返すことはない
+package cache
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"log/slog"
+	"strings"
+	"time"
+
+	"github.com/aiirononeko/bulktrack/apps/api/internal/telemetry"
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/metric"
+	"go.opentelemetry.io/otel/metric/noop"
+)
+
+// tokenTTL はスコープのトークンの有効期間 (値の TTL より長くする)
+const tokenTTL = 24 * time.Hour
+
+// Store はキャッシュの値を保存する
+// 複数のインスタンスで無効化を共有する場合は、共有のストア (Redis) を使う
+type Store interface {
+	// Get は key の値を返す (ない場合や期限切れの場合は ok が false)
+	Get(ctx context.Context, key string) (value []byte, ok bool, err error)
+	// Set は key に値を保存する (ttl が経過すると消える)
+	Set(ctx context.Context, key string, value []byte, ttl time.Duration) error
+	// Add は key に値がない場合のみ保存する (保存した場合は true)
+	Add(ctx context.Context, key string, value []byte, ttl time.Duration) (bool, error)
+}
+
+// requests はキャッシュの参照の数 (Prometheus では bulktrack_cache_requests_total、scope 属性はスコープの種類)
+var requests = newRequestCounter()
+
+func newRequestCounter() metric.Int64Counter {
+	meter := otel.Meter(telemetry.InstrumentationName + "/cache")
+	counter, err := meter.Int64Counter("bulktrack.cache.requests", metric.WithDescription("Number of cache lookups by result (hit / miss / error)"), metric.WithUnit("{request}"))
+	if err != nil {
+		otel.Handle(err)
+		return noop.Int64Counter{}
+	}
+	return counter
+}
+
+// Cache はストアに JSON で値を保存する (nil の Cache は常に読み込み直す)
+type Cache struct {
+	store  Store
+	logger *slog.Logger
+}
+
+// New は新しい Cache を作成する (store が nil の場合はキャッシュしない nil を返す)
+func New(store Store, logger *slog.Logger) *Cache {
+	if store == nil {
+		return nil
+	}
+	return &Cache{store: store, logger: logger}
+}
+
+// Load はスコープの key の値を返す。キャッシュにない場合は load で読み込んで ttl の間保存する
+// ストアのエラーはログに出力してキャッシュがない場合と同じように読み込む (キャッシュの障害でリクエストを失敗させない)
+func Load[T any](ctx context.Context, c *Cache, scope, key string, ttl time.Duration, load func(context.Context) (T, error)) (T, error) {
+	if c == nil {
+		return load(ctx)
+	}
+	kind := attribute.String("scope", scopeKind(scope))
+
+	token, err := c.token(ctx, scope)
+	if err != nil {
+		c.logger.WarnContext(ctx, "Failed to get cache token", slog.Any("error", err), slog.String("scope", scope))
+		requests.Add(ctx, 1, metric.WithAttributes(kind, attribute.String("result", "error")))
+		return load(ctx)
+	}
+	fullKey := scope + ":" + token + ":" + key
+
+	if data, ok, err := c.store.Get(ctx, fullKey); err != nil {
+		c.logger.WarnContext(ctx, "Failed to get cached value", slog.Any("error", err), slog.String("key", fullKey))
+	} else if ok {
+		var v T
+		if err := json.Unmarshal(data, &v); err == nil {
+			requests.Add(ctx, 1, metric.WithAttributes(kind, attribute.String("result", "hit")))
+			return v, nil
+		}
+		c.logger.WarnContext(ctx, "Failed to decode cached value", slog.Any("error", err), slog.String("key", fullKey))
+	}
+	requests.Add(ctx, 1, metric.WithAttributes(kind, attribute.String("result", "miss")))
+
+	v, err := load(ctx)
+	if err != nil {
+		return v, err
+	}
+	data, err := json.Marshal(v)
+	if err != nil {
+		c.logger.WarnContext(ctx, "Failed to encode value for cache", slog.Any("error", err), slog.String("key", fullKey))
+		return v, nil
+	}
+	if err := c.store.Set(ctx, fullKey, data, ttl); err != nil {
+		c.logger.WarnContext(ctx, "Failed to cache value", slog.Any("error", err), slog.String("key", fullKey))
+	}
+	return v, nil
+}
+
+// Invalidate はスコープの値をすべて無効にする (データを変更したトランザクションのコミットの後に呼び出す)
+// 失敗した場合は古い値が TTL の間返るため、ログに出力する
+func (c *Cache) Invalidate(ctx context.Context, scopes ...string) {
+	if c == nil {
+		return
+	}
+	for _, scope := range scopes {
+		if err := c.store.Set(ctx, tokenKey(scope), []byte(newToken()), tokenTTL); err != nil {
+			c.logger.ErrorContext(ctx, "Failed to invalidate cache", slog.Any("error", err), slog.String("scope", scope))
+		}
+	}
+}
+
+// token はスコープの現在のトークンを返す (ない場合は作成する)
+// 作成は値がない場合のみ保存するため、同時に Invalidate されても新しいトークンを上書きしない
+func (c *Cache) token(ctx context.Context, scope string) (string, error) {
+	key := tokenKey(scope)
+	for range 2 {
+		token, ok, err := c.store.Get(ctx, key)
+		if err != nil {
+			return "", err
+		}
+		if ok {
+			return string(token), nil
+		}
+		token = []byte(newToken())
+		added, err := c.store.Add(ctx, key, token, tokenTTL)
+		if err != nil {
+			return "", err
+		}
+		if added {
+			return string(token), nil
+		}
+		// 他のリクエストが先に作成したトークンを読み直す
+	}
+	return "", fmt.Errorf("cache token for %s changed while reading", scope)
+}
+
+func tokenKey(scope string) string {
+	return scope + ":token"
+}
+
+// scopeKind はスコープのメトリクスの属性 (ID を除いた "menu" などの部分) を返す
+func scopeKind(scope string) string {
+	kind, _, _ := strings.Cut(scope, ":")
+	return kind
+}
+
+func newToken() string {
+	b := make([]byte, 8)
+	_, _ = rand.Read(b)
+	return hex.EncodeToString(b)
+}
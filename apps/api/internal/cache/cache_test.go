@@ -0,0 +1,164 @@
+package cache
+
+import (
+	"context"
+	"errors"
+	"io"
+	"log/slog"
+	"testing"
+	"time"
+)
+
+var discardLogger = slog.New(slog.NewTextHandler(io.Discard, nil))
+
+func TestMemoryStore_EvictsLeastRecentlyUsed(t *testing.T) {
+	ctx := context.Background()
+	s := NewMemoryStore(2)
+
+	s.Set(ctx, "a", []byte("1"), time.Minute)
+	s.Set(ctx, "b", []byte("2"), time.Minute)
+	s.Get(ctx, "a") // b が最も長く参照されていない値になる
+	s.Set(ctx, "c", []byte("3"), time.Minute)
+
+	if _, ok, _ := s.Get(ctx, "b"); ok {
+		t.Error("least recently used entry was not evicted")
+	}
+	for _, key := range []string{"a", "c"} {
+		if _, ok, _ := s.Get(ctx, key); !ok {
+			t.Errorf("entry %q was evicted", key)
+		}
+	}
+	if s.Len() != 2 {
+		t.Errorf("Len() = %d, want 2", s.Len())
+	}
+}
+
+func TestMemoryStore_Expiry(t *testing.T) {
+	ctx := context.Background()
+	now := time.Date(2025, 5, 15, 12, 0, 0, 0, time.UTC)
+	s := NewMemoryStore(10)
+	s.now = func() time.Time { return now }
+
+	s.Set(ctx, "a", []byte("1"), time.Minute)
+	if added, _ := s.Add(ctx, "a", []byte("2"), time.Minute); added {
+		t.Error("Add() overwrote an existing entry")
+	}
+
+	now = now.Add(time.Minute)
+	if _, ok, _ := s.Get(ctx, "a"); ok {
+		t.Error("expired entry was returned")
+	}
+	if added, _ := s.Add(ctx, "a", []byte("2"), time.Minute); !added {
+		t.Error("Add() did not replace an expired entry")
+	}
+	if v, _, _ := s.Get(ctx, "a"); string(v) != "2" {
+		t.Errorf("Get() = %q, want %q", v, "2")
+	}
+}
+
+type summary struct {
+	Total float64 `json:"total"`
+}
+
+func TestLoad_CachesUntilInvalidated(t *testing.T) {
+	ctx := context.Background()
+	c := New(NewMemoryStore(10), discardLogger)
+
+	loads := 0
+	load := func(context.Context) (summary, error) {
+		loads++
+		return summary{Total: float64(loads * 100)}, nil
+	}
+
+	for range 2 {
+		got, err := Load(ctx, c, "volume:user_1", "weeks:12", time.Minute, load)
+		if err != nil {
+			t.Fatalf("Load() error = %v", err)
+		}
+		if got.Total != 100 || loads != 1 {
+			t.Fatalf("Load() = %v after %d loads, want cached {100} after 1 load", got, loads)
+		}
+	}
+
+	// 他のスコープの無効化は影響しない
+	c.Invalidate(ctx, "volume:user_2")
+	if got, _ := Load(ctx, c, "volume:user_1", "weeks:12", time.Minute, load); got.Total != 100 {
+		t.Errorf("Load() = %v after invalidating another scope, want {100}", got)
+	}
+
+	c.Invalidate(ctx, "volume:user_1")
+	if got, _ := Load(ctx, c, "volume:user_1", "weeks:12", time.Minute, load); got.Total != 200 {
+		t.Errorf("Load() = %v after Invalidate, want reloaded {200}", got)
+	}
+}
+
+func TestLoad_EvictedTokenDoesNotServeInvalidatedValue(t *testing.T) {
+	ctx := context.Background()
+	store := NewMemoryStore(10)
+	c := New(store, discardLogger)
+
+	total := 100.0
+	load := func(context.Context) (summary, error) { return summary{Total: total}, nil }
+
+	Load(ctx, c, "menu:m1", "", time.Minute, load)
+	c.Invalidate(ctx, "menu:m1")
+	total = 200
+	Load(ctx, c, "menu:m1", "", time.Minute, load)
+
+	// トークンだけが追い出された場合も、以前のトークンの値は返さない
+	store.mu.Lock()
+	store.remove(store.entries[tokenKey("menu:m1")])
+	store.mu.Unlock()
+	total = 300
+	if got, _ := Load(ctx, c, "menu:m1", "", time.Minute, load); got.Total != 300 {
+		t.Errorf("Load() = %v after the token was evicted, want reloaded {300}", got)
+	}
+}
+
+func TestLoad_DoesNotCacheErrors(t *testing.T) {
+	ctx := context.Background()
+	c := New(NewMemoryStore(10), discardLogger)
+
+	errLoad := errors.New("db unavailable")
+	if _, err := Load(ctx, c, "exercises", "", time.Minute, func(context.Context) (summary, error) {
+		return summary{}, errLoad
+	}); !errors.Is(err, errLoad) {
+		t.Fatalf("Load() error = %v, want %v", err, errLoad)
+	}
+	got, err := Load(ctx, c, "exercises", "", time.Minute, func(context.Context) (summary, error) {
+		return summary{Total: 1}, nil
+	})
+	if err != nil || got.Total != 1 {
+		t.Errorf("Load() = %v, %v after a failed load, want {1}, nil", got, err)
+	}
+}
+
+type failingStore struct{}
+
+func (failingStore) Get(context.Context, string) ([]byte, bool, error) {
+	return nil, false, errors.New("connection refused")
+}
+
+func (failingStore) Set(context.Context, string, []byte, time.Duration) error {
+	return errors.New("connection refused")
+}
+
+func (failingStore) Add(context.Context, string, []byte, time.Duration) (bool, error) {
+	return false, errors.New("connection refused")
+}
+
+func TestLoad_FallsBackWhenStoreFails(t *testing.T) {
+	ctx := context.Background()
+	load := func(context.Context) (summary, error) { return summary{Total: 1}, nil }
+
+	for name, c := range map[string]*Cache{
+		"failing store": New(failingStore{}, discardLogger),
+		"disabled":      New(nil, discardLogger),
+	} {
+		got, err := Load(ctx, c, "exercises", "", time.Minute, load)
+		if err != nil || got.Total != 1 {
+			t.Errorf("%s: Load() = %v, %v, want {1}, nil", name, got, err)
+		}
+		c.Invalidate(ctx, "exercises")
+	}
+}
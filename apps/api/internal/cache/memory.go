@@ -0,0 +1,115 @@
+package cache
+
+import (
+	"container/list"
+	"context"
+	"sync"
+	"time"
+)
+
+// DefaultMemoryEntries は MemoryStore に保存する値の数の既定値
+const DefaultMemoryEntries = 10000
+
+type memoryEntry struct {
+	key       string
+	value     []byte
+	expiresAt time.Time
+}
+
+// MemoryStore はプロセスのメモリ上に値を保存する LRU (インスタンスごとのキャッシュになる)
+// 保存する値の数が上限を超えた場合は、最も長く参照されていない値から削除する
+type MemoryStore struct {
+	mu         sync.Mutex
+	maxEntries int
+	entries    map[string]*list.Element
+	order      *list.List // 先頭が最も最近参照された値
+	now        func() time.Time
+}
+
+// NewMemoryStore は最大 maxEntries 個の値を保存する MemoryStore を作成する (0 以下の場合は DefaultMemoryEntries)
+func NewMemoryStore(maxEntries int) *MemoryStore {
+	if maxEntries <= 0 {
+		maxEntries = DefaultMemoryEntries
+	}
+	return &MemoryStore{
+		maxEntries: maxEntries,
+		entries:    make(map[string]*list.Element),
+		order:      list.New(),
+		now:        time.Now,
+	}
+}
+
+// Get は key の値を返す
+func (s *MemoryStore) Get(_ context.Context, key string) ([]byte, bool, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	e, ok := s.lookup(key)
+	if !ok {
+		return nil, false, nil
+	}
+	return e.value, true, nil
+}
+
+// Set は key に値を保存する
+func (s *MemoryStore) Set(_ context.Context, key string, value []byte, ttl time.Duration) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	s.store(key, value, ttl)
+	return nil
+}
+
+// Add は key に値がない場合のみ保存する
+func (s *MemoryStore) Add(_ context.Context, key string, value []byte, ttl time.Duration) (bool, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if _, ok := s.lookup(key); ok {
+		return false, nil
+	}
+	s.store(key, value, ttl)
+	return true, nil
+}
+
+// Len は保存している値の数を返す (期限切れで未削除の値を含む)
+func (s *MemoryStore) Len() int {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.order.Len()
+}
+
+// lookup は期限内の値を返し、最も最近参照された値にする (期限切れの値は削除する)
+func (s *MemoryStore) lookup(key string) (*memoryEntry, bool) {
+	elem, ok := s.entries[key]
+	if !ok {
+		return nil, false
+	}
+	e := elem.Value.(*memoryEntry)
+	if !s.now().Before(e.expiresAt) {
+		s.remove(elem)
+		return nil, false
+	}
+	s.order.MoveToFront(elem)
+	return e, true
+}
+
+func (s *MemoryStore) store(key string, value []byte, ttl time.Duration) {
+	expiresAt := s.now().Add(ttl)
+	if elem, ok := s.entries[key]; ok {
+		e := elem.Value.(*memoryEntry)
+		e.value = value
+		e.expiresAt = expiresAt
+		s.order.MoveToFront(elem)
+		return
+	}
+	s.entries[key] = s.order.PushFront(&memoryEntry{key: key, value: value, expiresAt: expiresAt})
+	for s.order.Len() > s.maxEntries {
+		s.remove(s.order.Back())
+	}
+}
+
+func (s *MemoryStore) remove(elem *list.Element) {
+	s.order.Remove(elem)
+	delete(s.entries, elem.Value.(*memoryEntry).key)
+}
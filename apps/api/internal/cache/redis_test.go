@@ -0,0 +1,158 @@
+package cache
+
+import (
+	"bufio"
+	"context"
+	"fmt"
+	"net"
+	"strconv"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+)
+
+// fakeRedis は GET / SET / AUTH / SELECT のみに応答するテスト用の Redis サーバー
+type fakeRedis struct {
+	ln       net.Listener
+	password string
+
+	mu       sync.Mutex
+	data     map[string]string
+	commands []string
+}
+
+func newFakeRedis(t *testing.T, password string) *fakeRedis {
+	t.Helper()
+	ln, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatalf("failed to listen: %v", err)
+	}
+	f := &fakeRedis{ln: ln, password: password, data: make(map[string]string)}
+	t.Cleanup(func() { ln.Close() })
+	go func() {
+		for {
+			conn, err := ln.Accept()
+			if err != nil {
+				return
+			}
+			go f.serve(conn)
+		}
+	}()
+	return f
+}
+
+func (f *fakeRedis) serve(conn net.Conn) {
+	defer conn.Close()
+	r := bufio.NewReader(conn)
+	authed := f.password == ""
+	for {
+		reply, err := readReply(r)
+		if err != nil {
+			return
+		}
+		items := reply.([]any)
+		args := make([]string, len(items))
+		for i, item := range items {
+			args[i] = string(item.([]byte))
+		}
+
+		f.mu.Lock()
+		f.commands = append(f.commands, strings.Join(args, " "))
+		var out string
+		switch {
+		case args[0] == "AUTH":
+			if args[len(args)-1] == f.password {
+				authed = true
+				out = "+OK\r\n"
+			} else {
+				out = "-WRONGPASS invalid password\r\n"
+			}
+		case !authed:
+			out = "-NOAUTH Authentication required.\r\n"
+		case args[0] == "SELECT":
+			out = "+OK\r\n"
+		case args[0] == "GET":
+			if v, ok := f.data[args[1]]; ok {
+				out = fmt.Sprintf("$%d\r\n%s\r\n", len(v), v)
+			} else {
+				out = "$-1\r\n"
+			}
+		case args[0] == "SET":
+			_, exists := f.data[args[1]]
+			if exists && args[len(args)-1] == "NX" {
+				out = "$-1\r\n"
+			} else {
+				f.data[args[1]] = args[2]
+				out = "+OK\r\n"
+			}
+		default:
+			out = "-ERR unknown command\r\n"
+		}
+		f.mu.Unlock()
+
+		if _, err := conn.Write([]byte(out)); err != nil {
+			return
+		}
+	}
+}
+
+func TestRedisStore(t *testing.T) {
+	ctx := context.Background()
+	f := newFakeRedis(t, "secret")
+	s, err := NewRedisStore("redis://:secret@" + f.ln.Addr().String() + "/2")
+	if err != nil {
+		t.Fatalf("NewRedisStore() error = %v", err)
+	}
+	defer s.Close()
+
+	if _, ok, err := s.Get(ctx, "missing"); err != nil || ok {
+		t.Fatalf("Get(missing) = ok %v, err %v, want false, nil", ok, err)
+	}
+	if err := s.Set(ctx, "k", []byte("v\r\n1"), time.Minute); err != nil {
+		t.Fatalf("Set() error = %v", err)
+	}
+	if v, ok, err := s.Get(ctx, "k"); err != nil || !ok || string(v) != "v\r\n1" {
+		t.Fatalf("Get(k) = %q, %v, %v, want %q", v, ok, err, "v\r\n1")
+	}
+	if added, err := s.Add(ctx, "k", []byte("other"), time.Minute); err != nil || added {
+		t.Errorf("Add(existing) = %v, %v, want false, nil", added, err)
+	}
+	if added, err := s.Add(ctx, "new", []byte("v"), time.Minute); err != nil || !added {
+		t.Errorf("Add(new) = %v, %v, want true, nil", added, err)
+	}
+
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	want := []string{"AUTH secret", "SELECT 2", "GET missing", "SET k v\r\n1 PX " + strconv.Itoa(60000)}
+	for i, cmd := range want {
+		if f.commands[i] != cmd {
+			t.Errorf("command %d = %q, want %q", i, f.commands[i], cmd)
+		}
+	}
+	// 接続は再利用する (AUTH は1回のみ)
+	if n := strings.Count(strings.Join(f.commands, "\n"), "AUTH"); n != 1 {
+		t.Errorf("AUTH sent %d times, want 1", n)
+	}
+}
+
+func TestRedisStore_ServerError(t *testing.T) {
+	f := newFakeRedis(t, "secret")
+	s, err := NewRedisStore("redis://:wrong@" + f.ln.Addr().String())
+	if err != nil {
+		t.Fatalf("NewRedisStore() error = %v", err)
+	}
+	defer s.Close()
+
+	if _, _, err := s.Get(context.Background(), "k"); err == nil {
+		t.Error("Get() with a wrong password succeeded")
+	}
+}
+
+func TestNewRedisStore_InvalidURL(t *testing.T) {
+	for _, rawURL := range []string{"http://localhost:6379", "redis://", "redis://localhost/db"} {
+		if _, err := NewRedisStore(rawURL); err == nil {
+			t.Errorf("NewRedisStore(%q) succeeded, want error", rawURL)
+		}
+	}
+}
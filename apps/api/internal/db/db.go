@@ -8,6 +8,7 @@ import (
 	"time" // pgxpool.Config で使用
 
 	// _ "github.com/jackc/pgx/v5/stdlib" // 削除
+	"github.com/aiirononeko/bulktrack/apps/api/internal/telemetry"
 	"github.com/jackc/pgx/v5/pgxpool" // 追加
 )
 
@@ -30,6 +31,9 @@ func New() (*pgxpool.Pool, error) { // 戻り値を *pgxpool.Pool に変更
 	config.MaxConns = 10                      // 最大接続数
 	config.MaxConnIdleTime = 30 * time.Minute // アイドル接続の最大時間
 
+	// クエリごとに OpenTelemetry のスパンを作成
+	config.ConnConfig.Tracer = telemetry.NewPgxTracer()
+
 	// pgxpool.NewWithConfig でプールを作成
 	pool, err := pgxpool.NewWithConfig(ctx, config)
 	if err != nil {
@@ -0,0 +1,114 @@
+package handler
+
+import (
+	"encoding/json"
+	"fmt"
+	"log/slog"
+	"net/http"
+
+	httpError "github.com/aiirononeko/bulktrack/apps/api/internal/http"
+	"github.com/aiirononeko/bulktrack/apps/api/internal/interfaces/http/middleware"
+	"github.com/aiirononeko/bulktrack/apps/api/internal/interfaces/service"
+)
+
+// AccountHandler はアカウント (削除) 関連のハンドラーを提供する
+type AccountHandler struct {
+	accountDeletionService *service.AccountDeletionService
+	logger                 *slog.Logger
+}
+
+// NewAccountHandler は新しいAccountHandlerを作成する
+func NewAccountHandler(accountDeletionService *service.AccountDeletionService, logger *slog.Logger) *AccountHandler {
+	return &AccountHandler{
+		accountDeletionService: accountDeletionService,
+		logger:                 logger,
+	}
+}
+
+// RegisterRoutes はルートを登録する
+func (h *AccountHandler) RegisterRoutes(mux Router, logging, auth func(http.Handler) http.Handler) {
+	mux.Handle("DELETE /me", logging(auth(http.HandlerFunc(h.handleRequestDeletion))))
+	mux.Handle("GET /me/deletion", logging(auth(http.HandlerFunc(h.handleGetDeletion))))
+	mux.Handle("POST /me/deletion/cancel", logging(auth(http.HandlerFunc(h.handleCancelDeletion))))
+}
+
+// handleRequestDeletion はアカウントの削除を予約するハンドラー
+// 猶予期間 (30日) の後にすべてのデータを削除する。それまでは POST /me/deletion/cancel で取り消せる
+func (h *AccountHandler) handleRequestDeletion(w http.ResponseWriter, r *http.Request) {
+	// コンテキストからユーザーIDを取得
+	userIDStr, ok := middleware.GetUserIDFromContext(r.Context())
+	if !ok {
+		h.logger.Error("User ID not found in context")
+		http.Error(w, "Unauthorized", http.StatusUnauthorized)
+		return
+	}
+
+	deletion, err := h.accountDeletionService.RequestDeletion(r.Context(), userIDStr, service.AccountDeletionSourceAPI)
+	if err != nil {
+		if httpError.IsAppError(err) {
+			httpError.WriteError(w, err)
+			return
+		}
+		h.logger.Error("Failed to request account deletion", slog.Any("error", err), slog.String("user_id", userIDStr))
+		http.Error(w, fmt.Sprintf("Failed to request account deletion: %v", err), http.StatusInternalServerError)
+		return
+	}
+
+	// レスポンス返却
+	w.Header().Set("Content-Type", "application/json")
+	w.Header().Set("Location", "/me/deletion")
+	w.WriteHeader(http.StatusAccepted)
+	json.NewEncoder(w).Encode(deletion)
+}
+
+// handleGetDeletion は予約中のアカウント削除を取得するハンドラー
+func (h *AccountHandler) handleGetDeletion(w http.ResponseWriter, r *http.Request) {
+	// コンテキストからユーザーIDを取得
+	userIDStr, ok := middleware.GetUserIDFromContext(r.Context())
+	if !ok {
+		h.logger.Error("User ID not found in context")
+		http.Error(w, "Unauthorized", http.StatusUnauthorized)
+		return
+	}
+
+	deletion, err := h.accountDeletionService.GetDeletion(r.Context(), userIDStr)
+	if err != nil {
+		if httpError.IsAppError(err) {
+			httpError.WriteError(w, err)
+			return
+		}
+		h.logger.Error("Failed to get account deletion", slog.Any("error", err), slog.String("user_id", userIDStr))
+		http.Error(w, fmt.Sprintf("Failed to get account deletion: %v", err), http.StatusInternalServerError)
+		return
+	}
+
+	// レスポンス返却
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(deletion)
+}
+
+// handleCancelDeletion は予約中のアカウント削除を取り消すハンドラー
+func (h *AccountHandler) handleCancelDeletion(w http.ResponseWriter, r *http.Request) {
+	// コンテキストからユーザーIDを取得
+	userIDStr, ok := middleware.GetUserIDFromContext(r.Context())
+	if !ok {
+		h.logger.Error("User ID not found in context")
+		http.Error(w, "Unauthorized", http.StatusUnauthorized)
+		return
+	}
+
+	deletion, err := h.accountDeletionService.CancelDeletion(r.Context(), userIDStr)
+	if err != nil {
+		if httpError.IsAppError(err) {
+			httpError.WriteError(w, err)
+			return
+		}
+		h.logger.Error("Failed to cancel account deletion", slog.Any("error", err), slog.String("user_id", userIDStr))
+		http.Error(w, fmt.Sprintf("Failed to cancel account deletion: %v", err), http.StatusInternalServerError)
+		return
+	}
+
+	// レスポンス返却
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(deletion)
+}
@@ -0,0 +1,79 @@
+package handler
+
+import (
+	"context"
+	"io"
+	"log/slog"
+	"net/http"
+
+	httpError "github.com/aiirononeko/bulktrack/apps/api/internal/http"
+	"github.com/aiirononeko/bulktrack/apps/api/internal/interfaces/service"
+	"github.com/aiirononeko/bulktrack/apps/api/internal/webhook"
+)
+
+// maxWebhookBodyBytes は Webhook の本文の最大サイズ
+const maxWebhookBodyBytes = 1 << 20
+
+// clerkWebhookService は ClerkWebhookHandler が利用する Webhook イベント処理のインターフェース
+type clerkWebhookService interface {
+	HandleEvent(ctx context.Context, eventID string, payload []byte) error
+}
+
+// ClerkWebhookHandler は Clerk の Webhook を受け取るハンドラーを提供する
+type ClerkWebhookHandler struct {
+	verifier       *webhook.SvixVerifier // 署名シークレット未設定の場合は nil
+	webhookService clerkWebhookService
+	logger         *slog.Logger
+}
+
+// NewClerkWebhookHandler は新しいClerkWebhookHandlerを作成する
+func NewClerkWebhookHandler(verifier *webhook.SvixVerifier, webhookService *service.ClerkWebhookService, logger *slog.Logger) *ClerkWebhookHandler {
+	return &ClerkWebhookHandler{
+		verifier:       verifier,
+		webhookService: webhookService,
+		logger:         logger,
+	}
+}
+
+// RegisterRoutes はルートを登録する (Svix の署名で検証するため JWT 認証は行わず、public で IP アドレスごとにレート制限する)
+func (h *ClerkWebhookHandler) RegisterRoutes(mux Router, logging, public func(http.Handler) http.Handler) {
+	mux.Handle("POST /webhooks/clerk", logging(public(http.HandlerFunc(h.handleWebhook))))
+}
+
+// handleWebhook は Clerk の Webhook を受け取るハンドラー
+//
+// 署名 (svix-signature) とタイムスタンプ (svix-timestamp) を検証してからイベントを処理する。
+// 処理済みのイベント (同じ svix-id) の再配信には何もせずに 204 を返す。
+// 処理に失敗した場合は 500 を返し、Svix の再配信とサーバー側の再試行の両方で再処理する
+func (h *ClerkWebhookHandler) handleWebhook(w http.ResponseWriter, r *http.Request) {
+	if h.verifier == nil {
+		h.logger.Error("Clerk webhook secret is not configured")
+		http.Error(w, "Webhook is not configured", http.StatusServiceUnavailable)
+		return
+	}
+
+	body, err := io.ReadAll(http.MaxBytesReader(w, r.Body, maxWebhookBodyBytes))
+	if err != nil {
+		h.logger.Warn("Failed to read webhook body", slog.Any("error", err))
+		http.Error(w, "Invalid request body", http.StatusBadRequest)
+		return
+	}
+	eventID := r.Header.Get(webhook.SvixIDHeader)
+	if err := h.verifier.Verify(r.Header, body); err != nil {
+		h.logger.Warn("Invalid webhook signature", slog.Any("error", err), slog.String("svix_id", eventID))
+		http.Error(w, "Invalid signature", http.StatusUnauthorized)
+		return
+	}
+
+	if err := h.webhookService.HandleEvent(r.Context(), eventID, body); err != nil {
+		if httpError.IsAppError(err) {
+			httpError.WriteError(w, err)
+			return
+		}
+		h.logger.Error("Failed to handle webhook event", slog.Any("error", err), slog.String("svix_id", eventID))
+		http.Error(w, "Failed to handle webhook event", http.StatusInternalServerError)
+		return
+	}
+
+	w.WriteHeader(http.StatusNoContent)
+}
@@ -0,0 +1,123 @@
+package handler
+
+import (
+	"bytes"
+	"context"
+	"encoding/base64"
+	"errors"
+	"log/slog"
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"testing"
+	"time"
+
+	"github.com/aiirononeko/bulktrack/apps/api/internal/webhook"
+)
+
+// mockClerkWebhookService はテスト用のモックサービス
+type mockClerkWebhookService struct {
+	calls []string // 処理したイベントID
+	err   error
+}
+
+// HandleEvent はモックの実装
+func (m *mockClerkWebhookService) HandleEvent(ctx context.Context, eventID string, payload []byte) error {
+	m.calls = append(m.calls, eventID)
+	return m.err
+}
+
+// newSignedWebhookRequest はローカルで署名した Webhook リクエストを作成する
+func newSignedWebhookRequest(t *testing.T, verifier *webhook.SvixVerifier, id string, timestamp time.Time, body []byte) *http.Request {
+	t.Helper()
+	req := httptest.NewRequest(http.MethodPost, "/webhooks/clerk", bytes.NewReader(body))
+	req.Header.Set(webhook.SvixIDHeader, id)
+	req.Header.Set(webhook.SvixTimestampHeader, strconv.FormatInt(timestamp.Unix(), 10))
+	req.Header.Set(webhook.SvixSignatureHeader, "v1,"+verifier.Sign(id, timestamp.Unix(), body))
+	return req
+}
+
+func TestClerkWebhook_Signature(t *testing.T) {
+	verifier, err := webhook.NewSvixVerifier("whsec_" + base64.StdEncoding.EncodeToString([]byte("bulktrack-test-signing-secret")))
+	if err != nil {
+		t.Fatalf("NewSvixVerifier() error = %v", err)
+	}
+	body := []byte(`{"type":"user.created","data":{"id":"user_123"}}`)
+
+	tests := []struct {
+		name       string
+		request    func() *http.Request
+		serviceErr error
+		wantStatus int
+		wantCalls  int
+	}{
+		{
+			name:       "valid signature",
+			request:    func() *http.Request { return newSignedWebhookRequest(t, verifier, "msg_1", time.Now(), body) },
+			wantStatus: http.StatusNoContent,
+			wantCalls:  1,
+		},
+		{
+			name: "tampered body",
+			request: func() *http.Request {
+				req := newSignedWebhookRequest(t, verifier, "msg_1", time.Now(), body)
+				req.Body = http.NoBody
+				return req
+			},
+			wantStatus: http.StatusUnauthorized,
+		},
+		{
+			name: "replayed old delivery",
+			request: func() *http.Request {
+				return newSignedWebhookRequest(t, verifier, "msg_1", time.Now().Add(-time.Hour), body)
+			},
+			wantStatus: http.StatusUnauthorized,
+		},
+		{
+			name: "unsigned request",
+			request: func() *http.Request {
+				return httptest.NewRequest(http.MethodPost, "/webhooks/clerk", bytes.NewReader(body))
+			},
+			wantStatus: http.StatusUnauthorized,
+		},
+		{
+			name:       "processing failure is retried by the sender",
+			request:    func() *http.Request { return newSignedWebhookRequest(t, verifier, "msg_1", time.Now(), body) },
+			serviceErr: errors.New("database is down"),
+			wantStatus: http.StatusInternalServerError,
+			wantCalls:  1,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			svc := &mockClerkWebhookService{err: tt.serviceErr}
+			h := &ClerkWebhookHandler{verifier: verifier, webhookService: svc, logger: slog.Default()}
+
+			rr := httptest.NewRecorder()
+			h.handleWebhook(rr, tt.request())
+
+			if rr.Code != tt.wantStatus {
+				t.Errorf("status = %d, want %d", rr.Code, tt.wantStatus)
+			}
+			if len(svc.calls) != tt.wantCalls {
+				t.Errorf("HandleEvent calls = %d, want %d", len(svc.calls), tt.wantCalls)
+			}
+		})
+	}
+}
+
+func TestClerkWebhook_NotConfigured(t *testing.T) {
+	svc := &mockClerkWebhookService{}
+	h := &ClerkWebhookHandler{webhookService: svc, logger: slog.Default()}
+
+	rr := httptest.NewRecorder()
+	h.handleWebhook(rr, httptest.NewRequest(http.MethodPost, "/webhooks/clerk", bytes.NewReader([]byte(`{}`))))
+
+	if rr.Code != http.StatusServiceUnavailable {
+		t.Errorf("status = %d, want %d", rr.Code, http.StatusServiceUnavailable)
+	}
+	if len(svc.calls) != 0 {
+		t.Errorf("HandleEvent calls = %d, want 0", len(svc.calls))
+	}
+}
@@ -0,0 +1,186 @@
+package handler
+
+import (
+	"encoding/json"
+	"fmt"
+	"log/slog"
+	"net/http"
+
+	httpError "github.com/aiirononeko/bulktrack/apps/api/internal/http"
+	"github.com/aiirononeko/bulktrack/apps/api/internal/interfaces/http/dto"
+	"github.com/aiirononeko/bulktrack/apps/api/internal/interfaces/http/middleware"
+	"github.com/aiirononeko/bulktrack/apps/api/internal/interfaces/service"
+	"github.com/google/uuid"
+)
+
+// CoachHandler はコーチとアスリートの共有関連のハンドラーを提供する
+type CoachHandler struct {
+	coachService *service.CoachService
+	logger       *slog.Logger
+}
+
+// NewCoachHandler は新しいCoachHandlerを作成する
+func NewCoachHandler(coachService *service.CoachService, logger *slog.Logger) *CoachHandler {
+	return &CoachHandler{
+		coachService: coachService,
+		logger:       logger,
+	}
+}
+
+// RegisterRoutes はルートを登録する
+// コーチがアスリートのデータにアクセスする際は、既存のルートに X-Athlete-ID ヘッダーを付けて呼び出す
+func (h *CoachHandler) RegisterRoutes(mux Router, logging, auth func(http.Handler) http.Handler) {
+	mux.Handle("GET /coach-grants", logging(auth(http.HandlerFunc(h.handleListGrants))))
+	mux.Handle("POST /coach-grants", logging(auth(http.HandlerFunc(h.handleCreateInvitation))))
+	mux.Handle("POST /coach-grants/accept", logging(auth(http.HandlerFunc(h.handleAcceptInvitation))))
+	mux.Handle("DELETE /coach-grants/{id}", logging(auth(http.HandlerFunc(h.handleRevokeGrant))))
+	mux.Handle("GET /coach-grants/{id}/audit-logs", logging(auth(http.HandlerFunc(h.handleListAuditLogs))))
+}
+
+// handleCreateInvitation はアスリートがコーチへの招待コードを発行するハンドラー
+func (h *CoachHandler) handleCreateInvitation(w http.ResponseWriter, r *http.Request) {
+	// コンテキストからユーザーIDを取得
+	userIDStr, ok := middleware.GetUserIDFromContext(r.Context())
+	if !ok {
+		h.logger.Error("User ID not found in context")
+		http.Error(w, "Unauthorized", http.StatusUnauthorized)
+		return
+	}
+
+	var req dto.CreateCoachGrantRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		h.logger.Warn("Invalid coach invitation request", slog.Any("error", err))
+		http.Error(w, "Invalid request format", http.StatusBadRequest)
+		return
+	}
+
+	grant, err := h.coachService.CreateInvitation(r.Context(), userIDStr, req)
+	if err != nil {
+		if httpError.IsAppError(err) {
+			httpError.WriteError(w, err)
+			return
+		}
+		h.logger.Error("Failed to create coach invitation", slog.Any("error", err), slog.String("user_id", userIDStr))
+		http.Error(w, fmt.Sprintf("Failed to create coach invitation: %v", err), http.StatusInternalServerError)
+		return
+	}
+
+	// レスポンス返却
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusCreated)
+	json.NewEncoder(w).Encode(grant)
+}
+
+// handleAcceptInvitation はコーチが招待コードを受け入れるハンドラー
+func (h *CoachHandler) handleAcceptInvitation(w http.ResponseWriter, r *http.Request) {
+	// コンテキストからユーザーIDを取得
+	userIDStr, ok := middleware.GetUserIDFromContext(r.Context())
+	if !ok {
+		h.logger.Error("User ID not found in context")
+		http.Error(w, "Unauthorized", http.StatusUnauthorized)
+		return
+	}
+
+	var req dto.AcceptCoachInvitationRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		h.logger.Warn("Invalid accept coach invitation request", slog.Any("error", err))
+		http.Error(w, "Invalid request format", http.StatusBadRequest)
+		return
+	}
+
+	grant, err := h.coachService.AcceptInvitation(r.Context(), userIDStr, req)
+	if err != nil {
+		if httpError.IsAppError(err) {
+			httpError.WriteError(w, err)
+			return
+		}
+		h.logger.Error("Failed to accept coach invitation", slog.Any("error", err), slog.String("user_id", userIDStr))
+		http.Error(w, fmt.Sprintf("Failed to accept coach invitation: %v", err), http.StatusInternalServerError)
+		return
+	}
+
+	// レスポンス返却
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(grant)
+}
+
+// handleListGrants はユーザーが与えた・与えられたアクセス権の一覧を取得するハンドラー
+func (h *CoachHandler) handleListGrants(w http.ResponseWriter, r *http.Request) {
+	// コンテキストからユーザーIDを取得
+	userIDStr, ok := middleware.GetUserIDFromContext(r.Context())
+	if !ok {
+		h.logger.Error("User ID not found in context")
+		http.Error(w, "Unauthorized", http.StatusUnauthorized)
+		return
+	}
+
+	grants, err := h.coachService.ListGrants(r.Context(), userIDStr)
+	if err != nil {
+		h.logger.Error("Failed to list coach grants", slog.Any("error", err), slog.String("user_id", userIDStr))
+		http.Error(w, fmt.Sprintf("Failed to list coach grants: %v", err), http.StatusInternalServerError)
+		return
+	}
+
+	// レスポンス返却
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(grants)
+}
+
+// handleRevokeGrant はアクセス権 (または招待) を取り消すハンドラー
+func (h *CoachHandler) handleRevokeGrant(w http.ResponseWriter, r *http.Request) {
+	// コンテキストからユーザーIDを取得
+	userIDStr, ok := middleware.GetUserIDFromContext(r.Context())
+	if !ok {
+		h.logger.Error("User ID not found in context")
+		http.Error(w, "Unauthorized", http.StatusUnauthorized)
+		return
+	}
+
+	grantID, err := uuid.Parse(r.PathValue("id"))
+	if err != nil {
+		h.logger.Warn("Invalid coach grant ID", slog.String("id", r.PathValue("id")), slog.Any("error", err))
+		http.Error(w, "Invalid grant ID", http.StatusBadRequest)
+		return
+	}
+
+	if err := h.coachService.RevokeGrant(r.Context(), userIDStr, grantID); err != nil {
+		if httpError.IsAppError(err) {
+			httpError.WriteError(w, err)
+			return
+		}
+		h.logger.Error("Failed to revoke coach grant", slog.Any("error", err), slog.String("user_id", userIDStr), slog.String("grant_id", grantID.String()))
+		http.Error(w, fmt.Sprintf("Failed to revoke coach grant: %v", err), http.StatusInternalServerError)
+		return
+	}
+
+	w.WriteHeader(http.StatusNoContent)
+}
+
+// handleListAuditLogs はアクセス権を使ってコーチが行った操作の記録を取得するハンドラー
+func (h *CoachHandler) handleListAuditLogs(w http.ResponseWriter, r *http.Request) {
+	// コンテキストからユーザーIDを取得
+	userIDStr, ok := middleware.GetUserIDFromContext(r.Context())
+	if !ok {
+		h.logger.Error("User ID not found in context")
+		http.Error(w, "Unauthorized", http.StatusUnauthorized)
+		return
+	}
+
+	grantID, err := uuid.Parse(r.PathValue("id"))
+	if err != nil {
+		h.logger.Warn("Invalid coach grant ID", slog.String("id", r.PathValue("id")), slog.Any("error", err))
+		http.Error(w, "Invalid grant ID", http.StatusBadRequest)
+		return
+	}
+
+	logs, err := h.coachService.ListAuditLogs(r.Context(), userIDStr, grantID)
+	if err != nil {
+		h.logger.Error("Failed to list coach audit logs", slog.Any("error", err), slog.String("user_id", userIDStr), slog.String("grant_id", grantID.String()))
+		http.Error(w, fmt.Sprintf("Failed to list coach audit logs: %v", err), http.StatusInternalServerError)
+		return
+	}
+
+	// レスポンス返却
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(logs)
+}
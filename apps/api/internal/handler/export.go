@@ -0,0 +1,207 @@
+package handler
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"log/slog"
+	"mime"
+	"net/http"
+	"strconv"
+	"time"
+
+	httpError "github.com/aiirononeko/bulktrack/apps/api/internal/http"
+	"github.com/aiirononeko/bulktrack/apps/api/internal/interfaces/http/dto"
+	"github.com/aiirononeko/bulktrack/apps/api/internal/interfaces/http/middleware"
+	"github.com/aiirononeko/bulktrack/apps/api/internal/interfaces/service"
+	"github.com/google/uuid"
+)
+
+// exportWriteTimeout はエクスポート (同期エクスポート・アーカイブのダウンロード) のレスポンスを書き込む時間の上限
+const exportWriteTimeout = 2 * time.Minute
+
+// ExportHandler はデータエクスポート関連のハンドラーを提供する
+type ExportHandler struct {
+	exportService *service.ExportService
+	logger        *slog.Logger
+}
+
+// NewExportHandler は新しいExportHandlerを作成する
+func NewExportHandler(exportService *service.ExportService, logger *slog.Logger) *ExportHandler {
+	return &ExportHandler{
+		exportService: exportService,
+		logger:        logger,
+	}
+}
+
+// RegisterRoutes はルートを登録する
+func (h *ExportHandler) RegisterRoutes(mux Router, logging, auth func(http.Handler) http.Handler) {
+	mux.Handle("GET /me/export", logging(auth(http.HandlerFunc(h.handleExport))))
+	mux.Handle("POST /me/exports", logging(auth(http.HandlerFunc(h.handleCreateExportJob))))
+	mux.Handle("GET /me/exports/{id}", logging(auth(http.HandlerFunc(h.handleGetExportJob))))
+	mux.Handle("GET /me/exports/{id}/download", logging(auth(http.HandlerFunc(h.handleDownloadExport))))
+}
+
+// handleExport はユーザーのトレーニングデータを CSV / JSON でストリーミング出力するハンドラー
+func (h *ExportHandler) handleExport(w http.ResponseWriter, r *http.Request) {
+	// コンテキストからユーザーIDを取得
+	userIDStr, ok := middleware.GetUserIDFromContext(r.Context())
+	if !ok {
+		h.logger.Error("User ID not found in context")
+		http.Error(w, "Unauthorized", http.StatusUnauthorized)
+		return
+	}
+
+	query := r.URL.Query()
+	plan, err := h.exportService.PrepareExport(r.Context(), userIDStr, service.ExportOptions{
+		Format:  query.Get("format"),
+		Dataset: query.Get("dataset"),
+		From:    query.Get("from"),
+		To:      query.Get("to"),
+	})
+	if err != nil {
+		if httpError.IsAppError(err) {
+			httpError.WriteError(w, err)
+			return
+		}
+		h.logger.Error("Failed to prepare export", slog.Any("error", err), slog.String("user_id", userIDStr))
+		http.Error(w, fmt.Sprintf("Failed to prepare export: %v", err), http.StatusInternalServerError)
+		return
+	}
+
+	// サーバー全体の WriteTimeout ではストリーミングが途中で切れるため、このリクエストのみ延長する
+	if err := http.NewResponseController(w).SetWriteDeadline(time.Now().Add(exportWriteTimeout)); err != nil {
+		h.logger.Warn("Failed to extend write deadline for export", slog.Any("error", err))
+	}
+
+	w.Header().Set("Content-Type", plan.ContentType)
+	w.Header().Set("Content-Disposition", attachmentDisposition(plan.Filename))
+	w.Header().Set("Cache-Control", "no-store")
+
+	// 書き込みを始めた後はステータスコードを変更できないため、ログのみ出力する
+	if err := h.exportService.WriteExport(r.Context(), w, plan); err != nil {
+		h.logger.Error("Failed to write export", slog.Any("error", err), slog.String("user_id", userIDStr), slog.String("format", plan.Format))
+	}
+}
+
+// handleCreateExportJob は非同期エクスポートジョブを作成するハンドラー
+func (h *ExportHandler) handleCreateExportJob(w http.ResponseWriter, r *http.Request) {
+	// コンテキストからユーザーIDを取得
+	userIDStr, ok := middleware.GetUserIDFromContext(r.Context())
+	if !ok {
+		h.logger.Error("User ID not found in context")
+		http.Error(w, "Unauthorized", http.StatusUnauthorized)
+		return
+	}
+
+	// リクエストボディは省略可能 (期間の指定なし)
+	var req dto.ExportJobRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil && !errors.Is(err, io.EOF) {
+		h.logger.Warn("Invalid request body", slog.Any("error", err))
+		http.Error(w, "Invalid request body", http.StatusBadRequest)
+		return
+	}
+
+	job, err := h.exportService.CreateExportJob(r.Context(), userIDStr, req)
+	if err != nil {
+		if httpError.IsAppError(err) {
+			httpError.WriteError(w, err)
+			return
+		}
+		h.logger.Error("Failed to create export job", slog.Any("error", err), slog.String("user_id", userIDStr))
+		http.Error(w, fmt.Sprintf("Failed to create export job: %v", err), http.StatusInternalServerError)
+		return
+	}
+
+	// レスポンス返却
+	w.Header().Set("Content-Type", "application/json")
+	w.Header().Set("Location", fmt.Sprintf("/me/exports/%s", job.ID))
+	w.WriteHeader(http.StatusAccepted)
+	json.NewEncoder(w).Encode(job)
+}
+
+// handleGetExportJob は非同期エクスポートジョブの状態を取得するハンドラー
+func (h *ExportHandler) handleGetExportJob(w http.ResponseWriter, r *http.Request) {
+	// コンテキストからユーザーIDを取得
+	userIDStr, ok := middleware.GetUserIDFromContext(r.Context())
+	if !ok {
+		h.logger.Error("User ID not found in context")
+		http.Error(w, "Unauthorized", http.StatusUnauthorized)
+		return
+	}
+
+	idStr := r.PathValue("id")
+	id, err := uuid.Parse(idStr)
+	if err != nil {
+		h.logger.Warn("Invalid export job ID", slog.String("id", idStr), slog.Any("error", err))
+		http.Error(w, "Invalid export job ID", http.StatusBadRequest)
+		return
+	}
+
+	job, err := h.exportService.GetExportJob(r.Context(), userIDStr, id)
+	if err != nil {
+		if httpError.IsAppError(err) {
+			httpError.WriteError(w, err)
+			return
+		}
+		h.logger.Error("Failed to get export job", slog.Any("error", err), slog.String("user_id", userIDStr), slog.String("job_id", idStr))
+		http.Error(w, fmt.Sprintf("Failed to get export job: %v", err), http.StatusInternalServerError)
+		return
+	}
+
+	// レスポンス返却
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(job)
+}
+
+// handleDownloadExport は完了した非同期エクスポートジョブの ZIP アーカイブをダウンロードするハンドラー
+func (h *ExportHandler) handleDownloadExport(w http.ResponseWriter, r *http.Request) {
+	// コンテキストからユーザーIDを取得
+	userIDStr, ok := middleware.GetUserIDFromContext(r.Context())
+	if !ok {
+		h.logger.Error("User ID not found in context")
+		http.Error(w, "Unauthorized", http.StatusUnauthorized)
+		return
+	}
+
+	idStr := r.PathValue("id")
+	id, err := uuid.Parse(idStr)
+	if err != nil {
+		h.logger.Warn("Invalid export job ID", slog.String("id", idStr), slog.Any("error", err))
+		http.Error(w, "Invalid export job ID", http.StatusBadRequest)
+		return
+	}
+
+	archive, err := h.exportService.OpenExportArchive(r.Context(), userIDStr, id)
+	if err != nil {
+		if httpError.IsAppError(err) {
+			httpError.WriteError(w, err)
+			return
+		}
+		h.logger.Error("Failed to open export archive", slog.Any("error", err), slog.String("user_id", userIDStr), slog.String("job_id", idStr))
+		http.Error(w, fmt.Sprintf("Failed to open export archive: %v", err), http.StatusInternalServerError)
+		return
+	}
+	defer archive.Close()
+
+	// 大きなアーカイブはサーバー全体の WriteTimeout までに送り終わらないため、このリクエストのみ延長する
+	if err := http.NewResponseController(w).SetWriteDeadline(time.Now().Add(exportWriteTimeout)); err != nil {
+		h.logger.Warn("Failed to extend write deadline for export download", slog.Any("error", err))
+	}
+
+	w.Header().Set("Content-Type", "application/zip")
+	w.Header().Set("Content-Disposition", attachmentDisposition(archive.Filename))
+	w.Header().Set("Content-Length", strconv.FormatInt(archive.Size, 10))
+	w.Header().Set("Cache-Control", "no-store")
+
+	// 書き込みを始めた後はステータスコードを変更できないため、ログのみ出力する
+	if _, err := io.Copy(w, archive); err != nil {
+		h.logger.Error("Failed to write export archive", slog.Any("error", err), slog.String("user_id", userIDStr), slog.String("job_id", idStr))
+	}
+}
+
+// attachmentDisposition はダウンロード用の Content-Disposition ヘッダーの値を作成する
+func attachmentDisposition(filename string) string {
+	return mime.FormatMediaType("attachment", map[string]string{"filename": filename})
+}
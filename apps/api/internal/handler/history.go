@@ -0,0 +1,107 @@
+package handler
+
+import (
+	"encoding/json"
+	"fmt"
+	"log/slog"
+	"net/http"
+	"strconv"
+
+	httpError "github.com/aiirononeko/bulktrack/apps/api/internal/http"
+	"github.com/aiirononeko/bulktrack/apps/api/internal/interfaces/http/middleware"
+	"github.com/aiirononeko/bulktrack/apps/api/internal/interfaces/service"
+)
+
+// HistoryHandler はトレーニング履歴関連のハンドラーを提供する
+type HistoryHandler struct {
+	historyService *service.HistoryService
+	logger         *slog.Logger
+}
+
+// NewHistoryHandler は新しいHistoryHandlerを作成する
+func NewHistoryHandler(historyService *service.HistoryService, logger *slog.Logger) *HistoryHandler {
+	return &HistoryHandler{
+		historyService: historyService,
+		logger:         logger,
+	}
+}
+
+// RegisterRoutes はルートを登録する
+func (h *HistoryHandler) RegisterRoutes(mux Router, logging, auth func(http.Handler) http.Handler) {
+	mux.Handle("GET /history", logging(auth(http.HandlerFunc(h.handleGetHistory))))
+	mux.Handle("GET /history/calendar", logging(auth(http.HandlerFunc(h.handleGetCalendar))))
+}
+
+// handleGetHistory は期間 (日・週・月) ごとのトレーニング履歴を取得するハンドラー
+func (h *HistoryHandler) handleGetHistory(w http.ResponseWriter, r *http.Request) {
+	// コンテキストからユーザーIDを取得
+	userIDStr, ok := middleware.GetUserIDFromContext(r.Context())
+	if !ok {
+		h.logger.Error("User ID not found in context")
+		http.Error(w, "Unauthorized", http.StatusUnauthorized)
+		return
+	}
+
+	// クエリパラメータから集計単位・期間・タイムゾーン・ページング条件を取得
+	query := r.URL.Query()
+	opts := service.HistoryOptions{
+		Interval: query.Get("interval"),
+		From:     query.Get("from"),
+		To:       query.Get("to"),
+		TimeZone: query.Get("tz"),
+		Cursor:   query.Get("cursor"),
+	}
+	if limitStr := query.Get("limit"); limitStr != "" {
+		limit, err := strconv.Atoi(limitStr)
+		if err != nil {
+			httpError.WriteError(w, httpError.NewInvalidLimitError("Limit must be an integer", err))
+			return
+		}
+		opts.Limit = limit
+	}
+
+	history, err := h.historyService.GetHistory(r.Context(), userIDStr, opts)
+	if err != nil {
+		if httpError.IsAppError(err) {
+			httpError.WriteError(w, err)
+			return
+		}
+		h.logger.Error("Failed to get history", slog.Any("error", err), slog.String("user_id", userIDStr), slog.String("interval", opts.Interval))
+		http.Error(w, fmt.Sprintf("Failed to get history: %v", err), http.StatusInternalServerError)
+		return
+	}
+
+	// レスポンス返却
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(history)
+}
+
+// handleGetCalendar は月のカレンダー (トレーニングした日のマーカー) を取得するハンドラー
+func (h *HistoryHandler) handleGetCalendar(w http.ResponseWriter, r *http.Request) {
+	// コンテキストからユーザーIDを取得
+	userIDStr, ok := middleware.GetUserIDFromContext(r.Context())
+	if !ok {
+		h.logger.Error("User ID not found in context")
+		http.Error(w, "Unauthorized", http.StatusUnauthorized)
+		return
+	}
+
+	// クエリパラメータから月 (YYYY-MM) とタイムゾーンを取得
+	query := r.URL.Query()
+	month := query.Get("month")
+
+	calendar, err := h.historyService.GetCalendar(r.Context(), userIDStr, month, query.Get("tz"))
+	if err != nil {
+		if httpError.IsAppError(err) {
+			httpError.WriteError(w, err)
+			return
+		}
+		h.logger.Error("Failed to get history calendar", slog.Any("error", err), slog.String("user_id", userIDStr), slog.String("month", month))
+		http.Error(w, fmt.Sprintf("Failed to get history calendar: %v", err), http.StatusInternalServerError)
+		return
+	}
+
+	// レスポンス返却
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(calendar)
+}
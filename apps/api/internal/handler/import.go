@@ -0,0 +1,114 @@
+package handler
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log/slog"
+	"net/http"
+	"time"
+
+	httpError "github.com/aiirononeko/bulktrack/apps/api/internal/http"
+	"github.com/aiirononeko/bulktrack/apps/api/internal/interfaces/http/dto"
+	"github.com/aiirononeko/bulktrack/apps/api/internal/interfaces/http/middleware"
+	"github.com/aiirononeko/bulktrack/apps/api/internal/interfaces/service"
+)
+
+// maxImportFileBytes は取り込む CSV ファイル (multipart 全体) の最大サイズ
+const maxImportFileBytes = 20 << 20
+
+// importTimeout は取り込みリクエストの読み込み・書き込みの時間の上限
+const importTimeout = 2 * time.Minute
+
+// ImportHandler はワークアウト履歴の取り込み関連のハンドラーを提供する
+type ImportHandler struct {
+	importService *service.ImportService
+	logger        *slog.Logger
+}
+
+// NewImportHandler は新しいImportHandlerを作成する
+func NewImportHandler(importService *service.ImportService, logger *slog.Logger) *ImportHandler {
+	return &ImportHandler{
+		importService: importService,
+		logger:        logger,
+	}
+}
+
+// RegisterRoutes はルートを登録する
+func (h *ImportHandler) RegisterRoutes(mux Router, logging, auth func(http.Handler) http.Handler) {
+	mux.Handle("POST /imports", logging(auth(http.HandlerFunc(h.handleImport))))
+}
+
+// handleImport は Strong / Hevy / FitNotes の CSV からワークアウト履歴を取り込むハンドラー
+//
+// multipart/form-data で file (CSV) と options (dto.ImportRequest の JSON) を受け取る
+// dry_run の場合は 200、取り込んだ場合は 201 で取り込み結果を返す
+func (h *ImportHandler) handleImport(w http.ResponseWriter, r *http.Request) {
+	// コンテキストからユーザーIDを取得
+	userIDStr, ok := middleware.GetUserIDFromContext(r.Context())
+	if !ok {
+		h.logger.Error("User ID not found in context")
+		http.Error(w, "Unauthorized", http.StatusUnauthorized)
+		return
+	}
+
+	// 大きな CSV はサーバー全体の Read/WriteTimeout に収まらないため、このリクエストのみ延長する
+	rc := http.NewResponseController(w)
+	if err := rc.SetReadDeadline(time.Now().Add(importTimeout)); err != nil {
+		h.logger.Warn("Failed to extend read deadline for import", slog.Any("error", err))
+	}
+	if err := rc.SetWriteDeadline(time.Now().Add(importTimeout)); err != nil {
+		h.logger.Warn("Failed to extend write deadline for import", slog.Any("error", err))
+	}
+
+	r.Body = http.MaxBytesReader(w, r.Body, maxImportFileBytes)
+	if err := r.ParseMultipartForm(maxImportFileBytes); err != nil {
+		var maxBytesErr *http.MaxBytesError
+		if errors.As(err, &maxBytesErr) {
+			http.Error(w, fmt.Sprintf("File too large. Maximum size is %d MB", maxImportFileBytes>>20), http.StatusRequestEntityTooLarge)
+			return
+		}
+		h.logger.Warn("Invalid multipart form", slog.Any("error", err))
+		http.Error(w, "Invalid request body. Expected multipart/form-data", http.StatusBadRequest)
+		return
+	}
+	defer r.MultipartForm.RemoveAll()
+
+	var req dto.ImportRequest
+	if options := r.FormValue("options"); options != "" {
+		if err := json.Unmarshal([]byte(options), &req); err != nil {
+			h.logger.Warn("Invalid import options", slog.Any("error", err))
+			http.Error(w, "Invalid options", http.StatusBadRequest)
+			return
+		}
+	}
+
+	file, _, err := r.FormFile("file")
+	if err != nil {
+		httpError.WriteError(w, httpError.NewValidationError("file is required", []httpError.ValidationDetail{
+			{Field: "file", Reason: "REQUIRED"},
+		}))
+		return
+	}
+	defer file.Close()
+
+	report, err := h.importService.ImportWorkouts(r.Context(), userIDStr, file, req)
+	if err != nil {
+		if httpError.IsAppError(err) {
+			httpError.WriteError(w, err)
+			return
+		}
+		h.logger.Error("Failed to import workouts", slog.Any("error", err), slog.String("user_id", userIDStr), slog.String("source", req.Source))
+		http.Error(w, fmt.Sprintf("Failed to import workouts: %v", err), http.StatusInternalServerError)
+		return
+	}
+
+	// レスポンス返却
+	w.Header().Set("Content-Type", "application/json")
+	if report.DryRun {
+		w.WriteHeader(http.StatusOK)
+	} else {
+		w.WriteHeader(http.StatusCreated)
+	}
+	json.NewEncoder(w).Encode(report)
+}
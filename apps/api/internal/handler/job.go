@@ -0,0 +1,86 @@
+package handler
+
+import (
+	"encoding/json"
+	"fmt"
+	"log/slog"
+	"net/http"
+	"strconv"
+
+	httpError "github.com/aiirononeko/bulktrack/apps/api/internal/http"
+	"github.com/aiirononeko/bulktrack/apps/api/internal/interfaces/service"
+)
+
+// JobHandler はバックグラウンドジョブの管理用のハンドラーを提供する
+type JobHandler struct {
+	jobService *service.JobService
+	logger     *slog.Logger
+}
+
+// NewJobHandler は新しいJobHandlerを作成する
+func NewJobHandler(jobService *service.JobService, logger *slog.Logger) *JobHandler {
+	return &JobHandler{
+		jobService: jobService,
+		logger:     logger,
+	}
+}
+
+// RegisterAdminRoutes は管理用ポートのルートを登録する (認証しないため、公開ポートには登録しないこと)
+//
+//	GET  /jobs/failed?kind=&limit=&cursor=  失敗したジョブの一覧 (新しい順)
+//	POST /jobs/{id}/retry                   失敗したジョブを試行回数を戻して再実行する
+func (h *JobHandler) RegisterAdminRoutes(mux *http.ServeMux) {
+	mux.HandleFunc("GET /jobs/failed", h.handleListFailedJobs)
+	mux.HandleFunc("POST /jobs/{id}/retry", h.handleRetryJob)
+}
+
+// handleListFailedJobs は失敗したジョブの一覧を取得するハンドラー
+func (h *JobHandler) handleListFailedJobs(w http.ResponseWriter, r *http.Request) {
+	query := r.URL.Query()
+	limit := 0
+	if limitStr := query.Get("limit"); limitStr != "" {
+		n, err := strconv.Atoi(limitStr)
+		if err != nil {
+			httpError.WriteError(w, httpError.NewInvalidLimitError("Limit must be an integer", err))
+			return
+		}
+		limit = n
+	}
+
+	resp, err := h.jobService.ListFailedJobs(r.Context(), query.Get("kind"), query.Get("cursor"), limit)
+	if err != nil {
+		if httpError.IsAppError(err) {
+			httpError.WriteError(w, err)
+			return
+		}
+		h.logger.Error("Failed to list failed jobs", slog.Any("error", err))
+		http.Error(w, fmt.Sprintf("Failed to list failed jobs: %v", err), http.StatusInternalServerError)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(resp)
+}
+
+// handleRetryJob は失敗したジョブを再実行するハンドラー
+func (h *JobHandler) handleRetryJob(w http.ResponseWriter, r *http.Request) {
+	id, err := strconv.ParseInt(r.PathValue("id"), 10, 64)
+	if err != nil {
+		httpError.WriteError(w, httpError.NewValidationError("Invalid job ID", []httpError.ValidationDetail{
+			{Field: "id", Reason: "INVALID_FORMAT"},
+		}))
+		return
+	}
+
+	if err := h.jobService.RetryFailedJob(r.Context(), id); err != nil {
+		if httpError.IsAppError(err) {
+			httpError.WriteError(w, err)
+			return
+		}
+		h.logger.Error("Failed to retry job", slog.Any("error", err), slog.Int64("job_id", id))
+		http.Error(w, fmt.Sprintf("Failed to retry job: %v", err), http.StatusInternalServerError)
+		return
+	}
+
+	w.WriteHeader(http.StatusNoContent)
+}
@@ -0,0 +1,363 @@
+package handler
+
+import (
+	"encoding/json"
+	"fmt"
+	"log/slog"
+	"net/http"
+	"strconv"
+
+	httpError "github.com/aiirononeko/bulktrack/apps/api/internal/http"
+	"github.com/aiirononeko/bulktrack/apps/api/internal/interfaces/http/dto"
+	"github.com/aiirononeko/bulktrack/apps/api/internal/interfaces/http/middleware"
+	"github.com/aiirononeko/bulktrack/apps/api/internal/interfaces/service"
+	"github.com/google/uuid"
+)
+
+// MeasurementHandler は身体計測関連のハンドラーを提供する
+type MeasurementHandler struct {
+	measurementService *service.MeasurementService
+	logger             *slog.Logger
+}
+
+// NewMeasurementHandler は新しいMeasurementHandlerを作成する
+func NewMeasurementHandler(measurementService *service.MeasurementService, logger *slog.Logger) *MeasurementHandler {
+	return &MeasurementHandler{
+		measurementService: measurementService,
+		logger:             logger,
+	}
+}
+
+// RegisterRoutes はルートを登録する
+func (h *MeasurementHandler) RegisterRoutes(mux Router, logging, auth func(http.Handler) http.Handler) {
+	mux.Handle("GET /measurement-metrics", logging(auth(http.HandlerFunc(h.handleListMetrics))))
+	mux.Handle("GET /measurements", logging(auth(http.HandlerFunc(h.handleListMeasurements))))
+	mux.Handle("POST /measurements", logging(auth(http.HandlerFunc(h.handleCreateMeasurement))))
+	mux.Handle("GET /measurements/series", logging(auth(http.HandlerFunc(h.handleGetMeasurementSeries))))
+	mux.Handle("GET /measurements/relative-strength", logging(auth(http.HandlerFunc(h.handleGetRelativeStrength))))
+	mux.Handle("PATCH /measurements/{id}", logging(auth(http.HandlerFunc(h.handleUpdateMeasurement))))
+	mux.Handle("DELETE /measurements/{id}", logging(auth(http.HandlerFunc(h.handleDeleteMeasurement))))
+
+	// 体重記録の互換 API (body_measurements の metric=body_weight を日ごとに読み書きする)
+	mux.Handle("GET /body-weights", logging(auth(http.HandlerFunc(h.handleListBodyWeights))))
+	mux.Handle("POST /body-weights", logging(auth(http.HandlerFunc(h.handleRecordBodyWeight))))
+	mux.Handle("DELETE /body-weights/{date}", logging(auth(http.HandlerFunc(h.handleDeleteBodyWeight))))
+}
+
+// handleListMetrics は計測項目の一覧を取得するハンドラー
+func (h *MeasurementHandler) handleListMetrics(w http.ResponseWriter, r *http.Request) {
+	metrics, err := h.measurementService.ListMetrics(r.Context())
+	if err != nil {
+		h.logger.Error("Failed to list measurement metrics", slog.Any("error", err))
+		http.Error(w, fmt.Sprintf("Failed to list measurement metrics: %v", err), http.StatusInternalServerError)
+		return
+	}
+
+	// レスポンス返却
+	w.Header().Set("Content-Type", "application/json")
+	w.Header().Set("Cache-Control", "max-age=3600") // 計測項目はマスタデータのため1時間キャッシュ
+	json.NewEncoder(w).Encode(metrics)
+}
+
+// handleListMeasurements は身体計測記録の一覧を取得するハンドラー
+func (h *MeasurementHandler) handleListMeasurements(w http.ResponseWriter, r *http.Request) {
+	// コンテキストからユーザーIDを取得
+	userIDStr, ok := middleware.GetUserIDFromContext(r.Context())
+	if !ok {
+		h.logger.Error("User ID not found in context")
+		http.Error(w, "Unauthorized", http.StatusUnauthorized)
+		return
+	}
+
+	// クエリパラメータから計測項目と期間を取得 (YYYY-MM-DD)
+	query := r.URL.Query()
+	metric := query.Get("metric")
+
+	measurements, err := h.measurementService.ListMeasurements(r.Context(), userIDStr, metric, query.Get("from"), query.Get("to"))
+	if err != nil {
+		if httpError.IsAppError(err) {
+			httpError.WriteError(w, err)
+			return
+		}
+		h.logger.Error("Failed to list measurements", slog.Any("error", err), slog.String("user_id", userIDStr), slog.String("metric", metric))
+		http.Error(w, fmt.Sprintf("Failed to list measurements: %v", err), http.StatusInternalServerError)
+		return
+	}
+
+	// レスポンス返却
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(measurements)
+}
+
+// handleCreateMeasurement は身体計測記録を作成するハンドラー
+func (h *MeasurementHandler) handleCreateMeasurement(w http.ResponseWriter, r *http.Request) {
+	// コンテキストからユーザーIDを取得
+	userIDStr, ok := middleware.GetUserIDFromContext(r.Context())
+	if !ok {
+		h.logger.Error("User ID not found in context")
+		http.Error(w, "Unauthorized", http.StatusUnauthorized)
+		return
+	}
+
+	var req dto.CreateMeasurementRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		h.logger.Warn("Invalid request body", slog.Any("error", err))
+		http.Error(w, "Invalid request body", http.StatusBadRequest)
+		return
+	}
+
+	measurement, err := h.measurementService.CreateMeasurement(r.Context(), userIDStr, req)
+	if err != nil {
+		if httpError.IsAppError(err) {
+			httpError.WriteError(w, err)
+			return
+		}
+		h.logger.Error("Failed to create measurement", slog.Any("error", err), slog.String("user_id", userIDStr), slog.String("metric", req.Metric))
+		http.Error(w, fmt.Sprintf("Failed to create measurement: %v", err), http.StatusInternalServerError)
+		return
+	}
+
+	// レスポンス返却
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusCreated)
+	json.NewEncoder(w).Encode(measurement)
+}
+
+// handleUpdateMeasurement は身体計測記録を更新するハンドラー
+func (h *MeasurementHandler) handleUpdateMeasurement(w http.ResponseWriter, r *http.Request) {
+	// コンテキストからユーザーIDを取得
+	userIDStr, ok := middleware.GetUserIDFromContext(r.Context())
+	if !ok {
+		h.logger.Error("User ID not found in context")
+		http.Error(w, "Unauthorized", http.StatusUnauthorized)
+		return
+	}
+
+	idStr := r.PathValue("id")
+	id, err := uuid.Parse(idStr)
+	if err != nil {
+		h.logger.Warn("Invalid measurement ID", slog.String("id", idStr), slog.Any("error", err))
+		http.Error(w, "Invalid measurement ID", http.StatusBadRequest)
+		return
+	}
+
+	var req dto.UpdateMeasurementRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		h.logger.Warn("Invalid request body", slog.Any("error", err))
+		http.Error(w, "Invalid request body", http.StatusBadRequest)
+		return
+	}
+
+	measurement, err := h.measurementService.UpdateMeasurement(r.Context(), userIDStr, id, req)
+	if err != nil {
+		if httpError.IsAppError(err) {
+			httpError.WriteError(w, err)
+			return
+		}
+		h.logger.Error("Failed to update measurement", slog.Any("error", err), slog.String("user_id", userIDStr), slog.String("measurement_id", idStr))
+		http.Error(w, fmt.Sprintf("Failed to update measurement: %v", err), http.StatusInternalServerError)
+		return
+	}
+
+	// レスポンス返却
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(measurement)
+}
+
+// handleDeleteMeasurement は身体計測記録を削除するハンドラー
+func (h *MeasurementHandler) handleDeleteMeasurement(w http.ResponseWriter, r *http.Request) {
+	// コンテキストからユーザーIDを取得
+	userIDStr, ok := middleware.GetUserIDFromContext(r.Context())
+	if !ok {
+		h.logger.Error("User ID not found in context")
+		http.Error(w, "Unauthorized", http.StatusUnauthorized)
+		return
+	}
+
+	idStr := r.PathValue("id")
+	id, err := uuid.Parse(idStr)
+	if err != nil {
+		h.logger.Warn("Invalid measurement ID", slog.String("id", idStr), slog.Any("error", err))
+		http.Error(w, "Invalid measurement ID", http.StatusBadRequest)
+		return
+	}
+
+	if err := h.measurementService.DeleteMeasurement(r.Context(), userIDStr, id); err != nil {
+		if httpError.IsAppError(err) {
+			httpError.WriteError(w, err)
+			return
+		}
+		h.logger.Error("Failed to delete measurement", slog.Any("error", err), slog.String("user_id", userIDStr), slog.String("measurement_id", idStr))
+		http.Error(w, fmt.Sprintf("Failed to delete measurement: %v", err), http.StatusInternalServerError)
+		return
+	}
+
+	w.WriteHeader(http.StatusNoContent)
+}
+
+// handleGetMeasurementSeries は計測項目の時系列データ (日ごと / 週ごとの平均) を取得するハンドラー
+func (h *MeasurementHandler) handleGetMeasurementSeries(w http.ResponseWriter, r *http.Request) {
+	// コンテキストからユーザーIDを取得
+	userIDStr, ok := middleware.GetUserIDFromContext(r.Context())
+	if !ok {
+		h.logger.Error("User ID not found in context")
+		http.Error(w, "Unauthorized", http.StatusUnauthorized)
+		return
+	}
+
+	query := r.URL.Query()
+	metric := query.Get("metric")
+	if metric == "" {
+		h.logger.Warn("Metric parameter is required")
+		http.Error(w, "Metric parameter is required", http.StatusBadRequest)
+		return
+	}
+
+	series, err := h.measurementService.GetMeasurementSeries(r.Context(), userIDStr, metric, query.Get("interval"), query.Get("from"), query.Get("to"))
+	if err != nil {
+		if httpError.IsAppError(err) {
+			httpError.WriteError(w, err)
+			return
+		}
+		h.logger.Error("Failed to get measurement series", slog.Any("error", err), slog.String("user_id", userIDStr), slog.String("metric", metric))
+		http.Error(w, fmt.Sprintf("Failed to get measurement series: %v", err), http.StatusInternalServerError)
+		return
+	}
+
+	// レスポンス返却
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(series)
+}
+
+// handleGetRelativeStrength は種目の相対筋力 (推定1RM / 体重) の推移を取得するハンドラー
+func (h *MeasurementHandler) handleGetRelativeStrength(w http.ResponseWriter, r *http.Request) {
+	// コンテキストからユーザーIDを取得
+	userIDStr, ok := middleware.GetUserIDFromContext(r.Context())
+	if !ok {
+		h.logger.Error("User ID not found in context")
+		http.Error(w, "Unauthorized", http.StatusUnauthorized)
+		return
+	}
+
+	query := r.URL.Query()
+	exerciseIDStr := query.Get("exercise_id")
+	exerciseID, err := uuid.Parse(exerciseIDStr)
+	if err != nil {
+		h.logger.Warn("Invalid exercise ID", slog.String("exercise_id", exerciseIDStr), slog.Any("error", err))
+		http.Error(w, "Invalid exercise ID", http.StatusBadRequest)
+		return
+	}
+
+	// クエリパラメータから週数を取得（デフォルトは12週）
+	weeksCount := int32(0)
+	if weeksCountStr := query.Get("weeks"); weeksCountStr != "" {
+		count, err := strconv.ParseInt(weeksCountStr, 10, 32)
+		if err != nil {
+			h.logger.Warn("Invalid weeks count parameter", slog.String("weeks", weeksCountStr), slog.Any("error", err))
+			http.Error(w, "Invalid weeks count parameter", http.StatusBadRequest)
+			return
+		}
+		weeksCount = int32(count)
+	}
+
+	result, err := h.measurementService.GetRelativeStrength(r.Context(), userIDStr, exerciseID, weeksCount)
+	if err != nil {
+		if httpError.IsAppError(err) {
+			httpError.WriteError(w, err)
+			return
+		}
+		h.logger.Error("Failed to get relative strength", slog.Any("error", err), slog.String("user_id", userIDStr), slog.String("exercise_id", exerciseIDStr))
+		http.Error(w, fmt.Sprintf("Failed to get relative strength: %v", err), http.StatusInternalServerError)
+		return
+	}
+
+	// レスポンス返却
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(result)
+}
+
+// handleListBodyWeights は日ごとの体重記録一覧を取得するハンドラー (互換 API)
+func (h *MeasurementHandler) handleListBodyWeights(w http.ResponseWriter, r *http.Request) {
+	// コンテキストからユーザーIDを取得
+	userIDStr, ok := middleware.GetUserIDFromContext(r.Context())
+	if !ok {
+		h.logger.Error("User ID not found in context")
+		http.Error(w, "Unauthorized", http.StatusUnauthorized)
+		return
+	}
+
+	// クエリパラメータから期間を取得 (YYYY-MM-DD)
+	query := r.URL.Query()
+	bodyWeights, err := h.measurementService.ListBodyWeights(r.Context(), userIDStr, query.Get("from"), query.Get("to"))
+	if err != nil {
+		if httpError.IsAppError(err) {
+			httpError.WriteError(w, err)
+			return
+		}
+		h.logger.Error("Failed to list body weights", slog.Any("error", err), slog.String("user_id", userIDStr))
+		http.Error(w, fmt.Sprintf("Failed to list body weights: %v", err), http.StatusInternalServerError)
+		return
+	}
+
+	// レスポンス返却
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(bodyWeights)
+}
+
+// handleRecordBodyWeight は体重を記録するハンドラー (互換 API)
+func (h *MeasurementHandler) handleRecordBodyWeight(w http.ResponseWriter, r *http.Request) {
+	// コンテキストからユーザーIDを取得
+	userIDStr, ok := middleware.GetUserIDFromContext(r.Context())
+	if !ok {
+		h.logger.Error("User ID not found in context")
+		http.Error(w, "Unauthorized", http.StatusUnauthorized)
+		return
+	}
+
+	var req dto.RecordBodyWeightRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		h.logger.Warn("Invalid request body", slog.Any("error", err))
+		http.Error(w, "Invalid request body", http.StatusBadRequest)
+		return
+	}
+
+	bodyWeight, err := h.measurementService.RecordBodyWeight(r.Context(), userIDStr, req)
+	if err != nil {
+		if httpError.IsAppError(err) {
+			httpError.WriteError(w, err)
+			return
+		}
+		h.logger.Error("Failed to record body weight", slog.Any("error", err), slog.String("user_id", userIDStr))
+		http.Error(w, fmt.Sprintf("Failed to record body weight: %v", err), http.StatusInternalServerError)
+		return
+	}
+
+	// レスポンス返却
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusCreated)
+	json.NewEncoder(w).Encode(bodyWeight)
+}
+
+// handleDeleteBodyWeight は指定日の体重記録を削除するハンドラー (互換 API)
+func (h *MeasurementHandler) handleDeleteBodyWeight(w http.ResponseWriter, r *http.Request) {
+	// コンテキストからユーザーIDを取得
+	userIDStr, ok := middleware.GetUserIDFromContext(r.Context())
+	if !ok {
+		h.logger.Error("User ID not found in context")
+		http.Error(w, "Unauthorized", http.StatusUnauthorized)
+		return
+	}
+
+	date := r.PathValue("date")
+	if err := h.measurementService.DeleteBodyWeight(r.Context(), userIDStr, date); err != nil {
+		if httpError.IsAppError(err) {
+			httpError.WriteError(w, err)
+			return
+		}
+		h.logger.Error("Failed to delete body weight", slog.Any("error", err), slog.String("user_id", userIDStr), slog.String("date", date))
+		http.Error(w, fmt.Sprintf("Failed to delete body weight: %v", err), http.StatusInternalServerError)
+		return
+	}
+
+	w.WriteHeader(http.StatusNoContent)
+}
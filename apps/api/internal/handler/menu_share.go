@@ -0,0 +1,184 @@
+package handler
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"log/slog"
+	"net/http"
+
+	httpError "github.com/aiirononeko/bulktrack/apps/api/internal/http"
+	"github.com/aiirononeko/bulktrack/apps/api/internal/interfaces/http/dto"
+	"github.com/aiirononeko/bulktrack/apps/api/internal/interfaces/http/middleware"
+	"github.com/aiirononeko/bulktrack/apps/api/internal/interfaces/service"
+	"github.com/google/uuid"
+)
+
+// MenuShareHandler はメニューの共有関連のハンドラーを提供する
+type MenuShareHandler struct {
+	menuShareService *service.MenuShareService
+	menuService      *service.MenuService
+	logger           *slog.Logger
+}
+
+// NewMenuShareHandler は新しいMenuShareHandlerを作成する
+func NewMenuShareHandler(menuShareService *service.MenuShareService, menuService *service.MenuService, logger *slog.Logger) *MenuShareHandler {
+	return &MenuShareHandler{
+		menuShareService: menuShareService,
+		menuService:      menuService,
+		logger:           logger,
+	}
+}
+
+// RegisterRoutes はルートを登録する
+func (h *MenuShareHandler) RegisterRoutes(mux Router, logging, auth func(http.Handler) http.Handler) {
+	mux.Handle("POST /menus/{id}/share", logging(auth(http.HandlerFunc(h.handleShareMenu))))
+	mux.Handle("DELETE /menus/{id}/share", logging(auth(http.HandlerFunc(h.handleUnshareMenu))))
+	mux.Handle("GET /shared-menus/{code}", logging(auth(http.HandlerFunc(h.handleGetSharedMenu))))
+	mux.Handle("POST /shared-menus/{code}/clone", logging(auth(http.HandlerFunc(h.handleCloneMenu))))
+	mux.Handle("GET /menu-templates", logging(auth(http.HandlerFunc(h.handleListTemplates))))
+}
+
+// handleShareMenu はメニューの共有コードを発行するハンドラー (共有中の場合はその共有コードを返す)
+func (h *MenuShareHandler) handleShareMenu(w http.ResponseWriter, r *http.Request) {
+	// コンテキストからユーザーIDを取得
+	userIDStr, ok := middleware.GetUserIDFromContext(r.Context())
+	if !ok {
+		h.logger.Error("User ID not found in context")
+		http.Error(w, "Unauthorized", http.StatusUnauthorized)
+		return
+	}
+
+	menuID, err := uuid.Parse(r.PathValue("id"))
+	if err != nil {
+		h.logger.Warn("Invalid menu ID", slog.String("id", r.PathValue("id")), slog.Any("error", err))
+		http.Error(w, "Invalid menu ID", http.StatusBadRequest)
+		return
+	}
+
+	share, created, err := h.menuShareService.ShareMenu(r.Context(), userIDStr, menuID)
+	if err != nil {
+		if httpError.IsAppError(err) {
+			httpError.WriteError(w, err)
+			return
+		}
+		h.logger.Error("Failed to share menu", slog.Any("error", err), slog.String("user_id", userIDStr), slog.String("menu_id", menuID.String()))
+		http.Error(w, fmt.Sprintf("Failed to share menu: %v", err), http.StatusInternalServerError)
+		return
+	}
+
+	// レスポンス返却
+	w.Header().Set("Content-Type", "application/json")
+	if created {
+		w.WriteHeader(http.StatusCreated)
+	}
+	json.NewEncoder(w).Encode(share)
+}
+
+// handleUnshareMenu はメニューの共有をやめるハンドラー
+func (h *MenuShareHandler) handleUnshareMenu(w http.ResponseWriter, r *http.Request) {
+	// コンテキストからユーザーIDを取得
+	userIDStr, ok := middleware.GetUserIDFromContext(r.Context())
+	if !ok {
+		h.logger.Error("User ID not found in context")
+		http.Error(w, "Unauthorized", http.StatusUnauthorized)
+		return
+	}
+
+	menuID, err := uuid.Parse(r.PathValue("id"))
+	if err != nil {
+		h.logger.Warn("Invalid menu ID", slog.String("id", r.PathValue("id")), slog.Any("error", err))
+		http.Error(w, "Invalid menu ID", http.StatusBadRequest)
+		return
+	}
+
+	if err := h.menuShareService.UnshareMenu(r.Context(), userIDStr, menuID); err != nil {
+		if httpError.IsAppError(err) {
+			httpError.WriteError(w, err)
+			return
+		}
+		h.logger.Error("Failed to unshare menu", slog.Any("error", err), slog.String("user_id", userIDStr), slog.String("menu_id", menuID.String()))
+		http.Error(w, fmt.Sprintf("Failed to unshare menu: %v", err), http.StatusInternalServerError)
+		return
+	}
+
+	w.WriteHeader(http.StatusNoContent)
+}
+
+// handleGetSharedMenu は共有コードのメニューをプレビューするハンドラー
+func (h *MenuShareHandler) handleGetSharedMenu(w http.ResponseWriter, r *http.Request) {
+	menu, err := h.menuShareService.GetSharedMenu(r.Context(), r.PathValue("code"))
+	if err != nil {
+		if httpError.IsAppError(err) {
+			httpError.WriteError(w, err)
+			return
+		}
+		h.logger.Error("Failed to get shared menu", slog.Any("error", err))
+		http.Error(w, fmt.Sprintf("Failed to get shared menu: %v", err), http.StatusInternalServerError)
+		return
+	}
+
+	// レスポンス返却
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(menu)
+}
+
+// handleCloneMenu は共有コードのメニューを自分のメニューとして複製するハンドラー
+func (h *MenuShareHandler) handleCloneMenu(w http.ResponseWriter, r *http.Request) {
+	// コンテキストからユーザーIDを取得
+	userIDStr, ok := middleware.GetUserIDFromContext(r.Context())
+	if !ok {
+		h.logger.Error("User ID not found in context")
+		http.Error(w, "Unauthorized", http.StatusUnauthorized)
+		return
+	}
+
+	// リクエストボディは省略できる
+	var req dto.CloneMenuRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil && !errors.Is(err, io.EOF) {
+		h.logger.Warn("Invalid clone menu request", slog.Any("error", err))
+		http.Error(w, "Invalid request format", http.StatusBadRequest)
+		return
+	}
+
+	menuID, createdExercises, err := h.menuShareService.CloneMenu(r.Context(), userIDStr, r.PathValue("code"), req)
+	if err != nil {
+		if httpError.IsAppError(err) {
+			httpError.WriteError(w, err)
+			return
+		}
+		h.logger.Error("Failed to clone menu", slog.Any("error", err), slog.String("user_id", userIDStr))
+		http.Error(w, fmt.Sprintf("Failed to clone menu: %v", err), http.StatusInternalServerError)
+		return
+	}
+
+	menu, err := h.menuService.GetMenuWithItems(r.Context(), menuID)
+	if err != nil {
+		h.logger.Error("Failed to get cloned menu", slog.Any("error", err), slog.String("user_id", userIDStr), slog.String("menu_id", menuID.String()))
+		http.Error(w, fmt.Sprintf("Failed to get cloned menu: %v", err), http.StatusInternalServerError)
+		return
+	}
+	if createdExercises == nil {
+		createdExercises = []string{}
+	}
+
+	// レスポンス返却
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusCreated)
+	json.NewEncoder(w).Encode(dto.CloneMenuResponse{Menu: menu, CreatedExercises: createdExercises})
+}
+
+// handleListTemplates はテンプレートライブラリのメニューの一覧を取得するハンドラー
+func (h *MenuShareHandler) handleListTemplates(w http.ResponseWriter, r *http.Request) {
+	templates, err := h.menuShareService.ListTemplates(r.Context())
+	if err != nil {
+		h.logger.Error("Failed to list menu templates", slog.Any("error", err))
+		http.Error(w, fmt.Sprintf("Failed to list menu templates: %v", err), http.StatusInternalServerError)
+		return
+	}
+
+	// レスポンス返却
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(templates)
+}
@@ -0,0 +1,124 @@
+package handler
+
+import (
+	"encoding/json"
+	"fmt"
+	"log/slog"
+	"net/http"
+
+	httpError "github.com/aiirononeko/bulktrack/apps/api/internal/http"
+	"github.com/aiirononeko/bulktrack/apps/api/internal/interfaces/http/dto"
+	"github.com/aiirononeko/bulktrack/apps/api/internal/interfaces/http/middleware"
+	"github.com/aiirononeko/bulktrack/apps/api/internal/interfaces/service"
+	"github.com/google/uuid"
+)
+
+// PersonalAccessTokenHandler はパーソナルアクセストークン関連のハンドラーを提供する
+type PersonalAccessTokenHandler struct {
+	tokenService *service.PersonalAccessTokenService
+	logger       *slog.Logger
+}
+
+// NewPersonalAccessTokenHandler は新しいPersonalAccessTokenHandlerを作成する
+func NewPersonalAccessTokenHandler(tokenService *service.PersonalAccessTokenService, logger *slog.Logger) *PersonalAccessTokenHandler {
+	return &PersonalAccessTokenHandler{
+		tokenService: tokenService,
+		logger:       logger,
+	}
+}
+
+// RegisterRoutes はルートを登録する
+// トークンの管理はパーソナルアクセストークンでは行えない (auth.RequiredScope にルートがないため)
+func (h *PersonalAccessTokenHandler) RegisterRoutes(mux Router, logging, auth func(http.Handler) http.Handler) {
+	mux.Handle("GET /me/tokens", logging(auth(http.HandlerFunc(h.handleListTokens))))
+	mux.Handle("POST /me/tokens", logging(auth(http.HandlerFunc(h.handleCreateToken))))
+	mux.Handle("DELETE /me/tokens/{id}", logging(auth(http.HandlerFunc(h.handleRevokeToken))))
+}
+
+// handleCreateToken はパーソナルアクセストークンを作成するハンドラー
+// トークンはこのレスポンスでのみ返す
+func (h *PersonalAccessTokenHandler) handleCreateToken(w http.ResponseWriter, r *http.Request) {
+	// コンテキストからユーザーIDを取得
+	userIDStr, ok := middleware.GetUserIDFromContext(r.Context())
+	if !ok {
+		h.logger.Error("User ID not found in context")
+		http.Error(w, "Unauthorized", http.StatusUnauthorized)
+		return
+	}
+
+	var req dto.CreatePersonalAccessTokenRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		h.logger.Warn("Invalid personal access token request", slog.Any("error", err))
+		http.Error(w, "Invalid request format", http.StatusBadRequest)
+		return
+	}
+
+	token, err := h.tokenService.CreateToken(r.Context(), userIDStr, req)
+	if err != nil {
+		if httpError.IsAppError(err) {
+			httpError.WriteError(w, err)
+			return
+		}
+		h.logger.Error("Failed to create personal access token", slog.Any("error", err), slog.String("user_id", userIDStr))
+		http.Error(w, fmt.Sprintf("Failed to create personal access token: %v", err), http.StatusInternalServerError)
+		return
+	}
+
+	// レスポンス返却 (トークンをキャッシュさせない)
+	w.Header().Set("Content-Type", "application/json")
+	w.Header().Set("Cache-Control", "no-store")
+	w.WriteHeader(http.StatusCreated)
+	json.NewEncoder(w).Encode(token)
+}
+
+// handleListTokens はパーソナルアクセストークンの一覧を取得するハンドラー
+func (h *PersonalAccessTokenHandler) handleListTokens(w http.ResponseWriter, r *http.Request) {
+	// コンテキストからユーザーIDを取得
+	userIDStr, ok := middleware.GetUserIDFromContext(r.Context())
+	if !ok {
+		h.logger.Error("User ID not found in context")
+		http.Error(w, "Unauthorized", http.StatusUnauthorized)
+		return
+	}
+
+	tokens, err := h.tokenService.ListTokens(r.Context(), userIDStr)
+	if err != nil {
+		h.logger.Error("Failed to list personal access tokens", slog.Any("error", err), slog.String("user_id", userIDStr))
+		http.Error(w, fmt.Sprintf("Failed to list personal access tokens: %v", err), http.StatusInternalServerError)
+		return
+	}
+
+	// レスポンス返却
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(tokens)
+}
+
+// handleRevokeToken はパーソナルアクセストークンを失効させるハンドラー
+func (h *PersonalAccessTokenHandler) handleRevokeToken(w http.ResponseWriter, r *http.Request) {
+	// コンテキストからユーザーIDを取得
+	userIDStr, ok := middleware.GetUserIDFromContext(r.Context())
+	if !ok {
+		h.logger.Error("User ID not found in context")
+		http.Error(w, "Unauthorized", http.StatusUnauthorized)
+		return
+	}
+
+	tokenID, err := uuid.Parse(r.PathValue("id"))
+	if err != nil {
+		h.logger.Warn("Invalid personal access token ID", slog.String("id", r.PathValue("id")), slog.Any("error", err))
+		http.Error(w, "Invalid token ID", http.StatusBadRequest)
+		return
+	}
+
+	if err := h.tokenService.RevokeToken(r.Context(), userIDStr, tokenID); err != nil {
+		if httpError.IsAppError(err) {
+			httpError.WriteError(w, err)
+			return
+		}
+		h.logger.Error("Failed to revoke personal access token", slog.Any("error", err), slog.String("user_id", userIDStr), slog.String("token_id", tokenID.String()))
+		http.Error(w, fmt.Sprintf("Failed to revoke personal access token: %v", err), http.StatusInternalServerError)
+		return
+	}
+
+	w.WriteHeader(http.StatusNoContent)
+}
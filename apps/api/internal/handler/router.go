@@ -0,0 +1,8 @@
+package handler
+
+import "net/http"
+
+// Router はハンドラーがルートを登録する先 (*http.ServeMux、または登録したパターンを記録するもの)
+type Router interface {
+	Handle(pattern string, handler http.Handler)
+}
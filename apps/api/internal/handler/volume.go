@@ -1,6 +1,7 @@
 package handler
 
 import (
+	"context"
 	"encoding/json"
 	"fmt"
 	"log/slog"
@@ -8,13 +9,23 @@ import (
 	"strconv"
 	"time"
 
+	httpError "github.com/aiirononeko/bulktrack/apps/api/internal/http"
+	"github.com/aiirononeko/bulktrack/apps/api/internal/interfaces/http/dto"
 	"github.com/aiirononeko/bulktrack/apps/api/internal/interfaces/http/middleware"
 	"github.com/aiirononeko/bulktrack/apps/api/internal/interfaces/service"
 )
 
+// weeklyVolumeService は VolumeHandler が利用する週間ボリュームサービスのインターフェース
+type weeklyVolumeService interface {
+	GetWeeklyVolumes(ctx context.Context, userID string, weeksCount int32) (*dto.WeeklyVolumeSummaryResponse, error)
+	GetWeeklyVolumeForWeek(ctx context.Context, userID string, weekStartDate time.Time) (*dto.WeeklySummaryResponse, error)
+	RecalculateWeeklyVolume(ctx context.Context, userID string, weekStartDate time.Time) error
+	GetWeeklyVolumeStats(ctx context.Context, userID string, startDate, endDate time.Time) (*dto.WeeklyVolumeStatsResponse, error)
+}
+
 // VolumeHandler は週間トレーニングボリューム関連のハンドラーを提供する
 type VolumeHandler struct {
-	volumeService *service.VolumeService
+	volumeService weeklyVolumeService
 	logger        *slog.Logger
 }
 
@@ -27,7 +38,7 @@ func NewVolumeHandler(volumeService *service.VolumeService, logger *slog.Logger)
 }
 
 // RegisterRoutes はルートを登録する
-func (h *VolumeHandler) RegisterRoutes(mux *http.ServeMux, logging, auth func(http.Handler) http.Handler) {
+func (h *VolumeHandler) RegisterRoutes(mux Router, logging, auth func(http.Handler) http.Handler) {
 	// 週間ボリューム取得エンドポイント
 	mux.Handle("GET /v1/weekly-volume", logging(auth(http.HandlerFunc(h.handleGetWeeklyVolumes))))
 	mux.Handle("GET /v1/weekly-volume/{week}", logging(auth(http.HandlerFunc(h.handleGetWeeklyVolumeForWeek))))
@@ -66,10 +77,8 @@ func (h *VolumeHandler) handleGetWeeklyVolumes(w http.ResponseWriter, r *http.Re
 		return
 	}
 
-	// レスポンス返却
-	w.Header().Set("Content-Type", "application/json")
-	w.Header().Set("Cache-Control", "max-age=900, stale-while-revalidate") // 15分キャッシュ
-	json.NewEncoder(w).Encode(volumes)
+	// レスポンス返却 (記録した直後の変更を反映するため、クライアントは ETag で毎回確認する)
+	httpError.WriteJSONWithETag(w, r, volumes, httpError.CacheControlRevalidate)
 }
 
 // handleGetWeeklyVolumeForWeek は特定の週の週間ボリュームを取得するハンドラー
@@ -106,10 +115,8 @@ func (h *VolumeHandler) handleGetWeeklyVolumeForWeek(w http.ResponseWriter, r *h
 		return
 	}
 
-	// レスポンス返却
-	w.Header().Set("Content-Type", "application/json")
-	w.Header().Set("Cache-Control", "max-age=900, stale-while-revalidate") // 15分キャッシュ
-	json.NewEncoder(w).Encode(volume)
+	// レスポンス返却 (記録した直後の変更を反映するため、クライアントは ETag で毎回確認する)
+	httpError.WriteJSONWithETag(w, r, volume, httpError.CacheControlRevalidate)
 }
 
 // handleGetWeeklyVolumeStats は週間ボリューム統計を取得するハンドラー
@@ -160,10 +167,8 @@ func (h *VolumeHandler) handleGetWeeklyVolumeStats(w http.ResponseWriter, r *htt
 		return
 	}
 
-	// レスポンス返却
-	w.Header().Set("Content-Type", "application/json")
-	w.Header().Set("Cache-Control", "max-age=900, stale-while-revalidate") // 15分キャッシュ
-	json.NewEncoder(w).Encode(stats)
+	// レスポンス返却 (記録した直後の変更を反映するため、クライアントは ETag で毎回確認する)
+	httpError.WriteJSONWithETag(w, r, stats, httpError.CacheControlRevalidate)
 }
 
 // handleRecalculateWeeklyVolume は週間ボリュームを再計算するハンドラー
@@ -177,9 +182,7 @@ func (h *VolumeHandler) handleRecalculateWeeklyVolume(w http.ResponseWriter, r *
 	}
 
 	// リクエストボディから週の日付を取得
-	var req struct {
-		Week string `json:"week"`
-	}
+	var req dto.RecalculateWeeklyVolumeRequest
 	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
 		h.logger.Warn("Invalid request body", slog.Any("error", err))
 		http.Error(w, "Invalid request body", http.StatusBadRequest)
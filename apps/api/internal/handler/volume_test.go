@@ -14,6 +14,7 @@ import (
 
 	"github.com/aiirononeko/bulktrack/apps/api/internal/interfaces/http/dto"
 	"github.com/aiirononeko/bulktrack/apps/api/internal/interfaces/http/middleware"
+	"github.com/aiirononeko/bulktrack/apps/api/internal/interfaces/http/spec"
 )
 
 // mockVolumeService はテスト用のモックサービス
@@ -138,6 +139,11 @@ func TestWeeklyVolume_MissingWeeks(t *testing.T) {
 		t.Errorf("Expected status code %d, got %d", http.StatusOK, rr.Code)
 	}
 
+	// レスポンスが OpenAPI のドキュメントに従っていることを確認
+	if err := spec.Document().ValidateResponse("GET /v1/weekly-volume", rr.Code, rr.Header(), rr.Body.Bytes()); err != nil {
+		t.Errorf("Response does not match the OpenAPI document: %v", err)
+	}
+
 	// レスポンスボディを確認
 	var response dto.WeeklyVolumeSummaryResponse
 	err = json.Unmarshal(rr.Body.Bytes(), &response)
@@ -178,3 +184,48 @@ func TestWeeklyVolume_MissingWeeks(t *testing.T) {
 		}
 	}
 }
+
+// TestWeeklyVolume_ETag は ETag が一致する場合に 304 を返すことをテストする
+func TestWeeklyVolume_ETag(t *testing.T) {
+	total := 1000.0
+	handler := &VolumeHandler{
+		volumeService: &mockVolumeService{
+			getWeeklyVolumesFunc: func(ctx context.Context, userID string, weeksCount int32) (*dto.WeeklyVolumeSummaryResponse, error) {
+				return &dto.WeeklyVolumeSummaryResponse{Summaries: []dto.WeeklySummaryResponse{{Week: "2025-05-12T00:00:00Z", TotalVolume: total}}}, nil
+			},
+		},
+		logger: slog.Default(),
+	}
+
+	get := func(ifNoneMatch string) *httptest.ResponseRecorder {
+		req := httptest.NewRequest("GET", "/v1/weekly-volume", nil)
+		req = req.WithContext(context.WithValue(req.Context(), middleware.UserIDKey, "test-user-id"))
+		if ifNoneMatch != "" {
+			req.Header.Set("If-None-Match", ifNoneMatch)
+		}
+		rr := httptest.NewRecorder()
+		handler.handleGetWeeklyVolumes(rr, req)
+		return rr
+	}
+
+	first := get("")
+	etag := first.Header().Get("ETag")
+	if first.Code != http.StatusOK || etag == "" {
+		t.Fatalf("Expected 200 with an ETag, got %d and %q", first.Code, etag)
+	}
+	if cc := first.Header().Get("Cache-Control"); cc != "private, no-cache" {
+		t.Errorf("Expected Cache-Control %q, got %q", "private, no-cache", cc)
+	}
+
+	// 変更がない場合は本文なしの 304
+	if rr := get(etag); rr.Code != http.StatusNotModified || rr.Body.Len() != 0 {
+		t.Errorf("Expected 304 without a body, got %d with %q", rr.Code, rr.Body.String())
+	}
+
+	// 記録して週間ボリュームが変わった場合は新しい ETag で 200
+	total = 1500
+	rr := get(etag)
+	if rr.Code != http.StatusOK || rr.Header().Get("ETag") == etag {
+		t.Errorf("Expected 200 with a new ETag after the volume changed, got %d and %q", rr.Code, rr.Header().Get("ETag"))
+	}
+}
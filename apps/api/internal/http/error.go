@@ -20,6 +20,12 @@ func (rw *responseWriter) WriteHeader(code int) {
 	rw.ResponseWriter.WriteHeader(code)
 }
 
+// Unwrap returns the underlying ResponseWriter so that http.ResponseController can reach it
+// (e.g. to extend the write deadline or flush)
+func (rw *responseWriter) Unwrap() http.ResponseWriter {
+	return rw.ResponseWriter
+}
+
 // Status returns the captured status code
 func (rw *responseWriter) Status() int {
 	if rw.status == 0 {
@@ -44,6 +50,9 @@ const (
 	ErrorInvalidLimit       ErrorCode = "ERROR.INVALID_LIMIT"
 	ErrorInternalServer     ErrorCode = "ERROR.INTERNAL_SERVER"
 	ErrorPreconditionFailed ErrorCode = "ERROR.PRECONDITION_FAILED"
+	ErrorExportTooLarge     ErrorCode = "ERROR.EXPORT_TOO_LARGE"
+	ErrorTooManyRequests    ErrorCode = "ERROR.TOO_MANY_REQUESTS"
+	ErrorConflict           ErrorCode = "ERROR.CONFLICT"
 )
 
 // ValidationDetail represents a single validation error detail
@@ -179,6 +188,36 @@ func NewItemsTooManyError(message string, err error) *AppError {
 	}
 }
 
+// NewExportTooLargeError creates a new export too large error
+func NewExportTooLargeError(message string, err error) *AppError {
+	return &AppError{
+		Code:    ErrorExportTooLarge,
+		Message: message,
+		Err:     err,
+		Status:  http.StatusBadRequest,
+	}
+}
+
+// NewTooManyRequestsError creates a new too many requests (rate limited) error
+func NewTooManyRequestsError(message string, err error) *AppError {
+	return &AppError{
+		Code:    ErrorTooManyRequests,
+		Message: message,
+		Err:     err,
+		Status:  http.StatusTooManyRequests,
+	}
+}
+
+// NewConflictError creates a new conflict error (the request conflicts with the current state of the resource)
+func NewConflictError(message string, err error) *AppError {
+	return &AppError{
+		Code:    ErrorConflict,
+		Message: message,
+		Err:     err,
+		Status:  http.StatusConflict,
+	}
+}
+
 // NewExerciseNotFoundError creates a new exercise not found error
 func NewExerciseNotFoundError(message string, err error) *AppError {
 	return &AppError{
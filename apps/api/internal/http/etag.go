@@ -0,0 +1,63 @@
+package http
+
+import (
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/json"
+	"net/http"
+	"strings"
+
+	"github.com/aiirononeko/bulktrack/apps/api/internal/auth"
+)
+
+// Cache-Control values for responses written with WriteJSONWithETag
+const (
+	// CacheControlRevalidate lets the client keep the response but revalidate it with If-None-Match on every use
+	CacheControlRevalidate = "private, no-cache"
+	// CacheControlCatalog lets the client reuse master data for 5 minutes before revalidating
+	CacheControlCatalog = "private, max-age=300"
+)
+
+// ETag returns a strong entity tag computed from the response body
+func ETag(body []byte) string {
+	sum := sha256.Sum256(body)
+	return `"` + base64.RawURLEncoding.EncodeToString(sum[:16]) + `"`
+}
+
+// WriteJSONWithETag writes v as JSON with ETag and Cache-Control headers.
+// If the request's If-None-Match matches the ETag, it writes 304 Not Modified without a body.
+// Responses vary by the authenticated user and the coach's athlete header.
+func WriteJSONWithETag(w http.ResponseWriter, r *http.Request, v any, cacheControl string) error {
+	body, err := json.Marshal(v)
+	if err != nil {
+		return err
+	}
+	body = append(body, '\n') // same as json.Encoder
+
+	etag := ETag(body)
+	h := w.Header()
+	h.Set("ETag", etag)
+	h.Set("Cache-Control", cacheControl)
+	h.Set("Vary", "Authorization, "+auth.CoachAthleteHeader)
+
+	if ifNoneMatch := r.Header.Get("If-None-Match"); ifNoneMatch != "" && etagMatches(ifNoneMatch, etag) {
+		w.WriteHeader(http.StatusNotModified)
+		return nil
+	}
+	h.Set("Content-Type", "application/json")
+	_, err = w.Write(body)
+	return err
+}
+
+// etagMatches reports whether an If-None-Match header matches etag (weak comparison, RFC 9110 13.1.2)
+func etagMatches(ifNoneMatch, etag string) bool {
+	if strings.TrimSpace(ifNoneMatch) == "*" {
+		return true
+	}
+	for _, candidate := range strings.Split(ifNoneMatch, ",") {
+		if strings.TrimPrefix(strings.TrimSpace(candidate), "W/") == etag {
+			return true
+		}
+	}
+	return false
+}
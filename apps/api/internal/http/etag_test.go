@@ -0,0 +1,52 @@
+package http
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+)
+
+func TestWriteJSONWithETag(t *testing.T) {
+	body := map[string]int{"total_volume": 1200}
+
+	rec := httptest.NewRecorder()
+	req := httptest.NewRequest(http.MethodGet, "/v1/weekly-volume", nil)
+	if err := WriteJSONWithETag(rec, req, body, CacheControlRevalidate); err != nil {
+		t.Fatalf("WriteJSONWithETag() error = %v", err)
+	}
+	etag := rec.Header().Get("ETag")
+	if rec.Code != http.StatusOK || etag == "" || rec.Body.String() != "{\"total_volume\":1200}\n" {
+		t.Fatalf("first response = %d, ETag %q, body %q", rec.Code, etag, rec.Body.String())
+	}
+	if got := rec.Header().Get("Cache-Control"); got != CacheControlRevalidate {
+		t.Errorf("Cache-Control = %q, want %q", got, CacheControlRevalidate)
+	}
+
+	tests := []struct {
+		name        string
+		ifNoneMatch string
+		want        int
+	}{
+		{name: "same etag", ifNoneMatch: etag, want: http.StatusNotModified},
+		{name: "weak etag in a list", ifNoneMatch: `"other", W/` + etag, want: http.StatusNotModified},
+		{name: "any", ifNoneMatch: "*", want: http.StatusNotModified},
+		{name: "changed", ifNoneMatch: `"other"`, want: http.StatusOK},
+	}
+	for _, tt := range tests {
+		rec := httptest.NewRecorder()
+		req := httptest.NewRequest(http.MethodGet, "/v1/weekly-volume", nil)
+		req.Header.Set("If-None-Match", tt.ifNoneMatch)
+		if err := WriteJSONWithETag(rec, req, body, CacheControlRevalidate); err != nil {
+			t.Fatalf("%s: WriteJSONWithETag() error = %v", tt.name, err)
+		}
+		if rec.Code != tt.want {
+			t.Errorf("%s: status = %d, want %d", tt.name, rec.Code, tt.want)
+		}
+		if tt.want == http.StatusNotModified && rec.Body.Len() != 0 {
+			t.Errorf("%s: 304 response has a body %q", tt.name, rec.Body.String())
+		}
+		if rec.Header().Get("ETag") != etag {
+			t.Errorf("%s: ETag = %q, want %q", tt.name, rec.Header().Get("ETag"), etag)
+		}
+	}
+}
@@ -0,0 +1,108 @@
+-- name: CreateAccountDeletion :one
+-- Schedule the deletion of a user's account
+INSERT INTO account_deletions (user_id, source, scheduled_for)
+VALUES (sqlc.arg(user_id)::text, sqlc.arg(source)::text, sqlc.arg(scheduled_for)::timestamptz)
+RETURNING id, status, source, requested_at, scheduled_for, cancelled_at, completed_at;
+
+-- name: GetPendingAccountDeletion :one
+-- Get the scheduled (not yet cancelled or completed) deletion of a user's account
+SELECT id, status, source, requested_at, scheduled_for, cancelled_at, completed_at
+FROM account_deletions
+WHERE user_id = sqlc.arg(user_id)::text AND status = 'pending';
+
+-- name: CancelAccountDeletion :one
+-- Cancel the scheduled deletion of a user's account
+UPDATE account_deletions
+SET status = 'cancelled', cancelled_at = now()
+WHERE user_id = sqlc.arg(user_id)::text AND status = 'pending'
+RETURNING id, status, source, requested_at, scheduled_for, cancelled_at, completed_at;
+
+-- name: ClaimDueAccountDeletion :one
+-- Lock the next scheduled deletion whose grace period has ended (other instances skip locked rows)
+SELECT id, user_id
+FROM account_deletions
+WHERE status = 'pending' AND scheduled_for <= now()
+ORDER BY scheduled_for
+LIMIT 1
+FOR UPDATE SKIP LOCKED;
+
+-- name: CountDueAccountDeletions :one
+-- Count the scheduled deletions whose grace period has ended
+SELECT COUNT(*)::bigint
+FROM account_deletions
+WHERE status = 'pending' AND scheduled_for <= now();
+
+-- name: CompleteAccountDeletion :exec
+-- Mark a deletion as completed and erase the user ID, keeping only the purged row counts for auditing
+UPDATE account_deletions
+SET status = 'completed', user_id = NULL, completed_at = now(), purged_counts = sqlc.arg(purged_counts)::jsonb
+WHERE id = sqlc.arg(id);
+
+-- name: PurgeUserSets :execrows
+-- Delete all sets of a user's workouts
+DELETE FROM sets
+WHERE workout_id IN (SELECT id FROM workouts WHERE user_id = sqlc.arg(user_id)::text);
+
+-- name: PurgeUserWorkouts :execrows
+DELETE FROM workouts
+WHERE user_id = sqlc.arg(user_id)::text;
+
+-- name: PurgeUserMenus :execrows
+-- Delete all menus of a user (menu_items are deleted by ON DELETE CASCADE)
+DELETE FROM menus
+WHERE user_id = sqlc.arg(user_id)::text;
+
+-- name: PurgeUserCustomExercises :execrows
+-- Delete the custom exercises of a user that are no longer referenced by sets or menu items (ON DELETE RESTRICT)
+DELETE FROM exercises e
+WHERE
+    e.created_by_user_id = sqlc.arg(user_id)::text AND
+    NOT EXISTS (SELECT 1 FROM sets s WHERE s.exercise_id = e.id) AND
+    NOT EXISTS (SELECT 1 FROM menu_items mi WHERE mi.exercise_id = e.id);
+
+-- name: AnonymizeUserCustomExercises :execrows
+-- Remove the name and owner of the custom exercises of a user that are still referenced by other users' data
+UPDATE exercises
+SET name = 'deleted-' || id::text, created_by_user_id = 'deleted'
+WHERE created_by_user_id = sqlc.arg(user_id)::text;
+
+-- name: PurgeUserBodyMeasurements :execrows
+DELETE FROM body_measurements
+WHERE user_id = sqlc.arg(user_id)::text;
+
+-- name: PurgeUserExportJobs :execrows
+DELETE FROM export_jobs
+WHERE user_id = sqlc.arg(user_id)::text;
+
+-- name: PurgeUserJobs :execrows
+-- Delete background jobs of the user in any state (job args such as export.archive keep the user ID)
+-- A job still running saves its result to no row, which is ignored
+DELETE FROM jobs
+WHERE args->>'user_id' = sqlc.arg(user_id)::text;
+
+-- name: PurgeUserWeeklyVolumes :execrows
+DELETE FROM weekly_volumes
+WHERE user_id = sqlc.arg(user_id)::text;
+
+-- name: PurgeUserSettings :execrows
+DELETE FROM user_settings
+WHERE user_id = sqlc.arg(user_id)::text;
+
+-- name: AnonymizeUserWebhookEvents :execrows
+-- Erase the user ID from received webhook events (the events are kept to ignore redelivered events)
+UPDATE webhook_events
+SET user_id = NULL
+WHERE user_id = sqlc.arg(user_id)::text;
+
+-- name: PurgeUserPersonalAccessTokens :execrows
+DELETE FROM personal_access_tokens
+WHERE user_id = sqlc.arg(user_id)::text;
+
+-- name: PurgeUserCoachGrants :execrows
+-- Delete grants given by and to the user (audit logs are deleted by cascade)
+DELETE FROM coach_grants
+WHERE athlete_user_id = sqlc.arg(user_id)::text OR coach_user_id = sqlc.arg(user_id)::text;
+
+-- name: PurgeUserWeeklyVolumeDirtyWeeks :execrows
+DELETE FROM weekly_volume_dirty_weeks
+WHERE user_id = sqlc.arg(user_id)::text;
@@ -0,0 +1,131 @@
+-- name: CountVolumeBackfillWeeks :one
+-- Count the user-weeks in the range that have workouts or a weekly_volumes row (all users when user_id is NULL)
+SELECT COUNT(*)::bigint
+FROM (
+    SELECT w.user_id, get_jst_week_start(w.started_at) AS week_start_date
+    FROM workouts w
+    WHERE
+        w.started_at >= sqlc.arg(from_time)::timestamptz AND
+        w.started_at < sqlc.arg(to_time)::timestamptz AND
+        (sqlc.narg(user_id)::text IS NULL OR w.user_id = sqlc.narg(user_id)::text)
+    UNION
+    SELECT v.user_id, v.week_start_date
+    FROM weekly_volumes v
+    WHERE
+        v.week_start_date >= sqlc.arg(from_week)::date AND
+        v.week_start_date <= sqlc.arg(to_week)::date AND
+        (sqlc.narg(user_id)::text IS NULL OR v.user_id = sqlc.narg(user_id)::text)
+) AS weeks;
+
+-- name: ListVolumeBackfillWeeks :many
+-- List the user-weeks in the range that have workouts or a weekly_volumes row, ordered by (user_id, week_start_date)
+-- (the cursor is the last row of the previous page)
+SELECT weeks.user_id::text AS user_id, weeks.week_start_date::date AS week_start_date
+FROM (
+    SELECT w.user_id, get_jst_week_start(w.started_at) AS week_start_date
+    FROM workouts w
+    WHERE
+        w.started_at >= sqlc.arg(from_time)::timestamptz AND
+        w.started_at < sqlc.arg(to_time)::timestamptz AND
+        (sqlc.narg(user_id)::text IS NULL OR w.user_id = sqlc.narg(user_id)::text)
+    UNION
+    SELECT v.user_id, v.week_start_date
+    FROM weekly_volumes v
+    WHERE
+        v.week_start_date >= sqlc.arg(from_week)::date AND
+        v.week_start_date <= sqlc.arg(to_week)::date AND
+        (sqlc.narg(user_id)::text IS NULL OR v.user_id = sqlc.narg(user_id)::text)
+) AS weeks
+WHERE sqlc.narg(cursor_user_id)::text IS NULL
+    OR (weeks.user_id, weeks.week_start_date) > (sqlc.narg(cursor_user_id)::text, sqlc.narg(cursor_week_start_date)::date)
+ORDER BY weeks.user_id, weeks.week_start_date
+LIMIT sqlc.arg(page_limit)::int;
+
+-- name: ListIntegrityIssueCounts :many
+-- Count rows that violate invariants the schema does not enforce (all users when user_id is NULL)
+SELECT 'workouts_without_started_at'::text AS check_name, COUNT(*)::bigint AS issue_count
+FROM workouts w
+WHERE w.started_at IS NULL AND (sqlc.narg(user_id)::text IS NULL OR w.user_id = sqlc.narg(user_id)::text)
+UNION ALL
+SELECT 'sets_without_workout_or_exercise', COUNT(*)
+FROM sets s
+WHERE (s.workout_id IS NULL OR s.exercise_id IS NULL) AND sqlc.narg(user_id)::text IS NULL
+UNION ALL
+SELECT 'sets_with_other_users_exercise', COUNT(*)
+FROM sets s
+JOIN workouts w ON w.id = s.workout_id
+JOIN exercises e ON e.id = s.exercise_id
+WHERE
+    e.created_by_user_id IS NOT NULL AND e.created_by_user_id NOT IN (w.user_id, 'deleted') AND
+    (sqlc.narg(user_id)::text IS NULL OR w.user_id = sqlc.narg(user_id)::text)
+UNION ALL
+SELECT 'sets_with_invalid_metrics', COUNT(*)
+FROM sets s
+JOIN workouts w ON w.id = s.workout_id
+JOIN exercises e ON e.id = s.exercise_id
+WHERE
+    CASE e.metric_type
+        WHEN 'weight_reps' THEN s.duration_seconds IS NOT NULL OR s.distance_m IS NOT NULL OR (s.weight_kg IS NULL AND e.load_type = 'external')
+        WHEN 'reps' THEN COALESCE(s.weight_kg, 0) <> 0 OR s.duration_seconds IS NOT NULL OR s.distance_m IS NOT NULL
+        WHEN 'time' THEN s.duration_seconds IS NULL OR s.reps <> 0 OR s.distance_m IS NOT NULL
+        WHEN 'distance_time' THEN s.distance_m IS NULL OR s.duration_seconds IS NULL OR COALESCE(s.weight_kg, 0) <> 0 OR s.reps <> 0
+    END AND
+    (sqlc.narg(user_id)::text IS NULL OR w.user_id = sqlc.narg(user_id)::text)
+UNION ALL
+SELECT 'menu_items_without_menu_or_exercise', COUNT(*)
+FROM menu_items mi
+WHERE (mi.menu_id IS NULL OR mi.exercise_id IS NULL) AND sqlc.narg(user_id)::text IS NULL
+UNION ALL
+SELECT 'weekly_volumes_missing', COUNT(*)
+FROM (
+    SELECT DISTINCT w.user_id, get_jst_week_start(w.started_at) AS week_start_date
+    FROM workouts w
+    JOIN sets s ON s.workout_id = w.id
+    WHERE
+        s.set_type <> 'warmup' AND is_strength_exercise(s.exercise_id) AND
+        (sqlc.narg(user_id)::text IS NULL OR w.user_id = sqlc.narg(user_id)::text)
+) AS weeks
+WHERE NOT EXISTS (
+    SELECT 1 FROM weekly_volumes v
+    WHERE v.user_id = weeks.user_id AND v.week_start_date = weeks.week_start_date AND v.set_count > 0
+)
+UNION ALL
+SELECT 'weekly_volumes_without_workouts', COUNT(*)
+FROM weekly_volumes v
+WHERE
+    v.set_count > 0 AND
+    NOT EXISTS (
+        SELECT 1 FROM workouts w
+        WHERE
+            w.user_id = v.user_id AND
+            w.started_at >= (v.week_start_date::timestamp AT TIME ZONE 'Asia/Tokyo') AND
+            w.started_at < ((v.week_start_date + 7)::timestamp AT TIME ZONE 'Asia/Tokyo')
+    ) AND
+    (sqlc.narg(user_id)::text IS NULL OR v.user_id = sqlc.narg(user_id)::text)
+UNION ALL
+SELECT 'overdue_account_deletions', COUNT(*)
+FROM account_deletions d
+WHERE
+    d.status = 'pending' AND d.scheduled_for < now() - interval '1 day' AND
+    (sqlc.narg(user_id)::text IS NULL OR d.user_id = sqlc.narg(user_id)::text);
+
+-- name: GetUserDataSummary :one
+-- Count a user's data in each table (for inspecting a user from the admin command)
+SELECT
+    (SELECT COUNT(*) FROM workouts WHERE user_id = sqlc.arg(user_id)::text)::bigint AS workout_count,
+    (SELECT COUNT(*) FROM sets s JOIN workouts w ON w.id = s.workout_id WHERE w.user_id = sqlc.arg(user_id)::text)::bigint AS set_count,
+    (SELECT MIN(started_at) FROM workouts WHERE user_id = sqlc.arg(user_id)::text)::timestamptz AS first_workout_at,
+    (SELECT MAX(started_at) FROM workouts WHERE user_id = sqlc.arg(user_id)::text)::timestamptz AS last_workout_at,
+    (SELECT COUNT(*) FROM menus WHERE user_id = sqlc.arg(user_id)::text)::bigint AS menu_count,
+    (SELECT COUNT(*) FROM exercises WHERE created_by_user_id = sqlc.arg(user_id)::text)::bigint AS custom_exercise_count,
+    (SELECT COUNT(*) FROM body_measurements WHERE user_id = sqlc.arg(user_id)::text)::bigint AS body_measurement_count,
+    (SELECT COUNT(*) FROM weekly_volumes WHERE user_id = sqlc.arg(user_id)::text)::bigint AS weekly_volume_count,
+    (SELECT COUNT(*) FROM export_jobs WHERE user_id = sqlc.arg(user_id)::text)::bigint AS export_job_count,
+    (SELECT COUNT(*) FROM personal_access_tokens WHERE user_id = sqlc.arg(user_id)::text AND revoked_at IS NULL)::bigint AS active_token_count,
+    (SELECT COUNT(*) FROM coach_grants WHERE (athlete_user_id = sqlc.arg(user_id)::text OR coach_user_id = sqlc.arg(user_id)::text) AND status = 'active')::bigint AS active_coach_grant_count,
+    EXISTS (SELECT 1 FROM user_settings WHERE user_id = sqlc.arg(user_id)::text) AS has_settings,
+    (SELECT scheduled_for FROM account_deletions WHERE user_id = sqlc.arg(user_id)::text AND status = 'pending')::timestamptz AS deletion_scheduled_for;
+
+-- name: ListMuscleGroups :many
+SELECT id, name FROM muscle_groups
+ORDER BY name;
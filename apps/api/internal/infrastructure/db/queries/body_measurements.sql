@@ -0,0 +1,153 @@
+-- name: ListMeasurementMetrics :many
+SELECT code, name, unit, sort_order FROM measurement_metrics
+ORDER BY sort_order, code;
+
+-- name: GetMeasurementMetric :one
+SELECT code, name, unit, sort_order FROM measurement_metrics
+WHERE code = $1 LIMIT 1;
+
+-- name: CreateBodyMeasurement :one
+INSERT INTO body_measurements (
+  user_id, metric_code, value, measured_at, note
+) VALUES (
+  $1, $2, $3, $4, $5
+)
+RETURNING id, user_id, metric_code, value, measured_at, note, created_at, updated_at;
+
+-- name: ListBodyMeasurements :many
+-- 期間内の身体計測記録を新しい順に取得する (metric_code 未指定の場合は全項目)
+SELECT id, user_id, metric_code, value, measured_at, note, created_at, updated_at
+FROM body_measurements
+WHERE user_id = sqlc.arg(user_id)::text
+  AND (sqlc.narg(metric_code)::text IS NULL OR metric_code = sqlc.narg(metric_code)::text)
+  AND measured_at >= sqlc.arg(from_time)::timestamptz
+  AND measured_at < sqlc.arg(to_time)::timestamptz
+ORDER BY measured_at DESC;
+
+-- name: UpdateBodyMeasurement :one
+UPDATE body_measurements
+SET value = COALESCE(sqlc.narg(value)::numeric, value),
+    measured_at = COALESCE(sqlc.narg(measured_at)::timestamptz, measured_at),
+    note = NULLIF(COALESCE(sqlc.narg(note)::text, note), ''), -- 空文字列でメモを削除
+    updated_at = now()
+WHERE id = sqlc.arg(id) AND user_id = sqlc.arg(user_id)
+RETURNING id, user_id, metric_code, value, measured_at, note, created_at, updated_at;
+
+-- name: DeleteBodyMeasurement :execrows
+DELETE FROM body_measurements
+WHERE id = $1 AND user_id = $2;
+
+-- name: ListDailyMeasurementAverages :many
+-- 日ごと (JST) の平均値と7日移動平均を取得する
+WITH daily AS (
+    SELECT
+        (measured_at AT TIME ZONE 'Asia/Tokyo')::date AS measured_on,
+        AVG(value) AS avg_value
+    FROM body_measurements
+    WHERE user_id = sqlc.arg(user_id)::text
+      AND metric_code = sqlc.arg(metric_code)::text
+      -- 移動平均の計算のため開始日の6日前から集計する
+      AND (measured_at AT TIME ZONE 'Asia/Tokyo')::date >= sqlc.arg(from_date)::date - 6
+      AND (measured_at AT TIME ZONE 'Asia/Tokyo')::date <= sqlc.arg(to_date)::date
+    GROUP BY 1
+),
+smoothed AS (
+    SELECT
+        measured_on,
+        avg_value,
+        AVG(avg_value) OVER (
+            ORDER BY measured_on
+            RANGE BETWEEN INTERVAL '6 days' PRECEDING AND CURRENT ROW
+        ) AS moving_avg_7d
+    FROM daily
+)
+SELECT
+    measured_on::date AS measured_on,
+    avg_value::numeric AS avg_value,
+    moving_avg_7d::numeric AS moving_avg_7d
+FROM smoothed
+WHERE measured_on >= sqlc.arg(from_date)::date
+ORDER BY measured_on;
+
+-- name: ListWeeklyMeasurementAverages :many
+-- 週ごと (weekly_volumes と同じ JST 月曜始まり) の平均値を取得する
+-- from_date / to_date を含む週はその週の記録すべてを集計する (週の途中の from_date でも最初の週を落とさない)
+SELECT
+    get_jst_week_start(measured_at) AS week_start_date,
+    AVG(value)::numeric AS avg_value,
+    MIN(value)::numeric AS min_value,
+    MAX(value)::numeric AS max_value,
+    COUNT(*) AS measurement_count
+FROM body_measurements
+WHERE user_id = sqlc.arg(user_id)::text
+  AND metric_code = sqlc.arg(metric_code)::text
+  AND get_jst_week_start(measured_at) >= date_trunc('week', sqlc.arg(from_date)::date)::date
+  AND get_jst_week_start(measured_at) <= sqlc.arg(to_date)::date
+GROUP BY week_start_date
+ORDER BY week_start_date;
+
+-- name: ListRelativeStrengthByWeek :many
+-- 種目の週ごとの推定1RMと、その週末時点の体重を取得する (推定1RM/体重の算出用)
+WITH weekly_e1rm AS (
+    SELECT
+        get_jst_week_start(w.started_at) AS week_start_date,
+        MAX(set_effective_load_kg(w.user_id, s.exercise_id, s.weight_kg, w.started_at) * (1 + s.reps / 30.0)) AS est_one_rm
+    FROM workouts w
+    JOIN sets s ON w.id = s.workout_id
+    WHERE w.user_id = sqlc.arg(user_id)::text
+      AND s.exercise_id = sqlc.arg(exercise_id)::uuid
+      AND s.set_type <> 'warmup'
+      AND get_jst_week_start(w.started_at) >= sqlc.arg(from_date)::date
+    GROUP BY 1
+)
+SELECT
+    week_start_date::date AS week_start_date,
+    est_one_rm::numeric AS est_one_rm,
+    get_body_weight_on(sqlc.arg(user_id)::text, week_start_date::date + 6)::numeric AS body_weight_kg
+FROM weekly_e1rm
+ORDER BY week_start_date;
+
+-- name: ListDailyBodyWeights :many
+-- 互換 API (/body-weights) 用: 期間内の日ごと (JST) の体重を新しい順に取得する (同じ日に複数の記録がある場合は最新の記録)
+SELECT DISTINCT ON ((measured_at AT TIME ZONE 'Asia/Tokyo')::date)
+    (measured_at AT TIME ZONE 'Asia/Tokyo')::date AS measured_on,
+    value
+FROM body_measurements
+WHERE user_id = sqlc.arg(user_id)::text
+  AND metric_code = 'body_weight'
+  AND (measured_at AT TIME ZONE 'Asia/Tokyo')::date >= sqlc.arg(from_date)::date
+  AND (measured_at AT TIME ZONE 'Asia/Tokyo')::date <= sqlc.arg(to_date)::date
+ORDER BY (measured_at AT TIME ZONE 'Asia/Tokyo')::date DESC, measured_at DESC;
+
+-- name: UpsertDailyBodyWeight :one
+-- 互換 API (/body-weights) 用: その日 (JST) の最新の体重の記録を上書きする (記録がない場合はその日の 0:00 JST で作成する)
+WITH updated AS (
+    UPDATE body_measurements
+    SET value = sqlc.arg(value)::numeric,
+        updated_at = now()
+    WHERE id = (
+        SELECT latest.id FROM body_measurements latest
+        WHERE latest.user_id = sqlc.arg(user_id)::text
+          AND latest.metric_code = 'body_weight'
+          AND (latest.measured_at AT TIME ZONE 'Asia/Tokyo')::date = sqlc.arg(measured_on)::date
+        ORDER BY latest.measured_at DESC
+        LIMIT 1
+    )
+    RETURNING value
+),
+inserted AS (
+    INSERT INTO body_measurements (user_id, metric_code, value, measured_at)
+    SELECT sqlc.arg(user_id)::text, 'body_weight', sqlc.arg(value)::numeric, sqlc.arg(measured_on)::date::timestamp AT TIME ZONE 'Asia/Tokyo'
+    WHERE NOT EXISTS (SELECT 1 FROM updated)
+    RETURNING value
+)
+SELECT value FROM updated
+UNION ALL
+SELECT value FROM inserted;
+
+-- name: DeleteDailyBodyWeights :execrows
+-- 互換 API (/body-weights) 用: その日 (JST) の体重の記録をすべて削除する
+DELETE FROM body_measurements
+WHERE user_id = sqlc.arg(user_id)::text
+  AND metric_code = 'body_weight'
+  AND (measured_at AT TIME ZONE 'Asia/Tokyo')::date = sqlc.arg(measured_on)::date;
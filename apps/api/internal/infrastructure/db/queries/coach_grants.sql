@@ -0,0 +1,68 @@
+-- name: CreateCoachInvitation :one
+INSERT INTO coach_grants (athlete_user_id, permission, invite_code, invite_expires_at)
+VALUES (
+    sqlc.arg(athlete_user_id)::text,
+    sqlc.arg(permission)::text,
+    sqlc.arg(invite_code)::text,
+    sqlc.arg(invite_expires_at)::timestamptz
+)
+RETURNING id, athlete_user_id, coach_user_id, permission, status, invite_code, invite_expires_at, created_at, accepted_at;
+
+-- name: GetCoachInvitation :one
+-- Get a pending invitation that has not expired by its code
+SELECT id, athlete_user_id
+FROM coach_grants
+WHERE invite_code = sqlc.arg(invite_code)::text
+  AND status = 'pending'
+  AND invite_expires_at > now();
+
+-- name: AcceptCoachInvitation :one
+UPDATE coach_grants
+SET status = 'active', coach_user_id = sqlc.arg(coach_user_id)::text, invite_code = NULL, accepted_at = now()
+WHERE id = sqlc.arg(id) AND status = 'pending'
+RETURNING id, athlete_user_id, coach_user_id, permission, status, invite_code, invite_expires_at, created_at, accepted_at;
+
+-- name: ListCoachGrants :many
+-- List grants given by the user (as an athlete) and to the user (as a coach) that are not revoked
+SELECT id, athlete_user_id, coach_user_id, permission, status, invite_code, invite_expires_at, created_at, accepted_at
+FROM coach_grants
+WHERE (athlete_user_id = sqlc.arg(user_id)::text OR coach_user_id = sqlc.arg(user_id)::text)
+  AND (status = 'active' OR (status = 'pending' AND invite_expires_at > now()))
+ORDER BY created_at DESC;
+
+-- name: RevokeCoachGrant :execrows
+-- Revoke a grant or an invitation (either the athlete or the coach can revoke)
+UPDATE coach_grants
+SET status = 'revoked', invite_code = NULL, revoked_at = now()
+WHERE id = sqlc.arg(id)
+  AND (athlete_user_id = sqlc.arg(user_id)::text OR coach_user_id = sqlc.arg(user_id)::text)
+  AND status IN ('pending', 'active');
+
+-- name: GetActiveCoachGrant :one
+SELECT id, permission
+FROM coach_grants
+WHERE coach_user_id = sqlc.arg(coach_user_id)::text
+  AND athlete_user_id = sqlc.arg(athlete_user_id)::text
+  AND status = 'active';
+
+-- name: CreateCoachAuditLog :exec
+INSERT INTO coach_audit_logs (grant_id, coach_user_id, athlete_user_id, method, route, path, status_code)
+VALUES (
+    sqlc.arg(grant_id),
+    sqlc.arg(coach_user_id)::text,
+    sqlc.arg(athlete_user_id)::text,
+    sqlc.arg(method)::text,
+    sqlc.arg(route)::text,
+    sqlc.arg(path)::text,
+    sqlc.arg(status_code)::int
+);
+
+-- name: ListCoachAuditLogs :many
+-- List the audit logs of a grant the user is a party of (newest first)
+SELECT l.id, l.coach_user_id, l.method, l.route, l.path, l.status_code, l.created_at
+FROM coach_audit_logs l
+JOIN coach_grants g ON g.id = l.grant_id
+WHERE l.grant_id = sqlc.arg(grant_id)
+  AND (g.athlete_user_id = sqlc.arg(user_id)::text OR g.coach_user_id = sqlc.arg(user_id)::text)
+ORDER BY l.created_at DESC
+LIMIT sqlc.arg(page_limit)::int;
@@ -1,18 +1,24 @@
 -- name: GetExercise :one
-SELECT id, name, main_target_muscle_group_id, is_custom, created_by_user_id, created_at FROM exercises
+SELECT id, name, main_target_muscle_group_id, is_custom, created_by_user_id, created_at, load_type, metric_type FROM exercises
 WHERE id = $1 LIMIT 1;
 
 -- name: ListExercises :many
-SELECT id, name FROM exercises
+SELECT id, name, load_type, metric_type FROM exercises
 WHERE is_custom = false -- 基本的な種目のみリストアップ (カスタムは除く)
 ORDER BY name;
 
 -- name: CreateExercise :one
 INSERT INTO exercises (
-  name, main_target_muscle_group_id, is_custom, created_by_user_id
+  name, main_target_muscle_group_id, is_custom, created_by_user_id, load_type, metric_type
 ) VALUES (
-  $1, $2, $3, $4
+  $1, $2, $3, $4, $5, $6
 )
-RETURNING id, name, main_target_muscle_group_id, is_custom, created_by_user_id, created_at;
+RETURNING id, name, main_target_muscle_group_id, is_custom, created_by_user_id, created_at, load_type, metric_type;
+
+-- name: ListExercisesForUser :many
+-- List built-in exercises and the custom exercises created by the user
+SELECT id, name, load_type, metric_type FROM exercises
+WHERE created_by_user_id IS NULL OR created_by_user_id = sqlc.arg(user_id)::text
+ORDER BY created_by_user_id IS NOT NULL, name; -- 組み込み種目を先に返す
 
 -- TODO: 必要に応じて ListExercisesByUser (カスタム種目含む) や UpdateExercise, DeleteExercise などを追加
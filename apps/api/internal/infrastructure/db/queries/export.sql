@@ -0,0 +1,91 @@
+-- name: CountExportSetRows :one
+-- Count the rows of the set export (one row per set, or per workout without sets) for a user in the time range
+SELECT COUNT(*)
+FROM workouts w
+LEFT JOIN sets s ON w.id = s.workout_id
+WHERE
+    w.user_id = sqlc.arg(user_id)::text AND
+    (sqlc.narg(from_time)::timestamptz IS NULL OR w.started_at >= sqlc.narg(from_time)::timestamptz) AND
+    (sqlc.narg(to_time)::timestamptz IS NULL OR w.started_at < sqlc.narg(to_time)::timestamptz);
+
+-- name: ListExportSetRows :many
+-- List workouts and their sets for export, one row per set (workouts without sets have NULL set columns)
+-- Rows are ordered by (started_at, workout_id, set_order) and read in batches with a keyset cursor
+SELECT
+    w.id AS workout_id,
+    w.started_at,
+    m.name AS menu_name,
+    w.note AS workout_note,
+    s.id AS set_id,
+    s.exercise_id,
+    e.name AS exercise_name,
+    s.set_order,
+    s.set_type,
+    s.group_key,
+    s.weight_kg,
+    s.reps,
+    s.rir,
+    s.rpe,
+    s.duration_seconds,
+    s.distance_m,
+    s.avg_heart_rate
+FROM workouts w
+LEFT JOIN menus m ON w.menu_id = m.id
+LEFT JOIN sets s ON w.id = s.workout_id
+LEFT JOIN exercises e ON s.exercise_id = e.id
+WHERE
+    w.user_id = sqlc.arg(user_id)::text AND
+    (sqlc.narg(from_time)::timestamptz IS NULL OR w.started_at >= sqlc.narg(from_time)::timestamptz) AND
+    (sqlc.narg(to_time)::timestamptz IS NULL OR w.started_at < sqlc.narg(to_time)::timestamptz) AND
+    (sqlc.narg(cursor_started_at)::timestamptz IS NULL OR
+        (w.started_at, w.id, COALESCE(s.set_order, 0)) > (sqlc.narg(cursor_started_at)::timestamptz, sqlc.narg(cursor_workout_id)::uuid, sqlc.arg(cursor_set_order)::int))
+ORDER BY w.started_at, w.id, COALESCE(s.set_order, 0)
+LIMIT sqlc.arg(page_limit)::int;
+
+-- name: ListExportMenuItems :many
+-- List a user's menus and their items for export, one row per item (menus without items have NULL item columns)
+SELECT
+    m.id AS menu_id,
+    m.name AS menu_name,
+    m.description,
+    m.created_at,
+    mi.set_order,
+    e.name AS exercise_name,
+    mi.planned_sets,
+    mi.planned_reps,
+    mi.planned_interval_seconds,
+    mi.group_key
+FROM menus m
+LEFT JOIN menu_items mi ON m.id = mi.menu_id
+LEFT JOIN exercises e ON mi.exercise_id = e.id
+WHERE m.user_id = sqlc.arg(user_id)::text
+ORDER BY m.created_at, m.id, mi.set_order;
+
+-- name: ListExportCustomExercises :many
+-- List the custom exercises created by a user for export
+SELECT
+    e.id,
+    e.name,
+    mg.name AS main_muscle_group,
+    e.load_type,
+    e.metric_type,
+    e.created_at
+FROM exercises e
+LEFT JOIN muscle_groups mg ON e.main_target_muscle_group_id = mg.id
+WHERE e.created_by_user_id = sqlc.arg(user_id)::text
+ORDER BY e.created_at, e.name;
+
+-- name: ListExportWeeklyVolumes :many
+-- List a user's weekly volumes for export (start_date / end_date are optional week start dates)
+SELECT
+    week_start_date,
+    total_volume,
+    est_one_rm,
+    exercise_count,
+    set_count
+FROM weekly_volumes
+WHERE
+    user_id = sqlc.arg(user_id)::text AND
+    (sqlc.narg(start_date)::date IS NULL OR week_start_date >= sqlc.narg(start_date)::date) AND
+    (sqlc.narg(end_date)::date IS NULL OR week_start_date <= sqlc.narg(end_date)::date)
+ORDER BY week_start_date;
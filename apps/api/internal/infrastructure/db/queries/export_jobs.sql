@@ -0,0 +1,49 @@
+-- name: CreateExportJob :one
+-- Create a pending export job
+INSERT INTO export_jobs (user_id, from_date, to_date)
+VALUES (sqlc.arg(user_id)::text, sqlc.narg(from_date)::date, sqlc.narg(to_date)::date)
+RETURNING id, user_id, status, from_date, to_date, archive_size, error, created_at, started_at, completed_at, expires_at;
+
+-- name: GetExportJob :one
+-- Get an export job of a user without the archive
+SELECT id, user_id, status, from_date, to_date, archive_size, error, created_at, started_at, completed_at, expires_at
+FROM export_jobs
+WHERE id = sqlc.arg(id) AND user_id = sqlc.arg(user_id)::text;
+
+-- name: StartExportJob :exec
+-- Mark an export job as running
+UPDATE export_jobs
+SET status = 'running', started_at = now()
+WHERE id = sqlc.arg(id);
+
+-- name: CompleteExportJob :exec
+-- Store the archive (a large object) of an export job and keep it for 7 days
+UPDATE export_jobs
+SET
+    status = 'completed',
+    archive_oid = sqlc.arg(archive_oid)::oid,
+    archive_size = sqlc.arg(archive_size)::bigint,
+    completed_at = now(),
+    expires_at = now() + interval '7 days'
+WHERE id = sqlc.arg(id);
+
+-- name: FailExportJob :exec
+-- Mark an export job as failed with the error message
+UPDATE export_jobs
+SET status = 'failed', error = sqlc.arg(error)::text, completed_at = now()
+WHERE id = sqlc.arg(id);
+
+-- name: GetExportJobArchive :one
+-- Get the archive (a large object) of a completed export job that has not expired
+SELECT archive_oid::oid AS archive_oid, archive_size::bigint AS archive_size
+FROM export_jobs
+WHERE
+    id = sqlc.arg(id) AND
+    user_id = sqlc.arg(user_id)::text AND
+    status = 'completed' AND
+    expires_at > now();
+
+-- name: DeleteExpiredExportJobs :execrows
+-- Delete export jobs whose archive has expired
+DELETE FROM export_jobs
+WHERE expires_at <= now();
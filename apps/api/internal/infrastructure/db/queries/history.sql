@@ -0,0 +1,44 @@
+-- name: ListHistoryPeriods :many
+-- Get training totals per period (day / week / month in the given time zone) for a user in the time range
+-- Periods are returned newest first; cursor_period (exclusive) continues from the previous page
+-- set_count and total_volume count strength sets only (warm-up sets and time / distance_time exercises are excluded)
+SELECT
+    date_trunc(sqlc.arg(bucket)::text, w.started_at AT TIME ZONE sqlc.arg(tz)::text)::date AS period_start,
+    COUNT(DISTINCT w.id) AS session_count,
+    COUNT(s.id) FILTER (WHERE s.set_type <> 'warmup' AND e.metric_type IN ('weight_reps', 'reps')) AS set_count,
+    COALESCE(SUM(set_effective_load_kg(w.user_id, s.exercise_id, s.weight_kg, w.started_at) * s.reps)
+        FILTER (WHERE s.set_type <> 'warmup' AND e.metric_type IN ('weight_reps', 'reps')), 0)::NUMERIC AS total_volume
+FROM workouts w
+LEFT JOIN sets s ON w.id = s.workout_id
+LEFT JOIN exercises e ON s.exercise_id = e.id
+WHERE
+    w.user_id = sqlc.arg(user_id)::text AND
+    w.started_at >= sqlc.arg(from_time)::timestamptz AND
+    w.started_at < sqlc.arg(to_time)::timestamptz AND
+    (sqlc.narg(cursor_period)::date IS NULL OR
+        date_trunc(sqlc.arg(bucket)::text, w.started_at AT TIME ZONE sqlc.arg(tz)::text)::date < sqlc.narg(cursor_period)::date)
+GROUP BY 1
+ORDER BY 1 DESC
+LIMIT sqlc.arg(page_limit)::int;
+
+-- name: ListHistoryTopWeights :many
+-- Get the top weight per exercise and period (day / week / month in the given time zone) for a user in the time range
+-- Only weight_reps exercises are included and warm-up sets are excluded.
+-- The weight is the effective load (body weight for bodyweight exercises, see set_effective_load_kg)
+SELECT
+    date_trunc(sqlc.arg(bucket)::text, w.started_at AT TIME ZONE sqlc.arg(tz)::text)::date AS period_start,
+    e.id AS exercise_id,
+    e.name AS exercise_name,
+    MAX(set_effective_load_kg(w.user_id, s.exercise_id, s.weight_kg, w.started_at))::NUMERIC AS max_weight_kg,
+    COUNT(s.id) AS set_count
+FROM workouts w
+JOIN sets s ON w.id = s.workout_id
+JOIN exercises e ON s.exercise_id = e.id
+WHERE
+    w.user_id = sqlc.arg(user_id)::text AND
+    w.started_at >= sqlc.arg(from_time)::timestamptz AND
+    w.started_at < sqlc.arg(to_time)::timestamptz AND
+    s.set_type <> 'warmup' AND
+    e.metric_type = 'weight_reps'
+GROUP BY 1, e.id, e.name
+ORDER BY 1 DESC, max_weight_kg DESC, e.name;
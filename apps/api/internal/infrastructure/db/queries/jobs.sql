@@ -0,0 +1,144 @@
+-- name: InsertJob :one
+-- Enqueue a job (no row is returned when an available or running job has the same unique key)
+INSERT INTO jobs (kind, args, run_at, max_attempts, unique_key)
+VALUES (
+    sqlc.arg(kind)::text,
+    sqlc.arg(args)::jsonb,
+    sqlc.arg(run_at)::timestamptz,
+    sqlc.arg(max_attempts)::int,
+    sqlc.narg(unique_key)::text
+)
+ON CONFLICT (unique_key) WHERE state IN ('available', 'running') DO NOTHING
+RETURNING id;
+
+-- name: ClaimJobs :many
+-- Mark due jobs of the kinds as running, oldest first, and count the attempt
+-- Jobs locked by other instances are skipped
+UPDATE jobs j
+SET
+    state = 'running',
+    attempt = j.attempt + 1,
+    locked_by = sqlc.arg(locked_by)::text,
+    locked_at = now(),
+    updated_at = now()
+FROM (
+    SELECT q.id
+    FROM jobs q
+    WHERE
+        q.state = 'available' AND
+        q.run_at <= now() AND
+        q.kind = ANY(sqlc.arg(kinds)::text[])
+    ORDER BY q.run_at, q.id
+    LIMIT sqlc.arg(batch_size)::int
+    FOR UPDATE SKIP LOCKED
+) AS due
+WHERE j.id = due.id
+RETURNING j.id, j.kind, j.args, j.attempt, j.max_attempts;
+
+-- name: CompleteJob :exec
+UPDATE jobs
+SET
+    state = 'completed',
+    locked_by = NULL,
+    locked_at = NULL,
+    completed_at = now(),
+    updated_at = now()
+WHERE id = sqlc.arg(id)::bigint AND state = 'running';
+
+-- name: RetryJob :exec
+-- Schedule the next attempt of a job that returned an error
+UPDATE jobs
+SET
+    state = 'available',
+    run_at = sqlc.arg(run_at)::timestamptz,
+    last_error = sqlc.arg(error)::text,
+    locked_by = NULL,
+    locked_at = NULL,
+    updated_at = now()
+WHERE id = sqlc.arg(id)::bigint AND state = 'running';
+
+-- name: FailJob :exec
+-- Give up a job whose attempts are exhausted (or that cannot succeed by retrying)
+UPDATE jobs
+SET
+    state = 'failed',
+    last_error = sqlc.arg(error)::text,
+    locked_by = NULL,
+    locked_at = NULL,
+    failed_at = now(),
+    updated_at = now()
+WHERE id = sqlc.arg(id)::bigint AND state = 'running';
+
+-- name: ReleaseJobs :execrows
+-- Return running jobs interrupted by a shutdown to the queue without counting the attempt
+UPDATE jobs
+SET
+    state = 'available',
+    attempt = attempt - 1,
+    locked_by = NULL,
+    locked_at = NULL,
+    updated_at = now()
+WHERE id = ANY(sqlc.arg(ids)::bigint[]) AND state = 'running';
+
+-- name: RescueStaleJobs :execrows
+-- Return jobs of the kind left running by an instance that stopped (e.g. crashed) before locked_before to the queue
+-- The interrupted run counts as an attempt, so a job that always crashes the worker eventually fails
+UPDATE jobs
+SET
+    state = CASE WHEN attempt >= max_attempts THEN 'failed' ELSE 'available' END,
+    last_error = 'worker stopped before the job finished',
+    locked_by = NULL,
+    locked_at = NULL,
+    failed_at = CASE WHEN attempt >= max_attempts THEN now() END,
+    updated_at = now()
+WHERE
+    state = 'running' AND
+    kind = sqlc.arg(kind)::text AND
+    locked_at < sqlc.arg(locked_before)::timestamptz;
+
+-- name: DeleteFinishedJobs :execrows
+-- Delete jobs completed before completed_before and jobs failed before failed_before
+DELETE FROM jobs
+WHERE
+    (state = 'completed' AND completed_at < sqlc.arg(completed_before)::timestamptz) OR
+    (state = 'failed' AND failed_at < sqlc.arg(failed_before)::timestamptz);
+
+-- name: ListFailedJobs :many
+-- List failed jobs, newest first (the cursor is the last id of the previous page)
+SELECT id, kind, args, attempt, max_attempts, unique_key, last_error, created_at, failed_at
+FROM jobs
+WHERE
+    state = 'failed' AND
+    (sqlc.narg(kind)::text IS NULL OR kind = sqlc.narg(kind)::text) AND
+    (sqlc.narg(cursor_id)::bigint IS NULL OR id < sqlc.narg(cursor_id)::bigint)
+ORDER BY id DESC
+LIMIT sqlc.arg(page_limit)::int;
+
+-- name: RetryFailedJob :execrows
+-- Run a failed job again with a fresh set of attempts
+UPDATE jobs
+SET
+    state = 'available',
+    attempt = 0,
+    run_at = now(),
+    failed_at = NULL,
+    updated_at = now()
+WHERE id = sqlc.arg(id)::bigint AND state = 'failed';
+
+-- name: EnsureJobSchedule :exec
+-- Register a periodic job, or move its next run forward when the schedule became shorter
+INSERT INTO job_schedules (name, next_run_at)
+VALUES (sqlc.arg(name)::text, sqlc.arg(next_run_at)::timestamptz)
+ON CONFLICT (name) DO UPDATE SET
+    next_run_at = EXCLUDED.next_run_at,
+    updated_at = now()
+WHERE job_schedules.next_run_at > EXCLUDED.next_run_at;
+
+-- name: AdvanceJobSchedule :execrows
+-- Move a due periodic job to its next run (only one instance updates the row, and that instance enqueues the job)
+UPDATE job_schedules
+SET
+    next_run_at = sqlc.arg(next_run_at)::timestamptz,
+    last_run_at = now(),
+    updated_at = now()
+WHERE name = sqlc.arg(name)::text AND next_run_at <= sqlc.arg(now)::timestamptz;
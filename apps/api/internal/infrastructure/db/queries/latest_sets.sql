@@ -15,6 +15,11 @@ SELECT
     s.reps,
     s.rir,
     s.rpe,
+    s.set_type,
+    s.group_key,
+    s.duration_seconds,
+    s.distance_m,
+    set_effective_load_kg(w.user_id, s.exercise_id, s.weight_kg, w.started_at)::numeric AS effective_load_kg, -- 自重系の種目は体重を含めた負荷
     w.started_at
 FROM sets s
 JOIN exercises e ON s.exercise_id = e.id
@@ -1,17 +1,17 @@
 -- name: CreateMenuItem :one
 INSERT INTO menu_items (
-  menu_id, exercise_id, set_order, planned_sets, planned_reps, planned_interval_seconds
+  menu_id, exercise_id, set_order, planned_sets, planned_reps, planned_interval_seconds, group_key
 ) VALUES (
-  $1, $2, $3, $4, $5, $6
+  $1, $2, $3, $4, $5, $6, $7
 )
-RETURNING id, menu_id, exercise_id, set_order, planned_sets, planned_reps, planned_interval_seconds;
+RETURNING id, menu_id, exercise_id, set_order, planned_sets, planned_reps, planned_interval_seconds, group_key;
 
 -- name: GetMenuItem :one
-SELECT id, menu_id, exercise_id, set_order, planned_sets, planned_reps, planned_interval_seconds FROM menu_items
+SELECT id, menu_id, exercise_id, set_order, planned_sets, planned_reps, planned_interval_seconds, group_key FROM menu_items
 WHERE id = $1 LIMIT 1;
 
 -- name: ListMenuItemsByMenu :many
-SELECT mi.id, mi.menu_id, mi.exercise_id, e.name as exercise_name, mi.set_order, mi.planned_sets, mi.planned_reps, mi.planned_interval_seconds 
+SELECT mi.id, mi.menu_id, mi.exercise_id, e.name as exercise_name, e.metric_type, mi.set_order, mi.planned_sets, mi.planned_reps, mi.planned_interval_seconds, mi.group_key
 FROM menu_items mi
 JOIN exercises e ON mi.exercise_id = e.id
 WHERE mi.menu_id = $1
@@ -19,9 +19,9 @@ ORDER BY mi.set_order;
 
 -- name: UpdateMenuItem :one
 UPDATE menu_items
-SET exercise_id = $2, planned_sets = $3, planned_reps = $4, planned_interval_seconds = $5
+SET exercise_id = $2, planned_sets = $3, planned_reps = $4, planned_interval_seconds = $5, group_key = $6
 WHERE id = $1
-RETURNING id, menu_id, exercise_id, set_order, planned_sets, planned_reps, planned_interval_seconds;
+RETURNING id, menu_id, exercise_id, set_order, planned_sets, planned_reps, planned_interval_seconds, group_key;
 
 -- name: DeleteMenuItem :exec
 DELETE FROM menu_items
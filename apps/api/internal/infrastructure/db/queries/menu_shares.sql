@@ -0,0 +1,44 @@
+-- name: GetActiveMenuShare :one
+SELECT id, menu_id, share_code, is_public, clone_count, created_at
+FROM menu_shares
+WHERE menu_id = sqlc.arg(menu_id) AND revoked_at IS NULL;
+
+-- name: CreateMenuShare :one
+INSERT INTO menu_shares (menu_id, share_code)
+VALUES (sqlc.arg(menu_id), sqlc.arg(share_code)::text)
+RETURNING id, menu_id, share_code, is_public, clone_count, created_at;
+
+-- name: RevokeMenuShare :execrows
+UPDATE menu_shares
+SET revoked_at = now()
+WHERE menu_id = sqlc.arg(menu_id) AND revoked_at IS NULL;
+
+-- name: GetSharedMenu :one
+-- Get a shared menu by its share code (the owner is not returned)
+SELECT s.id, s.menu_id, s.share_code, s.is_public, s.clone_count, m.name, m.description
+FROM menu_shares s
+JOIN menus m ON m.id = s.menu_id
+WHERE s.share_code = sqlc.arg(share_code)::text AND s.revoked_at IS NULL;
+
+-- name: ListPublicMenuTemplates :many
+-- List the menus in the template library
+SELECT s.share_code, m.name, m.description, s.clone_count,
+       (SELECT COUNT(*) FROM menu_items mi WHERE mi.menu_id = m.id)::int AS item_count
+FROM menu_shares s
+JOIN menus m ON m.id = s.menu_id
+WHERE s.is_public AND s.revoked_at IS NULL
+ORDER BY s.created_at, m.name;
+
+-- name: ListSharedMenuItems :many
+-- List the items of a shared menu with the exercises (to preview and clone)
+SELECT mi.set_order, mi.planned_sets, mi.planned_reps, mi.planned_interval_seconds, mi.group_key,
+       e.id AS exercise_id, e.name AS exercise_name, e.created_by_user_id, e.main_target_muscle_group_id, e.load_type, e.metric_type
+FROM menu_items mi
+JOIN exercises e ON e.id = mi.exercise_id
+WHERE mi.menu_id = sqlc.arg(menu_id)
+ORDER BY mi.set_order;
+
+-- name: IncrementMenuShareCloneCount :exec
+UPDATE menu_shares
+SET clone_count = clone_count + 1
+WHERE id = sqlc.arg(id);
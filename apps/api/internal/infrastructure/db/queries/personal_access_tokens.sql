@@ -0,0 +1,44 @@
+-- name: CreatePersonalAccessToken :one
+INSERT INTO personal_access_tokens (user_id, name, token_hash, token_prefix, scopes, expires_at)
+VALUES (
+    sqlc.arg(user_id)::text,
+    sqlc.arg(name)::text,
+    sqlc.arg(token_hash)::bytea,
+    sqlc.arg(token_prefix)::text,
+    sqlc.arg(scopes)::text[],
+    sqlc.narg(expires_at)::timestamptz
+)
+RETURNING id, name, token_prefix, scopes, expires_at, last_used_at, created_at;
+
+-- name: ListPersonalAccessTokens :many
+-- List the user's tokens that are not revoked (including expired ones)
+SELECT id, name, token_prefix, scopes, expires_at, last_used_at, created_at
+FROM personal_access_tokens
+WHERE user_id = sqlc.arg(user_id)::text AND revoked_at IS NULL
+ORDER BY created_at DESC;
+
+-- name: CountActivePersonalAccessTokens :one
+SELECT COUNT(*)::int
+FROM personal_access_tokens
+WHERE user_id = sqlc.arg(user_id)::text
+  AND revoked_at IS NULL
+  AND (expires_at IS NULL OR expires_at > now());
+
+-- name: RevokePersonalAccessToken :execrows
+UPDATE personal_access_tokens
+SET revoked_at = now()
+WHERE id = sqlc.arg(id) AND user_id = sqlc.arg(user_id)::text AND revoked_at IS NULL;
+
+-- name: GetActivePersonalAccessToken :one
+-- Look up a token by its hash (revoked and expired tokens are not returned)
+SELECT id, user_id, scopes, last_used_at
+FROM personal_access_tokens
+WHERE token_hash = sqlc.arg(token_hash)::bytea
+  AND revoked_at IS NULL
+  AND (expires_at IS NULL OR expires_at > now());
+
+-- name: TouchPersonalAccessToken :exec
+-- Record the last use of a token (at most once a minute to avoid a write on every request)
+UPDATE personal_access_tokens
+SET last_used_at = now()
+WHERE id = sqlc.arg(id) AND (last_used_at IS NULL OR last_used_at < now() - interval '1 minute');
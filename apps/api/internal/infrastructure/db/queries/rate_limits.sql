@@ -0,0 +1,16 @@
+-- name: TakeRateLimitToken :one
+-- Refill the bucket for the time elapsed since the last request (up to capacity) and take one token.
+-- A missing bucket starts full. Time is measured on the database so all instances agree.
+INSERT INTO rate_limit_buckets AS b (key, tokens, allowed, updated_at)
+VALUES (sqlc.arg(key)::text, sqlc.arg(capacity)::double precision - 1, true, now())
+ON CONFLICT (key) DO UPDATE SET
+    tokens = LEAST(sqlc.arg(capacity)::double precision, b.tokens + EXTRACT(EPOCH FROM now() - b.updated_at)::double precision * sqlc.arg(refill_per_second)::double precision)
+        - CASE WHEN LEAST(sqlc.arg(capacity)::double precision, b.tokens + EXTRACT(EPOCH FROM now() - b.updated_at)::double precision * sqlc.arg(refill_per_second)::double precision) >= 1 THEN 1 ELSE 0 END,
+    allowed = LEAST(sqlc.arg(capacity)::double precision, b.tokens + EXTRACT(EPOCH FROM now() - b.updated_at)::double precision * sqlc.arg(refill_per_second)::double precision) >= 1,
+    updated_at = now()
+RETURNING tokens, allowed;
+
+-- name: DeleteIdleRateLimitBuckets :execrows
+-- Buckets idle longer than the longest window are full again, so deleting them does not change any result
+DELETE FROM rate_limit_buckets
+WHERE updated_at < sqlc.arg(idle_before)::timestamptz;
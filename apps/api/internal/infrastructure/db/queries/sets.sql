@@ -1,9 +1,9 @@
 -- name: GetSet :one
-SELECT id, workout_id, exercise_id, set_order, weight_kg, reps, rir, rpe FROM sets
+SELECT id, workout_id, exercise_id, set_order, weight_kg, reps, rir, rpe, set_type, group_key, duration_seconds, distance_m, avg_heart_rate FROM sets
 WHERE id = $1 LIMIT 1;
 
 -- name: ListSetsByWorkout :many
-SELECT s.id, s.workout_id, s.exercise_id, e.name as exercise_name, s.set_order, s.weight_kg, s.reps, s.rir, s.rpe
+SELECT s.id, s.workout_id, s.exercise_id, e.name as exercise_name, e.metric_type, s.set_order, s.weight_kg, s.reps, s.rir, s.rpe, s.set_type, s.group_key, s.duration_seconds, s.distance_m, s.avg_heart_rate
 FROM sets s
 JOIN exercises e ON s.exercise_id = e.id
 WHERE s.workout_id = $1
@@ -11,18 +11,34 @@ ORDER BY s.set_order;
 
 -- name: CreateSet :one
 INSERT INTO sets (
-  workout_id, exercise_id, set_order, weight_kg, reps, rir, rpe
+  workout_id, exercise_id, set_order, weight_kg, reps, rir, rpe, set_type, group_key, duration_seconds, distance_m, avg_heart_rate
 ) VALUES (
-  $1, $2, $3, $4, $5, $6, $7
+  $1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11, $12
 )
-RETURNING id, workout_id, exercise_id, set_order, weight_kg, reps, rir, rpe;
+RETURNING id, workout_id, exercise_id, set_order, weight_kg, reps, rir, rpe, set_type, group_key, duration_seconds, distance_m, avg_heart_rate;
 
 -- name: UpdateSet :one
 UPDATE sets
-SET weight_kg = $2, reps = $3, rir = $4, rpe = $5
-WHERE id = $1
-RETURNING id, workout_id, exercise_id, set_order, weight_kg, reps, rir, rpe;
+SET weight_kg = sqlc.arg(weight_kg), reps = sqlc.arg(reps), rir = sqlc.arg(rir), rpe = sqlc.arg(rpe),
+  set_type = COALESCE(sqlc.narg(set_type)::text, set_type),
+  group_key = NULLIF(COALESCE(sqlc.narg(group_key)::text, group_key), ''), -- 空文字列でグループ解除
+  duration_seconds = sqlc.narg(duration_seconds), distance_m = sqlc.narg(distance_m), avg_heart_rate = sqlc.narg(avg_heart_rate)
+WHERE id = sqlc.arg(id)
+RETURNING id, workout_id, exercise_id, set_order, weight_kg, reps, rir, rpe, set_type, group_key, duration_seconds, distance_m, avg_heart_rate;
 
 -- name: DeleteSet :exec
 DELETE FROM sets
 WHERE id = $1;
+
+-- name: CreateSetsBulk :copyfrom
+-- Bulk insert sets with COPY (used by workout history and user data imports)
+INSERT INTO sets (
+  workout_id, exercise_id, set_order, weight_kg, reps, rir, rpe, set_type, group_key, duration_seconds, distance_m, avg_heart_rate
+) VALUES (
+  $1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11, $12
+);
+
+-- name: SkipWeeklyVolumeTrigger :exec
+-- Skip queueing the weeks of set, workout and body weight changes for weekly_volumes aggregation until the end of the transaction
+-- The caller must recalculate the affected weeks (RecalculateWeeklyVolume) before committing
+SELECT set_config('bulktrack.skip_weekly_volume_trigger', 'on', true);
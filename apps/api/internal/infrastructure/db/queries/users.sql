@@ -0,0 +1,19 @@
+-- name: ProvisionUserSettings :execrows
+-- Create the default settings of a new user (no-op if they already exist)
+INSERT INTO user_settings (user_id)
+VALUES (sqlc.arg(user_id)::text)
+ON CONFLICT (user_id) DO NOTHING;
+
+-- name: CreateStarterMenu :execrows
+-- Create the starter menu (big 3) of a new user from the built-in exercises (no-op if a menu with the same name exists)
+WITH menu AS (
+    INSERT INTO menus (user_id, name, description)
+    VALUES (sqlc.arg(user_id)::text, 'はじめてのメニュー', 'スクワット・ベンチプレス・デッドリフトの基本メニュー')
+    ON CONFLICT (user_id, name) DO NOTHING
+    RETURNING id
+)
+INSERT INTO menu_items (menu_id, exercise_id, set_order, planned_sets, planned_reps, planned_interval_seconds)
+SELECT menu.id, e.id, item.set_order, 3, item.planned_reps, 180
+FROM menu
+CROSS JOIN (VALUES (1, 'スクワット', 8), (2, 'ベンチプレス', 8), (3, 'デッドリフト', 5)) AS item (set_order, exercise_name, planned_reps)
+JOIN exercises e ON e.name = item.exercise_name AND e.created_by_user_id IS NULL;
@@ -80,15 +80,17 @@ WITH volume_data AS (
     SELECT 
         w.user_id,
         get_jst_week_start(w.started_at) AS week_start_date,
-        SUM(s.weight_kg * s.reps) AS total_volume,
-        MAX(s.weight_kg * (1 + s.reps / 30.0)) AS est_one_rm,
+        SUM(set_effective_load_kg(w.user_id, s.exercise_id, s.weight_kg, w.started_at) * s.reps) AS total_volume,
+        MAX(set_effective_load_kg(w.user_id, s.exercise_id, s.weight_kg, w.started_at) * (30 + s.reps) / 30.0) AS est_one_rm, -- 丸めが Go の集計と一致するよう先に掛ける
         COUNT(DISTINCT s.exercise_id) AS exercise_count,
         COUNT(s.id) AS set_count
     FROM workouts w
     JOIN sets s ON w.id = s.workout_id
     WHERE 
         w.user_id = sqlc.arg(user_id)::text AND
-        get_jst_week_start(w.started_at) = sqlc.arg(week_start_date)::date
+        get_jst_week_start(w.started_at) = sqlc.arg(week_start_date)::date AND
+        s.set_type <> 'warmup' AND -- ウォームアップセットは集計対象外
+        is_strength_exercise(s.exercise_id) -- 有酸素・時間計測の種目は集計対象外
     GROUP BY w.user_id, week_start_date
 )
 INSERT INTO weekly_volumes (
@@ -121,15 +123,17 @@ DO UPDATE SET
 SELECT 
     e.id AS exercise_id,
     e.name AS exercise_name,
-    SUM(s.weight_kg * s.reps) AS total_volume,
-    MAX(s.weight_kg * (1 + s.reps / 30.0)) AS est_one_rm,
+    SUM(set_effective_load_kg(w.user_id, s.exercise_id, s.weight_kg, w.started_at) * s.reps) AS total_volume,
+    MAX(set_effective_load_kg(w.user_id, s.exercise_id, s.weight_kg, w.started_at) * (1 + s.reps / 30.0)) AS est_one_rm,
     COUNT(s.id) AS set_count
 FROM workouts w
 JOIN sets s ON w.id = s.workout_id
 JOIN exercises e ON s.exercise_id = e.id
 WHERE 
     w.user_id = sqlc.arg(user_id)::text AND
-    get_jst_week_start(w.started_at) = sqlc.arg(week_start_date)::date
+    get_jst_week_start(w.started_at) = sqlc.arg(week_start_date)::date AND
+    (sqlc.arg(include_warmups)::boolean OR s.set_type <> 'warmup') AND
+    e.metric_type IN ('weight_reps', 'reps')
 GROUP BY e.id, e.name
 ORDER BY total_volume DESC;
 
@@ -138,7 +142,7 @@ ORDER BY total_volume DESC;
 SELECT 
     mg.id AS muscle_group_id,
     mg.name AS muscle_group_name,
-    SUM(s.weight_kg * s.reps) AS total_volume,
+    SUM(set_effective_load_kg(w.user_id, s.exercise_id, s.weight_kg, w.started_at) * s.reps) AS total_volume,
     COUNT(DISTINCT e.id) AS exercise_count,
     COUNT(s.id) AS set_count
 FROM workouts w
@@ -148,6 +152,180 @@ JOIN exercise_target_muscle_groups etmg ON e.id = etmg.exercise_id
 JOIN muscle_groups mg ON etmg.muscle_group_id = mg.id
 WHERE 
     w.user_id = sqlc.arg(user_id)::text AND
-    get_jst_week_start(w.started_at) = sqlc.arg(week_start_date)::date
+    get_jst_week_start(w.started_at) = sqlc.arg(week_start_date)::date AND
+    (sqlc.arg(include_warmups)::boolean OR s.set_type <> 'warmup') AND
+    e.metric_type IN ('weight_reps', 'reps')
 GROUP BY mg.id, mg.name
 ORDER BY total_volume DESC;
+
+-- name: ListWeeklyCardioTotals :many
+-- Get weekly cardio / timed exercise totals (time, distance_time) for a user in the date range
+-- Reported separately from total_volume so that strength numbers are not affected
+SELECT 
+    get_jst_week_start(w.started_at) AS week_start_date,
+    COALESCE(SUM(s.duration_seconds), 0)::BIGINT AS total_duration_seconds,
+    COALESCE(SUM(s.distance_m), 0)::NUMERIC AS total_distance_m,
+    COUNT(s.id) AS set_count
+FROM workouts w
+JOIN sets s ON w.id = s.workout_id
+JOIN exercises e ON s.exercise_id = e.id
+WHERE 
+    w.user_id = sqlc.arg(user_id)::text AND
+    get_jst_week_start(w.started_at) >= sqlc.arg(start_date)::date AND
+    get_jst_week_start(w.started_at) <= sqlc.arg(end_date)::date AND
+    e.metric_type IN ('time', 'distance_time')
+GROUP BY get_jst_week_start(w.started_at)
+ORDER BY week_start_date;
+
+-- name: ListWeeklyVolumeUserIDs :many
+-- List the users that have workouts or weekly_volumes rows, ordered by user_id (the cursor is the last user_id of the previous page)
+SELECT users.user_id::text AS user_id
+FROM (
+    SELECT w.user_id FROM workouts w
+    UNION
+    SELECT v.user_id FROM weekly_volumes v
+) AS users
+WHERE sqlc.narg(cursor_user_id)::text IS NULL OR users.user_id > sqlc.narg(cursor_user_id)::text
+ORDER BY users.user_id
+LIMIT sqlc.arg(page_limit)::int;
+
+-- name: ListWeeklyVolumeDrift :many
+-- Compare a user's weekly_volumes with a fresh aggregate from sets and workouts and list the weeks that differ
+-- (a missing row is treated as a row of zeros, so weeks whose sets were all deleted are not reported)
+WITH fresh AS (
+    SELECT 
+        get_jst_week_start(w.started_at) AS week_start_date,
+        SUM(set_effective_load_kg(w.user_id, s.exercise_id, s.weight_kg, w.started_at) * s.reps)::NUMERIC(10,2) AS total_volume,
+        MAX(set_effective_load_kg(w.user_id, s.exercise_id, s.weight_kg, w.started_at) * (30 + s.reps) / 30.0)::NUMERIC(10,2) AS est_one_rm,
+        COUNT(DISTINCT s.exercise_id)::int AS exercise_count,
+        COUNT(s.id)::int AS set_count
+    FROM workouts w
+    JOIN sets s ON w.id = s.workout_id
+    WHERE 
+        w.user_id = sqlc.arg(user_id)::text AND
+        s.set_type <> 'warmup' AND
+        is_strength_exercise(s.exercise_id)
+    GROUP BY get_jst_week_start(w.started_at)
+), stored AS (
+    SELECT week_start_date, total_volume, est_one_rm, exercise_count, set_count
+    FROM weekly_volumes
+    WHERE user_id = sqlc.arg(user_id)::text
+)
+SELECT 
+    COALESCE(f.week_start_date, st.week_start_date)::date AS week_start_date,
+    COALESCE(st.total_volume, 0)::NUMERIC AS stored_total_volume,
+    COALESCE(f.total_volume, 0)::NUMERIC AS fresh_total_volume,
+    COALESCE(st.est_one_rm, 0)::NUMERIC AS stored_est_one_rm,
+    COALESCE(f.est_one_rm, 0)::NUMERIC AS fresh_est_one_rm,
+    COALESCE(st.exercise_count, 0)::int AS stored_exercise_count,
+    COALESCE(f.exercise_count, 0)::int AS fresh_exercise_count,
+    COALESCE(st.set_count, 0)::int AS stored_set_count,
+    COALESCE(f.set_count, 0)::int AS fresh_set_count
+FROM fresh f
+FULL OUTER JOIN stored st ON st.week_start_date = f.week_start_date
+WHERE 
+    COALESCE(st.total_volume, 0) <> COALESCE(f.total_volume, 0) OR
+    COALESCE(st.est_one_rm, 0) <> COALESCE(f.est_one_rm, 0) OR
+    COALESCE(st.exercise_count, 0) <> COALESCE(f.exercise_count, 0) OR
+    COALESCE(st.set_count, 0) <> COALESCE(f.set_count, 0)
+ORDER BY 1;
+
+-- name: QueueWeeklyVolumes :exec
+-- Queue user-weeks of a user for aggregation (the aggregation deletes the rows of weeks that no longer have sets)
+INSERT INTO weekly_volume_dirty_weeks (user_id, week_start_date)
+SELECT sqlc.arg(user_id)::text, d.week_start_date
+FROM unnest(sqlc.arg(week_start_dates)::date[]) AS d(week_start_date)
+ON CONFLICT (user_id, week_start_date) DO NOTHING;
+
+-- name: ClaimDirtyWeeklyVolumes :many
+-- Take user-weeks queued before marked_before out of the aggregation queue, oldest first
+-- The rows stay locked until the transaction ends: other workers skip them, and changes committed meanwhile
+-- wait for the transaction and queue the week again
+DELETE FROM weekly_volume_dirty_weeks d
+USING (
+    SELECT q.user_id, q.week_start_date
+    FROM weekly_volume_dirty_weeks q
+    WHERE q.marked_at <= sqlc.arg(marked_before)::timestamptz
+    ORDER BY q.marked_at
+    LIMIT sqlc.arg(batch_size)::int
+    FOR UPDATE SKIP LOCKED
+) AS claimed
+WHERE d.user_id = claimed.user_id AND d.week_start_date = claimed.week_start_date
+RETURNING d.user_id, d.week_start_date;
+
+-- name: ClaimUserDirtyWeeklyVolumes :many
+-- Take all queued weeks of a user out of the aggregation queue (waits for workers aggregating them)
+DELETE FROM weekly_volume_dirty_weeks
+WHERE user_id = sqlc.arg(user_id)::text
+RETURNING user_id, week_start_date;
+
+-- name: ListWeeklyVolumeSets :many
+-- List the sets counted in weekly_volumes (no warm-up sets, strength exercises only) of the user-weeks
+-- Weights are returned in hundredths of a kilogram so that the aggregation in Go is exact
+SELECT 
+    d.user_id::text AS user_id,
+    d.week_start_date::date AS week_start_date,
+    (w.started_at AT TIME ZONE 'Asia/Tokyo')::date AS workout_date,
+    s.exercise_id,
+    COALESCE(e.load_type, 'external')::text AS load_type,
+    (COALESCE(s.weight_kg, 0) * 100)::bigint AS weight_centi,
+    s.reps
+FROM unnest(sqlc.arg(user_ids)::text[], sqlc.arg(week_start_dates)::date[]) AS d(user_id, week_start_date)
+JOIN workouts w ON 
+    w.user_id = d.user_id AND
+    w.started_at >= (d.week_start_date::timestamp AT TIME ZONE 'Asia/Tokyo') AND
+    w.started_at < ((d.week_start_date + 7)::timestamp AT TIME ZONE 'Asia/Tokyo')
+JOIN sets s ON s.workout_id = w.id
+LEFT JOIN exercises e ON e.id = s.exercise_id
+WHERE 
+    s.set_type <> 'warmup' AND
+    COALESCE(e.metric_type IN ('weight_reps', 'reps'), TRUE);
+
+-- name: ListWeeklyVolumeBodyWeights :many
+-- List the body weights of the users (in hundredths of a kilogram) in order of measurement
+SELECT 
+    user_id,
+    (measured_at AT TIME ZONE 'Asia/Tokyo')::date AS measured_date,
+    (value * 100)::bigint AS value_centi
+FROM body_measurements
+WHERE user_id = ANY(sqlc.arg(user_ids)::text[]) AND metric_code = 'body_weight'
+ORDER BY user_id, measured_at;
+
+-- name: UpsertWeeklyVolumes :exec
+-- Save aggregated weekly volumes (volumes in hundredths of a kilogram)
+INSERT INTO weekly_volumes (
+    user_id,
+    week_start_date,
+    total_volume,
+    est_one_rm,
+    exercise_count,
+    set_count
+)
+SELECT 
+    v.user_id,
+    v.week_start_date,
+    v.total_volume_centi / 100.0,
+    v.est_one_rm_centi / 100.0,
+    v.exercise_count,
+    v.set_count
+FROM unnest(
+    sqlc.arg(user_ids)::text[],
+    sqlc.arg(week_start_dates)::date[],
+    sqlc.arg(total_volume_centis)::bigint[],
+    sqlc.arg(est_one_rm_centis)::bigint[],
+    sqlc.arg(exercise_counts)::int[],
+    sqlc.arg(set_counts)::int[]
+) AS v(user_id, week_start_date, total_volume_centi, est_one_rm_centi, exercise_count, set_count)
+ON CONFLICT (user_id, week_start_date) 
+DO UPDATE SET
+    total_volume = EXCLUDED.total_volume,
+    est_one_rm = EXCLUDED.est_one_rm,
+    exercise_count = EXCLUDED.exercise_count,
+    set_count = EXCLUDED.set_count,
+    updated_at = now();
+
+-- name: DeleteWeeklyVolumes :exec
+-- Delete the weekly volumes of user-weeks that no longer have sets
+DELETE FROM weekly_volumes v
+USING unnest(sqlc.arg(user_ids)::text[], sqlc.arg(week_start_dates)::date[]) AS d(user_id, week_start_date)
+WHERE v.user_id = d.user_id AND v.week_start_date = d.week_start_date;
@@ -0,0 +1,38 @@
+-- name: CreateWebhookEvent :one
+-- Record a received webhook event (returns no rows if the event was already received)
+INSERT INTO webhook_events (source, event_id, type, user_id)
+VALUES (sqlc.arg(source)::text, sqlc.arg(event_id)::text, sqlc.arg(type)::text, sqlc.narg(user_id)::text)
+ON CONFLICT (source, event_id) DO NOTHING
+RETURNING id, source, event_id, type, user_id, status, attempts;
+
+-- name: GetWebhookEvent :one
+-- Get a received webhook event by the event ID of the source
+SELECT id, source, event_id, type, user_id, status, attempts
+FROM webhook_events
+WHERE source = sqlc.arg(source)::text AND event_id = sqlc.arg(event_id)::text;
+
+-- name: GetWebhookEventByID :one
+-- Get a received webhook event by its ID (for the retry job of the event)
+SELECT id, source, event_id, type, user_id, status, attempts
+FROM webhook_events
+WHERE id = sqlc.arg(id);
+
+-- name: MarkWebhookEventProcessed :exec
+UPDATE webhook_events
+SET status = 'processed', attempts = attempts + 1, last_error = NULL, processed_at = now(), next_attempt_at = NULL
+WHERE id = sqlc.arg(id);
+
+-- name: MarkWebhookEventFailed :exec
+-- Mark a webhook event as failed and record when the retry job runs next (1, 2, 4, ... minutes after each attempt)
+UPDATE webhook_events
+SET
+    status = 'failed',
+    attempts = attempts + 1,
+    last_error = sqlc.arg(last_error)::text,
+    next_attempt_at = now() + make_interval(mins => power(2, attempts)::int)
+WHERE id = sqlc.arg(id);
+
+-- name: DeleteOldWebhookEvents :execrows
+-- Delete processed events older than 30 days (they are only kept to ignore redelivered events)
+DELETE FROM webhook_events
+WHERE status = 'processed' AND processed_at <= now() - interval '30 days';
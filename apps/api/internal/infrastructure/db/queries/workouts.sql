@@ -2,10 +2,39 @@
 SELECT * FROM workouts
 WHERE id = $1 LIMIT 1;
 
--- name: ListWorkoutsByUser :many
-SELECT * FROM workouts
-WHERE user_id = sqlc.arg(user_id)::text
-ORDER BY started_at DESC;
+-- name: ListWorkoutsPageAsc :many
+-- ワークアウト一覧を古い順に取得する (カーソルは直前のページ末尾の started_at と id)
+SELECT w.id, w.menu_id, COALESCE(m.name, '')::text AS menu_name, w.started_at, w.note
+FROM workouts w
+LEFT JOIN menus m ON m.id = w.menu_id
+WHERE w.user_id = sqlc.arg(user_id)::text
+  AND (sqlc.narg(from_time)::timestamptz IS NULL OR w.started_at >= sqlc.narg(from_time)::timestamptz)
+  AND (sqlc.narg(to_time)::timestamptz IS NULL OR w.started_at < sqlc.narg(to_time)::timestamptz)
+  AND (sqlc.narg(menu_id)::uuid IS NULL OR w.menu_id = sqlc.narg(menu_id)::uuid)
+  AND (sqlc.narg(exercise_id)::uuid IS NULL OR EXISTS (
+    SELECT 1 FROM sets s WHERE s.workout_id = w.id AND s.exercise_id = sqlc.narg(exercise_id)::uuid
+  ))
+  AND (sqlc.narg(cursor_started_at)::timestamptz IS NULL
+    OR (w.started_at, w.id) > (sqlc.narg(cursor_started_at)::timestamptz, sqlc.narg(cursor_id)::uuid))
+ORDER BY w.started_at ASC, w.id ASC
+LIMIT sqlc.arg(page_limit)::int;
+
+-- name: ListWorkoutsPageDesc :many
+-- ワークアウト一覧を新しい順に取得する (カーソルは直前のページ末尾の started_at と id)
+SELECT w.id, w.menu_id, COALESCE(m.name, '')::text AS menu_name, w.started_at, w.note
+FROM workouts w
+LEFT JOIN menus m ON m.id = w.menu_id
+WHERE w.user_id = sqlc.arg(user_id)::text
+  AND (sqlc.narg(from_time)::timestamptz IS NULL OR w.started_at >= sqlc.narg(from_time)::timestamptz)
+  AND (sqlc.narg(to_time)::timestamptz IS NULL OR w.started_at < sqlc.narg(to_time)::timestamptz)
+  AND (sqlc.narg(menu_id)::uuid IS NULL OR w.menu_id = sqlc.narg(menu_id)::uuid)
+  AND (sqlc.narg(exercise_id)::uuid IS NULL OR EXISTS (
+    SELECT 1 FROM sets s WHERE s.workout_id = w.id AND s.exercise_id = sqlc.narg(exercise_id)::uuid
+  ))
+  AND (sqlc.narg(cursor_started_at)::timestamptz IS NULL
+    OR (w.started_at, w.id) < (sqlc.narg(cursor_started_at)::timestamptz, sqlc.narg(cursor_id)::uuid))
+ORDER BY w.started_at DESC, w.id DESC
+LIMIT sqlc.arg(page_limit)::int;
 
 -- name: CreateWorkout :one
 INSERT INTO workouts (
@@ -24,3 +53,16 @@ RETURNING *;
 -- name: DeleteWorkout :exec
 DELETE FROM workouts
 WHERE id = $1;
+
+-- name: CreateWorkoutsBulk :copyfrom
+-- Bulk insert workouts with COPY (used by workout history and user data imports; id and started_at are set by the caller)
+INSERT INTO workouts (id, user_id, menu_id, started_at, note) VALUES ($1, $2, $3, $4, $5);
+
+-- name: ListWorkoutStartTimes :many
+-- List the start times of a user's workouts in the time range (used to skip duplicates when importing)
+SELECT started_at
+FROM workouts
+WHERE
+    user_id = sqlc.arg(user_id)::text AND
+    started_at >= sqlc.arg(from_time)::timestamptz AND
+    started_at <= sqlc.arg(to_time)::timestamptz;
@@ -12,13 +12,21 @@ CREATE TABLE muscle_groups (
 -- 種目マスター
 CREATE TABLE exercises (
     id UUID PRIMARY KEY DEFAULT uuid_generate_v4(),
-    name TEXT UNIQUE NOT NULL,
+    name TEXT NOT NULL,
     main_target_muscle_group_id UUID REFERENCES muscle_groups(id),
     is_custom BOOLEAN DEFAULT FALSE,
     created_by_user_id TEXT, -- UUID REFERENCES users(id) ON DELETE SET NULL から変更
-    created_at TIMESTAMPTZ DEFAULT now()
+    created_at TIMESTAMPTZ DEFAULT now(),
+    -- 負荷の種類: external (外部負荷のみ) / bodyweight (自重) / bodyweight_plus (自重 + 加重) / assisted (自重 - アシスト)
+    load_type TEXT NOT NULL DEFAULT 'external' CHECK (load_type IN ('external', 'bodyweight', 'bodyweight_plus', 'assisted')),
+    -- 記録する指標: weight_reps (重量 x 回数) / reps (回数のみ) / time (時間) / distance_time (距離 + 時間)
+    metric_type TEXT NOT NULL DEFAULT 'weight_reps' CHECK (metric_type IN ('weight_reps', 'reps', 'time', 'distance_time'))
 );
 
+-- 種目名は組み込み種目の間、ユーザーごとのカスタム種目の間でそれぞれ一意 (他のユーザーのカスタム種目とは重複してよい)
+CREATE UNIQUE INDEX idx_exercises_builtin_name ON exercises (name) WHERE created_by_user_id IS NULL;
+CREATE UNIQUE INDEX idx_exercises_custom_name ON exercises (created_by_user_id, name) WHERE created_by_user_id IS NOT NULL;
+
 -- 種目とサブターゲット部位の中間テーブル
 CREATE TABLE exercise_target_muscle_groups (
     exercise_id UUID REFERENCES exercises(id) ON DELETE CASCADE,
@@ -45,6 +53,7 @@ CREATE TABLE menu_items (
   planned_sets INT, -- 追加
   planned_reps INT,
   planned_interval_seconds INT, -- 追加
+  group_key TEXT, -- スーパーセット/サーキットのグループキー (同じ値の項目をまとめて実施)
   UNIQUE (menu_id, set_order)
 );
 
@@ -57,19 +66,241 @@ CREATE TABLE workouts (
   note         TEXT
 );
 
+-- ワークアウト履歴のカーソルページング用 (user_id, started_at, id)
+CREATE INDEX idx_workouts_user_started_at ON workouts (user_id, started_at, id);
+
 -- sets (actual performance)
 CREATE TABLE sets (
   id          UUID PRIMARY KEY DEFAULT uuid_generate_v4(),
   workout_id  UUID REFERENCES workouts(id) ON DELETE CASCADE,
   exercise_id UUID REFERENCES exercises(id) ON DELETE RESTRICT, -- exercise TEXT NOT NULL から変更
   set_order   INT  NOT NULL,
-  weight_kg   NUMERIC(5,2) NOT NULL,
+  weight_kg   NUMERIC(5,2), -- 自重系の種目 (load_type が external 以外) では NULL (追加・補助の重量なし) を許容
   reps        INT NOT NULL,
   rir         NUMERIC(3,1), -- デフォルトで使用 (Nullable)
   rpe         NUMERIC(3,1), -- 選択的に使用 (Nullable)
+  set_type    TEXT NOT NULL DEFAULT 'working' CHECK (set_type IN ('warmup', 'working', 'drop', 'failure', 'amrap')),
+  group_key   TEXT, -- スーパーセット/サーキットのグループキー (menu_items.group_key と対応)
+  duration_seconds INT CHECK (duration_seconds > 0), -- time / distance_time 種目の実施時間
+  distance_m  NUMERIC(9,2) CHECK (distance_m > 0), -- distance_time 種目の距離 (メートル)
+  avg_heart_rate INT CHECK (avg_heart_rate BETWEEN 30 AND 250), -- 平均心拍数 (任意)
   UNIQUE (workout_id, set_order)
 );
 
+-- 計測項目マスター (体重・体脂肪率・周囲径など)
+CREATE TABLE measurement_metrics (
+  code       TEXT PRIMARY KEY, -- 例: body_weight, body_fat_percentage, waist
+  name       TEXT NOT NULL,
+  unit       TEXT NOT NULL, -- kg / % / cm
+  sort_order INT  NOT NULL DEFAULT 0
+);
+
+-- body_measurements: ユーザーごとの身体計測記録
+CREATE TABLE body_measurements (
+  id          UUID PRIMARY KEY DEFAULT uuid_generate_v4(),
+  user_id     TEXT NOT NULL,
+  metric_code TEXT NOT NULL REFERENCES measurement_metrics(code) ON DELETE RESTRICT,
+  value       NUMERIC(7,2) NOT NULL CHECK (value > 0),
+  measured_at TIMESTAMPTZ NOT NULL DEFAULT now(),
+  note        TEXT,
+  created_at  TIMESTAMPTZ NOT NULL DEFAULT now(),
+  updated_at  TIMESTAMPTZ NOT NULL DEFAULT now()
+);
+
+CREATE INDEX idx_body_measurements_user_metric_time ON body_measurements (user_id, metric_code, measured_at);
+
+-- export_jobs: データエクスポートの非同期ジョブ (完了すると ZIP アーカイブをダウンロードできる)
+CREATE TABLE export_jobs (
+  id           UUID PRIMARY KEY DEFAULT uuid_generate_v4(),
+  user_id      TEXT NOT NULL,
+  status       TEXT NOT NULL DEFAULT 'pending' CHECK (status IN ('pending', 'running', 'completed', 'failed')),
+  from_date    DATE, -- NULL は期間の指定なし
+  to_date      DATE,
+  archive_oid  OID, -- 完了したジョブの ZIP アーカイブ (ラージオブジェクト。ジョブの削除時に unlink_export_job_archive で削除する)
+  archive_size BIGINT,
+  error        TEXT,
+  created_at   TIMESTAMPTZ NOT NULL DEFAULT now(),
+  started_at   TIMESTAMPTZ,
+  completed_at TIMESTAMPTZ,
+  expires_at   TIMESTAMPTZ -- アーカイブの保持期限 (完了から7日)
+);
+
+CREATE INDEX idx_export_jobs_user_created_at ON export_jobs (user_id, created_at);
+
+-- Unlink the large object when its job is deleted (expiry or account deletion) or its archive is replaced
+CREATE OR REPLACE FUNCTION unlink_export_job_archive()
+RETURNS TRIGGER AS $$
+BEGIN
+    IF OLD.archive_oid IS NOT NULL AND (TG_OP = 'DELETE' OR NEW.archive_oid IS DISTINCT FROM OLD.archive_oid) THEN
+        PERFORM lo_unlink(OLD.archive_oid);
+    END IF;
+    RETURN NULL;
+END;
+$$ LANGUAGE plpgsql;
+
+CREATE TRIGGER unlink_export_job_archive_after_update
+AFTER UPDATE OF archive_oid ON export_jobs
+FOR EACH ROW
+EXECUTE FUNCTION unlink_export_job_archive();
+
+CREATE TRIGGER unlink_export_job_archive_after_delete
+AFTER DELETE ON export_jobs
+FOR EACH ROW
+EXECUTE FUNCTION unlink_export_job_archive();
+
+-- account_deletions: アカウント削除 (Right-to-Delete) の予約と監査記録
+-- 完了後は user_id を消去し、削除した件数のみ残す (個人データを含めない)
+CREATE TABLE account_deletions (
+  id            UUID PRIMARY KEY DEFAULT uuid_generate_v4(),
+  user_id       TEXT, -- 完了後は NULL
+  status        TEXT NOT NULL DEFAULT 'pending' CHECK (status IN ('pending', 'cancelled', 'completed')),
+  source        TEXT NOT NULL CHECK (source IN ('api', 'clerk_webhook')),
+  requested_at  TIMESTAMPTZ NOT NULL DEFAULT now(),
+  scheduled_for TIMESTAMPTZ NOT NULL, -- この日時以降に削除する (取り消し期限)
+  cancelled_at  TIMESTAMPTZ,
+  completed_at  TIMESTAMPTZ,
+  purged_counts JSONB -- テーブルごとの削除件数
+);
+
+-- 予約中の削除はユーザーごとに1件
+CREATE UNIQUE INDEX idx_account_deletions_pending_user ON account_deletions (user_id) WHERE status = 'pending';
+CREATE INDEX idx_account_deletions_pending_scheduled_for ON account_deletions (scheduled_for) WHERE status = 'pending';
+
+-- user_settings: ユーザーごとの設定 (Clerk の user.created Webhook で作成する)
+CREATE TABLE user_settings (
+  user_id     TEXT PRIMARY KEY,
+  time_zone   TEXT NOT NULL DEFAULT 'Asia/Tokyo',
+  weight_unit TEXT NOT NULL DEFAULT 'kg' CHECK (weight_unit IN ('kg', 'lb')),
+  created_at  TIMESTAMPTZ NOT NULL DEFAULT now(),
+  updated_at  TIMESTAMPTZ NOT NULL DEFAULT now()
+);
+
+-- webhook_events: 受け取った Webhook イベント (重複排除と失敗時の再試行に使う)
+-- 個人データを保持しないよう、処理に必要な項目 (種類と対象のユーザーID) のみ保存する
+CREATE TABLE webhook_events (
+  id              UUID PRIMARY KEY DEFAULT uuid_generate_v4(),
+  source          TEXT NOT NULL CHECK (source IN ('clerk')),
+  event_id        TEXT NOT NULL, -- 配信元のイベントID (Svix の svix-id)
+  type            TEXT NOT NULL, -- user.created など
+  user_id         TEXT,
+  status          TEXT NOT NULL DEFAULT 'received' CHECK (status IN ('received', 'processed', 'failed')),
+  attempts        INT  NOT NULL DEFAULT 0,
+  last_error      TEXT,
+  received_at     TIMESTAMPTZ NOT NULL DEFAULT now(),
+  processed_at    TIMESTAMPTZ,
+  next_attempt_at TIMESTAMPTZ, -- 失敗したイベントの次の再試行日時
+  UNIQUE (source, event_id)
+);
+
+CREATE INDEX idx_webhook_events_retry ON webhook_events (next_attempt_at) WHERE status = 'failed';
+
+-- personal_access_tokens: スクリプトや外部連携用のパーソナルアクセストークン
+-- トークンそのものは保存せず、SHA-256 のハッシュのみ保存する
+CREATE TABLE personal_access_tokens (
+  id           UUID PRIMARY KEY DEFAULT uuid_generate_v4(),
+  user_id      TEXT NOT NULL,
+  name         TEXT NOT NULL,
+  token_hash   BYTEA NOT NULL UNIQUE,
+  token_prefix TEXT NOT NULL, -- 一覧で見分けるためのトークンの先頭部分
+  scopes       TEXT[] NOT NULL,
+  expires_at   TIMESTAMPTZ, -- NULL の場合は無期限
+  last_used_at TIMESTAMPTZ,
+  created_at   TIMESTAMPTZ NOT NULL DEFAULT now(),
+  revoked_at   TIMESTAMPTZ
+);
+
+CREATE INDEX idx_personal_access_tokens_user ON personal_access_tokens (user_id, created_at) WHERE revoked_at IS NULL;
+
+-- coach_grants: アスリートがコーチに与えたデータへのアクセス権
+-- アスリートが招待コードを発行し (pending)、コーチが受け入れると有効になる (active)
+CREATE TABLE coach_grants (
+  id                UUID PRIMARY KEY DEFAULT uuid_generate_v4(),
+  athlete_user_id   TEXT NOT NULL,
+  coach_user_id     TEXT, -- 招待を受け入れたコーチ (pending の間は NULL)
+  permission        TEXT NOT NULL CHECK (permission IN ('read', 'write')),
+  status            TEXT NOT NULL DEFAULT 'pending' CHECK (status IN ('pending', 'active', 'revoked')),
+  invite_code       TEXT UNIQUE, -- 受け入れ後は NULL
+  invite_expires_at TIMESTAMPTZ NOT NULL,
+  created_at        TIMESTAMPTZ NOT NULL DEFAULT now(),
+  accepted_at       TIMESTAMPTZ,
+  revoked_at        TIMESTAMPTZ
+);
+
+CREATE UNIQUE INDEX idx_coach_grants_active_pair ON coach_grants (athlete_user_id, coach_user_id) WHERE status = 'active';
+CREATE INDEX idx_coach_grants_coach ON coach_grants (coach_user_id) WHERE status = 'active';
+
+-- coach_audit_logs: コーチがアスリートのデータに対して行った操作の記録
+CREATE TABLE coach_audit_logs (
+  id              UUID PRIMARY KEY DEFAULT uuid_generate_v4(),
+  grant_id        UUID NOT NULL REFERENCES coach_grants(id) ON DELETE CASCADE,
+  coach_user_id   TEXT NOT NULL,
+  athlete_user_id TEXT NOT NULL,
+  method          TEXT NOT NULL,
+  route           TEXT NOT NULL, -- ServeMux のパターン (例: PUT /menus/{id})
+  path            TEXT NOT NULL,
+  status_code     INT  NOT NULL,
+  created_at      TIMESTAMPTZ NOT NULL DEFAULT now()
+);
+
+CREATE INDEX idx_coach_audit_logs_grant_created_at ON coach_audit_logs (grant_id, created_at);
+
+-- menu_shares: メニューの共有コード (受け取ったユーザーはプレビューして自分のアカウントに複製できる)
+-- is_public のものはテンプレートライブラリに表示する
+CREATE TABLE menu_shares (
+  id          UUID PRIMARY KEY DEFAULT uuid_generate_v4(),
+  menu_id     UUID NOT NULL REFERENCES menus(id) ON DELETE CASCADE,
+  share_code  TEXT NOT NULL UNIQUE,
+  is_public   BOOLEAN NOT NULL DEFAULT false,
+  clone_count INT NOT NULL DEFAULT 0,
+  created_at  TIMESTAMPTZ NOT NULL DEFAULT now(),
+  revoked_at  TIMESTAMPTZ
+);
+
+CREATE UNIQUE INDEX idx_menu_shares_active_menu ON menu_shares (menu_id) WHERE revoked_at IS NULL;
+
+-- rate_limit_buckets: レート制限のトークンバケット (RATE_LIMIT_STORE=postgres の場合に使う)
+-- 失っても制限がリセットされるだけのため、WAL を書かない UNLOGGED テーブルにする
+CREATE UNLOGGED TABLE rate_limit_buckets (
+  key        TEXT PRIMARY KEY, -- ip:<address> / user:<user_id> / heavy:<user_id>
+  tokens     DOUBLE PRECISION NOT NULL,
+  allowed    BOOLEAN NOT NULL, -- 最後のリクエストを許可したか
+  updated_at TIMESTAMPTZ NOT NULL DEFAULT now()
+);
+
+CREATE INDEX idx_rate_limit_buckets_updated_at ON rate_limit_buckets (updated_at);
+
+-- jobs: バックグラウンドジョブのキュー (各インスタンスのジョブランナーが FOR UPDATE SKIP LOCKED で取り出す)
+-- 完了したジョブは 7 日、失敗したジョブは 30 日で削除する
+CREATE TABLE jobs (
+  id           BIGSERIAL PRIMARY KEY,
+  kind         TEXT NOT NULL, -- export.archive など (ジョブの種類ごとにハンドラーを登録する)
+  args         JSONB NOT NULL DEFAULT '{}',
+  state        TEXT NOT NULL DEFAULT 'available' CHECK (state IN ('available', 'running', 'completed', 'failed')),
+  attempt      INT  NOT NULL DEFAULT 0, -- 実行した回数 (実行中の回を含む)
+  max_attempts INT  NOT NULL CHECK (max_attempts > 0),
+  run_at       TIMESTAMPTZ NOT NULL DEFAULT now(), -- この日時以降に実行する (再試行の場合は次の実行日時)
+  unique_key   TEXT, -- 同じキーの未完了 (available / running) のジョブは1件のみ
+  last_error   TEXT,
+  locked_by    TEXT, -- 実行中のインスタンス
+  locked_at    TIMESTAMPTZ,
+  created_at   TIMESTAMPTZ NOT NULL DEFAULT now(),
+  updated_at   TIMESTAMPTZ NOT NULL DEFAULT now(),
+  completed_at TIMESTAMPTZ,
+  failed_at    TIMESTAMPTZ
+);
+
+CREATE INDEX idx_jobs_available_run_at ON jobs (run_at, id) WHERE state = 'available';
+CREATE INDEX idx_jobs_running_locked_at ON jobs (locked_at) WHERE state = 'running';
+CREATE UNIQUE INDEX idx_jobs_unique_key ON jobs (unique_key) WHERE state IN ('available', 'running');
+
+-- job_schedules: 定期実行のジョブの次の実行日時 (複数のインスタンスのうち1つのみがジョブを追加する)
+CREATE TABLE job_schedules (
+  name        TEXT PRIMARY KEY,
+  next_run_at TIMESTAMPTZ NOT NULL,
+  last_run_at TIMESTAMPTZ,
+  updated_at  TIMESTAMPTZ NOT NULL DEFAULT now()
+);
+
 -- Create weekly_volume table for storing aggregated weekly training data
 CREATE TABLE weekly_volumes (
     id UUID PRIMARY KEY DEFAULT uuid_generate_v4(),
@@ -96,182 +327,243 @@ BEGIN
 END;
 $$ LANGUAGE plpgsql IMMUTABLE;
 
--- Create function to update weekly_volumes when a new set is added or updated
-CREATE OR REPLACE FUNCTION update_weekly_volume() 
-RETURNS TRIGGER AS $$
+-- Get the user's body weight (body_measurements.metric_code = 'body_weight') on the given date (JST):
+-- the latest record on or before the date, falling back to the earliest record after it
+CREATE OR REPLACE FUNCTION get_body_weight_on(p_user_id TEXT, p_date DATE)
+RETURNS NUMERIC AS $$
+    SELECT value
+    FROM body_measurements
+    WHERE user_id = p_user_id
+    AND metric_code = 'body_weight'
+    ORDER BY ((measured_at AT TIME ZONE 'Asia/Tokyo')::DATE <= p_date) DESC,
+             CASE WHEN (measured_at AT TIME ZONE 'Asia/Tokyo')::DATE <= p_date THEN -EXTRACT(EPOCH FROM measured_at) ELSE EXTRACT(EPOCH FROM measured_at) END
+    LIMIT 1;
+$$ LANGUAGE sql STABLE;
+
+-- Calculate the effective load of a set from the exercise's load type and the body weight on the workout date
+--   external:        weight_kg
+--   bodyweight:      body weight (weight_kg when no body weight is recorded)
+--   bodyweight_plus: body weight + weight_kg
+--   assisted:        body weight - weight_kg (not below 0)
+-- A NULL weight_kg (bodyweight exercises recorded without a weight) counts as 0
+CREATE OR REPLACE FUNCTION set_effective_load_kg(p_user_id TEXT, p_exercise_id UUID, p_weight_kg NUMERIC, p_started_at TIMESTAMPTZ)
+RETURNS NUMERIC AS $$
 DECLARE
-    workout_start TIMESTAMPTZ;
-    workout_user_id TEXT;
-    week_start DATE;
+    exercise_load_type TEXT;
+    body_weight NUMERIC;
 BEGIN
-    -- Get workout information
-    SELECT started_at, user_id INTO workout_start, workout_user_id
-    FROM workouts
-    WHERE id = NEW.workout_id;
-    
-    -- Calculate the week start date in JST
-    week_start := get_jst_week_start(workout_start);
-    
-    -- Update or insert weekly volume record
-    INSERT INTO weekly_volumes (
-        user_id, 
-        week_start_date, 
-        total_volume,
-        est_one_rm,
-        exercise_count,
-        set_count
-    )
-    VALUES (
-        workout_user_id,
-        week_start,
-        (NEW.weight_kg * NEW.reps),
-        (NEW.weight_kg * (1 + NEW.reps / 30.0)), -- Simple Epley formula for 1RM estimation
-        1,
-        1
-    )
-    ON CONFLICT (user_id, week_start_date) DO UPDATE
-    SET 
-        total_volume = weekly_volumes.total_volume + (NEW.weight_kg * NEW.reps),
-        est_one_rm = GREATEST(weekly_volumes.est_one_rm, (NEW.weight_kg * (1 + NEW.reps / 30.0))),
-        exercise_count = (
-            SELECT COUNT(DISTINCT exercise_id) 
-            FROM sets s
-            JOIN workouts w ON s.workout_id = w.id
-            WHERE w.user_id = workout_user_id
-            AND get_jst_week_start(w.started_at) = week_start
-        ),
-        set_count = weekly_volumes.set_count + 1,
-        updated_at = now();
-    
-    RETURN NEW;
+    SELECT load_type INTO exercise_load_type FROM exercises WHERE id = p_exercise_id;
+
+    IF exercise_load_type IS NULL OR exercise_load_type = 'external' THEN
+        RETURN COALESCE(p_weight_kg, 0);
+    END IF;
+
+    body_weight := get_body_weight_on(p_user_id, (p_started_at AT TIME ZONE 'Asia/Tokyo')::DATE);
+
+    RETURN CASE exercise_load_type
+        WHEN 'bodyweight' THEN COALESCE(body_weight, p_weight_kg, 0)
+        WHEN 'bodyweight_plus' THEN COALESCE(body_weight, 0) + COALESCE(p_weight_kg, 0)
+        WHEN 'assisted' THEN GREATEST(COALESCE(body_weight, 0) - COALESCE(p_weight_kg, 0), 0)
+    END;
+END;
+$$ LANGUAGE plpgsql STABLE;
+
+-- Whether sets of the exercise count towards strength aggregation (volume, 1RM, set/exercise counts).
+-- Cardio / timed exercises (time, distance_time) are aggregated separately as duration and distance
+CREATE OR REPLACE FUNCTION is_strength_exercise(p_exercise_id UUID)
+RETURNS BOOLEAN AS $$
+    SELECT COALESCE((SELECT metric_type IN ('weight_reps', 'reps') FROM exercises WHERE id = p_exercise_id), TRUE);
+$$ LANGUAGE sql STABLE;
+
+-- weekly_volume_dirty_weeks: queue of user-weeks whose weekly_volumes must be aggregated again
+-- Written by the statement-level triggers below in the same transaction as the set / workout / body weight changes
+-- and consumed by the aggregation worker of the API (a user-week is queued once however often it changes)
+CREATE TABLE weekly_volume_dirty_weeks (
+    user_id TEXT NOT NULL,
+    week_start_date DATE NOT NULL, -- Monday 00:00 JST of the week
+    marked_at TIMESTAMPTZ NOT NULL DEFAULT now(), -- When the week was first queued
+    PRIMARY KEY (user_id, week_start_date)
+);
+
+CREATE INDEX idx_weekly_volume_dirty_weeks_marked_at ON weekly_volume_dirty_weeks (marked_at);
+
+-- Queue the weeks of the workouts of inserted, updated and deleted sets (once per statement)
+CREATE OR REPLACE FUNCTION queue_weekly_volumes_for_sets()
+RETURNS TRIGGER AS $$
+BEGIN
+    -- Bulk imports and account deletion purges recalculate (or delete) the affected weeks themselves
+    -- (enabled for the transaction with set_config('bulktrack.skip_weekly_volume_trigger', 'on', true))
+    IF current_setting('bulktrack.skip_weekly_volume_trigger', true) = 'on' THEN
+        RETURN NULL;
+    END IF;
+
+    IF TG_OP IN ('INSERT', 'UPDATE') THEN
+        INSERT INTO weekly_volume_dirty_weeks (user_id, week_start_date)
+        SELECT DISTINCT w.user_id, get_jst_week_start(w.started_at)
+        FROM new_sets s
+        JOIN workouts w ON w.id = s.workout_id
+        WHERE w.started_at IS NOT NULL
+        ON CONFLICT (user_id, week_start_date) DO NOTHING;
+    END IF;
+
+    -- Sets deleted by the cascade of a workout deletion have no workout here (the workout trigger queues the week)
+    IF TG_OP IN ('UPDATE', 'DELETE') THEN
+        INSERT INTO weekly_volume_dirty_weeks (user_id, week_start_date)
+        SELECT DISTINCT w.user_id, get_jst_week_start(w.started_at)
+        FROM old_sets s
+        JOIN workouts w ON w.id = s.workout_id
+        WHERE w.started_at IS NOT NULL
+        ON CONFLICT (user_id, week_start_date) DO NOTHING;
+    END IF;
+
+    RETURN NULL;
 END;
 $$ LANGUAGE plpgsql;
 
--- Create trigger to update weekly_volumes when a new set is inserted
-CREATE TRIGGER after_set_insert
+CREATE TRIGGER queue_weekly_volumes_after_set_insert
 AFTER INSERT ON sets
-FOR EACH ROW
-EXECUTE FUNCTION update_weekly_volume();
+REFERENCING NEW TABLE AS new_sets
+FOR EACH STATEMENT
+EXECUTE FUNCTION queue_weekly_volumes_for_sets();
+
+CREATE TRIGGER queue_weekly_volumes_after_set_update
+AFTER UPDATE ON sets
+REFERENCING OLD TABLE AS old_sets NEW TABLE AS new_sets
+FOR EACH STATEMENT
+EXECUTE FUNCTION queue_weekly_volumes_for_sets();
 
--- Create function to recalculate weekly volume when a set is deleted
-CREATE OR REPLACE FUNCTION recalculate_weekly_volume_after_delete() 
+CREATE TRIGGER queue_weekly_volumes_after_set_delete
+AFTER DELETE ON sets
+REFERENCING OLD TABLE AS old_sets
+FOR EACH STATEMENT
+EXECUTE FUNCTION queue_weekly_volumes_for_sets();
+
+-- Queue the old and new weeks of workouts whose start time or user changed, and the weeks of deleted workouts
+CREATE OR REPLACE FUNCTION queue_weekly_volumes_for_workouts()
 RETURNS TRIGGER AS $$
-DECLARE
-    workout_start TIMESTAMPTZ;
-    workout_user_id TEXT;
-    week_start DATE;
-    new_total_volume NUMERIC(10,2);
-    new_est_one_rm NUMERIC(10,2);
-    new_exercise_count INTEGER;
-    new_set_count INTEGER;
 BEGIN
-    -- Get workout information
-    SELECT started_at, user_id INTO workout_start, workout_user_id
-    FROM workouts
-    WHERE id = OLD.workout_id;
-    
-    -- Calculate the week start date in JST
-    week_start := get_jst_week_start(workout_start);
-    
-    -- Recalculate total volume for the week
-    SELECT COALESCE(SUM(weight_kg * reps), 0) INTO new_total_volume
-    FROM sets s
-    JOIN workouts w ON s.workout_id = w.id
-    WHERE w.user_id = workout_user_id
-    AND get_jst_week_start(w.started_at) = week_start;
-    
-    -- Recalculate estimated 1RM for the week
-    SELECT COALESCE(MAX(weight_kg * (1 + reps / 30.0)), 0) INTO new_est_one_rm
-    FROM sets s
-    JOIN workouts w ON s.workout_id = w.id
-    WHERE w.user_id = workout_user_id
-    AND get_jst_week_start(w.started_at) = week_start;
-    
-    -- Count unique exercises for the week
-    SELECT COUNT(DISTINCT exercise_id) INTO new_exercise_count
-    FROM sets s
-    JOIN workouts w ON s.workout_id = w.id
-    WHERE w.user_id = workout_user_id
-    AND get_jst_week_start(w.started_at) = week_start;
-    
-    -- Count total sets for the week
-    SELECT COUNT(*) INTO new_set_count
-    FROM sets s
-    JOIN workouts w ON s.workout_id = w.id
-    WHERE w.user_id = workout_user_id
-    AND get_jst_week_start(w.started_at) = week_start;
-    
-    -- Update weekly volume record
-    UPDATE weekly_volumes
-    SET 
-        total_volume = new_total_volume,
-        est_one_rm = new_est_one_rm,
-        exercise_count = new_exercise_count,
-        set_count = new_set_count,
-        updated_at = now()
-    WHERE user_id = workout_user_id
-    AND week_start_date = week_start;
-    
-    RETURN OLD;
+    IF current_setting('bulktrack.skip_weekly_volume_trigger', true) = 'on' THEN
+        RETURN NULL;
+    END IF;
+
+    IF TG_OP = 'UPDATE' THEN
+        INSERT INTO weekly_volume_dirty_weeks (user_id, week_start_date)
+        SELECT weeks.user_id, weeks.week_start_date
+        FROM (
+            SELECT o.user_id, get_jst_week_start(o.started_at) AS week_start_date
+            FROM old_workouts o
+            JOIN new_workouts n ON n.id = o.id
+            WHERE (o.user_id, o.started_at) IS DISTINCT FROM (n.user_id, n.started_at)
+            UNION
+            SELECT n.user_id, get_jst_week_start(n.started_at)
+            FROM old_workouts o
+            JOIN new_workouts n ON n.id = o.id
+            WHERE (o.user_id, o.started_at) IS DISTINCT FROM (n.user_id, n.started_at)
+        ) AS weeks
+        WHERE weeks.week_start_date IS NOT NULL
+        ON CONFLICT (user_id, week_start_date) DO NOTHING;
+    ELSE
+        INSERT INTO weekly_volume_dirty_weeks (user_id, week_start_date)
+        SELECT DISTINCT o.user_id, get_jst_week_start(o.started_at)
+        FROM old_workouts o
+        WHERE o.started_at IS NOT NULL
+        ON CONFLICT (user_id, week_start_date) DO NOTHING;
+    END IF;
+
+    RETURN NULL;
 END;
 $$ LANGUAGE plpgsql;
 
--- Create trigger to recalculate weekly_volumes when a set is deleted
-CREATE TRIGGER after_set_delete
-AFTER DELETE ON sets
-FOR EACH ROW
-EXECUTE FUNCTION recalculate_weekly_volume_after_delete();
+CREATE TRIGGER queue_weekly_volumes_after_workout_update
+AFTER UPDATE ON workouts
+REFERENCING OLD TABLE AS old_workouts NEW TABLE AS new_workouts
+FOR EACH STATEMENT
+EXECUTE FUNCTION queue_weekly_volumes_for_workouts();
+
+CREATE TRIGGER queue_weekly_volumes_after_workout_delete
+AFTER DELETE ON workouts
+REFERENCING OLD TABLE AS old_workouts
+FOR EACH STATEMENT
+EXECUTE FUNCTION queue_weekly_volumes_for_workouts();
+
+-- Queue the weeks of the user's workouts whose body weight (get_body_weight_on) resolves to the given record.
+-- Called for both the old and the new version of a changed record, so the weeks that switch from or to it are covered.
+-- The other records are read from the current table; records with the same measured_at are treated as competing
+-- for the same dates and queue those weeks as well.
+CREATE OR REPLACE FUNCTION queue_weekly_volumes_for_body_weight(p_user_id TEXT, p_measurement_id UUID, p_measured_at TIMESTAMPTZ)
+RETURNS void AS $$
+    INSERT INTO weekly_volume_dirty_weeks (user_id, week_start_date)
+    SELECT DISTINCT w.user_id, get_jst_week_start(w.started_at)
+    FROM workouts w
+    WHERE w.user_id = p_user_id
+    AND w.started_at IS NOT NULL
+    AND CASE
+        -- The record is on or before the workout date: used unless a later record is also on or before the date
+        WHEN (p_measured_at AT TIME ZONE 'Asia/Tokyo')::DATE <= (w.started_at AT TIME ZONE 'Asia/Tokyo')::DATE THEN
+            NOT EXISTS (
+                SELECT 1 FROM body_measurements m
+                WHERE m.user_id = p_user_id
+                AND m.metric_code = 'body_weight'
+                AND m.id <> p_measurement_id
+                AND m.measured_at > p_measured_at
+                AND (m.measured_at AT TIME ZONE 'Asia/Tokyo')::DATE <= (w.started_at AT TIME ZONE 'Asia/Tokyo')::DATE
+            )
+        -- The record is after the workout date: used only as the earliest record when none is on or before the date
+        ELSE
+            NOT EXISTS (
+                SELECT 1 FROM body_measurements m
+                WHERE m.user_id = p_user_id
+                AND m.metric_code = 'body_weight'
+                AND m.id <> p_measurement_id
+                AND (m.measured_at < p_measured_at
+                    OR (m.measured_at AT TIME ZONE 'Asia/Tokyo')::DATE <= (w.started_at AT TIME ZONE 'Asia/Tokyo')::DATE)
+            )
+    END
+    ON CONFLICT (user_id, week_start_date) DO NOTHING;
+$$ LANGUAGE sql;
 
--- Create function to recalculate weekly volume when a set is updated
-CREATE OR REPLACE FUNCTION recalculate_weekly_volume_after_update() 
+-- Queue the weeks whose effective load depends on a changed body weight
+-- (effective load of bodyweight / assisted exercises depends on the body weight)
+CREATE OR REPLACE FUNCTION queue_weekly_volumes_for_body_weights()
 RETURNS TRIGGER AS $$
-DECLARE
-    workout_start TIMESTAMPTZ;
-    workout_user_id TEXT;
-    week_start DATE;
-    new_total_volume NUMERIC(10,2);
-    new_est_one_rm NUMERIC(10,2);
 BEGIN
-    -- Get workout information
-    SELECT started_at, user_id INTO workout_start, workout_user_id
-    FROM workouts
-    WHERE id = NEW.workout_id;
-    
-    -- Calculate the week start date in JST
-    week_start := get_jst_week_start(workout_start);
-    
-    -- Recalculate total volume for the week
-    SELECT COALESCE(SUM(weight_kg * reps), 0) INTO new_total_volume
-    FROM sets s
-    JOIN workouts w ON s.workout_id = w.id
-    WHERE w.user_id = workout_user_id
-    AND get_jst_week_start(w.started_at) = week_start;
-    
-    -- Recalculate estimated 1RM for the week
-    SELECT COALESCE(MAX(weight_kg * (1 + reps / 30.0)), 0) INTO new_est_one_rm
-    FROM sets s
-    JOIN workouts w ON s.workout_id = w.id
-    WHERE w.user_id = workout_user_id
-    AND get_jst_week_start(w.started_at) = week_start;
-    
-    -- Update weekly volume record
-    UPDATE weekly_volumes
-    SET 
-        total_volume = new_total_volume,
-        est_one_rm = new_est_one_rm,
-        updated_at = now()
-    WHERE user_id = workout_user_id
-    AND week_start_date = week_start;
-    
-    RETURN NEW;
+    IF current_setting('bulktrack.skip_weekly_volume_trigger', true) = 'on' THEN
+        RETURN NULL;
+    END IF;
+
+    IF TG_OP IN ('INSERT', 'UPDATE') THEN
+        PERFORM queue_weekly_volumes_for_body_weight(m.user_id, m.id, m.measured_at)
+        FROM new_measurements m
+        WHERE m.metric_code = 'body_weight';
+    END IF;
+
+    IF TG_OP IN ('UPDATE', 'DELETE') THEN
+        PERFORM queue_weekly_volumes_for_body_weight(m.user_id, m.id, m.measured_at)
+        FROM old_measurements m
+        WHERE m.metric_code = 'body_weight';
+    END IF;
+
+    RETURN NULL;
 END;
 $$ LANGUAGE plpgsql;
 
--- Create trigger to recalculate weekly_volumes when a set is updated
-CREATE TRIGGER after_set_update
-AFTER UPDATE ON sets
-FOR EACH ROW
-EXECUTE FUNCTION recalculate_weekly_volume_after_update();
+CREATE TRIGGER queue_weekly_volumes_after_body_measurement_insert
+AFTER INSERT ON body_measurements
+REFERENCING NEW TABLE AS new_measurements
+FOR EACH STATEMENT
+EXECUTE FUNCTION queue_weekly_volumes_for_body_weights();
+
+CREATE TRIGGER queue_weekly_volumes_after_body_measurement_update
+AFTER UPDATE ON body_measurements
+REFERENCING OLD TABLE AS old_measurements NEW TABLE AS new_measurements
+FOR EACH STATEMENT
+EXECUTE FUNCTION queue_weekly_volumes_for_body_weights();
+
+CREATE TRIGGER queue_weekly_volumes_after_body_measurement_delete
+AFTER DELETE ON body_measurements
+REFERENCING OLD TABLE AS old_measurements
+FOR EACH STATEMENT
+EXECUTE FUNCTION queue_weekly_volumes_for_body_weights();
 
 -- Create a function to populate historical data
 CREATE OR REPLACE FUNCTION populate_weekly_volumes() 
@@ -292,12 +584,15 @@ BEGIN
     SELECT 
         w.user_id,
         get_jst_week_start(w.started_at) AS week_start_date,
-        SUM(s.weight_kg * s.reps) AS total_volume,
-        MAX(s.weight_kg * (1 + s.reps / 30.0)) AS est_one_rm,
+        SUM(set_effective_load_kg(w.user_id, s.exercise_id, s.weight_kg, w.started_at) * s.reps) AS total_volume,
+        -- Epley formula multiplied before dividing so that the rounded value matches the aggregation in Go
+        MAX(set_effective_load_kg(w.user_id, s.exercise_id, s.weight_kg, w.started_at) * (30 + s.reps) / 30.0) AS est_one_rm,
         COUNT(DISTINCT s.exercise_id) AS exercise_count,
         COUNT(s.id) AS set_count
     FROM workouts w
     JOIN sets s ON w.id = s.workout_id
+    WHERE s.set_type <> 'warmup'
+    AND is_strength_exercise(s.exercise_id)
     GROUP BY w.user_id, week_start_date;
 END;
 $$ LANGUAGE plpgsql;
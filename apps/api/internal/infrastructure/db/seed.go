@@ -0,0 +1,9 @@
+// Package db はデータベースのスキーマ定義と初期データの SQL を持つ
+package db
+
+import _ "embed"
+
+// SeedSQL は組み込み種目・部位・計測項目・テンプレートメニューの初期データ (何度実行しても同じ結果になる)
+//
+//go:embed seed.sql
+var SeedSQL string
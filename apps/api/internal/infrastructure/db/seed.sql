@@ -2,7 +2,11 @@
 -- Seed data for exercises table
 -- テーブル構造 (例)
 -- id  UUID PRIMARY KEY DEFAULT uuid_generate_v4()
--- name TEXT UNIQUE NOT NULL
+-- name TEXT NOT NULL (組み込み種目の間で一意)
+-- load_type TEXT NOT NULL DEFAULT 'external'
+-- metric_type TEXT NOT NULL DEFAULT 'weight_reps'
+--
+-- 何度実行しても同じ結果になるよう、既にある行は追加しない (bulktrack-admin seed で適用する)
 -- ============================================
 
 -- 必要に応じて既存レコードをクリア
@@ -59,8 +63,183 @@ INSERT INTO exercises (name) VALUES
 ('ロシアンツイスト'),
 ('アブローラー'),
 ('バックエクステンション'),
-('ケトルベルスイング');
+('ケトルベルスイング')
+ON CONFLICT (name) WHERE created_by_user_id IS NULL DO NOTHING;
+
+-- 自重種目の負荷タイプ (load_type のデフォルトは external)
+UPDATE exercises SET load_type = 'bodyweight'
+WHERE name IN ('プッシュアップ', 'クランチ', 'レッグレイズ', 'プランク', 'ロシアンツイスト', 'アブローラー', 'バックエクステンション');
+
+UPDATE exercises SET load_type = 'bodyweight_plus' -- 加重も可能な自重種目
+WHERE name IN ('懸垂', 'ディップス');
+
+-- アシスト種目 (weight_kg はアシスト量)
+INSERT INTO exercises (name, load_type) VALUES
+('アシスト懸垂', 'assisted'),
+('アシストディップス', 'assisted')
+ON CONFLICT (name) WHERE created_by_user_id IS NULL DO NOTHING;
+
+-- 時間・距離で記録する種目 (metric_type のデフォルトは weight_reps)
+UPDATE exercises SET metric_type = 'time'
+WHERE name IN ('プランク');
+
+INSERT INTO exercises (name, metric_type) VALUES
+('ローイングマシン', 'distance_time'),
+('エアロバイク', 'distance_time'),
+('ランニング', 'distance_time'),
+('ウォーキング', 'distance_time'),
+('縄跳び', 'time')
+ON CONFLICT (name) WHERE created_by_user_id IS NULL DO NOTHING;
 
 -- ============================================
 -- これで主要なコンパウンド種目と代表的なアイソレーション種目を網羅
 -- ============================================
+
+-- ============================================
+-- Seed data for muscle_groups table
+-- 組み込み種目の主な部位 (main_target_muscle_group_id と exercise_target_muscle_groups) も設定する
+-- ============================================
+
+INSERT INTO muscle_groups (name) VALUES
+('胸'),
+('背中'),
+('肩'),
+('脚'),
+('腕'),
+('体幹'),
+('全身')
+ON CONFLICT (name) DO NOTHING;
+
+WITH targets (exercise_name, muscle_group_name) AS (
+    VALUES
+    ('ベンチプレス', '胸'), ('インクラインベンチプレス', '胸'), ('ダンベルフライ', '胸'), ('プッシュアップ', '胸'),
+    ('ディップス', '胸'), ('アシストディップス', '胸'),
+    ('デッドリフト', '背中'), ('ベントオーバーロウ', '背中'), ('Tバーロウ', '背中'), ('ケーブルロウ', '背中'),
+    ('ラットプルダウン', '背中'), ('懸垂', '背中'), ('アシスト懸垂', '背中'), ('シーテッドロウ', '背中'),
+    ('シュラッグ', '背中'), ('バックエクステンション', '背中'),
+    ('スクワット', '脚'), ('ルーマニアンデッドリフト', '脚'), ('レッグプレス', '脚'), ('ブルガリアンスクワット', '脚'),
+    ('ランジ', '脚'), ('ヒップスラスト', '脚'), ('レッグエクステンション', '脚'), ('レッグカール', '脚'),
+    ('カーフレイズ', '脚'), ('グッドモーニング', '脚'),
+    ('ショルダープレス', '肩'), ('アーノルドプレス', '肩'), ('サイドレイズ', '肩'), ('フロントレイズ', '肩'),
+    ('リアデルトフライ', '肩'),
+    ('アームカール', '腕'), ('ハンマーカール', '腕'), ('プリーチャーカール', '腕'), ('トライセプスエクステンション', '腕'),
+    ('クローズグリップベンチプレス', '腕'), ('スカルクラッシャー', '腕'), ('ケーブルプレスダウン', '腕'),
+    ('クランチ', '体幹'), ('レッグレイズ', '体幹'), ('プランク', '体幹'), ('ロシアンツイスト', '体幹'),
+    ('アブローラー', '体幹'),
+    ('ケトルベルスイング', '全身'), ('ローイングマシン', '全身'), ('エアロバイク', '全身'), ('ランニング', '全身'),
+    ('ウォーキング', '全身'), ('縄跳び', '全身')
+), resolved AS (
+    SELECT e.id AS exercise_id, mg.id AS muscle_group_id
+    FROM targets t
+    JOIN exercises e ON e.name = t.exercise_name AND e.created_by_user_id IS NULL
+    JOIN muscle_groups mg ON mg.name = t.muscle_group_name
+), main_targets AS (
+    UPDATE exercises e
+    SET main_target_muscle_group_id = r.muscle_group_id
+    FROM resolved r
+    WHERE e.id = r.exercise_id AND e.main_target_muscle_group_id IS NULL -- 変更済みの部位は上書きしない
+)
+INSERT INTO exercise_target_muscle_groups (exercise_id, muscle_group_id)
+SELECT exercise_id, muscle_group_id FROM resolved
+ON CONFLICT DO NOTHING;
+
+-- ============================================
+-- Seed data for measurement_metrics table
+-- ============================================
+
+INSERT INTO measurement_metrics (code, name, unit, sort_order) VALUES
+('body_weight', '体重', 'kg', 10),
+('body_fat_percentage', '体脂肪率', '%', 20),
+('skeletal_muscle_mass', '骨格筋量', 'kg', 30),
+('neck', '首囲', 'cm', 40),
+('chest', '胸囲', 'cm', 50),
+('waist', 'ウエスト', 'cm', 60),
+('hip', 'ヒップ', 'cm', 70),
+('arm', '上腕囲', 'cm', 80),
+('thigh', '太もも囲', 'cm', 90),
+('calf', 'ふくらはぎ囲', 'cm', 100)
+ON CONFLICT (code) DO NOTHING;
+
+-- テンプレートライブラリ (予約ユーザー bulktrack のメニューを公開する)
+WITH menu AS (
+    INSERT INTO menus (user_id, name, description)
+    VALUES ('bulktrack', '全身 (初心者向け)', '主要な複合種目で全身を鍛える週2〜3回のメニュー')
+    ON CONFLICT (user_id, name) DO NOTHING
+    RETURNING id
+), items AS (
+    INSERT INTO menu_items (menu_id, exercise_id, set_order, planned_sets, planned_reps, planned_interval_seconds)
+    SELECT menu.id, e.id, item.set_order, item.planned_sets, item.planned_reps, item.planned_interval_seconds
+    FROM menu
+    CROSS JOIN (VALUES
+        (1, 'スクワット', 3, 8, 180),
+        (2, 'ベンチプレス', 3, 8, 180),
+        (3, 'ベントオーバーロウ', 3, 8, 120),
+        (4, 'ショルダープレス', 3, 10, 90)
+    ) AS item (set_order, exercise_name, planned_sets, planned_reps, planned_interval_seconds)
+    JOIN exercises e ON e.name = item.exercise_name AND e.created_by_user_id IS NULL
+)
+INSERT INTO menu_shares (menu_id, share_code, is_public)
+SELECT id, 'TPL-FULLBODY', true FROM menu;
+
+WITH menu AS (
+    INSERT INTO menus (user_id, name, description)
+    VALUES ('bulktrack', 'PPL - プッシュ', '胸・肩・上腕三頭筋 (Push / Pull / Legs の分割)')
+    ON CONFLICT (user_id, name) DO NOTHING
+    RETURNING id
+), items AS (
+    INSERT INTO menu_items (menu_id, exercise_id, set_order, planned_sets, planned_reps, planned_interval_seconds)
+    SELECT menu.id, e.id, item.set_order, item.planned_sets, item.planned_reps, item.planned_interval_seconds
+    FROM menu
+    CROSS JOIN (VALUES
+        (1, 'ベンチプレス', 4, 6, 180),
+        (2, 'インクラインベンチプレス', 3, 10, 120),
+        (3, 'ショルダープレス', 3, 10, 90),
+        (4, 'サイドレイズ', 3, 15, 60),
+        (5, 'トライセプスエクステンション', 3, 12, 60)
+    ) AS item (set_order, exercise_name, planned_sets, planned_reps, planned_interval_seconds)
+    JOIN exercises e ON e.name = item.exercise_name AND e.created_by_user_id IS NULL
+)
+INSERT INTO menu_shares (menu_id, share_code, is_public)
+SELECT id, 'TPL-PPL-PUSH', true FROM menu;
+
+WITH menu AS (
+    INSERT INTO menus (user_id, name, description)
+    VALUES ('bulktrack', 'PPL - プル', '背中・上腕二頭筋 (Push / Pull / Legs の分割)')
+    ON CONFLICT (user_id, name) DO NOTHING
+    RETURNING id
+), items AS (
+    INSERT INTO menu_items (menu_id, exercise_id, set_order, planned_sets, planned_reps, planned_interval_seconds)
+    SELECT menu.id, e.id, item.set_order, item.planned_sets, item.planned_reps, item.planned_interval_seconds
+    FROM menu
+    CROSS JOIN (VALUES
+        (1, 'デッドリフト', 3, 5, 180),
+        (2, '懸垂', 3, 8, 120),
+        (3, 'ベントオーバーロウ', 3, 10, 120),
+        (4, 'ラットプルダウン', 3, 12, 90),
+        (5, 'アームカール', 3, 12, 60)
+    ) AS item (set_order, exercise_name, planned_sets, planned_reps, planned_interval_seconds)
+    JOIN exercises e ON e.name = item.exercise_name AND e.created_by_user_id IS NULL
+)
+INSERT INTO menu_shares (menu_id, share_code, is_public)
+SELECT id, 'TPL-PPL-PULL', true FROM menu;
+
+WITH menu AS (
+    INSERT INTO menus (user_id, name, description)
+    VALUES ('bulktrack', 'PPL - レッグ', '脚・臀部 (Push / Pull / Legs の分割)')
+    ON CONFLICT (user_id, name) DO NOTHING
+    RETURNING id
+), items AS (
+    INSERT INTO menu_items (menu_id, exercise_id, set_order, planned_sets, planned_reps, planned_interval_seconds)
+    SELECT menu.id, e.id, item.set_order, item.planned_sets, item.planned_reps, item.planned_interval_seconds
+    FROM menu
+    CROSS JOIN (VALUES
+        (1, 'スクワット', 4, 6, 180),
+        (2, 'ルーマニアンデッドリフト', 3, 10, 120),
+        (3, 'レッグプレス', 3, 12, 90),
+        (4, 'レッグカール', 3, 12, 60),
+        (5, 'カーフレイズ', 4, 15, 60)
+    ) AS item (set_order, exercise_name, planned_sets, planned_reps, planned_interval_seconds)
+    JOIN exercises e ON e.name = item.exercise_name AND e.created_by_user_id IS NULL
+)
+INSERT INTO menu_shares (menu_id, share_code, is_public)
+SELECT id, 'TPL-PPL-LEGS', true FROM menu;
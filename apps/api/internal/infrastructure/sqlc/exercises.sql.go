@@ -14,11 +14,11 @@ import (
 
 const createExercise = `-- name: CreateExercise :one
 INSERT INTO exercises (
-  name, main_target_muscle_group_id, is_custom, created_by_user_id
+  name, main_target_muscle_group_id, is_custom, created_by_user_id, load_type, metric_type
 ) VALUES (
-  $1, $2, $3, $4
+  $1, $2, $3, $4, $5, $6
 )
-RETURNING id, name, main_target_muscle_group_id, is_custom, created_by_user_id, created_at
+RETURNING id, name, main_target_muscle_group_id, is_custom, created_by_user_id, created_at, load_type, metric_type
 `
 
 type CreateExerciseParams struct {
@@ -26,6 +26,8 @@ type CreateExerciseParams struct {
 	MainTargetMuscleGroupID pgtype.UUID `json:"main_target_muscle_group_id"`
 	IsCustom                pgtype.Bool `json:"is_custom"`
 	CreatedByUserID         pgtype.Text `json:"created_by_user_id"`
+	LoadType                string      `json:"load_type"`
+	MetricType              string      `json:"metric_type"`
 }
 
 func (q *Queries) CreateExercise(ctx context.Context, arg CreateExerciseParams) (Exercise, error) {
@@ -34,6 +36,8 @@ func (q *Queries) CreateExercise(ctx context.Context, arg CreateExerciseParams)
 		arg.MainTargetMuscleGroupID,
 		arg.IsCustom,
 		arg.CreatedByUserID,
+		arg.LoadType,
+		arg.MetricType,
 	)
 	var i Exercise
 	err := row.Scan(
@@ -43,12 +47,14 @@ func (q *Queries) CreateExercise(ctx context.Context, arg CreateExerciseParams)
 		&i.IsCustom,
 		&i.CreatedByUserID,
 		&i.CreatedAt,
+		&i.LoadType,
+		&i.MetricType,
 	)
 	return i, err
 }
 
 const getExercise = `-- name: GetExercise :one
-SELECT id, name, main_target_muscle_group_id, is_custom, created_by_user_id, created_at FROM exercises
+SELECT id, name, main_target_muscle_group_id, is_custom, created_by_user_id, created_at, load_type, metric_type FROM exercises
 WHERE id = $1 LIMIT 1
 `
 
@@ -62,19 +68,23 @@ func (q *Queries) GetExercise(ctx context.Context, id uuid.UUID) (Exercise, erro
 		&i.IsCustom,
 		&i.CreatedByUserID,
 		&i.CreatedAt,
+		&i.LoadType,
+		&i.MetricType,
 	)
 	return i, err
 }
 
 const listExercises = `-- name: ListExercises :many
-SELECT id, name FROM exercises
+SELECT id, name, load_type, metric_type FROM exercises
 WHERE is_custom = false -- 基本的な種目のみリストアップ (カスタムは除く)
 ORDER BY name
 `
 
 type ListExercisesRow struct {
-	ID   uuid.UUID `json:"id"`
-	Name string    `json:"name"`
+	ID         uuid.UUID `json:"id"`
+	Name       string    `json:"name"`
+	LoadType   string    `json:"load_type"`
+	MetricType string    `json:"metric_type"`
 }
 
 func (q *Queries) ListExercises(ctx context.Context) ([]ListExercisesRow, error) {
@@ -86,7 +96,51 @@ func (q *Queries) ListExercises(ctx context.Context) ([]ListExercisesRow, error)
 	items := []ListExercisesRow{}
 	for rows.Next() {
 		var i ListExercisesRow
-		if err := rows.Scan(&i.ID, &i.Name); err != nil {
+		if err := rows.Scan(
+			&i.ID,
+			&i.Name,
+			&i.LoadType,
+			&i.MetricType,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const listExercisesForUser = `-- name: ListExercisesForUser :many
+SELECT id, name, load_type, metric_type FROM exercises
+WHERE created_by_user_id IS NULL OR created_by_user_id = $1::text
+ORDER BY created_by_user_id IS NOT NULL, name -- 組み込み種目を先に返す
+`
+
+type ListExercisesForUserRow struct {
+	ID         uuid.UUID `json:"id"`
+	Name       string    `json:"name"`
+	LoadType   string    `json:"load_type"`
+	MetricType string    `json:"metric_type"`
+}
+
+// List built-in exercises and the custom exercises created by the user
+func (q *Queries) ListExercisesForUser(ctx context.Context, userID string) ([]ListExercisesForUserRow, error) {
+	rows, err := q.db.Query(ctx, listExercisesForUser, userID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	items := []ListExercisesForUserRow{}
+	for rows.Next() {
+		var i ListExercisesForUserRow
+		if err := rows.Scan(
+			&i.ID,
+			&i.Name,
+			&i.LoadType,
+			&i.MetricType,
+		); err != nil {
 			return nil, err
 		}
 		items = append(items, i)
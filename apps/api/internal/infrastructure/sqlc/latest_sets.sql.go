@@ -42,6 +42,11 @@ SELECT
     s.reps,
     s.rir,
     s.rpe,
+    s.set_type,
+    s.group_key,
+    s.duration_seconds,
+    s.distance_m,
+    set_effective_load_kg(w.user_id, s.exercise_id, s.weight_kg, w.started_at)::numeric AS effective_load_kg, -- 自重系の種目は体重を含めた負荷
     w.started_at
 FROM sets s
 JOIN exercises e ON s.exercise_id = e.id
@@ -57,14 +62,19 @@ type ListSetsByWorkoutAndExercisesParams struct {
 }
 
 type ListSetsByWorkoutAndExercisesRow struct {
-	ExerciseID   pgtype.UUID        `json:"exercise_id"`
-	ExerciseName string             `json:"exercise_name"`
-	SetOrder     int32              `json:"set_order"`
-	WeightKg     pgtype.Numeric     `json:"weight_kg"`
-	Reps         int32              `json:"reps"`
-	Rir          pgtype.Numeric     `json:"rir"`
-	Rpe          pgtype.Numeric     `json:"rpe"`
-	StartedAt    pgtype.Timestamptz `json:"started_at"`
+	ExerciseID      pgtype.UUID        `json:"exercise_id"`
+	ExerciseName    string             `json:"exercise_name"`
+	SetOrder        int32              `json:"set_order"`
+	WeightKg        pgtype.Numeric     `json:"weight_kg"`
+	Reps            int32              `json:"reps"`
+	Rir             pgtype.Numeric     `json:"rir"`
+	Rpe             pgtype.Numeric     `json:"rpe"`
+	SetType         string             `json:"set_type"`
+	GroupKey        pgtype.Text        `json:"group_key"`
+	DurationSeconds pgtype.Int4        `json:"duration_seconds"`
+	DistanceM       pgtype.Numeric     `json:"distance_m"`
+	EffectiveLoadKg pgtype.Numeric     `json:"effective_load_kg"`
+	StartedAt       pgtype.Timestamptz `json:"started_at"`
 }
 
 func (q *Queries) ListSetsByWorkoutAndExercises(ctx context.Context, arg ListSetsByWorkoutAndExercisesParams) ([]ListSetsByWorkoutAndExercisesRow, error) {
@@ -84,6 +94,11 @@ func (q *Queries) ListSetsByWorkoutAndExercises(ctx context.Context, arg ListSet
 			&i.Reps,
 			&i.Rir,
 			&i.Rpe,
+			&i.SetType,
+			&i.GroupKey,
+			&i.DurationSeconds,
+			&i.DistanceM,
+			&i.EffectiveLoadKg,
 			&i.StartedAt,
 		); err != nil {
 			return nil, err
@@ -14,11 +14,11 @@ import (
 
 const createMenuItem = `-- name: CreateMenuItem :one
 INSERT INTO menu_items (
-  menu_id, exercise_id, set_order, planned_sets, planned_reps, planned_interval_seconds
+  menu_id, exercise_id, set_order, planned_sets, planned_reps, planned_interval_seconds, group_key
 ) VALUES (
-  $1, $2, $3, $4, $5, $6
+  $1, $2, $3, $4, $5, $6, $7
 )
-RETURNING id, menu_id, exercise_id, set_order, planned_sets, planned_reps, planned_interval_seconds
+RETURNING id, menu_id, exercise_id, set_order, planned_sets, planned_reps, planned_interval_seconds, group_key
 `
 
 type CreateMenuItemParams struct {
@@ -28,6 +28,7 @@ type CreateMenuItemParams struct {
 	PlannedSets            pgtype.Int4 `json:"planned_sets"`
 	PlannedReps            pgtype.Int4 `json:"planned_reps"`
 	PlannedIntervalSeconds pgtype.Int4 `json:"planned_interval_seconds"`
+	GroupKey               pgtype.Text `json:"group_key"`
 }
 
 func (q *Queries) CreateMenuItem(ctx context.Context, arg CreateMenuItemParams) (MenuItem, error) {
@@ -38,6 +39,7 @@ func (q *Queries) CreateMenuItem(ctx context.Context, arg CreateMenuItemParams)
 		arg.PlannedSets,
 		arg.PlannedReps,
 		arg.PlannedIntervalSeconds,
+		arg.GroupKey,
 	)
 	var i MenuItem
 	err := row.Scan(
@@ -48,6 +50,7 @@ func (q *Queries) CreateMenuItem(ctx context.Context, arg CreateMenuItemParams)
 		&i.PlannedSets,
 		&i.PlannedReps,
 		&i.PlannedIntervalSeconds,
+		&i.GroupKey,
 	)
 	return i, err
 }
@@ -73,7 +76,7 @@ func (q *Queries) DeleteMenuItems(ctx context.Context, menuID pgtype.UUID) error
 }
 
 const getMenuItem = `-- name: GetMenuItem :one
-SELECT id, menu_id, exercise_id, set_order, planned_sets, planned_reps, planned_interval_seconds FROM menu_items
+SELECT id, menu_id, exercise_id, set_order, planned_sets, planned_reps, planned_interval_seconds, group_key FROM menu_items
 WHERE id = $1 LIMIT 1
 `
 
@@ -88,12 +91,13 @@ func (q *Queries) GetMenuItem(ctx context.Context, id uuid.UUID) (MenuItem, erro
 		&i.PlannedSets,
 		&i.PlannedReps,
 		&i.PlannedIntervalSeconds,
+		&i.GroupKey,
 	)
 	return i, err
 }
 
 const listMenuItemsByMenu = `-- name: ListMenuItemsByMenu :many
-SELECT mi.id, mi.menu_id, mi.exercise_id, e.name as exercise_name, mi.set_order, mi.planned_sets, mi.planned_reps, mi.planned_interval_seconds 
+SELECT mi.id, mi.menu_id, mi.exercise_id, e.name as exercise_name, e.metric_type, mi.set_order, mi.planned_sets, mi.planned_reps, mi.planned_interval_seconds, mi.group_key
 FROM menu_items mi
 JOIN exercises e ON mi.exercise_id = e.id
 WHERE mi.menu_id = $1
@@ -105,10 +109,12 @@ type ListMenuItemsByMenuRow struct {
 	MenuID                 pgtype.UUID `json:"menu_id"`
 	ExerciseID             pgtype.UUID `json:"exercise_id"`
 	ExerciseName           string      `json:"exercise_name"`
+	MetricType             string      `json:"metric_type"`
 	SetOrder               int32       `json:"set_order"`
 	PlannedSets            pgtype.Int4 `json:"planned_sets"`
 	PlannedReps            pgtype.Int4 `json:"planned_reps"`
 	PlannedIntervalSeconds pgtype.Int4 `json:"planned_interval_seconds"`
+	GroupKey               pgtype.Text `json:"group_key"`
 }
 
 func (q *Queries) ListMenuItemsByMenu(ctx context.Context, menuID pgtype.UUID) ([]ListMenuItemsByMenuRow, error) {
@@ -125,10 +131,12 @@ func (q *Queries) ListMenuItemsByMenu(ctx context.Context, menuID pgtype.UUID) (
 			&i.MenuID,
 			&i.ExerciseID,
 			&i.ExerciseName,
+			&i.MetricType,
 			&i.SetOrder,
 			&i.PlannedSets,
 			&i.PlannedReps,
 			&i.PlannedIntervalSeconds,
+			&i.GroupKey,
 		); err != nil {
 			return nil, err
 		}
@@ -142,9 +150,9 @@ func (q *Queries) ListMenuItemsByMenu(ctx context.Context, menuID pgtype.UUID) (
 
 const updateMenuItem = `-- name: UpdateMenuItem :one
 UPDATE menu_items
-SET exercise_id = $2, planned_sets = $3, planned_reps = $4, planned_interval_seconds = $5
+SET exercise_id = $2, planned_sets = $3, planned_reps = $4, planned_interval_seconds = $5, group_key = $6
 WHERE id = $1
-RETURNING id, menu_id, exercise_id, set_order, planned_sets, planned_reps, planned_interval_seconds
+RETURNING id, menu_id, exercise_id, set_order, planned_sets, planned_reps, planned_interval_seconds, group_key
 `
 
 type UpdateMenuItemParams struct {
@@ -153,6 +161,7 @@ type UpdateMenuItemParams struct {
 	PlannedSets            pgtype.Int4 `json:"planned_sets"`
 	PlannedReps            pgtype.Int4 `json:"planned_reps"`
 	PlannedIntervalSeconds pgtype.Int4 `json:"planned_interval_seconds"`
+	GroupKey               pgtype.Text `json:"group_key"`
 }
 
 func (q *Queries) UpdateMenuItem(ctx context.Context, arg UpdateMenuItemParams) (MenuItem, error) {
@@ -162,6 +171,7 @@ func (q *Queries) UpdateMenuItem(ctx context.Context, arg UpdateMenuItemParams)
 		arg.PlannedSets,
 		arg.PlannedReps,
 		arg.PlannedIntervalSeconds,
+		arg.GroupKey,
 	)
 	var i MenuItem
 	err := row.Scan(
@@ -172,6 +182,7 @@ func (q *Queries) UpdateMenuItem(ctx context.Context, arg UpdateMenuItemParams)
 		&i.PlannedSets,
 		&i.PlannedReps,
 		&i.PlannedIntervalSeconds,
+		&i.GroupKey,
 	)
 	return i, err
 }
@@ -11,6 +11,54 @@ import (
 	"github.com/jackc/pgx/v5/pgtype"
 )
 
+type AccountDeletion struct {
+	ID           uuid.UUID          `json:"id"`
+	UserID       pgtype.Text        `json:"user_id"`
+	Status       string             `json:"status"`
+	Source       string             `json:"source"`
+	RequestedAt  time.Time          `json:"requested_at"`
+	ScheduledFor time.Time          `json:"scheduled_for"`
+	CancelledAt  pgtype.Timestamptz `json:"cancelled_at"`
+	CompletedAt  pgtype.Timestamptz `json:"completed_at"`
+	PurgedCounts []byte             `json:"purged_counts"`
+}
+
+type BodyMeasurement struct {
+	ID         uuid.UUID      `json:"id"`
+	UserID     string         `json:"user_id"`
+	MetricCode string         `json:"metric_code"`
+	Value      pgtype.Numeric `json:"value"`
+	MeasuredAt time.Time      `json:"measured_at"`
+	Note       pgtype.Text    `json:"note"`
+	CreatedAt  time.Time      `json:"created_at"`
+	UpdatedAt  time.Time      `json:"updated_at"`
+}
+
+type CoachAuditLog struct {
+	ID            uuid.UUID `json:"id"`
+	GrantID       uuid.UUID `json:"grant_id"`
+	CoachUserID   string    `json:"coach_user_id"`
+	AthleteUserID string    `json:"athlete_user_id"`
+	Method        string    `json:"method"`
+	Route         string    `json:"route"`
+	Path          string    `json:"path"`
+	StatusCode    int32     `json:"status_code"`
+	CreatedAt     time.Time `json:"created_at"`
+}
+
+type CoachGrant struct {
+	ID              uuid.UUID          `json:"id"`
+	AthleteUserID   string             `json:"athlete_user_id"`
+	CoachUserID     pgtype.Text        `json:"coach_user_id"`
+	Permission      string             `json:"permission"`
+	Status          string             `json:"status"`
+	InviteCode      pgtype.Text        `json:"invite_code"`
+	InviteExpiresAt time.Time          `json:"invite_expires_at"`
+	CreatedAt       time.Time          `json:"created_at"`
+	AcceptedAt      pgtype.Timestamptz `json:"accepted_at"`
+	RevokedAt       pgtype.Timestamptz `json:"revoked_at"`
+}
+
 type Exercise struct {
 	ID                      uuid.UUID          `json:"id"`
 	Name                    string             `json:"name"`
@@ -18,6 +66,8 @@ type Exercise struct {
 	IsCustom                pgtype.Bool        `json:"is_custom"`
 	CreatedByUserID         pgtype.Text        `json:"created_by_user_id"`
 	CreatedAt               pgtype.Timestamptz `json:"created_at"`
+	LoadType                string             `json:"load_type"`
+	MetricType              string             `json:"metric_type"`
 }
 
 type ExerciseTargetMuscleGroup struct {
@@ -25,6 +75,53 @@ type ExerciseTargetMuscleGroup struct {
 	MuscleGroupID uuid.UUID `json:"muscle_group_id"`
 }
 
+type ExportJob struct {
+	ID          uuid.UUID          `json:"id"`
+	UserID      string             `json:"user_id"`
+	Status      string             `json:"status"`
+	FromDate    pgtype.Date        `json:"from_date"`
+	ToDate      pgtype.Date        `json:"to_date"`
+	ArchiveOid  pgtype.Uint32      `json:"archive_oid"`
+	ArchiveSize pgtype.Int8        `json:"archive_size"`
+	Error       pgtype.Text        `json:"error"`
+	CreatedAt   time.Time          `json:"created_at"`
+	StartedAt   pgtype.Timestamptz `json:"started_at"`
+	CompletedAt pgtype.Timestamptz `json:"completed_at"`
+	ExpiresAt   pgtype.Timestamptz `json:"expires_at"`
+}
+
+type Job struct {
+	ID          int64              `json:"id"`
+	Kind        string             `json:"kind"`
+	Args        []byte             `json:"args"`
+	State       string             `json:"state"`
+	Attempt     int32              `json:"attempt"`
+	MaxAttempts int32              `json:"max_attempts"`
+	RunAt       time.Time          `json:"run_at"`
+	UniqueKey   pgtype.Text        `json:"unique_key"`
+	LastError   pgtype.Text        `json:"last_error"`
+	LockedBy    pgtype.Text        `json:"locked_by"`
+	LockedAt    pgtype.Timestamptz `json:"locked_at"`
+	CreatedAt   time.Time          `json:"created_at"`
+	UpdatedAt   time.Time          `json:"updated_at"`
+	CompletedAt pgtype.Timestamptz `json:"completed_at"`
+	FailedAt    pgtype.Timestamptz `json:"failed_at"`
+}
+
+type JobSchedule struct {
+	Name      string             `json:"name"`
+	NextRunAt time.Time          `json:"next_run_at"`
+	LastRunAt pgtype.Timestamptz `json:"last_run_at"`
+	UpdatedAt time.Time          `json:"updated_at"`
+}
+
+type MeasurementMetric struct {
+	Code      string `json:"code"`
+	Name      string `json:"name"`
+	Unit      string `json:"unit"`
+	SortOrder int32  `json:"sort_order"`
+}
+
 type Menu struct {
 	ID          uuid.UUID          `json:"id"`
 	UserID      string             `json:"user_id"`
@@ -41,6 +138,17 @@ type MenuItem struct {
 	PlannedSets            pgtype.Int4 `json:"planned_sets"`
 	PlannedReps            pgtype.Int4 `json:"planned_reps"`
 	PlannedIntervalSeconds pgtype.Int4 `json:"planned_interval_seconds"`
+	GroupKey               pgtype.Text `json:"group_key"`
+}
+
+type MenuShare struct {
+	ID         uuid.UUID          `json:"id"`
+	MenuID     uuid.UUID          `json:"menu_id"`
+	ShareCode  string             `json:"share_code"`
+	IsPublic   bool               `json:"is_public"`
+	CloneCount int32              `json:"clone_count"`
+	CreatedAt  time.Time          `json:"created_at"`
+	RevokedAt  pgtype.Timestamptz `json:"revoked_at"`
 }
 
 type MuscleGroup struct {
@@ -48,15 +156,62 @@ type MuscleGroup struct {
 	Name string    `json:"name"`
 }
 
+type PersonalAccessToken struct {
+	ID          uuid.UUID          `json:"id"`
+	UserID      string             `json:"user_id"`
+	Name        string             `json:"name"`
+	TokenHash   []byte             `json:"token_hash"`
+	TokenPrefix string             `json:"token_prefix"`
+	Scopes      []string           `json:"scopes"`
+	ExpiresAt   pgtype.Timestamptz `json:"expires_at"`
+	LastUsedAt  pgtype.Timestamptz `json:"last_used_at"`
+	CreatedAt   time.Time          `json:"created_at"`
+	RevokedAt   pgtype.Timestamptz `json:"revoked_at"`
+}
+
+type RateLimitBucket struct {
+	Key       string    `json:"key"`
+	Tokens    float64   `json:"tokens"`
+	Allowed   bool      `json:"allowed"`
+	UpdatedAt time.Time `json:"updated_at"`
+}
+
 type Set struct {
-	ID         uuid.UUID      `json:"id"`
-	WorkoutID  pgtype.UUID    `json:"workout_id"`
-	ExerciseID pgtype.UUID    `json:"exercise_id"`
-	SetOrder   int32          `json:"set_order"`
-	WeightKg   pgtype.Numeric `json:"weight_kg"`
-	Reps       int32          `json:"reps"`
-	Rir        pgtype.Numeric `json:"rir"`
-	Rpe        pgtype.Numeric `json:"rpe"`
+	ID              uuid.UUID      `json:"id"`
+	WorkoutID       pgtype.UUID    `json:"workout_id"`
+	ExerciseID      pgtype.UUID    `json:"exercise_id"`
+	SetOrder        int32          `json:"set_order"`
+	WeightKg        pgtype.Numeric `json:"weight_kg"`
+	Reps            int32          `json:"reps"`
+	Rir             pgtype.Numeric `json:"rir"`
+	Rpe             pgtype.Numeric `json:"rpe"`
+	SetType         string         `json:"set_type"`
+	GroupKey        pgtype.Text    `json:"group_key"`
+	DurationSeconds pgtype.Int4    `json:"duration_seconds"`
+	DistanceM       pgtype.Numeric `json:"distance_m"`
+	AvgHeartRate    pgtype.Int4    `json:"avg_heart_rate"`
+}
+
+type UserSetting struct {
+	UserID     string    `json:"user_id"`
+	TimeZone   string    `json:"time_zone"`
+	WeightUnit string    `json:"weight_unit"`
+	CreatedAt  time.Time `json:"created_at"`
+	UpdatedAt  time.Time `json:"updated_at"`
+}
+
+type WebhookEvent struct {
+	ID            uuid.UUID          `json:"id"`
+	Source        string             `json:"source"`
+	EventID       string             `json:"event_id"`
+	Type          string             `json:"type"`
+	UserID        pgtype.Text        `json:"user_id"`
+	Status        string             `json:"status"`
+	Attempts      int32              `json:"attempts"`
+	LastError     pgtype.Text        `json:"last_error"`
+	ReceivedAt    time.Time          `json:"received_at"`
+	ProcessedAt   pgtype.Timestamptz `json:"processed_at"`
+	NextAttemptAt pgtype.Timestamptz `json:"next_attempt_at"`
 }
 
 type WeeklyVolume struct {
@@ -71,6 +226,12 @@ type WeeklyVolume struct {
 	UpdatedAt     time.Time      `json:"updated_at"`
 }
 
+type WeeklyVolumeDirtyWeek struct {
+	UserID        string      `json:"user_id"`
+	WeekStartDate pgtype.Date `json:"week_start_date"`
+	MarkedAt      time.Time   `json:"marked_at"`
+}
+
 type Workout struct {
 	ID        uuid.UUID          `json:"id"`
 	UserID    string             `json:"user_id"`
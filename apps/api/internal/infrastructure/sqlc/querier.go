@@ -6,29 +6,119 @@ package sqlc
 
 import (
 	"context"
+	"time"
 
 	"github.com/google/uuid"
 	"github.com/jackc/pgx/v5/pgtype"
 )
 
 type Querier interface {
+	AcceptCoachInvitation(ctx context.Context, arg AcceptCoachInvitationParams) (AcceptCoachInvitationRow, error)
+	// Move a due periodic job to its next run (only one instance updates the row, and that instance enqueues the job)
+	AdvanceJobSchedule(ctx context.Context, arg AdvanceJobScheduleParams) (int64, error)
+	// Remove the name and owner of the custom exercises of a user that are still referenced by other users' data
+	AnonymizeUserCustomExercises(ctx context.Context, userID string) (int64, error)
+	// Erase the user ID from received webhook events (the events are kept to ignore redelivered events)
+	AnonymizeUserWebhookEvents(ctx context.Context, userID string) (int64, error)
+	// Cancel the scheduled deletion of a user's account
+	CancelAccountDeletion(ctx context.Context, userID string) (CancelAccountDeletionRow, error)
+	// Take user-weeks queued before marked_before out of the aggregation queue, oldest first
+	// The rows stay locked until the transaction ends: other workers skip them, and changes committed meanwhile
+	// wait for the transaction and queue the week again
+	ClaimDirtyWeeklyVolumes(ctx context.Context, arg ClaimDirtyWeeklyVolumesParams) ([]ClaimDirtyWeeklyVolumesRow, error)
+	// Lock the next scheduled deletion whose grace period has ended (other instances skip locked rows)
+	ClaimDueAccountDeletion(ctx context.Context) (ClaimDueAccountDeletionRow, error)
+	// Mark due jobs of the kinds as running, oldest first, and count the attempt
+	// Jobs locked by other instances are skipped
+	ClaimJobs(ctx context.Context, arg ClaimJobsParams) ([]ClaimJobsRow, error)
+	// Take all queued weeks of a user out of the aggregation queue (waits for workers aggregating them)
+	ClaimUserDirtyWeeklyVolumes(ctx context.Context, userID string) ([]ClaimUserDirtyWeeklyVolumesRow, error)
+	// Mark a deletion as completed and erase the user ID, keeping only the purged row counts for auditing
+	CompleteAccountDeletion(ctx context.Context, arg CompleteAccountDeletionParams) error
+	// Store the archive (a large object) of an export job and keep it for 7 days
+	CompleteExportJob(ctx context.Context, arg CompleteExportJobParams) error
+	CompleteJob(ctx context.Context, id int64) error
+	CountActivePersonalAccessTokens(ctx context.Context, userID string) (int32, error)
+	// Count the scheduled deletions whose grace period has ended
+	CountDueAccountDeletions(ctx context.Context) (int64, error)
+	// Count the rows of the set export (one row per set, or per workout without sets) for a user in the time range
+	CountExportSetRows(ctx context.Context, arg CountExportSetRowsParams) (int64, error)
+	// Count the user-weeks in the range that have workouts or a weekly_volumes row (all users when user_id is NULL)
+	CountVolumeBackfillWeeks(ctx context.Context, arg CountVolumeBackfillWeeksParams) (int64, error)
+	// Schedule the deletion of a user's account
+	CreateAccountDeletion(ctx context.Context, arg CreateAccountDeletionParams) (CreateAccountDeletionRow, error)
+	CreateBodyMeasurement(ctx context.Context, arg CreateBodyMeasurementParams) (BodyMeasurement, error)
+	CreateCoachAuditLog(ctx context.Context, arg CreateCoachAuditLogParams) error
+	CreateCoachInvitation(ctx context.Context, arg CreateCoachInvitationParams) (CreateCoachInvitationRow, error)
 	CreateExercise(ctx context.Context, arg CreateExerciseParams) (Exercise, error)
+	// Create a pending export job
+	CreateExportJob(ctx context.Context, arg CreateExportJobParams) (CreateExportJobRow, error)
 	CreateMenu(ctx context.Context, arg CreateMenuParams) (Menu, error)
 	CreateMenuItem(ctx context.Context, arg CreateMenuItemParams) (MenuItem, error)
+	CreateMenuShare(ctx context.Context, arg CreateMenuShareParams) (CreateMenuShareRow, error)
+	CreatePersonalAccessToken(ctx context.Context, arg CreatePersonalAccessTokenParams) (CreatePersonalAccessTokenRow, error)
 	CreateSet(ctx context.Context, arg CreateSetParams) (Set, error)
+	// Bulk insert sets with COPY (used by workout history and user data imports)
+	CreateSetsBulk(ctx context.Context, arg []CreateSetsBulkParams) (int64, error)
+	// Create the starter menu (big 3) of a new user from the built-in exercises (no-op if a menu with the same name exists)
+	CreateStarterMenu(ctx context.Context, userID string) (int64, error)
+	// Record a received webhook event (returns no rows if the event was already received)
+	CreateWebhookEvent(ctx context.Context, arg CreateWebhookEventParams) (CreateWebhookEventRow, error)
 	CreateWorkout(ctx context.Context, arg CreateWorkoutParams) (Workout, error)
+	// Bulk insert workouts with COPY (used by workout history and user data imports; id and started_at are set by the caller)
+	CreateWorkoutsBulk(ctx context.Context, arg []CreateWorkoutsBulkParams) (int64, error)
+	DeleteBodyMeasurement(ctx context.Context, arg DeleteBodyMeasurementParams) (int64, error)
+	// 互換 API (/body-weights) 用: その日 (JST) の体重の記録をすべて削除する
+	DeleteDailyBodyWeights(ctx context.Context, arg DeleteDailyBodyWeightsParams) (int64, error)
+	// Delete export jobs whose archive has expired
+	DeleteExpiredExportJobs(ctx context.Context) (int64, error)
+	// Delete jobs completed before completed_before and jobs failed before failed_before
+	DeleteFinishedJobs(ctx context.Context, arg DeleteFinishedJobsParams) (int64, error)
+	// Buckets idle longer than the longest window are full again, so deleting them does not change any result
+	DeleteIdleRateLimitBuckets(ctx context.Context, idleBefore time.Time) (int64, error)
 	DeleteMenu(ctx context.Context, id uuid.UUID) error
 	DeleteMenuItem(ctx context.Context, id uuid.UUID) error
 	DeleteMenuItems(ctx context.Context, menuID pgtype.UUID) error
+	// Delete processed events older than 30 days (they are only kept to ignore redelivered events)
+	DeleteOldWebhookEvents(ctx context.Context) (int64, error)
 	DeleteSet(ctx context.Context, id uuid.UUID) error
+	// Delete the weekly volumes of user-weeks that no longer have sets
+	DeleteWeeklyVolumes(ctx context.Context, arg DeleteWeeklyVolumesParams) error
 	DeleteWorkout(ctx context.Context, id uuid.UUID) error
+	// Register a periodic job, or move its next run forward when the schedule became shorter
+	EnsureJobSchedule(ctx context.Context, arg EnsureJobScheduleParams) error
+	// Mark an export job as failed with the error message
+	FailExportJob(ctx context.Context, arg FailExportJobParams) error
+	// Give up a job whose attempts are exhausted (or that cannot succeed by retrying)
+	FailJob(ctx context.Context, arg FailJobParams) error
+	GetActiveCoachGrant(ctx context.Context, arg GetActiveCoachGrantParams) (GetActiveCoachGrantRow, error)
+	GetActiveMenuShare(ctx context.Context, menuID uuid.UUID) (GetActiveMenuShareRow, error)
+	// Look up a token by its hash (revoked and expired tokens are not returned)
+	GetActivePersonalAccessToken(ctx context.Context, tokenHash []byte) (GetActivePersonalAccessTokenRow, error)
+	// Get a pending invitation that has not expired by its code
+	GetCoachInvitation(ctx context.Context, inviteCode string) (GetCoachInvitationRow, error)
 	GetExercise(ctx context.Context, id uuid.UUID) (Exercise, error)
+	// Get an export job of a user without the archive
+	GetExportJob(ctx context.Context, arg GetExportJobParams) (GetExportJobRow, error)
+	// Get the archive (a large object) of a completed export job that has not expired
+	GetExportJobArchive(ctx context.Context, arg GetExportJobArchiveParams) (GetExportJobArchiveRow, error)
 	// Get the most recent weekly volume for a user
 	GetLatestWeeklyVolume(ctx context.Context, userID string) (WeeklyVolume, error)
 	GetLatestWorkoutIDByMenu(ctx context.Context, arg GetLatestWorkoutIDByMenuParams) (uuid.UUID, error)
+	GetMeasurementMetric(ctx context.Context, code string) (MeasurementMetric, error)
 	GetMenu(ctx context.Context, id uuid.UUID) (Menu, error)
 	GetMenuItem(ctx context.Context, id uuid.UUID) (MenuItem, error)
+	// Get the scheduled (not yet cancelled or completed) deletion of a user's account
+	GetPendingAccountDeletion(ctx context.Context, userID string) (GetPendingAccountDeletionRow, error)
 	GetSet(ctx context.Context, id uuid.UUID) (Set, error)
+	// Get a shared menu by its share code (the owner is not returned)
+	GetSharedMenu(ctx context.Context, shareCode string) (GetSharedMenuRow, error)
+	// Count a user's data in each table (for inspecting a user from the admin command)
+	GetUserDataSummary(ctx context.Context, userID string) (GetUserDataSummaryRow, error)
+	// Get a received webhook event by the event ID of the source
+	GetWebhookEvent(ctx context.Context, arg GetWebhookEventParams) (GetWebhookEventRow, error)
+	// Get a received webhook event by its ID (for the retry job of the event)
+	GetWebhookEventByID(ctx context.Context, id uuid.UUID) (GetWebhookEventByIDRow, error)
 	// Get weekly volumes broken down by exercise for a specific user and week
 	GetWeeklyVolumeByExercise(ctx context.Context, arg GetWeeklyVolumeByExerciseParams) ([]GetWeeklyVolumeByExerciseRow, error)
 	// Get weekly volumes broken down by muscle group for a specific user and week
@@ -41,18 +131,140 @@ type Querier interface {
 	// Returns data for the last N weeks, filling in zeros for weeks with no data
 	GetWeeklyVolumes(ctx context.Context, arg GetWeeklyVolumesParams) ([]GetWeeklyVolumesRow, error)
 	GetWorkout(ctx context.Context, id uuid.UUID) (Workout, error)
+	IncrementMenuShareCloneCount(ctx context.Context, id uuid.UUID) error
+	// Enqueue a job (no row is returned when an available or running job has the same unique key)
+	InsertJob(ctx context.Context, arg InsertJobParams) (int64, error)
+	// 期間内の身体計測記録を新しい順に取得する (metric_code 未指定の場合は全項目)
+	ListBodyMeasurements(ctx context.Context, arg ListBodyMeasurementsParams) ([]BodyMeasurement, error)
+	// List the audit logs of a grant the user is a party of (newest first)
+	ListCoachAuditLogs(ctx context.Context, arg ListCoachAuditLogsParams) ([]ListCoachAuditLogsRow, error)
+	// List grants given by the user (as an athlete) and to the user (as a coach) that are not revoked
+	ListCoachGrants(ctx context.Context, userID string) ([]ListCoachGrantsRow, error)
+	// 互換 API (/body-weights) 用: 期間内の日ごと (JST) の体重を新しい順に取得する (同じ日に複数の記録がある場合は最新の記録)
+	ListDailyBodyWeights(ctx context.Context, arg ListDailyBodyWeightsParams) ([]ListDailyBodyWeightsRow, error)
+	// 日ごと (JST) の平均値と7日移動平均を取得する
+	ListDailyMeasurementAverages(ctx context.Context, arg ListDailyMeasurementAveragesParams) ([]ListDailyMeasurementAveragesRow, error)
 	ListExercises(ctx context.Context) ([]ListExercisesRow, error)
+	// List built-in exercises and the custom exercises created by the user
+	ListExercisesForUser(ctx context.Context, userID string) ([]ListExercisesForUserRow, error)
+	// List the custom exercises created by a user for export
+	ListExportCustomExercises(ctx context.Context, userID string) ([]ListExportCustomExercisesRow, error)
+	// List a user's menus and their items for export, one row per item (menus without items have NULL item columns)
+	ListExportMenuItems(ctx context.Context, userID string) ([]ListExportMenuItemsRow, error)
+	// List workouts and their sets for export, one row per set (workouts without sets have NULL set columns)
+	// Rows are ordered by (started_at, workout_id, set_order) and read in batches with a keyset cursor
+	ListExportSetRows(ctx context.Context, arg ListExportSetRowsParams) ([]ListExportSetRowsRow, error)
+	// List a user's weekly volumes for export (start_date / end_date are optional week start dates)
+	ListExportWeeklyVolumes(ctx context.Context, arg ListExportWeeklyVolumesParams) ([]ListExportWeeklyVolumesRow, error)
+	// List failed jobs, newest first (the cursor is the last id of the previous page)
+	ListFailedJobs(ctx context.Context, arg ListFailedJobsParams) ([]ListFailedJobsRow, error)
+	// Get training totals per period (day / week / month in the given time zone) for a user in the time range
+	// Periods are returned newest first; cursor_period (exclusive) continues from the previous page
+	// total_volume counts strength sets only (warm-up sets and time / distance_time exercises are excluded)
+	ListHistoryPeriods(ctx context.Context, arg ListHistoryPeriodsParams) ([]ListHistoryPeriodsRow, error)
+	// Get the top weight per exercise and period (day / week / month in the given time zone) for a user in the time range
+	// Only weight_reps exercises are included and warm-up sets are excluded
+	ListHistoryTopWeights(ctx context.Context, arg ListHistoryTopWeightsParams) ([]ListHistoryTopWeightsRow, error)
+	// Count rows that violate invariants the schema does not enforce (all users when user_id is NULL)
+	ListIntegrityIssueCounts(ctx context.Context, userID pgtype.Text) ([]ListIntegrityIssueCountsRow, error)
+	ListMeasurementMetrics(ctx context.Context) ([]MeasurementMetric, error)
 	ListMenuItemsByMenu(ctx context.Context, menuID pgtype.UUID) ([]ListMenuItemsByMenuRow, error)
 	ListMenusByUser(ctx context.Context, userID string) ([]Menu, error)
+	ListMuscleGroups(ctx context.Context) ([]MuscleGroup, error)
+	// List the user's tokens that are not revoked (including expired ones)
+	ListPersonalAccessTokens(ctx context.Context, userID string) ([]ListPersonalAccessTokensRow, error)
+	// List the menus in the template library
+	ListPublicMenuTemplates(ctx context.Context) ([]ListPublicMenuTemplatesRow, error)
+	// 種目の週ごとの推定1RMと、その週末時点の体重を取得する (推定1RM/体重の算出用)
+	ListRelativeStrengthByWeek(ctx context.Context, arg ListRelativeStrengthByWeekParams) ([]ListRelativeStrengthByWeekRow, error)
 	ListSetsByWorkout(ctx context.Context, workoutID pgtype.UUID) ([]ListSetsByWorkoutRow, error)
 	ListSetsByWorkoutAndExercises(ctx context.Context, arg ListSetsByWorkoutAndExercisesParams) ([]ListSetsByWorkoutAndExercisesRow, error)
-	ListWorkoutsByUser(ctx context.Context, userID string) ([]Workout, error)
+	// List the items of a shared menu with the exercises (to preview and clone)
+	ListSharedMenuItems(ctx context.Context, menuID uuid.UUID) ([]ListSharedMenuItemsRow, error)
+	// List the user-weeks in the range that have workouts or a weekly_volumes row, ordered by (user_id, week_start_date)
+	// (the cursor is the last row of the previous page)
+	ListVolumeBackfillWeeks(ctx context.Context, arg ListVolumeBackfillWeeksParams) ([]ListVolumeBackfillWeeksRow, error)
+	// Get weekly cardio / timed exercise totals (time, distance_time) for a user in the date range
+	// Reported separately from total_volume so that strength numbers are not affected
+	ListWeeklyCardioTotals(ctx context.Context, arg ListWeeklyCardioTotalsParams) ([]ListWeeklyCardioTotalsRow, error)
+	// 週ごと (weekly_volumes と同じ JST 月曜始まり) の平均値を取得する
+	// from_date / to_date を含む週はその週の記録すべてを集計する (週の途中の from_date でも最初の週を落とさない)
+	ListWeeklyMeasurementAverages(ctx context.Context, arg ListWeeklyMeasurementAveragesParams) ([]ListWeeklyMeasurementAveragesRow, error)
+	// List the body weights of the users (in hundredths of a kilogram) in order of measurement
+	ListWeeklyVolumeBodyWeights(ctx context.Context, userIds []string) ([]ListWeeklyVolumeBodyWeightsRow, error)
+	// Compare a user's weekly_volumes with a fresh aggregate from sets and workouts and list the weeks that differ
+	// (a missing row is treated as a row of zeros, so weeks whose sets were all deleted are not reported)
+	ListWeeklyVolumeDrift(ctx context.Context, userID string) ([]ListWeeklyVolumeDriftRow, error)
+	// List the sets counted in weekly_volumes (no warm-up sets, strength exercises only) of the user-weeks
+	// Weights are returned in hundredths of a kilogram so that the aggregation in Go is exact
+	ListWeeklyVolumeSets(ctx context.Context, arg ListWeeklyVolumeSetsParams) ([]ListWeeklyVolumeSetsRow, error)
+	// List the users that have workouts or weekly_volumes rows, ordered by user_id (the cursor is the last user_id of the previous page)
+	ListWeeklyVolumeUserIDs(ctx context.Context, arg ListWeeklyVolumeUserIDsParams) ([]string, error)
+	// List the start times of a user's workouts in the time range (used to skip duplicates when importing)
+	ListWorkoutStartTimes(ctx context.Context, arg ListWorkoutStartTimesParams) ([]pgtype.Timestamptz, error)
+	// ワークアウト一覧を古い順に取得する (カーソルは直前のページ末尾の started_at と id)
+	ListWorkoutsPageAsc(ctx context.Context, arg ListWorkoutsPageAscParams) ([]ListWorkoutsPageAscRow, error)
+	// ワークアウト一覧を新しい順に取得する (カーソルは直前のページ末尾の started_at と id)
+	ListWorkoutsPageDesc(ctx context.Context, arg ListWorkoutsPageDescParams) ([]ListWorkoutsPageDescRow, error)
+	// Mark a webhook event as failed and record when the retry job runs next (1, 2, 4, ... minutes after each attempt)
+	MarkWebhookEventFailed(ctx context.Context, arg MarkWebhookEventFailedParams) error
+	MarkWebhookEventProcessed(ctx context.Context, id uuid.UUID) error
+	// Create the default settings of a new user (no-op if they already exist)
+	ProvisionUserSettings(ctx context.Context, userID string) (int64, error)
+	PurgeUserBodyMeasurements(ctx context.Context, userID string) (int64, error)
+	// Delete grants given by and to the user (audit logs are deleted by cascade)
+	PurgeUserCoachGrants(ctx context.Context, userID string) (int64, error)
+	// Delete the custom exercises of a user that are no longer referenced by sets or menu items (ON DELETE RESTRICT)
+	PurgeUserCustomExercises(ctx context.Context, userID string) (int64, error)
+	PurgeUserExportJobs(ctx context.Context, userID string) (int64, error)
+	// Delete background jobs of the user in any state (job args such as export.archive keep the user ID)
+	// A job still running saves its result to no row, which is ignored
+	PurgeUserJobs(ctx context.Context, userID string) (int64, error)
+	// Delete all menus of a user (menu_items are deleted by ON DELETE CASCADE)
+	PurgeUserMenus(ctx context.Context, userID string) (int64, error)
+	PurgeUserPersonalAccessTokens(ctx context.Context, userID string) (int64, error)
+	// Delete all sets of a user's workouts
+	PurgeUserSets(ctx context.Context, userID string) (int64, error)
+	PurgeUserSettings(ctx context.Context, userID string) (int64, error)
+	PurgeUserWeeklyVolumeDirtyWeeks(ctx context.Context, userID string) (int64, error)
+	PurgeUserWeeklyVolumes(ctx context.Context, userID string) (int64, error)
+	PurgeUserWorkouts(ctx context.Context, userID string) (int64, error)
+	// Queue user-weeks of a user for aggregation (the aggregation deletes the rows of weeks that no longer have sets)
+	QueueWeeklyVolumes(ctx context.Context, arg QueueWeeklyVolumesParams) error
 	// Manually recalculate weekly volume for a specific user and week
 	RecalculateWeeklyVolume(ctx context.Context, arg RecalculateWeeklyVolumeParams) error
+	// Return running jobs interrupted by a shutdown to the queue without counting the attempt
+	ReleaseJobs(ctx context.Context, ids []int64) (int64, error)
+	// Return jobs of the kind left running by an instance that stopped (e.g. crashed) before locked_before to the queue
+	// The interrupted run counts as an attempt, so a job that always crashes the worker eventually fails
+	RescueStaleJobs(ctx context.Context, arg RescueStaleJobsParams) (int64, error)
+	// Run a failed job again with a fresh set of attempts
+	RetryFailedJob(ctx context.Context, id int64) (int64, error)
+	// Schedule the next attempt of a job that returned an error
+	RetryJob(ctx context.Context, arg RetryJobParams) error
+	// Revoke a grant or an invitation (either the athlete or the coach can revoke)
+	RevokeCoachGrant(ctx context.Context, arg RevokeCoachGrantParams) (int64, error)
+	RevokeMenuShare(ctx context.Context, menuID uuid.UUID) (int64, error)
+	RevokePersonalAccessToken(ctx context.Context, arg RevokePersonalAccessTokenParams) (int64, error)
+	// Skip queueing the weeks of set, workout and body weight changes for weekly_volumes aggregation until the end of the transaction
+	// The caller must recalculate the affected weeks (RecalculateWeeklyVolume) before committing
+	SkipWeeklyVolumeTrigger(ctx context.Context) error
+	// Mark an export job as running
+	StartExportJob(ctx context.Context, id uuid.UUID) error
+	// Refill the bucket for the time elapsed since the last request (up to capacity) and take one token.
+	// A missing bucket starts full. Time is measured on the database so all instances agree.
+	TakeRateLimitToken(ctx context.Context, arg TakeRateLimitTokenParams) (TakeRateLimitTokenRow, error)
+	// Record the last use of a token (at most once a minute to avoid a write on every request)
+	TouchPersonalAccessToken(ctx context.Context, id uuid.UUID) error
+	UpdateBodyMeasurement(ctx context.Context, arg UpdateBodyMeasurementParams) (BodyMeasurement, error)
 	UpdateMenu(ctx context.Context, arg UpdateMenuParams) (Menu, error)
 	UpdateMenuItem(ctx context.Context, arg UpdateMenuItemParams) (MenuItem, error)
 	UpdateSet(ctx context.Context, arg UpdateSetParams) (Set, error)
 	UpdateWorkoutNote(ctx context.Context, arg UpdateWorkoutNoteParams) (Workout, error)
+	// 互換 API (/body-weights) 用: その日 (JST) の最新の体重の記録を上書きする (記録がない場合はその日の 0:00 JST で作成する)
+	UpsertDailyBodyWeight(ctx context.Context, arg UpsertDailyBodyWeightParams) (pgtype.Numeric, error)
+	// Save aggregated weekly volumes (volumes in hundredths of a kilogram)
+	UpsertWeeklyVolumes(ctx context.Context, arg UpsertWeeklyVolumesParams) error
 }
 
 var _ Querier = (*Queries)(nil)
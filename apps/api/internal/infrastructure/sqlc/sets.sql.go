@@ -14,21 +14,26 @@ import (
 
 const createSet = `-- name: CreateSet :one
 INSERT INTO sets (
-  workout_id, exercise_id, set_order, weight_kg, reps, rir, rpe
+  workout_id, exercise_id, set_order, weight_kg, reps, rir, rpe, set_type, group_key, duration_seconds, distance_m, avg_heart_rate
 ) VALUES (
-  $1, $2, $3, $4, $5, $6, $7
+  $1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11, $12
 )
-RETURNING id, workout_id, exercise_id, set_order, weight_kg, reps, rir, rpe
+RETURNING id, workout_id, exercise_id, set_order, weight_kg, reps, rir, rpe, set_type, group_key, duration_seconds, distance_m, avg_heart_rate
 `
 
 type CreateSetParams struct {
-	WorkoutID  pgtype.UUID    `json:"workout_id"`
-	ExerciseID pgtype.UUID    `json:"exercise_id"`
-	SetOrder   int32          `json:"set_order"`
-	WeightKg   pgtype.Numeric `json:"weight_kg"`
-	Reps       int32          `json:"reps"`
-	Rir        pgtype.Numeric `json:"rir"`
-	Rpe        pgtype.Numeric `json:"rpe"`
+	WorkoutID       pgtype.UUID    `json:"workout_id"`
+	ExerciseID      pgtype.UUID    `json:"exercise_id"`
+	SetOrder        int32          `json:"set_order"`
+	WeightKg        pgtype.Numeric `json:"weight_kg"`
+	Reps            int32          `json:"reps"`
+	Rir             pgtype.Numeric `json:"rir"`
+	Rpe             pgtype.Numeric `json:"rpe"`
+	SetType         string         `json:"set_type"`
+	GroupKey        pgtype.Text    `json:"group_key"`
+	DurationSeconds pgtype.Int4    `json:"duration_seconds"`
+	DistanceM       pgtype.Numeric `json:"distance_m"`
+	AvgHeartRate    pgtype.Int4    `json:"avg_heart_rate"`
 }
 
 func (q *Queries) CreateSet(ctx context.Context, arg CreateSetParams) (Set, error) {
@@ -40,6 +45,11 @@ func (q *Queries) CreateSet(ctx context.Context, arg CreateSetParams) (Set, erro
 		arg.Reps,
 		arg.Rir,
 		arg.Rpe,
+		arg.SetType,
+		arg.GroupKey,
+		arg.DurationSeconds,
+		arg.DistanceM,
+		arg.AvgHeartRate,
 	)
 	var i Set
 	err := row.Scan(
@@ -51,10 +61,30 @@ func (q *Queries) CreateSet(ctx context.Context, arg CreateSetParams) (Set, erro
 		&i.Reps,
 		&i.Rir,
 		&i.Rpe,
+		&i.SetType,
+		&i.GroupKey,
+		&i.DurationSeconds,
+		&i.DistanceM,
+		&i.AvgHeartRate,
 	)
 	return i, err
 }
 
+type CreateSetsBulkParams struct {
+	WorkoutID       pgtype.UUID    `json:"workout_id"`
+	ExerciseID      pgtype.UUID    `json:"exercise_id"`
+	SetOrder        int32          `json:"set_order"`
+	WeightKg        pgtype.Numeric `json:"weight_kg"`
+	Reps            int32          `json:"reps"`
+	Rir             pgtype.Numeric `json:"rir"`
+	Rpe             pgtype.Numeric `json:"rpe"`
+	SetType         string         `json:"set_type"`
+	GroupKey        pgtype.Text    `json:"group_key"`
+	DurationSeconds pgtype.Int4    `json:"duration_seconds"`
+	DistanceM       pgtype.Numeric `json:"distance_m"`
+	AvgHeartRate    pgtype.Int4    `json:"avg_heart_rate"`
+}
+
 const deleteSet = `-- name: DeleteSet :exec
 DELETE FROM sets
 WHERE id = $1
@@ -66,7 +96,7 @@ func (q *Queries) DeleteSet(ctx context.Context, id uuid.UUID) error {
 }
 
 const getSet = `-- name: GetSet :one
-SELECT id, workout_id, exercise_id, set_order, weight_kg, reps, rir, rpe FROM sets
+SELECT id, workout_id, exercise_id, set_order, weight_kg, reps, rir, rpe, set_type, group_key, duration_seconds, distance_m, avg_heart_rate FROM sets
 WHERE id = $1 LIMIT 1
 `
 
@@ -82,12 +112,17 @@ func (q *Queries) GetSet(ctx context.Context, id uuid.UUID) (Set, error) {
 		&i.Reps,
 		&i.Rir,
 		&i.Rpe,
+		&i.SetType,
+		&i.GroupKey,
+		&i.DurationSeconds,
+		&i.DistanceM,
+		&i.AvgHeartRate,
 	)
 	return i, err
 }
 
 const listSetsByWorkout = `-- name: ListSetsByWorkout :many
-SELECT s.id, s.workout_id, s.exercise_id, e.name as exercise_name, s.set_order, s.weight_kg, s.reps, s.rir, s.rpe
+SELECT s.id, s.workout_id, s.exercise_id, e.name as exercise_name, e.metric_type, s.set_order, s.weight_kg, s.reps, s.rir, s.rpe, s.set_type, s.group_key, s.duration_seconds, s.distance_m, s.avg_heart_rate
 FROM sets s
 JOIN exercises e ON s.exercise_id = e.id
 WHERE s.workout_id = $1
@@ -95,15 +130,21 @@ ORDER BY s.set_order
 `
 
 type ListSetsByWorkoutRow struct {
-	ID           uuid.UUID      `json:"id"`
-	WorkoutID    pgtype.UUID    `json:"workout_id"`
-	ExerciseID   pgtype.UUID    `json:"exercise_id"`
-	ExerciseName string         `json:"exercise_name"`
-	SetOrder     int32          `json:"set_order"`
-	WeightKg     pgtype.Numeric `json:"weight_kg"`
-	Reps         int32          `json:"reps"`
-	Rir          pgtype.Numeric `json:"rir"`
-	Rpe          pgtype.Numeric `json:"rpe"`
+	ID              uuid.UUID      `json:"id"`
+	WorkoutID       pgtype.UUID    `json:"workout_id"`
+	ExerciseID      pgtype.UUID    `json:"exercise_id"`
+	ExerciseName    string         `json:"exercise_name"`
+	MetricType      string         `json:"metric_type"`
+	SetOrder        int32          `json:"set_order"`
+	WeightKg        pgtype.Numeric `json:"weight_kg"`
+	Reps            int32          `json:"reps"`
+	Rir             pgtype.Numeric `json:"rir"`
+	Rpe             pgtype.Numeric `json:"rpe"`
+	SetType         string         `json:"set_type"`
+	GroupKey        pgtype.Text    `json:"group_key"`
+	DurationSeconds pgtype.Int4    `json:"duration_seconds"`
+	DistanceM       pgtype.Numeric `json:"distance_m"`
+	AvgHeartRate    pgtype.Int4    `json:"avg_heart_rate"`
 }
 
 func (q *Queries) ListSetsByWorkout(ctx context.Context, workoutID pgtype.UUID) ([]ListSetsByWorkoutRow, error) {
@@ -120,11 +161,17 @@ func (q *Queries) ListSetsByWorkout(ctx context.Context, workoutID pgtype.UUID)
 			&i.WorkoutID,
 			&i.ExerciseID,
 			&i.ExerciseName,
+			&i.MetricType,
 			&i.SetOrder,
 			&i.WeightKg,
 			&i.Reps,
 			&i.Rir,
 			&i.Rpe,
+			&i.SetType,
+			&i.GroupKey,
+			&i.DurationSeconds,
+			&i.DistanceM,
+			&i.AvgHeartRate,
 		); err != nil {
 			return nil, err
 		}
@@ -136,28 +183,52 @@ func (q *Queries) ListSetsByWorkout(ctx context.Context, workoutID pgtype.UUID)
 	return items, nil
 }
 
+const skipWeeklyVolumeTrigger = `-- name: SkipWeeklyVolumeTrigger :exec
+SELECT set_config('bulktrack.skip_weekly_volume_trigger', 'on', true)
+`
+
+// Skip queueing the weeks of set, workout and body weight changes for weekly_volumes aggregation until the end of the transaction
+// The caller must recalculate the affected weeks (RecalculateWeeklyVolume) before committing
+func (q *Queries) SkipWeeklyVolumeTrigger(ctx context.Context) error {
+	_, err := q.db.Exec(ctx, skipWeeklyVolumeTrigger)
+	return err
+}
+
 const updateSet = `-- name: UpdateSet :one
 UPDATE sets
-SET weight_kg = $2, reps = $3, rir = $4, rpe = $5
-WHERE id = $1
-RETURNING id, workout_id, exercise_id, set_order, weight_kg, reps, rir, rpe
+SET weight_kg = $1, reps = $2, rir = $3, rpe = $4,
+  set_type = COALESCE($5::text, set_type),
+  group_key = NULLIF(COALESCE($6::text, group_key), ''), -- 空文字列でグループ解除
+  duration_seconds = $7, distance_m = $8, avg_heart_rate = $9
+WHERE id = $10
+RETURNING id, workout_id, exercise_id, set_order, weight_kg, reps, rir, rpe, set_type, group_key, duration_seconds, distance_m, avg_heart_rate
 `
 
 type UpdateSetParams struct {
-	ID       uuid.UUID      `json:"id"`
-	WeightKg pgtype.Numeric `json:"weight_kg"`
-	Reps     int32          `json:"reps"`
-	Rir      pgtype.Numeric `json:"rir"`
-	Rpe      pgtype.Numeric `json:"rpe"`
+	WeightKg        pgtype.Numeric `json:"weight_kg"`
+	Reps            int32          `json:"reps"`
+	Rir             pgtype.Numeric `json:"rir"`
+	Rpe             pgtype.Numeric `json:"rpe"`
+	SetType         pgtype.Text    `json:"set_type"`
+	GroupKey        pgtype.Text    `json:"group_key"`
+	DurationSeconds pgtype.Int4    `json:"duration_seconds"`
+	DistanceM       pgtype.Numeric `json:"distance_m"`
+	AvgHeartRate    pgtype.Int4    `json:"avg_heart_rate"`
+	ID              uuid.UUID      `json:"id"`
 }
 
 func (q *Queries) UpdateSet(ctx context.Context, arg UpdateSetParams) (Set, error) {
 	row := q.db.QueryRow(ctx, updateSet,
-		arg.ID,
 		arg.WeightKg,
 		arg.Reps,
 		arg.Rir,
 		arg.Rpe,
+		arg.SetType,
+		arg.GroupKey,
+		arg.DurationSeconds,
+		arg.DistanceM,
+		arg.AvgHeartRate,
+		arg.ID,
 	)
 	var i Set
 	err := row.Scan(
@@ -169,6 +240,11 @@ func (q *Queries) UpdateSet(ctx context.Context, arg UpdateSetParams) (Set, erro
 		&i.Reps,
 		&i.Rir,
 		&i.Rpe,
+		&i.SetType,
+		&i.GroupKey,
+		&i.DurationSeconds,
+		&i.DistanceM,
+		&i.AvgHeartRate,
 	)
 	return i, err
 }
@@ -7,11 +7,108 @@ package sqlc
 
 import (
 	"context"
+	"time"
 
 	"github.com/google/uuid"
 	"github.com/jackc/pgx/v5/pgtype"
 )
 
+const claimDirtyWeeklyVolumes = `-- name: ClaimDirtyWeeklyVolumes :many
+DELETE FROM weekly_volume_dirty_weeks d
+USING (
+    SELECT q.user_id, q.week_start_date
+    FROM weekly_volume_dirty_weeks q
+    WHERE q.marked_at <= $1::timestamptz
+    ORDER BY q.marked_at
+    LIMIT $2::int
+    FOR UPDATE SKIP LOCKED
+) AS claimed
+WHERE d.user_id = claimed.user_id AND d.week_start_date = claimed.week_start_date
+RETURNING d.user_id, d.week_start_date
+`
+
+type ClaimDirtyWeeklyVolumesParams struct {
+	MarkedBefore time.Time `json:"marked_before"`
+	BatchSize    int32     `json:"batch_size"`
+}
+
+type ClaimDirtyWeeklyVolumesRow struct {
+	UserID        string      `json:"user_id"`
+	WeekStartDate pgtype.Date `json:"week_start_date"`
+}
+
+// Take user-weeks queued before marked_before out of the aggregation queue, oldest first
+// The rows stay locked until the transaction ends: other workers skip them, and changes committed meanwhile
+// wait for the transaction and queue the week again
+func (q *Queries) ClaimDirtyWeeklyVolumes(ctx context.Context, arg ClaimDirtyWeeklyVolumesParams) ([]ClaimDirtyWeeklyVolumesRow, error) {
+	rows, err := q.db.Query(ctx, claimDirtyWeeklyVolumes, arg.MarkedBefore, arg.BatchSize)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	items := []ClaimDirtyWeeklyVolumesRow{}
+	for rows.Next() {
+		var i ClaimDirtyWeeklyVolumesRow
+		if err := rows.Scan(&i.UserID, &i.WeekStartDate); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const claimUserDirtyWeeklyVolumes = `-- name: ClaimUserDirtyWeeklyVolumes :many
+DELETE FROM weekly_volume_dirty_weeks
+WHERE user_id = $1::text
+RETURNING user_id, week_start_date
+`
+
+type ClaimUserDirtyWeeklyVolumesRow struct {
+	UserID        string      `json:"user_id"`
+	WeekStartDate pgtype.Date `json:"week_start_date"`
+}
+
+// Take all queued weeks of a user out of the aggregation queue (waits for workers aggregating them)
+func (q *Queries) ClaimUserDirtyWeeklyVolumes(ctx context.Context, userID string) ([]ClaimUserDirtyWeeklyVolumesRow, error) {
+	rows, err := q.db.Query(ctx, claimUserDirtyWeeklyVolumes, userID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	items := []ClaimUserDirtyWeeklyVolumesRow{}
+	for rows.Next() {
+		var i ClaimUserDirtyWeeklyVolumesRow
+		if err := rows.Scan(&i.UserID, &i.WeekStartDate); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const deleteWeeklyVolumes = `-- name: DeleteWeeklyVolumes :exec
+DELETE FROM weekly_volumes v
+USING unnest($1::text[], $2::date[]) AS d(user_id, week_start_date)
+WHERE v.user_id = d.user_id AND v.week_start_date = d.week_start_date
+`
+
+type DeleteWeeklyVolumesParams struct {
+	UserIds        []string      `json:"user_ids"`
+	WeekStartDates []pgtype.Date `json:"week_start_dates"`
+}
+
+// Delete the weekly volumes of user-weeks that no longer have sets
+func (q *Queries) DeleteWeeklyVolumes(ctx context.Context, arg DeleteWeeklyVolumesParams) error {
+	_, err := q.db.Exec(ctx, deleteWeeklyVolumes, arg.UserIds, arg.WeekStartDates)
+	return err
+}
+
 const getLatestWeeklyVolume = `-- name: GetLatestWeeklyVolume :one
 SELECT 
     id,
@@ -51,22 +148,25 @@ const getWeeklyVolumeByExercise = `-- name: GetWeeklyVolumeByExercise :many
 SELECT 
     e.id AS exercise_id,
     e.name AS exercise_name,
-    SUM(s.weight_kg * s.reps) AS total_volume,
-    MAX(s.weight_kg * (1 + s.reps / 30.0)) AS est_one_rm,
+    SUM(set_effective_load_kg(w.user_id, s.exercise_id, s.weight_kg, w.started_at) * s.reps) AS total_volume,
+    MAX(set_effective_load_kg(w.user_id, s.exercise_id, s.weight_kg, w.started_at) * (1 + s.reps / 30.0)) AS est_one_rm,
     COUNT(s.id) AS set_count
 FROM workouts w
 JOIN sets s ON w.id = s.workout_id
 JOIN exercises e ON s.exercise_id = e.id
 WHERE 
     w.user_id = $1::text AND
-    get_jst_week_start(w.started_at) = $2::date
+    get_jst_week_start(w.started_at) = $2::date AND
+    ($3::boolean OR s.set_type <> 'warmup') AND
+    e.metric_type IN ('weight_reps', 'reps')
 GROUP BY e.id, e.name
 ORDER BY total_volume DESC
 `
 
 type GetWeeklyVolumeByExerciseParams struct {
-	UserID        string      `json:"user_id"`
-	WeekStartDate pgtype.Date `json:"week_start_date"`
+	UserID         string      `json:"user_id"`
+	WeekStartDate  pgtype.Date `json:"week_start_date"`
+	IncludeWarmups bool        `json:"include_warmups"`
 }
 
 type GetWeeklyVolumeByExerciseRow struct {
@@ -79,7 +179,7 @@ type GetWeeklyVolumeByExerciseRow struct {
 
 // Get weekly volumes broken down by exercise for a specific user and week
 func (q *Queries) GetWeeklyVolumeByExercise(ctx context.Context, arg GetWeeklyVolumeByExerciseParams) ([]GetWeeklyVolumeByExerciseRow, error) {
-	rows, err := q.db.Query(ctx, getWeeklyVolumeByExercise, arg.UserID, arg.WeekStartDate)
+	rows, err := q.db.Query(ctx, getWeeklyVolumeByExercise, arg.UserID, arg.WeekStartDate, arg.IncludeWarmups)
 	if err != nil {
 		return nil, err
 	}
@@ -108,7 +208,7 @@ const getWeeklyVolumeByMuscleGroup = `-- name: GetWeeklyVolumeByMuscleGroup :man
 SELECT 
     mg.id AS muscle_group_id,
     mg.name AS muscle_group_name,
-    SUM(s.weight_kg * s.reps) AS total_volume,
+    SUM(set_effective_load_kg(w.user_id, s.exercise_id, s.weight_kg, w.started_at) * s.reps) AS total_volume,
     COUNT(DISTINCT e.id) AS exercise_count,
     COUNT(s.id) AS set_count
 FROM workouts w
@@ -118,14 +218,17 @@ JOIN exercise_target_muscle_groups etmg ON e.id = etmg.exercise_id
 JOIN muscle_groups mg ON etmg.muscle_group_id = mg.id
 WHERE 
     w.user_id = $1::text AND
-    get_jst_week_start(w.started_at) = $2::date
+    get_jst_week_start(w.started_at) = $2::date AND
+    ($3::boolean OR s.set_type <> 'warmup') AND
+    e.metric_type IN ('weight_reps', 'reps')
 GROUP BY mg.id, mg.name
 ORDER BY total_volume DESC
 `
 
 type GetWeeklyVolumeByMuscleGroupParams struct {
-	UserID        string      `json:"user_id"`
-	WeekStartDate pgtype.Date `json:"week_start_date"`
+	UserID         string      `json:"user_id"`
+	WeekStartDate  pgtype.Date `json:"week_start_date"`
+	IncludeWarmups bool        `json:"include_warmups"`
 }
 
 type GetWeeklyVolumeByMuscleGroupRow struct {
@@ -138,7 +241,7 @@ type GetWeeklyVolumeByMuscleGroupRow struct {
 
 // Get weekly volumes broken down by muscle group for a specific user and week
 func (q *Queries) GetWeeklyVolumeByMuscleGroup(ctx context.Context, arg GetWeeklyVolumeByMuscleGroupParams) ([]GetWeeklyVolumeByMuscleGroupRow, error) {
-	rows, err := q.db.Query(ctx, getWeeklyVolumeByMuscleGroup, arg.UserID, arg.WeekStartDate)
+	rows, err := q.db.Query(ctx, getWeeklyVolumeByMuscleGroup, arg.UserID, arg.WeekStartDate, arg.IncludeWarmups)
 	if err != nil {
 		return nil, err
 	}
@@ -315,20 +418,323 @@ func (q *Queries) GetWeeklyVolumes(ctx context.Context, arg GetWeeklyVolumesPara
 	return items, nil
 }
 
+const listWeeklyCardioTotals = `-- name: ListWeeklyCardioTotals :many
+SELECT 
+    get_jst_week_start(w.started_at) AS week_start_date,
+    COALESCE(SUM(s.duration_seconds), 0)::BIGINT AS total_duration_seconds,
+    COALESCE(SUM(s.distance_m), 0)::NUMERIC AS total_distance_m,
+    COUNT(s.id) AS set_count
+FROM workouts w
+JOIN sets s ON w.id = s.workout_id
+JOIN exercises e ON s.exercise_id = e.id
+WHERE 
+    w.user_id = $1::text AND
+    get_jst_week_start(w.started_at) >= $2::date AND
+    get_jst_week_start(w.started_at) <= $3::date AND
+    e.metric_type IN ('time', 'distance_time')
+GROUP BY get_jst_week_start(w.started_at)
+ORDER BY week_start_date
+`
+
+type ListWeeklyCardioTotalsParams struct {
+	UserID    string      `json:"user_id"`
+	StartDate pgtype.Date `json:"start_date"`
+	EndDate   pgtype.Date `json:"end_date"`
+}
+
+type ListWeeklyCardioTotalsRow struct {
+	WeekStartDate        pgtype.Date    `json:"week_start_date"`
+	TotalDurationSeconds int64          `json:"total_duration_seconds"`
+	TotalDistanceM       pgtype.Numeric `json:"total_distance_m"`
+	SetCount             int64          `json:"set_count"`
+}
+
+// Get weekly cardio / timed exercise totals (time, distance_time) for a user in the date range
+// Reported separately from total_volume so that strength numbers are not affected
+func (q *Queries) ListWeeklyCardioTotals(ctx context.Context, arg ListWeeklyCardioTotalsParams) ([]ListWeeklyCardioTotalsRow, error) {
+	rows, err := q.db.Query(ctx, listWeeklyCardioTotals, arg.UserID, arg.StartDate, arg.EndDate)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	items := []ListWeeklyCardioTotalsRow{}
+	for rows.Next() {
+		var i ListWeeklyCardioTotalsRow
+		if err := rows.Scan(
+			&i.WeekStartDate,
+			&i.TotalDurationSeconds,
+			&i.TotalDistanceM,
+			&i.SetCount,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const listWeeklyVolumeBodyWeights = `-- name: ListWeeklyVolumeBodyWeights :many
+SELECT 
+    user_id,
+    (measured_at AT TIME ZONE 'Asia/Tokyo')::date AS measured_date,
+    (value * 100)::bigint AS value_centi
+FROM body_measurements
+WHERE user_id = ANY($1::text[]) AND metric_code = 'body_weight'
+ORDER BY user_id, measured_at
+`
+
+type ListWeeklyVolumeBodyWeightsRow struct {
+	UserID       string      `json:"user_id"`
+	MeasuredDate pgtype.Date `json:"measured_date"`
+	ValueCenti   int64       `json:"value_centi"`
+}
+
+// List the body weights of the users (in hundredths of a kilogram) in order of measurement
+func (q *Queries) ListWeeklyVolumeBodyWeights(ctx context.Context, userIds []string) ([]ListWeeklyVolumeBodyWeightsRow, error) {
+	rows, err := q.db.Query(ctx, listWeeklyVolumeBodyWeights, userIds)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	items := []ListWeeklyVolumeBodyWeightsRow{}
+	for rows.Next() {
+		var i ListWeeklyVolumeBodyWeightsRow
+		if err := rows.Scan(&i.UserID, &i.MeasuredDate, &i.ValueCenti); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const listWeeklyVolumeDrift = `-- name: ListWeeklyVolumeDrift :many
+WITH fresh AS (
+    SELECT 
+        get_jst_week_start(w.started_at) AS week_start_date,
+        SUM(set_effective_load_kg(w.user_id, s.exercise_id, s.weight_kg, w.started_at) * s.reps)::NUMERIC(10,2) AS total_volume,
+        MAX(set_effective_load_kg(w.user_id, s.exercise_id, s.weight_kg, w.started_at) * (30 + s.reps) / 30.0)::NUMERIC(10,2) AS est_one_rm,
+        COUNT(DISTINCT s.exercise_id)::int AS exercise_count,
+        COUNT(s.id)::int AS set_count
+    FROM workouts w
+    JOIN sets s ON w.id = s.workout_id
+    WHERE 
+        w.user_id = $1::text AND
+        s.set_type <> 'warmup' AND
+        is_strength_exercise(s.exercise_id)
+    GROUP BY get_jst_week_start(w.started_at)
+), stored AS (
+    SELECT week_start_date, total_volume, est_one_rm, exercise_count, set_count
+    FROM weekly_volumes
+    WHERE user_id = $1::text
+)
+SELECT 
+    COALESCE(f.week_start_date, st.week_start_date)::date AS week_start_date,
+    COALESCE(st.total_volume, 0)::NUMERIC AS stored_total_volume,
+    COALESCE(f.total_volume, 0)::NUMERIC AS fresh_total_volume,
+    COALESCE(st.est_one_rm, 0)::NUMERIC AS stored_est_one_rm,
+    COALESCE(f.est_one_rm, 0)::NUMERIC AS fresh_est_one_rm,
+    COALESCE(st.exercise_count, 0)::int AS stored_exercise_count,
+    COALESCE(f.exercise_count, 0)::int AS fresh_exercise_count,
+    COALESCE(st.set_count, 0)::int AS stored_set_count,
+    COALESCE(f.set_count, 0)::int AS fresh_set_count
+FROM fresh f
+FULL OUTER JOIN stored st ON st.week_start_date = f.week_start_date
+WHERE 
+    COALESCE(st.total_volume, 0) <> COALESCE(f.total_volume, 0) OR
+    COALESCE(st.est_one_rm, 0) <> COALESCE(f.est_one_rm, 0) OR
+    COALESCE(st.exercise_count, 0) <> COALESCE(f.exercise_count, 0) OR
+    COALESCE(st.set_count, 0) <> COALESCE(f.set_count, 0)
+ORDER BY 1
+`
+
+type ListWeeklyVolumeDriftRow struct {
+	WeekStartDate       pgtype.Date    `json:"week_start_date"`
+	StoredTotalVolume   pgtype.Numeric `json:"stored_total_volume"`
+	FreshTotalVolume    pgtype.Numeric `json:"fresh_total_volume"`
+	StoredEstOneRm      pgtype.Numeric `json:"stored_est_one_rm"`
+	FreshEstOneRm       pgtype.Numeric `json:"fresh_est_one_rm"`
+	StoredExerciseCount int32          `json:"stored_exercise_count"`
+	FreshExerciseCount  int32          `json:"fresh_exercise_count"`
+	StoredSetCount      int32          `json:"stored_set_count"`
+	FreshSetCount       int32          `json:"fresh_set_count"`
+}
+
+// Compare a user's weekly_volumes with a fresh aggregate from sets and workouts and list the weeks that differ
+// (a missing row is treated as a row of zeros, so weeks whose sets were all deleted are not reported)
+func (q *Queries) ListWeeklyVolumeDrift(ctx context.Context, userID string) ([]ListWeeklyVolumeDriftRow, error) {
+	rows, err := q.db.Query(ctx, listWeeklyVolumeDrift, userID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	items := []ListWeeklyVolumeDriftRow{}
+	for rows.Next() {
+		var i ListWeeklyVolumeDriftRow
+		if err := rows.Scan(
+			&i.WeekStartDate,
+			&i.StoredTotalVolume,
+			&i.FreshTotalVolume,
+			&i.StoredEstOneRm,
+			&i.FreshEstOneRm,
+			&i.StoredExerciseCount,
+			&i.FreshExerciseCount,
+			&i.StoredSetCount,
+			&i.FreshSetCount,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const listWeeklyVolumeSets = `-- name: ListWeeklyVolumeSets :many
+SELECT 
+    d.user_id::text AS user_id,
+    d.week_start_date::date AS week_start_date,
+    (w.started_at AT TIME ZONE 'Asia/Tokyo')::date AS workout_date,
+    s.exercise_id,
+    COALESCE(e.load_type, 'external')::text AS load_type,
+    (COALESCE(s.weight_kg, 0) * 100)::bigint AS weight_centi,
+    s.reps
+FROM unnest($1::text[], $2::date[]) AS d(user_id, week_start_date)
+JOIN workouts w ON 
+    w.user_id = d.user_id AND
+    w.started_at >= (d.week_start_date::timestamp AT TIME ZONE 'Asia/Tokyo') AND
+    w.started_at < ((d.week_start_date + 7)::timestamp AT TIME ZONE 'Asia/Tokyo')
+JOIN sets s ON s.workout_id = w.id
+LEFT JOIN exercises e ON e.id = s.exercise_id
+WHERE 
+    s.set_type <> 'warmup' AND
+    COALESCE(e.metric_type IN ('weight_reps', 'reps'), TRUE)
+`
+
+type ListWeeklyVolumeSetsParams struct {
+	UserIds        []string      `json:"user_ids"`
+	WeekStartDates []pgtype.Date `json:"week_start_dates"`
+}
+
+type ListWeeklyVolumeSetsRow struct {
+	UserID        string      `json:"user_id"`
+	WeekStartDate pgtype.Date `json:"week_start_date"`
+	WorkoutDate   pgtype.Date `json:"workout_date"`
+	ExerciseID    pgtype.UUID `json:"exercise_id"`
+	LoadType      string      `json:"load_type"`
+	WeightCenti   int64       `json:"weight_centi"`
+	Reps          int32       `json:"reps"`
+}
+
+// List the sets counted in weekly_volumes (no warm-up sets, strength exercises only) of the user-weeks
+// Weights are returned in hundredths of a kilogram so that the aggregation in Go is exact
+func (q *Queries) ListWeeklyVolumeSets(ctx context.Context, arg ListWeeklyVolumeSetsParams) ([]ListWeeklyVolumeSetsRow, error) {
+	rows, err := q.db.Query(ctx, listWeeklyVolumeSets, arg.UserIds, arg.WeekStartDates)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	items := []ListWeeklyVolumeSetsRow{}
+	for rows.Next() {
+		var i ListWeeklyVolumeSetsRow
+		if err := rows.Scan(
+			&i.UserID,
+			&i.WeekStartDate,
+			&i.WorkoutDate,
+			&i.ExerciseID,
+			&i.LoadType,
+			&i.WeightCenti,
+			&i.Reps,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const listWeeklyVolumeUserIDs = `-- name: ListWeeklyVolumeUserIDs :many
+SELECT users.user_id::text AS user_id
+FROM (
+    SELECT w.user_id FROM workouts w
+    UNION
+    SELECT v.user_id FROM weekly_volumes v
+) AS users
+WHERE $1::text IS NULL OR users.user_id > $1::text
+ORDER BY users.user_id
+LIMIT $2::int
+`
+
+type ListWeeklyVolumeUserIDsParams struct {
+	CursorUserID pgtype.Text `json:"cursor_user_id"`
+	PageLimit    int32       `json:"page_limit"`
+}
+
+// List the users that have workouts or weekly_volumes rows, ordered by user_id (the cursor is the last user_id of the previous page)
+func (q *Queries) ListWeeklyVolumeUserIDs(ctx context.Context, arg ListWeeklyVolumeUserIDsParams) ([]string, error) {
+	rows, err := q.db.Query(ctx, listWeeklyVolumeUserIDs, arg.CursorUserID, arg.PageLimit)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	items := []string{}
+	for rows.Next() {
+		var user_id string
+		if err := rows.Scan(&user_id); err != nil {
+			return nil, err
+		}
+		items = append(items, user_id)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const queueWeeklyVolumes = `-- name: QueueWeeklyVolumes :exec
+INSERT INTO weekly_volume_dirty_weeks (user_id, week_start_date)
+SELECT $1::text, d.week_start_date
+FROM unnest($2::date[]) AS d(week_start_date)
+ON CONFLICT (user_id, week_start_date) DO NOTHING
+`
+
+type QueueWeeklyVolumesParams struct {
+	UserID         string        `json:"user_id"`
+	WeekStartDates []pgtype.Date `json:"week_start_dates"`
+}
+
+// Queue user-weeks of a user for aggregation (the aggregation deletes the rows of weeks that no longer have sets)
+func (q *Queries) QueueWeeklyVolumes(ctx context.Context, arg QueueWeeklyVolumesParams) error {
+	_, err := q.db.Exec(ctx, queueWeeklyVolumes, arg.UserID, arg.WeekStartDates)
+	return err
+}
+
 const recalculateWeeklyVolume = `-- name: RecalculateWeeklyVolume :exec
 WITH volume_data AS (
     SELECT 
         w.user_id,
         get_jst_week_start(w.started_at) AS week_start_date,
-        SUM(s.weight_kg * s.reps) AS total_volume,
-        MAX(s.weight_kg * (1 + s.reps / 30.0)) AS est_one_rm,
+        SUM(set_effective_load_kg(w.user_id, s.exercise_id, s.weight_kg, w.started_at) * s.reps) AS total_volume,
+        MAX(set_effective_load_kg(w.user_id, s.exercise_id, s.weight_kg, w.started_at) * (30 + s.reps) / 30.0) AS est_one_rm, -- 丸めが Go の集計と一致するよう先に掛ける
         COUNT(DISTINCT s.exercise_id) AS exercise_count,
         COUNT(s.id) AS set_count
     FROM workouts w
     JOIN sets s ON w.id = s.workout_id
     WHERE 
         w.user_id = $1::text AND
-        get_jst_week_start(w.started_at) = $2::date
+        get_jst_week_start(w.started_at) = $2::date AND
+        s.set_type <> 'warmup' AND -- ウォームアップセットは集計対象外
+        is_strength_exercise(s.exercise_id) -- 有酸素・時間計測の種目は集計対象外
     GROUP BY w.user_id, week_start_date
 )
 INSERT INTO weekly_volumes (
@@ -367,3 +773,58 @@ func (q *Queries) RecalculateWeeklyVolume(ctx context.Context, arg RecalculateWe
 	_, err := q.db.Exec(ctx, recalculateWeeklyVolume, arg.UserID, arg.WeekStartDate)
 	return err
 }
+
+const upsertWeeklyVolumes = `-- name: UpsertWeeklyVolumes :exec
+INSERT INTO weekly_volumes (
+    user_id,
+    week_start_date,
+    total_volume,
+    est_one_rm,
+    exercise_count,
+    set_count
+)
+SELECT 
+    v.user_id,
+    v.week_start_date,
+    v.total_volume_centi / 100.0,
+    v.est_one_rm_centi / 100.0,
+    v.exercise_count,
+    v.set_count
+FROM unnest(
+    $1::text[],
+    $2::date[],
+    $3::bigint[],
+    $4::bigint[],
+    $5::int[],
+    $6::int[]
+) AS v(user_id, week_start_date, total_volume_centi, est_one_rm_centi, exercise_count, set_count)
+ON CONFLICT (user_id, week_start_date) 
+DO UPDATE SET
+    total_volume = EXCLUDED.total_volume,
+    est_one_rm = EXCLUDED.est_one_rm,
+    exercise_count = EXCLUDED.exercise_count,
+    set_count = EXCLUDED.set_count,
+    updated_at = now()
+`
+
+type UpsertWeeklyVolumesParams struct {
+	UserIds           []string      `json:"user_ids"`
+	WeekStartDates    []pgtype.Date `json:"week_start_dates"`
+	TotalVolumeCentis []int64       `json:"total_volume_centis"`
+	EstOneRmCentis    []int64       `json:"est_one_rm_centis"`
+	ExerciseCounts    []int32       `json:"exercise_counts"`
+	SetCounts         []int32       `json:"set_counts"`
+}
+
+// Save aggregated weekly volumes (volumes in hundredths of a kilogram)
+func (q *Queries) UpsertWeeklyVolumes(ctx context.Context, arg UpsertWeeklyVolumesParams) error {
+	_, err := q.db.Exec(ctx, upsertWeeklyVolumes,
+		arg.UserIds,
+		arg.WeekStartDates,
+		arg.TotalVolumeCentis,
+		arg.EstOneRmCentis,
+		arg.ExerciseCounts,
+		arg.SetCounts,
+	)
+	return err
+}
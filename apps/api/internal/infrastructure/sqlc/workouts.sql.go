@@ -40,6 +40,14 @@ func (q *Queries) CreateWorkout(ctx context.Context, arg CreateWorkoutParams) (W
 	return i, err
 }
 
+type CreateWorkoutsBulkParams struct {
+	ID        uuid.UUID          `json:"id"`
+	UserID    string             `json:"user_id"`
+	MenuID    pgtype.UUID        `json:"menu_id"`
+	StartedAt pgtype.Timestamptz `json:"started_at"`
+	Note      pgtype.Text        `json:"note"`
+}
+
 const deleteWorkout = `-- name: DeleteWorkout :exec
 DELETE FROM workouts
 WHERE id = $1
@@ -68,25 +76,173 @@ func (q *Queries) GetWorkout(ctx context.Context, id uuid.UUID) (Workout, error)
 	return i, err
 }
 
-const listWorkoutsByUser = `-- name: ListWorkoutsByUser :many
-SELECT id, user_id, menu_id, started_at, note FROM workouts
-WHERE user_id = $1::text
-ORDER BY started_at DESC
+const listWorkoutStartTimes = `-- name: ListWorkoutStartTimes :many
+SELECT started_at
+FROM workouts
+WHERE
+    user_id = $1::text AND
+    started_at >= $2::timestamptz AND
+    started_at <= $3::timestamptz
+`
+
+type ListWorkoutStartTimesParams struct {
+	UserID   string             `json:"user_id"`
+	FromTime pgtype.Timestamptz `json:"from_time"`
+	ToTime   pgtype.Timestamptz `json:"to_time"`
+}
+
+// List the start times of a user's workouts in the time range (used to skip duplicates when importing)
+func (q *Queries) ListWorkoutStartTimes(ctx context.Context, arg ListWorkoutStartTimesParams) ([]pgtype.Timestamptz, error) {
+	rows, err := q.db.Query(ctx, listWorkoutStartTimes, arg.UserID, arg.FromTime, arg.ToTime)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	items := []pgtype.Timestamptz{}
+	for rows.Next() {
+		var started_at pgtype.Timestamptz
+		if err := rows.Scan(&started_at); err != nil {
+			return nil, err
+		}
+		items = append(items, started_at)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const listWorkoutsPageAsc = `-- name: ListWorkoutsPageAsc :many
+SELECT w.id, w.menu_id, COALESCE(m.name, '')::text AS menu_name, w.started_at, w.note
+FROM workouts w
+LEFT JOIN menus m ON m.id = w.menu_id
+WHERE w.user_id = $1::text
+  AND ($2::timestamptz IS NULL OR w.started_at >= $2::timestamptz)
+  AND ($3::timestamptz IS NULL OR w.started_at < $3::timestamptz)
+  AND ($4::uuid IS NULL OR w.menu_id = $4::uuid)
+  AND ($5::uuid IS NULL OR EXISTS (
+    SELECT 1 FROM sets s WHERE s.workout_id = w.id AND s.exercise_id = $5::uuid
+  ))
+  AND ($6::timestamptz IS NULL
+    OR (w.started_at, w.id) > ($6::timestamptz, $7::uuid))
+ORDER BY w.started_at ASC, w.id ASC
+LIMIT $8::int
+`
+
+type ListWorkoutsPageAscParams struct {
+	UserID          string             `json:"user_id"`
+	FromTime        pgtype.Timestamptz `json:"from_time"`
+	ToTime          pgtype.Timestamptz `json:"to_time"`
+	MenuID          pgtype.UUID        `json:"menu_id"`
+	ExerciseID      pgtype.UUID        `json:"exercise_id"`
+	CursorStartedAt pgtype.Timestamptz `json:"cursor_started_at"`
+	CursorID        pgtype.UUID        `json:"cursor_id"`
+	PageLimit       int32              `json:"page_limit"`
+}
+
+type ListWorkoutsPageAscRow struct {
+	ID        uuid.UUID          `json:"id"`
+	MenuID    pgtype.UUID        `json:"menu_id"`
+	MenuName  string             `json:"menu_name"`
+	StartedAt pgtype.Timestamptz `json:"started_at"`
+	Note      pgtype.Text        `json:"note"`
+}
+
+// ワークアウト一覧を古い順に取得する (カーソルは直前のページ末尾の started_at と id)
+func (q *Queries) ListWorkoutsPageAsc(ctx context.Context, arg ListWorkoutsPageAscParams) ([]ListWorkoutsPageAscRow, error) {
+	rows, err := q.db.Query(ctx, listWorkoutsPageAsc,
+		arg.UserID,
+		arg.FromTime,
+		arg.ToTime,
+		arg.MenuID,
+		arg.ExerciseID,
+		arg.CursorStartedAt,
+		arg.CursorID,
+		arg.PageLimit,
+	)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	items := []ListWorkoutsPageAscRow{}
+	for rows.Next() {
+		var i ListWorkoutsPageAscRow
+		if err := rows.Scan(
+			&i.ID,
+			&i.MenuID,
+			&i.MenuName,
+			&i.StartedAt,
+			&i.Note,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const listWorkoutsPageDesc = `-- name: ListWorkoutsPageDesc :many
+SELECT w.id, w.menu_id, COALESCE(m.name, '')::text AS menu_name, w.started_at, w.note
+FROM workouts w
+LEFT JOIN menus m ON m.id = w.menu_id
+WHERE w.user_id = $1::text
+  AND ($2::timestamptz IS NULL OR w.started_at >= $2::timestamptz)
+  AND ($3::timestamptz IS NULL OR w.started_at < $3::timestamptz)
+  AND ($4::uuid IS NULL OR w.menu_id = $4::uuid)
+  AND ($5::uuid IS NULL OR EXISTS (
+    SELECT 1 FROM sets s WHERE s.workout_id = w.id AND s.exercise_id = $5::uuid
+  ))
+  AND ($6::timestamptz IS NULL
+    OR (w.started_at, w.id) < ($6::timestamptz, $7::uuid))
+ORDER BY w.started_at DESC, w.id DESC
+LIMIT $8::int
 `
 
-func (q *Queries) ListWorkoutsByUser(ctx context.Context, userID string) ([]Workout, error) {
-	rows, err := q.db.Query(ctx, listWorkoutsByUser, userID)
+type ListWorkoutsPageDescParams struct {
+	UserID          string             `json:"user_id"`
+	FromTime        pgtype.Timestamptz `json:"from_time"`
+	ToTime          pgtype.Timestamptz `json:"to_time"`
+	MenuID          pgtype.UUID        `json:"menu_id"`
+	ExerciseID      pgtype.UUID        `json:"exercise_id"`
+	CursorStartedAt pgtype.Timestamptz `json:"cursor_started_at"`
+	CursorID        pgtype.UUID        `json:"cursor_id"`
+	PageLimit       int32              `json:"page_limit"`
+}
+
+type ListWorkoutsPageDescRow struct {
+	ID        uuid.UUID          `json:"id"`
+	MenuID    pgtype.UUID        `json:"menu_id"`
+	MenuName  string             `json:"menu_name"`
+	StartedAt pgtype.Timestamptz `json:"started_at"`
+	Note      pgtype.Text        `json:"note"`
+}
+
+// ワークアウト一覧を新しい順に取得する (カーソルは直前のページ末尾の started_at と id)
+func (q *Queries) ListWorkoutsPageDesc(ctx context.Context, arg ListWorkoutsPageDescParams) ([]ListWorkoutsPageDescRow, error) {
+	rows, err := q.db.Query(ctx, listWorkoutsPageDesc,
+		arg.UserID,
+		arg.FromTime,
+		arg.ToTime,
+		arg.MenuID,
+		arg.ExerciseID,
+		arg.CursorStartedAt,
+		arg.CursorID,
+		arg.PageLimit,
+	)
 	if err != nil {
 		return nil, err
 	}
 	defer rows.Close()
-	items := []Workout{}
+	items := []ListWorkoutsPageDescRow{}
 	for rows.Next() {
-		var i Workout
+		var i ListWorkoutsPageDescRow
 		if err := rows.Scan(
 			&i.ID,
-			&i.UserID,
 			&i.MenuID,
+			&i.MenuName,
 			&i.StartedAt,
 			&i.Note,
 		); err != nil {
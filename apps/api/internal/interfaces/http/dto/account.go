@@ -0,0 +1,14 @@
+package dto
+
+import "github.com/google/uuid"
+
+// AccountDeletionView はアカウント削除の予約のレスポンスを表す
+type AccountDeletionView struct {
+	ID           uuid.UUID `json:"id"`
+	Status       string    `json:"status" enum:"pending,cancelled,completed"`
+	Source       string    `json:"source" enum:"api,clerk_webhook"`
+	RequestedAt  string    `json:"requested_at" format:"date-time"`
+	ScheduledFor string    `json:"scheduled_for" format:"date-time"` // この日時以降にすべてのデータを削除する (それまでは取り消せる)
+	CancelledAt  *string   `json:"cancelled_at,omitempty" format:"date-time"`
+	CompletedAt  *string   `json:"completed_at,omitempty" format:"date-time"`
+}
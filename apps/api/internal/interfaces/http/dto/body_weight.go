@@ -0,0 +1,13 @@
+package dto
+
+// RecordBodyWeightRequest は体重記録リクエストを表す (互換 API。POST /measurements の metric=body_weight を推奨)
+type RecordBodyWeightRequest struct {
+	Date     string  `json:"date,omitempty" format:"date"` // 計測日 (YYYY-MM-DD)。省略時は当日 (JST)
+	WeightKg float64 `json:"weight_kg"`
+}
+
+// BodyWeightView は日ごとの体重記録のレスポンスを表す (互換 API。同じ日に複数の記録がある場合は最新の記録)
+type BodyWeightView struct {
+	Date     string  `json:"date" format:"date"` // 計測日 (YYYY-MM-DD、JST)
+	WeightKg float64 `json:"weight_kg"`
+}
@@ -0,0 +1,38 @@
+package dto
+
+import "github.com/google/uuid"
+
+// CreateCoachGrantRequest はコーチへの招待の作成リクエストを表す
+type CreateCoachGrantRequest struct {
+	Permission string `json:"permission" enum:"read,write"`
+}
+
+// AcceptCoachInvitationRequest はコーチへの招待の受け入れリクエストを表す
+type AcceptCoachInvitationRequest struct {
+	InviteCode string `json:"invite_code"`
+}
+
+// CoachGrantView はコーチへのアクセス権 (招待を含む) のレスポンスを表す
+type CoachGrantView struct {
+	ID              uuid.UUID `json:"id"`
+	Role            string    `json:"role" enum:"athlete,coach"` // リクエストしたユーザーの立場
+	AthleteUserID   string    `json:"athlete_user_id"`
+	CoachUserID     *string   `json:"coach_user_id"`
+	Permission      string    `json:"permission" enum:"read,write"`
+	Status          string    `json:"status" enum:"pending,active"`
+	InviteCode      *string   `json:"invite_code,omitempty"` // アスリートにのみ返す (受け入れ前)
+	InviteExpiresAt *string   `json:"invite_expires_at,omitempty" format:"date-time"`
+	CreatedAt       string    `json:"created_at" format:"date-time"`
+	AcceptedAt      *string   `json:"accepted_at" format:"date-time"`
+}
+
+// CoachAuditLogView はコーチの操作の監査ログのレスポンスを表す
+type CoachAuditLogView struct {
+	ID          uuid.UUID `json:"id"`
+	CoachUserID string    `json:"coach_user_id"`
+	Method      string    `json:"method"`
+	Route       string    `json:"route"`
+	Path        string    `json:"path"`
+	StatusCode  int32     `json:"status_code"`
+	CreatedAt   string    `json:"created_at" format:"date-time"`
+}
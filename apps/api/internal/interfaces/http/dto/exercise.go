@@ -4,6 +4,8 @@ import "github.com/google/uuid"
 
 // Exercise は種目情報のレスポンスを表す
 type Exercise struct {
-	ID   uuid.UUID `json:"id"`
-	Name string    `json:"name"`
+	ID         uuid.UUID `json:"id"`
+	Name       string    `json:"name"`
+	LoadType   string    `json:"load_type" enum:"external,bodyweight,bodyweight_plus,assisted"`
+	MetricType string    `json:"metric_type" enum:"weight_reps,reps,time,distance_time"`
 }
@@ -10,15 +10,24 @@ import (
 type ExerciseLastRecord struct {
 	ExerciseID   uuid.UUID        `json:"exercise_id"`
 	ExerciseName string           `json:"exercise_name"`
-	LastRecord   []LastRecordData `json:"last_records"` // フィールド名を複数形に、型をスライスに変更
+	MetricType   string           `json:"metric_type" enum:"weight_reps,reps,time,distance_time"`
+	GroupKey     *string          `json:"group_key,omitempty"` // メニュー項目のグループキー
+	LastRecord   []LastRecordData `json:"last_records"`        // フィールド名を複数形に、型をスライスに変更
 }
 
 // LastRecordData は前回の記録セットデータを表す
 type LastRecordData struct {
 	SetOrder int32     `json:"set_order"` // セット順序を追加
 	Date     time.Time `json:"date"`      // SQLCが time.Time を返す想定
-	WeightKg float64   `json:"weight_kg"`
+	WeightKg float64   `json:"weight_kg"` // 記録した重量 (自重系の種目では追加・補助の重量。未記録は 0)
 	Reps     int32     `json:"reps"`
 	RIR      *float64  `json:"rir,omitempty"`
 	RPE      *float64  `json:"rpe,omitempty"`
+	SetType  string    `json:"set_type" enum:"warmup,working,drop,failure,amrap"`
+	GroupKey *string   `json:"group_key,omitempty"`
+	// 有酸素・時間計測の種目のみ
+	DurationSeconds *int32   `json:"duration_seconds,omitempty"`
+	DistanceM       *float64 `json:"distance_m,omitempty"`
+	// 体重を含めた実効負荷 (kg)。外部負荷の種目では weight_kg と同じ
+	EffectiveLoadKg *float64 `json:"effective_load_kg,omitempty"`
 }
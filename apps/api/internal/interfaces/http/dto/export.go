@@ -0,0 +1,99 @@
+package dto
+
+import "github.com/google/uuid"
+
+// ExportSet はエクスポートするセットを表す
+type ExportSet struct {
+	ID              uuid.UUID `json:"id"`
+	ExerciseID      uuid.UUID `json:"exercise_id"`
+	ExerciseName    string    `json:"exercise_name"`
+	SetOrder        int32     `json:"set_order"`
+	SetType         string    `json:"set_type"`
+	GroupKey        *string   `json:"group_key"`
+	WeightKg        float64   `json:"weight_kg"`
+	Reps            int32     `json:"reps"`
+	Rir             *float64  `json:"rir"`
+	Rpe             *float64  `json:"rpe"`
+	DurationSeconds *int32    `json:"duration_seconds"`
+	DistanceM       *float64  `json:"distance_m"`
+	AvgHeartRate    *int32    `json:"avg_heart_rate"`
+}
+
+// ExportWorkout はエクスポートするワークアウトを表す
+type ExportWorkout struct {
+	ID        uuid.UUID   `json:"id"`
+	StartedAt string      `json:"started_at" format:"date-time"`
+	MenuName  *string     `json:"menu_name"`
+	Note      *string     `json:"note"`
+	Sets      []ExportSet `json:"sets"`
+}
+
+// ExportMenuItem はエクスポートするメニュー項目を表す
+type ExportMenuItem struct {
+	SetOrder               int32   `json:"set_order"`
+	ExerciseName           string  `json:"exercise_name"`
+	PlannedSets            *int32  `json:"planned_sets"`
+	PlannedReps            *int32  `json:"planned_reps"`
+	PlannedIntervalSeconds *int32  `json:"planned_interval_seconds"`
+	GroupKey               *string `json:"group_key"`
+}
+
+// ExportMenu はエクスポートするメニューを表す
+type ExportMenu struct {
+	ID          uuid.UUID        `json:"id"`
+	Name        string           `json:"name"`
+	Description *string          `json:"description"`
+	CreatedAt   *string          `json:"created_at" format:"date-time"`
+	Items       []ExportMenuItem `json:"items"`
+}
+
+// ExportCustomExercise はエクスポートするカスタム種目を表す
+type ExportCustomExercise struct {
+	ID              uuid.UUID `json:"id"`
+	Name            string    `json:"name"`
+	MainMuscleGroup *string   `json:"main_muscle_group"`
+	LoadType        string    `json:"load_type"`
+	MetricType      string    `json:"metric_type"`
+	CreatedAt       *string   `json:"created_at" format:"date-time"`
+}
+
+// ExportWeeklyVolume はエクスポートする週間ボリュームを表す
+type ExportWeeklyVolume struct {
+	WeekStartDate string  `json:"week_start_date" format:"date"`
+	TotalVolume   float64 `json:"total_volume"`
+	EstOneRM      float64 `json:"est_one_rm"`
+	ExerciseCount int32   `json:"exercise_count"`
+	SetCount      int32   `json:"set_count"`
+}
+
+// ExportDocument は JSON エクスポート (GET /me/export?format=json) の全体を表す
+// 書き込みは ExportService が順次行うため、読み込み (管理コマンドのユーザーデータの取り込み) に使う
+type ExportDocument struct {
+	ExportedAt      string                 `json:"exported_at" format:"date-time"`
+	From            *string                `json:"from" format:"date"`
+	To              *string                `json:"to" format:"date"`
+	Workouts        []ExportWorkout        `json:"workouts"`
+	Menus           []ExportMenu           `json:"menus"`
+	CustomExercises []ExportCustomExercise `json:"custom_exercises"`
+	WeeklyVolumes   []ExportWeeklyVolume   `json:"weekly_volumes"`
+}
+
+// ExportJobRequest は非同期エクスポートジョブの作成リクエストを表す
+type ExportJobRequest struct {
+	From string `json:"from,omitempty" format:"date"`
+	To   string `json:"to,omitempty" format:"date"`
+}
+
+// ExportJobView は非同期エクスポートジョブのレスポンスを表す
+type ExportJobView struct {
+	ID          uuid.UUID `json:"id"`
+	Status      string    `json:"status" enum:"pending,running,completed,failed"`
+	From        *string   `json:"from" format:"date"`
+	To          *string   `json:"to" format:"date"`
+	ArchiveSize *int64    `json:"archive_size,omitempty"` // バイト数
+	Error       *string   `json:"error,omitempty"`
+	CreatedAt   string    `json:"created_at" format:"date-time"`
+	CompletedAt *string   `json:"completed_at,omitempty" format:"date-time"`
+	ExpiresAt   *string   `json:"expires_at,omitempty" format:"date-time"`
+	DownloadURL *string   `json:"download_url,omitempty"` // 完了したジョブのみ
+}
@@ -0,0 +1,46 @@
+package dto
+
+import "github.com/google/uuid"
+
+// HistoryTopWeight は期間内の種目ごとの最高重量を表す
+type HistoryTopWeight struct {
+	ExerciseID   uuid.UUID `json:"exercise_id"`
+	ExerciseName string    `json:"exercise_name"`
+	MaxWeightKg  float64   `json:"max_weight_kg"`
+	SetCount     int64     `json:"set_count"` // ウォームアップを除くセット数
+}
+
+// HistoryPeriod は期間 (日・週・月) ごとのトレーニング集計を表す
+type HistoryPeriod struct {
+	PeriodStart  string             `json:"period_start" format:"date"`
+	PeriodEnd    string             `json:"period_end" format:"date"` // 当日を含む
+	SessionCount int64              `json:"session_count"`
+	SetCount     int64              `json:"set_count"`    // 筋トレ種目のウォームアップを除くセット数 (有酸素・時間計測の種目は含まない)
+	TotalVolume  float64            `json:"total_volume"` // 筋トレ種目のボリューム (有酸素・時間計測の種目は含まない)
+	TopWeights   []HistoryTopWeight `json:"top_weights"`
+}
+
+// HistoryResponse は期間ごとのトレーニング履歴のレスポンスを表す (新しい順)
+type HistoryResponse struct {
+	Interval   string          `json:"interval" enum:"day,week,month"`
+	TimeZone   string          `json:"time_zone"`          // 例: Asia/Tokyo
+	From       string          `json:"from" format:"date"` // 集計単位の境界に揃えた開始日
+	To         string          `json:"to" format:"date"`   // 集計単位の境界に揃えた終了日
+	Periods    []HistoryPeriod `json:"periods"`            // トレーニングした期間のみ
+	NextCursor *string         `json:"next_cursor"`
+}
+
+// CalendarDay はトレーニングした日のカレンダーのマーカーを表す
+type CalendarDay struct {
+	Date         string  `json:"date" format:"date"`
+	SessionCount int64   `json:"session_count"`
+	SetCount     int64   `json:"set_count"`    // 筋トレ種目のウォームアップを除くセット数
+	TotalVolume  float64 `json:"total_volume"` // 筋トレ種目のボリューム
+}
+
+// HistoryCalendarResponse は月のカレンダーのレスポンスを表す
+type HistoryCalendarResponse struct {
+	Month    string        `json:"month"`     // YYYY-MM
+	TimeZone string        `json:"time_zone"` // 例: Asia/Tokyo
+	Days     []CalendarDay `json:"days"`      // トレーニングした日のみ (古い順)
+}
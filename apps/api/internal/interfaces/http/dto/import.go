@@ -0,0 +1,63 @@
+package dto
+
+import "github.com/google/uuid"
+
+// ImportRequest はワークアウト履歴の取り込み条件を表す (multipart の options パートの JSON)
+type ImportRequest struct {
+	Source           string                           `json:"source" enum:"strong,hevy,fitnotes"`
+	DryRun           bool                             `json:"dry_run,omitempty"`                    // true の場合は保存せずに結果のみ返す
+	TimeZone         string                           `json:"tz,omitempty"`                         // CSV の日時のタイムゾーン (デフォルト Asia/Tokyo)
+	WeightUnit       string                           `json:"weight_unit,omitempty" enum:"kg,lb"`   // Strong の重量の単位 (デフォルト kg)
+	DistanceUnit     string                           `json:"distance_unit,omitempty" enum:"km,mi"` // Strong の距離の単位 (デフォルト km)
+	CreateMissing    bool                             `json:"create_missing,omitempty"`
+	ExerciseMappings map[string]ImportExerciseMapping `json:"exercise_mappings,omitempty"` // キーは CSV の種目名
+}
+
+// ImportExerciseMapping は CSV の種目名の取り込み先を表す (exercise_id か create のどちらかを指定)
+type ImportExerciseMapping struct {
+	ExerciseID *uuid.UUID `json:"exercise_id,omitempty"`
+	Create     bool       `json:"create,omitempty"` // カスタム種目として作成する
+}
+
+// ImportExerciseCandidate は解決できない種目名の候補を表す
+type ImportExerciseCandidate struct {
+	ExerciseID   uuid.UUID `json:"exercise_id"`
+	ExerciseName string    `json:"exercise_name"`
+	Similarity   float64   `json:"similarity"`
+}
+
+// ImportExerciseResolution は CSV の種目名の解決結果を表す
+type ImportExerciseResolution struct {
+	SourceName   string                    `json:"source_name"`
+	Status       string                    `json:"status" enum:"exact,alias,fuzzy,mapped,create,unresolved"`
+	ExerciseID   *uuid.UUID                `json:"exercise_id"`
+	ExerciseName *string                   `json:"exercise_name"`
+	MetricType   *string                   `json:"metric_type"`
+	Similarity   *float64                  `json:"similarity,omitempty"`
+	Candidates   []ImportExerciseCandidate `json:"candidates,omitempty"`
+	SetCount     int                       `json:"set_count"`
+}
+
+// ImportWarning は取り込めなかった行を表す
+type ImportWarning struct {
+	Row     int    `json:"row"` // CSV の行番号 (ヘッダーが1行目)
+	Message string `json:"message"`
+}
+
+// ImportReport はワークアウト履歴の取り込み結果を表す
+type ImportReport struct {
+	Source                       string                     `json:"source"`
+	DryRun                       bool                       `json:"dry_run"`
+	WorkoutCount                 int                        `json:"workout_count"`
+	SetCount                     int                        `json:"set_count"`
+	CreatedExerciseCount         int                        `json:"created_exercise_count"`
+	SkippedDuplicateWorkoutCount int                        `json:"skipped_duplicate_workout_count"`
+	SkippedSetCount              int                        `json:"skipped_set_count"`
+	From                         *string                    `json:"from" format:"date-time"` // 最初のワークアウトの開始日時
+	To                           *string                    `json:"to" format:"date-time"`   // 最後のワークアウトの開始日時
+	AffectedWeeks                []string                   `json:"affected_weeks"`
+	Exercises                    []ImportExerciseResolution `json:"exercises"`
+	UnresolvedCount              int                        `json:"unresolved_count"`
+	Warnings                     []ImportWarning            `json:"warnings"`
+	WarningCount                 int                        `json:"warning_count"` // warnings は先頭の一部のみ返す
+}
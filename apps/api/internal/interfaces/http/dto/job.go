@@ -0,0 +1,22 @@
+package dto
+
+import "encoding/json"
+
+// FailedJob は失敗したバックグラウンドジョブを表す
+type FailedJob struct {
+	ID          int64           `json:"id"`
+	Kind        string          `json:"kind"`
+	Args        json.RawMessage `json:"args"`
+	Attempt     int32           `json:"attempt"`
+	MaxAttempts int32           `json:"max_attempts"`
+	UniqueKey   *string         `json:"unique_key"`
+	LastError   *string         `json:"last_error"`
+	CreatedAt   string          `json:"created_at"` // RFC3339
+	FailedAt    *string         `json:"failed_at"`  // RFC3339
+}
+
+// FailedJobsResponse は失敗したバックグラウンドジョブの一覧のレスポンスを表す (新しい順)
+type FailedJobsResponse struct {
+	Jobs       []FailedJob `json:"jobs"`
+	NextCursor *string     `json:"next_cursor"` // 次ページがない場合は null
+}
@@ -0,0 +1,67 @@
+package dto
+
+import "github.com/google/uuid"
+
+// MeasurementMetric は計測項目を表す
+type MeasurementMetric struct {
+	Code string `json:"code"` // 例: body_weight, body_fat_percentage, waist
+	Name string `json:"name"`
+	Unit string `json:"unit"` // kg / % / cm
+}
+
+// CreateMeasurementRequest は身体計測記録の作成リクエストを表す
+type CreateMeasurementRequest struct {
+	Metric     string  `json:"metric"`
+	Value      float64 `json:"value"`
+	MeasuredAt string  `json:"measured_at,omitempty" format:"date-time"` // 省略時は現在時刻
+	Note       *string `json:"note,omitempty"`
+}
+
+// UpdateMeasurementRequest は身体計測記録の更新リクエストを表す (未指定のフィールドは変更しない)
+type UpdateMeasurementRequest struct {
+	Value      *float64 `json:"value,omitempty"`
+	MeasuredAt *string  `json:"measured_at,omitempty" format:"date-time"`
+	Note       *string  `json:"note,omitempty"` // 空文字列でメモを削除
+}
+
+// MeasurementView は身体計測記録のレスポンスを表す
+type MeasurementView struct {
+	ID         uuid.UUID `json:"id"`
+	Metric     string    `json:"metric"`
+	Value      float64   `json:"value"`
+	MeasuredAt string    `json:"measured_at" format:"date-time"`
+	Note       *string   `json:"note,omitempty"`
+}
+
+// MeasurementSeriesPoint は時系列データの1点を表す
+type MeasurementSeriesPoint struct {
+	Date          string   `json:"date" format:"date"` // interval=week の場合は週の開始日 (月曜)
+	Value         float64  `json:"value"`              // 期間内の平均値
+	MovingAverage *float64 `json:"moving_avg_7d,omitempty"`
+	Min           *float64 `json:"min,omitempty"`
+	Max           *float64 `json:"max,omitempty"`
+	Count         *int     `json:"count,omitempty"`
+}
+
+// MeasurementSeriesResponse は計測項目の時系列データのレスポンスを表す
+type MeasurementSeriesResponse struct {
+	Metric   string                   `json:"metric"`
+	Unit     string                   `json:"unit"`
+	Interval string                   `json:"interval" enum:"day,week"`
+	Points   []MeasurementSeriesPoint `json:"points"`
+}
+
+// RelativeStrengthPoint は週ごとの相対筋力 (推定1RM/体重) を表す
+type RelativeStrengthPoint struct {
+	Week         string   `json:"week" format:"date"` // 週の開始日
+	EstOneRM     float64  `json:"est_one_rm"`
+	BodyWeightKg *float64 `json:"body_weight_kg,omitempty"` // 体重の記録がない場合は省略
+	Ratio        *float64 `json:"ratio,omitempty"`          // 推定1RM / 体重
+}
+
+// RelativeStrengthResponse は種目の相対筋力の推移のレスポンスを表す
+type RelativeStrengthResponse struct {
+	ExerciseID   uuid.UUID               `json:"exercise_id"`
+	ExerciseName string                  `json:"exercise_name"`
+	Points       []RelativeStrengthPoint `json:"points"`
+}
@@ -23,6 +23,7 @@ type MenuItemInput struct {
 	PlannedSets            *int32    `json:"planned_sets,omitempty"`
 	PlannedReps            *int32    `json:"planned_reps,omitempty"`
 	PlannedIntervalSeconds *int32    `json:"planned_interval_seconds,omitempty"`
+	GroupKey               *string   `json:"group_key,omitempty"` // 同じ値の項目はスーパーセット/サーキットとして実施
 }
 
 // MenuResponse はメニュー作成レスポンスを表す
@@ -30,7 +31,7 @@ type MenuResponse struct {
 	ID          uuid.UUID      `json:"id"`
 	Name        string         `json:"name"`
 	Description *string        `json:"description,omitempty"`
-	CreatedAt   string         `json:"created_at"`
+	CreatedAt   string         `json:"created_at" format:"date-time"`
 	Items       []MenuItemView `json:"items"`
 }
 
@@ -43,4 +44,5 @@ type MenuItemView struct {
 	PlannedSets            *int32    `json:"planned_sets,omitempty"`
 	PlannedReps            *int32    `json:"planned_reps,omitempty"`
 	PlannedIntervalSeconds *int32    `json:"planned_interval_seconds,omitempty"`
+	GroupKey               *string   `json:"group_key,omitempty"` // 同じ値の項目はスーパーセット/サーキットとして実施
 }
@@ -0,0 +1,54 @@
+package dto
+
+import "github.com/google/uuid"
+
+// MenuShareView はメニューの共有コードのレスポンスを表す
+type MenuShareView struct {
+	ShareCode  string    `json:"share_code"`
+	MenuID     uuid.UUID `json:"menu_id"`
+	IsPublic   bool      `json:"is_public"` // テンプレートライブラリに表示されるか
+	CloneCount int32     `json:"clone_count"`
+	CreatedAt  string    `json:"created_at" format:"date-time"`
+}
+
+// SharedMenuItemView は共有されたメニューの項目 (プレビュー) を表す
+type SharedMenuItemView struct {
+	SetOrder               int32   `json:"set_order"`
+	ExerciseName           string  `json:"exercise_name"`
+	MetricType             string  `json:"metric_type" enum:"weight_reps,reps,time,distance_time"`
+	IsCustomExercise       bool    `json:"is_custom_exercise"` // 複製すると同じ名前のカスタム種目を使う (ない場合は作成する)
+	PlannedSets            *int32  `json:"planned_sets"`
+	PlannedReps            *int32  `json:"planned_reps"`
+	PlannedIntervalSeconds *int32  `json:"planned_interval_seconds"`
+	GroupKey               *string `json:"group_key"`
+}
+
+// SharedMenuView は共有されたメニューのプレビューを表す (共有したユーザーは含まない)
+type SharedMenuView struct {
+	ShareCode   string               `json:"share_code"`
+	Name        string               `json:"name"`
+	Description *string              `json:"description"`
+	IsTemplate  bool                 `json:"is_template"`
+	CloneCount  int32                `json:"clone_count"`
+	Items       []SharedMenuItemView `json:"items"`
+}
+
+// MenuTemplateView はテンプレートライブラリのメニューを表す
+type MenuTemplateView struct {
+	ShareCode   string  `json:"share_code"`
+	Name        string  `json:"name"`
+	Description *string `json:"description"`
+	ItemCount   int32   `json:"item_count"`
+	CloneCount  int32   `json:"clone_count"`
+}
+
+// CloneMenuRequest は共有されたメニューの複製リクエストを表す
+type CloneMenuRequest struct {
+	Name string `json:"name,omitempty"` // 省略した場合は共有されたメニューの名前 (重複する場合は "名前 (2)" など)
+}
+
+// CloneMenuResponse は複製したメニューのレスポンスを表す
+type CloneMenuResponse struct {
+	Menu             *MenuResponse `json:"menu"`
+	CreatedExercises []string      `json:"created_exercises"` // 複製のために作成したカスタム種目の名前
+}
@@ -0,0 +1,28 @@
+package dto
+
+import "github.com/google/uuid"
+
+// CreatePersonalAccessTokenRequest はパーソナルアクセストークンの作成リクエストを表す
+type CreatePersonalAccessTokenRequest struct {
+	Name          string   `json:"name"`
+	Scopes        []string `json:"scopes"`                    // read:workouts, write:sets, read:volume など
+	ExpiresInDays *int     `json:"expires_in_days,omitempty"` // 1〜365 (省略した場合は無期限)
+}
+
+// PersonalAccessTokenView はパーソナルアクセストークンのレスポンスを表す (トークンそのものは含まない)
+type PersonalAccessTokenView struct {
+	ID          uuid.UUID `json:"id"`
+	Name        string    `json:"name"`
+	TokenPrefix string    `json:"token_prefix"` // トークンの先頭部分 (見分けるため)
+	Scopes      []string  `json:"scopes"`
+	ExpiresAt   *string   `json:"expires_at" format:"date-time"`
+	LastUsedAt  *string   `json:"last_used_at" format:"date-time"`
+	CreatedAt   string    `json:"created_at" format:"date-time"`
+}
+
+// CreatedPersonalAccessToken は作成したパーソナルアクセストークンのレスポンスを表す
+// Token は作成時にのみ返す (サーバーにはハッシュのみ保存する)
+type CreatedPersonalAccessToken struct {
+	PersonalAccessTokenView
+	Token string `json:"token"`
+}
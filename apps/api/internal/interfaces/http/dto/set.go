@@ -3,8 +3,13 @@ package dto
 // UpdateSetRequest はセット更新リクエストを表す
 // RIR と RPE はどちらか一方、または両方がnull許容で送信されることを想定
 type UpdateSetRequest struct {
-	WeightKg *float64 `json:"weight_kg"`     // ポインタにしてnullを許容 (重量0kgを区別するため)
-	Reps     *int32   `json:"reps"`          // ポインタにしてnullを許容 (0 repsを区別するため)
-	RIR      *float64 `json:"rir,omitempty"` // Reps in Reserve
-	RPE      *float64 `json:"rpe,omitempty"` // Rating of Perceived Exertion
+	WeightKg        *float64 `json:"weight_kg,omitempty"`        // 未指定の場合は変更しない (ポインタにして重量0kgと区別する)
+	Reps            *int32   `json:"reps,omitempty"`             // 未指定の場合は変更しない (ポインタにして0 repsと区別する)
+	RIR             *float64 `json:"rir,omitempty"`              // Reps in Reserve
+	RPE             *float64 `json:"rpe,omitempty"`              // Rating of Perceived Exertion
+	SetType         *string  `json:"set_type,omitempty"`         // 未指定の場合は変更しない
+	GroupKey        *string  `json:"group_key,omitempty"`        // 未指定の場合は変更しない (空文字列でグループ解除)
+	DurationSeconds *int32   `json:"duration_seconds,omitempty"` // 未指定の場合は変更しない (time / distance_time 種目)
+	DistanceM       *float64 `json:"distance_m,omitempty"`       // 未指定の場合は変更しない (distance_time 種目)
+	AvgHeartRate    *int32   `json:"avg_heart_rate,omitempty"`   // 未指定の場合は変更しない
 }
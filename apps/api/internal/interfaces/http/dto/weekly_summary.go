@@ -2,11 +2,20 @@ package dto
 
 // WeeklySummaryResponse は週間トレーニングボリュームのレスポンス
 type WeeklySummaryResponse struct {
-	Week          string  `json:"week"`           // 週の開始日（ISO形式）
-	TotalVolume   float64 `json:"total_volume"`   // 総ボリューム（重量 x レップ数の合計）
-	EstOneRM      float64 `json:"est_1rm"`        // 推定1RMの最大値
-	ExerciseCount int     `json:"exercise_count"` // 種目数
-	SetCount      int     `json:"set_count"`      // セット数
+	Week          string  `json:"week" format:"date-time"` // 週の開始日
+	TotalVolume   float64 `json:"total_volume"`            // 総ボリューム（重量 x レップ数の合計、有酸素・時間計測の種目は除く）
+	EstOneRM      float64 `json:"est_1rm"`                 // 推定1RMの最大値
+	ExerciseCount int     `json:"exercise_count"`          // 種目数
+	SetCount      int     `json:"set_count"`               // セット数
+	// 有酸素・時間計測の種目 (total_volume とは別に集計)
+	CardioMinutes    float64 `json:"cardio_minutes"`     // 合計時間（分）
+	CardioDistanceKm float64 `json:"cardio_distance_km"` // 合計距離（km）
+	CardioSetCount   int     `json:"cardio_set_count"`   // セット数
+}
+
+// RecalculateWeeklyVolumeRequest は週間ボリュームの再計算リクエストを表す
+type RecalculateWeeklyVolumeRequest struct {
+	Week string `json:"week" format:"date-time"` // 週の開始日
 }
 
 // WeeklyVolumeSummaryResponse は週間ボリューム統計のレスポンス
@@ -5,15 +5,21 @@ import "github.com/google/uuid"
 // ExerciseWithSets はワークアウト時のエクササイズとセットを表す
 type ExerciseWithSets struct {
 	ExerciseID string       `json:"exercise_id"`
+	GroupKey   *string      `json:"group_key,omitempty"` // スーパーセット/サーキットのグループキー
 	Sets       []WorkoutSet `json:"sets"`
 }
 
 // WorkoutSet はワークアウトセットの詳細を表す
 type WorkoutSet struct {
-	WeightKg float64  `json:"weight_kg"`
+	WeightKg *float64 `json:"weight_kg,omitempty"` // 自重系の種目 (load_type が external 以外) では省略可
 	Reps     int32    `json:"reps"`
 	RIR      *float64 `json:"rir,omitempty"`
 	RPE      *float64 `json:"rpe,omitempty"`
+	SetType  string   `json:"set_type,omitempty" enum:"warmup,working,drop,failure,amrap"` // 未指定は working
+	// 有酸素・時間計測の種目 (metric_type が time / distance_time) で使用
+	DurationSeconds *int32   `json:"duration_seconds,omitempty"`
+	DistanceM       *float64 `json:"distance_m,omitempty"`
+	AvgHeartRate    *int32   `json:"avg_heart_rate,omitempty"`
 }
 
 // CreateWorkoutRequest はワークアウト作成リクエストを表す
@@ -28,7 +34,7 @@ type WorkoutResponse struct {
 	ID        uuid.UUID `json:"id"`
 	MenuID    uuid.UUID `json:"menu_id"`
 	MenuName  string    `json:"menu_name"`
-	StartedAt string    `json:"started_at"`
+	StartedAt string    `json:"started_at" format:"date-time"`
 	Note      string    `json:"note,omitempty"`
 	Sets      []SetView `json:"sets"`
 }
@@ -38,10 +44,16 @@ type WorkoutSummary struct {
 	ID        uuid.UUID `json:"id"`
 	MenuID    uuid.UUID `json:"menu_id"`
 	MenuName  string    `json:"menu_name"`
-	StartedAt string    `json:"started_at"`
+	StartedAt string    `json:"started_at" format:"date-time"`
 	Note      string    `json:"note,omitempty"`
 }
 
+// WorkoutListResponse はワークアウト一覧 (カーソルページング) のレスポンスを表す
+type WorkoutListResponse struct {
+	Data       []WorkoutSummary `json:"data"`
+	NextCursor *string          `json:"next_cursor"` // 次ページがない場合は null
+}
+
 // SetView はセットの表示を表す
 type SetView struct {
 	ID       uuid.UUID `json:"id"`
@@ -51,4 +63,13 @@ type SetView struct {
 	Reps     int32     `json:"reps"`
 	RIR      float64   `json:"rir"`
 	RPE      float64   `json:"rpe"`
+	SetType  string    `json:"set_type" enum:"warmup,working,drop,failure,amrap"`
+	GroupKey *string   `json:"group_key,omitempty"`
+	// 種目の記録指標 (weight_reps / reps / time / distance_time)
+	MetricType string `json:"metric_type" enum:"weight_reps,reps,time,distance_time"`
+	// 有酸素・時間計測の種目のみ
+	DurationSeconds  *int32   `json:"duration_seconds,omitempty"`
+	DistanceM        *float64 `json:"distance_m,omitempty"`
+	PaceSecondsPerKm *float64 `json:"pace_sec_per_km,omitempty"` // 距離と時間から算出
+	AvgHeartRate     *int32   `json:"avg_heart_rate,omitempty"`
 }
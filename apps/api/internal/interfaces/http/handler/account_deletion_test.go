@@ -0,0 +1,192 @@
+package handler
+
+import (
+	"context"
+	"io"
+	"log/slog"
+	"net/http"
+	"testing"
+	"time"
+
+	"github.com/aiirononeko/bulktrack/apps/api/internal/interfaces/http/dto"
+	"github.com/aiirononeko/bulktrack/apps/api/internal/interfaces/service"
+	"github.com/google/uuid"
+)
+
+// アカウント削除は30日の猶予期間の間は取り消せ、期限を過ぎると削除するユーザーのデータのみ消去する
+func TestAccountDeletionGracePeriod(t *testing.T) {
+	ctx := context.Background()
+	logger := slog.New(slog.NewTextHandler(io.Discard, nil))
+	env := newDBTestServer(t, false)
+	pool := env.pool
+
+	const (
+		userID      = "user_account_deletion_test"
+		otherUserID = "user_account_deletion_other"
+	)
+	do := env.as(t, userID)
+	deletions := service.NewAccountDeletionService(pool, logger)
+	decode := func(data []byte) (view dto.AccountDeletionView) {
+		t.Helper()
+		decodeJSON(t, data, &view)
+		return view
+	}
+	count := func(query string, args ...any) int {
+		t.Helper()
+		var n int
+		if err := pool.QueryRow(ctx, query, args...).Scan(&n); err != nil {
+			t.Fatalf("failed to count rows: %v", err)
+		}
+		return n
+	}
+	purge := func(want int) {
+		t.Helper()
+		purged, err := deletions.PurgeDueDeletions(ctx)
+		if err != nil {
+			t.Fatalf("PurgeDueDeletions() error = %v", err)
+		}
+		if purged != want {
+			t.Fatalf("PurgeDueDeletions() = %d, want %d", purged, want)
+		}
+	}
+
+	// 削除するユーザーのカスタム種目2件 (1件は他のユーザーのメニューからも参照される) と、両ユーザーのメニュー・ワークアウト
+	var ownExerciseID, sharedExerciseID uuid.UUID
+	for name, id := range map[string]*uuid.UUID{"Own custom": &ownExerciseID, "Shared custom": &sharedExerciseID} {
+		if err := pool.QueryRow(ctx, "INSERT INTO exercises (name, is_custom, created_by_user_id) VALUES ($1, TRUE, $2) RETURNING id", name, userID).Scan(id); err != nil {
+			t.Fatalf("failed to insert exercise: %v", err)
+		}
+	}
+	for _, owner := range []struct {
+		userID     string
+		exerciseID uuid.UUID
+	}{{userID, ownExerciseID}, {otherUserID, sharedExerciseID}} {
+		if _, err := pool.Exec(ctx, `
+			WITH m AS (INSERT INTO menus (user_id, name) VALUES ($1, 'Menu') RETURNING id),
+			     mi AS (INSERT INTO menu_items (menu_id, exercise_id, set_order) SELECT id, $2, 1 FROM m),
+			     w AS (INSERT INTO workouts (user_id, menu_id) SELECT $1, id FROM m RETURNING id)
+			INSERT INTO sets (workout_id, exercise_id, set_order, weight_kg, reps) SELECT id, $2, 1, 50, 10 FROM w`, owner.userID, owner.exerciseID); err != nil {
+			t.Fatalf("failed to insert menu and workout: %v", err)
+		}
+	}
+	if _, err := pool.Exec(ctx, "INSERT INTO body_measurements (user_id, metric_code, value, measured_at) VALUES ($1, 'body_weight', 65, now())", userID); err != nil {
+		t.Fatalf("failed to insert body measurement: %v", err)
+	}
+	// 完了・失敗したエクスポートのジョブ (引数にユーザーIDが残る) と他のユーザーの実行待ちのジョブ
+	if _, err := pool.Exec(ctx, `
+		INSERT INTO jobs (kind, args, state, max_attempts, completed_at, failed_at) VALUES
+			('export.archive', jsonb_build_object('user_id', $1::text, 'from', '2025-01-01'), 'completed', 5, now(), NULL),
+			('export.archive', jsonb_build_object('user_id', $1::text), 'failed', 5, NULL, now()),
+			('export.archive', jsonb_build_object('user_id', $2::text), 'available', 5, NULL, NULL)`, userID, otherUserID); err != nil {
+		t.Fatalf("failed to insert jobs: %v", err)
+	}
+
+	do("GET /me/deletion", "/me/deletion", nil, http.StatusNotFound)
+	do("POST /me/deletion/cancel", "/me/deletion/cancel", nil, http.StatusNotFound)
+
+	// 予約すると30日後に削除する。予約中に再度削除を依頼しても同じ予約を返す
+	first := decode(do("DELETE /me", "/me", nil, http.StatusAccepted))
+	requestedAt, err := time.Parse(time.RFC3339, first.RequestedAt)
+	if err != nil {
+		t.Fatalf("requested_at = %q: %v", first.RequestedAt, err)
+	}
+	scheduledFor, err := time.Parse(time.RFC3339, first.ScheduledFor)
+	if err != nil {
+		t.Fatalf("scheduled_for = %q: %v", first.ScheduledFor, err)
+	}
+	if first.Status != "pending" || first.Source != service.AccountDeletionSourceAPI {
+		t.Errorf("DELETE /me = %+v, want a pending deletion from the API", first)
+	}
+	if gap := scheduledFor.Sub(requestedAt); gap < service.AccountDeletionGracePeriod-time.Minute || gap > service.AccountDeletionGracePeriod+time.Minute {
+		t.Errorf("scheduled_for - requested_at = %v, want %v", gap, service.AccountDeletionGracePeriod)
+	}
+	if again := decode(do("DELETE /me", "/me", nil, http.StatusAccepted)); again.ID != first.ID || again.ScheduledFor != first.ScheduledFor {
+		t.Errorf("DELETE /me again = %+v, want the same deletion %+v", again, first)
+	}
+	if got := decode(do("GET /me/deletion", "/me/deletion", nil, http.StatusOK)); got.ID != first.ID {
+		t.Errorf("GET /me/deletion = %+v, want %s", got, first.ID)
+	}
+
+	// 猶予期間中は削除しない
+	purge(0)
+	if n := count("SELECT COUNT(*) FROM workouts WHERE user_id = $1", userID); n != 1 {
+		t.Fatalf("workouts during the grace period = %d, want 1", n)
+	}
+
+	// 取り消すと予約はなくなり、期限を過ぎても削除しない
+	cancelled := decode(do("POST /me/deletion/cancel", "/me/deletion/cancel", nil, http.StatusOK))
+	if cancelled.ID != first.ID || cancelled.Status != "cancelled" || cancelled.CancelledAt == nil {
+		t.Errorf("POST /me/deletion/cancel = %+v, want the cancelled deletion", cancelled)
+	}
+	do("GET /me/deletion", "/me/deletion", nil, http.StatusNotFound)
+	do("POST /me/deletion/cancel", "/me/deletion/cancel", nil, http.StatusNotFound)
+	if _, err := pool.Exec(ctx, "UPDATE account_deletions SET scheduled_for = now() - interval '1 second' WHERE id = $1", first.ID); err != nil {
+		t.Fatalf("failed to move scheduled_for: %v", err)
+	}
+	purge(0)
+
+	// 取り消した後は新しく予約でき、期限を過ぎると削除する
+	second := decode(do("DELETE /me", "/me", nil, http.StatusAccepted))
+	if second.ID == first.ID || second.Status != "pending" {
+		t.Fatalf("DELETE /me after cancelling = %+v, want a new pending deletion", second)
+	}
+	if _, err := pool.Exec(ctx, "UPDATE account_deletions SET scheduled_for = now() - interval '1 second' WHERE id = $1", second.ID); err != nil {
+		t.Fatalf("failed to move scheduled_for: %v", err)
+	}
+	if due, err := deletions.CountDueDeletions(ctx); err != nil || due != 1 {
+		t.Fatalf("CountDueDeletions() = %d, %v, want 1", due, err)
+	}
+	purge(1)
+	purge(0)
+
+	for _, tt := range []struct {
+		query string
+		want  int
+	}{
+		{query: "SELECT COUNT(*) FROM workouts WHERE user_id = $1", want: 0},
+		{query: "SELECT COUNT(*) FROM sets s JOIN workouts w ON s.workout_id = w.id WHERE w.user_id = $1", want: 0},
+		{query: "SELECT COUNT(*) FROM menus WHERE user_id = $1", want: 0},
+		{query: "SELECT COUNT(*) FROM body_measurements WHERE user_id = $1", want: 0},
+		{query: "SELECT COUNT(*) FROM weekly_volumes WHERE user_id = $1", want: 0},
+		{query: "SELECT COUNT(*) FROM weekly_volume_dirty_weeks WHERE user_id = $1", want: 0},
+		{query: "SELECT COUNT(*) FROM jobs WHERE args->>'user_id' = $1", want: 0},
+		{query: "SELECT COUNT(*) FROM exercises WHERE created_by_user_id = $1", want: 0},
+		{query: "SELECT COUNT(*) FROM account_deletions WHERE user_id = $1", want: 1}, // 取り消した予約のみ残る
+	} {
+		if n := count(tt.query, userID); n != tt.want {
+			t.Errorf("%s = %d, want %d", tt.query, n, tt.want)
+		}
+	}
+
+	// 他のユーザーのデータは残し、そこから参照されるカスタム種目は名前と所有者を消去して残す
+	if n := count("SELECT COUNT(*) FROM sets s JOIN workouts w ON s.workout_id = w.id WHERE w.user_id = $1", otherUserID); n != 1 {
+		t.Errorf("sets of the other user = %d, want 1", n)
+	}
+	if n := count("SELECT COUNT(*) FROM jobs WHERE args->>'user_id' = $1", otherUserID); n != 1 {
+		t.Errorf("jobs of the other user = %d, want 1", n)
+	}
+	if n := count("SELECT COUNT(*) FROM exercises WHERE id = $1", ownExerciseID); n != 0 {
+		t.Errorf("unreferenced custom exercise was not deleted")
+	}
+	var sharedName, sharedOwner string
+	if err := pool.QueryRow(ctx, "SELECT name, created_by_user_id FROM exercises WHERE id = $1", sharedExerciseID).Scan(&sharedName, &sharedOwner); err != nil {
+		t.Fatalf("failed to read the shared custom exercise: %v", err)
+	}
+	if sharedName != "deleted-"+sharedExerciseID.String() || sharedOwner != "deleted" {
+		t.Errorf("shared custom exercise = %q by %q, want it anonymized", sharedName, sharedOwner)
+	}
+
+	// 完了した予約はユーザーIDを消去し、削除件数のみ残す
+	var status string
+	var deletedUserID *string
+	var purgedCounts map[string]int64
+	if err := pool.QueryRow(ctx, "SELECT status, user_id, purged_counts FROM account_deletions WHERE id = $1", second.ID).Scan(&status, &deletedUserID, &purgedCounts); err != nil {
+		t.Fatalf("failed to read the completed deletion: %v", err)
+	}
+	if status != "completed" || deletedUserID != nil {
+		t.Errorf("completed deletion = %s for %v, want completed without a user ID", status, deletedUserID)
+	}
+	if purgedCounts["sets"] != 1 || purgedCounts["workouts"] != 1 || purgedCounts["custom_exercises"] != 1 || purgedCounts["anonymized_custom_exercises"] != 1 || purgedCounts["jobs"] != 2 {
+		t.Errorf("purged_counts = %v", purgedCounts)
+	}
+}
@@ -0,0 +1,59 @@
+package handler
+
+import (
+	"net/http"
+	"slices"
+	"testing"
+
+	"github.com/aiirononeko/bulktrack/apps/api/internal/interfaces/http/dto"
+)
+
+// 他のユーザーのメニュー・ワークアウト・セットには存在しない場合と同じ 404 を返し、変更しない
+func TestOtherUsersResourcesAreNotFound(t *testing.T) {
+	env := newDBTestServer(t, true)
+	asOwner := env.as(t, "user_authorization_owner")
+	asOther := env.as(t, "user_authorization_other")
+
+	var exercises []dto.Exercise
+	decodeJSON(t, asOwner("GET /exercises", "/exercises", nil, http.StatusOK), &exercises)
+	i := slices.IndexFunc(exercises, func(e dto.Exercise) bool { return e.MetricType == "weight_reps" })
+	if i < 0 {
+		t.Fatal("no weight_reps exercise is seeded")
+	}
+	exerciseID := exercises[i].ID
+
+	var menu dto.MenuResponse
+	decodeJSON(t, asOwner("POST /menus", "/menus", dto.CreateMenuRequest{
+		Name:  "Authorization test",
+		Items: []dto.MenuItemInput{{ExerciseID: exerciseID, SetOrder: 1}},
+	}, http.StatusCreated), &menu)
+	var workout dto.WorkoutResponse
+	weightKg := 60.0
+	decodeJSON(t, asOwner("POST /workouts", "/workouts", dto.CreateWorkoutRequest{
+		MenuID: menu.ID,
+		Exercises: []dto.ExerciseWithSets{{
+			ExerciseID: exerciseID.String(),
+			Sets:       []dto.WorkoutSet{{WeightKg: &weightKg, Reps: 10}},
+		}},
+	}, http.StatusCreated), &workout)
+	if len(workout.Sets) == 0 {
+		t.Fatal("POST /workouts returned no sets")
+	}
+	setPath := "/sets/" + workout.Sets[0].ID.String()
+	workoutPath := "/workouts/" + workout.ID.String()
+	menuPath := "/menus/" + menu.ID.String()
+
+	reps := int32(1)
+	asOther("PATCH /sets/{id}", setPath, dto.UpdateSetRequest{Reps: &reps}, http.StatusNotFound)
+	asOther("GET /workouts/{id}", workoutPath, nil, http.StatusNotFound)
+	asOther("GET /menus/{id}", menuPath, nil, http.StatusNotFound)
+	asOther("GET /menus/{id}/exercises/last-records", menuPath+"/exercises/last-records", nil, http.StatusNotFound)
+
+	// 他のユーザーの PATCH でセットが変更されていないこと
+	var got dto.WorkoutResponse
+	decodeJSON(t, asOwner("GET /workouts/{id}", workoutPath, nil, http.StatusOK), &got)
+	if len(got.Sets) == 0 || got.Sets[0].Reps != 10 {
+		t.Errorf("set was modified by another user: %+v", got.Sets)
+	}
+	asOwner("GET /menus/{id}/exercises/last-records", menuPath+"/exercises/last-records", nil, http.StatusOK)
+}
@@ -0,0 +1,164 @@
+package handler
+
+import (
+	"context"
+	"net/http"
+	"slices"
+	"testing"
+
+	"github.com/aiirononeko/bulktrack/apps/api/internal/interfaces/http/dto"
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgtype"
+)
+
+// 自重種目のセットは重量なしで記録でき、最高重量・前回の記録は体重を含めた実効負荷になる。
+// 体重の変更では、その記録を参照するワークアウトの週だけを週間ボリュームの再集計対象にする
+func TestBodyweightSetsUseEffectiveLoad(t *testing.T) {
+	ctx := context.Background()
+	env := newDBTestServer(t, true)
+	pool := env.pool
+
+	const userID = "user_bodyweight_test"
+	do := env.as(t, userID)
+
+	var exercises []dto.Exercise
+	decodeJSON(t, do("GET /exercises", "/exercises", nil, http.StatusOK), &exercises)
+	i := slices.IndexFunc(exercises, func(e dto.Exercise) bool {
+		return e.MetricType == "weight_reps" && e.LoadType == "bodyweight"
+	})
+	j := slices.IndexFunc(exercises, func(e dto.Exercise) bool {
+		return e.MetricType == "weight_reps" && e.LoadType == "external"
+	})
+	if i < 0 || j < 0 {
+		t.Fatal("no bodyweight or external weight_reps exercise is seeded")
+	}
+	bodyweightID, externalID := exercises[i].ID, exercises[j].ID
+
+	var menu dto.MenuResponse
+	decodeJSON(t, do("POST /menus", "/menus", dto.CreateMenuRequest{
+		Name: "Bodyweight test",
+		Items: []dto.MenuItemInput{
+			{ExerciseID: bodyweightID, SetOrder: 1},
+			{ExerciseID: externalID, SetOrder: 2},
+		},
+	}, http.StatusCreated), &menu)
+	do("POST /measurements", "/measurements", dto.CreateMeasurementRequest{Metric: "body_weight", Value: 70}, http.StatusCreated)
+
+	// 外部負荷の種目は重量が必須
+	do("POST /workouts", "/workouts", dto.CreateWorkoutRequest{
+		MenuID:    menu.ID,
+		Exercises: []dto.ExerciseWithSets{{ExerciseID: externalID.String(), Sets: []dto.WorkoutSet{{Reps: 10}}}},
+	}, http.StatusBadRequest)
+
+	var workout dto.WorkoutResponse
+	decodeJSON(t, do("POST /workouts", "/workouts", dto.CreateWorkoutRequest{
+		MenuID:    menu.ID,
+		Exercises: []dto.ExerciseWithSets{{ExerciseID: bodyweightID.String(), Sets: []dto.WorkoutSet{{Reps: 10}}}},
+	}, http.StatusCreated), &workout)
+	if len(workout.Sets) != 1 || workout.Sets[0].WeightKg != 0 {
+		t.Fatalf("POST /workouts sets = %+v, want one set without weight", workout.Sets)
+	}
+	var weightKg pgtype.Numeric
+	if err := pool.QueryRow(ctx, "SELECT weight_kg FROM sets WHERE id = $1", workout.Sets[0].ID).Scan(&weightKg); err != nil {
+		t.Fatalf("failed to read the set: %v", err)
+	}
+	if weightKg.Valid {
+		t.Errorf("sets.weight_kg = %v, want NULL", weightKg)
+	}
+
+	var records []dto.ExerciseLastRecord
+	decodeJSON(t, do("GET /menus/{id}/exercises/last-records", "/menus/"+menu.ID.String()+"/exercises/last-records", nil, http.StatusOK), &records)
+	k := slices.IndexFunc(records, func(r dto.ExerciseLastRecord) bool { return r.ExerciseID == bodyweightID })
+	if k < 0 || len(records[k].LastRecord) != 1 {
+		t.Fatalf("last records = %+v, want one set of the bodyweight exercise", records)
+	}
+	if got := records[k].LastRecord[0].EffectiveLoadKg; got == nil || *got != 70 {
+		t.Errorf("last record effective_load_kg = %v, want 70", got)
+	}
+
+	var history dto.HistoryResponse
+	decodeJSON(t, do("GET /history", "/history?interval=week", nil, http.StatusOK), &history)
+	if len(history.Periods) == 0 {
+		t.Fatal("GET /history returned no periods")
+	}
+	k = slices.IndexFunc(history.Periods[0].TopWeights, func(w dto.HistoryTopWeight) bool { return w.ExerciseID == bodyweightID })
+	if k < 0 || history.Periods[0].TopWeights[k].MaxWeightKg != 70 {
+		t.Errorf("top weights = %+v, want 70 kg for the bodyweight exercise", history.Periods[0].TopWeights)
+	}
+
+	// 体重の変更で再集計対象になる週 (JST の月曜)
+	if _, err := pool.Exec(ctx, `INSERT INTO workouts (user_id, started_at) VALUES ($1, '2024-01-10 09:00+09'), ($1, '2024-03-10 09:00+09')`, userID); err != nil {
+		t.Fatalf("failed to insert workouts: %v", err)
+	}
+	insertBodyWeight := func(measuredAt string) uuid.UUID {
+		t.Helper()
+		var id uuid.UUID
+		if err := pool.QueryRow(ctx, `INSERT INTO body_measurements (user_id, metric_code, value, measured_at) VALUES ($1, 'body_weight', 65, $2) RETURNING id`, userID, measuredAt).Scan(&id); err != nil {
+			t.Fatalf("failed to insert body weight: %v", err)
+		}
+		return id
+	}
+	january := insertBodyWeight("2024-01-01 08:00+09")
+	march := insertBodyWeight("2024-03-01 08:00+09")
+
+	tests := []struct {
+		name   string
+		change func() error
+		want   []string
+	}{
+		{
+			name: "update of the record used by one week",
+			change: func() error {
+				_, err := pool.Exec(ctx, "UPDATE body_measurements SET value = 66 WHERE id = $1", january)
+				return err
+			},
+			want: []string{"2024-01-08"},
+		},
+		{
+			name: "record that no workout resolves to",
+			change: func() error {
+				_, err := pool.Exec(ctx, `INSERT INTO body_measurements (user_id, metric_code, value, measured_at) VALUES ($1, 'body_weight', 65, '2024-02-01 08:00+09')`, userID)
+				return err
+			},
+			want: []string{},
+		},
+		{
+			name: "deletion moves the week to the previous record",
+			change: func() error {
+				_, err := pool.Exec(ctx, "DELETE FROM body_measurements WHERE id = $1", march)
+				return err
+			},
+			want: []string{"2024-03-04"},
+		},
+		{
+			name: "moving a record across a workout date",
+			change: func() error {
+				_, err := pool.Exec(ctx, "UPDATE body_measurements SET measured_at = '2024-01-12 08:00+09' WHERE id = $1", january)
+				return err
+			},
+			want: []string{"2024-01-08"},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if _, err := pool.Exec(ctx, "DELETE FROM weekly_volume_dirty_weeks WHERE user_id = $1", userID); err != nil {
+				t.Fatalf("failed to clear dirty weeks: %v", err)
+			}
+			if err := tt.change(); err != nil {
+				t.Fatalf("failed to change body weight: %v", err)
+			}
+			rows, err := pool.Query(ctx, "SELECT week_start_date::text FROM weekly_volume_dirty_weeks WHERE user_id = $1 ORDER BY 1", userID)
+			if err != nil {
+				t.Fatalf("failed to list dirty weeks: %v", err)
+			}
+			got, err := pgx.CollectRows(rows, pgx.RowTo[string])
+			if err != nil {
+				t.Fatalf("failed to list dirty weeks: %v", err)
+			}
+			if !slices.Equal(got, tt.want) {
+				t.Errorf("dirty weeks = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
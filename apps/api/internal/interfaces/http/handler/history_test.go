@@ -0,0 +1,138 @@
+package handler
+
+import (
+	"context"
+	"net/http"
+	"net/url"
+	"reflect"
+	"testing"
+
+	"github.com/aiirononeko/bulktrack/apps/api/internal/interfaces/http/dto"
+	"github.com/google/uuid"
+)
+
+// 日・週・月の境界は指定したタイムゾーンで区切り、セット数とボリュームは筋トレ種目のウォームアップ以外のセットのみ数える
+func TestHistoryPeriodBoundaries(t *testing.T) {
+	ctx := context.Background()
+	env := newDBTestServer(t, true)
+	pool := env.pool
+
+	const userID = "user_history_test"
+
+	var strengthID, cardioID uuid.UUID
+	if err := pool.QueryRow(ctx, "SELECT id FROM exercises WHERE metric_type = 'weight_reps' AND load_type = 'external' ORDER BY name LIMIT 1").Scan(&strengthID); err != nil {
+		t.Fatalf("failed to find a weight_reps exercise: %v", err)
+	}
+	if err := pool.QueryRow(ctx, "SELECT id FROM exercises WHERE metric_type = 'distance_time' ORDER BY name LIMIT 1").Scan(&cardioID); err != nil {
+		t.Fatalf("failed to find a distance_time exercise: %v", err)
+	}
+
+	// 日曜の深夜と月曜の未明 (UTC ではどちらも日曜)、月末の深夜と月初の未明 (UTC ではどちらも月末)
+	workouts := []struct {
+		startedAt string
+		weightKg  float64
+	}{
+		{startedAt: "2025-05-04 23:30:00+09", weightKg: 60},
+		{startedAt: "2025-05-05 00:30:00+09", weightKg: 50},
+		{startedAt: "2025-05-31 23:30:00+09", weightKg: 40},
+		{startedAt: "2025-06-01 00:10:00+09", weightKg: 30},
+	}
+	for i, w := range workouts {
+		var workoutID uuid.UUID
+		if err := pool.QueryRow(ctx, "INSERT INTO workouts (user_id, started_at) VALUES ($1, $2) RETURNING id", userID, w.startedAt).Scan(&workoutID); err != nil {
+			t.Fatalf("failed to insert workout: %v", err)
+		}
+		if _, err := pool.Exec(ctx, "INSERT INTO sets (workout_id, exercise_id, set_order, weight_kg, reps) VALUES ($1, $2, 1, $3, 10)", workoutID, strengthID, w.weightKg); err != nil {
+			t.Fatalf("failed to insert set: %v", err)
+		}
+		if i > 0 {
+			continue
+		}
+		// 最初のワークアウトにはウォームアップと有酸素のセットも記録する (どちらも数えない)
+		if _, err := pool.Exec(ctx, `
+			INSERT INTO sets (workout_id, exercise_id, set_order, weight_kg, reps, set_type, duration_seconds, distance_m) VALUES
+				($1, $2, 2, 20, 10, 'warmup', NULL, NULL),
+				($1, $3, 3, NULL, 0, 'working', 1800, 5000)`, workoutID, strengthID, cardioID); err != nil {
+			t.Fatalf("failed to insert sets: %v", err)
+		}
+	}
+
+	// get は認証したリクエストを処理し、ステータスコードを確認してレスポンスをデコードする
+	get := func(t *testing.T, pattern, path string, v any) {
+		t.Helper()
+		decodeJSON(t, env.as(t, userID)(pattern, path, nil, http.StatusOK), v)
+	}
+
+	type period struct {
+		start, end             string
+		sessionCount, setCount int64
+		totalVolume            float64
+	}
+	tests := []struct {
+		name  string
+		query url.Values
+		want  []period
+	}{
+		{
+			name:  "day in Asia/Tokyo",
+			query: url.Values{"interval": {"day"}, "from": {"2025-05-04"}, "to": {"2025-06-01"}, "tz": {"Asia/Tokyo"}},
+			want: []period{
+				{start: "2025-06-01", end: "2025-06-01", sessionCount: 1, setCount: 1, totalVolume: 300},
+				{start: "2025-05-31", end: "2025-05-31", sessionCount: 1, setCount: 1, totalVolume: 400},
+				{start: "2025-05-05", end: "2025-05-05", sessionCount: 1, setCount: 1, totalVolume: 500},
+				{start: "2025-05-04", end: "2025-05-04", sessionCount: 1, setCount: 1, totalVolume: 600},
+			},
+		},
+		{
+			name:  "day in UTC",
+			query: url.Values{"interval": {"day"}, "from": {"2025-05-04"}, "to": {"2025-06-01"}, "tz": {"UTC"}},
+			want: []period{
+				{start: "2025-05-31", end: "2025-05-31", sessionCount: 2, setCount: 2, totalVolume: 700},
+				{start: "2025-05-04", end: "2025-05-04", sessionCount: 2, setCount: 2, totalVolume: 1100},
+			},
+		},
+		{
+			name:  "week from Monday in Asia/Tokyo",
+			query: url.Values{"interval": {"week"}, "from": {"2025-04-28"}, "to": {"2025-06-01"}},
+			want: []period{
+				{start: "2025-05-26", end: "2025-06-01", sessionCount: 2, setCount: 2, totalVolume: 700},
+				{start: "2025-05-05", end: "2025-05-11", sessionCount: 1, setCount: 1, totalVolume: 500},
+				{start: "2025-04-28", end: "2025-05-04", sessionCount: 1, setCount: 1, totalVolume: 600},
+			},
+		},
+		{
+			name:  "month in Asia/Tokyo",
+			query: url.Values{"interval": {"month"}, "from": {"2025-05-01"}, "to": {"2025-06-30"}},
+			want: []period{
+				{start: "2025-06-01", end: "2025-06-30", sessionCount: 1, setCount: 1, totalVolume: 300},
+				{start: "2025-05-01", end: "2025-05-31", sessionCount: 3, setCount: 3, totalVolume: 1500},
+			},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var resp dto.HistoryResponse
+			get(t, "GET /history", "/history?"+tt.query.Encode(), &resp)
+			var got []period
+			for _, p := range resp.Periods {
+				got = append(got, period{start: p.PeriodStart, end: p.PeriodEnd, sessionCount: p.SessionCount, setCount: p.SetCount, totalVolume: p.TotalVolume})
+			}
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("periods = %+v, want %+v", got, tt.want)
+			}
+		})
+	}
+
+	t.Run("calendar in Asia/Tokyo", func(t *testing.T) {
+		var resp dto.HistoryCalendarResponse
+		get(t, "GET /history/calendar", "/history/calendar?month=2025-05", &resp)
+		want := []dto.CalendarDay{
+			{Date: "2025-05-04", SessionCount: 1, SetCount: 1, TotalVolume: 600},
+			{Date: "2025-05-05", SessionCount: 1, SetCount: 1, TotalVolume: 500},
+			{Date: "2025-05-31", SessionCount: 1, SetCount: 1, TotalVolume: 400},
+		}
+		if !reflect.DeepEqual(resp.Days, want) {
+			t.Errorf("days = %+v, want %+v", resp.Days, want)
+		}
+	})
+}
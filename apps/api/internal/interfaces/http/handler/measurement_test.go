@@ -0,0 +1,126 @@
+package handler
+
+import (
+	"math"
+	"net/http"
+	"testing"
+	"time"
+
+	"github.com/aiirononeko/bulktrack/apps/api/internal/interfaces/http/dto"
+)
+
+// 体重の日ごとの平均と7日移動平均、週ごとの平均 (JST 区切り) と、/body-weights の互換 API
+func TestMeasurementSeriesAndBodyWeights(t *testing.T) {
+	env := newDBTestServer(t, true)
+	do := env.as(t, "user_measurement_test")
+
+	approx := func(got float64, want float64) bool { return math.Abs(got-want) < 0.005 }
+
+	// 2025-05-05 (月) から 2025-05-13 (火) の体重。05-12 00:30 JST は UTC では 05-11
+	for _, m := range []struct {
+		measuredAt string
+		value      float64
+	}{
+		{"2025-05-05T07:00:00+09:00", 70},
+		{"2025-05-07T07:00:00+09:00", 71},
+		{"2025-05-07T21:00:00+09:00", 72},
+		{"2025-05-12T00:30:00+09:00", 69},
+		{"2025-05-13T07:00:00+09:00", 70},
+	} {
+		do("POST /measurements", "/measurements", dto.CreateMeasurementRequest{Metric: "body_weight", Value: m.value, MeasuredAt: m.measuredAt}, http.StatusCreated)
+	}
+
+	t.Run("daily averages and 7-day moving average", func(t *testing.T) {
+		var series dto.MeasurementSeriesResponse
+		decodeJSON(t, do("GET /measurements/series", "/measurements/series?metric=body_weight&interval=day&from=2025-05-07&to=2025-05-13", nil, http.StatusOK), &series)
+		want := []struct {
+			date          string
+			value, moving float64
+		}{
+			{"2025-05-07", 71.5, (70 + 71.5) / 2},      // 05-05 は期間外だが移動平均には含む
+			{"2025-05-12", 69, (71.5 + 69) / 2},        // 05-05 は7日の範囲外
+			{"2025-05-13", 70, (71.5 + 69 + 70) / 3.0}, // 05-07〜05-13
+		}
+		if len(series.Points) != len(want) {
+			t.Fatalf("points = %+v, want %d points", series.Points, len(want))
+		}
+		for i, w := range want {
+			p := series.Points[i]
+			if p.Date != w.date || !approx(p.Value, w.value) || p.MovingAverage == nil || !approx(*p.MovingAverage, w.moving) {
+				t.Errorf("points[%d] = {%s %v %v}, want {%s %v %v}", i, p.Date, p.Value, p.MovingAverage, w.date, w.value, w.moving)
+			}
+		}
+	})
+
+	t.Run("weekly averages include the week of a mid-week from date", func(t *testing.T) {
+		var series dto.MeasurementSeriesResponse
+		decodeJSON(t, do("GET /measurements/series", "/measurements/series?metric=body_weight&interval=week&from=2025-05-07&to=2025-05-13", nil, http.StatusOK), &series)
+		want := []struct {
+			week          string
+			avg, min, max float64
+			count         int
+		}{
+			{"2025-05-05", 71, 70, 72, 3},
+			{"2025-05-12", 69.5, 69, 70, 2},
+		}
+		if len(series.Points) != len(want) {
+			t.Fatalf("points = %+v, want %d weeks", series.Points, len(want))
+		}
+		for i, w := range want {
+			p := series.Points[i]
+			if p.Date != w.week || !approx(p.Value, w.avg) || p.Min == nil || !approx(*p.Min, w.min) ||
+				p.Max == nil || !approx(*p.Max, w.max) || p.Count == nil || *p.Count != w.count {
+				t.Errorf("points[%d] = %+v, want %+v", i, p, w)
+			}
+		}
+	})
+
+	t.Run("body weights compatibility", func(t *testing.T) {
+		do("POST /body-weights", "/body-weights", dto.RecordBodyWeightRequest{Date: "2025-05-07", WeightKg: 1000}, http.StatusBadRequest)
+
+		// 同じ日の記録は最新の記録を上書きし、ない日は 0:00 JST で記録する
+		var recorded dto.BodyWeightView
+		decodeJSON(t, do("POST /body-weights", "/body-weights", dto.RecordBodyWeightRequest{Date: "2025-05-07", WeightKg: 73}, http.StatusCreated), &recorded)
+		if recorded != (dto.BodyWeightView{Date: "2025-05-07", WeightKg: 73}) {
+			t.Errorf("POST /body-weights = %+v", recorded)
+		}
+		do("POST /body-weights", "/body-weights", dto.RecordBodyWeightRequest{Date: "2025-05-09", WeightKg: 68}, http.StatusCreated)
+
+		var bodyWeights []dto.BodyWeightView
+		decodeJSON(t, do("GET /body-weights", "/body-weights?from=2025-05-05&to=2025-05-13", nil, http.StatusOK), &bodyWeights)
+		want := []dto.BodyWeightView{
+			{Date: "2025-05-13", WeightKg: 70},
+			{Date: "2025-05-12", WeightKg: 69},
+			{Date: "2025-05-09", WeightKg: 68},
+			{Date: "2025-05-07", WeightKg: 73},
+			{Date: "2025-05-05", WeightKg: 70},
+		}
+		if len(bodyWeights) != len(want) {
+			t.Fatalf("GET /body-weights = %+v, want %+v", bodyWeights, want)
+		}
+		for i := range want {
+			if bodyWeights[i] != want[i] {
+				t.Errorf("GET /body-weights[%d] = %+v, want %+v", i, bodyWeights[i], want[i])
+			}
+		}
+
+		var measurements []dto.MeasurementView
+		decodeJSON(t, do("GET /measurements", "/measurements?metric=body_weight&from=2025-05-09&to=2025-05-09", nil, http.StatusOK), &measurements)
+		if len(measurements) != 1 {
+			t.Fatalf("GET /measurements = %+v, want the record of 2025-05-09", measurements)
+		}
+		measuredAt, err := time.Parse(time.RFC3339, measurements[0].MeasuredAt)
+		if err != nil || !measuredAt.Equal(time.Date(2025, 5, 9, 0, 0, 0, 0, time.FixedZone("JST", 9*60*60))) {
+			t.Errorf("measured_at = %s, want 2025-05-09T00:00:00+09:00", measurements[0].MeasuredAt)
+		}
+
+		// 日付の削除はその日の記録をすべて削除する
+		do("DELETE /body-weights/{date}", "/body-weights/2025-05-07", nil, http.StatusNoContent)
+		do("DELETE /body-weights/{date}", "/body-weights/2025-05-07", nil, http.StatusNotFound)
+		do("DELETE /body-weights/{date}", "/body-weights/20250507", nil, http.StatusBadRequest)
+		decodeJSON(t, do("GET /measurements", "/measurements?metric=body_weight&from=2025-05-07&to=2025-05-07", nil, http.StatusOK), &measurements)
+		if len(measurements) != 0 {
+			t.Errorf("GET /measurements after DELETE /body-weights = %+v, want none", measurements)
+		}
+	})
+}
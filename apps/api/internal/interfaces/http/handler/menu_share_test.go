@@ -0,0 +1,152 @@
+package handler
+
+import (
+	"context"
+	"net/http"
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/aiirononeko/bulktrack/apps/api/internal/interfaces/http/dto"
+	"github.com/google/uuid"
+)
+
+// 共有コードから複製したメニューと種目は複製したユーザーのものになり、共有元のメニューには触れられない
+func TestCloneSharedMenuOwnership(t *testing.T) {
+	ctx := context.Background()
+	env := newDBTestServer(t, true)
+	pool := env.pool
+
+	const (
+		ownerID = "user_menu_share_owner"
+		cloneID = "user_menu_share_cloner"
+	)
+	asOwner := env.as(t, ownerID)
+	asCloner := env.as(t, cloneID)
+
+	// exerciseOwner はメニュー項目の種目を作成したユーザーを返す (組み込み種目は空文字列)
+	exerciseOwner := func(exerciseID uuid.UUID) string {
+		t.Helper()
+		var owner *string
+		if err := pool.QueryRow(ctx, "SELECT created_by_user_id FROM exercises WHERE id = $1", exerciseID).Scan(&owner); err != nil {
+			t.Fatalf("failed to read exercise: %v", err)
+		}
+		if owner == nil {
+			return ""
+		}
+		return *owner
+	}
+
+	// 共有元: 組み込み種目と共有元のユーザーのカスタム種目のメニュー
+	var builtinID, customID uuid.UUID
+	if err := pool.QueryRow(ctx, "SELECT id FROM exercises WHERE created_by_user_id IS NULL ORDER BY name LIMIT 1").Scan(&builtinID); err != nil {
+		t.Fatalf("failed to find a built-in exercise: %v", err)
+	}
+	if err := pool.QueryRow(ctx, "INSERT INTO exercises (name, is_custom, created_by_user_id, metric_type) VALUES ('Owner custom', TRUE, $1, 'reps') RETURNING id", ownerID).Scan(&customID); err != nil {
+		t.Fatalf("failed to insert custom exercise: %v", err)
+	}
+	var original dto.MenuResponse
+	decodeJSON(t, asOwner("POST /menus", "/menus", dto.CreateMenuRequest{
+		Name: "Shared",
+		Items: []dto.MenuItemInput{
+			{ExerciseID: builtinID, SetOrder: 1},
+			{ExerciseID: customID, SetOrder: 2},
+		},
+	}, http.StatusCreated), &original)
+	menuPath := "/menus/" + original.ID.String()
+
+	// 共有・共有の取り消しは所有者のみ
+	asCloner("POST /menus/{id}/share", menuPath+"/share", nil, http.StatusNotFound)
+	var share dto.MenuShareView
+	decodeJSON(t, asOwner("POST /menus/{id}/share", menuPath+"/share", nil, http.StatusCreated), &share)
+	var again dto.MenuShareView
+	decodeJSON(t, asOwner("POST /menus/{id}/share", menuPath+"/share", nil, http.StatusOK), &again)
+	if again.ShareCode != share.ShareCode {
+		t.Errorf("sharing again returned %s, want the same share code %s", again.ShareCode, share.ShareCode)
+	}
+	asCloner("DELETE /menus/{id}/share", menuPath+"/share", nil, http.StatusNotFound)
+	sharedPath := "/shared-menus/" + strings.ToLower(share.ShareCode) // 共有コードは大文字小文字を区別しない
+
+	var preview dto.SharedMenuView
+	decodeJSON(t, asCloner("GET /shared-menus/{code}", sharedPath, nil, http.StatusOK), &preview)
+	if len(preview.Items) != 2 || preview.Items[0].IsCustomExercise || !preview.Items[1].IsCustomExercise {
+		t.Errorf("GET /shared-menus/{code} items = %+v, want a built-in and a custom exercise", preview.Items)
+	}
+
+	// 複製すると、組み込み種目はそのまま使い、他のユーザーのカスタム種目は複製したユーザーのカスタム種目として作成する
+	var first dto.CloneMenuResponse
+	decodeJSON(t, asCloner("POST /shared-menus/{code}/clone", sharedPath+"/clone", nil, http.StatusCreated), &first)
+	if first.Menu.Name != "Shared" || !reflect.DeepEqual(first.CreatedExercises, []string{"Owner custom"}) {
+		t.Errorf("first clone = %q, created %v, want Shared creating [Owner custom]", first.Menu.Name, first.CreatedExercises)
+	}
+	if len(first.Menu.Items) != 2 {
+		t.Fatalf("first clone items = %+v, want 2", first.Menu.Items)
+	}
+	if first.Menu.Items[0].ExerciseID != builtinID {
+		t.Errorf("first clone built-in exercise = %s, want %s", first.Menu.Items[0].ExerciseID, builtinID)
+	}
+	clonedCustomID := first.Menu.Items[1].ExerciseID
+	if clonedCustomID == customID || exerciseOwner(clonedCustomID) != cloneID {
+		t.Errorf("first clone custom exercise %s is owned by %q, want a new exercise of %s", clonedCustomID, exerciseOwner(clonedCustomID), cloneID)
+	}
+	var metricType string
+	if err := pool.QueryRow(ctx, "SELECT metric_type FROM exercises WHERE id = $1", clonedCustomID).Scan(&metricType); err != nil || metricType != "reps" {
+		t.Errorf("cloned custom exercise metric_type = %q, %v, want reps", metricType, err)
+	}
+
+	// 2回目は同じ名前のメニューに番号を付け、1回目に作成したカスタム種目を使う
+	var second dto.CloneMenuResponse
+	decodeJSON(t, asCloner("POST /shared-menus/{code}/clone", sharedPath+"/clone", nil, http.StatusCreated), &second)
+	if second.Menu.Name != "Shared (2)" || len(second.CreatedExercises) != 0 || second.Menu.Items[1].ExerciseID != clonedCustomID {
+		t.Errorf("second clone = %q, created %v, custom %s, want Shared (2) reusing %s", second.Menu.Name, second.CreatedExercises, second.Menu.Items[1].ExerciseID, clonedCustomID)
+	}
+
+	// 複製したメニューは複製したユーザーのもので、共有元のユーザーからは見えない
+	for _, menuID := range []uuid.UUID{first.Menu.ID, second.Menu.ID} {
+		var owner string
+		if err := pool.QueryRow(ctx, "SELECT user_id FROM menus WHERE id = $1", menuID).Scan(&owner); err != nil || owner != cloneID {
+			t.Errorf("cloned menu %s is owned by %q, %v, want %s", menuID, owner, err, cloneID)
+		}
+		asOwner("GET /menus/{id}", "/menus/"+menuID.String(), nil, http.StatusNotFound)
+		asCloner("GET /menus/{id}", "/menus/"+menuID.String(), nil, http.StatusOK)
+	}
+	// 共有元のメニューは複製したユーザーからは変更・削除できない
+	asCloner("GET /menus/{id}", menuPath, nil, http.StatusNotFound)
+	asCloner("DELETE /menus/{id}", menuPath, nil, http.StatusNotFound)
+
+	// 複製したメニューを共有元のユーザーが複製し直すと、自分の同じ名前のカスタム種目を使う
+	var reshare dto.MenuShareView
+	decodeJSON(t, asCloner("POST /menus/{id}/share", "/menus/"+first.Menu.ID.String()+"/share", nil, http.StatusCreated), &reshare)
+	var third dto.CloneMenuResponse
+	decodeJSON(t, asOwner("POST /shared-menus/{code}/clone", "/shared-menus/"+reshare.ShareCode+"/clone", dto.CloneMenuRequest{Name: "Back"}, http.StatusCreated), &third)
+	if third.Menu.Name != "Back" || len(third.CreatedExercises) != 0 || third.Menu.Items[1].ExerciseID != customID {
+		t.Errorf("clone back = %q, created %v, custom %s, want Back reusing %s", third.Menu.Name, third.CreatedExercises, third.Menu.Items[1].ExerciseID, customID)
+	}
+
+	// 共有をやめると共有コードは使えなくなり、複製済みのメニューは残る
+	asOwner("DELETE /menus/{id}/share", menuPath+"/share", nil, http.StatusNoContent)
+	asCloner("GET /shared-menus/{code}", sharedPath, nil, http.StatusNotFound)
+	asCloner("POST /shared-menus/{code}/clone", sharedPath+"/clone", nil, http.StatusNotFound)
+	asCloner("GET /menus/{id}", "/menus/"+first.Menu.ID.String(), nil, http.StatusOK)
+	var cloneCount int
+	if err := pool.QueryRow(ctx, "SELECT clone_count FROM menu_shares WHERE share_code = $1", share.ShareCode).Scan(&cloneCount); err != nil || cloneCount != 2 {
+		t.Errorf("clone_count = %d, %v, want 2", cloneCount, err)
+	}
+
+	// テンプレートは組み込み種目のみで、複製しても種目を作成しない
+	var templates []dto.MenuTemplateView
+	decodeJSON(t, asCloner("GET /menu-templates", "/menu-templates", nil, http.StatusOK), &templates)
+	if len(templates) == 0 {
+		t.Fatal("GET /menu-templates returned no templates")
+	}
+	var fromTemplate dto.CloneMenuResponse
+	decodeJSON(t, asCloner("POST /shared-menus/{code}/clone", "/shared-menus/"+templates[0].ShareCode+"/clone", nil, http.StatusCreated), &fromTemplate)
+	if len(fromTemplate.CreatedExercises) != 0 || len(fromTemplate.Menu.Items) != int(templates[0].ItemCount) {
+		t.Errorf("template clone created %v with %d items, want no exercises and %d items", fromTemplate.CreatedExercises, len(fromTemplate.Menu.Items), templates[0].ItemCount)
+	}
+	for _, item := range fromTemplate.Menu.Items {
+		if owner := exerciseOwner(item.ExerciseID); owner != "" {
+			t.Errorf("template clone item %s uses an exercise of %q", item.ExerciseName, owner)
+		}
+	}
+}
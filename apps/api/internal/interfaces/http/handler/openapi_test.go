@@ -0,0 +1,448 @@
+package handler
+
+import (
+	"bytes"
+	"context"
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/json"
+	"io"
+	"log/slog"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/aiirononeko/bulktrack/apps/api/internal/config"
+	"github.com/aiirononeko/bulktrack/apps/api/internal/di"
+	"github.com/aiirononeko/bulktrack/apps/api/internal/interfaces/http/dto"
+	"github.com/aiirononeko/bulktrack/apps/api/internal/interfaces/http/spec"
+	"github.com/aiirononeko/bulktrack/apps/api/internal/interfaces/service"
+	"github.com/aiirononeko/bulktrack/apps/api/internal/openapi"
+	"github.com/aiirononeko/bulktrack/apps/api/internal/testdb"
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5/pgxpool"
+)
+
+// unreachableDatabaseURL は接続できないデータベース (pgxpool は最初のクエリまで接続しないため、DB を使わないリクエストのテストに使う)
+const unreachableDatabaseURL = "postgres://bulktrack@127.0.0.1:1/bulktrack?sslmode=disable&connect_timeout=1"
+
+// newTestServer はテスト用のサーバーを作成する (configure で設定を変更できる)
+func newTestServer(t *testing.T, pool *pgxpool.Pool, configure func(*config.Config)) *Server {
+	t.Helper()
+	if pool == nil {
+		var err error
+		pool, err = pgxpool.New(context.Background(), unreachableDatabaseURL)
+		if err != nil {
+			t.Fatalf("pgxpool.New() error = %v", err)
+		}
+		t.Cleanup(pool.Close)
+	}
+	cfg := &config.Config{
+		RateLimitStore: "memory",
+		RateLimitIP:    "1000/1m",
+		RateLimitUser:  "1000/1m",
+		RateLimitHeavy: "1000/1m",
+	}
+	if configure != nil {
+		configure(cfg)
+	}
+	logger := slog.New(slog.NewTextHandler(io.Discard, nil))
+	return NewServer(di.NewContainer(cfg, pool, logger))
+}
+
+// serve はリクエストを処理し、レスポンスがドキュメントのパターンの操作に従っているかを確認する
+func serve(t *testing.T, s *Server, pattern string, req *http.Request) *httptest.ResponseRecorder {
+	t.Helper()
+	rec := httptest.NewRecorder()
+	s.ServeHTTP(rec, req)
+	if err := spec.Document().ValidateResponse(pattern, rec.Code, rec.Header(), rec.Body.Bytes()); err != nil {
+		t.Errorf("%s %s: response does not match the OpenAPI document: %v\nbody: %s", req.Method, req.URL, err, rec.Body.Bytes())
+	}
+	return rec
+}
+
+// examplePath はパターンのパスのパラメーターを、ドキュメントのスキーマに合う値に置き換えたパスを返す
+func examplePath(pattern string, op *openapi.Operation) string {
+	_, path, _ := strings.Cut(pattern, " ")
+	for _, p := range op.Parameters {
+		if p.In != "path" {
+			continue
+		}
+		value := "example"
+		switch p.Schema.Format {
+		case "uuid":
+			value = uuid.NewString()
+		case "date-time":
+			value = "2025-05-12T00:00:00Z"
+		}
+		path = strings.ReplaceAll(path, "{"+p.Name+"}", value)
+	}
+	return path
+}
+
+func TestOpenAPIDocumentIsValid(t *testing.T) {
+	if err := spec.Document().Check(); err != nil {
+		t.Fatalf("Check() error = %v", err)
+	}
+}
+
+// TestRoutesMatchOpenAPIDocument はサーバーに登録したルートとドキュメントの操作が一致することを確認する
+func TestRoutesMatchOpenAPIDocument(t *testing.T) {
+	s := newTestServer(t, nil, nil)
+	registered := slices.Sorted(slices.Values(s.routes.patterns))
+	documented := spec.Document().Patterns()
+
+	for _, pattern := range registered {
+		if !slices.Contains(documented, pattern) {
+			t.Errorf("%s is registered but not documented", pattern)
+		}
+	}
+	for _, pattern := range documented {
+		if !slices.Contains(registered, pattern) {
+			t.Errorf("%s is documented but not registered", pattern)
+		}
+	}
+}
+
+// TestUnauthenticatedResponsesMatchOpenAPIDocument は認証が必要な操作が認証なしのリクエストに 401 を返し、ドキュメントに従うことを確認する
+func TestUnauthenticatedResponsesMatchOpenAPIDocument(t *testing.T) {
+	s := newTestServer(t, nil, nil)
+	d := spec.Document()
+	for _, pattern := range d.Patterns() {
+		op := d.Operation(pattern)
+		if len(op.Security) == 0 {
+			continue
+		}
+		method, _, _ := strings.Cut(pattern, " ")
+		for _, authorization := range []string{"", "Basic dXNlcjpwYXNz"} {
+			req := httptest.NewRequest(method, examplePath(pattern, op), nil)
+			if authorization != "" {
+				req.Header.Set("Authorization", authorization)
+			}
+			if rec := serve(t, s, pattern, req); rec.Code != http.StatusUnauthorized {
+				t.Errorf("%s with Authorization %q: status = %d, want %d", pattern, authorization, rec.Code, http.StatusUnauthorized)
+			}
+		}
+	}
+}
+
+// TestRateLimitResponseMatchesOpenAPIDocument はレート制限の 429 がドキュメントに従うことを確認する
+func TestRateLimitResponseMatchesOpenAPIDocument(t *testing.T) {
+	s := newTestServer(t, nil, func(cfg *config.Config) { cfg.RateLimitIP = "1/1m" })
+	serve(t, s, "GET /menus", httptest.NewRequest(http.MethodGet, "/menus", nil))
+	rec := serve(t, s, "GET /menus", httptest.NewRequest(http.MethodGet, "/menus", nil))
+	if rec.Code != http.StatusTooManyRequests {
+		t.Fatalf("status = %d, want %d", rec.Code, http.StatusTooManyRequests)
+	}
+	if rec.Header().Get("Retry-After") == "" {
+		t.Error("Retry-After is not set")
+	}
+}
+
+// TestPublicRoutesAreRateLimitedByIP は認証なしのルートもヘルスチェックを除いて IP アドレスごとにレート制限することを確認する
+func TestPublicRoutesAreRateLimitedByIP(t *testing.T) {
+	tests := []struct {
+		pattern     string
+		newRequest  func() *http.Request
+		wantLimited bool
+	}{
+		{
+			pattern:     "GET /openapi.json",
+			newRequest:  func() *http.Request { return httptest.NewRequest(http.MethodGet, "/openapi.json", nil) },
+			wantLimited: true,
+		},
+		{
+			pattern: "POST /webhooks/clerk",
+			newRequest: func() *http.Request {
+				return httptest.NewRequest(http.MethodPost, "/webhooks/clerk", strings.NewReader(`{}`))
+			},
+			wantLimited: true,
+		},
+		{
+			pattern:     "GET /health",
+			newRequest:  func() *http.Request { return httptest.NewRequest(http.MethodGet, "/health", nil) },
+			wantLimited: false,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.pattern, func(t *testing.T) {
+			s := newTestServer(t, nil, func(cfg *config.Config) { cfg.RateLimitIP = "1/1m" })
+			first := serve(t, s, tt.pattern, tt.newRequest())
+			if first.Code == http.StatusTooManyRequests {
+				t.Fatalf("first request: status = %d, want the request to be allowed", first.Code)
+			}
+			rec := serve(t, s, tt.pattern, tt.newRequest())
+			if limited := rec.Code == http.StatusTooManyRequests; limited != tt.wantLimited {
+				t.Errorf("second request: status = %d, rate limited = %v, want %v", rec.Code, limited, tt.wantLimited)
+			}
+			if tt.wantLimited && rec.Header().Get("Retry-After") == "" {
+				t.Error("Retry-After is not set")
+			}
+		})
+	}
+}
+
+// TestPublicResponsesMatchOpenAPIDocument は認証なしの操作 (DB・Webhook のシークレットがない場合のエラーを含む) がドキュメントに従うことを確認する
+func TestPublicResponsesMatchOpenAPIDocument(t *testing.T) {
+	s := newTestServer(t, nil, nil)
+
+	if rec := serve(t, s, "GET /health", httptest.NewRequest(http.MethodGet, "/health", nil)); rec.Code != http.StatusServiceUnavailable {
+		t.Errorf("GET /health: status = %d, want %d", rec.Code, http.StatusServiceUnavailable)
+	}
+	webhook := httptest.NewRequest(http.MethodPost, "/webhooks/clerk", strings.NewReader(`{}`))
+	if rec := serve(t, s, "POST /webhooks/clerk", webhook); rec.Code != http.StatusServiceUnavailable {
+		t.Errorf("POST /webhooks/clerk: status = %d, want %d", rec.Code, http.StatusServiceUnavailable)
+	}
+
+	rec := serve(t, s, "GET /openapi.json", httptest.NewRequest(http.MethodGet, "/openapi.json", nil))
+	if rec.Code != http.StatusOK {
+		t.Fatalf("GET /openapi.json: status = %d, want %d", rec.Code, http.StatusOK)
+	}
+	var served openapi.Document
+	if err := json.Unmarshal(rec.Body.Bytes(), &served); err != nil {
+		t.Fatalf("failed to decode /openapi.json: %v", err)
+	}
+	if served.OpenAPI != openapi.Version {
+		t.Errorf("openapi = %q, want %q", served.OpenAPI, openapi.Version)
+	}
+	if got, want := served.Patterns(), spec.Document().Patterns(); !slices.Equal(got, want) {
+		t.Errorf("served patterns = %v, want %v", got, want)
+	}
+	for _, name := range []string{spec.SecurityClerk, spec.SecurityPersonalAccessToken} {
+		if served.Components.SecuritySchemes[name] == nil {
+			t.Errorf("security scheme %s is not served", name)
+		}
+	}
+	if served.Components.Schemas["ErrorResponse"] == nil {
+		t.Error("ErrorResponse is not served")
+	}
+
+	revalidate := httptest.NewRequest(http.MethodGet, "/openapi.json", nil)
+	revalidate.Header.Set("If-None-Match", rec.Header().Get("ETag"))
+	if rec := serve(t, s, "GET /openapi.json", revalidate); rec.Code != http.StatusNotModified {
+		t.Errorf("GET /openapi.json with If-None-Match: status = %d, want %d", rec.Code, http.StatusNotModified)
+	}
+}
+
+// TestAuthenticatedResponsesMatchOpenAPIDocument は主な操作を実際のデータベースで呼び出し、レスポンス (エラーを含む) がドキュメントに従うことを確認する
+//
+// TEST_DATABASE_URL (データベースを作成できるユーザー) が設定されている場合のみ実行する
+func TestAuthenticatedResponsesMatchOpenAPIDocument(t *testing.T) {
+	do := newDBTestServer(t, true).as(t, "user_contract_test")
+
+	// 種目・メニュー
+	var exercises []dto.Exercise
+	decodeJSON(t, do("GET /exercises", "/exercises", nil, http.StatusOK), &exercises)
+	i := slices.IndexFunc(exercises, func(e dto.Exercise) bool { return e.MetricType == "weight_reps" })
+	if i < 0 {
+		t.Fatal("no weight_reps exercise is seeded")
+	}
+	exerciseID := exercises[i].ID
+	plannedSets := int32(3)
+	menuRequest := dto.CreateMenuRequest{
+		Name:  "Contract test",
+		Items: []dto.MenuItemInput{{ExerciseID: exerciseID, SetOrder: 1, PlannedSets: &plannedSets}},
+	}
+	var menu dto.MenuResponse
+	decodeJSON(t, do("POST /menus", "/menus", menuRequest, http.StatusCreated), &menu)
+	menuPath := "/menus/" + menu.ID.String()
+	do("GET /menus", "/menus", nil, http.StatusOK)
+	do("GET /menus/{id}", menuPath, nil, http.StatusOK)
+	do("PUT /menus/{id}", menuPath, dto.MenuUpdateRequest{Name: "Contract test (updated)", Items: menuRequest.Items}, http.StatusOK)
+	do("GET /menus/{id}", "/menus/"+uuid.NewString(), nil, http.StatusNotFound)
+
+	// ワークアウト・セット
+	firstWeightKg, secondWeightKg := 60.0, 62.5
+	workoutRequest := dto.CreateWorkoutRequest{
+		MenuID: menu.ID,
+		Exercises: []dto.ExerciseWithSets{{
+			ExerciseID: exerciseID.String(),
+			Sets:       []dto.WorkoutSet{{WeightKg: &firstWeightKg, Reps: 10}, {WeightKg: &secondWeightKg, Reps: 8}},
+		}},
+	}
+	var workout dto.WorkoutResponse
+	decodeJSON(t, do("POST /workouts", "/workouts", workoutRequest, http.StatusCreated), &workout)
+	if len(workout.Sets) == 0 {
+		t.Fatal("POST /workouts returned no sets")
+	}
+	do("GET /workouts", "/workouts?limit=10", nil, http.StatusOK)
+	do("GET /workouts", "/workouts?limit=1000", nil, http.StatusBadRequest)
+	do("GET /workouts/{id}", "/workouts/"+workout.ID.String(), nil, http.StatusOK)
+	reps := int32(9)
+	do("PATCH /sets/{id}", "/sets/"+workout.Sets[0].ID.String(), dto.UpdateSetRequest{Reps: &reps}, http.StatusOK)
+	do("GET /menus/{id}/exercises/last-records", menuPath+"/exercises/last-records", nil, http.StatusOK)
+
+	// 履歴・週間ボリューム
+	do("GET /history", "/history?interval=week", nil, http.StatusOK)
+	do("GET /history", "/history?interval=year", nil, http.StatusBadRequest)
+	do("GET /history/calendar", "/history/calendar", nil, http.StatusOK)
+	week := time.Now().UTC().Truncate(24 * time.Hour).Format(time.RFC3339)
+	do("POST /v1/weekly-volume/recalculate", "/v1/weekly-volume/recalculate", dto.RecalculateWeeklyVolumeRequest{Week: week}, http.StatusOK)
+	do("GET /v1/weekly-volume", "/v1/weekly-volume?weeks=4", nil, http.StatusOK)
+	do("GET /v1/weekly-volume/{week}", "/v1/weekly-volume/"+week, nil, http.StatusOK)
+	do("GET /v1/weekly-volume/stats", "/v1/weekly-volume/stats", nil, http.StatusOK)
+
+	// 身体計測
+	do("GET /measurement-metrics", "/measurement-metrics", nil, http.StatusOK)
+	var measurement dto.MeasurementView
+	decodeJSON(t, do("POST /measurements", "/measurements", dto.CreateMeasurementRequest{Metric: "body_weight", Value: 70}, http.StatusCreated), &measurement)
+	measurementPath := "/measurements/" + measurement.ID.String()
+	do("GET /measurements", "/measurements?metric=body_weight", nil, http.StatusOK)
+	do("GET /measurements/series", "/measurements/series?metric=body_weight", nil, http.StatusOK)
+	do("GET /measurements/relative-strength", "/measurements/relative-strength?exercise_id="+exerciseID.String(), nil, http.StatusOK)
+	value := 71.5
+	do("PATCH /measurements/{id}", measurementPath, dto.UpdateMeasurementRequest{Value: &value}, http.StatusOK)
+	do("DELETE /measurements/{id}", measurementPath, nil, http.StatusNoContent)
+
+	// メニューの共有
+	var share dto.MenuShareView
+	decodeJSON(t, do("POST /menus/{id}/share", menuPath+"/share", nil, http.StatusCreated), &share)
+	do("POST /menus/{id}/share", menuPath+"/share", nil, http.StatusOK)
+	do("GET /shared-menus/{code}", "/shared-menus/"+share.ShareCode, nil, http.StatusOK)
+	do("POST /shared-menus/{code}/clone", "/shared-menus/"+share.ShareCode+"/clone", nil, http.StatusCreated)
+	do("GET /menu-templates", "/menu-templates", nil, http.StatusOK)
+	do("DELETE /menus/{id}/share", menuPath+"/share", nil, http.StatusNoContent)
+
+	// パーソナルアクセストークン・コーチ
+	var created dto.CreatedPersonalAccessToken
+	decodeJSON(t, do("POST /me/tokens", "/me/tokens", dto.CreatePersonalAccessTokenRequest{Name: "contract", Scopes: []string{"read:workouts"}}, http.StatusCreated), &created)
+	do("GET /me/tokens", "/me/tokens", nil, http.StatusOK)
+	do("DELETE /me/tokens/{id}", "/me/tokens/"+created.ID.String(), nil, http.StatusNoContent)
+	var grant dto.CoachGrantView
+	decodeJSON(t, do("POST /coach-grants", "/coach-grants", dto.CreateCoachGrantRequest{Permission: "read"}, http.StatusCreated), &grant)
+	do("GET /coach-grants", "/coach-grants", nil, http.StatusOK)
+	do("GET /coach-grants/{id}/audit-logs", "/coach-grants/"+grant.ID.String()+"/audit-logs", nil, http.StatusOK)
+	do("DELETE /coach-grants/{id}", "/coach-grants/"+grant.ID.String(), nil, http.StatusNoContent)
+
+	// エクスポート・アカウント削除
+	do("GET /me/export", "/me/export?format=json", nil, http.StatusOK)
+	do("GET /me/export", "/me/export?format=csv&dataset=sets", nil, http.StatusOK)
+	var job dto.ExportJobView
+	decodeJSON(t, do("POST /me/exports", "/me/exports", nil, http.StatusAccepted), &job)
+	do("GET /me/exports/{id}", "/me/exports/"+job.ID.String(), nil, http.StatusOK)
+	do("GET /me/deletion", "/me/deletion", nil, http.StatusNotFound)
+	do("DELETE /me", "/me", nil, http.StatusAccepted)
+	do("GET /me/deletion", "/me/deletion", nil, http.StatusOK)
+	do("POST /me/deletion/cancel", "/me/deletion/cancel", nil, http.StatusOK)
+
+	do("DELETE /menus/{id}", menuPath, nil, http.StatusNoContent)
+}
+
+// testEnv はマイグレーションを適用したテスト用のデータベースと、そのデータベースを使うサーバー
+type testEnv struct {
+	pool   *pgxpool.Pool
+	server *Server
+	key    testSigningKey
+}
+
+// newDBTestServer はテスト用のデータベースとサーバーを作成する (seed が true の場合は種目のマスターデータを投入する)
+// TEST_DATABASE_URL が設定されていない場合はテストをスキップする
+func newDBTestServer(t *testing.T, seed bool) *testEnv {
+	t.Helper()
+	pool := testdb.New(t, "handler")
+	if seed {
+		logger := slog.New(slog.NewTextHandler(io.Discard, nil))
+		if _, err := service.NewAdminService(pool, logger).Seed(context.Background(), false); err != nil {
+			t.Fatalf("Seed() error = %v", err)
+		}
+	}
+	key, jwksFile := newTestJWKS(t)
+	return &testEnv{
+		pool:   pool,
+		server: newTestServer(t, pool, func(cfg *config.Config) { cfg.JWKSFile = jwksFile }),
+		key:    key,
+	}
+}
+
+// doFunc は認証したリクエストを処理し、ステータスコードとドキュメントとの一致を確認してボディを返す
+type doFunc func(pattern, path string, body any, wantStatus int) []byte
+
+// as は userID のトークンで認証してリクエストを処理する doFunc を返す
+func (e *testEnv) as(t *testing.T, userID string) doFunc {
+	t.Helper()
+	token := e.key.sign(t, userID)
+	return func(pattern, path string, body any, wantStatus int) []byte {
+		t.Helper()
+		method, _, _ := strings.Cut(pattern, " ")
+		var reader io.Reader
+		if body != nil {
+			data, err := json.Marshal(body)
+			if err != nil {
+				t.Fatalf("failed to marshal request body: %v", err)
+			}
+			reader = bytes.NewReader(data)
+		}
+		req := httptest.NewRequest(method, path, reader)
+		req.Header.Set("Authorization", "Bearer "+token)
+		rec := serve(t, e.server, pattern, req)
+		if rec.Code != wantStatus {
+			t.Fatalf("%s %s: status = %d, want %d\nbody: %s", method, path, rec.Code, wantStatus, rec.Body.Bytes())
+		}
+		return rec.Body.Bytes()
+	}
+}
+
+// decodeJSON はレスポンスのボディを v に読み込む
+func decodeJSON(t *testing.T, data []byte, v any) {
+	t.Helper()
+	if err := json.Unmarshal(data, v); err != nil {
+		t.Fatalf("failed to decode response: %v", err)
+	}
+}
+
+// testSigningKey はテスト用のトークンの署名鍵 (ES256)
+type testSigningKey struct {
+	kid     string
+	private *ecdsa.PrivateKey
+}
+
+// newTestJWKS は署名鍵を作成し、公開鍵の JWKS を書き込んだファイル (JWKS_FILE に指定する) を返す
+func newTestJWKS(t *testing.T) (testSigningKey, string) {
+	t.Helper()
+	private, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	if err != nil {
+		t.Fatalf("failed to generate EC key: %v", err)
+	}
+	key := testSigningKey{kid: "contract-test", private: private}
+
+	encode := func(b []byte) string { return base64.RawURLEncoding.EncodeToString(b) }
+	jwks, _ := json.Marshal(map[string][]map[string]string{"keys": {{
+		"kty": "EC", "kid": key.kid, "use": "sig", "alg": "ES256", "crv": "P-256",
+		"x": encode(private.X.FillBytes(make([]byte, 32))), "y": encode(private.Y.FillBytes(make([]byte, 32))),
+	}}})
+	path := filepath.Join(t.TempDir(), "jwks.json")
+	if err := os.WriteFile(path, jwks, 0o600); err != nil {
+		t.Fatalf("failed to write JWKS: %v", err)
+	}
+	return key, path
+}
+
+// sign はユーザーのトークンを作成する (有効期限は1時間)
+func (k testSigningKey) sign(t *testing.T, subject string) string {
+	t.Helper()
+	now := time.Now()
+	header, _ := json.Marshal(map[string]string{"alg": "ES256", "typ": "JWT", "kid": k.kid})
+	payload, _ := json.Marshal(map[string]any{
+		"sub": subject,
+		"sid": "sess_contract_test",
+		"iat": now.Add(-time.Minute).Unix(),
+		"nbf": now.Add(-time.Minute).Unix(),
+		"exp": now.Add(time.Hour).Unix(),
+	})
+	input := base64.RawURLEncoding.EncodeToString(header) + "." + base64.RawURLEncoding.EncodeToString(payload)
+	digest := sha256.Sum256([]byte(input))
+	r, s, err := ecdsa.Sign(rand.Reader, k.private, digest[:])
+	if err != nil {
+		t.Fatalf("failed to sign token: %v", err)
+	}
+	signature := append(r.FillBytes(make([]byte, 32)), s.FillBytes(make([]byte, 32))...)
+	return input + "." + base64.RawURLEncoding.EncodeToString(signature)
+}
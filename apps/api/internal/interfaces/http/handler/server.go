@@ -6,14 +6,19 @@ import (
 	"io"
 	"log/slog"
 	"net/http"
+	"strconv"
 	"strings"
 
 	"github.com/aiirononeko/bulktrack/apps/api/internal/application/query"
 	"github.com/aiirononeko/bulktrack/apps/api/internal/di"
 	"github.com/aiirononeko/bulktrack/apps/api/internal/handler"
+	httpError "github.com/aiirononeko/bulktrack/apps/api/internal/http"
 	"github.com/aiirononeko/bulktrack/apps/api/internal/interfaces/http/dto"
 	"github.com/aiirononeko/bulktrack/apps/api/internal/interfaces/http/middleware"
+	"github.com/aiirononeko/bulktrack/apps/api/internal/interfaces/http/spec"
 	"github.com/aiirononeko/bulktrack/apps/api/internal/interfaces/service"
+	"github.com/aiirononeko/bulktrack/apps/api/internal/ratelimit"
+	"github.com/aiirononeko/bulktrack/apps/api/internal/webhook"
 	"github.com/google/uuid"
 )
 
@@ -26,10 +31,32 @@ type Server struct {
 	volumeService         *service.VolumeService
 	latestSetQueryService query.LatestSetQueryService
 	volumeHandler         *handler.VolumeHandler
+	measurementHandler    *handler.MeasurementHandler
+	historyHandler        *handler.HistoryHandler
+	exportHandler         *handler.ExportHandler
+	importHandler         *handler.ImportHandler
+	accountHandler        *handler.AccountHandler
+	clerkWebhookHandler   *handler.ClerkWebhookHandler
+	tokenHandler          *handler.PersonalAccessTokenHandler
+	coachHandler          *handler.CoachHandler
+	menuShareHandler      *handler.MenuShareHandler
 	mux                   *http.ServeMux
+	routes                *routeRecorder
 	logger                *slog.Logger
 }
 
+// routeRecorder は ServeMux にルートを登録し、登録したパターンを記録する (OpenAPI のドキュメントとの照合に使う)
+type routeRecorder struct {
+	mux      *http.ServeMux
+	patterns []string
+}
+
+// Handle はルートを登録する
+func (r *routeRecorder) Handle(pattern string, handler http.Handler) {
+	r.mux.Handle(pattern, handler)
+	r.patterns = append(r.patterns, pattern)
+}
+
 // NewServer は新しいHTTPサーバーを作成
 func NewServer(container *di.Container) *Server {
 	// サービスの初期化
@@ -38,9 +65,38 @@ func NewServer(container *di.Container) *Server {
 	exerciseService := service.NewExerciseService(container.DB, container.Logger)
 	volumeService := service.NewVolumeService(container.DB, container.Logger)
 	latestSetQueryService := query.NewLatestSetQueryService(container.DB, container.Logger)
+	measurementService := service.NewMeasurementService(container.DB, container.Logger)
+	historyService := service.NewHistoryService(container.DB, container.Logger)
+	exportService := service.NewExportService(container.DB, container.Logger)
+	importService := service.NewImportService(container.DB, container.Logger)
+	accountDeletionService := service.NewAccountDeletionService(container.DB, container.Logger)
+	clerkWebhookService := service.NewClerkWebhookService(container.DB, accountDeletionService, container.Logger)
+	tokenService := service.NewPersonalAccessTokenService(container.DB, container.Logger)
+	coachService := service.NewCoachService(container.DB, container.Logger)
+	menuShareService := service.NewMenuShareService(container.DB, container.Logger)
+
+	// Clerk の Webhook の署名検証 (シークレット未設定の場合 Webhook は 503 を返す)
+	var clerkWebhookVerifier *webhook.SvixVerifier
+	if container.Config.ClerkWebhookSecret != "" {
+		verifier, err := webhook.NewSvixVerifier(container.Config.ClerkWebhookSecret)
+		if err != nil {
+			container.Logger.Error("Invalid CLERK_WEBHOOK_SECRET", slog.Any("error", err))
+		} else {
+			clerkWebhookVerifier = verifier
+		}
+	}
 
 	// ハンドラーの初期化
 	volumeHandler := handler.NewVolumeHandler(volumeService, container.Logger)
+	measurementHandler := handler.NewMeasurementHandler(measurementService, container.Logger)
+	historyHandler := handler.NewHistoryHandler(historyService, container.Logger)
+	exportHandler := handler.NewExportHandler(exportService, container.Logger)
+	importHandler := handler.NewImportHandler(importService, container.Logger)
+	accountHandler := handler.NewAccountHandler(accountDeletionService, container.Logger)
+	clerkWebhookHandler := handler.NewClerkWebhookHandler(clerkWebhookVerifier, clerkWebhookService, container.Logger)
+	tokenHandler := handler.NewPersonalAccessTokenHandler(tokenService, container.Logger)
+	coachHandler := handler.NewCoachHandler(coachService, container.Logger)
+	menuShareHandler := handler.NewMenuShareHandler(menuShareService, menuService, container.Logger)
 
 	s := &Server{
 		container:             container,
@@ -50,42 +106,114 @@ func NewServer(container *di.Container) *Server {
 		volumeService:         volumeService,
 		latestSetQueryService: latestSetQueryService,
 		volumeHandler:         volumeHandler,
+		measurementHandler:    measurementHandler,
+		historyHandler:        historyHandler,
+		exportHandler:         exportHandler,
+		importHandler:         importHandler,
+		accountHandler:        accountHandler,
+		clerkWebhookHandler:   clerkWebhookHandler,
+		tokenHandler:          tokenHandler,
+		coachHandler:          coachHandler,
+		menuShareHandler:      menuShareHandler,
 		mux:                   http.NewServeMux(),
 		logger:                container.Logger,
 	}
+	s.routes = &routeRecorder{mux: s.mux}
 
 	// ミドルウェアの作成
-	logging := middleware.LoggingMiddleware(s.logger)
-	auth := middleware.ClerkAuth(container.Config, s.logger)
+	// リクエストごとのスパンとメトリクス (ログにトレースIDが付くよう、ログの外側で開始する)
+	// リクエストIDはスパンの中で付け、アクセスログを含むリクエスト内のログに付ける
+	logRequests := middleware.LoggingMiddleware(s.logger)
+	telemetry := middleware.Telemetry()
+	requestID := middleware.RequestID()
+	logging := func(next http.Handler) http.Handler {
+		return telemetry(requestID(logRequests(next)))
+	}
+	// 認証の前にIPアドレスごと、認証の後にユーザーごと (コーチの場合はコーチ自身) にレート制限する
+	// 認証の後、X-Athlete-ID がある場合はコーチのアクセス権を確認してアスリートのユーザーIDに置き換える
+	rateLimiter := middleware.NewRateLimiter(container.Config, newRateLimitStore(container), s.logger)
+	authenticate := middleware.ClerkAuth(container.Config, s.logger, tokenService)
+	coachAccess := middleware.CoachAccess(coachService, s.logger)
+	auth := func(next http.Handler) http.Handler {
+		return rateLimiter.ByIP(authenticate(rateLimiter.ByUser(coachAccess(next))))
+	}
+	// 認証なしのルートも IP アドレスごとにレート制限する (ヘルスチェックはロードバランサーの確認を止めないよう制限しない)
+	public := rateLimiter.ByIP
 
 	// ルートの登録
-	s.mux.Handle("GET /health", logging(http.HandlerFunc(s.handleHealth)))
+	s.routes.Handle("GET /health", logging(http.HandlerFunc(s.handleHealth)))
+
+	// API のドキュメント (OpenAPI 3.1) - 認証なし
+	s.routes.Handle("GET /openapi.json", logging(public(http.HandlerFunc(s.handleOpenAPI))))
 
 	// トレーニングメニュー - 認証必須
-	s.mux.Handle("GET /menus", logging(auth(http.HandlerFunc(s.handleListMenus))))
-	s.mux.Handle("POST /menus", logging(auth(http.HandlerFunc(s.handleCreateMenu))))
-	s.mux.Handle("GET /menus/{id}", logging(auth(http.HandlerFunc(s.handleGetMenu))))
-	s.mux.Handle("DELETE /menus/{id}", logging(auth(http.HandlerFunc(s.handleDeleteMenu))))
-	s.mux.Handle("PUT /menus/{id}", logging(auth(http.HandlerFunc(s.handleUpdateMenu))))
-	s.mux.Handle("GET /menus/{id}/exercises/last-records", logging(auth(http.HandlerFunc(s.handleGetLastRecords))))
+	s.routes.Handle("GET /menus", logging(auth(http.HandlerFunc(s.handleListMenus))))
+	s.routes.Handle("POST /menus", logging(auth(http.HandlerFunc(s.handleCreateMenu))))
+	s.routes.Handle("GET /menus/{id}", logging(auth(http.HandlerFunc(s.handleGetMenu))))
+	s.routes.Handle("DELETE /menus/{id}", logging(auth(http.HandlerFunc(s.handleDeleteMenu))))
+	s.routes.Handle("PUT /menus/{id}", logging(auth(http.HandlerFunc(s.handleUpdateMenu))))
+	s.routes.Handle("GET /menus/{id}/exercises/last-records", logging(auth(http.HandlerFunc(s.handleGetLastRecords))))
 
 	// ワークアウト - 認証必須
-	s.mux.Handle("GET /workouts", logging(auth(http.HandlerFunc(s.handleListWorkouts))))
-	s.mux.Handle("POST /workouts", logging(auth(http.HandlerFunc(s.handleStartWorkout))))
-	s.mux.Handle("GET /workouts/{id}", logging(auth(http.HandlerFunc(s.handleGetWorkout))))
+	s.routes.Handle("GET /workouts", logging(auth(http.HandlerFunc(s.handleListWorkouts))))
+	s.routes.Handle("POST /workouts", logging(auth(http.HandlerFunc(s.handleStartWorkout))))
+	s.routes.Handle("GET /workouts/{id}", logging(auth(http.HandlerFunc(s.handleGetWorkout))))
 
 	// セット - 認証必須
-	s.mux.Handle("PATCH /sets/{id}", logging(auth(http.HandlerFunc(s.handleUpdateSet))))
+	s.routes.Handle("PATCH /sets/{id}", logging(auth(http.HandlerFunc(s.handleUpdateSet))))
 
 	// 種目 - 認証必須
-	s.mux.Handle("GET /exercises", logging(auth(http.HandlerFunc(s.handleListExercises))))
+	s.routes.Handle("GET /exercises", logging(auth(http.HandlerFunc(s.handleListExercises))))
 
 	// 週間ボリューム関連のルート登録
-	s.volumeHandler.RegisterRoutes(s.mux, logging, auth)
+	s.volumeHandler.RegisterRoutes(s.routes, logging, auth)
+
+	// 身体計測関連のルート登録
+	s.measurementHandler.RegisterRoutes(s.routes, logging, auth)
+
+	// トレーニング履歴 (日・週・月ごとの集計、カレンダー) 関連のルート登録
+	s.historyHandler.RegisterRoutes(s.routes, logging, auth)
+
+	// データエクスポート関連のルート登録
+	s.exportHandler.RegisterRoutes(s.routes, logging, auth)
+
+	// 他のアプリ (Strong / Hevy / FitNotes) からのワークアウト履歴の取り込み関連のルート登録
+	s.importHandler.RegisterRoutes(s.routes, logging, auth)
+
+	// アカウント削除 (Right-to-Delete) 関連のルート登録
+	s.accountHandler.RegisterRoutes(s.routes, logging, auth)
+
+	// パーソナルアクセストークン関連のルート登録
+	s.tokenHandler.RegisterRoutes(s.routes, logging, auth)
+
+	// コーチとアスリートの共有関連のルート登録
+	s.coachHandler.RegisterRoutes(s.routes, logging, auth)
+
+	// メニューの共有 (共有コード・テンプレートライブラリ) と複製関連のルート登録
+	s.menuShareHandler.RegisterRoutes(s.routes, logging, auth)
+
+	// Clerk の Webhook - Svix の署名で検証 (JWT 認証なし)
+	s.clerkWebhookHandler.RegisterRoutes(s.routes, logging, public)
 
 	return s
 }
 
+// newRateLimitStore は RATE_LIMIT_STORE の設定に応じたレート制限のストアを返す (off の場合は nil で制限しない)
+func newRateLimitStore(container *di.Container) ratelimit.Store {
+	switch container.Config.RateLimitStore {
+	case "off":
+		container.Logger.Warn("Rate limiting is disabled")
+		return nil
+	case "postgres":
+		return service.NewRateLimitService(container.DB, container.Logger)
+	case "memory":
+		return ratelimit.NewMemoryStore()
+	default:
+		container.Logger.Error("Unknown RATE_LIMIT_STORE, using memory", slog.String("store", container.Config.RateLimitStore))
+		return ratelimit.NewMemoryStore()
+	}
+}
+
 // ServeHTTP はHTTPリクエストを処理
 func (s *Server) ServeHTTP(w http.ResponseWriter, r *http.Request) {
 	s.mux.ServeHTTP(w, r)
@@ -104,6 +232,14 @@ func (s *Server) handleHealth(w http.ResponseWriter, r *http.Request) {
 	json.NewEncoder(w).Encode(map[string]string{"status": "ok"})
 }
 
+// handleOpenAPI は API の OpenAPI のドキュメントを返すハンドラー
+func (s *Server) handleOpenAPI(w http.ResponseWriter, r *http.Request) {
+	if err := httpError.WriteJSONWithETag(w, r, spec.Document(), httpError.CacheControlCatalog); err != nil {
+		s.logger.Error("Failed to write OpenAPI document", slog.Any("error", err))
+		http.Error(w, "Failed to write OpenAPI document", http.StatusInternalServerError)
+	}
+}
+
 // メニュー作成ハンドラー
 func (s *Server) handleCreateMenu(w http.ResponseWriter, r *http.Request) {
 	// コンテキストからユーザーIDを取得
@@ -136,6 +272,11 @@ func (s *Server) handleCreateMenu(w http.ResponseWriter, r *http.Request) {
 	if err != nil {
 		// userID.String() ではなく userIDStr をログに出力
 		s.logger.Error("Failed to create menu", slog.Any("error", err), slog.String("user_id", userIDStr), slog.Any("request", req))
+		// 検証エラーなどのアプリケーションエラーはそのステータスで返す
+		if httpError.IsAppError(err) {
+			httpError.WriteError(w, err)
+			return
+		}
 		http.Error(w, fmt.Sprintf("Failed to create menu: %v", err), http.StatusInternalServerError)
 		return
 	}
@@ -159,6 +300,10 @@ func (s *Server) handleGetMenu(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
+	if !s.authorizeMenu(w, r, menuID) {
+		return
+	}
+
 	// メニュー取得
 	resp, err := s.menuService.GetMenuWithItems(r.Context(), menuID)
 	if err != nil {
@@ -168,8 +313,7 @@ func (s *Server) handleGetMenu(w http.ResponseWriter, r *http.Request) {
 	}
 
 	// レスポンス返却
-	w.Header().Set("Content-Type", "application/json")
-	json.NewEncoder(w).Encode(resp)
+	httpError.WriteJSONWithETag(w, r, resp, httpError.CacheControlRevalidate)
 }
 
 // メニュー削除ハンドラー
@@ -185,6 +329,10 @@ func (s *Server) handleDeleteMenu(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
+	if !s.authorizeMenu(w, r, menuID) {
+		return
+	}
+
 	// メニュー削除
 	if err := s.menuService.DeleteMenu(r.Context(), menuID); err != nil {
 		s.logger.Error("Failed to delete menu", slog.Any("error", err), slog.String("menu_id", menuID.String()))
@@ -231,10 +379,56 @@ func (s *Server) handleListWorkouts(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
-	// ワークアウト一覧取得 (userIDStr を渡す)
-	workouts, err := s.workoutService.ListWorkoutsByUser(r.Context(), userIDStr) // userID ではなく userIDStr を渡す
+	// クエリパラメータからページング・絞り込み条件を取得
+	query := r.URL.Query()
+	opts := service.ListWorkoutsOptions{
+		Limit:  service.DefaultWorkoutPageLimit,
+		Cursor: query.Get("cursor"),
+		From:   query.Get("from"),
+		To:     query.Get("to"),
+		Order:  query.Get("order"),
+	}
+
+	// limit は 1〜100 (ERROR.INVALID_LIMIT)
+	if limitStr := query.Get("limit"); limitStr != "" {
+		limit, err := strconv.Atoi(limitStr)
+		if err != nil {
+			httpError.WriteError(w, httpError.NewInvalidLimitError("Limit must be an integer", err))
+			return
+		}
+		opts.Limit = limit
+	}
+	if result := s.container.Validator.ValidatePagination(r.Context(), opts.Limit); !result.Valid {
+		httpError.WriteError(w, httpError.NewInvalidLimitError("Limit must be between 1 and 100", nil).WithDetails(result.Details))
+		return
+	}
+
+	if menuIDStr := query.Get("menu_id"); menuIDStr != "" {
+		menuID, err := uuid.Parse(menuIDStr)
+		if err != nil {
+			s.logger.Warn("Invalid menu ID", slog.String("menu_id", menuIDStr), slog.Any("error", err))
+			http.Error(w, "Invalid menu ID", http.StatusBadRequest)
+			return
+		}
+		opts.MenuID = &menuID
+	}
+	if exerciseIDStr := query.Get("exercise_id"); exerciseIDStr != "" {
+		exerciseID, err := uuid.Parse(exerciseIDStr)
+		if err != nil {
+			s.logger.Warn("Invalid exercise ID", slog.String("exercise_id", exerciseIDStr), slog.Any("error", err))
+			http.Error(w, "Invalid exercise ID", http.StatusBadRequest)
+			return
+		}
+		opts.ExerciseID = &exerciseID
+	}
+
+	// ワークアウト一覧取得
+	workouts, err := s.workoutService.ListWorkouts(r.Context(), userIDStr, opts)
 	if err != nil {
-		// userID.String() ではなく userIDStr をログに出力
+		if httpError.IsAppError(err) {
+			httpError.WriteError(w, err)
+			return
+		}
 		s.logger.Error("Failed to list workouts by user", slog.Any("error", err), slog.String("user_id", userIDStr))
 		http.Error(w, fmt.Sprintf("Failed to list workouts: %v", err), http.StatusInternalServerError)
 		return
@@ -271,8 +465,8 @@ func (s *Server) handleStartWorkout(w http.ResponseWriter, r *http.Request) {
 	}
 	defer r.Body.Close()
 
-	// デバッグログ：リクエストボディ
-	s.logger.InfoContext(r.Context(), "StartWorkout request body",
+	// デバッグログ：リクエストボディ (body はログ出力時に伏字になる、logging.RedactPolicy)
+	s.logger.DebugContext(r.Context(), "StartWorkout request body",
 		slog.String("user_id", userIDStr),
 		slog.String("body", string(body)))
 
@@ -315,6 +509,11 @@ func (s *Server) handleStartWorkout(w http.ResponseWriter, r *http.Request) {
 			slog.String("user_id", userIDStr),
 			slog.String("menu_id", req.MenuID.String()),
 			slog.Int("exercises_count", exercisesCount))
+		// 検証エラーなどのアプリケーションエラーはそのステータスで返す
+		if httpError.IsAppError(err) {
+			httpError.WriteError(w, err)
+			return
+		}
 		http.Error(w, fmt.Sprintf("Failed to start workout: %v", err), http.StatusInternalServerError)
 		return
 	}
@@ -336,12 +535,14 @@ func (s *Server) handleStartWorkout(w http.ResponseWriter, r *http.Request) {
 	w.Header().Set("Content-Type", "application/json")
 	w.WriteHeader(http.StatusCreated)
 
-	// デバッグ用にJSONを文字列にエンコードし、ログに出力
-	responseJSON, _ := json.MarshalIndent(resp, "", "  ")
-	s.logger.InfoContext(r.Context(), "StartWorkout JSON response",
-		slog.String("user_id", userIDStr),
-		slog.String("workout_id", resp.ID.String()),
-		slog.String("response_json", string(responseJSON)))
+	// デバッグ用にJSONを文字列にエンコードし、ログに出力 (response_json はログ出力時に伏字になる)
+	if s.logger.Enabled(r.Context(), slog.LevelDebug) {
+		responseJSON, _ := json.Marshal(resp)
+		s.logger.DebugContext(r.Context(), "StartWorkout JSON response",
+			slog.String("user_id", userIDStr),
+			slog.String("workout_id", resp.ID.String()),
+			slog.String("response_json", string(responseJSON)))
+	}
 
 	json.NewEncoder(w).Encode(resp)
 }
@@ -359,6 +560,10 @@ func (s *Server) handleGetWorkout(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
+	if !s.authorizeWorkout(w, r, workoutID) {
+		return
+	}
+
 	// ワークアウト取得
 	resp, err := s.workoutService.GetWorkoutWithSets(r.Context(), workoutID)
 	s.logger.Debug("Workout response", slog.Any("response", resp))
@@ -386,6 +591,10 @@ func (s *Server) handleUpdateSet(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
+	if !s.authorizeSet(w, r, setID) {
+		return
+	}
+
 	// リクエストボディの読み取り
 	body, err := io.ReadAll(r.Body)
 	if err != nil {
@@ -408,6 +617,11 @@ func (s *Server) handleUpdateSet(w http.ResponseWriter, r *http.Request) {
 	if err != nil {
 		// エラーレスポンスを改善 (例: どのセットの更新に失敗したか)
 		s.logger.Error("Failed to update set", slog.Any("error", err), slog.String("set_id", setID.String()), slog.Any("request", req))
+		// 検証エラーなどのアプリケーションエラーはそのステータスで返す
+		if httpError.IsAppError(err) {
+			httpError.WriteError(w, err)
+			return
+		}
 		http.Error(w, fmt.Sprintf("Failed to update set (ID: %s): %v", setID, err), http.StatusInternalServerError)
 		return
 	}
@@ -426,8 +640,7 @@ func (s *Server) handleListExercises(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
-	w.Header().Set("Content-Type", "application/json")
-	json.NewEncoder(w).Encode(exercises)
+	httpError.WriteJSONWithETag(w, r, exercises, httpError.CacheControlCatalog)
 }
 
 // 前回のトレーニング記録を取得するエンドポイント
@@ -443,6 +656,10 @@ func (s *Server) handleGetLastRecords(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
+	if !s.authorizeMenu(w, r, menuID) {
+		return
+	}
+
 	// コンテキストからユーザーIDを取得
 	userIDStr, ok := middleware.GetUserIDFromContext(r.Context())
 	if !ok {
@@ -485,6 +702,10 @@ func (s *Server) handleUpdateMenu(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
+	if !s.authorizeMenu(w, r, menuID) {
+		return
+	}
+
 	// リクエストボディの読み取り
 	body, err := io.ReadAll(r.Body)
 	if err != nil {
@@ -512,6 +733,11 @@ func (s *Server) handleUpdateMenu(w http.ResponseWriter, r *http.Request) {
 			slog.Any("error", err),
 			slog.String("menu_id", menuID.String()),
 			slog.String("user_id", userIDStr))
+		// 検証エラーなどのアプリケーションエラーはそのステータスで返す
+		if httpError.IsAppError(err) {
+			httpError.WriteError(w, err)
+			return
+		}
 		http.Error(w, fmt.Sprintf("Failed to update menu: %v", err), http.StatusInternalServerError)
 		return
 	}
@@ -520,3 +746,63 @@ func (s *Server) handleUpdateMenu(w http.ResponseWriter, r *http.Request) {
 	w.Header().Set("Content-Type", "application/json")
 	json.NewEncoder(w).Encode(resp)
 }
+
+// authorizeMenu はメニューがリクエストしたユーザー (コーチのアクセスの場合はアスリート) のものかを確認する
+// そうでない場合はエラーレスポンスを書き込んで false を返す
+func (s *Server) authorizeMenu(w http.ResponseWriter, r *http.Request, menuID uuid.UUID) bool {
+	userIDStr, ok := middleware.GetUserIDFromContext(r.Context())
+	if !ok {
+		s.logger.Error("User ID not found in context")
+		http.Error(w, "Unauthorized", http.StatusUnauthorized)
+		return false
+	}
+	if err := s.menuService.AuthorizeMenu(r.Context(), menuID, userIDStr); err != nil {
+		if httpError.IsAppError(err) {
+			httpError.WriteError(w, err)
+			return false
+		}
+		http.Error(w, fmt.Sprintf("Failed to get menu: %v", err), http.StatusInternalServerError)
+		return false
+	}
+	return true
+}
+
+// authorizeWorkout はワークアウトがリクエストしたユーザー (コーチのアクセスの場合はアスリート) のものかを確認する
+// そうでない場合はエラーレスポンスを書き込んで false を返す
+func (s *Server) authorizeWorkout(w http.ResponseWriter, r *http.Request, workoutID uuid.UUID) bool {
+	userIDStr, ok := middleware.GetUserIDFromContext(r.Context())
+	if !ok {
+		s.logger.Error("User ID not found in context")
+		http.Error(w, "Unauthorized", http.StatusUnauthorized)
+		return false
+	}
+	if err := s.workoutService.AuthorizeWorkout(r.Context(), workoutID, userIDStr); err != nil {
+		if httpError.IsAppError(err) {
+			httpError.WriteError(w, err)
+			return false
+		}
+		http.Error(w, fmt.Sprintf("Failed to get workout: %v", err), http.StatusInternalServerError)
+		return false
+	}
+	return true
+}
+
+// authorizeSet はセットがリクエストしたユーザー (コーチのアクセスの場合はアスリート) のワークアウトのものかを確認する
+// そうでない場合はエラーレスポンスを書き込んで false を返す
+func (s *Server) authorizeSet(w http.ResponseWriter, r *http.Request, setID uuid.UUID) bool {
+	userIDStr, ok := middleware.GetUserIDFromContext(r.Context())
+	if !ok {
+		s.logger.Error("User ID not found in context")
+		http.Error(w, "Unauthorized", http.StatusUnauthorized)
+		return false
+	}
+	if err := s.workoutService.AuthorizeSet(r.Context(), setID, userIDStr); err != nil {
+		if httpError.IsAppError(err) {
+			httpError.WriteError(w, err)
+			return false
+		}
+		http.Error(w, fmt.Sprintf("Failed to get set: %v", err), http.StatusInternalServerError)
+		return false
+	}
+	return true
+}
@@ -0,0 +1,122 @@
+package handler
+
+import (
+	"context"
+	"net/http"
+	"reflect"
+	"slices"
+	"strings"
+	"testing"
+
+	"github.com/aiirononeko/bulktrack/apps/api/internal/interfaces/http/dto"
+	"github.com/aiirononeko/bulktrack/apps/api/internal/interfaces/service"
+)
+
+// セット種別とグループキーの検証と、セット種別の変更による週間ボリュームの集計
+// (ウォームアップのみの週は週間ボリュームの行がなく、メインセットに変更すると行を作成する)
+func TestSetTypesAndGroupsInWeeklyVolumes(t *testing.T) {
+	ctx := context.Background()
+	env := newDBTestServer(t, true)
+	pool := env.pool
+
+	const userID = "user_set_type_test"
+	do := env.as(t, userID)
+
+	// weeklyVolume は集計キューを処理させてから、ユーザーの週間ボリュームのセット数と合計を返す
+	weeklyVolume := func() (setCount int, totalVolume float64) {
+		t.Helper()
+		do("GET /v1/weekly-volume", "/v1/weekly-volume", nil, http.StatusOK)
+		if err := pool.QueryRow(ctx, "SELECT COALESCE(SUM(set_count), 0)::int, COALESCE(SUM(total_volume), 0)::float8 FROM weekly_volumes WHERE user_id = $1", userID).Scan(&setCount, &totalVolume); err != nil {
+			t.Fatalf("failed to read weekly volumes: %v", err)
+		}
+		return setCount, totalVolume
+	}
+
+	var exercises []dto.Exercise
+	decodeJSON(t, do("GET /exercises", "/exercises", nil, http.StatusOK), &exercises)
+	i := slices.IndexFunc(exercises, func(e dto.Exercise) bool {
+		return e.MetricType == "weight_reps" && e.LoadType == "external"
+	})
+	if i < 0 {
+		t.Fatal("no external weight_reps exercise is seeded")
+	}
+	exerciseID := exercises[i].ID
+
+	// グループキーは20文字まで
+	tooLong := strings.Repeat("a", 21)
+	superset := "A"
+	do("POST /menus", "/menus", dto.CreateMenuRequest{
+		Name:  "Too long group",
+		Items: []dto.MenuItemInput{{ExerciseID: exerciseID, SetOrder: 1, GroupKey: &tooLong}},
+	}, http.StatusBadRequest)
+	var menu dto.MenuResponse
+	decodeJSON(t, do("POST /menus", "/menus", dto.CreateMenuRequest{
+		Name: "Superset",
+		Items: []dto.MenuItemInput{
+			{ExerciseID: exerciseID, SetOrder: 1, GroupKey: &superset},
+			{ExerciseID: exerciseID, SetOrder: 2, GroupKey: &superset},
+		},
+	}, http.StatusCreated), &menu)
+	if len(menu.Items) != 2 || menu.Items[0].GroupKey == nil || *menu.Items[0].GroupKey != "A" || menu.Items[1].GroupKey == nil || *menu.Items[1].GroupKey != "A" {
+		t.Errorf("POST /menus items = %+v, want both in group A", menu.Items)
+	}
+
+	weightKg := 60.0
+	workoutWith := func(setType string) dto.CreateWorkoutRequest {
+		return dto.CreateWorkoutRequest{
+			MenuID: menu.ID,
+			Exercises: []dto.ExerciseWithSets{{ExerciseID: exerciseID.String(), Sets: []dto.WorkoutSet{
+				{WeightKg: &weightKg, Reps: 10, SetType: setType},
+			}}},
+		}
+	}
+	do("POST /workouts", "/workouts", workoutWith("superset"), http.StatusBadRequest)
+
+	var workout dto.WorkoutResponse
+	decodeJSON(t, do("POST /workouts", "/workouts", workoutWith(service.SetTypeWarmup), http.StatusCreated), &workout)
+	if len(workout.Sets) != 1 || workout.Sets[0].SetType != service.SetTypeWarmup {
+		t.Fatalf("POST /workouts sets = %+v, want one warm-up set", workout.Sets)
+	}
+	setPath := "/sets/" + workout.Sets[0].ID.String()
+	if setCount, totalVolume := weeklyVolume(); setCount != 0 || totalVolume != 0 {
+		t.Errorf("weekly volume with only a warm-up set = %d sets, %v kg, want none", setCount, totalVolume)
+	}
+
+	tests := []struct {
+		name          string
+		req           dto.UpdateSetRequest
+		wantStatus    int
+		wantSetCount  int
+		wantTotalVol  float64
+		wantGroupKey  *string
+		checkGroupKey bool
+	}{
+		{name: "invalid set type", req: dto.UpdateSetRequest{SetType: ptrTo("superset")}, wantStatus: http.StatusBadRequest},
+		{name: "group key too long", req: dto.UpdateSetRequest{GroupKey: &tooLong}, wantStatus: http.StatusBadRequest},
+		{name: "warm-up to working creates the week", req: dto.UpdateSetRequest{SetType: ptrTo(service.SetTypeWorking)}, wantStatus: http.StatusOK, wantSetCount: 1, wantTotalVol: 600},
+		{name: "group the set", req: dto.UpdateSetRequest{GroupKey: &superset}, wantStatus: http.StatusOK, wantSetCount: 1, wantTotalVol: 600, wantGroupKey: &superset, checkGroupKey: true},
+		{name: "empty group key ungroups", req: dto.UpdateSetRequest{GroupKey: ptrTo("")}, wantStatus: http.StatusOK, wantSetCount: 1, wantTotalVol: 600, checkGroupKey: true},
+		{name: "working to warm-up empties the week", req: dto.UpdateSetRequest{SetType: ptrTo(service.SetTypeWarmup)}, wantStatus: http.StatusOK},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			data := do("PATCH /sets/{id}", setPath, tt.req, tt.wantStatus)
+			if tt.wantStatus != http.StatusOK {
+				return
+			}
+			if tt.checkGroupKey {
+				var set dto.SetView
+				decodeJSON(t, data, &set)
+				if !reflect.DeepEqual(set.GroupKey, tt.wantGroupKey) {
+					t.Errorf("group_key = %v, want %v", set.GroupKey, tt.wantGroupKey)
+				}
+			}
+			if setCount, totalVolume := weeklyVolume(); setCount != tt.wantSetCount || totalVolume != tt.wantTotalVol {
+				t.Errorf("weekly volume = %d sets, %v kg, want %d sets, %v kg", setCount, totalVolume, tt.wantSetCount, tt.wantTotalVol)
+			}
+		})
+	}
+}
+
+// ptrTo は値のポインタを返す
+func ptrTo[T any](v T) *T { return &v }
@@ -0,0 +1,96 @@
+package handler
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/url"
+	"slices"
+	"strings"
+	"testing"
+
+	"github.com/aiirononeko/bulktrack/apps/api/internal/interfaces/http/dto"
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5"
+)
+
+// 同じ started_at のワークアウトは id で順序を決め、ページの境界をまたいでも重複・欠落なく返す
+func TestListWorkoutsPagesThroughIdenticalStartTimes(t *testing.T) {
+	ctx := context.Background()
+	env := newDBTestServer(t, false)
+	pool := env.pool
+
+	const userID = "user_workout_list_test"
+
+	// 5件は同じ開始日時 (マイクロ秒まで一致)、前後に1件ずつ
+	if _, err := pool.Exec(ctx, `
+		INSERT INTO workouts (user_id, started_at)
+		SELECT $1, t FROM unnest(ARRAY[
+			'2025-05-05 07:00:00.000001+09', '2025-05-06 07:00:00.123456+09', '2025-05-06 07:00:00.123456+09',
+			'2025-05-06 07:00:00.123456+09', '2025-05-06 07:00:00.123456+09', '2025-05-06 07:00:00.123456+09',
+			'2025-05-07 07:00:00+09'
+		]::timestamptz[]) AS t`, userID); err != nil {
+		t.Fatalf("failed to insert workouts: %v", err)
+	}
+	rows, err := pool.Query(ctx, "SELECT id FROM workouts WHERE user_id = $1 ORDER BY started_at, id", userID)
+	if err != nil {
+		t.Fatalf("failed to list workouts: %v", err)
+	}
+	ascending, err := pgx.CollectRows(rows, pgx.RowTo[uuid.UUID])
+	if err != nil {
+		t.Fatalf("failed to list workouts: %v", err)
+	}
+	descending := slices.Clone(ascending)
+	slices.Reverse(descending)
+
+	// listPages は next_cursor が null になるまでページを取得し、ワークアウトの id を順に返す
+	listPages := func(t *testing.T, query url.Values) []uuid.UUID {
+		t.Helper()
+		var ids []uuid.UUID
+		for page := 0; ; page++ {
+			if page > len(ascending) {
+				t.Fatalf("next_cursor did not end after %d pages", page)
+			}
+			body := env.as(t, userID)("GET /workouts", "/workouts?"+query.Encode(), nil, http.StatusOK)
+			var raw map[string]json.RawMessage
+			decodeJSON(t, body, &raw)
+			if _, ok := raw["next_cursor"]; !ok {
+				t.Fatalf("GET /workouts response has no next_cursor: %s", body)
+			}
+			var resp dto.WorkoutListResponse
+			decodeJSON(t, body, &resp)
+			for _, w := range resp.Data {
+				ids = append(ids, w.ID)
+			}
+			if resp.NextCursor == nil {
+				return ids
+			}
+			query.Set("cursor", *resp.NextCursor)
+		}
+	}
+
+	tests := []struct {
+		name  string
+		query url.Values
+		want  []uuid.UUID
+	}{
+		{name: "desc by 2", query: url.Values{"limit": {"2"}}, want: descending},
+		{name: "asc by 2", query: url.Values{"limit": {"2"}, "order": {"asc"}}, want: ascending},
+		{name: "desc by 1", query: url.Values{"limit": {"1"}}, want: descending},
+		{name: "asc by 3 within the day", query: url.Values{"limit": {"3"}, "order": {"asc"}, "from": {"2025-05-06"}, "to": {"2025-05-06"}}, want: ascending[1:6]},
+		{name: "single page", query: url.Values{"limit": {"100"}}, want: descending},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := listPages(t, tt.query); !slices.Equal(got, tt.want) {
+				t.Errorf("ids = %v, want %v", got, tt.want)
+			}
+		})
+	}
+
+	t.Run("invalid cursor", func(t *testing.T) {
+		if body := env.as(t, userID)("GET /workouts", "/workouts?cursor=not-a-cursor", nil, http.StatusBadRequest); !strings.Contains(string(body), "cursor") {
+			t.Errorf("GET /workouts with an invalid cursor: body = %s, want an error about cursor", body)
+		}
+	})
+}
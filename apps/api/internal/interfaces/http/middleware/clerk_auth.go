@@ -2,14 +2,20 @@ package middleware
 
 import (
 	"context"
+	"errors"
+	"fmt"
 	"net/http"
+	"slices"
 	"strings"
 
 	"log/slog"
 
+	"github.com/aiirononeko/bulktrack/apps/api/internal/auth"
 	"github.com/aiirononeko/bulktrack/apps/api/internal/config"
-	"github.com/clerk/clerk-sdk-go/v2"
-	"github.com/clerk/clerk-sdk-go/v2/jwt"
+	httpError "github.com/aiirononeko/bulktrack/apps/api/internal/http"
+	"github.com/aiirononeko/bulktrack/apps/api/internal/logging"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/trace"
 )
 
 type contextKey string
@@ -22,10 +28,61 @@ func GetUserIDFromContext(ctx context.Context) (string, bool) {
 	return userID, ok
 }
 
-// ClerkAuth はClerkによる認証を行うミドルウェアです
-func ClerkAuth(cfg *config.Config, logger *slog.Logger) func(next http.Handler) http.Handler {
-	// Clerk APIキーの設定
-	clerk.SetKey(cfg.ClerkSecretKey)
+// verifiedToken は検証済みトークンのうち認証に使う項目です
+type verifiedToken struct {
+	Subject   string
+	SessionID string
+}
+
+// tokenVerifier はトークンを検証する関数です
+type tokenVerifier func(ctx context.Context, token string) (*verifiedToken, error)
+
+// clerkJWKSURL は Clerk Backend API の JWKS (シークレットキーで取得する)
+const clerkJWKSURL = "https://api.clerk.com/v1/jwks"
+
+// newTokenVerifier は設定に応じたトークンの検証方法を返します
+// JWKS の URL またはファイルが設定されている場合はその鍵で検証し (Clerk のアカウントがなくても動かせる)、
+// 設定されていない場合は Clerk Backend API の JWKS で検証します。どちらも kid ごとに鍵をキャッシュします
+func newTokenVerifier(cfg *config.Config, logger *slog.Logger) tokenVerifier {
+	opts := auth.Options{
+		JWKSURL:           cfg.JWKSURL,
+		JWKSFile:          cfg.JWKSFile,
+		Issuer:            cfg.JWTIssuer,
+		Audiences:         cfg.JWTAudiences,
+		AuthorizedParties: cfg.JWTAuthorizedParties,
+		ClockSkew:         cfg.JWTClockSkew,
+	}
+	if opts.JWKSURL == "" && opts.JWKSFile == "" && cfg.ClerkSecretKey != "" {
+		opts.JWKSURL = clerkJWKSURL
+		opts.JWKSHeader = http.Header{"Authorization": {"Bearer " + cfg.ClerkSecretKey}}
+	}
+
+	verifier, err := auth.NewJWKSVerifier(opts)
+	if err != nil {
+		logger.Error("JWKS_URL・JWKS_FILE・CLERK_SECRET_KEY のいずれも設定されていないため、トークンを検証できません", "error", err)
+		return func(ctx context.Context, token string) (*verifiedToken, error) {
+			return nil, err
+		}
+	}
+	logger.Info("JWKSでトークンを検証します", "jwksURL", opts.JWKSURL, "jwksFile", opts.JWKSFile, "issuer", opts.Issuer)
+	return func(ctx context.Context, token string) (*verifiedToken, error) {
+		claims, err := verifier.Verify(ctx, token)
+		if err != nil {
+			return nil, err
+		}
+		return &verifiedToken{Subject: claims.Subject, SessionID: claims.SessionID}, nil
+	}
+}
+
+// PersonalAccessTokenAuthenticator はパーソナルアクセストークンを検証し、ユーザーIDとスコープを返します
+type PersonalAccessTokenAuthenticator interface {
+	AuthenticateToken(ctx context.Context, token string) (userID string, scopes []string, err error)
+}
+
+// ClerkAuth はClerk (または設定した JWKS の発行者) のトークンによる認証を行うミドルウェアです
+// pats を指定した場合はパーソナルアクセストークン (btpat_...) も受け付け、ルートに必要なスコープを確認します
+func ClerkAuth(cfg *config.Config, logger *slog.Logger, pats PersonalAccessTokenAuthenticator) func(next http.Handler) http.Handler {
+	verify := newTokenVerifier(cfg, logger)
 
 	return func(next http.Handler) http.Handler {
 		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
@@ -45,10 +102,14 @@ func ClerkAuth(cfg *config.Config, logger *slog.Logger) func(next http.Handler)
 				return
 			}
 
+			// パーソナルアクセストークン
+			if pats != nil && strings.HasPrefix(token, auth.PersonalAccessTokenPrefix) {
+				authenticatePersonalAccessToken(w, r, next, pats, token, logger)
+				return
+			}
+
 			// トークンを検証
-			claims, err := jwt.Verify(r.Context(), &jwt.VerifyParams{
-				Token: token,
-			})
+			claims, err := verify(r.Context(), token)
 			if err != nil {
 				logger.Error("トークン検証エラー", "error", err)
 				http.Error(w, "認証に失敗しました", http.StatusUnauthorized)
@@ -58,6 +119,8 @@ func ClerkAuth(cfg *config.Config, logger *slog.Logger) func(next http.Handler)
 			// ユーザーIDをコンテキストに保存
 			userID := claims.Subject
 			ctx := context.WithValue(r.Context(), UserIDKey, userID)
+			trace.SpanFromContext(ctx).SetAttributes(attribute.String("user_id", userID))
+			logging.AddAttrs(ctx, slog.String(logging.UserIDKey, userID))
 
 			logger.Debug("認証成功",
 				"userID", userID,
@@ -68,3 +131,37 @@ func ClerkAuth(cfg *config.Config, logger *slog.Logger) func(next http.Handler)
 		})
 	}
 }
+
+// authenticatePersonalAccessToken はパーソナルアクセストークンを検証し、スコープが足りる場合のみ次のハンドラに渡します
+func authenticatePersonalAccessToken(w http.ResponseWriter, r *http.Request, next http.Handler, pats PersonalAccessTokenAuthenticator, token string, logger *slog.Logger) {
+	userID, scopes, err := pats.AuthenticateToken(r.Context(), token)
+	if err != nil {
+		if errors.Is(err, auth.ErrInvalidPersonalAccessToken) {
+			logger.Debug("無効なパーソナルアクセストークンです")
+			http.Error(w, "認証に失敗しました", http.StatusUnauthorized)
+			return
+		}
+		logger.Error("パーソナルアクセストークン検証エラー", "error", err)
+		http.Error(w, "認証に失敗しました", http.StatusInternalServerError)
+		return
+	}
+
+	// r.Pattern は ServeMux が一致したルートのパターン
+	scope, ok := auth.RequiredScope(r.Pattern)
+	if !ok {
+		logger.Debug("パーソナルアクセストークンでは呼び出せないルートです", "userID", userID, "pattern", r.Pattern)
+		httpError.WriteError(w, httpError.NewForbiddenError("This endpoint cannot be accessed with a personal access token", nil))
+		return
+	}
+	if !slices.Contains(scopes, scope) {
+		logger.Debug("スコープが足りません", "userID", userID, "pattern", r.Pattern, "requiredScope", scope)
+		httpError.WriteError(w, httpError.NewForbiddenError(fmt.Sprintf("Personal access token requires the %s scope", scope), nil))
+		return
+	}
+
+	ctx := context.WithValue(r.Context(), UserIDKey, userID)
+	trace.SpanFromContext(ctx).SetAttributes(attribute.String("user_id", userID), attribute.String("auth.method", "personal_access_token"))
+	logging.AddAttrs(ctx, slog.String(logging.UserIDKey, userID))
+	logger.Debug("認証成功 (パーソナルアクセストークン)", "userID", userID, "scope", scope)
+	next.ServeHTTP(w, r.WithContext(ctx))
+}
@@ -0,0 +1,97 @@
+package middleware
+
+import (
+	"context"
+	"errors"
+	"log/slog"
+	"net/http"
+
+	"github.com/aiirononeko/bulktrack/apps/api/internal/auth"
+	httpError "github.com/aiirononeko/bulktrack/apps/api/internal/http"
+	"github.com/aiirononeko/bulktrack/apps/api/internal/logging"
+	"github.com/google/uuid"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/trace"
+)
+
+// CoachUserIDKey はアスリートのデータにアクセスしているコーチのユーザーIDのコンテキストキーです
+const CoachUserIDKey contextKey = "coachUserID"
+
+// GetCoachUserIDFromContext はアスリートのデータにアクセスしているコーチのユーザーIDを取得します
+// コーチとしてのアクセスでない場合は ok が false です
+func GetCoachUserIDFromContext(ctx context.Context) (string, bool) {
+	coachUserID, ok := ctx.Value(CoachUserIDKey).(string)
+	return coachUserID, ok
+}
+
+// CoachAuthorizer はコーチのアスリートのデータへのアクセス権を確認し、操作を記録します
+type CoachAuthorizer interface {
+	AuthorizeCoach(ctx context.Context, coachUserID, athleteUserID, required string) (grantID uuid.UUID, err error)
+	RecordCoachAction(ctx context.Context, action auth.CoachAction) error
+}
+
+// CoachAccess はコーチがアスリートのデータにアクセスするためのミドルウェアです (認証の後に使います)
+//
+// X-Athlete-ID ヘッダーがある場合、ルートに必要な権限がアスリートから与えられているかを確認し、
+// コンテキストのユーザーIDをアスリートに置き換えます。コーチとしてのリクエストはすべて監査ログに記録します
+func CoachAccess(coaches CoachAuthorizer, logger *slog.Logger) func(next http.Handler) http.Handler {
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			athleteUserID := r.Header.Get(auth.CoachAthleteHeader)
+			if athleteUserID == "" {
+				next.ServeHTTP(w, r)
+				return
+			}
+
+			coachUserID, ok := GetUserIDFromContext(r.Context())
+			if !ok {
+				logger.Error("User ID not found in context")
+				http.Error(w, "Unauthorized", http.StatusUnauthorized)
+				return
+			}
+			if athleteUserID == coachUserID {
+				// 自分自身のデータ
+				next.ServeHTTP(w, r)
+				return
+			}
+
+			// r.Pattern は ServeMux が一致したルートのパターン
+			required, ok := auth.RequiredCoachPermission(r.Pattern)
+			if !ok {
+				httpError.WriteError(w, httpError.NewForbiddenError("This endpoint cannot be accessed on behalf of an athlete", nil))
+				return
+			}
+			grantID, err := coaches.AuthorizeCoach(r.Context(), coachUserID, athleteUserID, required)
+			if err != nil {
+				if errors.Is(err, auth.ErrCoachAccessDenied) {
+					logger.Warn("Coach access denied", slog.String("user_id", coachUserID), slog.String("athlete_user_id", athleteUserID), slog.String("pattern", r.Pattern))
+					httpError.WriteError(w, httpError.NewForbiddenError("You do not have "+required+" access to this athlete", nil))
+					return
+				}
+				logger.Error("Failed to authorize coach", slog.Any("error", err), slog.String("user_id", coachUserID))
+				http.Error(w, "Failed to authorize coach", http.StatusInternalServerError)
+				return
+			}
+
+			ctx := context.WithValue(r.Context(), UserIDKey, athleteUserID)
+			ctx = context.WithValue(ctx, CoachUserIDKey, coachUserID)
+			trace.SpanFromContext(ctx).SetAttributes(attribute.String("user_id", athleteUserID), attribute.String("coach_user_id", coachUserID))
+			logging.AddAttrs(ctx, slog.String(logging.UserIDKey, athleteUserID), slog.String(logging.CoachUserIDKey, coachUserID))
+			rw := &responseWriter{ResponseWriter: w, status: http.StatusOK}
+			next.ServeHTTP(rw, r.WithContext(ctx))
+
+			// レスポンスを返した後に記録する (クライアントが切断していても記録する)
+			if err := coaches.RecordCoachAction(context.WithoutCancel(r.Context()), auth.CoachAction{
+				GrantID:       grantID,
+				CoachUserID:   coachUserID,
+				AthleteUserID: athleteUserID,
+				Method:        r.Method,
+				Route:         r.Pattern,
+				Path:          r.URL.Path,
+				StatusCode:    rw.status,
+			}); err != nil {
+				logger.Error("Failed to record coach action", slog.Any("error", err), slog.String("user_id", coachUserID), slog.String("grant_id", grantID.String()))
+			}
+		})
+	}
+}
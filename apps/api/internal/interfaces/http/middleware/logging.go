@@ -17,6 +17,11 @@ func (rw *responseWriter) WriteHeader(statusCode int) {
 	rw.ResponseWriter.WriteHeader(statusCode)
 }
 
+// Unwrap は元の http.ResponseWriter を返す (http.ResponseController が書き込み期限の変更やフラッシュに使う)
+func (rw *responseWriter) Unwrap() http.ResponseWriter {
+	return rw.ResponseWriter
+}
+
 // LoggingMiddleware はリクエスト情報をログに出力するミドルウェア
 func LoggingMiddleware(logger *slog.Logger) func(http.Handler) http.Handler {
 	return func(next http.Handler) http.Handler {
@@ -31,8 +36,8 @@ func LoggingMiddleware(logger *slog.Logger) func(http.Handler) http.Handler {
 
 			duration := time.Since(start)
 
-			// ログ出力
-			logger.Info("Request processed",
+			// ログ出力 (request_id と認証後の user_id はコンテキストから付く)
+			logger.InfoContext(r.Context(), "Request processed",
 				slog.String("method", r.Method),
 				slog.String("path", r.URL.Path),
 				slog.Int("status", rw.status),
@@ -0,0 +1,50 @@
+package middleware
+
+import (
+	"io"
+	"log/slog"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	httpError "github.com/aiirononeko/bulktrack/apps/api/internal/http"
+)
+
+// ラップしたレスポンスライターを通して、ハンドラーがサーバーの WriteTimeout より書き込み期限を延長できること (同期エクスポートが使う)
+func TestResponseWriterWrappersAllowExtendingWriteDeadline(t *testing.T) {
+	const (
+		writeTimeout = 100 * time.Millisecond
+		work         = 3 * writeTimeout
+	)
+	logger := slog.New(slog.NewTextHandler(io.Discard, nil))
+
+	deadlineErr := make(chan error, 1)
+	handler := http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		deadlineErr <- http.NewResponseController(w).SetWriteDeadline(time.Now().Add(10 * writeTimeout))
+		time.Sleep(work)
+		io.WriteString(w, "complete")
+	})
+
+	// cmd/server と同じ順にラップする
+	srv := httptest.NewUnstartedServer(httpError.ErrorHandler(Telemetry()(RequestID()(LoggingMiddleware(logger)(handler)))))
+	srv.Config.WriteTimeout = writeTimeout
+	srv.Start()
+	defer srv.Close()
+
+	resp, err := srv.Client().Get(srv.URL)
+	if err != nil {
+		t.Fatalf("GET error = %v (the write deadline was not extended)", err)
+	}
+	defer resp.Body.Close()
+	body, err := io.ReadAll(resp.Body)
+	if err != nil {
+		t.Fatalf("reading body error = %v (the write deadline was not extended)", err)
+	}
+	if err := <-deadlineErr; err != nil {
+		t.Fatalf("SetWriteDeadline() error = %v", err)
+	}
+	if string(body) != "complete" {
+		t.Errorf("body = %q, want %q", body, "complete")
+	}
+}
@@ -0,0 +1,118 @@
+package middleware
+
+import (
+	"log/slog"
+	"math"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/aiirononeko/bulktrack/apps/api/internal/config"
+	httpError "github.com/aiirononeko/bulktrack/apps/api/internal/http"
+	"github.com/aiirononeko/bulktrack/apps/api/internal/ratelimit"
+)
+
+// デフォルトのレート制限 (設定が不正な場合に使います)
+var (
+	defaultIPRateLimit    = ratelimit.Limit{Requests: 100, Window: 15 * time.Minute}
+	defaultUserRateLimit  = ratelimit.Limit{Requests: 600, Window: 15 * time.Minute}
+	defaultHeavyRateLimit = ratelimit.Limit{Requests: 10, Window: time.Hour}
+)
+
+// RateLimiter はトークンバケットによるレート制限のミドルウェアを提供します
+type RateLimiter struct {
+	store    ratelimit.Store
+	ip       ratelimit.Limit
+	user     ratelimit.Limit
+	heavy    ratelimit.Limit
+	ipHeader string
+	logger   *slog.Logger
+}
+
+// NewRateLimiter は設定の制限で新しい RateLimiter を作成します (store が nil の場合は制限しません)
+func NewRateLimiter(cfg *config.Config, store ratelimit.Store, logger *slog.Logger) *RateLimiter {
+	return &RateLimiter{
+		store:    store,
+		ip:       parseRateLimit(cfg.RateLimitIP, "RATE_LIMIT_IP", defaultIPRateLimit, logger),
+		user:     parseRateLimit(cfg.RateLimitUser, "RATE_LIMIT_USER", defaultUserRateLimit, logger),
+		heavy:    parseRateLimit(cfg.RateLimitHeavy, "RATE_LIMIT_HEAVY", defaultHeavyRateLimit, logger),
+		ipHeader: cfg.RateLimitIPHeader,
+		logger:   logger,
+	}
+}
+
+func parseRateLimit(value, env string, defaultLimit ratelimit.Limit, logger *slog.Logger) ratelimit.Limit {
+	limit, err := ratelimit.ParseLimit(value)
+	if err != nil {
+		logger.Error("Invalid rate limit, using default", slog.String("env", env), slog.Any("error", err), slog.String("default", defaultLimit.String()))
+		return defaultLimit
+	}
+	return limit
+}
+
+// ByIP は送信元のIPアドレスごとに制限するミドルウェアです (認証の前に使います)
+func (l *RateLimiter) ByIP(next http.Handler) http.Handler {
+	if l.store == nil {
+		return next
+	}
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		ip := ratelimit.ClientIP(r, l.ipHeader)
+		if !l.take(w, r, "ip:"+ip, l.ip) {
+			l.logger.Warn("Rate limited by IP", slog.String("remote_ip", ip), slog.String("pattern", r.Pattern))
+			return
+		}
+		next.ServeHTTP(w, r)
+	})
+}
+
+// ByUser は認証したユーザーごとに制限するミドルウェアです (認証の後に使います)
+// エクスポートや再計算など負荷の高いルートには、さらに別の制限を適用します
+func (l *RateLimiter) ByUser(next http.Handler) http.Handler {
+	if l.store == nil {
+		return next
+	}
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		userID, ok := GetUserIDFromContext(r.Context())
+		if !ok {
+			next.ServeHTTP(w, r)
+			return
+		}
+		if !l.take(w, r, "user:"+userID, l.user) {
+			l.logger.Warn("Rate limited by user", slog.String("user_id", userID), slog.String("pattern", r.Pattern))
+			return
+		}
+		// r.Pattern は ServeMux が一致したルートのパターン
+		if ratelimit.IsHeavyRoute(r.Pattern) && !l.take(w, r, "heavy:"+userID, l.heavy) {
+			l.logger.Warn("Rate limited on heavy endpoint", slog.String("user_id", userID), slog.String("pattern", r.Pattern))
+			return
+		}
+		next.ServeHTTP(w, r)
+	})
+}
+
+// take はトークンを取り出してレート制限のヘッダーを設定し、制限を超えた場合は 429 を返します
+// ストアのエラー (データベースの障害など) の場合は、リクエストを止めないよう許可します
+func (l *RateLimiter) take(w http.ResponseWriter, r *http.Request, key string, limit ratelimit.Limit) bool {
+	res, err := l.store.Take(r.Context(), key, limit)
+	if err != nil {
+		l.logger.Error("Failed to check rate limit, allowing request", slog.Any("error", err), slog.String("pattern", r.Pattern))
+		return true
+	}
+
+	h := w.Header()
+	h.Set("RateLimit-Limit", strconv.Itoa(res.Limit))
+	h.Set("RateLimit-Remaining", strconv.Itoa(res.Remaining))
+	h.Set("RateLimit-Reset", strconv.Itoa(ceilSeconds(res.ResetAfter)))
+	if res.Allowed {
+		return true
+	}
+
+	h.Set("Retry-After", strconv.Itoa(ceilSeconds(res.RetryAfter)))
+	httpError.WriteError(w, httpError.NewTooManyRequestsError("Too many requests, please retry later", nil))
+	return false
+}
+
+// ceilSeconds は秒単位に切り上げます (0秒の場合は1秒)
+func ceilSeconds(d time.Duration) int {
+	return max(int(math.Ceil(d.Seconds())), 1)
+}
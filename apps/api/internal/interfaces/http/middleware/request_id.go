@@ -0,0 +1,51 @@
+package middleware
+
+import (
+	"log/slog"
+	"net/http"
+
+	"github.com/aiirononeko/bulktrack/apps/api/internal/logging"
+	"github.com/google/uuid"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/trace"
+)
+
+// RequestIDHeader はリクエストIDのヘッダーです
+const RequestIDHeader = "X-Request-ID"
+
+// maxRequestIDLength は受け付けるリクエストIDの最大長です
+const maxRequestIDLength = 128
+
+// RequestID はリクエストIDをコンテキストに保存し、レスポンスヘッダーで返すミドルウェアです
+// クライアントやプロキシから X-Request-ID が渡された場合はそれを使い、ない場合 (または不正な値の場合) は生成します
+// コンテキストを使うログ (InfoContext など) には request_id と、認証後は user_id が付きます (logging.Handler)
+func RequestID() func(http.Handler) http.Handler {
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			requestID := r.Header.Get(RequestIDHeader)
+			if !validRequestID(requestID) {
+				requestID = uuid.NewString()
+			}
+			w.Header().Set(RequestIDHeader, requestID)
+
+			ctx := logging.WithRequestAttrs(r.Context(), slog.String(logging.RequestIDKey, requestID))
+			trace.SpanFromContext(ctx).SetAttributes(attribute.String("http.request.id", requestID))
+			next.ServeHTTP(w, r.WithContext(ctx))
+		})
+	}
+}
+
+// validRequestID はリクエストIDがログやヘッダーに出力しても安全な値かを確認します (英数字と - _ . : のみ)
+func validRequestID(id string) bool {
+	if id == "" || len(id) > maxRequestIDLength {
+		return false
+	}
+	for _, c := range id {
+		switch {
+		case 'a' <= c && c <= 'z', 'A' <= c && c <= 'Z', '0' <= c && c <= '9', c == '-', c == '_', c == '.', c == ':':
+		default:
+			return false
+		}
+	}
+	return true
+}
@@ -0,0 +1,62 @@
+package middleware
+
+import (
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/aiirononeko/bulktrack/apps/api/internal/telemetry"
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/codes"
+	"go.opentelemetry.io/otel/metric"
+	"go.opentelemetry.io/otel/propagation"
+	"go.opentelemetry.io/otel/trace"
+)
+
+// Telemetry はリクエストごとにスパンを作成し、ルートごとの RED メトリクス (リクエスト数・エラー・所要時間) を記録するミドルウェアです
+// スパン名とメトリクスの http.route には ServeMux のパターン (例: GET /menus/{id}) を使います
+func Telemetry() func(http.Handler) http.Handler {
+	tracer := otel.Tracer(telemetry.InstrumentationName + "/http")
+	meter := otel.Meter(telemetry.InstrumentationName + "/http")
+	duration, err := meter.Float64Histogram("http.server.request.duration",
+		metric.WithDescription("Duration of HTTP server requests"),
+		metric.WithUnit("s"),
+		metric.WithExplicitBucketBoundaries(0.005, 0.01, 0.025, 0.05, 0.075, 0.1, 0.25, 0.5, 0.75, 1, 2.5, 5, 7.5, 10))
+	if err != nil {
+		otel.Handle(err)
+	}
+	propagator := otel.GetTextMapPropagator()
+
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			start := time.Now()
+			ctx := propagator.Extract(r.Context(), propagation.HeaderCarrier(r.Header))
+			ctx, span := tracer.Start(ctx, r.Pattern,
+				trace.WithSpanKind(trace.SpanKindServer),
+				trace.WithAttributes(
+					attribute.String("http.request.method", r.Method),
+					attribute.String("http.route", r.Pattern),
+					attribute.String("url.path", r.URL.Path),
+					attribute.String("user_agent.original", r.UserAgent()),
+				),
+			)
+			defer span.End()
+
+			rw := &responseWriter{ResponseWriter: w, status: http.StatusOK}
+			next.ServeHTTP(rw, r.WithContext(ctx))
+
+			span.SetAttributes(attribute.Int("http.response.status_code", rw.status))
+			if rw.status >= http.StatusInternalServerError {
+				span.SetStatus(codes.Error, strconv.Itoa(rw.status))
+			}
+			if duration != nil {
+				duration.Record(ctx, time.Since(start).Seconds(), metric.WithAttributes(
+					attribute.String("http.request.method", r.Method),
+					attribute.String("http.route", r.Pattern),
+					attribute.Int("http.response.status_code", rw.status),
+				))
+			}
+		})
+	}
+}
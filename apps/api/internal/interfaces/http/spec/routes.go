@@ -0,0 +1,601 @@
+package spec
+
+import (
+	"net/http"
+
+	"github.com/aiirononeko/bulktrack/apps/api/internal/interfaces/http/dto"
+	"github.com/aiirononeko/bulktrack/apps/api/internal/openapi"
+	"github.com/aiirononeko/bulktrack/apps/api/internal/webhook"
+)
+
+// routes は API のすべてのルートの操作を返す
+// サーバーにルートを追加・変更した場合はこの表も更新する (一致しない場合は契約テストが失敗する)
+func routes(d *openapi.Document) []route {
+	uuidParam := func(name, description string) *openapi.Parameter {
+		return pathParam(name, description, stringSchema("uuid"))
+	}
+	limitParam := queryParam("limit", "1ページの件数 (1〜100)", integerSchema(1, 100))
+	cursorParam := queryParam("cursor", "前のページの next_cursor", stringSchema(""))
+	fromDateParam := queryParam("from", "期間の開始日 (YYYY-MM-DD)", stringSchema("date"))
+	toDateParam := queryParam("to", "期間の終了日 (YYYY-MM-DD、この日を含む)", stringSchema("date"))
+	tzParam := queryParam("tz", "集計に使う IANA タイムゾーン名 (デフォルトは Asia/Tokyo)", stringSchema(""))
+
+	return []route{
+		// システム
+		{
+			pattern:     "GET /health",
+			operationID: "getHealth",
+			summary:     "ヘルスチェック",
+			description: "データベースに接続できる場合に 200 を返す。レート制限しない。",
+			tag:         "system",
+			public:      true,
+			unlimited:   true,
+			responses: map[int]*openapi.Response{
+				http.StatusOK: {
+					Description: "正常",
+					Content: map[string]*openapi.MediaType{"application/json": {Schema: &openapi.Schema{
+						Type:       openapi.Types{"object"},
+						Properties: map[string]*openapi.Schema{"status": stringSchema("", "ok")},
+						Required:   []string{"status"},
+					}}},
+				},
+			},
+			errors: []int{http.StatusServiceUnavailable},
+		},
+		{
+			pattern:     "GET /openapi.json",
+			operationID: "getOpenAPIDocument",
+			summary:     "この API の OpenAPI ドキュメント",
+			tag:         "system",
+			public:      true,
+			etag:        true,
+			responses: map[int]*openapi.Response{
+				http.StatusOK: {
+					Description: "OpenAPI 3.1 のドキュメント",
+					Content:     map[string]*openapi.MediaType{"application/json": {Schema: &openapi.Schema{Type: openapi.Types{"object"}}}},
+				},
+			},
+		},
+		{
+			pattern:     "POST /webhooks/clerk",
+			operationID: "handleClerkWebhook",
+			summary:     "Clerk の Webhook (ユーザーの削除など) を受け取る",
+			description: "Svix の署名で検証する (JWT の認証はしない)。CLERK_WEBHOOK_SECRET が未設定の場合は 503 を返す。",
+			tag:         "system",
+			public:      true,
+			params: []*openapi.Parameter{
+				{Name: webhook.SvixIDHeader, In: "header", Required: true, Schema: stringSchema("")},
+				{Name: webhook.SvixTimestampHeader, In: "header", Required: true, Schema: stringSchema("")},
+				{Name: webhook.SvixSignatureHeader, In: "header", Required: true, Schema: stringSchema("")},
+			},
+			body: &openapi.RequestBody{
+				Required: true,
+				Content:  map[string]*openapi.MediaType{"application/json": {Schema: &openapi.Schema{Type: openapi.Types{"object"}}}},
+			},
+			responses: map[int]*openapi.Response{http.StatusNoContent: noContent("受け取った")},
+			errors:    []int{http.StatusBadRequest, http.StatusUnauthorized, http.StatusServiceUnavailable},
+		},
+
+		// トレーニングメニュー
+		{
+			pattern:     "GET /menus",
+			operationID: "listMenus",
+			summary:     "メニューの一覧",
+			tag:         "menus",
+			responses:   map[int]*openapi.Response{http.StatusOK: jsonResponse[[]dto.MenuResponse](d, "メニューの一覧")},
+		},
+		{
+			pattern:     "POST /menus",
+			operationID: "createMenu",
+			summary:     "メニューを作成する",
+			tag:         "menus",
+			body:        jsonBody[dto.CreateMenuRequest](d, true),
+			responses:   map[int]*openapi.Response{http.StatusCreated: jsonResponse[dto.MenuResponse](d, "作成したメニュー")},
+			errors:      []int{http.StatusBadRequest, http.StatusNotFound, http.StatusConflict},
+		},
+		{
+			pattern:     "GET /menus/{id}",
+			operationID: "getMenu",
+			summary:     "メニューと種目を取得する",
+			tag:         "menus",
+			etag:        true,
+			params:      []*openapi.Parameter{uuidParam("id", "メニューのID")},
+			responses:   map[int]*openapi.Response{http.StatusOK: jsonResponse[dto.MenuResponse](d, "メニュー")},
+			errors:      []int{http.StatusBadRequest, http.StatusNotFound},
+		},
+		{
+			pattern:     "PUT /menus/{id}",
+			operationID: "updateMenu",
+			summary:     "メニューを更新する",
+			tag:         "menus",
+			params:      []*openapi.Parameter{uuidParam("id", "メニューのID")},
+			body:        jsonBody[dto.MenuUpdateRequest](d, true),
+			responses:   map[int]*openapi.Response{http.StatusOK: jsonResponse[dto.MenuResponse](d, "更新したメニュー")},
+			errors:      []int{http.StatusBadRequest, http.StatusNotFound, http.StatusConflict, http.StatusPreconditionFailed},
+		},
+		{
+			pattern:     "DELETE /menus/{id}",
+			operationID: "deleteMenu",
+			summary:     "メニューを削除する",
+			tag:         "menus",
+			params:      []*openapi.Parameter{uuidParam("id", "メニューのID")},
+			responses:   map[int]*openapi.Response{http.StatusNoContent: noContent("削除した")},
+			errors:      []int{http.StatusBadRequest, http.StatusNotFound},
+		},
+		{
+			pattern:     "GET /menus/{id}/exercises/last-records",
+			operationID: "listMenuLastRecords",
+			summary:     "メニューの種目ごとの前回の記録",
+			tag:         "menus",
+			params:      []*openapi.Parameter{uuidParam("id", "メニューのID")},
+			responses:   map[int]*openapi.Response{http.StatusOK: jsonResponse[[]dto.ExerciseLastRecord](d, "種目ごとの前回のセット")},
+			errors:      []int{http.StatusBadRequest, http.StatusNotFound},
+		},
+		{
+			pattern:     "POST /menus/{id}/share",
+			operationID: "shareMenu",
+			summary:     "メニューの共有コードを発行する",
+			description: "すでに共有している場合は同じ共有コードを 200 で返す。",
+			tag:         "menus",
+			params:      []*openapi.Parameter{uuidParam("id", "メニューのID")},
+			responses: map[int]*openapi.Response{
+				http.StatusOK:      jsonResponse[dto.MenuShareView](d, "共有済みの共有コード"),
+				http.StatusCreated: jsonResponse[dto.MenuShareView](d, "発行した共有コード"),
+			},
+			errors: []int{http.StatusBadRequest, http.StatusNotFound},
+		},
+		{
+			pattern:     "DELETE /menus/{id}/share",
+			operationID: "unshareMenu",
+			summary:     "メニューの共有を停止する",
+			tag:         "menus",
+			params:      []*openapi.Parameter{uuidParam("id", "メニューのID")},
+			responses:   map[int]*openapi.Response{http.StatusNoContent: noContent("共有を停止した")},
+			errors:      []int{http.StatusBadRequest, http.StatusNotFound},
+		},
+		{
+			pattern:     "GET /shared-menus/{code}",
+			operationID: "getSharedMenu",
+			summary:     "共有コードのメニューを取得する",
+			tag:         "menus",
+			params:      []*openapi.Parameter{pathParam("code", "共有コード", stringSchema(""))},
+			responses:   map[int]*openapi.Response{http.StatusOK: jsonResponse[dto.SharedMenuView](d, "共有されたメニュー")},
+			errors:      []int{http.StatusNotFound},
+		},
+		{
+			pattern:     "POST /shared-menus/{code}/clone",
+			operationID: "cloneSharedMenu",
+			summary:     "共有されたメニューを自分のメニューに複製する",
+			description: "自分にないカスタム種目は作成する。ボディは省略できる。",
+			tag:         "menus",
+			params:      []*openapi.Parameter{pathParam("code", "共有コード", stringSchema(""))},
+			body:        jsonBody[dto.CloneMenuRequest](d, false),
+			responses:   map[int]*openapi.Response{http.StatusCreated: jsonResponse[dto.CloneMenuResponse](d, "複製したメニュー")},
+			errors:      []int{http.StatusBadRequest, http.StatusNotFound, http.StatusConflict},
+		},
+		{
+			pattern:     "GET /menu-templates",
+			operationID: "listMenuTemplates",
+			summary:     "メニューのテンプレートの一覧",
+			tag:         "menus",
+			responses:   map[int]*openapi.Response{http.StatusOK: jsonResponse[[]dto.MenuTemplateView](d, "テンプレートの一覧")},
+		},
+
+		// ワークアウト・セット・履歴・取り込み
+		{
+			pattern:     "GET /workouts",
+			operationID: "listWorkouts",
+			summary:     "ワークアウトの一覧 (カーソルでページング)",
+			description: "レスポンスは data と next_cursor のオブジェクト (以前の配列のみのレスポンスから変更)。limit を省略した場合は 20 件ずつ返す。",
+			tag:         "workouts",
+			params: []*openapi.Parameter{
+				cursorParam,
+				fromDateParam,
+				toDateParam,
+				queryParam("order", "開始日時の並び順 (デフォルトは desc)", stringSchema("", "asc", "desc")),
+				limitParam,
+				queryParam("menu_id", "メニューで絞り込む", stringSchema("uuid")),
+				queryParam("exercise_id", "種目で絞り込む", stringSchema("uuid")),
+			},
+			responses: map[int]*openapi.Response{http.StatusOK: jsonResponse[dto.WorkoutListResponse](d, "ワークアウトの一覧")},
+			errors:    []int{http.StatusBadRequest},
+		},
+		{
+			pattern:     "POST /workouts",
+			operationID: "startWorkout",
+			summary:     "ワークアウトを記録する",
+			tag:         "workouts",
+			body:        jsonBody[dto.CreateWorkoutRequest](d, true),
+			responses:   map[int]*openapi.Response{http.StatusCreated: jsonResponse[dto.WorkoutResponse](d, "記録したワークアウト")},
+			errors:      []int{http.StatusBadRequest, http.StatusNotFound},
+		},
+		{
+			pattern:     "GET /workouts/{id}",
+			operationID: "getWorkout",
+			summary:     "ワークアウトとセットを取得する",
+			tag:         "workouts",
+			params:      []*openapi.Parameter{uuidParam("id", "ワークアウトのID")},
+			responses:   map[int]*openapi.Response{http.StatusOK: jsonResponse[dto.WorkoutResponse](d, "ワークアウト")},
+			errors:      []int{http.StatusBadRequest, http.StatusNotFound},
+		},
+		{
+			pattern:     "PATCH /sets/{id}",
+			operationID: "updateSet",
+			summary:     "セットを更新する",
+			tag:         "workouts",
+			params:      []*openapi.Parameter{uuidParam("id", "セットのID")},
+			body:        jsonBody[dto.UpdateSetRequest](d, true),
+			responses:   map[int]*openapi.Response{http.StatusOK: jsonResponse[dto.SetView](d, "更新したセット")},
+			errors:      []int{http.StatusBadRequest, http.StatusNotFound},
+		},
+		{
+			pattern:     "GET /history",
+			operationID: "getHistory",
+			summary:     "トレーニング履歴を日・週・月ごとに集計する",
+			tag:         "workouts",
+			params: []*openapi.Parameter{
+				queryParam("interval", "集計の単位 (デフォルトは week)", stringSchema("", "day", "week", "month")),
+				fromDateParam,
+				toDateParam,
+				tzParam,
+				cursorParam,
+				limitParam,
+			},
+			responses: map[int]*openapi.Response{http.StatusOK: jsonResponse[dto.HistoryResponse](d, "期間ごとの集計")},
+			errors:    []int{http.StatusBadRequest},
+		},
+		{
+			pattern:     "GET /history/calendar",
+			operationID: "getHistoryCalendar",
+			summary:     "月のカレンダー (トレーニングした日)",
+			tag:         "workouts",
+			params: []*openapi.Parameter{
+				queryParam("month", "月 (YYYY-MM、デフォルトは今月)", stringSchema("")),
+				tzParam,
+			},
+			responses: map[int]*openapi.Response{http.StatusOK: jsonResponse[dto.HistoryCalendarResponse](d, "カレンダー")},
+			errors:    []int{http.StatusBadRequest},
+		},
+		{
+			pattern:     "POST /imports",
+			operationID: "importWorkouts",
+			summary:     "他のアプリ (Strong / Hevy / FitNotes) の CSV からワークアウト履歴を取り込む",
+			description: "dry_run の場合は取り込まずに結果を 200 で返す。",
+			tag:         "workouts",
+			body: &openapi.RequestBody{
+				Required: true,
+				Content: map[string]*openapi.MediaType{"multipart/form-data": {Schema: &openapi.Schema{
+					Type: openapi.Types{"object"},
+					Properties: map[string]*openapi.Schema{
+						"file":    {Type: openapi.Types{"string"}, Format: "binary", Description: "エクスポートした CSV"},
+						"options": {Type: openapi.Types{"string"}, Description: "ImportRequest の JSON"},
+					},
+					Required: []string{"file"},
+				}}},
+			},
+			responses: map[int]*openapi.Response{
+				http.StatusOK:      jsonResponse[dto.ImportReport](d, "取り込みの結果 (dry_run)"),
+				http.StatusCreated: jsonResponse[dto.ImportReport](d, "取り込みの結果"),
+			},
+			errors: []int{http.StatusBadRequest, http.StatusRequestEntityTooLarge},
+		},
+
+		// 種目
+		{
+			pattern:     "GET /exercises",
+			operationID: "listExercises",
+			summary:     "種目の一覧",
+			tag:         "exercises",
+			etag:        true,
+			responses:   map[int]*openapi.Response{http.StatusOK: jsonResponse[[]dto.Exercise](d, "種目の一覧")},
+		},
+
+		// 週間ボリューム
+		{
+			pattern:     "GET /v1/weekly-volume",
+			operationID: "listWeeklyVolumes",
+			summary:     "直近の週間ボリューム",
+			tag:         "volume",
+			etag:        true,
+			params:      []*openapi.Parameter{queryParam("weeks", "週数 (デフォルトは12)", integerSchema(0, 0))},
+			responses:   map[int]*openapi.Response{http.StatusOK: jsonResponse[dto.WeeklyVolumeSummaryResponse](d, "週ごとのボリューム")},
+			errors:      []int{http.StatusBadRequest},
+		},
+		{
+			pattern:     "GET /v1/weekly-volume/{week}",
+			operationID: "getWeeklyVolume",
+			summary:     "週の種目ごとのボリューム",
+			tag:         "volume",
+			etag:        true,
+			params:      []*openapi.Parameter{pathParam("week", "週の開始日時 (RFC3339)", stringSchema("date-time"))},
+			responses:   map[int]*openapi.Response{http.StatusOK: jsonResponse[dto.WeeklySummaryResponse](d, "週のボリューム")},
+			errors:      []int{http.StatusBadRequest},
+		},
+		{
+			pattern:     "GET /v1/weekly-volume/stats",
+			operationID: "getWeeklyVolumeStats",
+			summary:     "期間の週間ボリュームの統計",
+			tag:         "volume",
+			etag:        true,
+			params: []*openapi.Parameter{
+				queryParam("start_date", "期間の開始日時 (RFC3339、デフォルトは3ヶ月前)", stringSchema("date-time")),
+				queryParam("end_date", "期間の終了日時 (RFC3339、デフォルトは現在)", stringSchema("date-time")),
+			},
+			responses: map[int]*openapi.Response{http.StatusOK: jsonResponse[dto.WeeklyVolumeStatsResponse](d, "統計")},
+			errors:    []int{http.StatusBadRequest},
+		},
+		{
+			pattern:     "POST /v1/weekly-volume/recalculate",
+			operationID: "recalculateWeeklyVolume",
+			summary:     "週のボリュームを再集計する",
+			tag:         "volume",
+			body:        jsonBody[dto.RecalculateWeeklyVolumeRequest](d, true),
+			responses:   map[int]*openapi.Response{http.StatusOK: jsonResponse[dto.WeeklySummaryResponse](d, "再集計した週のボリューム")},
+			errors:      []int{http.StatusBadRequest},
+		},
+
+		// 身体計測
+		{
+			pattern:     "GET /measurement-metrics",
+			operationID: "listMeasurementMetrics",
+			summary:     "計測項目の一覧",
+			tag:         "measurements",
+			responses:   map[int]*openapi.Response{http.StatusOK: jsonResponse[[]dto.MeasurementMetric](d, "計測項目の一覧")},
+		},
+		{
+			pattern:     "GET /measurements",
+			operationID: "listMeasurements",
+			summary:     "計測値の一覧",
+			tag:         "measurements",
+			params: []*openapi.Parameter{
+				queryParam("metric", "計測項目で絞り込む", stringSchema("")),
+				fromDateParam,
+				toDateParam,
+			},
+			responses: map[int]*openapi.Response{http.StatusOK: jsonResponse[[]dto.MeasurementView](d, "計測値の一覧")},
+			errors:    []int{http.StatusBadRequest},
+		},
+		{
+			pattern:     "POST /measurements",
+			operationID: "createMeasurement",
+			summary:     "計測値を記録する",
+			tag:         "measurements",
+			body:        jsonBody[dto.CreateMeasurementRequest](d, true),
+			responses:   map[int]*openapi.Response{http.StatusCreated: jsonResponse[dto.MeasurementView](d, "記録した計測値")},
+			errors:      []int{http.StatusBadRequest, http.StatusConflict},
+		},
+		{
+			pattern:     "GET /measurements/series",
+			operationID: "getMeasurementSeries",
+			summary:     "計測値の推移 (日ごとの平均と7日移動平均、または週ごとの平均)",
+			tag:         "measurements",
+			params: []*openapi.Parameter{
+				requiredQueryParam("metric", "計測項目", stringSchema("")),
+				queryParam("interval", "集計の単位 (デフォルトは day)", stringSchema("", "day", "week")),
+				fromDateParam,
+				toDateParam,
+			},
+			responses: map[int]*openapi.Response{http.StatusOK: jsonResponse[dto.MeasurementSeriesResponse](d, "計測値の推移")},
+			errors:    []int{http.StatusBadRequest},
+		},
+		{
+			pattern:     "GET /measurements/relative-strength",
+			operationID: "getRelativeStrength",
+			summary:     "種目の相対筋力 (推定1RM / 体重) の推移",
+			tag:         "measurements",
+			params: []*openapi.Parameter{
+				requiredQueryParam("exercise_id", "種目のID", stringSchema("uuid")),
+				queryParam("weeks", "週数 (デフォルトは12)", integerSchema(0, 0)),
+			},
+			responses: map[int]*openapi.Response{http.StatusOK: jsonResponse[dto.RelativeStrengthResponse](d, "週ごとの相対筋力")},
+			errors:    []int{http.StatusBadRequest, http.StatusNotFound},
+		},
+		{
+			pattern:     "PATCH /measurements/{id}",
+			operationID: "updateMeasurement",
+			summary:     "計測値を更新する",
+			tag:         "measurements",
+			params:      []*openapi.Parameter{uuidParam("id", "計測値のID")},
+			body:        jsonBody[dto.UpdateMeasurementRequest](d, true),
+			responses:   map[int]*openapi.Response{http.StatusOK: jsonResponse[dto.MeasurementView](d, "更新した計測値")},
+			errors:      []int{http.StatusBadRequest, http.StatusNotFound},
+		},
+		{
+			pattern:     "DELETE /measurements/{id}",
+			operationID: "deleteMeasurement",
+			summary:     "計測値を削除する",
+			tag:         "measurements",
+			params:      []*openapi.Parameter{uuidParam("id", "計測値のID")},
+			responses:   map[int]*openapi.Response{http.StatusNoContent: noContent("削除した")},
+			errors:      []int{http.StatusBadRequest, http.StatusNotFound},
+		},
+		{
+			pattern:     "GET /body-weights",
+			operationID: "listBodyWeights",
+			summary:     "日ごとの体重の一覧 (互換)",
+			description: "GET /measurements?metric=body_weight の互換 API。日ごと (JST) に最新の記録を1件返す (新しい順)",
+			tag:         "measurements",
+			deprecated:  true,
+			params:      []*openapi.Parameter{fromDateParam, toDateParam},
+			responses:   map[int]*openapi.Response{http.StatusOK: jsonResponse[[]dto.BodyWeightView](d, "日ごとの体重の一覧")},
+			errors:      []int{http.StatusBadRequest},
+		},
+		{
+			pattern:     "POST /body-weights",
+			operationID: "recordBodyWeight",
+			summary:     "体重を記録する (互換)",
+			description: "POST /measurements の互換 API。同じ日 (JST) の記録がある場合は最新の記録を上書きし、ない場合はその日の 0:00 (JST) で記録する",
+			tag:         "measurements",
+			deprecated:  true,
+			body:        jsonBody[dto.RecordBodyWeightRequest](d, true),
+			responses:   map[int]*openapi.Response{http.StatusCreated: jsonResponse[dto.BodyWeightView](d, "記録した体重")},
+			errors:      []int{http.StatusBadRequest},
+		},
+		{
+			pattern:     "DELETE /body-weights/{date}",
+			operationID: "deleteBodyWeight",
+			summary:     "指定日の体重を削除する (互換)",
+			description: "DELETE /measurements/{id} の互換 API。その日 (JST) の体重の記録をすべて削除する",
+			tag:         "measurements",
+			deprecated:  true,
+			params:      []*openapi.Parameter{pathParam("date", "計測日 (YYYY-MM-DD)", stringSchema("date"))},
+			responses:   map[int]*openapi.Response{http.StatusNoContent: noContent("削除した")},
+			errors:      []int{http.StatusBadRequest, http.StatusNotFound},
+		},
+
+		// アカウント削除・データエクスポート
+		{
+			pattern:     "DELETE /me",
+			operationID: "requestAccountDeletion",
+			summary:     "アカウントの削除を予約する",
+			description: "猶予期間の後にすべてのデータを削除する。猶予期間の間は取り消せる。",
+			tag:         "account",
+			responses:   map[int]*openapi.Response{http.StatusAccepted: withLocation(jsonResponse[dto.AccountDeletionView](d, "予約した削除"))},
+		},
+		{
+			pattern:     "GET /me/deletion",
+			operationID: "getAccountDeletion",
+			summary:     "予約したアカウントの削除を取得する",
+			tag:         "account",
+			responses:   map[int]*openapi.Response{http.StatusOK: jsonResponse[dto.AccountDeletionView](d, "予約した削除")},
+			errors:      []int{http.StatusNotFound},
+		},
+		{
+			pattern:     "POST /me/deletion/cancel",
+			operationID: "cancelAccountDeletion",
+			summary:     "予約したアカウントの削除を取り消す",
+			tag:         "account",
+			responses:   map[int]*openapi.Response{http.StatusOK: jsonResponse[dto.AccountDeletionView](d, "取り消した削除")},
+			errors:      []int{http.StatusNotFound, http.StatusConflict},
+		},
+		{
+			pattern:     "GET /me/export",
+			operationID: "exportData",
+			summary:     "トレーニングデータを JSON または CSV で出力する",
+			description: "format=json は全データを ExportDocument として、format=csv は dataset ごとに1つの CSV として出力する。",
+			tag:         "account",
+			params: []*openapi.Parameter{
+				queryParam("format", "出力形式 (デフォルトは json)", stringSchema("", "json", "csv")),
+				queryParam("dataset", "CSV のデータセット (デフォルトは sets)", stringSchema("", "sets", "menus", "custom_exercises", "weekly_volumes")),
+				fromDateParam,
+				toDateParam,
+			},
+			responses: map[int]*openapi.Response{
+				http.StatusOK: {
+					Description: "エクスポートしたデータ (添付ファイル)",
+					Content: map[string]*openapi.MediaType{
+						"application/json": {Schema: schemaOf[dto.ExportDocument](d)},
+						"text/csv":         {Schema: stringSchema("")},
+					},
+				},
+			},
+			errors: []int{http.StatusBadRequest},
+		},
+		{
+			pattern:     "POST /me/exports",
+			operationID: "createExportJob",
+			summary:     "全データの ZIP アーカイブを作成するジョブを登録する",
+			description: "ボディは省略できる。",
+			tag:         "account",
+			body:        jsonBody[dto.ExportJobRequest](d, false),
+			responses:   map[int]*openapi.Response{http.StatusAccepted: withLocation(jsonResponse[dto.ExportJobView](d, "登録したジョブ"))},
+			errors:      []int{http.StatusBadRequest},
+		},
+		{
+			pattern:     "GET /me/exports/{id}",
+			operationID: "getExportJob",
+			summary:     "エクスポートジョブの状態を取得する",
+			tag:         "account",
+			params:      []*openapi.Parameter{uuidParam("id", "ジョブのID")},
+			responses:   map[int]*openapi.Response{http.StatusOK: jsonResponse[dto.ExportJobView](d, "ジョブ")},
+			errors:      []int{http.StatusBadRequest, http.StatusNotFound},
+		},
+		{
+			pattern:     "GET /me/exports/{id}/download",
+			operationID: "downloadExport",
+			summary:     "完了したエクスポートジョブの ZIP アーカイブをダウンロードする",
+			tag:         "account",
+			params:      []*openapi.Parameter{uuidParam("id", "ジョブのID")},
+			responses: map[int]*openapi.Response{
+				http.StatusOK: {
+					Description: "ZIP アーカイブ (添付ファイル)",
+					Content:     map[string]*openapi.MediaType{"application/zip": {Schema: stringSchema("binary")}},
+				},
+			},
+			errors: []int{http.StatusBadRequest, http.StatusNotFound, http.StatusConflict},
+		},
+
+		// パーソナルアクセストークン
+		{
+			pattern:     "GET /me/tokens",
+			operationID: "listPersonalAccessTokens",
+			summary:     "パーソナルアクセストークンの一覧",
+			tag:         "tokens",
+			responses:   map[int]*openapi.Response{http.StatusOK: jsonResponse[[]dto.PersonalAccessTokenView](d, "トークンの一覧 (トークン自体は含まない)")},
+		},
+		{
+			pattern:     "POST /me/tokens",
+			operationID: "createPersonalAccessToken",
+			summary:     "パーソナルアクセストークンを発行する",
+			description: "トークンはこのレスポンスでのみ返す。",
+			tag:         "tokens",
+			body:        jsonBody[dto.CreatePersonalAccessTokenRequest](d, true),
+			responses:   map[int]*openapi.Response{http.StatusCreated: jsonResponse[dto.CreatedPersonalAccessToken](d, "発行したトークン")},
+			errors:      []int{http.StatusBadRequest},
+		},
+		{
+			pattern:     "DELETE /me/tokens/{id}",
+			operationID: "revokePersonalAccessToken",
+			summary:     "パーソナルアクセストークンを失効させる",
+			tag:         "tokens",
+			params:      []*openapi.Parameter{uuidParam("id", "トークンのID")},
+			responses:   map[int]*openapi.Response{http.StatusNoContent: noContent("失効させた")},
+			errors:      []int{http.StatusBadRequest, http.StatusNotFound},
+		},
+
+		// コーチとアスリートの共有
+		{
+			pattern:     "GET /coach-grants",
+			operationID: "listCoachGrants",
+			summary:     "コーチとの共有の一覧 (アスリートとしてのものとコーチとしてのもの)",
+			tag:         "coach",
+			responses:   map[int]*openapi.Response{http.StatusOK: jsonResponse[[]dto.CoachGrantView](d, "共有の一覧")},
+		},
+		{
+			pattern:     "POST /coach-grants",
+			operationID: "createCoachInvitation",
+			summary:     "コーチを招待する",
+			tag:         "coach",
+			body:        jsonBody[dto.CreateCoachGrantRequest](d, true),
+			responses:   map[int]*openapi.Response{http.StatusCreated: jsonResponse[dto.CoachGrantView](d, "招待 (招待コードを含む)")},
+			errors:      []int{http.StatusBadRequest},
+		},
+		{
+			pattern:     "POST /coach-grants/accept",
+			operationID: "acceptCoachInvitation",
+			summary:     "コーチとして招待を受け入れる",
+			tag:         "coach",
+			body:        jsonBody[dto.AcceptCoachInvitationRequest](d, true),
+			responses:   map[int]*openapi.Response{http.StatusOK: jsonResponse[dto.CoachGrantView](d, "有効になった共有")},
+			errors:      []int{http.StatusBadRequest, http.StatusNotFound, http.StatusConflict},
+		},
+		{
+			pattern:     "DELETE /coach-grants/{id}",
+			operationID: "revokeCoachGrant",
+			summary:     "コーチとの共有を取り消す",
+			tag:         "coach",
+			params:      []*openapi.Parameter{uuidParam("id", "共有のID")},
+			responses:   map[int]*openapi.Response{http.StatusNoContent: noContent("取り消した")},
+			errors:      []int{http.StatusBadRequest, http.StatusNotFound},
+		},
+		{
+			pattern:     "GET /coach-grants/{id}/audit-logs",
+			operationID: "listCoachAuditLogs",
+			summary:     "コーチの操作の監査ログ",
+			tag:         "coach",
+			params:      []*openapi.Parameter{uuidParam("id", "共有のID")},
+			responses:   map[int]*openapi.Response{http.StatusOK: jsonResponse[[]dto.CoachAuditLogView](d, "監査ログ")},
+			errors:      []int{http.StatusBadRequest, http.StatusNotFound},
+		},
+	}
+}
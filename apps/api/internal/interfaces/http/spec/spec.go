@@ -0,0 +1,251 @@
+// Package spec は API の OpenAPI 3.1 ドキュメント (GET /openapi.json) を定義する
+//
+// 操作は routes の表に ServeMux のパターンごとに記述し、ボディのスキーマは DTO から生成する。
+// 認証方式・パーソナルアクセストークンのスコープ・コーチのアクセス (X-Athlete-ID) と共通のエラーは auth のルートの表から付け加える。
+// 表とサーバーに登録したルートが一致すること、レスポンスがドキュメントに従うことは契約テストで確認する
+package spec
+
+import (
+	"fmt"
+	"net/http"
+	"reflect"
+	"strconv"
+	"sync"
+
+	"github.com/aiirononeko/bulktrack/apps/api/internal/auth"
+	httpError "github.com/aiirononeko/bulktrack/apps/api/internal/http"
+	"github.com/aiirononeko/bulktrack/apps/api/internal/openapi"
+)
+
+// apiVersion はドキュメントの API のバージョン (互換性のない変更をした場合に上げる)
+const apiVersion = "1.0.0"
+
+// 認証方式の名前 (components の securitySchemes のキー)
+const (
+	SecurityClerk               = "clerk"
+	SecurityPersonalAccessToken = "personalAccessToken"
+)
+
+// document は生成したドキュメント (初回の Document の呼び出しで生成する)
+var document = sync.OnceValue(build)
+
+// Document は API のドキュメントを返す (すべての呼び出しで同じものを返すため、変更しないこと)
+func Document() *openapi.Document {
+	return document()
+}
+
+// route はルート (ServeMux のパターン) の操作を表す
+type route struct {
+	pattern     string
+	operationID string
+	summary     string
+	description string
+	tag         string
+	public      bool // 認証なしで呼び出せる
+	unlimited   bool // レート制限しない
+	etag        bool // ETag を返し、If-None-Match が一致する場合は 304 を返す
+	deprecated  bool // 互換のために残している (新しいクライアントは使わない)
+	params      []*openapi.Parameter
+	body        *openapi.RequestBody
+	responses   map[int]*openapi.Response // 成功のレスポンス
+	errors      []int                     // ルートに固有のエラーのステータスコード (認証のエラーは public でなければ、レート制限のエラーは unlimited でなければ付け加える)
+}
+
+var tags = []openapi.Tag{
+	{Name: "menus", Description: "トレーニングメニューと共有・テンプレート"},
+	{Name: "workouts", Description: "ワークアウト・セット・トレーニング履歴・取り込み"},
+	{Name: "exercises", Description: "種目"},
+	{Name: "volume", Description: "週間ボリューム"},
+	{Name: "measurements", Description: "身体計測と相対筋力"},
+	{Name: "account", Description: "アカウント削除とデータエクスポート"},
+	{Name: "tokens", Description: "パーソナルアクセストークン"},
+	{Name: "coach", Description: "コーチとアスリートの共有"},
+	{Name: "system", Description: "ヘルスチェック・ドキュメント・Webhook"},
+}
+
+// build はドキュメントを生成する
+func build() *openapi.Document {
+	d := openapi.New(openapi.Info{
+		Title:   "BulkTrack API",
+		Version: apiVersion,
+		Description: "BulkTrack のトレーニング記録 API。\n\n" +
+			"エラーは原則として ErrorResponse (application/json) で返す。" +
+			"認証のエラー (401) と一部の入力エラー・サーバーエラーは text/plain のメッセージで返す。\n\n" +
+			"コーチは " + auth.CoachAthleteHeader + " ヘッダーにアスリートのユーザーIDを指定して、与えられた権限の範囲でアスリートのデータにアクセスできる。",
+	})
+	d.Tags = tags
+	d.Components.SecuritySchemes[SecurityClerk] = &openapi.SecurityScheme{
+		Type:         "http",
+		Scheme:       "bearer",
+		BearerFormat: "JWT",
+		Description:  "Clerk (または JWKS_URL / JWKS_FILE で設定した発行者) のセッショントークン。すべての認証が必要な操作で使える",
+	}
+	d.Components.SecuritySchemes[SecurityPersonalAccessToken] = &openapi.SecurityScheme{
+		Type:        "http",
+		Scheme:      "bearer",
+		Description: "パーソナルアクセストークン (" + auth.PersonalAccessTokenPrefix + "...)。操作ごとに必要なスコープがあり、スコープのない操作は呼び出せない",
+	}
+
+	for _, r := range routes(d) {
+		d.AddOperation(r.pattern, r.operation(d))
+	}
+	return d
+}
+
+// operation はルートの操作を返す (認証が必要なルートには認証方式・コーチのヘッダー・共通のエラーを、レート制限するルートには 429 を付け加える)
+func (r route) operation(d *openapi.Document) *openapi.Operation {
+	op := &openapi.Operation{
+		OperationID: r.operationID,
+		Summary:     r.summary,
+		Description: r.description,
+		Tags:        []string{r.tag},
+		Parameters:  r.params,
+		RequestBody: r.body,
+		Responses:   make(map[string]*openapi.Response),
+		Deprecated:  r.deprecated,
+	}
+	for status, response := range r.responses {
+		if r.etag && status == http.StatusOK {
+			response = withETag(response)
+		}
+		op.Responses[strconv.Itoa(status)] = response
+	}
+	if r.etag {
+		op.Parameters = append(op.Parameters, &openapi.Parameter{
+			Name:        "If-None-Match",
+			In:          "header",
+			Description: "前回のレスポンスの ETag。変わっていない場合は 304 を返す",
+			Schema:      &openapi.Schema{Type: openapi.Types{"string"}},
+		})
+		op.Responses[strconv.Itoa(http.StatusNotModified)] = &openapi.Response{Description: "前回のレスポンスから変わっていない"}
+	}
+	for _, status := range r.errors {
+		op.Responses[strconv.Itoa(status)] = errorResponse(d, http.StatusText(status))
+	}
+	op.Responses["default"] = errorResponse(d, "その他のエラー")
+	if !r.unlimited {
+		tooManyRequests := errorResponse(d, "レート制限を超えた")
+		tooManyRequests.Headers = map[string]*openapi.Header{
+			"Retry-After": {Description: "再試行できるまでの秒数", Schema: &openapi.Schema{Type: openapi.Types{"integer"}}},
+		}
+		op.Responses[strconv.Itoa(http.StatusTooManyRequests)] = tooManyRequests
+	}
+	if r.public {
+		return op
+	}
+
+	op.Security = []openapi.SecurityRequirement{{SecurityClerk: []string{}}}
+	if scope, ok := auth.RequiredScope(r.pattern); ok {
+		op.Security = append(op.Security, openapi.SecurityRequirement{SecurityPersonalAccessToken: []string{scope}})
+		op.Description = appendSentence(op.Description, fmt.Sprintf("パーソナルアクセストークンでは %s のスコープが必要。", scope))
+	} else {
+		op.Description = appendSentence(op.Description, "パーソナルアクセストークンでは呼び出せない。")
+	}
+	if permission, ok := auth.RequiredCoachPermission(r.pattern); ok {
+		op.Parameters = append(op.Parameters, &openapi.Parameter{
+			Name:        auth.CoachAthleteHeader,
+			In:          "header",
+			Description: fmt.Sprintf("コーチがアクセスするアスリートのユーザーID (%s 以上の権限が必要)", permission),
+			Schema:      &openapi.Schema{Type: openapi.Types{"string"}},
+		})
+	}
+	op.Responses[strconv.Itoa(http.StatusUnauthorized)] = &openapi.Response{
+		Description: "認証トークンがない・不正・期限切れ",
+		Content:     map[string]*openapi.MediaType{"text/plain": {Schema: &openapi.Schema{Type: openapi.Types{"string"}}}},
+	}
+	op.Responses[strconv.Itoa(http.StatusForbidden)] = errorResponse(d, "パーソナルアクセストークンのスコープが足りない、またはコーチにアスリートへのアクセス権がない")
+	return op
+}
+
+// appendSentence は説明に文を付け加える
+func appendSentence(description, sentence string) string {
+	if description == "" {
+		return sentence
+	}
+	return description + "\n\n" + sentence
+}
+
+// schemaOf は T の JSON のスキーマを返す
+func schemaOf[T any](d *openapi.Document) *openapi.Schema {
+	return d.SchemaOf(reflect.TypeFor[T]())
+}
+
+// jsonBody は T の JSON のリクエストボディを返す
+func jsonBody[T any](d *openapi.Document, required bool) *openapi.RequestBody {
+	return &openapi.RequestBody{
+		Required: required,
+		Content:  map[string]*openapi.MediaType{"application/json": {Schema: schemaOf[T](d)}},
+	}
+}
+
+// jsonResponse は T の JSON のレスポンスを返す
+func jsonResponse[T any](d *openapi.Document, description string) *openapi.Response {
+	return &openapi.Response{
+		Description: description,
+		Content:     map[string]*openapi.MediaType{"application/json": {Schema: schemaOf[T](d)}},
+	}
+}
+
+// noContent はボディのないレスポンスを返す
+func noContent(description string) *openapi.Response {
+	return &openapi.Response{Description: description}
+}
+
+// withLocation はレスポンスに作成したリソースの URL (Location) のヘッダーを付け加える
+func withLocation(response *openapi.Response) *openapi.Response {
+	response.Headers = map[string]*openapi.Header{
+		"Location": {Description: "作成したリソースの URL", Schema: &openapi.Schema{Type: openapi.Types{"string"}}},
+	}
+	return response
+}
+
+// withETag はレスポンスに ETag と Cache-Control のヘッダーを付け加える
+func withETag(response *openapi.Response) *openapi.Response {
+	response.Headers = map[string]*openapi.Header{
+		"ETag":          {Description: "レスポンスのボディの ETag (If-None-Match に指定する)", Schema: &openapi.Schema{Type: openapi.Types{"string"}}},
+		"Cache-Control": {Schema: &openapi.Schema{Type: openapi.Types{"string"}}},
+	}
+	return response
+}
+
+// errorResponse はエラーのレスポンス (ErrorResponse、または text/plain のメッセージ) を返す
+func errorResponse(d *openapi.Document, description string) *openapi.Response {
+	return &openapi.Response{
+		Description: description,
+		Content: map[string]*openapi.MediaType{
+			"application/json": {Schema: schemaOf[httpError.ErrorResponse](d)},
+			"text/plain":       {Schema: &openapi.Schema{Type: openapi.Types{"string"}}},
+		},
+	}
+}
+
+// pathParam はパスのパラメーターを返す
+func pathParam(name, description string, schema *openapi.Schema) *openapi.Parameter {
+	return &openapi.Parameter{Name: name, In: "path", Description: description, Required: true, Schema: schema}
+}
+
+// queryParam はクエリのパラメーターを返す
+func queryParam(name, description string, schema *openapi.Schema) *openapi.Parameter {
+	return &openapi.Parameter{Name: name, In: "query", Description: description, Schema: schema}
+}
+
+// requiredQueryParam は必須のクエリのパラメーターを返す
+func requiredQueryParam(name, description string, schema *openapi.Schema) *openapi.Parameter {
+	p := queryParam(name, description, schema)
+	p.Required = true
+	return p
+}
+
+// stringSchema は文字列のスキーマを返す (format と enum は省略できる)
+func stringSchema(format string, enum ...string) *openapi.Schema {
+	return &openapi.Schema{Type: openapi.Types{"string"}, Format: format, Enum: enum}
+}
+
+// integerSchema は min から max (0 の場合は上限なし) の整数のスキーマを返す
+func integerSchema(min, max float64) *openapi.Schema {
+	s := &openapi.Schema{Type: openapi.Types{"integer"}, Minimum: &min}
+	if max > 0 {
+		s.Maximum = &max
+	}
+	return s
+}
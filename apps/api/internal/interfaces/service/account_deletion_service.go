@@ -0,0 +1,281 @@
+package service
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log/slog"
+	"time"
+
+	httpError "github.com/aiirononeko/bulktrack/apps/api/internal/http"
+	"github.com/aiirononeko/bulktrack/apps/api/internal/infrastructure/sqlc"
+	"github.com/aiirononeko/bulktrack/apps/api/internal/interfaces/http/dto"
+	"github.com/aiirononeko/bulktrack/apps/api/internal/jobs"
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgtype"
+	"github.com/jackc/pgx/v5/pgxpool"
+	"go.opentelemetry.io/otel/attribute"
+)
+
+// AccountDeletionGracePeriod はアカウント削除の予約から実際に削除するまでの期間 (この間は取り消せる)
+const AccountDeletionGracePeriod = 30 * 24 * time.Hour
+
+// アカウント削除の依頼元
+const (
+	AccountDeletionSourceAPI          = "api"           // DELETE /me
+	AccountDeletionSourceClerkWebhook = "clerk_webhook" // Clerk の user.deleted Webhook
+)
+
+// 削除期限を過ぎた予約のデータ削除の定期実行
+const (
+	accountDeletionPurgeInterval   = time.Hour
+	accountDeletionPurgeJobTimeout = 30 * time.Minute
+)
+
+// AccountDeletionService はアカウント削除 (Right-to-Delete) を提供する
+type AccountDeletionService struct {
+	pool    *pgxpool.Pool
+	queries *sqlc.Queries
+	logger  *slog.Logger
+}
+
+// NewAccountDeletionService は新しい AccountDeletionService を作成する
+func NewAccountDeletionService(pool *pgxpool.Pool, logger *slog.Logger) *AccountDeletionService {
+	return &AccountDeletionService{
+		pool:    pool,
+		queries: sqlc.New(pool),
+		logger:  logger,
+	}
+}
+
+// RequestDeletion はアカウントの削除を予約する
+// 猶予期間 (30日) の後にユーザーのすべてのデータを削除する。既に予約済みの場合はその予約を返す
+func (s *AccountDeletionService) RequestDeletion(ctx context.Context, userID, source string) (*dto.AccountDeletionView, error) {
+	ctx, span := startSpan(ctx, "AccountDeletionService.RequestDeletion", attribute.String("user_id", userID), attribute.String("source", source))
+	defer span.End()
+
+	pending, err := s.queries.GetPendingAccountDeletion(ctx, userID)
+	if err == nil {
+		view := toAccountDeletionView(sqlc.CreateAccountDeletionRow(pending))
+		return &view, nil
+	}
+	if !errors.Is(err, pgx.ErrNoRows) {
+		s.logger.ErrorContext(ctx, "Failed to execute GetPendingAccountDeletion query", slog.Any("error", err), slog.String("user_id", userID))
+		return nil, fmt.Errorf("failed to get account deletion: %w", err)
+	}
+
+	deletion, err := s.queries.CreateAccountDeletion(ctx, sqlc.CreateAccountDeletionParams{
+		UserID:       userID,
+		Source:       source,
+		ScheduledFor: pgtype.Timestamptz{Time: time.Now().Add(AccountDeletionGracePeriod), Valid: true},
+	})
+	if err != nil {
+		s.logger.ErrorContext(ctx, "Failed to execute CreateAccountDeletion query", slog.Any("error", err), slog.String("user_id", userID))
+		return nil, fmt.Errorf("failed to schedule account deletion: %w", err)
+	}
+
+	s.logger.InfoContext(ctx, "Scheduled account deletion", slog.String("user_id", userID), slog.String("deletion_id", deletion.ID.String()), slog.String("source", source), slog.Time("scheduled_for", deletion.ScheduledFor))
+	view := toAccountDeletionView(deletion)
+	return &view, nil
+}
+
+// GetDeletion は予約中のアカウント削除を取得する
+func (s *AccountDeletionService) GetDeletion(ctx context.Context, userID string) (*dto.AccountDeletionView, error) {
+	ctx, span := startSpan(ctx, "AccountDeletionService.GetDeletion", attribute.String("user_id", userID))
+	defer span.End()
+
+	pending, err := s.queries.GetPendingAccountDeletion(ctx, userID)
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return nil, httpError.NewNotFoundError("Account deletion is not scheduled", err)
+		}
+		s.logger.ErrorContext(ctx, "Failed to execute GetPendingAccountDeletion query", slog.Any("error", err), slog.String("user_id", userID))
+		return nil, fmt.Errorf("failed to get account deletion: %w", err)
+	}
+	view := toAccountDeletionView(sqlc.CreateAccountDeletionRow(pending))
+	return &view, nil
+}
+
+// CancelDeletion は予約中のアカウント削除を取り消す
+func (s *AccountDeletionService) CancelDeletion(ctx context.Context, userID string) (*dto.AccountDeletionView, error) {
+	ctx, span := startSpan(ctx, "AccountDeletionService.CancelDeletion", attribute.String("user_id", userID))
+	defer span.End()
+
+	cancelled, err := s.queries.CancelAccountDeletion(ctx, userID)
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return nil, httpError.NewNotFoundError("Account deletion is not scheduled", err)
+		}
+		s.logger.ErrorContext(ctx, "Failed to execute CancelAccountDeletion query", slog.Any("error", err), slog.String("user_id", userID))
+		return nil, fmt.Errorf("failed to cancel account deletion: %w", err)
+	}
+
+	s.logger.InfoContext(ctx, "Cancelled account deletion", slog.String("user_id", userID), slog.String("deletion_id", cancelled.ID.String()))
+	view := toAccountDeletionView(sqlc.CreateAccountDeletionRow(cancelled))
+	return &view, nil
+}
+
+// AccountDeletionPurgeArgs は削除期限を過ぎた予約のデータを削除する定期実行のジョブの引数
+type AccountDeletionPurgeArgs struct{}
+
+// Kind はジョブの種類を返す
+func (AccountDeletionPurgeArgs) Kind() string { return "account_deletion.purge" }
+
+// RegisterJobs は削除期限を過ぎた予約のデータを accountDeletionPurgeInterval ごとに削除する定期実行のジョブを登録する
+func (s *AccountDeletionService) RegisterJobs(runner *jobs.Runner) {
+	jobs.Register(runner, s.runPurgeJob, jobs.HandlerOptions{Timeout: accountDeletionPurgeJobTimeout})
+	runner.AddPeriodic(jobs.PeriodicJob{
+		Name:     "account_deletion.purge",
+		Schedule: jobs.Every(accountDeletionPurgeInterval),
+		Args:     AccountDeletionPurgeArgs{},
+		// 失敗した場合は次の実行日時に削除し直すため再試行しない (削除済みの予約は読み飛ばす)
+		Options: &jobs.InsertOptions{MaxAttempts: 1},
+	})
+}
+
+// runPurgeJob は削除期限を過ぎた予約のデータを削除する
+func (s *AccountDeletionService) runPurgeJob(ctx context.Context, _ *jobs.Job[AccountDeletionPurgeArgs]) error {
+	purged, err := s.PurgeDueDeletions(ctx)
+	if err != nil {
+		s.logger.ErrorContext(ctx, "Failed to purge deleted accounts", slog.Any("error", err), slog.Int("purged", purged))
+		return err
+	}
+	if purged > 0 {
+		s.logger.InfoContext(ctx, "Purged deleted accounts", slog.Int("purged", purged))
+	}
+	return nil
+}
+
+// PurgeDueDeletions は削除期限を過ぎた予約のユーザーのデータをすべて削除し、削除したアカウント数を返す
+// 複数のインスタンスで同時に実行しても同じ予約を重複して処理しない
+func (s *AccountDeletionService) PurgeDueDeletions(ctx context.Context) (int, error) {
+	ctx, span := startSpan(ctx, "AccountDeletionService.PurgeDueDeletions")
+	defer span.End()
+
+	purged := 0
+	for {
+		done, err := s.purgeNext(ctx)
+		if err != nil {
+			return purged, err
+		}
+		if done {
+			return purged, nil
+		}
+		purged++
+	}
+}
+
+// CountDueDeletions は削除期限を過ぎた (次の PurgeDueDeletions で削除される) 予約の件数を返す
+func (s *AccountDeletionService) CountDueDeletions(ctx context.Context) (int64, error) {
+	count, err := s.queries.CountDueAccountDeletions(ctx)
+	if err != nil {
+		s.logger.ErrorContext(ctx, "Failed to execute CountDueAccountDeletions query", slog.Any("error", err))
+		return 0, fmt.Errorf("failed to count due account deletions: %w", err)
+	}
+	return count, nil
+}
+
+// purgeNext は削除期限を過ぎた予約を1件処理する (処理する予約がない場合は done が true)
+func (s *AccountDeletionService) purgeNext(ctx context.Context) (done bool, err error) {
+	tx, err := s.pool.Begin(ctx)
+	if err != nil {
+		s.logger.ErrorContext(ctx, "Failed to begin transaction for PurgeAccount", slog.Any("error", err))
+		return false, err
+	}
+	defer func() {
+		if r := recover(); r != nil {
+			s.logger.ErrorContext(ctx, "Recovered in PurgeAccount, rolling back transaction", slog.Any("panic_value", r))
+			tx.Rollback(ctx)
+			panic(r)
+		} else if err != nil || done {
+			rollErr := tx.Rollback(ctx)
+			if rollErr != nil && err != nil {
+				s.logger.ErrorContext(ctx, "Failed to rollback transaction for PurgeAccount", slog.Any("rollback_error", rollErr), slog.Any("original_error", err))
+			}
+		}
+	}()
+
+	qtx := sqlc.New(tx)
+
+	deletion, err := qtx.ClaimDueAccountDeletion(ctx)
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return true, nil
+		}
+		s.logger.ErrorContext(ctx, "Failed to execute ClaimDueAccountDeletion query", slog.Any("error", err))
+		return false, fmt.Errorf("failed to claim account deletion: %w", err)
+	}
+	userID := deletion.UserID.String
+	logger := s.logger.With(slog.String("deletion_id", deletion.ID.String()), slog.String("user_id", userID))
+
+	// ユーザーの週次ボリュームと集計キューもすべて削除するため、削除した週を集計キューに追加しない
+	if err = qtx.SkipWeeklyVolumeTrigger(ctx); err != nil {
+		logger.ErrorContext(ctx, "Failed to execute SkipWeeklyVolumeTrigger query", slog.Any("error", err))
+		return false, fmt.Errorf("failed to disable weekly volume trigger: %w", err)
+	}
+
+	// 参照 (ON DELETE RESTRICT) の順に削除する: セット → ワークアウト → メニュー (メニュー項目) → カスタム種目
+	purges := []struct {
+		name  string
+		purge func(context.Context, string) (int64, error)
+	}{
+		{"sets", qtx.PurgeUserSets},
+		{"workouts", qtx.PurgeUserWorkouts},
+		{"menus", qtx.PurgeUserMenus},
+		{"custom_exercises", qtx.PurgeUserCustomExercises},
+		{"anonymized_custom_exercises", qtx.AnonymizeUserCustomExercises}, // 他のユーザーのデータから参照されているもの
+		{"body_measurements", qtx.PurgeUserBodyMeasurements},
+		{"export_jobs", qtx.PurgeUserExportJobs},
+		{"jobs", qtx.PurgeUserJobs}, // エクスポートなどのバックグラウンドジョブの引数にユーザーIDが残る
+		{"weekly_volumes", qtx.PurgeUserWeeklyVolumes},
+		{"weekly_volume_dirty_weeks", qtx.PurgeUserWeeklyVolumeDirtyWeeks},
+		{"user_settings", qtx.PurgeUserSettings},
+		{"personal_access_tokens", qtx.PurgeUserPersonalAccessTokens},
+		{"coach_grants", qtx.PurgeUserCoachGrants},
+		{"anonymized_webhook_events", qtx.AnonymizeUserWebhookEvents},
+	}
+	counts := make(map[string]int64, len(purges))
+	for _, p := range purges {
+		var n int64
+		if n, err = p.purge(ctx, userID); err != nil {
+			logger.ErrorContext(ctx, "Failed to purge user data", slog.Any("error", err), slog.String("table", p.name))
+			return false, fmt.Errorf("failed to purge %s: %w", p.name, err)
+		}
+		counts[p.name] = n
+	}
+
+	purgedCounts, err := json.Marshal(counts)
+	if err != nil {
+		return false, fmt.Errorf("failed to marshal purged counts: %w", err)
+	}
+	if err = qtx.CompleteAccountDeletion(ctx, sqlc.CompleteAccountDeletionParams{PurgedCounts: purgedCounts, ID: deletion.ID}); err != nil {
+		logger.ErrorContext(ctx, "Failed to execute CompleteAccountDeletion query", slog.Any("error", err))
+		return false, fmt.Errorf("failed to complete account deletion: %w", err)
+	}
+
+	if err = tx.Commit(ctx); err != nil {
+		logger.ErrorContext(ctx, "Failed to commit transaction for PurgeAccount", slog.Any("error", err))
+		return false, fmt.Errorf("failed to commit account purge: %w", err)
+	}
+	if counts["custom_exercises"] > 0 || counts["anonymized_custom_exercises"] > 0 {
+		invalidateExerciseCatalog(ctx)
+	}
+
+	// 完了後のログには削除件数のみ出力する
+	s.logger.InfoContext(ctx, "Completed account deletion", slog.String("deletion_id", deletion.ID.String()), slog.Any("purged_counts", counts))
+	return false, nil
+}
+
+// toAccountDeletionView は sqlc の型をレスポンスの型に変換する
+func toAccountDeletionView(deletion sqlc.CreateAccountDeletionRow) dto.AccountDeletionView {
+	return dto.AccountDeletionView{
+		ID:           deletion.ID,
+		Status:       deletion.Status,
+		Source:       deletion.Source,
+		RequestedAt:  deletion.RequestedAt.Format(time.RFC3339),
+		ScheduledFor: deletion.ScheduledFor.Format(time.RFC3339),
+		CancelledAt:  pgtypeTimestamptzToPtrString(deletion.CancelledAt),
+		CompletedAt:  pgtypeTimestamptzToPtrString(deletion.CompletedAt),
+	}
+}
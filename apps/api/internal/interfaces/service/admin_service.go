@@ -0,0 +1,685 @@
+package service
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"log/slog"
+	"slices"
+	"sort"
+	"strings"
+	"time"
+
+	seeddata "github.com/aiirononeko/bulktrack/apps/api/internal/infrastructure/db"
+	"github.com/aiirononeko/bulktrack/apps/api/internal/infrastructure/sqlc"
+	"github.com/aiirononeko/bulktrack/apps/api/internal/interfaces/http/dto"
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5/pgtype"
+	"github.com/jackc/pgx/v5/pgxpool"
+	"go.opentelemetry.io/otel/attribute"
+)
+
+// DefaultVolumeBackfillBatchSize は週間ボリュームの一括再計算で1つのトランザクションに含めるユーザー週の数
+const DefaultVolumeBackfillBatchSize = 500
+
+// AdminService は運用者向けの管理コマンド (bulktrack-admin) の処理を提供する
+type AdminService struct {
+	pool    *pgxpool.Pool
+	queries *sqlc.Queries
+	logger  *slog.Logger
+}
+
+// NewAdminService は新しい AdminService を作成する
+func NewAdminService(pool *pgxpool.Pool, logger *slog.Logger) *AdminService {
+	return &AdminService{
+		pool:    pool,
+		queries: sqlc.New(pool),
+		logger:  logger,
+	}
+}
+
+// VolumeBackfillOptions は週間ボリュームの一括再計算の条件を表す
+type VolumeBackfillOptions struct {
+	From      string // 開始日 (YYYY-MM-DD, JST)。この日を含む週から再計算する
+	To        string // 終了日 (YYYY-MM-DD, JST)。この日を含む週まで再計算する
+	UserID    string // 空の場合は全ユーザー
+	BatchSize int    // 0 以下の場合は DefaultVolumeBackfillBatchSize
+	DryRun    bool   // 対象の件数のみ数え、再計算しない
+}
+
+// VolumeBackfillProgress は週間ボリュームの一括再計算の進捗を表す
+type VolumeBackfillProgress struct {
+	Total int64 // 対象のユーザー週 (期間内にワークアウトか weekly_volumes の行がある週) の数
+	Done  int64 // 再計算したユーザー週の数
+}
+
+// BackfillWeeklyVolumes は期間内の週間ボリュームをユーザー週ごとに再計算する
+// BatchSize 件ごとに1つのトランザクションで再計算してコミットし、progress を呼び出す (中断した場合は再実行すればよい)
+func (s *AdminService) BackfillWeeklyVolumes(ctx context.Context, opts VolumeBackfillOptions, progress func(VolumeBackfillProgress)) (VolumeBackfillProgress, error) {
+	ctx, span := startSpan(ctx, "AdminService.BackfillWeeklyVolumes", attribute.String("user_id", opts.UserID), attribute.Bool("dry_run", opts.DryRun))
+	defer span.End()
+
+	var result VolumeBackfillProgress
+	from, err := parseDate("from", opts.From)
+	if err != nil {
+		return result, err
+	}
+	to, err := parseDate("to", opts.To)
+	if err != nil {
+		return result, err
+	}
+	if from.After(to) {
+		return result, fmt.Errorf("from (%s) must be on or before to (%s)", opts.From, opts.To)
+	}
+	batchSize := opts.BatchSize
+	if batchSize <= 0 {
+		batchSize = DefaultVolumeBackfillBatchSize
+	}
+
+	fromWeek := truncateToPeriod(from, HistoryIntervalWeek)
+	toWeek := truncateToPeriod(to, HistoryIntervalWeek)
+	userID := pgtype.Text{String: opts.UserID, Valid: opts.UserID != ""}
+
+	result.Total, err = s.queries.CountVolumeBackfillWeeks(ctx, sqlc.CountVolumeBackfillWeeksParams{
+		FromTime: pgtype.Timestamptz{Time: fromWeek, Valid: true},
+		ToTime:   pgtype.Timestamptz{Time: toWeek.AddDate(0, 0, 7), Valid: true},
+		UserID:   userID,
+		FromWeek: pgtype.Date{Time: fromWeek, Valid: true},
+		ToWeek:   pgtype.Date{Time: toWeek, Valid: true},
+	})
+	if err != nil {
+		s.logger.ErrorContext(ctx, "Failed to execute CountVolumeBackfillWeeks query", slog.Any("error", err))
+		return result, fmt.Errorf("failed to count weeks to backfill: %w", err)
+	}
+	if opts.DryRun || result.Total == 0 {
+		return result, nil
+	}
+
+	params := sqlc.ListVolumeBackfillWeeksParams{
+		FromTime:  pgtype.Timestamptz{Time: fromWeek, Valid: true},
+		ToTime:    pgtype.Timestamptz{Time: toWeek.AddDate(0, 0, 7), Valid: true},
+		UserID:    userID,
+		FromWeek:  pgtype.Date{Time: fromWeek, Valid: true},
+		ToWeek:    pgtype.Date{Time: toWeek, Valid: true},
+		PageLimit: int32(batchSize),
+	}
+	for {
+		weeks, err := s.queries.ListVolumeBackfillWeeks(ctx, params)
+		if err != nil {
+			s.logger.ErrorContext(ctx, "Failed to execute ListVolumeBackfillWeeks query", slog.Any("error", err))
+			return result, fmt.Errorf("failed to list weeks to backfill: %w", err)
+		}
+		if len(weeks) == 0 {
+			return result, nil
+		}
+		if err := s.recalculateWeeks(ctx, weeks); err != nil {
+			return result, err
+		}
+		result.Done += int64(len(weeks))
+		if progress != nil {
+			progress(result)
+		}
+		if len(weeks) < batchSize {
+			return result, nil
+		}
+
+		last := weeks[len(weeks)-1]
+		params.CursorUserID = pgtype.Text{String: last.UserID, Valid: true}
+		params.CursorWeekStartDate = last.WeekStartDate
+	}
+}
+
+// recalculateWeeks はユーザー週の週間ボリュームを1つのトランザクションで再計算する
+func (s *AdminService) recalculateWeeks(ctx context.Context, weeks []sqlc.ListVolumeBackfillWeeksRow) (err error) {
+	tx, err := s.pool.Begin(ctx)
+	if err != nil {
+		s.logger.ErrorContext(ctx, "Failed to begin transaction for BackfillWeeklyVolumes", slog.Any("error", err))
+		return err
+	}
+	defer func() {
+		if err != nil {
+			if rollErr := tx.Rollback(ctx); rollErr != nil {
+				s.logger.ErrorContext(ctx, "Failed to rollback transaction for BackfillWeeklyVolumes", slog.Any("rollback_error", rollErr), slog.Any("original_error", err))
+			}
+		}
+	}()
+
+	qtx := sqlc.New(tx)
+	for _, week := range weeks {
+		if err = qtx.RecalculateWeeklyVolume(ctx, sqlc.RecalculateWeeklyVolumeParams{
+			UserID:        week.UserID,
+			WeekStartDate: week.WeekStartDate,
+		}); err != nil {
+			s.logger.ErrorContext(ctx, "Failed to execute RecalculateWeeklyVolume query", slog.Any("error", err), slog.String("user_id", week.UserID), slog.String("week_start_date", week.WeekStartDate.Time.Format(dateLayout)))
+			return fmt.Errorf("failed to recalculate weekly volume: %w", err)
+		}
+	}
+	if err = tx.Commit(ctx); err != nil {
+		s.logger.ErrorContext(ctx, "Failed to commit transaction for BackfillWeeklyVolumes", slog.Any("error", err))
+		return fmt.Errorf("failed to commit weekly volume backfill: %w", err)
+	}
+	// weeks はユーザーの順のため、続くユーザー週が同じユーザーの場合は1度だけ無効にする
+	for i, week := range weeks {
+		if i == 0 || weeks[i-1].UserID != week.UserID {
+			responseCache.Invalidate(ctx, volumeCacheScope(week.UserID))
+		}
+	}
+	volumeRecalculations.Add(ctx, int64(len(weeks)), sourceAttr("admin"))
+	return nil
+}
+
+// IntegrityCheck はデータの整合性チェック1つの結果を表す
+type IntegrityCheck struct {
+	Name        string `json:"name"`
+	Description string `json:"description"`
+	IssueCount  int64  `json:"issue_count"`
+}
+
+// integrityCheckDescriptions は整合性チェック (ListIntegrityIssueCounts の check_name) の説明
+var integrityCheckDescriptions = map[string]string{
+	"workouts_without_started_at":         "started_at が NULL のワークアウト (エクスポートのカーソルにできない)",
+	"sets_without_workout_or_exercise":    "workout_id か exercise_id が NULL のセット",
+	"sets_with_other_users_exercise":      "他のユーザーのカスタム種目を参照するセット",
+	"sets_with_invalid_metrics":           "種目の記録指標 (metric_type) に合わない値のセット",
+	"menu_items_without_menu_or_exercise": "menu_id か exercise_id が NULL のメニュー項目",
+	"weekly_volumes_missing":              "集計対象のセットがあるのに weekly_volumes がない (またはセット数が 0 の) ユーザー週",
+	"weekly_volumes_without_workouts":     "ワークアウトがないのにセット数が 1 以上の weekly_volumes",
+	"overdue_account_deletions":           "削除期限を1日以上過ぎても削除されていないアカウント削除の予約",
+}
+
+// CheckIntegrity はスキーマの制約では保証されないデータの整合性を確認する (userID が空の場合は全ユーザー)
+func (s *AdminService) CheckIntegrity(ctx context.Context, userID string) ([]IntegrityCheck, error) {
+	ctx, span := startSpan(ctx, "AdminService.CheckIntegrity", attribute.String("user_id", userID))
+	defer span.End()
+
+	rows, err := s.queries.ListIntegrityIssueCounts(ctx, pgtype.Text{String: userID, Valid: userID != ""})
+	if err != nil {
+		s.logger.ErrorContext(ctx, "Failed to execute ListIntegrityIssueCounts query", slog.Any("error", err), slog.String("user_id", userID))
+		return nil, fmt.Errorf("failed to check integrity: %w", err)
+	}
+	checks := make([]IntegrityCheck, 0, len(rows))
+	for _, row := range rows {
+		checks = append(checks, IntegrityCheck{
+			Name:        row.CheckName,
+			Description: integrityCheckDescriptions[row.CheckName],
+			IssueCount:  row.IssueCount,
+		})
+	}
+	return checks, nil
+}
+
+// UserDataSummary はユーザーのデータの件数を表す (管理コマンドでの確認用)
+type UserDataSummary struct {
+	UserID                string  `json:"user_id"`
+	WorkoutCount          int64   `json:"workout_count"`
+	SetCount              int64   `json:"set_count"`
+	FirstWorkoutAt        *string `json:"first_workout_at"` // RFC3339
+	LastWorkoutAt         *string `json:"last_workout_at"`  // RFC3339
+	MenuCount             int64   `json:"menu_count"`
+	CustomExerciseCount   int64   `json:"custom_exercise_count"`
+	BodyMeasurementCount  int64   `json:"body_measurement_count"`
+	WeeklyVolumeCount     int64   `json:"weekly_volume_count"`
+	ExportJobCount        int64   `json:"export_job_count"`
+	ActiveTokenCount      int64   `json:"active_token_count"`
+	ActiveCoachGrantCount int64   `json:"active_coach_grant_count"`
+	HasSettings           bool    `json:"has_settings"`
+	DeletionScheduledFor  *string `json:"deletion_scheduled_for"` // 予約中のアカウント削除の削除予定日時 (RFC3339)
+}
+
+// GetUserDataSummary はユーザーのデータの件数を取得する
+func (s *AdminService) GetUserDataSummary(ctx context.Context, userID string) (*UserDataSummary, error) {
+	ctx, span := startSpan(ctx, "AdminService.GetUserDataSummary", attribute.String("user_id", userID))
+	defer span.End()
+
+	row, err := s.queries.GetUserDataSummary(ctx, userID)
+	if err != nil {
+		s.logger.ErrorContext(ctx, "Failed to execute GetUserDataSummary query", slog.Any("error", err), slog.String("user_id", userID))
+		return nil, fmt.Errorf("failed to get user data summary: %w", err)
+	}
+	return &UserDataSummary{
+		UserID:                userID,
+		WorkoutCount:          row.WorkoutCount,
+		SetCount:              row.SetCount,
+		FirstWorkoutAt:        pgtypeTimestamptzToPtrString(row.FirstWorkoutAt),
+		LastWorkoutAt:         pgtypeTimestamptzToPtrString(row.LastWorkoutAt),
+		MenuCount:             row.MenuCount,
+		CustomExerciseCount:   row.CustomExerciseCount,
+		BodyMeasurementCount:  row.BodyMeasurementCount,
+		WeeklyVolumeCount:     row.WeeklyVolumeCount,
+		ExportJobCount:        row.ExportJobCount,
+		ActiveTokenCount:      row.ActiveTokenCount,
+		ActiveCoachGrantCount: row.ActiveCoachGrantCount,
+		HasSettings:           row.HasSettings,
+		DeletionScheduledFor:  pgtypeTimestamptzToPtrString(row.DeletionScheduledFor),
+	}, nil
+}
+
+// seedCountQueries は初期データの適用で追加した行数を数えるテーブルとクエリ
+var seedCountQueries = []struct {
+	table string
+	query string
+}{
+	{"exercises", "SELECT COUNT(*) FROM exercises WHERE created_by_user_id IS NULL"},
+	{"muscle_groups", "SELECT COUNT(*) FROM muscle_groups"},
+	{"exercise_target_muscle_groups", "SELECT COUNT(*) FROM exercise_target_muscle_groups"},
+	{"measurement_metrics", "SELECT COUNT(*) FROM measurement_metrics"},
+	{"template_menus", "SELECT COUNT(*) FROM menus WHERE user_id = 'bulktrack'"},
+}
+
+// Seed は組み込み種目・部位・計測項目・テンプレートメニューの初期データを適用し、テーブルごとに追加した行数を返す
+// 既にある行は追加しないため、何度実行してもよい。dryRun の場合は適用した結果をロールバックする
+func (s *AdminService) Seed(ctx context.Context, dryRun bool) (added map[string]int64, err error) {
+	ctx, span := startSpan(ctx, "AdminService.Seed", attribute.Bool("dry_run", dryRun))
+	defer span.End()
+
+	tx, err := s.pool.Begin(ctx)
+	if err != nil {
+		s.logger.ErrorContext(ctx, "Failed to begin transaction for Seed", slog.Any("error", err))
+		return nil, err
+	}
+	defer func() {
+		if err != nil || dryRun {
+			if rollErr := tx.Rollback(ctx); rollErr != nil && err != nil {
+				s.logger.ErrorContext(ctx, "Failed to rollback transaction for Seed", slog.Any("rollback_error", rollErr), slog.Any("original_error", err))
+			}
+		}
+	}()
+
+	counts := func() (map[string]int64, error) {
+		result := make(map[string]int64, len(seedCountQueries))
+		for _, q := range seedCountQueries {
+			var n int64
+			if err := tx.QueryRow(ctx, q.query).Scan(&n); err != nil {
+				return nil, fmt.Errorf("failed to count %s: %w", q.table, err)
+			}
+			result[q.table] = n
+		}
+		return result, nil
+	}
+
+	before, err := counts()
+	if err != nil {
+		return nil, err
+	}
+	// 引数なしの Exec は simple protocol で実行されるため、複数の文を含む SQL をそのまま実行できる
+	if _, err = tx.Exec(ctx, seeddata.SeedSQL); err != nil {
+		s.logger.ErrorContext(ctx, "Failed to apply seed data", slog.Any("error", err))
+		return nil, fmt.Errorf("failed to apply seed data: %w", err)
+	}
+	after, err := counts()
+	if err != nil {
+		return nil, err
+	}
+	added = make(map[string]int64, len(after))
+	for table, n := range after {
+		added[table] = n - before[table]
+	}
+
+	if dryRun {
+		return added, nil
+	}
+	if err = tx.Commit(ctx); err != nil {
+		s.logger.ErrorContext(ctx, "Failed to commit transaction for Seed", slog.Any("error", err))
+		return nil, fmt.Errorf("failed to commit seed data: %w", err)
+	}
+	invalidateExerciseCatalog(ctx) // 件数が変わらなくても既存の種目の負荷・記録の種類を更新する
+	s.logger.InfoContext(ctx, "Applied seed data", slog.Any("added", added))
+	return added, nil
+}
+
+// UserImportResult はユーザーデータの取り込み結果を表す
+type UserImportResult struct {
+	DryRun            bool `json:"dry_run"`
+	CreatedExercises  int  `json:"created_exercises"`
+	CreatedMenus      int  `json:"created_menus"`
+	SkippedMenus      int  `json:"skipped_menus"` // 同じ名前のメニューが既にあるもの
+	ImportedWorkouts  int  `json:"imported_workouts"`
+	SkippedWorkouts   int  `json:"skipped_workouts"` // 同じ開始日時のワークアウトが既にあるもの
+	ImportedSets      int  `json:"imported_sets"`
+	RecalculatedWeeks int  `json:"recalculated_weeks"`
+}
+
+// ImportUserData は JSON エクスポート (dto.ExportDocument) をユーザーのデータとして取り込む
+// 別の環境からのユーザーの移行や、エクスポートからの復元に使う
+//
+//   - カスタム種目は同じ名前のものがあれば再利用し、なければ作成する
+//   - 種目は ID (同じデータベースの場合) → 組み込み種目の名前 → カスタム種目の名前 の順に解決する (解決できない場合は取り込まない)
+//   - 同じ名前のメニュー、同じ開始日時のワークアウトが既にある場合は読み飛ばす
+//   - 週間ボリュームはエクスポートの値を使わず、取り込んだ週を再計算する
+//
+// すべて1つのトランザクションで保存し、dryRun の場合はロールバックして取り込み結果のみ返す
+func (s *AdminService) ImportUserData(ctx context.Context, userID string, r io.Reader, dryRun bool) (result *UserImportResult, err error) {
+	ctx, span := startSpan(ctx, "AdminService.ImportUserData", attribute.String("user_id", userID), attribute.Bool("dry_run", dryRun))
+	defer span.End()
+
+	var doc dto.ExportDocument
+	if err := json.NewDecoder(r).Decode(&doc); err != nil {
+		return nil, fmt.Errorf("failed to decode export: %w", err)
+	}
+
+	tx, err := s.pool.Begin(ctx)
+	if err != nil {
+		s.logger.ErrorContext(ctx, "Failed to begin transaction for ImportUserData", slog.Any("error", err), slog.String("user_id", userID))
+		return nil, err
+	}
+	defer func() {
+		if err != nil || dryRun {
+			if rollErr := tx.Rollback(ctx); rollErr != nil && err != nil {
+				s.logger.ErrorContext(ctx, "Failed to rollback transaction for ImportUserData", slog.Any("rollback_error", rollErr), slog.Any("original_error", err), slog.String("user_id", userID))
+			}
+		}
+	}()
+
+	qtx := sqlc.New(tx)
+	result = &UserImportResult{DryRun: dryRun}
+
+	// 集計キューには入れず、最後に取り込んだ週をまとめて再集計する
+	if err = qtx.SkipWeeklyVolumeTrigger(ctx); err != nil {
+		s.logger.ErrorContext(ctx, "Failed to execute SkipWeeklyVolumeTrigger query", slog.Any("error", err), slog.String("user_id", userID))
+		return nil, fmt.Errorf("failed to disable weekly volume trigger: %w", err)
+	}
+
+	resolver, err := s.importExercises(ctx, qtx, userID, doc.CustomExercises, result)
+	if err != nil {
+		return nil, err
+	}
+	if missing := resolver.missing(doc); len(missing) > 0 {
+		return nil, fmt.Errorf("exercises not found: %s", strings.Join(missing, ", "))
+	}
+
+	menuIDs, err := s.importMenus(ctx, qtx, userID, doc.Menus, resolver, result)
+	if err != nil {
+		return nil, err
+	}
+
+	weekStarts, err := s.importWorkouts(ctx, qtx, userID, doc.Workouts, resolver, menuIDs, result)
+	if err != nil {
+		return nil, err
+	}
+
+	for _, week := range weekStarts {
+		if err = qtx.RecalculateWeeklyVolume(ctx, sqlc.RecalculateWeeklyVolumeParams{
+			UserID:        userID,
+			WeekStartDate: pgtype.Date{Time: week, Valid: true},
+		}); err != nil {
+			s.logger.ErrorContext(ctx, "Failed to execute RecalculateWeeklyVolume query", slog.Any("error", err), slog.String("user_id", userID), slog.String("week_start_date", week.Format(dateLayout)))
+			return nil, fmt.Errorf("failed to recalculate weekly volume: %w", err)
+		}
+	}
+	result.RecalculatedWeeks = len(weekStarts)
+
+	if dryRun {
+		return result, nil
+	}
+	if err = tx.Commit(ctx); err != nil {
+		s.logger.ErrorContext(ctx, "Failed to commit transaction for ImportUserData", slog.Any("error", err), slog.String("user_id", userID))
+		return nil, fmt.Errorf("failed to commit user data import: %w", err)
+	}
+	if result.CreatedExercises > 0 {
+		invalidateExerciseCatalog(ctx)
+	}
+
+	s.logger.InfoContext(ctx, "Imported user data", slog.String("user_id", userID), slog.Any("result", result))
+	workoutsStarted.Add(ctx, int64(result.ImportedWorkouts), sourceAttr("admin"))
+	setsLogged.Add(ctx, int64(result.ImportedSets), sourceAttr("admin"))
+	volumeRecalculations.Add(ctx, int64(result.RecalculatedWeeks), sourceAttr("admin"))
+	return result, nil
+}
+
+// importExerciseResolver はエクスポートの種目を取り込み先の種目に解決する
+type importExerciseResolver struct {
+	mapped  map[uuid.UUID]uuid.UUID // エクスポートのカスタム種目の ID → 取り込み先の種目の ID
+	known   map[uuid.UUID]struct{}  // 取り込み先のユーザーが使える種目の ID
+	builtin map[string]uuid.UUID    // 組み込み種目の名前 → ID
+	custom  map[string]uuid.UUID    // ユーザーのカスタム種目の名前 → ID
+}
+
+// resolve は種目の ID と名前から取り込み先の種目の ID を返す (メニュー項目は ID がないため uuid.Nil を渡す)
+func (r *importExerciseResolver) resolve(id uuid.UUID, name string) (uuid.UUID, bool) {
+	if mapped, ok := r.mapped[id]; ok {
+		return mapped, true
+	}
+	if _, ok := r.known[id]; ok {
+		return id, true
+	}
+	if builtin, ok := r.builtin[name]; ok {
+		return builtin, true
+	}
+	custom, ok := r.custom[name]
+	return custom, ok
+}
+
+// missing は解決できない種目の名前を返す
+func (r *importExerciseResolver) missing(doc dto.ExportDocument) []string {
+	names := map[string]struct{}{}
+	for _, m := range doc.Menus {
+		for _, item := range m.Items {
+			if _, ok := r.resolve(uuid.Nil, item.ExerciseName); !ok {
+				names[item.ExerciseName] = struct{}{}
+			}
+		}
+	}
+	for _, w := range doc.Workouts {
+		for _, set := range w.Sets {
+			if _, ok := r.resolve(set.ExerciseID, set.ExerciseName); !ok {
+				names[set.ExerciseName] = struct{}{}
+			}
+		}
+	}
+	missing := make([]string, 0, len(names))
+	for name := range names {
+		missing = append(missing, name)
+	}
+	sort.Strings(missing)
+	return missing
+}
+
+// importExercises はエクスポートのカスタム種目を取り込み (同じ名前のものがあれば再利用する)、種目の解決に使う対応表を返す
+func (s *AdminService) importExercises(ctx context.Context, qtx *sqlc.Queries, userID string, exercises []dto.ExportCustomExercise, result *UserImportResult) (*importExerciseResolver, error) {
+	existing, err := qtx.ListExercisesForUser(ctx, userID)
+	if err != nil {
+		s.logger.ErrorContext(ctx, "Failed to execute ListExercisesForUser query", slog.Any("error", err), slog.String("user_id", userID))
+		return nil, fmt.Errorf("failed to list exercises: %w", err)
+	}
+	muscleGroups, err := qtx.ListMuscleGroups(ctx)
+	if err != nil {
+		s.logger.ErrorContext(ctx, "Failed to execute ListMuscleGroups query", slog.Any("error", err))
+		return nil, fmt.Errorf("failed to list muscle groups: %w", err)
+	}
+
+	resolver := &importExerciseResolver{
+		mapped:  map[uuid.UUID]uuid.UUID{},
+		known:   make(map[uuid.UUID]struct{}, len(existing)),
+		builtin: map[string]uuid.UUID{},
+		custom:  map[string]uuid.UUID{},
+	}
+	customIDs, err := qtx.ListExportCustomExercises(ctx, userID)
+	if err != nil {
+		s.logger.ErrorContext(ctx, "Failed to execute ListExportCustomExercises query", slog.Any("error", err), slog.String("user_id", userID))
+		return nil, fmt.Errorf("failed to list custom exercises: %w", err)
+	}
+	for _, e := range existing {
+		resolver.known[e.ID] = struct{}{}
+		if slices.ContainsFunc(customIDs, func(c sqlc.ListExportCustomExercisesRow) bool { return c.ID == e.ID }) {
+			resolver.custom[e.Name] = e.ID
+		} else {
+			resolver.builtin[e.Name] = e.ID
+		}
+	}
+
+	for _, e := range exercises {
+		if id, ok := resolver.custom[e.Name]; ok {
+			resolver.mapped[e.ID] = id
+			continue
+		}
+		var muscleGroupID pgtype.UUID
+		if e.MainMuscleGroup != nil {
+			if i := slices.IndexFunc(muscleGroups, func(mg sqlc.MuscleGroup) bool { return mg.Name == *e.MainMuscleGroup }); i >= 0 {
+				muscleGroupID = pgtype.UUID{Bytes: muscleGroups[i].ID, Valid: true}
+			}
+		}
+		created, err := qtx.CreateExercise(ctx, sqlc.CreateExerciseParams{
+			Name:                    e.Name,
+			MainTargetMuscleGroupID: muscleGroupID,
+			IsCustom:                pgtype.Bool{Bool: true, Valid: true},
+			CreatedByUserID:         pgtype.Text{String: userID, Valid: true},
+			LoadType:                e.LoadType,
+			MetricType:              e.MetricType,
+		})
+		if err != nil {
+			s.logger.ErrorContext(ctx, "Failed to execute CreateExercise query", slog.Any("error", err), slog.String("user_id", userID), slog.String("name", e.Name))
+			return nil, fmt.Errorf("failed to create exercise %q: %w", e.Name, err)
+		}
+		resolver.custom[e.Name] = created.ID
+		resolver.mapped[e.ID] = created.ID
+		result.CreatedExercises++
+	}
+	return resolver, nil
+}
+
+// importMenus はエクスポートのメニューを取り込み (同じ名前のメニューは読み飛ばす)、メニュー名 → ID の対応表を返す
+func (s *AdminService) importMenus(ctx context.Context, qtx *sqlc.Queries, userID string, menus []dto.ExportMenu, resolver *importExerciseResolver, result *UserImportResult) (map[string]uuid.UUID, error) {
+	existing, err := qtx.ListMenusByUser(ctx, userID)
+	if err != nil {
+		s.logger.ErrorContext(ctx, "Failed to execute ListMenusByUser query", slog.Any("error", err), slog.String("user_id", userID))
+		return nil, fmt.Errorf("failed to list menus: %w", err)
+	}
+	menuIDs := make(map[string]uuid.UUID, len(existing)+len(menus))
+	for _, m := range existing {
+		menuIDs[m.Name] = m.ID
+	}
+
+	for _, m := range menus {
+		if _, ok := menuIDs[m.Name]; ok {
+			result.SkippedMenus++
+			continue
+		}
+		created, err := qtx.CreateMenu(ctx, sqlc.CreateMenuParams{
+			UserID:      userID,
+			Name:        m.Name,
+			Description: ptrStringToPgtypeText(m.Description),
+		})
+		if err != nil {
+			s.logger.ErrorContext(ctx, "Failed to execute CreateMenu query", slog.Any("error", err), slog.String("user_id", userID), slog.String("name", m.Name))
+			return nil, fmt.Errorf("failed to create menu %q: %w", m.Name, err)
+		}
+		for _, item := range m.Items {
+			exerciseID, _ := resolver.resolve(uuid.Nil, item.ExerciseName)
+			if _, err := qtx.CreateMenuItem(ctx, sqlc.CreateMenuItemParams{
+				MenuID:                 pgtype.UUID{Bytes: created.ID, Valid: true},
+				ExerciseID:             pgtype.UUID{Bytes: exerciseID, Valid: true},
+				SetOrder:               item.SetOrder,
+				PlannedSets:            ptrInt32ToPgtypeInt4(item.PlannedSets),
+				PlannedReps:            ptrInt32ToPgtypeInt4(item.PlannedReps),
+				PlannedIntervalSeconds: ptrInt32ToPgtypeInt4(item.PlannedIntervalSeconds),
+				GroupKey:               ptrStringToPgtypeText(item.GroupKey),
+			}); err != nil {
+				s.logger.ErrorContext(ctx, "Failed to execute CreateMenuItem query", slog.Any("error", err), slog.String("user_id", userID), slog.String("menu", m.Name))
+				return nil, fmt.Errorf("failed to create menu item of %q: %w", m.Name, err)
+			}
+		}
+		menuIDs[m.Name] = created.ID
+		result.CreatedMenus++
+	}
+	return menuIDs, nil
+}
+
+// importWorkouts はエクスポートのワークアウトとセットを取り込み (同じ開始日時のワークアウトは読み飛ばす)、取り込んだ週の開始日を返す
+func (s *AdminService) importWorkouts(ctx context.Context, qtx *sqlc.Queries, userID string, workouts []dto.ExportWorkout, resolver *importExerciseResolver, menuIDs map[string]uuid.UUID, result *UserImportResult) ([]time.Time, error) {
+	startedAt := make([]time.Time, len(workouts))
+	for i, w := range workouts {
+		t, err := time.Parse(time.RFC3339, w.StartedAt)
+		if err != nil {
+			return nil, fmt.Errorf("invalid started_at %q of workout %s: %w", w.StartedAt, w.ID, err)
+		}
+		startedAt[i] = t
+	}
+	if len(workouts) == 0 {
+		return nil, nil
+	}
+
+	existing, err := qtx.ListWorkoutStartTimes(ctx, sqlc.ListWorkoutStartTimesParams{
+		UserID:   userID,
+		FromTime: pgtype.Timestamptz{Time: slices.MinFunc(startedAt, time.Time.Compare), Valid: true},
+		ToTime:   pgtype.Timestamptz{Time: slices.MaxFunc(startedAt, time.Time.Compare), Valid: true},
+	})
+	if err != nil {
+		s.logger.ErrorContext(ctx, "Failed to execute ListWorkoutStartTimes query", slog.Any("error", err), slog.String("user_id", userID))
+		return nil, fmt.Errorf("failed to list workout start times: %w", err)
+	}
+	existingStarts := make(map[int64]struct{}, len(existing))
+	for _, t := range existing {
+		existingStarts[t.Time.Unix()] = struct{}{}
+	}
+
+	weeks := map[time.Time]struct{}{}
+	workoutParams := make([]sqlc.CreateWorkoutsBulkParams, 0, len(workouts))
+	var setParams []sqlc.CreateSetsBulkParams
+	for i, w := range workouts {
+		if _, ok := existingStarts[startedAt[i].Unix()]; ok {
+			result.SkippedWorkouts++
+			continue
+		}
+		workoutID := uuid.New()
+		params := sqlc.CreateWorkoutsBulkParams{
+			ID:        workoutID,
+			UserID:    userID,
+			StartedAt: pgtype.Timestamptz{Time: startedAt[i], Valid: true},
+			Note:      ptrStringToPgtypeText(w.Note),
+		}
+		if w.MenuName != nil {
+			if menuID, ok := menuIDs[*w.MenuName]; ok {
+				params.MenuID = pgtype.UUID{Bytes: menuID, Valid: true}
+			}
+		}
+		workoutParams = append(workoutParams, params)
+		weeks[truncateToPeriod(startedAt[i].In(jst), HistoryIntervalWeek)] = struct{}{}
+
+		for _, set := range w.Sets {
+			exerciseID, _ := resolver.resolve(set.ExerciseID, set.ExerciseName)
+			params := sqlc.CreateSetsBulkParams{
+				WorkoutID:       pgtype.UUID{Bytes: workoutID, Valid: true},
+				ExerciseID:      pgtype.UUID{Bytes: exerciseID, Valid: true},
+				SetOrder:        set.SetOrder,
+				Reps:            set.Reps,
+				SetType:         set.SetType,
+				GroupKey:        ptrStringToPgtypeText(set.GroupKey),
+				DurationSeconds: ptrInt32ToPgtypeInt4(set.DurationSeconds),
+				AvgHeartRate:    ptrInt32ToPgtypeInt4(set.AvgHeartRate),
+			}
+			weightKg := set.WeightKg
+			if params.WeightKg, err = ptrFloat64ToPgtypeNumeric(&weightKg); err != nil {
+				return nil, fmt.Errorf("failed to convert weight_kg: %w", err)
+			}
+			if params.Rir, err = ptrFloat64ToPgtypeNumeric(set.Rir); err != nil {
+				return nil, fmt.Errorf("failed to convert rir: %w", err)
+			}
+			if params.Rpe, err = ptrFloat64ToPgtypeNumeric(set.Rpe); err != nil {
+				return nil, fmt.Errorf("failed to convert rpe: %w", err)
+			}
+			if params.DistanceM, err = ptrFloat64ToPgtypeNumeric(set.DistanceM); err != nil {
+				return nil, fmt.Errorf("failed to convert distance_m: %w", err)
+			}
+			setParams = append(setParams, params)
+		}
+	}
+
+	if _, err := qtx.CreateWorkoutsBulk(ctx, workoutParams); err != nil {
+		s.logger.ErrorContext(ctx, "Failed to execute CreateWorkoutsBulk query", slog.Any("error", err), slog.String("user_id", userID), slog.Int("workouts", len(workoutParams)))
+		return nil, fmt.Errorf("failed to insert workouts: %w", err)
+	}
+	if _, err := qtx.CreateSetsBulk(ctx, setParams); err != nil {
+		s.logger.ErrorContext(ctx, "Failed to execute CreateSetsBulk query", slog.Any("error", err), slog.String("user_id", userID), slog.Int("sets", len(setParams)))
+		return nil, fmt.Errorf("failed to insert sets: %w", err)
+	}
+	result.ImportedWorkouts = len(workoutParams)
+	result.ImportedSets = len(setParams)
+
+	weekStarts := make([]time.Time, 0, len(weeks))
+	for week := range weeks {
+		weekStarts = append(weekStarts, week)
+	}
+	slices.SortFunc(weekStarts, time.Time.Compare)
+	return weekStarts, nil
+}
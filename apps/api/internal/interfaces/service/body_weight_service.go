@@ -0,0 +1,124 @@
+package service
+
+import (
+	"context"
+	"fmt"
+	"log/slog"
+	"strconv"
+	"time"
+
+	httpError "github.com/aiirononeko/bulktrack/apps/api/internal/http"
+	"github.com/aiirononeko/bulktrack/apps/api/internal/infrastructure/sqlc"
+	"github.com/aiirononeko/bulktrack/apps/api/internal/interfaces/http/dto"
+	"github.com/jackc/pgx/v5/pgtype"
+	"go.opentelemetry.io/otel/attribute"
+)
+
+// 体重記録の互換 API (/body-weights)。
+// body_weights テーブルは body_measurements (metric_code = 'body_weight') に統合されたため、
+// 日ごと (JST) に1件の体重という従来の形で body_measurements を読み書きする
+
+// maxBodyWeightKg は互換 API で受け付ける体重の上限 (旧 body_weights.weight_kg の NUMERIC(5,2))
+const maxBodyWeightKg = 999.99
+
+// RecordBodyWeight は体重を記録する (同じ日の記録は最新の記録を上書きする)
+// 体重が変わると自重種目の実効負荷が変わるため、影響のある週は DB トリガーで集計キューに入る
+func (s *MeasurementService) RecordBodyWeight(ctx context.Context, userID string, req dto.RecordBodyWeightRequest) (*dto.BodyWeightView, error) {
+	ctx, span := startSpan(ctx, "MeasurementService.RecordBodyWeight", attribute.String("user_id", userID))
+	defer span.End()
+
+	if req.WeightKg <= 0 || req.WeightKg > maxBodyWeightKg {
+		return nil, httpError.NewValidationError("Weight must be greater than 0 and at most 999.99", []httpError.ValidationDetail{
+			{Field: "weight_kg", Reason: "RANGE"},
+		})
+	}
+
+	now := time.Now().In(jst)
+	measuredOn := time.Date(now.Year(), now.Month(), now.Day(), 0, 0, 0, 0, jst)
+	if req.Date != "" {
+		parsed, err := parseDate("date", req.Date)
+		if err != nil {
+			return nil, err
+		}
+		measuredOn = parsed
+	}
+
+	var weightKg pgtype.Numeric
+	weightKgStr := strconv.FormatFloat(req.WeightKg, 'f', 2, 64)
+	if err := weightKg.Scan(weightKgStr); err != nil {
+		s.logger.ErrorContext(ctx, "weight_kg conversion error during RecordBodyWeight", slog.Any("error", err), slog.String("input", weightKgStr))
+		return nil, fmt.Errorf("weight_kg conversion error: %w", err)
+	}
+
+	value, err := s.queries.UpsertDailyBodyWeight(ctx, sqlc.UpsertDailyBodyWeightParams{
+		Value:      weightKg,
+		UserID:     userID,
+		MeasuredOn: pgtype.Date{Time: measuredOn, Valid: true},
+	})
+	if err != nil {
+		s.logger.ErrorContext(ctx, "Failed to execute UpsertDailyBodyWeight query", slog.Any("error", err), slog.String("user_id", userID), slog.String("date", measuredOn.Format(dateLayout)))
+		return nil, fmt.Errorf("failed to record body weight: %w", err)
+	}
+
+	return &dto.BodyWeightView{
+		Date:     measuredOn.Format(dateLayout),
+		WeightKg: numericToFloat64(ctx, s.logger, value),
+	}, nil
+}
+
+// ListBodyWeights は期間内の日ごとの体重を新しい順に取得する
+// from / to (YYYY-MM-DD) が空の場合は直近90日間を対象とする
+func (s *MeasurementService) ListBodyWeights(ctx context.Context, userID, from, to string) ([]dto.BodyWeightView, error) {
+	ctx, span := startSpan(ctx, "MeasurementService.ListBodyWeights", attribute.String("user_id", userID))
+	defer span.End()
+
+	fromDate, toDate, err := parseDateRange(from, to, defaultMeasurementRangeDays)
+	if err != nil {
+		return nil, err
+	}
+
+	rows, err := s.queries.ListDailyBodyWeights(ctx, sqlc.ListDailyBodyWeightsParams{
+		UserID:   userID,
+		FromDate: pgtype.Date{Time: fromDate, Valid: true},
+		ToDate:   pgtype.Date{Time: toDate, Valid: true},
+	})
+	if err != nil {
+		s.logger.ErrorContext(ctx, "Failed to execute ListDailyBodyWeights query", slog.Any("error", err), slog.String("user_id", userID))
+		return nil, fmt.Errorf("failed to list body weights: %w", err)
+	}
+
+	result := make([]dto.BodyWeightView, 0, len(rows))
+	for _, row := range rows {
+		result = append(result, dto.BodyWeightView{
+			Date:     row.MeasuredOn.Time.Format(dateLayout),
+			WeightKg: numericToFloat64(ctx, s.logger, row.Value),
+		})
+	}
+
+	return result, nil
+}
+
+// DeleteBodyWeight は指定日 (YYYY-MM-DD、JST) の体重の記録をすべて削除する
+func (s *MeasurementService) DeleteBodyWeight(ctx context.Context, userID, date string) error {
+	ctx, span := startSpan(ctx, "MeasurementService.DeleteBodyWeight", attribute.String("user_id", userID))
+	defer span.End()
+
+	measuredOn, err := parseDate("date", date)
+	if err != nil {
+		return err
+	}
+
+	deleted, err := s.queries.DeleteDailyBodyWeights(ctx, sqlc.DeleteDailyBodyWeightsParams{
+		UserID:     userID,
+		MeasuredOn: pgtype.Date{Time: measuredOn, Valid: true},
+	})
+	if err != nil {
+		s.logger.ErrorContext(ctx, "Failed to execute DeleteDailyBodyWeights query", slog.Any("error", err), slog.String("user_id", userID), slog.String("date", date))
+		return fmt.Errorf("failed to delete body weight: %w", err)
+	}
+	if deleted == 0 {
+		return httpError.NewNotFoundError("Body weight record not found", nil)
+	}
+
+	return nil
+}
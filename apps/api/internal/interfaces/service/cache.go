@@ -0,0 +1,57 @@
+package service
+
+import (
+	"context"
+	"time"
+
+	"github.com/aiirononeko/bulktrack/apps/api/internal/cache"
+	"github.com/aiirononeko/bulktrack/apps/api/internal/volume"
+	"github.com/google/uuid"
+)
+
+// responseCache は種目の一覧・メニューの詳細・週間ボリュームのキャッシュ (SetCache を呼び出すまでは nil でキャッシュしない)
+var responseCache *cache.Cache
+
+// SetCache はサービスが使うキャッシュを設定する (起動時、サービスを使い始める前に1度だけ呼び出す)
+func SetCache(c *cache.Cache) {
+	responseCache = c
+}
+
+// キャッシュの有効期間
+// 無効化は変更したインスタンスのストアに対して行うため、メモリ上のストアで複数のインスタンスを動かす場合は
+// 他のインスタンスで変更した値がこの期間まで返ることがある
+const (
+	exerciseCatalogCacheTTL = 10 * time.Minute // 種目を書き込むシード・取り込み・メニューの複製・アカウントの削除で無効にする
+	menuCacheTTL            = 10 * time.Minute // メニューの更新・削除で無効にする
+	weeklyVolumeCacheTTL    = 5 * time.Minute  // 週間ボリュームの集計で無効にする
+)
+
+// exerciseCatalogCacheScope は種目の一覧のキャッシュのスコープ
+const exerciseCatalogCacheScope = "exercises"
+
+// menuCacheScope はメニューの詳細のキャッシュのスコープ
+func menuCacheScope(menuID uuid.UUID) string {
+	return "menu:" + menuID.String()
+}
+
+// volumeCacheScope はユーザーの週間ボリュームのキャッシュのスコープ
+func volumeCacheScope(userID string) string {
+	return "volume:" + userID
+}
+
+// invalidateExerciseCatalog は種目の一覧のキャッシュを無効にする (種目を書き込んだトランザクションのコミットの後に呼び出す)
+// 一覧は組み込み種目のみだが、カスタム種目の作成・削除でも無効にし、種目の書き込みと一覧のキャッシュがずれないようにする
+func invalidateExerciseCatalog(ctx context.Context) {
+	responseCache.Invalidate(ctx, exerciseCatalogCacheScope)
+}
+
+// invalidateWeeklyVolumes はユーザー週を集計し直したユーザーの週間ボリュームのキャッシュを無効にする (コミットの後に呼び出す)
+func invalidateWeeklyVolumes(ctx context.Context, weeks []volume.Week) {
+	seen := make(map[string]bool)
+	for _, week := range weeks {
+		if !seen[week.UserID] {
+			seen[week.UserID] = true
+			responseCache.Invalidate(ctx, volumeCacheScope(week.UserID))
+		}
+	}
+}
@@ -0,0 +1,258 @@
+package service
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log/slog"
+	"time"
+
+	httpError "github.com/aiirononeko/bulktrack/apps/api/internal/http"
+	"github.com/aiirononeko/bulktrack/apps/api/internal/infrastructure/sqlc"
+	"github.com/aiirononeko/bulktrack/apps/api/internal/jobs"
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgtype"
+	"github.com/jackc/pgx/v5/pgxpool"
+	"go.opentelemetry.io/otel/attribute"
+)
+
+// webhookSourceClerk は webhook_events の source (配信元)
+const webhookSourceClerk = "clerk"
+
+// Clerk の Webhook イベントの種類
+const (
+	ClerkEventUserCreated = "user.created"
+	ClerkEventUserDeleted = "user.deleted"
+)
+
+// 失敗した Webhook イベントの再試行と古いイベントの削除
+const (
+	webhookMaxAttempts     = 8 // 1, 2, 4, ... 分の間隔で再試行する (合計約2時間)
+	webhookRetryDelay      = time.Minute
+	webhookCleanupInterval = time.Hour
+)
+
+// ClerkWebhookRetryArgs は処理に失敗した Webhook イベントを再試行するバックグラウンドジョブの引数
+type ClerkWebhookRetryArgs struct {
+	WebhookEventID uuid.UUID `json:"webhook_event_id"` // webhook_events の ID
+}
+
+// Kind はジョブの種類を返す
+func (ClerkWebhookRetryArgs) Kind() string { return "clerk_webhook.retry" }
+
+// ClerkWebhookCleanupArgs は処理済みの古い Webhook イベントを削除する定期実行のジョブの引数
+type ClerkWebhookCleanupArgs struct{}
+
+// Kind はジョブの種類を返す
+func (ClerkWebhookCleanupArgs) Kind() string { return "clerk_webhook.cleanup" }
+
+// clerkEvent は Clerk の Webhook イベントのうち処理に使う項目を表す
+type clerkEvent struct {
+	Type string `json:"type"`
+	Data struct {
+		ID string `json:"id"` // ユーザーID (JWT の sub)
+	} `json:"data"`
+}
+
+// ClerkWebhookService は Clerk の Webhook イベント (ユーザーの作成・削除) の処理を提供する
+//
+// 受け取ったイベントは webhook_events に記録し、同じイベントの再配信は処理済みであれば読み飛ばす。
+// 処理に失敗したイベントは再試行のジョブで間隔を空けて再試行する (Svix からの再配信でも再試行される)
+type ClerkWebhookService struct {
+	pool                   *pgxpool.Pool
+	queries                *sqlc.Queries
+	accountDeletionService *AccountDeletionService
+	logger                 *slog.Logger
+}
+
+// NewClerkWebhookService は新しい ClerkWebhookService を作成する
+func NewClerkWebhookService(pool *pgxpool.Pool, accountDeletionService *AccountDeletionService, logger *slog.Logger) *ClerkWebhookService {
+	return &ClerkWebhookService{
+		pool:                   pool,
+		queries:                sqlc.New(pool),
+		accountDeletionService: accountDeletionService,
+		logger:                 logger,
+	}
+}
+
+// HandleEvent は署名を検証済みの Webhook イベントを記録して処理する
+// eventID は Svix の svix-id。処理済みのイベントは何もせずに成功とする
+func (s *ClerkWebhookService) HandleEvent(ctx context.Context, eventID string, payload []byte) error {
+	ctx, span := startSpan(ctx, "ClerkWebhookService.HandleEvent", attribute.String("event_id", eventID))
+	defer span.End()
+
+	var event clerkEvent
+	if err := json.Unmarshal(payload, &event); err != nil || event.Type == "" {
+		return httpError.NewValidationError("Invalid webhook payload", []httpError.ValidationDetail{
+			{Field: "type", Reason: "REQUIRED"},
+		})
+	}
+
+	row, err := s.queries.CreateWebhookEvent(ctx, sqlc.CreateWebhookEventParams{
+		Source:  webhookSourceClerk,
+		EventID: eventID,
+		Type:    event.Type,
+		UserID:  pgtype.Text{String: event.Data.ID, Valid: event.Data.ID != ""},
+	})
+	if errors.Is(err, pgx.ErrNoRows) {
+		// 既に受け取ったイベント (再配信)
+		var existing sqlc.GetWebhookEventRow
+		existing, err = s.queries.GetWebhookEvent(ctx, sqlc.GetWebhookEventParams{Source: webhookSourceClerk, EventID: eventID})
+		row = sqlc.CreateWebhookEventRow(existing)
+	}
+	if err != nil {
+		s.logger.ErrorContext(ctx, "Failed to record webhook event", slog.Any("error", err), slog.String("event_id", eventID), slog.String("type", event.Type))
+		return fmt.Errorf("failed to record webhook event: %w", err)
+	}
+	if row.Status == "processed" {
+		s.logger.InfoContext(ctx, "Ignored redelivered webhook event", slog.String("event_id", eventID), slog.String("type", event.Type))
+		return nil
+	}
+
+	if err := s.process(ctx, row); err != nil {
+		s.enqueueRetry(ctx, row)
+		return err
+	}
+	return nil
+}
+
+// enqueueRetry は処理に失敗したイベントの再試行のジョブを追加する (同じイベントの未完了のジョブがある場合は追加しない)
+// 追加に失敗した場合も Svix からの再配信で再試行されるため、ログのみ記録する
+func (s *ClerkWebhookService) enqueueRetry(ctx context.Context, event sqlc.CreateWebhookEventRow) {
+	remaining := webhookMaxAttempts - int(event.Attempts) - 1 // 今回の試行を除いた残りの試行回数
+	if remaining <= 0 {
+		return
+	}
+	params, err := jobs.NewInsertParams(ClerkWebhookRetryArgs{WebhookEventID: event.ID}, &jobs.InsertOptions{
+		RunAt:       time.Now().Add(webhookRetryDelay),
+		MaxAttempts: remaining,
+		UniqueKey:   "webhook:" + event.ID.String(),
+	})
+	if err == nil {
+		_, _, err = insertJob(ctx, s.queries, params)
+	}
+	if err != nil {
+		s.logger.ErrorContext(ctx, "Failed to enqueue webhook event retry", slog.Any("error", err), slog.String("event_id", event.EventID))
+	}
+}
+
+// RegisterJobs は失敗した Webhook イベントの再試行と、処理済みの古いイベントを定期的に削除するジョブを登録する
+func (s *ClerkWebhookService) RegisterJobs(runner *jobs.Runner) {
+	jobs.Register(runner, s.runRetryJob, jobs.HandlerOptions{
+		// イベントの n 回目の試行 (ジョブの n-1 回目) の失敗から 2^(n-1) 分後に再試行する
+		Backoff: func(attempt int) time.Duration { return webhookRetryDelay << attempt },
+	})
+	jobs.Register(runner, s.runCleanupJob, jobs.HandlerOptions{})
+	runner.AddPeriodic(jobs.PeriodicJob{
+		Name:     "clerk_webhook.cleanup",
+		Schedule: jobs.Every(webhookCleanupInterval),
+		Args:     ClerkWebhookCleanupArgs{},
+		Options:  &jobs.InsertOptions{MaxAttempts: 1}, // 失敗した場合は次の実行日時に削除する
+	})
+}
+
+// runRetryJob は失敗した Webhook イベントを処理し直す (処理済みのイベントは何もしない)
+func (s *ClerkWebhookService) runRetryJob(ctx context.Context, job *jobs.Job[ClerkWebhookRetryArgs]) error {
+	event, err := s.queries.GetWebhookEventByID(ctx, job.Args.WebhookEventID)
+	if errors.Is(err, pgx.ErrNoRows) {
+		return nil // 削除されたイベント
+	}
+	if err != nil {
+		s.logger.ErrorContext(ctx, "Failed to execute GetWebhookEventByID query", slog.Any("error", err), slog.String("webhook_event_id", job.Args.WebhookEventID.String()))
+		return fmt.Errorf("failed to get webhook event: %w", err)
+	}
+	if event.Status == "processed" {
+		return nil
+	}
+	// 失敗は process 内で記録する
+	return s.process(ctx, sqlc.CreateWebhookEventRow(event))
+}
+
+// runCleanupJob は処理済みの古い Webhook イベントを削除する
+func (s *ClerkWebhookService) runCleanupJob(ctx context.Context, _ *jobs.Job[ClerkWebhookCleanupArgs]) error {
+	deleted, err := s.queries.DeleteOldWebhookEvents(ctx)
+	if err != nil {
+		s.logger.WarnContext(ctx, "Failed to delete old webhook events", slog.Any("error", err))
+		return fmt.Errorf("failed to delete old webhook events: %w", err)
+	}
+	if deleted > 0 {
+		s.logger.InfoContext(ctx, "Deleted old webhook events", slog.Int64("count", deleted))
+	}
+	return nil
+}
+
+// process はイベントを処理し、結果を記録する (各処理は同じイベントを繰り返し処理しても結果が変わらない)
+func (s *ClerkWebhookService) process(ctx context.Context, event sqlc.CreateWebhookEventRow) error {
+	logger := s.logger.With(slog.String("event_id", event.EventID), slog.String("type", event.Type), slog.String("user_id", event.UserID.String), slog.Int("attempt", int(event.Attempts)+1))
+
+	var err error
+	switch event.Type {
+	case ClerkEventUserCreated:
+		err = s.provisionUser(ctx, event.UserID)
+	case ClerkEventUserDeleted:
+		if event.UserID.Valid {
+			_, err = s.accountDeletionService.RequestDeletion(ctx, event.UserID.String, AccountDeletionSourceClerkWebhook)
+		}
+	default:
+		// user.updated など、保持しているデータに影響しないイベントは記録のみ
+	}
+
+	if err != nil {
+		logger.ErrorContext(ctx, "Failed to process webhook event", slog.Any("error", err))
+		if markErr := s.queries.MarkWebhookEventFailed(ctx, sqlc.MarkWebhookEventFailedParams{LastError: err.Error(), ID: event.ID}); markErr != nil {
+			logger.ErrorContext(ctx, "Failed to execute MarkWebhookEventFailed query", slog.Any("error", markErr))
+		}
+		return fmt.Errorf("failed to process webhook event: %w", err)
+	}
+	if err := s.queries.MarkWebhookEventProcessed(ctx, event.ID); err != nil {
+		logger.ErrorContext(ctx, "Failed to execute MarkWebhookEventProcessed query", slog.Any("error", err))
+		return fmt.Errorf("failed to mark webhook event as processed: %w", err)
+	}
+	logger.InfoContext(ctx, "Processed webhook event")
+	return nil
+}
+
+// provisionUser は新しいユーザーのデフォルト設定とはじめてのメニューを作成する
+// 設定が既にある (プロビジョニング済み) 場合は何もしない
+func (s *ClerkWebhookService) provisionUser(ctx context.Context, userID pgtype.Text) (err error) {
+	if !userID.Valid {
+		return errors.New("user.created event without user ID")
+	}
+
+	tx, err := s.pool.Begin(ctx)
+	if err != nil {
+		s.logger.ErrorContext(ctx, "Failed to begin transaction for ProvisionUser", slog.Any("error", err), slog.String("user_id", userID.String))
+		return err
+	}
+	defer func() {
+		if r := recover(); r != nil {
+			s.logger.ErrorContext(ctx, "Recovered in ProvisionUser, rolling back transaction", slog.Any("panic_value", r), slog.String("user_id", userID.String))
+			tx.Rollback(ctx)
+			panic(r)
+		} else if err != nil {
+			rollErr := tx.Rollback(ctx)
+			if rollErr != nil {
+				s.logger.ErrorContext(ctx, "Failed to rollback transaction for ProvisionUser", slog.Any("rollback_error", rollErr), slog.Any("original_error", err), slog.String("user_id", userID.String))
+			}
+		}
+	}()
+
+	qtx := sqlc.New(tx)
+
+	created, err := qtx.ProvisionUserSettings(ctx, userID.String)
+	if err != nil {
+		return fmt.Errorf("failed to create user settings: %w", err)
+	}
+	if created > 0 {
+		if _, err = qtx.CreateStarterMenu(ctx, userID.String); err != nil {
+			return fmt.Errorf("failed to create starter menu: %w", err)
+		}
+	}
+
+	if err = tx.Commit(ctx); err != nil {
+		return fmt.Errorf("failed to commit user provisioning: %w", err)
+	}
+	return nil
+}
@@ -0,0 +1,263 @@
+package service
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/base32"
+	"errors"
+	"fmt"
+	"log/slog"
+	"strings"
+	"time"
+
+	"github.com/aiirononeko/bulktrack/apps/api/internal/auth"
+	httpError "github.com/aiirononeko/bulktrack/apps/api/internal/http"
+	"github.com/aiirononeko/bulktrack/apps/api/internal/infrastructure/sqlc"
+	"github.com/aiirononeko/bulktrack/apps/api/internal/interfaces/http/dto"
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgtype"
+	"github.com/jackc/pgx/v5/pgxpool"
+	"go.opentelemetry.io/otel/attribute"
+)
+
+// コーチへの招待
+const (
+	coachInvitationTTL       = 7 * 24 * time.Hour
+	coachInviteCodeBytes     = 10 // base32 で16文字
+	defaultCoachAuditLogSize = 100
+)
+
+// コーチへのアクセス権に対するユーザーの立場
+const (
+	CoachRoleAthlete = "athlete"
+	CoachRoleCoach   = "coach"
+)
+
+// CoachService はコーチとアスリートの共有 (アスリートがコーチに与えるアクセス権) を提供する
+type CoachService struct {
+	pool    *pgxpool.Pool
+	queries *sqlc.Queries
+	logger  *slog.Logger
+}
+
+// NewCoachService は新しい CoachService を作成する
+func NewCoachService(pool *pgxpool.Pool, logger *slog.Logger) *CoachService {
+	return &CoachService{
+		pool:    pool,
+		queries: sqlc.New(pool),
+		logger:  logger,
+	}
+}
+
+// CreateInvitation はアスリートがコーチを招待する (招待コードを発行する)
+// コーチが7日以内に招待コードを受け入れると、指定した権限でアスリートのデータにアクセスできる
+func (s *CoachService) CreateInvitation(ctx context.Context, athleteUserID string, req dto.CreateCoachGrantRequest) (*dto.CoachGrantView, error) {
+	ctx, span := startSpan(ctx, "CoachService.CreateInvitation", attribute.String("user_id", athleteUserID))
+	defer span.End()
+
+	if !auth.IsValidCoachPermission(req.Permission) {
+		return nil, httpError.NewValidationError("Permission must be read or write", []httpError.ValidationDetail{
+			{Field: "permission", Reason: "INVALID_VALUE"},
+		})
+	}
+
+	code := make([]byte, coachInviteCodeBytes)
+	if _, err := rand.Read(code); err != nil {
+		return nil, fmt.Errorf("failed to generate invite code: %w", err)
+	}
+
+	grant, err := s.queries.CreateCoachInvitation(ctx, sqlc.CreateCoachInvitationParams{
+		AthleteUserID:   athleteUserID,
+		Permission:      req.Permission,
+		InviteCode:      base32.StdEncoding.WithPadding(base32.NoPadding).EncodeToString(code),
+		InviteExpiresAt: pgtype.Timestamptz{Time: time.Now().Add(coachInvitationTTL), Valid: true},
+	})
+	if err != nil {
+		s.logger.ErrorContext(ctx, "Failed to execute CreateCoachInvitation query", slog.Any("error", err), slog.String("user_id", athleteUserID))
+		return nil, fmt.Errorf("failed to create coach invitation: %w", err)
+	}
+
+	s.logger.InfoContext(ctx, "Created coach invitation", slog.String("user_id", athleteUserID), slog.String("grant_id", grant.ID.String()), slog.String("permission", grant.Permission))
+	view := toCoachGrantView(grant, athleteUserID)
+	return &view, nil
+}
+
+// AcceptInvitation はコーチが招待コードを受け入れ、アスリートのデータへのアクセス権を有効にする
+func (s *CoachService) AcceptInvitation(ctx context.Context, coachUserID string, req dto.AcceptCoachInvitationRequest) (*dto.CoachGrantView, error) {
+	ctx, span := startSpan(ctx, "CoachService.AcceptInvitation", attribute.String("user_id", coachUserID))
+	defer span.End()
+
+	code := strings.ToUpper(strings.TrimSpace(req.InviteCode))
+	if code == "" {
+		return nil, httpError.NewValidationError("invite_code is required", []httpError.ValidationDetail{
+			{Field: "invite_code", Reason: "REQUIRED"},
+		})
+	}
+
+	invitation, err := s.queries.GetCoachInvitation(ctx, code)
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return nil, httpError.NewNotFoundError("Invitation not found or expired", err)
+		}
+		s.logger.ErrorContext(ctx, "Failed to execute GetCoachInvitation query", slog.Any("error", err), slog.String("user_id", coachUserID))
+		return nil, fmt.Errorf("failed to get coach invitation: %w", err)
+	}
+	if invitation.AthleteUserID == coachUserID {
+		return nil, httpError.NewValidationError("You cannot accept your own invitation", []httpError.ValidationDetail{
+			{Field: "invite_code", Reason: "NOT_ALLOWED"},
+		})
+	}
+	if _, err := s.queries.GetActiveCoachGrant(ctx, sqlc.GetActiveCoachGrantParams{CoachUserID: coachUserID, AthleteUserID: invitation.AthleteUserID}); err == nil {
+		return nil, httpError.NewValidationError("You already coach this athlete", []httpError.ValidationDetail{
+			{Field: "invite_code", Reason: "NOT_ALLOWED"},
+		})
+	} else if !errors.Is(err, pgx.ErrNoRows) {
+		s.logger.ErrorContext(ctx, "Failed to execute GetActiveCoachGrant query", slog.Any("error", err), slog.String("user_id", coachUserID))
+		return nil, fmt.Errorf("failed to get coach grant: %w", err)
+	}
+
+	grant, err := s.queries.AcceptCoachInvitation(ctx, sqlc.AcceptCoachInvitationParams{CoachUserID: coachUserID, ID: invitation.ID})
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			// 同時に受け入れられた・取り消された
+			return nil, httpError.NewNotFoundError("Invitation not found or expired", err)
+		}
+		s.logger.ErrorContext(ctx, "Failed to execute AcceptCoachInvitation query", slog.Any("error", err), slog.String("user_id", coachUserID))
+		return nil, fmt.Errorf("failed to accept coach invitation: %w", err)
+	}
+
+	s.logger.InfoContext(ctx, "Accepted coach invitation", slog.String("user_id", coachUserID), slog.String("athlete_user_id", grant.AthleteUserID), slog.String("grant_id", grant.ID.String()))
+	view := toCoachGrantView(sqlc.CreateCoachInvitationRow(grant), coachUserID)
+	return &view, nil
+}
+
+// ListGrants はユーザーが与えた (アスリートとして) アクセス権と与えられた (コーチとして) アクセス権の一覧を取得する
+func (s *CoachService) ListGrants(ctx context.Context, userID string) ([]dto.CoachGrantView, error) {
+	ctx, span := startSpan(ctx, "CoachService.ListGrants", attribute.String("user_id", userID))
+	defer span.End()
+
+	rows, err := s.queries.ListCoachGrants(ctx, userID)
+	if err != nil {
+		s.logger.ErrorContext(ctx, "Failed to execute ListCoachGrants query", slog.Any("error", err), slog.String("user_id", userID))
+		return nil, fmt.Errorf("failed to list coach grants: %w", err)
+	}
+
+	grants := make([]dto.CoachGrantView, 0, len(rows))
+	for _, row := range rows {
+		grants = append(grants, toCoachGrantView(sqlc.CreateCoachInvitationRow(row), userID))
+	}
+	return grants, nil
+}
+
+// RevokeGrant はアクセス権 (または招待) を取り消す。アスリートとコーチのどちらからでも取り消せる
+func (s *CoachService) RevokeGrant(ctx context.Context, userID string, grantID uuid.UUID) error {
+	ctx, span := startSpan(ctx, "CoachService.RevokeGrant", attribute.String("user_id", userID), attribute.String("grant_id", grantID.String()))
+	defer span.End()
+
+	revoked, err := s.queries.RevokeCoachGrant(ctx, sqlc.RevokeCoachGrantParams{ID: grantID, UserID: userID})
+	if err != nil {
+		s.logger.ErrorContext(ctx, "Failed to execute RevokeCoachGrant query", slog.Any("error", err), slog.String("user_id", userID), slog.String("grant_id", grantID.String()))
+		return fmt.Errorf("failed to revoke coach grant: %w", err)
+	}
+	if revoked == 0 {
+		return httpError.NewNotFoundError("Coach grant not found", nil)
+	}
+
+	s.logger.InfoContext(ctx, "Revoked coach grant", slog.String("user_id", userID), slog.String("grant_id", grantID.String()))
+	return nil
+}
+
+// ListAuditLogs はアクセス権を使ってコーチが行った操作の記録を新しい順に取得する
+func (s *CoachService) ListAuditLogs(ctx context.Context, userID string, grantID uuid.UUID) ([]dto.CoachAuditLogView, error) {
+	ctx, span := startSpan(ctx, "CoachService.ListAuditLogs", attribute.String("user_id", userID), attribute.String("grant_id", grantID.String()))
+	defer span.End()
+
+	rows, err := s.queries.ListCoachAuditLogs(ctx, sqlc.ListCoachAuditLogsParams{
+		GrantID:   grantID,
+		UserID:    userID,
+		PageLimit: defaultCoachAuditLogSize,
+	})
+	if err != nil {
+		s.logger.ErrorContext(ctx, "Failed to execute ListCoachAuditLogs query", slog.Any("error", err), slog.String("user_id", userID), slog.String("grant_id", grantID.String()))
+		return nil, fmt.Errorf("failed to list coach audit logs: %w", err)
+	}
+
+	logs := make([]dto.CoachAuditLogView, 0, len(rows))
+	for _, row := range rows {
+		logs = append(logs, dto.CoachAuditLogView{
+			ID:          row.ID,
+			CoachUserID: row.CoachUserID,
+			Method:      row.Method,
+			Route:       row.Route,
+			Path:        row.Path,
+			StatusCode:  row.StatusCode,
+			CreatedAt:   row.CreatedAt.Format(time.RFC3339),
+		})
+	}
+	return logs, nil
+}
+
+// AuthorizeCoach はコーチがアスリートのデータに対して required の権限の操作をできるかを確認し、アクセス権のIDを返す
+// アクセス権がない・権限が足りない場合は auth.ErrCoachAccessDenied を返す
+func (s *CoachService) AuthorizeCoach(ctx context.Context, coachUserID, athleteUserID, required string) (uuid.UUID, error) {
+	ctx, span := startSpan(ctx, "CoachService.AuthorizeCoach", attribute.String("user_id", athleteUserID), attribute.String("coach_user_id", coachUserID))
+	defer span.End()
+
+	grant, err := s.queries.GetActiveCoachGrant(ctx, sqlc.GetActiveCoachGrantParams{CoachUserID: coachUserID, AthleteUserID: athleteUserID})
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return uuid.Nil, auth.ErrCoachAccessDenied
+		}
+		s.logger.ErrorContext(ctx, "Failed to execute GetActiveCoachGrant query", slog.Any("error", err), slog.String("user_id", coachUserID))
+		return uuid.Nil, fmt.Errorf("failed to get coach grant: %w", err)
+	}
+	if !auth.CoachPermissionAllows(grant.Permission, required) {
+		return uuid.Nil, auth.ErrCoachAccessDenied
+	}
+	return grant.ID, nil
+}
+
+// RecordCoachAction はコーチの操作を監査ログに記録する
+func (s *CoachService) RecordCoachAction(ctx context.Context, action auth.CoachAction) error {
+	ctx, span := startSpan(ctx, "CoachService.RecordCoachAction", attribute.String("user_id", action.AthleteUserID), attribute.String("coach_user_id", action.CoachUserID), attribute.String("grant_id", action.GrantID.String()))
+	defer span.End()
+
+	err := s.queries.CreateCoachAuditLog(ctx, sqlc.CreateCoachAuditLogParams{
+		GrantID:       action.GrantID,
+		CoachUserID:   action.CoachUserID,
+		AthleteUserID: action.AthleteUserID,
+		Method:        action.Method,
+		Route:         action.Route,
+		Path:          action.Path,
+		StatusCode:    int32(action.StatusCode),
+	})
+	if err != nil {
+		s.logger.ErrorContext(ctx, "Failed to execute CreateCoachAuditLog query", slog.Any("error", err), slog.String("user_id", action.CoachUserID), slog.String("grant_id", action.GrantID.String()))
+		return fmt.Errorf("failed to record coach action: %w", err)
+	}
+	return nil
+}
+
+// toCoachGrantView は sqlc の型をレスポンスの型に変換する (招待コードはアスリートにのみ返す)
+func toCoachGrantView(grant sqlc.CreateCoachInvitationRow, userID string) dto.CoachGrantView {
+	view := dto.CoachGrantView{
+		ID:            grant.ID,
+		Role:          CoachRoleCoach,
+		AthleteUserID: grant.AthleteUserID,
+		CoachUserID:   pgtypeTextToPtrString(grant.CoachUserID),
+		Permission:    grant.Permission,
+		Status:        grant.Status,
+		CreatedAt:     grant.CreatedAt.Format(time.RFC3339),
+		AcceptedAt:    pgtypeTimestamptzToPtrString(grant.AcceptedAt),
+	}
+	if grant.AthleteUserID == userID {
+		view.Role = CoachRoleAthlete
+		if grant.Status == "pending" {
+			view.InviteCode = pgtypeTextToPtrString(grant.InviteCode)
+			expiresAt := grant.InviteExpiresAt.Format(time.RFC3339)
+			view.InviteExpiresAt = &expiresAt
+		}
+	}
+	return view
+}
@@ -0,0 +1,77 @@
+package service
+
+import (
+	"time"
+
+	httpError "github.com/aiirononeko/bulktrack/apps/api/internal/http"
+)
+
+// dateLayout は日付 (YYYY-MM-DD) の書式
+const dateLayout = "2006-01-02"
+
+// jst は日付の区切りに使うタイムゾーン (weekly_volumes の週区切りと同じく JST)
+var jst = time.FixedZone("Asia/Tokyo", 9*60*60)
+
+// DefaultTimeZone は tz 未指定時に使うタイムゾーン
+const DefaultTimeZone = "Asia/Tokyo"
+
+// loadTimeZone は IANA タイムゾーン名を読み込む (空の場合は Asia/Tokyo)
+func loadTimeZone(name string) (*time.Location, error) {
+	if name == "" {
+		name = DefaultTimeZone
+	}
+	loc, err := time.LoadLocation(name)
+	if err != nil || name == "Local" {
+		return nil, httpError.NewValidationError("Invalid time zone. Expected an IANA time zone name such as Asia/Tokyo", []httpError.ValidationDetail{
+			{Field: "tz", Reason: "INVALID_VALUE"},
+		})
+	}
+	return loc, nil
+}
+
+// parseDate は YYYY-MM-DD 形式の日付を JST としてパースする
+func parseDate(field, value string) (time.Time, error) {
+	return parseDateIn(field, value, jst)
+}
+
+// parseDateIn は YYYY-MM-DD 形式の日付を指定したタイムゾーンの 0:00 としてパースする
+func parseDateIn(field, value string, loc *time.Location) (time.Time, error) {
+	parsed, err := time.ParseInLocation(dateLayout, value, loc)
+	if err != nil {
+		return time.Time{}, httpError.NewValidationError("Invalid date format. Expected YYYY-MM-DD", []httpError.ValidationDetail{
+			{Field: field, Reason: "INVALID_FORMAT"},
+		})
+	}
+	return parsed, nil
+}
+
+// parseDateRange は YYYY-MM-DD 形式の期間 (from / to) をパースする
+// 未指定の場合、to は今日、from は to の defaultDays 日前とする
+func parseDateRange(from, to string, defaultDays int) (time.Time, time.Time, error) {
+	now := time.Now().In(jst)
+	toDate := time.Date(now.Year(), now.Month(), now.Day(), 0, 0, 0, 0, jst)
+	if to != "" {
+		parsed, err := parseDate("to", to)
+		if err != nil {
+			return time.Time{}, time.Time{}, err
+		}
+		toDate = parsed
+	}
+
+	fromDate := toDate.AddDate(0, 0, -defaultDays)
+	if from != "" {
+		parsed, err := parseDate("from", from)
+		if err != nil {
+			return time.Time{}, time.Time{}, err
+		}
+		fromDate = parsed
+	}
+
+	if fromDate.After(toDate) {
+		return time.Time{}, time.Time{}, httpError.NewValidationError("from must be on or before to", []httpError.ValidationDetail{
+			{Field: "from", Reason: "RANGE"},
+		})
+	}
+
+	return fromDate, toDate, nil
+}
@@ -4,6 +4,7 @@ import (
 	"context"
 	"log/slog"
 
+	"github.com/aiirononeko/bulktrack/apps/api/internal/cache"
 	"github.com/aiirononeko/bulktrack/apps/api/internal/infrastructure/sqlc"
 	"github.com/aiirononeko/bulktrack/apps/api/internal/interfaces/http/dto"
 	"github.com/jackc/pgx/v5/pgxpool"
@@ -27,6 +28,14 @@ func NewExerciseService(pool *pgxpool.Pool, logger *slog.Logger) *ExerciseServic
 
 // ListExercises は基本的な種目（カスタムを除く）の一覧を取得する
 func (s *ExerciseService) ListExercises(ctx context.Context) ([]dto.Exercise, error) {
+	ctx, span := startSpan(ctx, "ExerciseService.ListExercises")
+	defer span.End()
+
+	return cache.Load(ctx, responseCache, exerciseCatalogCacheScope, "", exerciseCatalogCacheTTL, s.loadExercises)
+}
+
+// loadExercises は基本的な種目の一覧を DB から読み込む
+func (s *ExerciseService) loadExercises(ctx context.Context) ([]dto.Exercise, error) {
 	// データベースから種目一覧を取得 (is_custom = false のもの)
 	exercisesDB, err := s.queries.ListExercises(ctx)
 	if err != nil {
@@ -38,8 +47,10 @@ func (s *ExerciseService) ListExercises(ctx context.Context) ([]dto.Exercise, er
 	result := make([]dto.Exercise, 0, len(exercisesDB))
 	for _, dbExercise := range exercisesDB {
 		result = append(result, dto.Exercise{
-			ID:   dbExercise.ID,
-			Name: dbExercise.Name,
+			ID:         dbExercise.ID,
+			Name:       dbExercise.Name,
+			LoadType:   dbExercise.LoadType,
+			MetricType: dbExercise.MetricType,
 		})
 	}
 
@@ -0,0 +1,989 @@
+package service
+
+import (
+	"archive/zip"
+	"bufio"
+	"context"
+	"encoding/csv"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"log/slog"
+	"os"
+	"strconv"
+	"time"
+
+	httpError "github.com/aiirononeko/bulktrack/apps/api/internal/http"
+	"github.com/aiirononeko/bulktrack/apps/api/internal/infrastructure/sqlc"
+	"github.com/aiirononeko/bulktrack/apps/api/internal/interfaces/http/dto"
+	"github.com/aiirononeko/bulktrack/apps/api/internal/jobs"
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgtype"
+	"github.com/jackc/pgx/v5/pgxpool"
+	"go.opentelemetry.io/otel/attribute"
+)
+
+// エクスポート形式
+const (
+	ExportFormatJSON = "json" // 全データを1つの JSON で出力 (デフォルト)
+	ExportFormatCSV  = "csv"  // データセットごとに1つの CSV で出力
+)
+
+// CSV エクスポートのデータセット
+const (
+	ExportDatasetSets            = "sets" // デフォルト
+	ExportDatasetMenus           = "menus"
+	ExportDatasetCustomExercises = "custom_exercises"
+	ExportDatasetWeeklyVolumes   = "weekly_volumes"
+)
+
+// exportDatasets はアーカイブに含める CSV のデータセット (この順に出力する)
+var exportDatasets = []string{ExportDatasetSets, ExportDatasetMenus, ExportDatasetCustomExercises, ExportDatasetWeeklyVolumes}
+
+// CSV の列。既存の列の順序・名前は変えず、追加する場合は末尾に追加する
+var exportCSVColumns = map[string][]string{
+	ExportDatasetSets: {
+		"workout_id", "started_at", "menu_name", "workout_note",
+		"set_id", "exercise_id", "exercise_name", "set_order", "set_type", "group_key",
+		"weight_kg", "reps", "rir", "rpe", "duration_seconds", "distance_m", "avg_heart_rate",
+	},
+	ExportDatasetMenus: {
+		"menu_id", "menu_name", "description", "created_at",
+		"set_order", "exercise_name", "planned_sets", "planned_reps", "planned_interval_seconds", "group_key",
+	},
+	ExportDatasetCustomExercises: {"id", "name", "main_muscle_group", "load_type", "metric_type", "created_at"},
+	ExportDatasetWeeklyVolumes:   {"week_start_date", "total_volume", "est_one_rm", "exercise_count", "set_count"},
+}
+
+// exportBatchSize はセットを読み込む1バッチあたりの行数 (全件をメモリに載せないようにバッチで読み込む)
+const exportBatchSize = 500
+
+// MaxSyncExportRows は同期エクスポート (GET /me/export) で扱うセットの最大行数
+// 超える場合は非同期エクスポートジョブ (POST /me/exports) を使う
+const MaxSyncExportRows = 20000
+
+// exportJobTimeout は非同期エクスポートジョブのタイムアウト
+const exportJobTimeout = 10 * time.Minute
+
+// exportArchiveChunkSize はアーカイブのラージオブジェクトを読み書きする単位
+// (1回の読み書きがデータベースへの1往復になるため、まとめて読み書きする)
+const exportArchiveChunkSize = 1 << 20
+
+// ExportArchiveArgs は非同期エクスポートの ZIP アーカイブを作成するバックグラウンドジョブの引数
+type ExportArchiveArgs struct {
+	ExportJobID uuid.UUID `json:"export_job_id"`
+	UserID      string    `json:"user_id"`
+	From        string    `json:"from,omitempty"` // YYYY-MM-DD (JST)
+	To          string    `json:"to,omitempty"`
+}
+
+// Kind はジョブの種類を返す
+func (ExportArchiveArgs) Kind() string { return "export.archive" }
+
+// utf8BOM は Excel で CSV を開いた際に日本語が文字化けしないよう先頭に付ける BOM
+const utf8BOM = "\ufeff"
+
+// ExportOptions はエクスポートの条件を表す
+type ExportOptions struct {
+	Format  string // json / csv
+	Dataset string // format=csv の場合のデータセット
+	From    string // 開始日 (YYYY-MM-DD, JST)
+	To      string // 終了日 (YYYY-MM-DD, JST, 当日を含む)
+}
+
+// ExportPlan は検証済みのエクスポート条件を表す
+type ExportPlan struct {
+	Format      string
+	Dataset     string
+	Filename    string // Content-Disposition に使うファイル名
+	ContentType string
+
+	userID   string
+	fromDate pgtype.Date // ワークアウト・週間ボリュームの期間 (メニュー・カスタム種目は期間に関係なく全件)
+	toDate   pgtype.Date
+	fromTime pgtype.Timestamptz
+	toTime   pgtype.Timestamptz
+}
+
+// ExportArchive は完了した非同期エクスポートジョブの ZIP アーカイブを読み込む
+// 読み込み中はラージオブジェクトを開いたトランザクションを保持するため、読み終えたら必ず Close する
+type ExportArchive struct {
+	io.Reader
+	Filename string
+	Size     int64
+
+	ctx context.Context
+	tx  pgx.Tx
+}
+
+// Close はアーカイブを読み込んだトランザクションを終了する
+func (a *ExportArchive) Close() error {
+	return a.tx.Rollback(a.ctx)
+}
+
+// exportSource はエクスポートするデータを読み込む (テストではデータベースの代わりに固定の行を返す)
+type exportSource interface {
+	forEachSetRow(ctx context.Context, plan *ExportPlan, fn func([]sqlc.ListExportSetRowsRow) error) error
+	listMenuItems(ctx context.Context, plan *ExportPlan) ([]sqlc.ListExportMenuItemsRow, error)
+	listCustomExercises(ctx context.Context, plan *ExportPlan) ([]sqlc.ListExportCustomExercisesRow, error)
+	listWeeklyVolumes(ctx context.Context, plan *ExportPlan) ([]sqlc.ListExportWeeklyVolumesRow, error)
+}
+
+// ExportService はユーザーのトレーニングデータのエクスポート関連のサービスを提供する
+type ExportService struct {
+	pool    *pgxpool.Pool
+	queries *sqlc.Queries
+	source  exportSource
+	logger  *slog.Logger
+}
+
+// NewExportService は新しい ExportService を作成する
+func NewExportService(pool *pgxpool.Pool, logger *slog.Logger) *ExportService {
+	queries := sqlc.New(pool)
+	return &ExportService{
+		pool:    pool,
+		queries: queries,
+		source: &dbExportSource{
+			queries:    queries,
+			aggregator: NewWeeklyVolumeAggregator(pool, logger),
+			logger:     logger,
+		},
+		logger: logger,
+	}
+}
+
+// PrepareExport は同期エクスポートの条件を検証する
+// セットの行数が MaxSyncExportRows を超える場合は非同期ジョブを使うようエラーを返す
+func (s *ExportService) PrepareExport(ctx context.Context, userID string, opts ExportOptions) (*ExportPlan, error) {
+	ctx, span := startSpan(ctx, "ExportService.PrepareExport", attribute.String("user_id", userID))
+	defer span.End()
+
+	format := opts.Format
+	if format == "" {
+		format = ExportFormatJSON
+	}
+
+	var details []httpError.ValidationDetail
+	dataset := opts.Dataset
+	switch format {
+	case ExportFormatJSON:
+		if dataset != "" {
+			details = append(details, httpError.ValidationDetail{Field: "dataset", Reason: "NOT_ALLOWED"})
+		}
+	case ExportFormatCSV:
+		if dataset == "" {
+			dataset = ExportDatasetSets
+		}
+		if _, ok := exportCSVColumns[dataset]; !ok {
+			details = append(details, httpError.ValidationDetail{Field: "dataset", Reason: "INVALID_VALUE"})
+		}
+	default:
+		details = append(details, httpError.ValidationDetail{Field: "format", Reason: "INVALID_VALUE"})
+	}
+	if len(details) > 0 {
+		return nil, httpError.NewValidationError("Invalid export options. format must be json or csv, and dataset is only allowed for csv", details)
+	}
+
+	plan, err := newExportPlan(userID, format, dataset, opts.From, opts.To)
+	if err != nil {
+		return nil, err
+	}
+
+	if format == ExportFormatJSON || dataset == ExportDatasetSets {
+		count, err := s.queries.CountExportSetRows(ctx, sqlc.CountExportSetRowsParams{
+			UserID:   userID,
+			FromTime: plan.fromTime,
+			ToTime:   plan.toTime,
+		})
+		if err != nil {
+			s.logger.ErrorContext(ctx, "Failed to execute CountExportSetRows query", slog.Any("error", err), slog.String("user_id", userID))
+			return nil, fmt.Errorf("failed to count export rows: %w", err)
+		}
+		if count > MaxSyncExportRows {
+			return nil, httpError.NewExportTooLargeError(fmt.Sprintf("Export has %d rows, which exceeds %d. Use POST /me/exports to create an export job", count, MaxSyncExportRows), nil)
+		}
+	}
+
+	return plan, nil
+}
+
+// PrepareFullExport は JSON の全データエクスポートの条件を作成する (管理コマンド用)
+// 同期エクスポートと違い、セットの行数は制限しない
+func (s *ExportService) PrepareFullExport(userID, from, to string) (*ExportPlan, error) {
+	return newExportPlan(userID, ExportFormatJSON, "", from, to)
+}
+
+// WriteExport はエクスポートを w に書き込む (セットはバッチで読み込みながら順次書き込む)
+func (s *ExportService) WriteExport(ctx context.Context, w io.Writer, plan *ExportPlan) error {
+	ctx, span := startSpan(ctx, "ExportService.WriteExport")
+	defer span.End()
+
+	if plan.Format == ExportFormatCSV {
+		return s.writeCSV(ctx, w, plan, plan.Dataset)
+	}
+	return s.writeJSON(ctx, w, plan)
+}
+
+// CreateExportJob は非同期エクスポートジョブを作成し、ZIP アーカイブを作成するバックグラウンドジョブを追加する
+// アーカイブには export.json とデータセットごとの CSV を含める (失敗した場合やサーバーの再起動で中断した場合は再試行する)
+func (s *ExportService) CreateExportJob(ctx context.Context, userID string, req dto.ExportJobRequest) (*dto.ExportJobView, error) {
+	ctx, span := startSpan(ctx, "ExportService.CreateExportJob", attribute.String("user_id", userID))
+	defer span.End()
+
+	plan, err := newExportPlan(userID, ExportFormatJSON, "", req.From, req.To)
+	if err != nil {
+		return nil, err
+	}
+
+	// 保持期限を過ぎたアーカイブを削除する (失敗してもジョブの作成は続ける)
+	if deleted, err := s.queries.DeleteExpiredExportJobs(ctx); err != nil {
+		s.logger.WarnContext(ctx, "Failed to delete expired export jobs", slog.Any("error", err))
+	} else if deleted > 0 {
+		s.logger.InfoContext(ctx, "Deleted expired export jobs", slog.Int64("count", deleted))
+	}
+
+	job, err := s.createExportJob(ctx, plan, req)
+	if err != nil {
+		return nil, err
+	}
+
+	view := toExportJobView(sqlc.GetExportJobRow(job))
+	return &view, nil
+}
+
+// GetExportJob は非同期エクスポートジョブの状態を取得する
+func (s *ExportService) GetExportJob(ctx context.Context, userID string, jobID uuid.UUID) (*dto.ExportJobView, error) {
+	ctx, span := startSpan(ctx, "ExportService.GetExportJob", attribute.String("user_id", userID), attribute.String("export_job_id", jobID.String()))
+	defer span.End()
+
+	job, err := s.getExportJob(ctx, userID, jobID)
+	if err != nil {
+		return nil, err
+	}
+	view := toExportJobView(job)
+	return &view, nil
+}
+
+// OpenExportArchive は完了した非同期エクスポートジョブの ZIP アーカイブを開く
+// アーカイブはメモリに載せず、ラージオブジェクトから exportArchiveChunkSize ずつ読み込む
+func (s *ExportService) OpenExportArchive(ctx context.Context, userID string, jobID uuid.UUID) (archive *ExportArchive, err error) {
+	ctx, span := startSpan(ctx, "ExportService.OpenExportArchive", attribute.String("user_id", userID), attribute.String("export_job_id", jobID.String()))
+	defer span.End()
+
+	job, err := s.getExportJob(ctx, userID, jobID)
+	if err != nil {
+		return nil, err
+	}
+
+	// ラージオブジェクトはトランザクションの中でのみ読み込める
+	tx, err := s.pool.BeginTx(ctx, pgx.TxOptions{AccessMode: pgx.ReadOnly})
+	if err != nil {
+		s.logger.ErrorContext(ctx, "Failed to begin transaction for OpenExportArchive", slog.Any("error", err), slog.String("user_id", userID))
+		return nil, err
+	}
+	defer func() {
+		if err != nil {
+			if rollErr := tx.Rollback(ctx); rollErr != nil {
+				s.logger.ErrorContext(ctx, "Failed to rollback transaction for OpenExportArchive", slog.Any("rollback_error", rollErr), slog.Any("original_error", err), slog.String("user_id", userID))
+			}
+		}
+	}()
+
+	row, err := sqlc.New(tx).GetExportJobArchive(ctx, sqlc.GetExportJobArchiveParams{ID: jobID, UserID: userID})
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return nil, httpError.NewNotFoundError("Export archive is not available. The job may not be completed yet or the archive has expired", err)
+		}
+		s.logger.ErrorContext(ctx, "Failed to execute GetExportJobArchive query", slog.Any("error", err), slog.String("user_id", userID), slog.String("job_id", jobID.String()))
+		return nil, fmt.Errorf("failed to get export archive: %w", err)
+	}
+
+	lobs := tx.LargeObjects()
+	obj, err := lobs.Open(ctx, row.ArchiveOid, pgx.LargeObjectModeRead)
+	if err != nil {
+		s.logger.ErrorContext(ctx, "Failed to open export archive", slog.Any("error", err), slog.String("user_id", userID), slog.String("job_id", jobID.String()))
+		return nil, fmt.Errorf("failed to open export archive: %w", err)
+	}
+
+	return &ExportArchive{
+		Reader:   bufio.NewReaderSize(obj, exportArchiveChunkSize),
+		Filename: exportFilename("export", job.FromDate, job.ToDate, "zip"),
+		Size:     row.ArchiveSize,
+		ctx:      ctx,
+		tx:       tx,
+	}, nil
+}
+
+// getExportJob はユーザーの非同期エクスポートジョブを取得する
+func (s *ExportService) getExportJob(ctx context.Context, userID string, jobID uuid.UUID) (sqlc.GetExportJobRow, error) {
+	job, err := s.queries.GetExportJob(ctx, sqlc.GetExportJobParams{ID: jobID, UserID: userID})
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return job, httpError.NewNotFoundError("Export job not found", err)
+		}
+		s.logger.ErrorContext(ctx, "Failed to execute GetExportJob query", slog.Any("error", err), slog.String("user_id", userID), slog.String("job_id", jobID.String()))
+		return job, fmt.Errorf("failed to get export job: %w", err)
+	}
+	return job, nil
+}
+
+// createExportJob はエクスポートジョブとアーカイブを作成するバックグラウンドジョブを同じトランザクションで追加する
+func (s *ExportService) createExportJob(ctx context.Context, plan *ExportPlan, req dto.ExportJobRequest) (job sqlc.CreateExportJobRow, err error) {
+	tx, err := s.pool.Begin(ctx)
+	if err != nil {
+		s.logger.ErrorContext(ctx, "Failed to begin transaction for CreateExportJob", slog.Any("error", err), slog.String("user_id", plan.userID))
+		return job, err
+	}
+	defer func() {
+		if err != nil {
+			if rollErr := tx.Rollback(ctx); rollErr != nil {
+				s.logger.ErrorContext(ctx, "Failed to rollback transaction for CreateExportJob", slog.Any("rollback_error", rollErr), slog.Any("original_error", err), slog.String("user_id", plan.userID))
+			}
+		}
+	}()
+
+	qtx := sqlc.New(tx)
+	job, err = qtx.CreateExportJob(ctx, sqlc.CreateExportJobParams{
+		UserID:   plan.userID,
+		FromDate: plan.fromDate,
+		ToDate:   plan.toDate,
+	})
+	if err != nil {
+		s.logger.ErrorContext(ctx, "Failed to execute CreateExportJob query", slog.Any("error", err), slog.String("user_id", plan.userID))
+		return job, fmt.Errorf("failed to create export job: %w", err)
+	}
+
+	params, err := jobs.NewInsertParams(ExportArchiveArgs{
+		ExportJobID: job.ID,
+		UserID:      plan.userID,
+		From:        req.From,
+		To:          req.To,
+	}, &jobs.InsertOptions{UniqueKey: "export:" + job.ID.String()})
+	if err != nil {
+		return job, err
+	}
+	if _, _, err = insertJob(ctx, qtx, params); err != nil {
+		s.logger.ErrorContext(ctx, "Failed to execute InsertJob query", slog.Any("error", err), slog.String("user_id", plan.userID), slog.String("job_id", job.ID.String()))
+		return job, err
+	}
+
+	if err = tx.Commit(ctx); err != nil {
+		s.logger.ErrorContext(ctx, "Failed to commit transaction for CreateExportJob", slog.Any("error", err), slog.String("user_id", plan.userID))
+		return job, fmt.Errorf("failed to commit export job: %w", err)
+	}
+	return job, nil
+}
+
+// RegisterJobs はエクスポートのバックグラウンドジョブのハンドラーを登録する
+func (s *ExportService) RegisterJobs(runner *jobs.Runner) {
+	jobs.Register(runner, s.runExportArchiveJob, jobs.HandlerOptions{Timeout: exportJobTimeout})
+}
+
+// runExportArchiveJob は ZIP アーカイブを作成してエクスポートジョブに保存する
+// 失敗した場合は再試行し、最後の試行で失敗した場合のみエクスポートジョブを failed にする
+func (s *ExportService) runExportArchiveJob(ctx context.Context, job *jobs.Job[ExportArchiveArgs]) error {
+	args := job.Args
+	logger := s.logger.With(slog.String("job_id", args.ExportJobID.String()), slog.String("user_id", args.UserID), slog.Int("attempt", job.Attempt))
+
+	plan, err := newExportPlan(args.UserID, ExportFormatJSON, "", args.From, args.To)
+	if err != nil {
+		return jobs.Permanent(err)
+	}
+
+	if err := s.queries.StartExportJob(ctx, args.ExportJobID); err != nil {
+		logger.ErrorContext(ctx, "Failed to execute StartExportJob query", slog.Any("error", err))
+		return fmt.Errorf("failed to start export job: %w", err)
+	}
+
+	size, err := s.buildAndStoreArchive(ctx, args.ExportJobID, plan)
+	if err != nil {
+		logger.ErrorContext(ctx, "Failed to build export archive", slog.Any("error", err))
+		if job.LastAttempt() {
+			if err := s.queries.FailExportJob(ctx, sqlc.FailExportJobParams{Error: "Failed to build export archive", ID: args.ExportJobID}); err != nil {
+				logger.ErrorContext(ctx, "Failed to execute FailExportJob query", slog.Any("error", err))
+			}
+		}
+		return fmt.Errorf("failed to build export archive: %w", err)
+	}
+	logger.InfoContext(ctx, "Export job completed", slog.Int64("archive_size", size))
+	return nil
+}
+
+// buildAndStoreArchive は ZIP アーカイブを一時ファイルに作成し、ラージオブジェクトに保存してエクスポートジョブを完了する
+// アーカイブの作成中はトランザクションを保持しないよう、一時ファイルを経由する
+func (s *ExportService) buildAndStoreArchive(ctx context.Context, jobID uuid.UUID, plan *ExportPlan) (size int64, err error) {
+	f, err := os.CreateTemp("", "bulktrack-export-*.zip")
+	if err != nil {
+		return 0, fmt.Errorf("failed to create temporary file: %w", err)
+	}
+	defer func() {
+		f.Close()
+		os.Remove(f.Name())
+	}()
+
+	if err := s.buildArchive(ctx, f, plan); err != nil {
+		return 0, err
+	}
+	if _, err := f.Seek(0, io.SeekStart); err != nil {
+		return 0, err
+	}
+
+	tx, err := s.pool.Begin(ctx)
+	if err != nil {
+		return 0, err
+	}
+	defer func() {
+		if err != nil {
+			if rollErr := tx.Rollback(ctx); rollErr != nil {
+				s.logger.ErrorContext(ctx, "Failed to rollback transaction for export archive", slog.Any("rollback_error", rollErr), slog.Any("original_error", err), slog.String("job_id", jobID.String()))
+			}
+		}
+	}()
+
+	lobs := tx.LargeObjects()
+	oid, err := lobs.Create(ctx, 0)
+	if err != nil {
+		return 0, fmt.Errorf("failed to create large object: %w", err)
+	}
+	obj, err := lobs.Open(ctx, oid, pgx.LargeObjectModeWrite)
+	if err != nil {
+		return 0, fmt.Errorf("failed to open large object: %w", err)
+	}
+	bw := bufio.NewWriterSize(obj, exportArchiveChunkSize)
+	if size, err = io.Copy(bw, f); err != nil {
+		return 0, fmt.Errorf("failed to write large object: %w", err)
+	}
+	if err = bw.Flush(); err != nil {
+		return 0, fmt.Errorf("failed to write large object: %w", err)
+	}
+	if err = obj.Close(); err != nil {
+		return 0, err
+	}
+
+	if err = sqlc.New(tx).CompleteExportJob(ctx, sqlc.CompleteExportJobParams{ArchiveOid: oid, ArchiveSize: size, ID: jobID}); err != nil {
+		s.logger.ErrorContext(ctx, "Failed to execute CompleteExportJob query", slog.Any("error", err), slog.String("job_id", jobID.String()))
+		return 0, fmt.Errorf("failed to complete export job: %w", err)
+	}
+	if err = tx.Commit(ctx); err != nil {
+		return 0, fmt.Errorf("failed to commit export archive: %w", err)
+	}
+	return size, nil
+}
+
+// buildArchive は export.json とデータセットごとの CSV を含む ZIP アーカイブを w に書き込む
+func (s *ExportService) buildArchive(ctx context.Context, w io.Writer, plan *ExportPlan) error {
+	zw := zip.NewWriter(w)
+
+	f, err := zw.Create("export.json")
+	if err != nil {
+		return err
+	}
+	if err := s.writeJSON(ctx, f, plan); err != nil {
+		return err
+	}
+
+	for _, dataset := range exportDatasets {
+		f, err := zw.Create(dataset + ".csv")
+		if err != nil {
+			return err
+		}
+		if err := s.writeCSV(ctx, f, plan, dataset); err != nil {
+			return err
+		}
+	}
+
+	return zw.Close()
+}
+
+// writeCSV はデータセットを CSV で w に書き込む
+func (s *ExportService) writeCSV(ctx context.Context, w io.Writer, plan *ExportPlan, dataset string) error {
+	if _, err := io.WriteString(w, utf8BOM); err != nil {
+		return err
+	}
+	cw := csv.NewWriter(w)
+	if err := cw.Write(exportCSVColumns[dataset]); err != nil {
+		return err
+	}
+
+	switch dataset {
+	case ExportDatasetSets:
+		err := s.source.forEachSetRow(ctx, plan, func(rows []sqlc.ListExportSetRowsRow) error {
+			for _, row := range rows {
+				if err := cw.Write([]string{
+					row.WorkoutID.String(),
+					csvTimestamptz(row.StartedAt),
+					row.MenuName.String,
+					row.WorkoutNote.String,
+					csvUUID(row.SetID),
+					csvUUID(row.ExerciseID),
+					row.ExerciseName.String,
+					csvInt4(row.SetOrder),
+					row.SetType.String,
+					row.GroupKey.String,
+					csvNumeric(row.WeightKg),
+					csvInt4(row.Reps),
+					csvNumeric(row.Rir),
+					csvNumeric(row.Rpe),
+					csvInt4(row.DurationSeconds),
+					csvNumeric(row.DistanceM),
+					csvInt4(row.AvgHeartRate),
+				}); err != nil {
+					return err
+				}
+			}
+			// バッチごとに書き出してメモリに溜めない
+			cw.Flush()
+			return cw.Error()
+		})
+		if err != nil {
+			return err
+		}
+	case ExportDatasetMenus:
+		rows, err := s.source.listMenuItems(ctx, plan)
+		if err != nil {
+			return err
+		}
+		for _, row := range rows {
+			if err := cw.Write([]string{
+				row.MenuID.String(),
+				row.MenuName,
+				row.Description.String,
+				csvTimestamptz(row.CreatedAt),
+				csvInt4(row.SetOrder),
+				row.ExerciseName.String,
+				csvInt4(row.PlannedSets),
+				csvInt4(row.PlannedReps),
+				csvInt4(row.PlannedIntervalSeconds),
+				row.GroupKey.String,
+			}); err != nil {
+				return err
+			}
+		}
+	case ExportDatasetCustomExercises:
+		rows, err := s.source.listCustomExercises(ctx, plan)
+		if err != nil {
+			return err
+		}
+		for _, row := range rows {
+			if err := cw.Write([]string{
+				row.ID.String(),
+				row.Name,
+				row.MainMuscleGroup.String,
+				row.LoadType,
+				row.MetricType,
+				csvTimestamptz(row.CreatedAt),
+			}); err != nil {
+				return err
+			}
+		}
+	case ExportDatasetWeeklyVolumes:
+		rows, err := s.source.listWeeklyVolumes(ctx, plan)
+		if err != nil {
+			return err
+		}
+		for _, row := range rows {
+			if err := cw.Write([]string{
+				row.WeekStartDate.Time.Format(dateLayout),
+				csvNumeric(row.TotalVolume),
+				csvNumeric(row.EstOneRm),
+				strconv.Itoa(int(row.ExerciseCount)),
+				strconv.Itoa(int(row.SetCount)),
+			}); err != nil {
+				return err
+			}
+		}
+	}
+
+	cw.Flush()
+	return cw.Error()
+}
+
+// writeJSON は全データを1つの JSON で w に書き込む
+// ワークアウトは1件ずつエンコードして書き込み、全件をメモリに載せない
+func (s *ExportService) writeJSON(ctx context.Context, w io.Writer, plan *ExportPlan) error {
+	jw := &jsonStreamWriter{w: w}
+	jw.raw(`{"exported_at":`)
+	jw.value(time.Now().In(jst).Format(time.RFC3339))
+	jw.raw(`,"from":`)
+	jw.value(pgtypeDateToPtrString(plan.fromDate))
+	jw.raw(`,"to":`)
+	jw.value(pgtypeDateToPtrString(plan.toDate))
+
+	jw.raw(`,"workouts":[`)
+	var current *dto.ExportWorkout
+	written := 0
+	writeCurrent := func() {
+		if current == nil {
+			return
+		}
+		if written > 0 {
+			jw.raw(",")
+		}
+		jw.value(current)
+		written++
+	}
+	err := s.source.forEachSetRow(ctx, plan, func(rows []sqlc.ListExportSetRowsRow) error {
+		for _, row := range rows {
+			if current == nil || current.ID != row.WorkoutID {
+				writeCurrent()
+				current = &dto.ExportWorkout{
+					ID:        row.WorkoutID,
+					StartedAt: csvTimestamptz(row.StartedAt),
+					MenuName:  pgtypeTextToPtrString(row.MenuName),
+					Note:      pgtypeTextToPtrString(row.WorkoutNote),
+					Sets:      []dto.ExportSet{},
+				}
+			}
+			if row.SetID.Valid {
+				current.Sets = append(current.Sets, toExportSet(row))
+			}
+		}
+		return jw.err
+	})
+	if err != nil {
+		return err
+	}
+	writeCurrent()
+	jw.raw("]")
+
+	menuRows, err := s.source.listMenuItems(ctx, plan)
+	if err != nil {
+		return err
+	}
+	jw.raw(`,"menus":`)
+	jw.value(toExportMenus(menuRows))
+
+	exerciseRows, err := s.source.listCustomExercises(ctx, plan)
+	if err != nil {
+		return err
+	}
+	exercises := make([]dto.ExportCustomExercise, 0, len(exerciseRows))
+	for _, row := range exerciseRows {
+		exercises = append(exercises, dto.ExportCustomExercise{
+			ID:              row.ID,
+			Name:            row.Name,
+			MainMuscleGroup: pgtypeTextToPtrString(row.MainMuscleGroup),
+			LoadType:        row.LoadType,
+			MetricType:      row.MetricType,
+			CreatedAt:       pgtypeTimestamptzToPtrString(row.CreatedAt),
+		})
+	}
+	jw.raw(`,"custom_exercises":`)
+	jw.value(exercises)
+
+	volumeRows, err := s.source.listWeeklyVolumes(ctx, plan)
+	if err != nil {
+		return err
+	}
+	volumes := make([]dto.ExportWeeklyVolume, 0, len(volumeRows))
+	for _, row := range volumeRows {
+		volumes = append(volumes, dto.ExportWeeklyVolume{
+			WeekStartDate: row.WeekStartDate.Time.Format(dateLayout),
+			TotalVolume:   numericToFloat64(ctx, s.logger, row.TotalVolume),
+			EstOneRM:      numericToFloat64(ctx, s.logger, row.EstOneRm),
+			ExerciseCount: row.ExerciseCount,
+			SetCount:      row.SetCount,
+		})
+	}
+	jw.raw(`,"weekly_volumes":`)
+	jw.value(volumes)
+	jw.raw("}\n")
+
+	return jw.err
+}
+
+// dbExportSource はエクスポートするデータをデータベースから読み込む
+type dbExportSource struct {
+	queries    *sqlc.Queries
+	aggregator *WeeklyVolumeAggregator
+	logger     *slog.Logger
+}
+
+// forEachSetRow はワークアウトとセットの行を exportBatchSize 件ずつ読み込み、バッチごとに fn を呼び出す
+func (s *dbExportSource) forEachSetRow(ctx context.Context, plan *ExportPlan, fn func([]sqlc.ListExportSetRowsRow) error) error {
+	params := sqlc.ListExportSetRowsParams{
+		UserID:    plan.userID,
+		FromTime:  plan.fromTime,
+		ToTime:    plan.toTime,
+		PageLimit: exportBatchSize,
+	}
+	for {
+		rows, err := s.queries.ListExportSetRows(ctx, params)
+		if err != nil {
+			s.logger.ErrorContext(ctx, "Failed to execute ListExportSetRows query", slog.Any("error", err), slog.String("user_id", plan.userID))
+			return fmt.Errorf("failed to list export set rows: %w", err)
+		}
+		if err := fn(rows); err != nil {
+			return err
+		}
+		if len(rows) < exportBatchSize {
+			return nil
+		}
+
+		last := rows[len(rows)-1]
+		if !last.StartedAt.Valid {
+			// started_at が NULL の行はカーソルにできない (先頭から読み直して無限ループになる)
+			return fmt.Errorf("workout %s has no started_at", last.WorkoutID)
+		}
+		params.CursorStartedAt = last.StartedAt
+		params.CursorWorkoutID = pgtype.UUID{Bytes: last.WorkoutID, Valid: true}
+		params.CursorSetOrder = last.SetOrder.Int32
+	}
+}
+
+// listMenuItems はユーザーのメニューとメニュー項目を取得する
+func (s *dbExportSource) listMenuItems(ctx context.Context, plan *ExportPlan) ([]sqlc.ListExportMenuItemsRow, error) {
+	rows, err := s.queries.ListExportMenuItems(ctx, plan.userID)
+	if err != nil {
+		s.logger.ErrorContext(ctx, "Failed to execute ListExportMenuItems query", slog.Any("error", err), slog.String("user_id", plan.userID))
+		return nil, fmt.Errorf("failed to list export menu items: %w", err)
+	}
+	return rows, nil
+}
+
+// listCustomExercises はユーザーが作成したカスタム種目を取得する
+func (s *dbExportSource) listCustomExercises(ctx context.Context, plan *ExportPlan) ([]sqlc.ListExportCustomExercisesRow, error) {
+	rows, err := s.queries.ListExportCustomExercises(ctx, plan.userID)
+	if err != nil {
+		s.logger.ErrorContext(ctx, "Failed to execute ListExportCustomExercises query", slog.Any("error", err), slog.String("user_id", plan.userID))
+		return nil, fmt.Errorf("failed to list export custom exercises: %w", err)
+	}
+	return rows, nil
+}
+
+// listWeeklyVolumes はユーザーの週間ボリュームを取得する (期間は from を含む週から to を含む週まで)
+func (s *dbExportSource) listWeeklyVolumes(ctx context.Context, plan *ExportPlan) ([]sqlc.ListExportWeeklyVolumesRow, error) {
+	// 記録した直後の変更を含めるため、集計キューに残っている週を先に集計する
+	if err := s.aggregator.FlushUser(ctx, plan.userID); err != nil {
+		return nil, fmt.Errorf("failed to aggregate weekly volumes: %w", err)
+	}
+	startDate := plan.fromDate
+	if startDate.Valid {
+		startDate.Time = truncateToPeriod(startDate.Time, HistoryIntervalWeek)
+	}
+	rows, err := s.queries.ListExportWeeklyVolumes(ctx, sqlc.ListExportWeeklyVolumesParams{
+		UserID:    plan.userID,
+		StartDate: startDate,
+		EndDate:   plan.toDate,
+	})
+	if err != nil {
+		s.logger.ErrorContext(ctx, "Failed to execute ListExportWeeklyVolumes query", slog.Any("error", err), slog.String("user_id", plan.userID))
+		return nil, fmt.Errorf("failed to list export weekly volumes: %w", err)
+	}
+	return rows, nil
+}
+
+// newExportPlan は期間 (YYYY-MM-DD, JST) をパースしてエクスポート条件を作成する (未指定の場合は期間の制限なし)
+func newExportPlan(userID, format, dataset, from, to string) (*ExportPlan, error) {
+	plan := &ExportPlan{
+		Format:  format,
+		Dataset: dataset,
+		userID:  userID,
+	}
+
+	if from != "" {
+		fromDate, err := parseDate("from", from)
+		if err != nil {
+			return nil, err
+		}
+		plan.fromDate = pgtype.Date{Time: fromDate, Valid: true}
+		plan.fromTime = pgtype.Timestamptz{Time: fromDate, Valid: true}
+	}
+	if to != "" {
+		toDate, err := parseDate("to", to)
+		if err != nil {
+			return nil, err
+		}
+		plan.toDate = pgtype.Date{Time: toDate, Valid: true}
+		plan.toTime = pgtype.Timestamptz{Time: toDate.AddDate(0, 0, 1), Valid: true}
+	}
+	if plan.fromDate.Valid && plan.toDate.Valid && plan.fromDate.Time.After(plan.toDate.Time) {
+		return nil, httpError.NewValidationError("from must be on or before to", []httpError.ValidationDetail{
+			{Field: "from", Reason: "RANGE"},
+		})
+	}
+
+	if format == ExportFormatCSV {
+		plan.Filename = exportFilename(dataset, plan.fromDate, plan.toDate, "csv")
+		plan.ContentType = "text/csv; charset=utf-8"
+	} else {
+		plan.Filename = exportFilename("export", plan.fromDate, plan.toDate, "json")
+		plan.ContentType = "application/json"
+	}
+	return plan, nil
+}
+
+// exportFilename は期間を含むファイル名を作成する
+// 例: bulktrack-sets-20250401-20250430.csv / bulktrack-export-all.json (期間の指定なし)
+func exportFilename(name string, fromDate, toDate pgtype.Date, ext string) string {
+	const layout = "20060102"
+	period := "all"
+	if fromDate.Valid || toDate.Valid {
+		from := "start"
+		if fromDate.Valid {
+			from = fromDate.Time.Format(layout)
+		}
+		to := time.Now().In(jst).Format(layout)
+		if toDate.Valid {
+			to = toDate.Time.Format(layout)
+		}
+		period = from + "-" + to
+	}
+	return fmt.Sprintf("bulktrack-%s-%s.%s", name, period, ext)
+}
+
+// toExportSet はセットの行をエクスポート用のセットに変換する
+func toExportSet(row sqlc.ListExportSetRowsRow) dto.ExportSet {
+	var weightKg float64
+	if v := pgtypeNumericToPtrFloat64(row.WeightKg); v != nil {
+		weightKg = *v
+	}
+	return dto.ExportSet{
+		ID:              uuid.UUID(row.SetID.Bytes),
+		ExerciseID:      uuid.UUID(row.ExerciseID.Bytes),
+		ExerciseName:    row.ExerciseName.String,
+		SetOrder:        row.SetOrder.Int32,
+		SetType:         row.SetType.String,
+		GroupKey:        pgtypeTextToPtrString(row.GroupKey),
+		WeightKg:        weightKg,
+		Reps:            row.Reps.Int32,
+		Rir:             pgtypeNumericToPtrFloat64(row.Rir),
+		Rpe:             pgtypeNumericToPtrFloat64(row.Rpe),
+		DurationSeconds: pgtypeInt4ToPtrInt32(row.DurationSeconds),
+		DistanceM:       pgtypeNumericToPtrFloat64(row.DistanceM),
+		AvgHeartRate:    pgtypeInt4ToPtrInt32(row.AvgHeartRate),
+	}
+}
+
+// toExportMenus はメニュー項目の行をメニューごとにまとめる
+func toExportMenus(rows []sqlc.ListExportMenuItemsRow) []dto.ExportMenu {
+	menus := []dto.ExportMenu{}
+	for _, row := range rows {
+		if len(menus) == 0 || menus[len(menus)-1].ID != row.MenuID {
+			menus = append(menus, dto.ExportMenu{
+				ID:          row.MenuID,
+				Name:        row.MenuName,
+				Description: pgtypeTextToPtrString(row.Description),
+				CreatedAt:   pgtypeTimestamptzToPtrString(row.CreatedAt),
+				Items:       []dto.ExportMenuItem{},
+			})
+		}
+		if !row.SetOrder.Valid {
+			continue // 項目のないメニュー
+		}
+		menu := &menus[len(menus)-1]
+		menu.Items = append(menu.Items, dto.ExportMenuItem{
+			SetOrder:               row.SetOrder.Int32,
+			ExerciseName:           row.ExerciseName.String,
+			PlannedSets:            pgtypeInt4ToPtrInt32(row.PlannedSets),
+			PlannedReps:            pgtypeInt4ToPtrInt32(row.PlannedReps),
+			PlannedIntervalSeconds: pgtypeInt4ToPtrInt32(row.PlannedIntervalSeconds),
+			GroupKey:               pgtypeTextToPtrString(row.GroupKey),
+		})
+	}
+	return menus
+}
+
+// toExportJobView は非同期エクスポートジョブをレスポンスに変換する
+func toExportJobView(job sqlc.GetExportJobRow) dto.ExportJobView {
+	view := dto.ExportJobView{
+		ID:          job.ID,
+		Status:      job.Status,
+		From:        pgtypeDateToPtrString(job.FromDate),
+		To:          pgtypeDateToPtrString(job.ToDate),
+		Error:       pgtypeTextToPtrString(job.Error),
+		CreatedAt:   job.CreatedAt.Format(time.RFC3339),
+		CompletedAt: pgtypeTimestamptzToPtrString(job.CompletedAt),
+		ExpiresAt:   pgtypeTimestamptzToPtrString(job.ExpiresAt),
+	}
+	if job.ArchiveSize.Valid {
+		size := job.ArchiveSize.Int64
+		view.ArchiveSize = &size
+	}
+	if job.Status == "completed" {
+		url := fmt.Sprintf("/me/exports/%s/download", job.ID)
+		view.DownloadURL = &url
+	}
+	return view
+}
+
+// jsonStreamWriter は JSON を順次書き込み、最初に発生したエラーを保持する
+type jsonStreamWriter struct {
+	w   io.Writer
+	err error
+}
+
+// raw は文字列をそのまま書き込む
+func (jw *jsonStreamWriter) raw(s string) {
+	if jw.err != nil {
+		return
+	}
+	_, jw.err = io.WriteString(jw.w, s)
+}
+
+// value は値を JSON にエンコードして書き込む
+func (jw *jsonStreamWriter) value(v any) {
+	if jw.err != nil {
+		return
+	}
+	b, err := json.Marshal(v)
+	if err != nil {
+		jw.err = err
+		return
+	}
+	_, jw.err = jw.w.Write(b)
+}
+
+// csvTimestamptz は日時を RFC3339 (JST) の文字列に変換する (NULL は空文字列)
+func csvTimestamptz(t pgtype.Timestamptz) string {
+	if !t.Valid {
+		return ""
+	}
+	return t.Time.In(jst).Format(time.RFC3339)
+}
+
+// csvUUID は pgtype.UUID を文字列に変換する (NULL は空文字列)
+func csvUUID(u pgtype.UUID) string {
+	if !u.Valid {
+		return ""
+	}
+	return uuid.UUID(u.Bytes).String()
+}
+
+// csvInt4 は pgtype.Int4 を文字列に変換する (NULL は空文字列)
+func csvInt4(v pgtype.Int4) string {
+	if !v.Valid {
+		return ""
+	}
+	return strconv.Itoa(int(v.Int32))
+}
+
+// csvNumeric は pgtype.Numeric を小数表記の文字列に変換する (NULL は空文字列)
+func csvNumeric(n pgtype.Numeric) string {
+	v, err := n.Value()
+	if err != nil || v == nil {
+		return ""
+	}
+	s, _ := v.(string)
+	return s
+}
+
+// pgtypeTimestamptzToPtrString は pgtype.Timestamptz を RFC3339 (JST) の *string に変換する (NULL は nil)
+func pgtypeTimestamptzToPtrString(t pgtype.Timestamptz) *string {
+	if !t.Valid {
+		return nil
+	}
+	s := t.Time.In(jst).Format(time.RFC3339)
+	return &s
+}
+
+// pgtypeDateToPtrString は pgtype.Date を YYYY-MM-DD の *string に変換する (NULL は nil)
+func pgtypeDateToPtrString(d pgtype.Date) *string {
+	if !d.Valid {
+		return nil
+	}
+	s := d.Time.Format(dateLayout)
+	return &s
+}
@@ -0,0 +1,335 @@
+package service
+
+import (
+	"archive/zip"
+	"bytes"
+	"context"
+	"encoding/csv"
+	"encoding/json"
+	"errors"
+	"io"
+	"log/slog"
+	"math/big"
+	"reflect"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/aiirononeko/bulktrack/apps/api/internal/infrastructure/sqlc"
+	"github.com/aiirononeko/bulktrack/apps/api/internal/interfaces/http/dto"
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5/pgtype"
+)
+
+// fakeExportSource は固定の行を返す exportSource (セットは batchSize 件ずつのバッチで返す)
+type fakeExportSource struct {
+	sets            []sqlc.ListExportSetRowsRow
+	batchSize       int
+	menuItems       []sqlc.ListExportMenuItemsRow
+	customExercises []sqlc.ListExportCustomExercisesRow
+	weeklyVolumes   []sqlc.ListExportWeeklyVolumesRow
+	err             error // すべての読み込みで返すエラー
+}
+
+func (f *fakeExportSource) forEachSetRow(ctx context.Context, plan *ExportPlan, fn func([]sqlc.ListExportSetRowsRow) error) error {
+	if f.err != nil {
+		return f.err
+	}
+	size := f.batchSize
+	if size == 0 {
+		size = exportBatchSize
+	}
+	for i := 0; ; i += size {
+		batch := f.sets[i:min(i+size, len(f.sets))]
+		if err := fn(batch); err != nil {
+			return err
+		}
+		if len(batch) < size {
+			return nil
+		}
+	}
+}
+
+func (f *fakeExportSource) listMenuItems(ctx context.Context, plan *ExportPlan) ([]sqlc.ListExportMenuItemsRow, error) {
+	return f.menuItems, f.err
+}
+
+func (f *fakeExportSource) listCustomExercises(ctx context.Context, plan *ExportPlan) ([]sqlc.ListExportCustomExercisesRow, error) {
+	return f.customExercises, f.err
+}
+
+func (f *fakeExportSource) listWeeklyVolumes(ctx context.Context, plan *ExportPlan) ([]sqlc.ListExportWeeklyVolumesRow, error) {
+	return f.weeklyVolumes, f.err
+}
+
+var (
+	exportWorkout1  = uuid.MustParse("11111111-1111-1111-1111-111111111111")
+	exportWorkout2  = uuid.MustParse("22222222-2222-2222-2222-222222222222")
+	exportSet1      = uuid.MustParse("aaaaaaaa-0000-0000-0000-000000000001")
+	exportSet2      = uuid.MustParse("aaaaaaaa-0000-0000-0000-000000000002")
+	exportSet3      = uuid.MustParse("aaaaaaaa-0000-0000-0000-000000000003")
+	exportExercise  = uuid.MustParse("bbbbbbbb-0000-0000-0000-000000000001")
+	exportMenu      = uuid.MustParse("cccccccc-0000-0000-0000-000000000001")
+	exportCustomExe = uuid.MustParse("dddddddd-0000-0000-0000-000000000001")
+)
+
+func testNumeric(unscaled int64, exp int32) pgtype.Numeric {
+	return pgtype.Numeric{Int: big.NewInt(unscaled), Exp: exp, Valid: true}
+}
+
+func testTimestamptz(s string) pgtype.Timestamptz {
+	t, err := time.Parse(time.RFC3339, s)
+	if err != nil {
+		panic(err)
+	}
+	return pgtype.Timestamptz{Time: t, Valid: true}
+}
+
+func testSetRow(workoutID, setID uuid.UUID, startedAt string, setOrder int32, weight pgtype.Numeric, reps int32) sqlc.ListExportSetRowsRow {
+	return sqlc.ListExportSetRowsRow{
+		WorkoutID:    workoutID,
+		StartedAt:    testTimestamptz(startedAt),
+		MenuName:     pgtype.Text{String: "胸の日", Valid: true},
+		SetID:        pgtype.UUID{Bytes: setID, Valid: true},
+		ExerciseID:   pgtype.UUID{Bytes: exportExercise, Valid: true},
+		ExerciseName: pgtype.Text{String: "ベンチプレス", Valid: true},
+		SetOrder:     pgtype.Int4{Int32: setOrder, Valid: true},
+		SetType:      pgtype.Text{String: "working", Valid: true},
+		WeightKg:     weight,
+		Reps:         pgtype.Int4{Int32: reps, Valid: true},
+	}
+}
+
+// newTestExportSource はワークアウト2件 (1件目はセット2件、2件目はセットなし) とメニュー・カスタム種目・週間ボリュームを返す
+func newTestExportSource() *fakeExportSource {
+	set1 := testSetRow(exportWorkout1, exportSet1, "2025-05-12T10:00:00Z", 1, testNumeric(600, -1), 10)
+	set1.Rir = testNumeric(2, 0)
+	set1.GroupKey = pgtype.Text{String: "A", Valid: true}
+	set2 := testSetRow(exportWorkout1, exportSet2, "2025-05-12T10:00:00Z", 2, testNumeric(625, -1), 8)
+	empty := sqlc.ListExportSetRowsRow{
+		WorkoutID:   exportWorkout2,
+		StartedAt:   testTimestamptz("2025-05-14T23:30:00Z"),
+		WorkoutNote: pgtype.Text{String: "休養, \"軽め\"", Valid: true},
+	}
+	return &fakeExportSource{
+		sets: []sqlc.ListExportSetRowsRow{set1, set2, empty},
+		menuItems: []sqlc.ListExportMenuItemsRow{
+			{MenuID: exportMenu, MenuName: "胸の日", CreatedAt: testTimestamptz("2025-05-01T00:00:00Z"), SetOrder: pgtype.Int4{Int32: 1, Valid: true}, ExerciseName: pgtype.Text{String: "ベンチプレス", Valid: true}, PlannedSets: pgtype.Int4{Int32: 3, Valid: true}},
+			{MenuID: exportMenu, MenuName: "胸の日", CreatedAt: testTimestamptz("2025-05-01T00:00:00Z"), SetOrder: pgtype.Int4{Int32: 2, Valid: true}, ExerciseName: pgtype.Text{String: "ディップス", Valid: true}},
+		},
+		customExercises: []sqlc.ListExportCustomExercisesRow{
+			{ID: exportCustomExe, Name: "ケーブルフライ", LoadType: "external", MetricType: "weight_reps", CreatedAt: testTimestamptz("2025-05-02T00:00:00Z")},
+		},
+		weeklyVolumes: []sqlc.ListExportWeeklyVolumesRow{
+			{WeekStartDate: pgtype.Date{Time: time.Date(2025, 5, 12, 0, 0, 0, 0, time.UTC), Valid: true}, TotalVolume: testNumeric(11000, 0), EstOneRm: testNumeric(8000, -2), ExerciseCount: 1, SetCount: 2},
+		},
+	}
+}
+
+func newTestExportService(source exportSource) *ExportService {
+	return &ExportService{source: source, logger: slog.New(slog.NewTextHandler(io.Discard, nil))}
+}
+
+func readCSV(t *testing.T, data string) [][]string {
+	t.Helper()
+	if !strings.HasPrefix(data, utf8BOM) {
+		t.Fatalf("CSV does not start with the UTF-8 BOM: %q", data)
+	}
+	records, err := csv.NewReader(strings.NewReader(strings.TrimPrefix(data, utf8BOM))).ReadAll()
+	if err != nil {
+		t.Fatalf("failed to read CSV: %v", err)
+	}
+	return records
+}
+
+func TestWriteCSV(t *testing.T) {
+	tests := []struct {
+		dataset string
+		want    [][]string
+	}{
+		{
+			dataset: ExportDatasetSets,
+			want: [][]string{
+				exportCSVColumns[ExportDatasetSets],
+				{exportWorkout1.String(), "2025-05-12T19:00:00+09:00", "胸の日", "", exportSet1.String(), exportExercise.String(), "ベンチプレス", "1", "working", "A", "60.0", "10", "2", "", "", "", ""},
+				{exportWorkout1.String(), "2025-05-12T19:00:00+09:00", "胸の日", "", exportSet2.String(), exportExercise.String(), "ベンチプレス", "2", "working", "", "62.5", "8", "", "", "", "", ""},
+				{exportWorkout2.String(), "2025-05-15T08:30:00+09:00", "", "休養, \"軽め\"", "", "", "", "", "", "", "", "", "", "", "", "", ""},
+			},
+		},
+		{
+			dataset: ExportDatasetMenus,
+			want: [][]string{
+				exportCSVColumns[ExportDatasetMenus],
+				{exportMenu.String(), "胸の日", "", "2025-05-01T09:00:00+09:00", "1", "ベンチプレス", "3", "", "", ""},
+				{exportMenu.String(), "胸の日", "", "2025-05-01T09:00:00+09:00", "2", "ディップス", "", "", "", ""},
+			},
+		},
+		{
+			dataset: ExportDatasetCustomExercises,
+			want: [][]string{
+				exportCSVColumns[ExportDatasetCustomExercises],
+				{exportCustomExe.String(), "ケーブルフライ", "", "external", "weight_reps", "2025-05-02T09:00:00+09:00"},
+			},
+		},
+		{
+			dataset: ExportDatasetWeeklyVolumes,
+			want: [][]string{
+				exportCSVColumns[ExportDatasetWeeklyVolumes],
+				{"2025-05-12", "11000", "80.00", "1", "2"},
+			},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.dataset, func(t *testing.T) {
+			s := newTestExportService(newTestExportSource())
+			var buf bytes.Buffer
+			if err := s.writeCSV(context.Background(), &buf, &ExportPlan{}, tt.dataset); err != nil {
+				t.Fatalf("writeCSV() error = %v", err)
+			}
+			if got := readCSV(t, buf.String()); !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("writeCSV() =\n%q\nwant\n%q", got, tt.want)
+			}
+		})
+	}
+}
+
+// セットをバッチに分けて読み込んでも、同じワークアウトのセットは1つのワークアウトにまとまる
+func TestWriteJSON(t *testing.T) {
+	for _, batchSize := range []int{1, 2, exportBatchSize} {
+		source := newTestExportSource()
+		source.batchSize = batchSize
+		s := newTestExportService(source)
+		plan, err := newExportPlan("user_1", ExportFormatJSON, "", "2025-05-01", "2025-05-31")
+		if err != nil {
+			t.Fatalf("newExportPlan() error = %v", err)
+		}
+
+		var buf bytes.Buffer
+		if err := s.writeJSON(context.Background(), &buf, plan); err != nil {
+			t.Fatalf("writeJSON() error = %v", err)
+		}
+		var doc dto.ExportDocument
+		if err := json.Unmarshal(buf.Bytes(), &doc); err != nil {
+			t.Fatalf("writeJSON() wrote invalid JSON: %v\n%s", err, buf.Bytes())
+		}
+
+		if doc.From == nil || *doc.From != "2025-05-01" || doc.To == nil || *doc.To != "2025-05-31" {
+			t.Errorf("batch size %d: from/to = %v/%v", batchSize, doc.From, doc.To)
+		}
+		if len(doc.Workouts) != 2 {
+			t.Fatalf("batch size %d: got %d workouts, want 2", batchSize, len(doc.Workouts))
+		}
+		first, second := doc.Workouts[0], doc.Workouts[1]
+		if first.ID != exportWorkout1 || len(first.Sets) != 2 || first.Sets[0].ID != exportSet1 || first.Sets[1].WeightKg != 62.5 {
+			t.Errorf("batch size %d: first workout = %+v", batchSize, first)
+		}
+		if first.Sets[0].Rir == nil || *first.Sets[0].Rir != 2 || first.Sets[0].Rpe != nil {
+			t.Errorf("batch size %d: rir/rpe = %v/%v, want 2/nil", batchSize, first.Sets[0].Rir, first.Sets[0].Rpe)
+		}
+		if second.ID != exportWorkout2 || second.Sets == nil || len(second.Sets) != 0 || second.Note == nil || second.StartedAt != "2025-05-15T08:30:00+09:00" {
+			t.Errorf("batch size %d: second workout = %+v", batchSize, second)
+		}
+		if len(doc.Menus) != 1 || len(doc.Menus[0].Items) != 2 || doc.Menus[0].Items[1].PlannedSets != nil {
+			t.Errorf("batch size %d: menus = %+v", batchSize, doc.Menus)
+		}
+		if len(doc.CustomExercises) != 1 || doc.CustomExercises[0].MainMuscleGroup != nil {
+			t.Errorf("batch size %d: custom exercises = %+v", batchSize, doc.CustomExercises)
+		}
+		if len(doc.WeeklyVolumes) != 1 || doc.WeeklyVolumes[0].TotalVolume != 11000 || doc.WeeklyVolumes[0].EstOneRM != 80 {
+			t.Errorf("batch size %d: weekly volumes = %+v", batchSize, doc.WeeklyVolumes)
+		}
+	}
+}
+
+// データがない場合も配列は null ではなく空の配列にする
+func TestWriteJSONEmpty(t *testing.T) {
+	s := newTestExportService(&fakeExportSource{})
+	var buf bytes.Buffer
+	if err := s.writeJSON(context.Background(), &buf, &ExportPlan{}); err != nil {
+		t.Fatalf("writeJSON() error = %v", err)
+	}
+	var doc map[string]json.RawMessage
+	if err := json.Unmarshal(buf.Bytes(), &doc); err != nil {
+		t.Fatalf("writeJSON() wrote invalid JSON: %v\n%s", err, buf.Bytes())
+	}
+	for _, key := range []string{"workouts", "menus", "custom_exercises", "weekly_volumes"} {
+		if string(doc[key]) != "[]" {
+			t.Errorf("%s = %s, want []", key, doc[key])
+		}
+	}
+	if string(doc["from"]) != "null" || string(doc["to"]) != "null" {
+		t.Errorf("from/to = %s/%s, want null", doc["from"], doc["to"])
+	}
+}
+
+func TestBuildArchive(t *testing.T) {
+	s := newTestExportService(newTestExportSource())
+	var buf bytes.Buffer
+	if err := s.buildArchive(context.Background(), &buf, &ExportPlan{}); err != nil {
+		t.Fatalf("buildArchive() error = %v", err)
+	}
+
+	zr, err := zip.NewReader(bytes.NewReader(buf.Bytes()), int64(buf.Len()))
+	if err != nil {
+		t.Fatalf("buildArchive() wrote an invalid ZIP archive: %v", err)
+	}
+	files := make(map[string]string)
+	var names []string
+	for _, f := range zr.File {
+		rc, err := f.Open()
+		if err != nil {
+			t.Fatalf("failed to open %s: %v", f.Name, err)
+		}
+		data, err := io.ReadAll(rc)
+		rc.Close()
+		if err != nil {
+			t.Fatalf("failed to read %s: %v", f.Name, err)
+		}
+		names = append(names, f.Name)
+		files[f.Name] = string(data)
+	}
+
+	wantNames := []string{"export.json", "sets.csv", "menus.csv", "custom_exercises.csv", "weekly_volumes.csv"}
+	if !reflect.DeepEqual(names, wantNames) {
+		t.Fatalf("archive files = %v, want %v", names, wantNames)
+	}
+	var doc dto.ExportDocument
+	if err := json.Unmarshal([]byte(files["export.json"]), &doc); err != nil || len(doc.Workouts) != 2 {
+		t.Errorf("export.json = %s (error %v)", files["export.json"], err)
+	}
+	for _, dataset := range exportDatasets {
+		records := readCSV(t, files[dataset+".csv"])
+		if !reflect.DeepEqual(records[0], exportCSVColumns[dataset]) {
+			t.Errorf("%s.csv header = %v, want %v", dataset, records[0], exportCSVColumns[dataset])
+		}
+	}
+	if got := len(readCSV(t, files["sets.csv"])); got != 4 {
+		t.Errorf("sets.csv has %d records, want 4 (header and 3 rows)", got)
+	}
+}
+
+// 読み込みのエラーでアーカイブの作成を中止する (不完全なアーカイブを保存しない)
+func TestBuildArchiveSourceError(t *testing.T) {
+	errSource := errors.New("connection reset")
+	s := newTestExportService(&fakeExportSource{err: errSource})
+	if err := s.buildArchive(context.Background(), io.Discard, &ExportPlan{}); !errors.Is(err, errSource) {
+		t.Errorf("buildArchive() error = %v, want %v", err, errSource)
+	}
+}
+
+// 書き込み先のエラー (クライアントの切断など) を返す
+func TestWriteExportWriterError(t *testing.T) {
+	errWrite := errors.New("broken pipe")
+	s := newTestExportService(newTestExportSource())
+	for _, format := range []string{ExportFormatJSON, ExportFormatCSV} {
+		plan := &ExportPlan{Format: format, Dataset: ExportDatasetSets}
+		if err := s.WriteExport(context.Background(), failingWriter{errWrite}, plan); !errors.Is(err, errWrite) {
+			t.Errorf("WriteExport(%s) error = %v, want %v", format, err, errWrite)
+		}
+	}
+}
+
+// failingWriter は常にエラーを返す io.Writer
+type failingWriter struct{ err error }
+
+func (w failingWriter) Write(p []byte) (int, error) { return 0, w.err }
@@ -0,0 +1,314 @@
+package service
+
+import (
+	"context"
+	"fmt"
+	"log/slog"
+	"time"
+
+	httpError "github.com/aiirononeko/bulktrack/apps/api/internal/http"
+	"github.com/aiirononeko/bulktrack/apps/api/internal/infrastructure/sqlc"
+	"github.com/aiirononeko/bulktrack/apps/api/internal/interfaces/http/dto"
+	"github.com/jackc/pgx/v5/pgtype"
+	"github.com/jackc/pgx/v5/pgxpool"
+	"go.opentelemetry.io/otel/attribute"
+)
+
+// 履歴の集計単位 (Postgres の date_trunc にそのまま渡す)
+const (
+	HistoryIntervalDay   = "day"
+	HistoryIntervalWeek  = "week" // 月曜始まり
+	HistoryIntervalMonth = "month"
+)
+
+// 履歴の1ページあたりの件数 (期間数)
+const (
+	DefaultHistoryPageLimit = 30
+	maxHistoryPageLimit     = 100
+)
+
+// historyIntervalRanges は集計単位ごとの期間未指定時の期間数と、1リクエストで指定できる最大の期間数
+var historyIntervalRanges = map[string]struct{ defaultPeriods, maxPeriods int }{
+	HistoryIntervalDay:   {defaultPeriods: 30, maxPeriods: 93},
+	HistoryIntervalWeek:  {defaultPeriods: 12, maxPeriods: 53},
+	HistoryIntervalMonth: {defaultPeriods: 12, maxPeriods: 24},
+}
+
+// calendarMonthLayout はカレンダーの月 (YYYY-MM) の書式
+const calendarMonthLayout = "2006-01"
+
+// HistoryOptions は履歴集計の条件を表す
+type HistoryOptions struct {
+	Interval string // day / week / month (デフォルトは week)
+	From     string // 開始日 (YYYY-MM-DD, 期間の開始に揃える)
+	To       string // 終了日 (YYYY-MM-DD, 当日を含む期間の終わりまで)
+	TimeZone string // IANA タイムゾーン名 (デフォルトは Asia/Tokyo)
+	Cursor   string // 前ページのレスポンスの next_cursor
+	Limit    int    // 1ページあたりの期間数 (0 はデフォルト)
+}
+
+// HistoryService はトレーニング履歴 (日・週・月ごとの集計、カレンダー) 関連のサービスを提供する
+type HistoryService struct {
+	pool    *pgxpool.Pool
+	queries *sqlc.Queries
+	logger  *slog.Logger
+}
+
+// NewHistoryService は新しい HistoryService を作成する
+func NewHistoryService(pool *pgxpool.Pool, logger *slog.Logger) *HistoryService {
+	return &HistoryService{
+		pool:    pool,
+		queries: sqlc.New(pool),
+		logger:  logger,
+	}
+}
+
+// GetHistory は期間 (日・週・月) ごとのセッション数・セット数・ボリューム・種目ごとの最高重量を新しい順に取得する
+func (s *HistoryService) GetHistory(ctx context.Context, userID string, opts HistoryOptions) (*dto.HistoryResponse, error) {
+	ctx, span := startSpan(ctx, "HistoryService.GetHistory", attribute.String("user_id", userID))
+	defer span.End()
+
+	interval := opts.Interval
+	if interval == "" {
+		interval = HistoryIntervalWeek
+	}
+	ranges, ok := historyIntervalRanges[interval]
+	if !ok {
+		return nil, httpError.NewValidationError("Invalid interval. Expected day, week or month", []httpError.ValidationDetail{
+			{Field: "interval", Reason: "INVALID_VALUE"},
+		})
+	}
+
+	limit := opts.Limit
+	if limit == 0 {
+		limit = DefaultHistoryPageLimit
+	}
+	if limit < 1 || limit > maxHistoryPageLimit {
+		return nil, httpError.NewInvalidLimitError(fmt.Sprintf("Limit must be between 1 and %d", maxHistoryPageLimit), nil).WithDetails([]httpError.ValidationDetail{
+			{Field: "limit", Reason: "RANGE"},
+		})
+	}
+
+	loc, err := loadTimeZone(opts.TimeZone)
+	if err != nil {
+		return nil, err
+	}
+
+	// 期間は集計単位の境界に揃える ([fromStart, toEnd))
+	now := time.Now().In(loc)
+	toDate := time.Date(now.Year(), now.Month(), now.Day(), 0, 0, 0, 0, loc)
+	if opts.To != "" {
+		if toDate, err = parseDateIn("to", opts.To, loc); err != nil {
+			return nil, err
+		}
+	}
+	toEnd := addPeriods(truncateToPeriod(toDate, interval), interval, 1)
+
+	fromStart := addPeriods(toEnd, interval, -ranges.defaultPeriods)
+	if opts.From != "" {
+		fromDate, err := parseDateIn("from", opts.From, loc)
+		if err != nil {
+			return nil, err
+		}
+		fromStart = truncateToPeriod(fromDate, interval)
+	}
+
+	if !fromStart.Before(toEnd) {
+		return nil, httpError.NewValidationError("from must be on or before to", []httpError.ValidationDetail{
+			{Field: "from", Reason: "RANGE"},
+		})
+	}
+	if addPeriods(fromStart, interval, ranges.maxPeriods).Before(toEnd) {
+		return nil, httpError.NewValidationError(fmt.Sprintf("Range must not exceed %d %ss", ranges.maxPeriods, interval), []httpError.ValidationDetail{
+			{Field: "from", Reason: "RANGE"},
+		})
+	}
+
+	var cursor pgtype.Date
+	if opts.Cursor != "" {
+		cursorDate, err := parseDateIn("cursor", opts.Cursor, time.UTC)
+		if err != nil {
+			return nil, err
+		}
+		cursor = pgtype.Date{Time: cursorDate, Valid: true}
+	}
+
+	// 次ページの有無を判定するため1件多く取得する
+	periods, err := s.queries.ListHistoryPeriods(ctx, sqlc.ListHistoryPeriodsParams{
+		Bucket:       interval,
+		Tz:           loc.String(),
+		UserID:       userID,
+		FromTime:     pgtype.Timestamptz{Time: fromStart, Valid: true},
+		ToTime:       pgtype.Timestamptz{Time: toEnd, Valid: true},
+		CursorPeriod: cursor,
+		PageLimit:    int32(limit + 1),
+	})
+	if err != nil {
+		s.logger.ErrorContext(ctx, "Failed to execute ListHistoryPeriods query", slog.Any("error", err), slog.String("user_id", userID), slog.String("interval", interval))
+		return nil, fmt.Errorf("failed to list history periods: %w", err)
+	}
+
+	var nextCursor *string
+	if len(periods) > limit {
+		periods = periods[:limit]
+		c := periods[len(periods)-1].PeriodStart.Time.Format(dateLayout)
+		nextCursor = &c
+	}
+
+	response := &dto.HistoryResponse{
+		Interval:   interval,
+		TimeZone:   loc.String(),
+		From:       fromStart.Format(dateLayout),
+		To:         toEnd.AddDate(0, 0, -1).Format(dateLayout),
+		Periods:    make([]dto.HistoryPeriod, 0, len(periods)),
+		NextCursor: nextCursor,
+	}
+	if len(periods) == 0 {
+		return response, nil
+	}
+
+	// 最高重量はこのページに含まれる期間の分だけ取得する
+	newest := periodStartIn(periods[0].PeriodStart, loc)
+	oldest := periodStartIn(periods[len(periods)-1].PeriodStart, loc)
+	topWeights, err := s.queries.ListHistoryTopWeights(ctx, sqlc.ListHistoryTopWeightsParams{
+		Bucket:   interval,
+		Tz:       loc.String(),
+		UserID:   userID,
+		FromTime: pgtype.Timestamptz{Time: laterOf(fromStart, oldest), Valid: true},
+		ToTime:   pgtype.Timestamptz{Time: earlierOf(toEnd, addPeriods(newest, interval, 1)), Valid: true},
+	})
+	if err != nil {
+		s.logger.ErrorContext(ctx, "Failed to execute ListHistoryTopWeights query", slog.Any("error", err), slog.String("user_id", userID), slog.String("interval", interval))
+		return nil, fmt.Errorf("failed to list history top weights: %w", err)
+	}
+
+	topWeightsByPeriod := make(map[string][]dto.HistoryTopWeight)
+	for _, tw := range topWeights {
+		key := tw.PeriodStart.Time.Format(dateLayout)
+		topWeightsByPeriod[key] = append(topWeightsByPeriod[key], dto.HistoryTopWeight{
+			ExerciseID:   tw.ExerciseID,
+			ExerciseName: tw.ExerciseName,
+			MaxWeightKg:  numericToFloat64(ctx, s.logger, tw.MaxWeightKg),
+			SetCount:     tw.SetCount,
+		})
+	}
+
+	for _, p := range periods {
+		start := periodStartIn(p.PeriodStart, loc)
+		key := start.Format(dateLayout)
+		weights := topWeightsByPeriod[key]
+		if weights == nil {
+			weights = []dto.HistoryTopWeight{}
+		}
+		response.Periods = append(response.Periods, dto.HistoryPeriod{
+			PeriodStart:  key,
+			PeriodEnd:    addPeriods(start, interval, 1).AddDate(0, 0, -1).Format(dateLayout),
+			SessionCount: p.SessionCount,
+			SetCount:     p.SetCount,
+			TotalVolume:  numericToFloat64(ctx, s.logger, p.TotalVolume),
+			TopWeights:   weights,
+		})
+	}
+
+	return response, nil
+}
+
+// GetCalendar は月 (YYYY-MM) のうちトレーニングした日の一覧を取得する (カレンダーのマーカー表示用)
+func (s *HistoryService) GetCalendar(ctx context.Context, userID, month, timeZone string) (*dto.HistoryCalendarResponse, error) {
+	ctx, span := startSpan(ctx, "HistoryService.GetCalendar", attribute.String("user_id", userID), attribute.String("month", month))
+	defer span.End()
+
+	loc, err := loadTimeZone(timeZone)
+	if err != nil {
+		return nil, err
+	}
+
+	now := time.Now().In(loc)
+	monthStart := time.Date(now.Year(), now.Month(), 1, 0, 0, 0, 0, loc)
+	if month != "" {
+		monthStart, err = time.ParseInLocation(calendarMonthLayout, month, loc)
+		if err != nil {
+			return nil, httpError.NewValidationError("Invalid month format. Expected YYYY-MM", []httpError.ValidationDetail{
+				{Field: "month", Reason: "INVALID_FORMAT"},
+			})
+		}
+	}
+	monthEnd := monthStart.AddDate(0, 1, 0)
+
+	days, err := s.queries.ListHistoryPeriods(ctx, sqlc.ListHistoryPeriodsParams{
+		Bucket:    HistoryIntervalDay,
+		Tz:        loc.String(),
+		UserID:    userID,
+		FromTime:  pgtype.Timestamptz{Time: monthStart, Valid: true},
+		ToTime:    pgtype.Timestamptz{Time: monthEnd, Valid: true},
+		PageLimit: 31,
+	})
+	if err != nil {
+		s.logger.ErrorContext(ctx, "Failed to execute ListHistoryPeriods query", slog.Any("error", err), slog.String("user_id", userID), slog.String("month", monthStart.Format(calendarMonthLayout)))
+		return nil, fmt.Errorf("failed to list calendar days: %w", err)
+	}
+
+	// クエリは新しい順のため、カレンダー表示用に古い順に並べ替える
+	result := make([]dto.CalendarDay, 0, len(days))
+	for i := len(days) - 1; i >= 0; i-- {
+		result = append(result, dto.CalendarDay{
+			Date:         days[i].PeriodStart.Time.Format(dateLayout),
+			SessionCount: days[i].SessionCount,
+			SetCount:     days[i].SetCount,
+			TotalVolume:  numericToFloat64(ctx, s.logger, days[i].TotalVolume),
+		})
+	}
+
+	return &dto.HistoryCalendarResponse{
+		Month:    monthStart.Format(calendarMonthLayout),
+		TimeZone: loc.String(),
+		Days:     result,
+	}, nil
+}
+
+// truncateToPeriod は日時を集計単位の開始 (日: 0:00 / 週: 月曜 0:00 / 月: 1日 0:00) に切り捨てる
+func truncateToPeriod(t time.Time, interval string) time.Time {
+	day := time.Date(t.Year(), t.Month(), t.Day(), 0, 0, 0, 0, t.Location())
+	switch interval {
+	case HistoryIntervalWeek:
+		offset := (int(day.Weekday()) + 6) % 7 // 月曜日からの日数
+		return day.AddDate(0, 0, -offset)
+	case HistoryIntervalMonth:
+		return time.Date(t.Year(), t.Month(), 1, 0, 0, 0, 0, t.Location())
+	default:
+		return day
+	}
+}
+
+// addPeriods は集計単位で n 期間分進めた日時を返す (n が負の場合は戻す)
+func addPeriods(t time.Time, interval string, n int) time.Time {
+	switch interval {
+	case HistoryIntervalWeek:
+		return t.AddDate(0, 0, 7*n)
+	case HistoryIntervalMonth:
+		return t.AddDate(0, n, 0)
+	default:
+		return t.AddDate(0, 0, n)
+	}
+}
+
+// periodStartIn はクエリが返す期間の開始日を指定したタイムゾーンの 0:00 に変換する
+func periodStartIn(d pgtype.Date, loc *time.Location) time.Time {
+	return time.Date(d.Time.Year(), d.Time.Month(), d.Time.Day(), 0, 0, 0, 0, loc)
+}
+
+// laterOf は2つの日時のうち遅い方を返す
+func laterOf(a, b time.Time) time.Time {
+	if a.After(b) {
+		return a
+	}
+	return b
+}
+
+// earlierOf は2つの日時のうち早い方を返す
+func earlierOf(a, b time.Time) time.Time {
+	if a.Before(b) {
+		return a
+	}
+	return b
+}
@@ -0,0 +1,68 @@
+package service
+
+import (
+	"testing"
+	"time"
+)
+
+func TestTruncateToPeriod(t *testing.T) {
+	tokyo, err := time.LoadLocation("Asia/Tokyo")
+	if err != nil {
+		t.Fatalf("time.LoadLocation() error = %v", err)
+	}
+	at := func(value string) time.Time {
+		t.Helper()
+		parsed, err := time.ParseInLocation("2006-01-02 15:04", value, tokyo)
+		if err != nil {
+			t.Fatalf("time.ParseInLocation(%q) error = %v", value, err)
+		}
+		return parsed
+	}
+
+	tests := []struct {
+		name     string
+		t        time.Time
+		interval string
+		want     time.Time
+	}{
+		{name: "day just before midnight", t: at("2025-05-04 23:59"), interval: HistoryIntervalDay, want: at("2025-05-04 00:00")},
+		// UTC ではまだ前日 (2025-05-04 15:30Z) でも JST の日付で区切る
+		{name: "day just after midnight", t: at("2025-05-05 00:30"), interval: HistoryIntervalDay, want: at("2025-05-05 00:00")},
+		{name: "week on Sunday belongs to the previous Monday", t: at("2025-05-04 23:59"), interval: HistoryIntervalWeek, want: at("2025-04-28 00:00")},
+		{name: "week on Monday", t: at("2025-05-05 00:30"), interval: HistoryIntervalWeek, want: at("2025-05-05 00:00")},
+		{name: "week across a month", t: at("2025-06-01 00:10"), interval: HistoryIntervalWeek, want: at("2025-05-26 00:00")},
+		{name: "month on the last day", t: at("2025-05-31 23:30"), interval: HistoryIntervalMonth, want: at("2025-05-01 00:00")},
+		{name: "month on the first day", t: at("2025-06-01 00:10"), interval: HistoryIntervalMonth, want: at("2025-06-01 00:00")},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := truncateToPeriod(tt.t, tt.interval); !got.Equal(tt.want) {
+				t.Errorf("truncateToPeriod(%v, %s) = %v, want %v", tt.t, tt.interval, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestAddPeriods(t *testing.T) {
+	tokyo, err := time.LoadLocation("Asia/Tokyo")
+	if err != nil {
+		t.Fatalf("time.LoadLocation() error = %v", err)
+	}
+	start := time.Date(2025, 1, 27, 0, 0, 0, 0, tokyo)
+	tests := []struct {
+		interval string
+		n        int
+		want     time.Time
+	}{
+		{interval: HistoryIntervalDay, n: 5, want: time.Date(2025, 2, 1, 0, 0, 0, 0, tokyo)},
+		{interval: HistoryIntervalWeek, n: 1, want: time.Date(2025, 2, 3, 0, 0, 0, 0, tokyo)},
+		{interval: HistoryIntervalWeek, n: -4, want: time.Date(2024, 12, 30, 0, 0, 0, 0, tokyo)},
+		{interval: HistoryIntervalMonth, n: 1, want: time.Date(2025, 2, 27, 0, 0, 0, 0, tokyo)},
+		{interval: HistoryIntervalMonth, n: -12, want: time.Date(2024, 1, 27, 0, 0, 0, 0, tokyo)},
+	}
+	for _, tt := range tests {
+		if got := addPeriods(start, tt.interval, tt.n); !got.Equal(tt.want) {
+			t.Errorf("addPeriods(%v, %s, %d) = %v, want %v", start, tt.interval, tt.n, got, tt.want)
+		}
+	}
+}
@@ -0,0 +1,263 @@
+package service
+
+import (
+	"sort"
+	"strings"
+	"unicode"
+
+	"github.com/google/uuid"
+)
+
+// 取り込み元の種目名の解決方法
+const (
+	ImportMatchExact      = "exact"      // 種目名が一致
+	ImportMatchAlias      = "alias"      // 英語名の別名が一致
+	ImportMatchFuzzy      = "fuzzy"      // 種目名が十分に類似
+	ImportMatchMapped     = "mapped"     // exercise_mappings で指定
+	ImportMatchCreate     = "create"     // カスタム種目として作成
+	ImportMatchUnresolved = "unresolved" // 解決できない (exercise_mappings か create_missing が必要)
+)
+
+// 種目名の類似度のしきい値
+const (
+	importFuzzyAutoMatch = 0.85 // これ以上なら自動で一致とみなす
+	importFuzzyCandidate = 0.5  // これ以上なら候補として返す
+)
+
+// importCandidateLimit は解決できない種目名に返す候補の最大数
+const importCandidateLimit = 3
+
+// importExerciseAliases は取り込み元のアプリの英語の種目名と組み込み種目名の対応 (正規化した名前で引く)
+var importExerciseAliases = map[string]string{
+	"bench press":                 "ベンチプレス",
+	"bench press barbell":         "ベンチプレス",
+	"flat barbell bench press":    "ベンチプレス",
+	"incline bench press":         "インクラインベンチプレス",
+	"incline bench press barbell": "インクラインベンチプレス",
+	"close grip bench press":      "クローズグリップベンチプレス",
+	"dumbbell fly":                "ダンベルフライ",
+	"chest fly dumbbell":          "ダンベルフライ",
+	"push up":                     "プッシュアップ",
+	"pushup":                      "プッシュアップ",
+	"dip":                         "ディップス",
+	"dips":                        "ディップス",
+	"triceps dip":                 "ディップス",
+	"squat":                       "スクワット",
+	"squat barbell":               "スクワット",
+	"barbell squat":               "スクワット",
+	"back squat":                  "スクワット",
+	"bulgarian split squat":       "ブルガリアンスクワット",
+	"lunge":                       "ランジ",
+	"hip thrust":                  "ヒップスラスト",
+	"hip thrust barbell":          "ヒップスラスト",
+	"leg press":                   "レッグプレス",
+	"leg extension":               "レッグエクステンション",
+	"leg extension machine":       "レッグエクステンション",
+	"leg curl":                    "レッグカール",
+	"lying leg curl machine":      "レッグカール",
+	"seated leg curl machine":     "レッグカール",
+	"calf raise":                  "カーフレイズ",
+	"standing calf raise":         "カーフレイズ",
+	"good morning":                "グッドモーニング",
+	"deadlift":                    "デッドリフト",
+	"conventional deadlift":       "デッドリフト",
+	"deadlift barbell":            "デッドリフト",
+	"romanian deadlift":           "ルーマニアンデッドリフト",
+	"romanian deadlift barbell":   "ルーマニアンデッドリフト",
+	"bent over row":               "ベントオーバーロウ",
+	"bent over row barbell":       "ベントオーバーロウ",
+	"t bar row":                   "Tバーロウ",
+	"cable row":                   "ケーブルロウ",
+	"seated cable row":            "シーテッドロウ",
+	"seated row":                  "シーテッドロウ",
+	"lat pulldown":                "ラットプルダウン",
+	"lat pulldown cable":          "ラットプルダウン",
+	"pull up":                     "懸垂",
+	"pullup":                      "懸垂",
+	"chin up":                     "懸垂",
+	"pull up assisted":            "アシスト懸垂",
+	"dip assisted":                "アシストディップス",
+	"overhead press":              "ショルダープレス",
+	"overhead press barbell":      "ショルダープレス",
+	"shoulder press":              "ショルダープレス",
+	"shoulder press dumbbell":     "ショルダープレス",
+	"arnold press":                "アーノルドプレス",
+	"arnold press dumbbell":       "アーノルドプレス",
+	"lateral raise":               "サイドレイズ",
+	"lateral raise dumbbell":      "サイドレイズ",
+	"front raise":                 "フロントレイズ",
+	"front raise dumbbell":        "フロントレイズ",
+	"rear delt fly":               "リアデルトフライ",
+	"reverse fly":                 "リアデルトフライ",
+	"shrug":                       "シュラッグ",
+	"shrug barbell":               "シュラッグ",
+	"bicep curl":                  "アームカール",
+	"bicep curl barbell":          "アームカール",
+	"bicep curl dumbbell":         "アームカール",
+	"hammer curl":                 "ハンマーカール",
+	"hammer curl dumbbell":        "ハンマーカール",
+	"preacher curl":               "プリーチャーカール",
+	"triceps extension":           "トライセプスエクステンション",
+	"skullcrusher":                "スカルクラッシャー",
+	"skull crusher":               "スカルクラッシャー",
+	"triceps pushdown":            "ケーブルプレスダウン",
+	"triceps pushdown cable":      "ケーブルプレスダウン",
+	"crunch":                      "クランチ",
+	"leg raise":                   "レッグレイズ",
+	"hanging leg raise":           "レッグレイズ",
+	"plank":                       "プランク",
+	"russian twist":               "ロシアンツイスト",
+	"ab wheel":                    "アブローラー",
+	"back extension":              "バックエクステンション",
+	"kettlebell swing":            "ケトルベルスイング",
+	"rowing machine":              "ローイングマシン",
+	"rowing":                      "ローイングマシン",
+	"cycling":                     "エアロバイク",
+	"stationary bike":             "エアロバイク",
+	"running":                     "ランニング",
+	"treadmill":                   "ランニング",
+	"walking":                     "ウォーキング",
+	"jump rope":                   "縄跳び",
+}
+
+// importExercise は取り込み先の種目 (組み込み種目とユーザーのカスタム種目) を表す
+type importExercise struct {
+	ID         uuid.UUID
+	Name       string
+	MetricType string
+	normalized string
+}
+
+// importExerciseMatch は種目名の解決結果を表す
+type importExerciseMatch struct {
+	Status     string
+	Exercise   *importExercise
+	Similarity float64
+	Candidates []importExerciseCandidate
+}
+
+// importExerciseCandidate は解決できない種目名の候補を表す
+type importExerciseCandidate struct {
+	Exercise   *importExercise
+	Similarity float64
+}
+
+// importExerciseMatcher は取り込み元の種目名を取り込み先の種目に解決する
+type importExerciseMatcher struct {
+	exercises []*importExercise
+	byName    map[string]*importExercise
+}
+
+// newImportExerciseMatcher は種目一覧から importExerciseMatcher を作成する
+// 同じ正規化名の種目がある場合はユーザーのカスタム種目より先に渡した種目 (組み込み種目) を優先する
+func newImportExerciseMatcher(exercises []importExercise) *importExerciseMatcher {
+	m := &importExerciseMatcher{byName: make(map[string]*importExercise, len(exercises))}
+	for i := range exercises {
+		e := &exercises[i]
+		e.normalized = normalizeExerciseName(e.Name)
+		m.exercises = append(m.exercises, e)
+		if _, ok := m.byName[e.normalized]; !ok {
+			m.byName[e.normalized] = e
+		}
+	}
+	return m
+}
+
+// add は取り込み中に作成する種目を追加する
+func (m *importExerciseMatcher) add(e *importExercise) {
+	e.normalized = normalizeExerciseName(e.Name)
+	m.exercises = append(m.exercises, e)
+	if _, ok := m.byName[e.normalized]; !ok {
+		m.byName[e.normalized] = e
+	}
+}
+
+// byID は ID の種目を返す
+func (m *importExerciseMatcher) byID(id uuid.UUID) *importExercise {
+	for _, e := range m.exercises {
+		if e.ID == id {
+			return e
+		}
+	}
+	return nil
+}
+
+// match は種目名を 完全一致 → 別名 → 類似度 の順に解決する
+func (m *importExerciseMatcher) match(name string) importExerciseMatch {
+	normalized := normalizeExerciseName(name)
+	if e, ok := m.byName[normalized]; ok {
+		return importExerciseMatch{Status: ImportMatchExact, Exercise: e, Similarity: 1}
+	}
+	if alias, ok := importExerciseAliases[normalized]; ok {
+		if e, ok := m.byName[normalizeExerciseName(alias)]; ok {
+			return importExerciseMatch{Status: ImportMatchAlias, Exercise: e, Similarity: 1}
+		}
+	}
+
+	var candidates []importExerciseCandidate
+	for _, e := range m.exercises {
+		if similarity := nameSimilarity(normalized, e.normalized); similarity >= importFuzzyCandidate {
+			candidates = append(candidates, importExerciseCandidate{Exercise: e, Similarity: similarity})
+		}
+	}
+	sort.SliceStable(candidates, func(i, j int) bool {
+		return candidates[i].Similarity > candidates[j].Similarity
+	})
+	if len(candidates) > 0 && candidates[0].Similarity >= importFuzzyAutoMatch {
+		return importExerciseMatch{Status: ImportMatchFuzzy, Exercise: candidates[0].Exercise, Similarity: candidates[0].Similarity}
+	}
+	if len(candidates) > importCandidateLimit {
+		candidates = candidates[:importCandidateLimit]
+	}
+	return importExerciseMatch{Status: ImportMatchUnresolved, Candidates: candidates}
+}
+
+// normalizeExerciseName は比較用に種目名を正規化する
+// 小文字化し、括弧・記号を空白として扱い、連続する空白を1つにまとめる ("Pull-Up (Assisted)" → "pull up assisted")
+func normalizeExerciseName(name string) string {
+	var b strings.Builder
+	space := false
+	for _, r := range strings.ToLower(name) {
+		if unicode.IsLetter(r) || unicode.IsNumber(r) {
+			if space && b.Len() > 0 {
+				b.WriteByte(' ')
+			}
+			b.WriteRune(r)
+			space = false
+			continue
+		}
+		space = true
+	}
+	return b.String()
+}
+
+// nameSimilarity はレーベンシュタイン距離による 0〜1 の類似度を返す
+func nameSimilarity(a, b string) float64 {
+	ra, rb := []rune(a), []rune(b)
+	if len(ra) == 0 && len(rb) == 0 {
+		return 1
+	}
+	maxLen := max(len(ra), len(rb))
+	return 1 - float64(levenshtein(ra, rb))/float64(maxLen)
+}
+
+// levenshtein は2つの文字列のレーベンシュタイン距離を返す
+func levenshtein(a, b []rune) int {
+	prev := make([]int, len(b)+1)
+	curr := make([]int, len(b)+1)
+	for j := range prev {
+		prev[j] = j
+	}
+	for i := 1; i <= len(a); i++ {
+		curr[0] = i
+		for j := 1; j <= len(b); j++ {
+			cost := 1
+			if a[i-1] == b[j-1] {
+				cost = 0
+			}
+			curr[j] = min(prev[j]+1, curr[j-1]+1, prev[j-1]+cost)
+		}
+		prev, curr = curr, prev
+	}
+	return prev[len(b)]
+}
@@ -0,0 +1,122 @@
+package service
+
+import (
+	"math"
+	"reflect"
+	"testing"
+
+	"github.com/google/uuid"
+)
+
+func TestNormalizeExerciseName(t *testing.T) {
+	tests := []struct {
+		name string
+		want string
+	}{
+		{name: "Pull-Up (Assisted)", want: "pull up assisted"},
+		{name: "  Bench Press  (Barbell) ", want: "bench press barbell"},
+		{name: "T-Bar Row", want: "t bar row"},
+		{name: "ベンチプレス", want: "ベンチプレス"},
+		{name: "21s", want: "21s"},
+		{name: "--", want: ""},
+	}
+	for _, tt := range tests {
+		if got := normalizeExerciseName(tt.name); got != tt.want {
+			t.Errorf("normalizeExerciseName(%q) = %q, want %q", tt.name, got, tt.want)
+		}
+	}
+}
+
+func TestNameSimilarity(t *testing.T) {
+	tests := []struct {
+		a, b string
+		want float64
+	}{
+		{a: "", b: "", want: 1},
+		{a: "squat", b: "squat", want: 1},
+		{a: "cable crossovers", b: "cable crossover", want: 1 - 1.0/16},
+		{a: "cable cross", b: "cable crossover", want: 1 - 4.0/15},
+		{a: "スクワット", b: "スクワッド", want: 1 - 1.0/5}, // 文字数はルーンで数える
+		{a: "squat", b: "", want: 0},
+	}
+	for _, tt := range tests {
+		if got := nameSimilarity(tt.a, tt.b); math.Abs(got-tt.want) > 1e-9 {
+			t.Errorf("nameSimilarity(%q, %q) = %v, want %v", tt.a, tt.b, got, tt.want)
+		}
+	}
+}
+
+func TestImportExerciseMatcher(t *testing.T) {
+	benchPress := uuid.New()
+	squat := uuid.New()
+	customBench := uuid.New()
+	frontSquat := uuid.New()
+	hackSquat := uuid.New()
+	sissySquat := uuid.New()
+	boxSquat := uuid.New()
+	crossover := uuid.New()
+
+	// 組み込み種目を先に渡し、同じ正規化名のカスタム種目より優先させる
+	matcher := newImportExerciseMatcher([]importExercise{
+		{ID: benchPress, Name: "ベンチプレス", MetricType: MetricTypeWeightReps},
+		{ID: squat, Name: "スクワット", MetricType: MetricTypeWeightReps},
+		{ID: customBench, Name: "ベンチプレス ", MetricType: MetricTypeWeightReps},
+		{ID: frontSquat, Name: "Front Squat", MetricType: MetricTypeWeightReps},
+		{ID: hackSquat, Name: "Hack Squat", MetricType: MetricTypeWeightReps},
+		{ID: sissySquat, Name: "Sissy Squat", MetricType: MetricTypeWeightReps},
+		{ID: boxSquat, Name: "Box Squat", MetricType: MetricTypeWeightReps},
+		{ID: crossover, Name: "Cable Crossover", MetricType: MetricTypeWeightReps},
+	})
+
+	tests := []struct {
+		name           string
+		source         string
+		wantStatus     string
+		wantID         uuid.UUID
+		wantCandidates []uuid.UUID
+	}{
+		{name: "exact match ignores case and symbols", source: "cable-crossover", wantStatus: ImportMatchExact, wantID: crossover},
+		{name: "builtin wins over custom with the same normalized name", source: "ベンチプレス", wantStatus: ImportMatchExact, wantID: benchPress},
+		{name: "alias of the source app", source: "Bench Press (Barbell)", wantStatus: ImportMatchAlias, wantID: benchPress},
+		{name: "alias without symbols", source: "SQUAT", wantStatus: ImportMatchAlias, wantID: squat},
+		// 類似度 0.9375 (>= 0.85) は自動で一致とみなす
+		{name: "fuzzy above the auto-match threshold", source: "Cable Crossovers", wantStatus: ImportMatchFuzzy, wantID: crossover},
+		// 類似度 0.82 (< 0.85) は候補のみ返す。Hack Squat はちょうど 0.5 で候補に含む
+		{name: "similar name below the auto-match threshold", source: "Frnt Sqat", wantStatus: ImportMatchUnresolved, wantCandidates: []uuid.UUID{frontSquat, hackSquat}},
+		// 類似度 0.5 以上の候補は類似度順に3件まで (同じ類似度は渡した順)
+		{name: "candidates are limited", source: "Split Squat", wantStatus: ImportMatchUnresolved, wantCandidates: []uuid.UUID{frontSquat, sissySquat, hackSquat}},
+		// 類似度 0.5 未満は候補にしない
+		{name: "no candidates", source: "Zercher Carry", wantStatus: ImportMatchUnresolved},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := matcher.match(tt.source)
+			if got.Status != tt.wantStatus {
+				t.Fatalf("match(%q).Status = %s, want %s", tt.source, got.Status, tt.wantStatus)
+			}
+			if tt.wantID != uuid.Nil && (got.Exercise == nil || got.Exercise.ID != tt.wantID) {
+				t.Errorf("match(%q).Exercise = %+v, want %s", tt.source, got.Exercise, tt.wantID)
+			}
+			var candidates []uuid.UUID
+			for _, c := range got.Candidates {
+				if c.Similarity < importFuzzyCandidate || c.Similarity >= importFuzzyAutoMatch {
+					t.Errorf("match(%q) candidate %s similarity = %v, want in [%v, %v)", tt.source, c.Exercise.Name, c.Similarity, importFuzzyCandidate, importFuzzyAutoMatch)
+				}
+				candidates = append(candidates, c.Exercise.ID)
+			}
+			if !reflect.DeepEqual(candidates, tt.wantCandidates) {
+				t.Errorf("match(%q).Candidates = %v, want %v", tt.source, candidates, tt.wantCandidates)
+			}
+		})
+	}
+
+	// 取り込み中に作成した種目は以降の完全一致の対象になる
+	created := &importExercise{ID: uuid.New(), Name: "Zercher Carry", MetricType: MetricTypeWeightReps}
+	matcher.add(created)
+	if got := matcher.match("zercher carry"); got.Status != ImportMatchExact || got.Exercise != created {
+		t.Errorf("match() after add = %+v, want the created exercise", got)
+	}
+	if got := matcher.byID(created.ID); got != created {
+		t.Errorf("byID() = %+v, want the created exercise", got)
+	}
+}
@@ -0,0 +1,539 @@
+package service
+
+import (
+	"bufio"
+	"encoding/csv"
+	"errors"
+	"fmt"
+	"io"
+	"math"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	httpError "github.com/aiirononeko/bulktrack/apps/api/internal/http"
+	"github.com/aiirononeko/bulktrack/apps/api/internal/interfaces/http/dto"
+)
+
+// 取り込み元のアプリ (CSV の形式)
+const (
+	ImportSourceStrong   = "strong"
+	ImportSourceHevy     = "hevy"
+	ImportSourceFitNotes = "fitnotes"
+)
+
+// 重量・距離の単位 (Strong の CSV は単位を含まないため指定する)
+const (
+	WeightUnitKg   = "kg"
+	WeightUnitLb   = "lb"
+	DistanceUnitKm = "km"
+	DistanceUnitMi = "mi"
+)
+
+// 単位の換算
+const (
+	kgPerLb = 0.45359237
+	mPerMi  = 1609.344
+	mPerFt  = 0.3048
+	mPerYd  = 0.9144
+)
+
+// maxImportRows は1回の取り込みで読み込む CSV の最大行数
+const maxImportRows = 100000
+
+// maxImportWeightKg はセットの重量の上限 (NUMERIC(5,2))
+const maxImportWeightKg = 999.99
+
+// importedSet は取り込み元の CSV から読み取ったセットを表す
+type importedSet struct {
+	Row             int // CSV の行番号 (ヘッダーが1行目)
+	ExerciseName    string
+	SetType         string
+	GroupKey        string
+	WeightKg        float64
+	Reps            int32
+	DurationSeconds *int32
+	DistanceM       *float64
+	Rpe             *float64
+}
+
+// importedWorkout は取り込み元の CSV から読み取ったワークアウトを表す
+type importedWorkout struct {
+	StartedAt time.Time
+	Note      string
+	Sets      []importedSet
+}
+
+// importParseOptions は CSV の読み取り条件を表す
+type importParseOptions struct {
+	Location     *time.Location // CSV の日時のタイムゾーン
+	WeightUnit   string         // Strong の重量の単位
+	DistanceUnit string         // Strong の距離の単位
+}
+
+// importCSV はヘッダー名 (小文字) で列を参照できる CSV を表す
+type importCSV struct {
+	reader  *csv.Reader
+	columns map[string]int
+	row     int
+}
+
+// newImportCSV は CSV を開いてヘッダーを読み込む (区切り文字はカンマとセミコロンを判別する)
+func newImportCSV(r io.Reader) (*importCSV, error) {
+	br := bufio.NewReader(r)
+	firstLine, err := br.Peek(4096)
+	if err != nil && !errors.Is(err, io.EOF) && !errors.Is(err, bufio.ErrBufferFull) {
+		return nil, err
+	}
+	if i := strings.IndexByte(string(firstLine), '\n'); i >= 0 {
+		firstLine = firstLine[:i]
+	}
+
+	reader := csv.NewReader(br)
+	reader.FieldsPerRecord = -1
+	reader.LazyQuotes = true
+	reader.ReuseRecord = false
+	if strings.Count(string(firstLine), ";") > strings.Count(string(firstLine), ",") {
+		reader.Comma = ';'
+	}
+
+	header, err := reader.Read()
+	if err != nil {
+		return nil, invalidImportFile("The file is empty or is not a CSV file")
+	}
+	columns := make(map[string]int, len(header))
+	for i, name := range header {
+		name = strings.TrimPrefix(name, utf8BOM)
+		columns[strings.ToLower(strings.TrimSpace(name))] = i
+	}
+	return &importCSV{reader: reader, columns: columns, row: 1}, nil
+}
+
+// require は必須の列がすべてあることを確認する
+func (c *importCSV) require(source string, names ...string) error {
+	var missing []string
+	for _, name := range names {
+		if _, ok := c.columns[name]; !ok {
+			missing = append(missing, name)
+		}
+	}
+	if len(missing) > 0 {
+		return invalidImportFile(fmt.Sprintf("The file is not a %s CSV export. Missing columns: %s", source, strings.Join(missing, ", ")))
+	}
+	return nil
+}
+
+// has は列があるかどうかを返す
+func (c *importCSV) has(name string) bool {
+	_, ok := c.columns[name]
+	return ok
+}
+
+// next は次の行を読み込む (終端の場合は nil)
+func (c *importCSV) next() ([]string, error) {
+	record, err := c.reader.Read()
+	if errors.Is(err, io.EOF) {
+		return nil, nil
+	}
+	c.row++
+	if err != nil {
+		return nil, invalidImportFile(fmt.Sprintf("Failed to read CSV at row %d: %v", c.row, err))
+	}
+	if c.row > maxImportRows+1 {
+		return nil, invalidImportFile(fmt.Sprintf("The file has more than %d rows. Split the file and import each part", maxImportRows))
+	}
+	return record, nil
+}
+
+// get は列の値を返す (列がない場合は空文字列)
+func (c *importCSV) get(record []string, name string) string {
+	i, ok := c.columns[name]
+	if !ok || i >= len(record) {
+		return ""
+	}
+	return strings.TrimSpace(record[i])
+}
+
+// importWorkoutBuilder は行をワークアウトごとにまとめる
+type importWorkoutBuilder struct {
+	workouts []*importedWorkout
+	index    map[string]*importedWorkout
+	warnings []dto.ImportWarning
+}
+
+func newImportWorkoutBuilder() *importWorkoutBuilder {
+	return &importWorkoutBuilder{index: make(map[string]*importedWorkout)}
+}
+
+// workout はキーに対応するワークアウトを返す (なければ作成する)
+func (b *importWorkoutBuilder) workout(key string, startedAt time.Time, note string) *importedWorkout {
+	if w, ok := b.index[key]; ok {
+		return w
+	}
+	w := &importedWorkout{StartedAt: startedAt, Note: note}
+	b.index[key] = w
+	b.workouts = append(b.workouts, w)
+	return w
+}
+
+// warn は読み飛ばした行の警告を追加する
+func (b *importWorkoutBuilder) warn(row int, format string, args ...any) {
+	b.warnings = append(b.warnings, dto.ImportWarning{Row: row, Message: fmt.Sprintf(format, args...)})
+}
+
+// result はワークアウトを開始日時順に返す
+func (b *importWorkoutBuilder) result() ([]importedWorkout, []dto.ImportWarning) {
+	workouts := make([]importedWorkout, 0, len(b.workouts))
+	for _, w := range b.workouts {
+		workouts = append(workouts, *w)
+	}
+	sort.SliceStable(workouts, func(i, j int) bool {
+		return workouts[i].StartedAt.Before(workouts[j].StartedAt)
+	})
+	return workouts, b.warnings
+}
+
+// parseImportCSV は取り込み元のアプリの CSV をワークアウトに変換する
+// 読み取れない行は読み飛ばして警告を返す
+func parseImportCSV(source string, r io.Reader, opts importParseOptions) ([]importedWorkout, []dto.ImportWarning, error) {
+	c, err := newImportCSV(r)
+	if err != nil {
+		return nil, nil, err
+	}
+	switch source {
+	case ImportSourceStrong:
+		return parseStrongCSV(c, opts)
+	case ImportSourceHevy:
+		return parseHevyCSV(c, opts)
+	case ImportSourceFitNotes:
+		return parseFitNotesCSV(c, opts)
+	default:
+		return nil, nil, httpError.NewValidationError("Invalid source. Expected strong, hevy or fitnotes", []httpError.ValidationDetail{
+			{Field: "source", Reason: "INVALID_VALUE"},
+		})
+	}
+}
+
+// parseStrongCSV は Strong の CSV を読み取る
+//
+//	Date,Workout Name,Duration,Exercise Name,Set Order,Weight,Reps,Distance,Seconds,Notes,Workout Notes,RPE
+//
+// Set Order は数値 (通常セット) または W (ウォームアップ) / D (ドロップ) / F (限界)。Rest Timer などの行は読み飛ばす
+func parseStrongCSV(c *importCSV, opts importParseOptions) ([]importedWorkout, []dto.ImportWarning, error) {
+	if err := c.require("Strong", "date", "workout name", "exercise name", "set order", "weight", "reps"); err != nil {
+		return nil, nil, err
+	}
+	weightFactor := 1.0
+	if opts.WeightUnit == WeightUnitLb {
+		weightFactor = kgPerLb
+	}
+	distanceFactor := 1000.0
+	if opts.DistanceUnit == DistanceUnitMi {
+		distanceFactor = mPerMi
+	}
+
+	b := newImportWorkoutBuilder()
+	for {
+		record, err := c.next()
+		if err != nil {
+			return nil, nil, err
+		}
+		if record == nil {
+			break
+		}
+
+		setType := SetTypeWorking
+		switch setOrder := strings.ToUpper(c.get(record, "set order")); setOrder {
+		case "W":
+			setType = SetTypeWarmup
+		case "D":
+			setType = SetTypeDrop
+		case "F":
+			setType = SetTypeFailure
+		default:
+			if _, err := strconv.Atoi(setOrder); err != nil {
+				continue // Rest Timer・Note などセット以外の行
+			}
+		}
+
+		dateStr := c.get(record, "date")
+		startedAt, ok := parseImportTime(dateStr, opts.Location, "2006-01-02 15:04:05", "2006-01-02 15:04")
+		if !ok {
+			b.warn(c.row, "Invalid date %q", dateStr)
+			continue
+		}
+		set, err := readImportSet(c, record, importSetColumns{
+			exercise: "exercise name", weight: "weight", reps: "reps", distance: "distance", seconds: "seconds", rpe: "rpe",
+		}, weightFactor, distanceFactor)
+		if err != nil {
+			b.warn(c.row, "%v", err)
+			continue
+		}
+		set.SetType = setType
+
+		name := c.get(record, "workout name")
+		note := joinImportNote(name, c.get(record, "workout notes"))
+		w := b.workout(dateStr+"\x00"+name, startedAt, note)
+		w.Sets = append(w.Sets, set)
+	}
+
+	workouts, warnings := b.result()
+	return workouts, warnings, nil
+}
+
+// parseHevyCSV は Hevy の CSV を読み取る
+//
+//	"title","start_time","end_time","description","exercise_title","superset_id","exercise_notes","set_index","set_type","weight_kg","reps","distance_km","duration_seconds","rpe"
+//
+// 重量・距離はヘッダーの単位 (weight_kg / weight_lbs, distance_km / distance_miles) で換算する
+func parseHevyCSV(c *importCSV, opts importParseOptions) ([]importedWorkout, []dto.ImportWarning, error) {
+	if err := c.require("Hevy", "title", "start_time", "exercise_title", "set_type", "reps"); err != nil {
+		return nil, nil, err
+	}
+	cols := importSetColumns{exercise: "exercise_title", reps: "reps", seconds: "duration_seconds", rpe: "rpe"}
+	weightFactor, distanceFactor := 1.0, 1000.0
+	switch {
+	case c.has("weight_kg"):
+		cols.weight = "weight_kg"
+	case c.has("weight_lbs"):
+		cols.weight, weightFactor = "weight_lbs", kgPerLb
+	default:
+		return nil, nil, c.require("Hevy", "weight_kg")
+	}
+	switch {
+	case c.has("distance_km"):
+		cols.distance = "distance_km"
+	case c.has("distance_miles"):
+		cols.distance, distanceFactor = "distance_miles", mPerMi
+	}
+
+	b := newImportWorkoutBuilder()
+	for {
+		record, err := c.next()
+		if err != nil {
+			return nil, nil, err
+		}
+		if record == nil {
+			break
+		}
+
+		startStr := c.get(record, "start_time")
+		startedAt, ok := parseImportTime(startStr, opts.Location, "2 Jan 2006, 15:04", "2 Jan 2006 15:04", "2006-01-02 15:04:05", "2006-01-02T15:04:05Z07:00")
+		if !ok {
+			b.warn(c.row, "Invalid start_time %q", startStr)
+			continue
+		}
+		set, err := readImportSet(c, record, cols, weightFactor, distanceFactor)
+		if err != nil {
+			b.warn(c.row, "%v", err)
+			continue
+		}
+		switch c.get(record, "set_type") {
+		case "warmup":
+			set.SetType = SetTypeWarmup
+		case "failure":
+			set.SetType = SetTypeFailure
+		case "dropset":
+			set.SetType = SetTypeDrop
+		default:
+			set.SetType = SetTypeWorking
+		}
+		if supersetID := c.get(record, "superset_id"); supersetID != "" {
+			set.GroupKey = "superset-" + supersetID
+		}
+
+		title := c.get(record, "title")
+		note := joinImportNote(title, c.get(record, "description"))
+		w := b.workout(startStr+"\x00"+title, startedAt, note)
+		w.Sets = append(w.Sets, set)
+	}
+
+	workouts, warnings := b.result()
+	return workouts, warnings, nil
+}
+
+// parseFitNotesCSV は FitNotes の CSV を読み取る
+//
+//	Date,Exercise,Category,Weight (kgs),Reps,Distance,Distance Unit,Time,Comment
+//
+// FitNotes は時刻を記録しないため、日付ごとに1つのワークアウト (その日の12:00開始) とする
+func parseFitNotesCSV(c *importCSV, opts importParseOptions) ([]importedWorkout, []dto.ImportWarning, error) {
+	if err := c.require("FitNotes", "date", "exercise", "reps"); err != nil {
+		return nil, nil, err
+	}
+	weightCol, weightFactor := "weight (kgs)", 1.0
+	if !c.has(weightCol) {
+		if !c.has("weight (lbs)") {
+			return nil, nil, c.require("FitNotes", "weight (kgs)")
+		}
+		weightCol, weightFactor = "weight (lbs)", kgPerLb
+	}
+
+	b := newImportWorkoutBuilder()
+	for {
+		record, err := c.next()
+		if err != nil {
+			return nil, nil, err
+		}
+		if record == nil {
+			break
+		}
+
+		dateStr := c.get(record, "date")
+		date, ok := parseImportTime(dateStr, opts.Location, dateLayout)
+		if !ok {
+			b.warn(c.row, "Invalid date %q", dateStr)
+			continue
+		}
+
+		// 距離は行ごとの単位で換算する
+		distanceFactor := 1.0
+		switch strings.ToLower(c.get(record, "distance unit")) {
+		case "km":
+			distanceFactor = 1000
+		case "mi", "mile", "miles":
+			distanceFactor = mPerMi
+		case "ft", "feet":
+			distanceFactor = mPerFt
+		case "yd", "yds", "yards":
+			distanceFactor = mPerYd
+		}
+
+		set, err := readImportSet(c, record, importSetColumns{exercise: "exercise", weight: weightCol, reps: "reps", distance: "distance"}, weightFactor, distanceFactor)
+		if err != nil {
+			b.warn(c.row, "%v", err)
+			continue
+		}
+		if timeStr := c.get(record, "time"); timeStr != "" {
+			seconds, ok := parseImportDuration(timeStr)
+			if !ok {
+				b.warn(c.row, "Invalid time %q", timeStr)
+				continue
+			}
+			set.DurationSeconds = seconds
+		}
+		set.SetType = SetTypeWorking
+
+		startedAt := time.Date(date.Year(), date.Month(), date.Day(), 12, 0, 0, 0, opts.Location)
+		w := b.workout(dateStr, startedAt, "FitNotes")
+		w.Sets = append(w.Sets, set)
+	}
+
+	workouts, warnings := b.result()
+	return workouts, warnings, nil
+}
+
+// importSetColumns はセットの値を読み取る列名を表す (空の列は読み取らない)
+type importSetColumns struct {
+	exercise, weight, reps, distance, seconds, rpe string
+}
+
+// readImportSet は行からセットの値を読み取り、重量を kg、距離を m に換算する
+func readImportSet(c *importCSV, record []string, cols importSetColumns, weightFactor, distanceFactor float64) (importedSet, error) {
+	set := importedSet{Row: c.row, ExerciseName: c.get(record, cols.exercise)}
+	if set.ExerciseName == "" {
+		return set, errors.New("Exercise name is empty")
+	}
+
+	weight, err := parseImportNumber(c.get(record, cols.weight))
+	if err != nil || weight < 0 {
+		return set, fmt.Errorf("Invalid weight %q", c.get(record, cols.weight))
+	}
+	set.WeightKg = math.Round(weight*weightFactor*100) / 100
+	if set.WeightKg > maxImportWeightKg {
+		return set, fmt.Errorf("Weight %.2f kg exceeds %.2f kg", set.WeightKg, maxImportWeightKg)
+	}
+
+	reps, err := parseImportNumber(c.get(record, cols.reps))
+	if err != nil || reps < 0 || reps > math.MaxInt32 {
+		return set, fmt.Errorf("Invalid reps %q", c.get(record, cols.reps))
+	}
+	set.Reps = int32(reps)
+
+	if cols.distance != "" {
+		distance, err := parseImportNumber(c.get(record, cols.distance))
+		if err != nil || distance < 0 {
+			return set, fmt.Errorf("Invalid distance %q", c.get(record, cols.distance))
+		}
+		if distance > 0 {
+			m := math.Round(distance*distanceFactor*100) / 100
+			set.DistanceM = &m
+		}
+	}
+	if cols.seconds != "" {
+		seconds, err := parseImportNumber(c.get(record, cols.seconds))
+		if err != nil || seconds < 0 || seconds > math.MaxInt32 {
+			return set, fmt.Errorf("Invalid duration %q", c.get(record, cols.seconds))
+		}
+		if seconds > 0 {
+			s := int32(seconds)
+			set.DurationSeconds = &s
+		}
+	}
+	if cols.rpe != "" {
+		rpe, err := parseImportNumber(c.get(record, cols.rpe))
+		if err == nil && rpe > 0 && rpe <= 10 {
+			set.Rpe = &rpe
+		}
+	}
+	return set, nil
+}
+
+// parseImportNumber は数値を読み取る (空文字列は 0、小数点のカンマも許容する)
+func parseImportNumber(value string) (float64, error) {
+	if value == "" {
+		return 0, nil
+	}
+	return strconv.ParseFloat(strings.ReplaceAll(value, ",", "."), 64)
+}
+
+// parseImportTime は日時をいずれかの書式で読み取る (タイムゾーンを含まない書式は loc の日時とする)
+func parseImportTime(value string, loc *time.Location, layouts ...string) (time.Time, bool) {
+	for _, layout := range layouts {
+		if t, err := time.ParseInLocation(layout, value, loc); err == nil {
+			return t, true
+		}
+	}
+	return time.Time{}, false
+}
+
+// parseImportDuration は H:MM:SS / MM:SS 形式の時間を秒に変換する (0 の場合は nil)
+func parseImportDuration(value string) (*int32, bool) {
+	parts := strings.Split(value, ":")
+	if len(parts) > 3 {
+		return nil, false
+	}
+	total := 0
+	for _, part := range parts {
+		n, err := strconv.Atoi(part)
+		if err != nil || n < 0 {
+			return nil, false
+		}
+		total = total*60 + n
+	}
+	if total == 0 {
+		return nil, true
+	}
+	seconds := int32(total)
+	return &seconds, true
+}
+
+// joinImportNote はワークアウト名とメモをワークアウトのメモにまとめる
+func joinImportNote(name, note string) string {
+	switch {
+	case name == "":
+		return note
+	case note == "":
+		return name
+	default:
+		return name + "\n" + note
+	}
+}
+
+// invalidImportFile は取り込むファイルが不正な場合のエラーを返す
+func invalidImportFile(message string) error {
+	return httpError.NewValidationError(message, []httpError.ValidationDetail{
+		{Field: "file", Reason: "INVALID_FORMAT"},
+	})
+}
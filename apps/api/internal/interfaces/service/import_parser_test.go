@@ -0,0 +1,300 @@
+package service
+
+import (
+	"errors"
+	"os"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+	"time"
+
+	httpError "github.com/aiirononeko/bulktrack/apps/api/internal/http"
+	"github.com/aiirononeko/bulktrack/apps/api/internal/interfaces/http/dto"
+	"github.com/jackc/pgx/v5/pgtype"
+)
+
+// ptrTo は値のポインタを返す
+func ptrTo[T any](v T) *T { return &v }
+
+// assertImportedWorkouts は読み取ったワークアウトを比較する (開始日時は時刻として比較する)
+func assertImportedWorkouts(t *testing.T, got, want []importedWorkout) {
+	t.Helper()
+	if len(got) != len(want) {
+		t.Fatalf("workouts = %+v, want %d workouts", got, len(want))
+	}
+	for i := range want {
+		if !got[i].StartedAt.Equal(want[i].StartedAt) {
+			t.Errorf("workouts[%d].StartedAt = %v, want %v", i, got[i].StartedAt, want[i].StartedAt)
+		}
+		if got[i].Note != want[i].Note {
+			t.Errorf("workouts[%d].Note = %q, want %q", i, got[i].Note, want[i].Note)
+		}
+		if !reflect.DeepEqual(got[i].Sets, want[i].Sets) {
+			t.Errorf("workouts[%d].Sets =\n%+v\nwant\n%+v", i, got[i].Sets, want[i].Sets)
+		}
+	}
+}
+
+func TestParseImportCSV(t *testing.T) {
+	tokyo, err := time.LoadLocation("Asia/Tokyo")
+	if err != nil {
+		t.Fatalf("LoadLocation() error = %v", err)
+	}
+
+	tests := []struct {
+		name         string
+		source       string
+		file         string
+		opts         importParseOptions
+		want         []importedWorkout
+		wantWarnings []dto.ImportWarning
+	}{
+		{
+			// 欧州ロケールの Strong はセミコロン区切りで小数点がカンマ
+			name:   "strong semicolon and decimal comma in kg and km",
+			source: ImportSourceStrong,
+			file:   "strong_semicolon.csv",
+			opts:   importParseOptions{Location: tokyo, WeightUnit: WeightUnitKg, DistanceUnit: DistanceUnitKm},
+			want: []importedWorkout{
+				{
+					StartedAt: time.Date(2025, 5, 5, 7, 30, 0, 0, tokyo),
+					Note:      "Push\nFelt good",
+					Sets: []importedSet{
+						{Row: 2, ExerciseName: "Bench Press (Barbell)", SetType: SetTypeWarmup, WeightKg: 40, Reps: 10},
+						{Row: 3, ExerciseName: "Bench Press (Barbell)", SetType: SetTypeWorking, WeightKg: 82.5, Reps: 5, Rpe: ptrTo[float64](8.5)},
+						{Row: 5, ExerciseName: "Running", SetType: SetTypeWorking, DurationSeconds: ptrTo[int32](1800), DistanceM: ptrTo[float64](5200)},
+					},
+				},
+				{
+					StartedAt: time.Date(2025, 5, 7, 18, 0, 0, 0, tokyo),
+					Note:      "Pull",
+					Sets: []importedSet{
+						{Row: 7, ExerciseName: "Deadlift (Barbell)", SetType: SetTypeWorking, WeightKg: 140, Reps: 3},
+					},
+				},
+			},
+			wantWarnings: []dto.ImportWarning{{Row: 6, Message: `Invalid weight "abc"`}},
+		},
+		{
+			name:   "strong in lb and mi",
+			source: ImportSourceStrong,
+			file:   "strong_semicolon.csv",
+			opts:   importParseOptions{Location: time.UTC, WeightUnit: WeightUnitLb, DistanceUnit: DistanceUnitMi},
+			want: []importedWorkout{
+				{
+					StartedAt: time.Date(2025, 5, 5, 7, 30, 0, 0, time.UTC),
+					Note:      "Push\nFelt good",
+					Sets: []importedSet{
+						{Row: 2, ExerciseName: "Bench Press (Barbell)", SetType: SetTypeWarmup, WeightKg: 18.14, Reps: 10},
+						{Row: 3, ExerciseName: "Bench Press (Barbell)", SetType: SetTypeWorking, WeightKg: 37.42, Reps: 5, Rpe: ptrTo[float64](8.5)},
+						{Row: 5, ExerciseName: "Running", SetType: SetTypeWorking, DurationSeconds: ptrTo[int32](1800), DistanceM: ptrTo[float64](8368.59)},
+					},
+				},
+				{
+					StartedAt: time.Date(2025, 5, 7, 18, 0, 0, 0, time.UTC),
+					Note:      "Pull",
+					Sets: []importedSet{
+						{Row: 7, ExerciseName: "Deadlift (Barbell)", SetType: SetTypeWorking, WeightKg: 63.5, Reps: 3},
+					},
+				},
+			},
+			wantWarnings: []dto.ImportWarning{{Row: 6, Message: `Invalid weight "abc"`}},
+		},
+		{
+			// Hevy は列名の単位 (weight_lbs, distance_miles) で換算し、Strong 用の単位の指定は使わない
+			name:   "hevy in lbs and miles",
+			source: ImportSourceHevy,
+			file:   "hevy_lbs_miles.csv",
+			opts:   importParseOptions{Location: tokyo, WeightUnit: WeightUnitKg, DistanceUnit: DistanceUnitKm},
+			want: []importedWorkout{
+				{
+					StartedAt: time.Date(2025, 5, 5, 7, 30, 0, 0, tokyo),
+					Note:      "Upper",
+					Sets: []importedSet{
+						{Row: 2, ExerciseName: "Bench Press (Barbell)", SetType: SetTypeWarmup, WeightKg: 20.41, Reps: 10},
+						{Row: 3, ExerciseName: "Bench Press (Barbell)", SetType: SetTypeWorking, GroupKey: "superset-0", WeightKg: 83.91, Reps: 5, Rpe: ptrTo[float64](9)},
+						{Row: 4, ExerciseName: "Lat Pulldown (Cable)", SetType: SetTypeDrop, GroupKey: "superset-0", WeightKg: 45.36, Reps: 12},
+					},
+				},
+				{
+					StartedAt: time.Date(2025, 5, 6, 19, 0, 0, 0, tokyo),
+					Note:      "Cardio\nEasy run",
+					Sets: []importedSet{
+						{Row: 5, ExerciseName: "Running", SetType: SetTypeWorking, DurationSeconds: ptrTo[int32](1800), DistanceM: ptrTo[float64](4988.97)},
+					},
+				},
+			},
+			wantWarnings: []dto.ImportWarning{{Row: 6, Message: `Invalid start_time "32 May 2025, 07:00"`}},
+		},
+		{
+			// FitNotes は日付ごとに1つのワークアウトにまとめ、同じ内容の行も別のセットとして扱う
+			name:   "fitnotes with per-row distance units",
+			source: ImportSourceFitNotes,
+			file:   "fitnotes.csv",
+			opts:   importParseOptions{Location: tokyo, WeightUnit: WeightUnitKg, DistanceUnit: DistanceUnitKm},
+			want: []importedWorkout{
+				{
+					StartedAt: time.Date(2025, 5, 5, 12, 0, 0, 0, tokyo),
+					Note:      "FitNotes",
+					Sets: []importedSet{
+						{Row: 2, ExerciseName: "Flat Barbell Bench Press", SetType: SetTypeWorking, WeightKg: 80, Reps: 5},
+						{Row: 3, ExerciseName: "Flat Barbell Bench Press", SetType: SetTypeWorking, WeightKg: 80, Reps: 5},
+						{Row: 4, ExerciseName: "Treadmill", SetType: SetTypeWorking, DurationSeconds: ptrTo[int32](750), DistanceM: ptrTo[float64](2414.02)},
+					},
+				},
+				{
+					StartedAt: time.Date(2025, 5, 6, 12, 0, 0, 0, tokyo),
+					Note:      "FitNotes",
+					Sets: []importedSet{
+						{Row: 5, ExerciseName: "Rowing Machine", SetType: SetTypeWorking, DurationSeconds: ptrTo[int32](125), DistanceM: ptrTo[float64](500)},
+					},
+				},
+			},
+			wantWarnings: []dto.ImportWarning{{Row: 6, Message: `Invalid time "1:xx"`}},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			f, err := os.Open(filepath.Join("testdata", "import", tt.file))
+			if err != nil {
+				t.Fatalf("failed to open %s: %v", tt.file, err)
+			}
+			defer f.Close()
+
+			got, warnings, err := parseImportCSV(tt.source, f, tt.opts)
+			if err != nil {
+				t.Fatalf("parseImportCSV() error = %v", err)
+			}
+			assertImportedWorkouts(t, got, tt.want)
+			if !reflect.DeepEqual(warnings, tt.wantWarnings) {
+				t.Errorf("parseImportCSV() warnings = %+v, want %+v", warnings, tt.wantWarnings)
+			}
+		})
+	}
+}
+
+func TestParseImportCSVRejectsOtherFormats(t *testing.T) {
+	tests := []struct {
+		name      string
+		source    string
+		data      string
+		wantField string
+	}{
+		{name: "unknown source", source: "jefit", data: "Date\n", wantField: "source"},
+		{name: "empty file", source: ImportSourceStrong, data: "", wantField: "file"},
+		{name: "hevy file as strong", source: ImportSourceStrong, data: `"title","start_time","exercise_title","set_type","weight_kg","reps"` + "\n", wantField: "file"},
+		{name: "hevy without weight column", source: ImportSourceHevy, data: "title,start_time,exercise_title,set_type,reps\n", wantField: "file"},
+		{name: "fitnotes without weight column", source: ImportSourceFitNotes, data: "Date,Exercise,Reps\n", wantField: "file"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, _, err := parseImportCSV(tt.source, strings.NewReader(tt.data), importParseOptions{Location: time.UTC})
+			var appErr *httpError.AppError
+			if !errors.As(err, &appErr) || len(appErr.Details) != 1 || appErr.Details[0].Field != tt.wantField {
+				t.Errorf("parseImportCSV() error = %v, want a validation error of %s", err, tt.wantField)
+			}
+		})
+	}
+}
+
+func TestParseImportNumber(t *testing.T) {
+	tests := []struct {
+		value   string
+		want    float64
+		wantErr bool
+	}{
+		{value: "", want: 0},
+		{value: "82.5", want: 82.5},
+		{value: "82,5", want: 82.5},
+		{value: "100", want: 100},
+		{value: "1,234.5", wantErr: true}, // 桁区切りは扱わない
+		{value: "abc", wantErr: true},
+	}
+	for _, tt := range tests {
+		got, err := parseImportNumber(tt.value)
+		if (err != nil) != tt.wantErr || (!tt.wantErr && got != tt.want) {
+			t.Errorf("parseImportNumber(%q) = %v, %v, want %v (error %v)", tt.value, got, err, tt.want, tt.wantErr)
+		}
+	}
+}
+
+func TestParseImportDuration(t *testing.T) {
+	tests := []struct {
+		value  string
+		want   *int32
+		wantOK bool
+	}{
+		{value: "45", want: ptrTo[int32](45), wantOK: true},
+		{value: "2:05", want: ptrTo[int32](125), wantOK: true},
+		{value: "1:02:03", want: ptrTo[int32](3723), wantOK: true},
+		{value: "0:00", want: nil, wantOK: true},
+		{value: "1:2:3:4", wantOK: false},
+		{value: "-1:00", wantOK: false},
+		{value: "1:xx", wantOK: false},
+	}
+	for _, tt := range tests {
+		got, ok := parseImportDuration(tt.value)
+		if ok != tt.wantOK || !reflect.DeepEqual(got, tt.want) {
+			t.Errorf("parseImportDuration(%q) = %v, %v, want %v, %v", tt.value, got, ok, tt.want, tt.wantOK)
+		}
+	}
+}
+
+func TestExcludeExistingWorkouts(t *testing.T) {
+	tokyo := time.FixedZone("JST", 9*60*60)
+	workouts := []importedWorkout{
+		{StartedAt: time.Date(2025, 5, 5, 7, 30, 0, 0, tokyo), Note: "Push"},
+		{StartedAt: time.Date(2025, 5, 6, 7, 30, 0, 0, tokyo), Note: "Pull"},
+		{StartedAt: time.Date(2025, 5, 7, 7, 30, 0, 0, tokyo), Note: "Legs"},
+	}
+	timestamptz := func(t time.Time) pgtype.Timestamptz { return pgtype.Timestamptz{Time: t, Valid: true} }
+
+	tests := []struct {
+		name        string
+		startTimes  []pgtype.Timestamptz
+		wantNotes   []string
+		wantSkipped int
+	}{
+		{name: "no existing workouts", wantNotes: []string{"Push", "Pull", "Legs"}},
+		{
+			// 同じ時刻は別のタイムゾーンで表されていても重複とみなす
+			name:        "same instant in UTC",
+			startTimes:  []pgtype.Timestamptz{timestamptz(time.Date(2025, 5, 5, 22, 30, 0, 0, time.UTC))},
+			wantNotes:   []string{"Push", "Legs"},
+			wantSkipped: 1,
+		},
+		{
+			// 秒未満の差は重複とみなす
+			name:        "sub-second difference",
+			startTimes:  []pgtype.Timestamptz{timestamptz(time.Date(2025, 5, 7, 7, 30, 0, 500_000_000, tokyo))},
+			wantNotes:   []string{"Push", "Pull"},
+			wantSkipped: 1,
+		},
+		{
+			name:       "one second later is another workout",
+			startTimes: []pgtype.Timestamptz{timestamptz(time.Date(2025, 5, 5, 7, 30, 1, 0, tokyo))},
+			wantNotes:  []string{"Push", "Pull", "Legs"},
+		},
+		{
+			name: "all imported before",
+			startTimes: []pgtype.Timestamptz{
+				timestamptz(workouts[0].StartedAt), timestamptz(workouts[1].StartedAt), timestamptz(workouts[2].StartedAt),
+			},
+			wantNotes:   []string{},
+			wantSkipped: 3,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, skipped := excludeExistingWorkouts(workouts, tt.startTimes)
+			notes := make([]string, 0, len(got))
+			for _, w := range got {
+				notes = append(notes, w.Note)
+			}
+			if !reflect.DeepEqual(notes, tt.wantNotes) || skipped != tt.wantSkipped {
+				t.Errorf("excludeExistingWorkouts() = %v, %d, want %v, %d", notes, skipped, tt.wantNotes, tt.wantSkipped)
+			}
+		})
+	}
+}
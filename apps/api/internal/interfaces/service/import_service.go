@@ -0,0 +1,502 @@
+package service
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"log/slog"
+	"sort"
+	"time"
+
+	httpError "github.com/aiirononeko/bulktrack/apps/api/internal/http"
+	"github.com/aiirononeko/bulktrack/apps/api/internal/infrastructure/sqlc"
+	"github.com/aiirononeko/bulktrack/apps/api/internal/interfaces/http/dto"
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5/pgtype"
+	"github.com/jackc/pgx/v5/pgxpool"
+	"go.opentelemetry.io/otel/attribute"
+)
+
+// maxImportWarnings は取り込み結果に含める警告の最大数 (件数は warning_count で返す)
+const maxImportWarnings = 100
+
+// ImportService は他のアプリのワークアウト履歴の取り込みを提供する
+type ImportService struct {
+	pool    *pgxpool.Pool
+	queries *sqlc.Queries
+	logger  *slog.Logger
+}
+
+// NewImportService は新しい ImportService を作成する
+func NewImportService(pool *pgxpool.Pool, logger *slog.Logger) *ImportService {
+	return &ImportService{
+		pool:    pool,
+		queries: sqlc.New(pool),
+		logger:  logger,
+	}
+}
+
+// importSourceName は CSV の種目名ごとの集計と解決結果を表す
+type importSourceName struct {
+	name        string
+	setCount    int
+	hasWeight   bool
+	hasReps     bool
+	hasDuration bool
+	hasDistance bool
+	status      string
+	exercise    *importExercise
+	similarity  float64
+	candidates  []importExerciseCandidate
+}
+
+// ImportWorkouts は Strong / Hevy / FitNotes の CSV からワークアウト履歴を取り込む
+//
+//   - 種目名は 完全一致 → 別名 → 類似度 の順に解決し、exercise_mappings の指定を優先する
+//   - 解決できない種目名は create_missing の場合にカスタム種目として作成する (残る場合は取り込まない)
+//   - 同じ開始日時のワークアウトが既にある場合は重複として読み飛ばす
+//   - dry_run の場合は保存せずに取り込み結果のみ返す
+//
+// 保存は週間ボリュームの集計キューを使わずに一括で行い、影響のある週を同じトランザクションで再集計する
+func (s *ImportService) ImportWorkouts(ctx context.Context, userID string, file io.Reader, req dto.ImportRequest) (*dto.ImportReport, error) {
+	ctx, span := startSpan(ctx, "ImportService.ImportWorkouts", attribute.String("user_id", userID))
+	defer span.End()
+
+	loc, err := loadTimeZone(req.TimeZone)
+	if err != nil {
+		return nil, err
+	}
+	opts := importParseOptions{Location: loc, WeightUnit: req.WeightUnit, DistanceUnit: req.DistanceUnit}
+	if opts.WeightUnit == "" {
+		opts.WeightUnit = WeightUnitKg
+	}
+	if opts.DistanceUnit == "" {
+		opts.DistanceUnit = DistanceUnitKm
+	}
+	if opts.WeightUnit != WeightUnitKg && opts.WeightUnit != WeightUnitLb {
+		return nil, httpError.NewValidationError("Invalid weight_unit. Expected kg or lb", []httpError.ValidationDetail{
+			{Field: "weight_unit", Reason: "INVALID_VALUE"},
+		})
+	}
+	if opts.DistanceUnit != DistanceUnitKm && opts.DistanceUnit != DistanceUnitMi {
+		return nil, httpError.NewValidationError("Invalid distance_unit. Expected km or mi", []httpError.ValidationDetail{
+			{Field: "distance_unit", Reason: "INVALID_VALUE"},
+		})
+	}
+
+	workouts, warnings, err := parseImportCSV(req.Source, file, opts)
+	if err != nil {
+		return nil, err
+	}
+
+	exerciseRows, err := s.queries.ListExercisesForUser(ctx, userID)
+	if err != nil {
+		s.logger.ErrorContext(ctx, "Failed to execute ListExercisesForUser query", slog.Any("error", err), slog.String("user_id", userID))
+		return nil, fmt.Errorf("failed to list exercises: %w", err)
+	}
+	exercises := make([]importExercise, 0, len(exerciseRows))
+	for _, row := range exerciseRows {
+		exercises = append(exercises, importExercise{ID: row.ID, Name: row.Name, MetricType: row.MetricType})
+	}
+	matcher := newImportExerciseMatcher(exercises)
+
+	names, err := resolveImportExercises(workouts, matcher, req)
+	if err != nil {
+		return nil, err
+	}
+
+	workouts, skippedDuplicates, err := s.skipDuplicateWorkouts(ctx, userID, workouts)
+	if err != nil {
+		return nil, err
+	}
+
+	report := &dto.ImportReport{
+		Source:                       req.Source,
+		DryRun:                       req.DryRun,
+		SkippedDuplicateWorkoutCount: skippedDuplicates,
+		AffectedWeeks:                []string{},
+	}
+
+	// 種目の記録指標に合わせてセットを変換し、記録できないセットは読み飛ばす
+	imported := workouts[:0]
+	for _, w := range workouts {
+		sets := w.Sets[:0]
+		for _, set := range w.Sets {
+			source := names[normalizeExerciseName(set.ExerciseName)]
+			if source.exercise == nil {
+				sets = append(sets, set) // 解決できない種目は取り込まないため変換しない
+				continue
+			}
+			if err := coerceImportSet(&set, source.exercise.MetricType); err != nil {
+				warnings = append(warnings, dto.ImportWarning{Row: set.Row, Message: err.Error()})
+				report.SkippedSetCount++
+				continue
+			}
+			sets = append(sets, set)
+		}
+		if len(sets) == 0 {
+			continue
+		}
+		w.Sets = sets
+		imported = append(imported, w)
+	}
+	workouts = imported
+
+	weeks := make(map[time.Time]struct{})
+	for _, w := range workouts {
+		report.SetCount += len(w.Sets)
+		weeks[truncateToPeriod(w.StartedAt.In(jst), HistoryIntervalWeek)] = struct{}{}
+	}
+	report.WorkoutCount = len(workouts)
+	if len(workouts) > 0 {
+		from := workouts[0].StartedAt.Format(time.RFC3339)
+		to := workouts[len(workouts)-1].StartedAt.Format(time.RFC3339)
+		report.From, report.To = &from, &to
+	}
+	weekStarts := make([]time.Time, 0, len(weeks))
+	for week := range weeks {
+		weekStarts = append(weekStarts, week)
+	}
+	sort.Slice(weekStarts, func(i, j int) bool { return weekStarts[i].Before(weekStarts[j]) })
+	for _, week := range weekStarts {
+		report.AffectedWeeks = append(report.AffectedWeeks, week.Format(dateLayout))
+	}
+
+	report.Exercises = make([]dto.ImportExerciseResolution, 0, len(names))
+	var unresolved []httpError.ValidationDetail
+	for _, source := range sortedImportSourceNames(names) {
+		resolution := dto.ImportExerciseResolution{SourceName: source.name, Status: source.status, SetCount: source.setCount}
+		if source.exercise != nil {
+			name, metricType := source.exercise.Name, source.exercise.MetricType
+			resolution.ExerciseName, resolution.MetricType = &name, &metricType
+			if source.exercise.ID != uuid.Nil {
+				id := source.exercise.ID
+				resolution.ExerciseID = &id
+			}
+		}
+		if source.status == ImportMatchFuzzy {
+			similarity := source.similarity
+			resolution.Similarity = &similarity
+		}
+		for _, c := range source.candidates {
+			resolution.Candidates = append(resolution.Candidates, dto.ImportExerciseCandidate{
+				ExerciseID: c.Exercise.ID, ExerciseName: c.Exercise.Name, Similarity: c.Similarity,
+			})
+		}
+		switch source.status {
+		case ImportMatchCreate:
+			report.CreatedExerciseCount++
+		case ImportMatchUnresolved:
+			report.UnresolvedCount++
+			unresolved = append(unresolved, httpError.ValidationDetail{Field: "exercise_mappings." + source.name, Reason: "REQUIRED"})
+		}
+		report.Exercises = append(report.Exercises, resolution)
+	}
+
+	report.WarningCount = len(warnings)
+	if len(warnings) > maxImportWarnings {
+		warnings = warnings[:maxImportWarnings]
+	}
+	report.Warnings = warnings
+	if report.Warnings == nil {
+		report.Warnings = []dto.ImportWarning{}
+	}
+
+	if req.DryRun {
+		return report, nil
+	}
+	if len(unresolved) > 0 {
+		return nil, httpError.NewValidationError(
+			fmt.Sprintf("%d exercises could not be matched. Specify exercise_mappings or set create_missing", len(unresolved)),
+			unresolved,
+		)
+	}
+	if len(workouts) == 0 {
+		return report, nil
+	}
+
+	if err := s.saveImport(ctx, userID, workouts, names, weekStarts); err != nil {
+		return nil, err
+	}
+	for i := range report.Exercises {
+		if report.Exercises[i].ExerciseID == nil {
+			id := names[normalizeExerciseName(report.Exercises[i].SourceName)].exercise.ID
+			report.Exercises[i].ExerciseID = &id
+		}
+	}
+	return report, nil
+}
+
+// resolveImportExercises は CSV の種目名を取り込み先の種目に解決する (キーは正規化した種目名)
+func resolveImportExercises(workouts []importedWorkout, matcher *importExerciseMatcher, req dto.ImportRequest) (map[string]*importSourceName, error) {
+	names := make(map[string]*importSourceName)
+	for _, w := range workouts {
+		for _, set := range w.Sets {
+			key := normalizeExerciseName(set.ExerciseName)
+			source, ok := names[key]
+			if !ok {
+				source = &importSourceName{name: set.ExerciseName}
+				names[key] = source
+			}
+			source.setCount++
+			source.hasWeight = source.hasWeight || set.WeightKg > 0
+			source.hasReps = source.hasReps || set.Reps > 0
+			source.hasDuration = source.hasDuration || set.DurationSeconds != nil
+			source.hasDistance = source.hasDistance || set.DistanceM != nil
+		}
+	}
+
+	mappings := make(map[string]dto.ImportExerciseMapping, len(req.ExerciseMappings))
+	for name, mapping := range req.ExerciseMappings {
+		mappings[normalizeExerciseName(name)] = mapping
+	}
+
+	for _, key := range sortedImportKeys(names) {
+		source := names[key]
+		mapping, mapped := mappings[key]
+		switch {
+		case mapped && mapping.ExerciseID != nil:
+			e := matcher.byID(*mapping.ExerciseID)
+			if e == nil {
+				return nil, httpError.NewValidationError(fmt.Sprintf("Exercise %s for %q was not found", mapping.ExerciseID, source.name), []httpError.ValidationDetail{
+					{Field: "exercise_mappings." + source.name, Reason: "INVALID_VALUE"},
+				})
+			}
+			source.status, source.exercise, source.similarity = ImportMatchMapped, e, 1
+			continue
+		case mapped && !mapping.Create:
+			return nil, httpError.NewValidationError(fmt.Sprintf("Specify exercise_id or create for %q", source.name), []httpError.ValidationDetail{
+				{Field: "exercise_mappings." + source.name, Reason: "REQUIRED"},
+			})
+		}
+
+		match := matcher.match(source.name)
+		if match.Status == ImportMatchExact || (!mapped && match.Status != ImportMatchUnresolved) {
+			// 同じ名前の種目がある場合は作成せずにその種目を使う
+			source.status, source.exercise, source.similarity = match.Status, match.Exercise, match.Similarity
+			continue
+		}
+		if mapped || req.CreateMissing {
+			e := &importExercise{Name: source.name, MetricType: inferImportMetricType(source)}
+			matcher.add(e)
+			source.status, source.exercise = ImportMatchCreate, e
+			continue
+		}
+		source.status, source.candidates = ImportMatchUnresolved, match.Candidates
+	}
+	return names, nil
+}
+
+// inferImportMetricType は作成する種目の記録指標を CSV の値から推定する
+func inferImportMetricType(source *importSourceName) string {
+	switch {
+	case source.hasDistance:
+		return MetricTypeDistanceTime
+	case source.hasDuration && !source.hasReps:
+		return MetricTypeTime
+	case !source.hasWeight:
+		return MetricTypeReps
+	default:
+		return MetricTypeWeightReps
+	}
+}
+
+// coerceImportSet はセットを種目の記録指標で記録できる値に変換する (記録できない場合はエラー)
+func coerceImportSet(set *importedSet, metricType string) error {
+	switch metricType {
+	case MetricTypeReps:
+		set.WeightKg, set.DurationSeconds, set.DistanceM = 0, nil, nil
+	case MetricTypeTime:
+		set.Reps, set.DistanceM = 0, nil
+	case MetricTypeDistanceTime:
+		set.WeightKg, set.Reps = 0, 0
+	default:
+		set.DurationSeconds, set.DistanceM = nil, nil
+	}
+	if err := validateSetMetrics(metricType, setMetrics{
+		WeightKg:        &set.WeightKg, // インポートしたセットの重量は常にある (未記録は 0)
+		Reps:            set.Reps,
+		DurationSeconds: set.DurationSeconds,
+		DistanceM:       set.DistanceM,
+	}); err != nil {
+		return fmt.Errorf("Skipped set of %q: required values for metric type %s are missing or out of range", set.ExerciseName, metricType)
+	}
+	return nil
+}
+
+// skipDuplicateWorkouts は同じ開始日時のワークアウトが既にあるものを除く
+func (s *ImportService) skipDuplicateWorkouts(ctx context.Context, userID string, workouts []importedWorkout) ([]importedWorkout, int, error) {
+	if len(workouts) == 0 {
+		return workouts, 0, nil
+	}
+	startTimes, err := s.queries.ListWorkoutStartTimes(ctx, sqlc.ListWorkoutStartTimesParams{
+		UserID:   userID,
+		FromTime: pgtype.Timestamptz{Time: workouts[0].StartedAt, Valid: true},
+		ToTime:   pgtype.Timestamptz{Time: workouts[len(workouts)-1].StartedAt, Valid: true},
+	})
+	if err != nil {
+		s.logger.ErrorContext(ctx, "Failed to execute ListWorkoutStartTimes query", slog.Any("error", err), slog.String("user_id", userID))
+		return nil, 0, fmt.Errorf("failed to list workout start times: %w", err)
+	}
+	result, skipped := excludeExistingWorkouts(workouts, startTimes)
+	return result, skipped, nil
+}
+
+// excludeExistingWorkouts は既存のワークアウトと開始日時 (秒単位) が同じワークアウトを除き、除いた件数を返す
+func excludeExistingWorkouts(workouts []importedWorkout, startTimes []pgtype.Timestamptz) ([]importedWorkout, int) {
+	existing := make(map[int64]struct{}, len(startTimes))
+	for _, t := range startTimes {
+		existing[t.Time.Unix()] = struct{}{}
+	}
+
+	result := make([]importedWorkout, 0, len(workouts))
+	skipped := 0
+	for _, w := range workouts {
+		if _, ok := existing[w.StartedAt.Unix()]; ok {
+			skipped++
+			continue
+		}
+		result = append(result, w)
+	}
+	return result, skipped
+}
+
+// saveImport は種目・ワークアウト・セットを1つのトランザクションで保存し、影響のある週の週次ボリュームを再集計する
+func (s *ImportService) saveImport(ctx context.Context, userID string, workouts []importedWorkout, names map[string]*importSourceName, weekStarts []time.Time) (err error) {
+	tx, err := s.pool.Begin(ctx)
+	if err != nil {
+		s.logger.ErrorContext(ctx, "Failed to begin transaction for ImportWorkouts", slog.Any("error", err), slog.String("user_id", userID))
+		return err
+	}
+	defer func() {
+		if r := recover(); r != nil {
+			s.logger.ErrorContext(ctx, "Recovered in ImportWorkouts, rolling back transaction", slog.Any("panic_value", r), slog.String("user_id", userID))
+			tx.Rollback(ctx)
+			panic(r)
+		} else if err != nil {
+			rollErr := tx.Rollback(ctx)
+			if rollErr != nil {
+				s.logger.ErrorContext(ctx, "Failed to rollback transaction for ImportWorkouts", slog.Any("rollback_error", rollErr), slog.Any("original_error", err), slog.String("user_id", userID))
+			}
+		}
+	}()
+
+	qtx := sqlc.New(tx)
+
+	// 集計キューには入れず、最後に影響のある週をまとめて再集計する (コミット直後から週間ボリュームに反映される)
+	if err = qtx.SkipWeeklyVolumeTrigger(ctx); err != nil {
+		s.logger.ErrorContext(ctx, "Failed to execute SkipWeeklyVolumeTrigger query", slog.Any("error", err), slog.String("user_id", userID))
+		return fmt.Errorf("failed to disable weekly volume trigger: %w", err)
+	}
+
+	createdExercises := 0
+	for _, key := range sortedImportKeys(names) {
+		source := names[key]
+		if source.status != ImportMatchCreate {
+			continue
+		}
+		var created sqlc.Exercise
+		created, err = qtx.CreateExercise(ctx, sqlc.CreateExerciseParams{
+			Name:            source.exercise.Name,
+			IsCustom:        pgtype.Bool{Bool: true, Valid: true},
+			CreatedByUserID: pgtype.Text{String: userID, Valid: true},
+			LoadType:        "external",
+			MetricType:      source.exercise.MetricType,
+		})
+		if err != nil {
+			s.logger.ErrorContext(ctx, "Failed to execute CreateExercise query", slog.Any("error", err), slog.String("user_id", userID), slog.String("name", source.exercise.Name))
+			return fmt.Errorf("failed to create exercise %q: %w", source.exercise.Name, err)
+		}
+		source.exercise.ID = created.ID
+		createdExercises++
+	}
+
+	workoutParams := make([]sqlc.CreateWorkoutsBulkParams, 0, len(workouts))
+	var setParams []sqlc.CreateSetsBulkParams
+	for _, w := range workouts {
+		workoutID := uuid.New()
+		workoutParams = append(workoutParams, sqlc.CreateWorkoutsBulkParams{
+			ID:        workoutID,
+			UserID:    userID,
+			StartedAt: pgtype.Timestamptz{Time: w.StartedAt, Valid: true},
+			Note:      pgtype.Text{String: w.Note, Valid: w.Note != ""},
+		})
+		for i, set := range w.Sets {
+			params := sqlc.CreateSetsBulkParams{
+				WorkoutID:       pgtype.UUID{Bytes: workoutID, Valid: true},
+				ExerciseID:      pgtype.UUID{Bytes: names[normalizeExerciseName(set.ExerciseName)].exercise.ID, Valid: true},
+				SetOrder:        int32(i + 1),
+				Reps:            set.Reps,
+				SetType:         set.SetType,
+				GroupKey:        pgtype.Text{String: set.GroupKey, Valid: set.GroupKey != ""},
+				DurationSeconds: ptrInt32ToPgtypeInt4(set.DurationSeconds),
+			}
+			weightKg := set.WeightKg
+			if params.WeightKg, err = ptrFloat64ToPgtypeNumeric(&weightKg); err != nil {
+				return fmt.Errorf("failed to convert weight_kg: %w", err)
+			}
+			if params.Rpe, err = ptrFloat64ToPgtypeNumeric(set.Rpe); err != nil {
+				return fmt.Errorf("failed to convert rpe: %w", err)
+			}
+			if params.DistanceM, err = ptrFloat64ToPgtypeNumeric(set.DistanceM); err != nil {
+				return fmt.Errorf("failed to convert distance_m: %w", err)
+			}
+			setParams = append(setParams, params)
+		}
+	}
+
+	if _, err = qtx.CreateWorkoutsBulk(ctx, workoutParams); err != nil {
+		s.logger.ErrorContext(ctx, "Failed to execute CreateWorkoutsBulk query", slog.Any("error", err), slog.String("user_id", userID), slog.Int("workouts", len(workoutParams)))
+		return fmt.Errorf("failed to insert workouts: %w", err)
+	}
+	if _, err = qtx.CreateSetsBulk(ctx, setParams); err != nil {
+		s.logger.ErrorContext(ctx, "Failed to execute CreateSetsBulk query", slog.Any("error", err), slog.String("user_id", userID), slog.Int("sets", len(setParams)))
+		return fmt.Errorf("failed to insert sets: %w", err)
+	}
+
+	for _, week := range weekStarts {
+		if err = qtx.RecalculateWeeklyVolume(ctx, sqlc.RecalculateWeeklyVolumeParams{
+			UserID:        userID,
+			WeekStartDate: pgtype.Date{Time: week, Valid: true},
+		}); err != nil {
+			s.logger.ErrorContext(ctx, "Failed to execute RecalculateWeeklyVolume query", slog.Any("error", err), slog.String("user_id", userID), slog.String("week_start_date", week.Format(dateLayout)))
+			return fmt.Errorf("failed to recalculate weekly volume: %w", err)
+		}
+	}
+
+	if err = tx.Commit(ctx); err != nil {
+		s.logger.ErrorContext(ctx, "Failed to commit transaction for ImportWorkouts", slog.Any("error", err), slog.String("user_id", userID))
+		return fmt.Errorf("failed to commit import: %w", err)
+	}
+	if createdExercises > 0 {
+		invalidateExerciseCatalog(ctx)
+	}
+
+	s.logger.InfoContext(ctx, "Imported workouts", slog.String("user_id", userID), slog.Int("workouts", len(workoutParams)), slog.Int("sets", len(setParams)))
+	workoutsStarted.Add(ctx, int64(len(workoutParams)), sourceAttr("import"))
+	setsLogged.Add(ctx, int64(len(setParams)), sourceAttr("import"))
+	volumeRecalculations.Add(ctx, int64(len(weekStarts)), sourceAttr("import"))
+	return nil
+}
+
+// sortedImportKeys は種目名のキーを並べて返す (処理順を決定的にする)
+func sortedImportKeys(names map[string]*importSourceName) []string {
+	keys := make([]string, 0, len(names))
+	for key := range names {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+	return keys
+}
+
+// sortedImportSourceNames は種目名をセット数の多い順に返す
+func sortedImportSourceNames(names map[string]*importSourceName) []*importSourceName {
+	sources := make([]*importSourceName, 0, len(names))
+	for _, key := range sortedImportKeys(names) {
+		sources = append(sources, names[key])
+	}
+	sort.SliceStable(sources, func(i, j int) bool {
+		return sources[i].setCount > sources[j].setCount
+	})
+	return sources
+}
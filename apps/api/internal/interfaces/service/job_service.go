@@ -0,0 +1,259 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log/slog"
+	"strconv"
+	"time"
+
+	httpError "github.com/aiirononeko/bulktrack/apps/api/internal/http"
+	"github.com/aiirononeko/bulktrack/apps/api/internal/infrastructure/sqlc"
+	"github.com/aiirononeko/bulktrack/apps/api/internal/interfaces/http/dto"
+	"github.com/aiirononeko/bulktrack/apps/api/internal/jobs"
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgconn"
+	"github.com/jackc/pgx/v5/pgtype"
+	"github.com/jackc/pgx/v5/pgxpool"
+	"go.opentelemetry.io/otel/attribute"
+)
+
+const (
+	DefaultFailedJobsPageLimit = 50
+	maxFailedJobsPageLimit     = 200
+)
+
+// uniqueViolation は一意制約違反の SQLSTATE
+const uniqueViolation = "23505"
+
+// JobService はバックグラウンドジョブ (jobs) を Postgres に保存する jobs.Store の実装と、失敗したジョブの管理を提供する
+type JobService struct {
+	pool    *pgxpool.Pool
+	queries *sqlc.Queries
+	logger  *slog.Logger
+}
+
+var _ jobs.Store = (*JobService)(nil)
+
+// NewJobService は新しい JobService を作成する
+func NewJobService(pool *pgxpool.Pool, logger *slog.Logger) *JobService {
+	return &JobService{
+		pool:    pool,
+		queries: sqlc.New(pool),
+		logger:  logger,
+	}
+}
+
+// Insert はジョブを保存する
+func (s *JobService) Insert(ctx context.Context, job jobs.InsertParams) (int64, bool, error) {
+	return insertJob(ctx, s.queries, job)
+}
+
+// insertJob はジョブを保存する (他の更新と同じトランザクションで追加する場合はトランザクションの Queries を渡す)
+// 同じ UniqueKey の未完了のジョブがある場合は保存せず、inserted が false
+func insertJob(ctx context.Context, q *sqlc.Queries, job jobs.InsertParams) (id int64, inserted bool, err error) {
+	id, err = q.InsertJob(ctx, sqlc.InsertJobParams{
+		Kind:        job.Kind,
+		Args:        job.Args,
+		RunAt:       job.RunAt,
+		MaxAttempts: int32(job.MaxAttempts),
+		UniqueKey:   pgtype.Text{String: job.UniqueKey, Valid: job.UniqueKey != ""},
+	})
+	if errors.Is(err, pgx.ErrNoRows) {
+		return 0, false, nil
+	}
+	if err != nil {
+		return 0, false, fmt.Errorf("failed to insert %s job: %w", job.Kind, err)
+	}
+	return id, true, nil
+}
+
+// Claim は実行日時を過ぎたジョブを取り出して実行中にする
+func (s *JobService) Claim(ctx context.Context, workerID string, kinds []string, limit int) ([]jobs.ClaimedJob, error) {
+	rows, err := s.queries.ClaimJobs(ctx, sqlc.ClaimJobsParams{
+		LockedBy:  workerID,
+		Kinds:     kinds,
+		BatchSize: int32(limit),
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to claim jobs: %w", err)
+	}
+	claimed := make([]jobs.ClaimedJob, len(rows))
+	for i, row := range rows {
+		claimed[i] = jobs.ClaimedJob{
+			ID:          row.ID,
+			Kind:        row.Kind,
+			Args:        row.Args,
+			Attempt:     int(row.Attempt),
+			MaxAttempts: int(row.MaxAttempts),
+		}
+	}
+	return claimed, nil
+}
+
+// Complete はジョブを完了にする
+func (s *JobService) Complete(ctx context.Context, id int64) error {
+	if err := s.queries.CompleteJob(ctx, id); err != nil {
+		return fmt.Errorf("failed to complete job: %w", err)
+	}
+	return nil
+}
+
+// Retry はジョブを runAt に再試行する
+func (s *JobService) Retry(ctx context.Context, id int64, runAt time.Time, errMsg string) error {
+	if err := s.queries.RetryJob(ctx, sqlc.RetryJobParams{RunAt: runAt, Error: errMsg, ID: id}); err != nil {
+		return fmt.Errorf("failed to schedule job retry: %w", err)
+	}
+	return nil
+}
+
+// Fail はジョブを失敗にする
+func (s *JobService) Fail(ctx context.Context, id int64, errMsg string) error {
+	if err := s.queries.FailJob(ctx, sqlc.FailJobParams{Error: errMsg, ID: id}); err != nil {
+		return fmt.Errorf("failed to mark job as failed: %w", err)
+	}
+	return nil
+}
+
+// Release は中断したジョブを実行待ちに戻す
+func (s *JobService) Release(ctx context.Context, ids []int64) error {
+	if _, err := s.queries.ReleaseJobs(ctx, ids); err != nil {
+		return fmt.Errorf("failed to release jobs: %w", err)
+	}
+	return nil
+}
+
+// RescueStale は停止したインスタンスが実行中のまま残したジョブを実行待ちに戻す
+func (s *JobService) RescueStale(ctx context.Context, kind string, lockedBefore time.Time) (int64, error) {
+	n, err := s.queries.RescueStaleJobs(ctx, sqlc.RescueStaleJobsParams{Kind: kind, LockedBefore: lockedBefore})
+	if err != nil {
+		return 0, fmt.Errorf("failed to rescue stale jobs: %w", err)
+	}
+	return n, nil
+}
+
+// DeleteFinished は古い完了・失敗のジョブを削除する
+func (s *JobService) DeleteFinished(ctx context.Context, completedBefore, failedBefore time.Time) (int64, error) {
+	n, err := s.queries.DeleteFinishedJobs(ctx, sqlc.DeleteFinishedJobsParams{
+		CompletedBefore: completedBefore,
+		FailedBefore:    failedBefore,
+	})
+	if err != nil {
+		return 0, fmt.Errorf("failed to delete finished jobs: %w", err)
+	}
+	return n, nil
+}
+
+// EnqueueScheduled は実行日時を過ぎた定期実行のジョブを追加する
+// スケジュールの行の更新とジョブの追加は同じトランザクションで行い、行を更新できたインスタンスのみが追加する
+func (s *JobService) EnqueueScheduled(ctx context.Context, name string, now, next time.Time, job jobs.InsertParams) (enqueued bool, err error) {
+	tx, err := s.pool.Begin(ctx)
+	if err != nil {
+		return false, fmt.Errorf("failed to begin transaction: %w", err)
+	}
+	defer func() {
+		if err != nil {
+			if rollErr := tx.Rollback(ctx); rollErr != nil {
+				s.logger.ErrorContext(ctx, "Failed to rollback transaction for EnqueueScheduled", slog.Any("rollback_error", rollErr), slog.Any("original_error", err), slog.String("name", name))
+			}
+		}
+	}()
+
+	qtx := sqlc.New(tx)
+	if err = qtx.EnsureJobSchedule(ctx, sqlc.EnsureJobScheduleParams{Name: name, NextRunAt: next}); err != nil {
+		return false, fmt.Errorf("failed to register job schedule: %w", err)
+	}
+	advanced, err := qtx.AdvanceJobSchedule(ctx, sqlc.AdvanceJobScheduleParams{NextRunAt: next, Name: name, Now: now})
+	if err != nil {
+		return false, fmt.Errorf("failed to advance job schedule: %w", err)
+	}
+	if advanced > 0 {
+		// 前回のジョブが終わっていない場合は UniqueKey で追加しない (実行日時は進める)
+		if _, enqueued, err = insertJob(ctx, qtx, job); err != nil {
+			return false, err
+		}
+	}
+	if err = tx.Commit(ctx); err != nil {
+		return false, fmt.Errorf("failed to commit job schedule: %w", err)
+	}
+	return enqueued, nil
+}
+
+// ListFailedJobs は失敗したジョブを新しい順に取得する (kind が空の場合はすべての種類)
+// cursor は前のページの next_cursor (空の場合は最初のページ)
+func (s *JobService) ListFailedJobs(ctx context.Context, kind, cursor string, limit int) (*dto.FailedJobsResponse, error) {
+	ctx, span := startSpan(ctx, "JobService.ListFailedJobs", attribute.String("kind", kind))
+	defer span.End()
+
+	if limit == 0 {
+		limit = DefaultFailedJobsPageLimit
+	}
+	if limit < 1 || limit > maxFailedJobsPageLimit {
+		return nil, httpError.NewInvalidLimitError(fmt.Sprintf("Limit must be between 1 and %d", maxFailedJobsPageLimit), nil).WithDetails([]httpError.ValidationDetail{
+			{Field: "limit", Reason: "RANGE"},
+		})
+	}
+	params := sqlc.ListFailedJobsParams{
+		Kind:      pgtype.Text{String: kind, Valid: kind != ""},
+		PageLimit: int32(limit + 1),
+	}
+	if cursor != "" {
+		cursorID, err := strconv.ParseInt(cursor, 10, 64)
+		if err != nil {
+			return nil, httpError.NewValidationError("Invalid cursor", []httpError.ValidationDetail{
+				{Field: "cursor", Reason: "INVALID_FORMAT"},
+			})
+		}
+		params.CursorID = pgtype.Int8{Int64: cursorID, Valid: true}
+	}
+
+	rows, err := s.queries.ListFailedJobs(ctx, params)
+	if err != nil {
+		s.logger.ErrorContext(ctx, "Failed to execute ListFailedJobs query", slog.Any("error", err), slog.String("kind", kind))
+		return nil, fmt.Errorf("failed to list failed jobs: %w", err)
+	}
+
+	resp := &dto.FailedJobsResponse{Jobs: []dto.FailedJob{}}
+	if len(rows) > limit {
+		rows = rows[:limit]
+		next := strconv.FormatInt(rows[limit-1].ID, 10)
+		resp.NextCursor = &next
+	}
+	for _, row := range rows {
+		resp.Jobs = append(resp.Jobs, dto.FailedJob{
+			ID:          row.ID,
+			Kind:        row.Kind,
+			Args:        row.Args,
+			Attempt:     row.Attempt,
+			MaxAttempts: row.MaxAttempts,
+			UniqueKey:   pgtypeTextToPtrString(row.UniqueKey),
+			LastError:   pgtypeTextToPtrString(row.LastError),
+			CreatedAt:   row.CreatedAt.In(jst).Format(time.RFC3339),
+			FailedAt:    pgtypeTimestamptzToPtrString(row.FailedAt),
+		})
+	}
+	return resp, nil
+}
+
+// RetryFailedJob は失敗したジョブを試行回数を戻して実行待ちにする
+// 同じ UniqueKey の未完了のジョブがある場合 (定期実行のジョブの次の実行など) は再試行できない
+func (s *JobService) RetryFailedJob(ctx context.Context, id int64) error {
+	ctx, span := startSpan(ctx, "JobService.RetryFailedJob", attribute.Int64("job_id", id))
+	defer span.End()
+
+	n, err := s.queries.RetryFailedJob(ctx, id)
+	if err != nil {
+		var pgErr *pgconn.PgError
+		if errors.As(err, &pgErr) && pgErr.Code == uniqueViolation {
+			return httpError.NewConflictError("Another job with the same unique key is pending", err)
+		}
+		s.logger.ErrorContext(ctx, "Failed to execute RetryFailedJob query", slog.Any("error", err), slog.Int64("job_id", id))
+		return fmt.Errorf("failed to retry job: %w", err)
+	}
+	if n == 0 {
+		return httpError.NewNotFoundError("Failed job not found", nil)
+	}
+	s.logger.InfoContext(ctx, "Retrying failed job", slog.Int64("job_id", id))
+	return nil
+}
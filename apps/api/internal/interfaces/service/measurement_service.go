@@ -0,0 +1,396 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log/slog"
+	"strconv"
+	"time"
+
+	httpError "github.com/aiirononeko/bulktrack/apps/api/internal/http"
+	"github.com/aiirononeko/bulktrack/apps/api/internal/infrastructure/sqlc"
+	"github.com/aiirononeko/bulktrack/apps/api/internal/interfaces/http/dto"
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgtype"
+	"github.com/jackc/pgx/v5/pgxpool"
+	"go.opentelemetry.io/otel/attribute"
+)
+
+// 時系列データの集計単位
+const (
+	MeasurementIntervalDay  = "day"
+	MeasurementIntervalWeek = "week"
+)
+
+// maxMeasurementValue は計測値の上限 (NUMERIC(7,2))
+const maxMeasurementValue = 99999.99
+
+// defaultMeasurementRangeDays は期間未指定時に取得する日数
+const defaultMeasurementRangeDays = 90
+
+// defaultRelativeStrengthWeeks は相対筋力の推移で取得するデフォルトの週数
+const defaultRelativeStrengthWeeks = 12
+
+// MeasurementService は身体計測 (体重・体脂肪率・周囲径など) 関連のサービスを提供する
+type MeasurementService struct {
+	pool    *pgxpool.Pool
+	queries *sqlc.Queries
+	logger  *slog.Logger
+}
+
+// NewMeasurementService は新しい MeasurementService を作成する
+func NewMeasurementService(pool *pgxpool.Pool, logger *slog.Logger) *MeasurementService {
+	return &MeasurementService{
+		pool:    pool,
+		queries: sqlc.New(pool),
+		logger:  logger,
+	}
+}
+
+// ListMetrics は計測項目の一覧を取得する
+func (s *MeasurementService) ListMetrics(ctx context.Context) ([]dto.MeasurementMetric, error) {
+	ctx, span := startSpan(ctx, "MeasurementService.ListMetrics")
+	defer span.End()
+
+	metrics, err := s.queries.ListMeasurementMetrics(ctx)
+	if err != nil {
+		s.logger.ErrorContext(ctx, "Failed to execute ListMeasurementMetrics query", slog.Any("error", err))
+		return nil, err
+	}
+
+	result := make([]dto.MeasurementMetric, 0, len(metrics))
+	for _, metric := range metrics {
+		result = append(result, dto.MeasurementMetric{
+			Code: metric.Code,
+			Name: metric.Name,
+			Unit: metric.Unit,
+		})
+	}
+
+	return result, nil
+}
+
+// CreateMeasurement は身体計測記録を作成する
+// 体重 (body_weight) が変わると自重種目の実効負荷が変わるため、影響のある週は DB トリガーで集計キューに入り、週間ボリュームが集計し直される
+func (s *MeasurementService) CreateMeasurement(ctx context.Context, userID string, req dto.CreateMeasurementRequest) (*dto.MeasurementView, error) {
+	ctx, span := startSpan(ctx, "MeasurementService.CreateMeasurement", attribute.String("user_id", userID))
+	defer span.End()
+
+	if _, err := s.getMetric(ctx, req.Metric); err != nil {
+		return nil, err
+	}
+
+	value, err := measurementValueToNumeric(req.Value)
+	if err != nil {
+		return nil, err
+	}
+
+	measuredAt := time.Now()
+	if req.MeasuredAt != "" {
+		measuredAt, err = parseMeasuredAt(req.MeasuredAt)
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	measurement, err := s.queries.CreateBodyMeasurement(ctx, sqlc.CreateBodyMeasurementParams{
+		UserID:     userID,
+		MetricCode: req.Metric,
+		Value:      value,
+		MeasuredAt: measuredAt,
+		Note:       ptrStringToPgtypeText(req.Note),
+	})
+	if err != nil {
+		s.logger.ErrorContext(ctx, "Failed to execute CreateBodyMeasurement query", slog.Any("error", err), slog.String("user_id", userID), slog.String("metric", req.Metric))
+		return nil, fmt.Errorf("failed to create measurement: %w", err)
+	}
+
+	view := s.toMeasurementView(ctx, measurement)
+	return &view, nil
+}
+
+// ListMeasurements は期間内の身体計測記録を新しい順に取得する
+// metric が空の場合は全項目、from / to (YYYY-MM-DD) が空の場合は直近90日間を対象とする
+func (s *MeasurementService) ListMeasurements(ctx context.Context, userID, metric, from, to string) ([]dto.MeasurementView, error) {
+	ctx, span := startSpan(ctx, "MeasurementService.ListMeasurements", attribute.String("user_id", userID), attribute.String("metric", metric))
+	defer span.End()
+
+	var metricCode pgtype.Text
+	if metric != "" {
+		if _, err := s.getMetric(ctx, metric); err != nil {
+			return nil, err
+		}
+		metricCode = pgtype.Text{String: metric, Valid: true}
+	}
+
+	fromDate, toDate, err := parseDateRange(from, to, defaultMeasurementRangeDays)
+	if err != nil {
+		return nil, err
+	}
+
+	measurements, err := s.queries.ListBodyMeasurements(ctx, sqlc.ListBodyMeasurementsParams{
+		UserID:     userID,
+		MetricCode: metricCode,
+		FromTime:   fromDate,
+		ToTime:     toDate.AddDate(0, 0, 1), // 終了日を含む
+	})
+	if err != nil {
+		s.logger.ErrorContext(ctx, "Failed to execute ListBodyMeasurements query", slog.Any("error", err), slog.String("user_id", userID), slog.String("metric", metric))
+		return nil, fmt.Errorf("failed to list measurements: %w", err)
+	}
+
+	result := make([]dto.MeasurementView, 0, len(measurements))
+	for _, measurement := range measurements {
+		result = append(result, s.toMeasurementView(ctx, measurement))
+	}
+
+	return result, nil
+}
+
+// UpdateMeasurement は身体計測記録を更新する
+func (s *MeasurementService) UpdateMeasurement(ctx context.Context, userID string, id uuid.UUID, req dto.UpdateMeasurementRequest) (*dto.MeasurementView, error) {
+	ctx, span := startSpan(ctx, "MeasurementService.UpdateMeasurement", attribute.String("user_id", userID), attribute.String("measurement_id", id.String()))
+	defer span.End()
+
+	params := sqlc.UpdateBodyMeasurementParams{
+		ID:     id,
+		UserID: userID,
+	}
+
+	if req.Value != nil {
+		value, err := measurementValueToNumeric(*req.Value)
+		if err != nil {
+			return nil, err
+		}
+		params.Value = value
+	}
+
+	if req.MeasuredAt != nil {
+		measuredAt, err := parseMeasuredAt(*req.MeasuredAt)
+		if err != nil {
+			return nil, err
+		}
+		params.MeasuredAt = pgtype.Timestamptz{Time: measuredAt, Valid: true}
+	}
+
+	if req.Note != nil {
+		params.Note = pgtype.Text{String: *req.Note, Valid: true}
+	}
+
+	measurement, err := s.queries.UpdateBodyMeasurement(ctx, params)
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return nil, httpError.NewNotFoundError("Measurement not found", err)
+		}
+		s.logger.ErrorContext(ctx, "Failed to execute UpdateBodyMeasurement query", slog.Any("error", err), slog.String("user_id", userID), slog.String("measurement_id", id.String()))
+		return nil, fmt.Errorf("failed to update measurement (ID: %s): %w", id, err)
+	}
+
+	view := s.toMeasurementView(ctx, measurement)
+	return &view, nil
+}
+
+// DeleteMeasurement は身体計測記録を削除する
+func (s *MeasurementService) DeleteMeasurement(ctx context.Context, userID string, id uuid.UUID) error {
+	ctx, span := startSpan(ctx, "MeasurementService.DeleteMeasurement", attribute.String("user_id", userID), attribute.String("measurement_id", id.String()))
+	defer span.End()
+
+	deleted, err := s.queries.DeleteBodyMeasurement(ctx, sqlc.DeleteBodyMeasurementParams{
+		ID:     id,
+		UserID: userID,
+	})
+	if err != nil {
+		s.logger.ErrorContext(ctx, "Failed to execute DeleteBodyMeasurement query", slog.Any("error", err), slog.String("user_id", userID), slog.String("measurement_id", id.String()))
+		return fmt.Errorf("failed to delete measurement (ID: %s): %w", id, err)
+	}
+	if deleted == 0 {
+		return httpError.NewNotFoundError("Measurement not found", nil)
+	}
+
+	return nil
+}
+
+// GetMeasurementSeries は計測項目の時系列データを取得する
+// interval=day は日ごとの平均値と7日移動平均、interval=week は週ごと (JST 月曜始まり) の平均値を返す
+func (s *MeasurementService) GetMeasurementSeries(ctx context.Context, userID, metric, interval, from, to string) (*dto.MeasurementSeriesResponse, error) {
+	ctx, span := startSpan(ctx, "MeasurementService.GetMeasurementSeries", attribute.String("user_id", userID), attribute.String("metric", metric))
+	defer span.End()
+
+	metricRow, err := s.getMetric(ctx, metric)
+	if err != nil {
+		return nil, err
+	}
+
+	if interval == "" {
+		interval = MeasurementIntervalDay
+	}
+	if interval != MeasurementIntervalDay && interval != MeasurementIntervalWeek {
+		return nil, httpError.NewValidationError("Invalid interval: "+interval, []httpError.ValidationDetail{
+			{Field: "interval", Reason: "INVALID_VALUE"},
+		})
+	}
+
+	fromDate, toDate, err := parseDateRange(from, to, defaultMeasurementRangeDays)
+	if err != nil {
+		return nil, err
+	}
+
+	points := make([]dto.MeasurementSeriesPoint, 0)
+	switch interval {
+	case MeasurementIntervalDay:
+		rows, err := s.queries.ListDailyMeasurementAverages(ctx, sqlc.ListDailyMeasurementAveragesParams{
+			UserID:     userID,
+			MetricCode: metric,
+			FromDate:   pgtype.Date{Time: fromDate, Valid: true},
+			ToDate:     pgtype.Date{Time: toDate, Valid: true},
+		})
+		if err != nil {
+			s.logger.ErrorContext(ctx, "Failed to execute ListDailyMeasurementAverages query", slog.Any("error", err), slog.String("user_id", userID), slog.String("metric", metric))
+			return nil, fmt.Errorf("failed to get daily measurement series: %w", err)
+		}
+		for _, row := range rows {
+			movingAverage := numericToFloat64(ctx, s.logger, row.MovingAvg7d)
+			points = append(points, dto.MeasurementSeriesPoint{
+				Date:          row.MeasuredOn.Time.Format(dateLayout),
+				Value:         numericToFloat64(ctx, s.logger, row.AvgValue),
+				MovingAverage: &movingAverage,
+			})
+		}
+	case MeasurementIntervalWeek:
+		rows, err := s.queries.ListWeeklyMeasurementAverages(ctx, sqlc.ListWeeklyMeasurementAveragesParams{
+			UserID:     userID,
+			MetricCode: metric,
+			FromDate:   pgtype.Date{Time: fromDate, Valid: true},
+			ToDate:     pgtype.Date{Time: toDate, Valid: true},
+		})
+		if err != nil {
+			s.logger.ErrorContext(ctx, "Failed to execute ListWeeklyMeasurementAverages query", slog.Any("error", err), slog.String("user_id", userID), slog.String("metric", metric))
+			return nil, fmt.Errorf("failed to get weekly measurement series: %w", err)
+		}
+		for _, row := range rows {
+			minValue := numericToFloat64(ctx, s.logger, row.MinValue)
+			maxValue := numericToFloat64(ctx, s.logger, row.MaxValue)
+			count := int(row.MeasurementCount)
+			points = append(points, dto.MeasurementSeriesPoint{
+				Date:  row.WeekStartDate.Time.Format(dateLayout),
+				Value: numericToFloat64(ctx, s.logger, row.AvgValue),
+				Min:   &minValue,
+				Max:   &maxValue,
+				Count: &count,
+			})
+		}
+	}
+
+	return &dto.MeasurementSeriesResponse{
+		Metric:   metricRow.Code,
+		Unit:     metricRow.Unit,
+		Interval: interval,
+		Points:   points,
+	}, nil
+}
+
+// GetRelativeStrength は種目の週ごとの推定1RMと体重比 (推定1RM / 体重) を取得する
+func (s *MeasurementService) GetRelativeStrength(ctx context.Context, userID string, exerciseID uuid.UUID, weeks int32) (*dto.RelativeStrengthResponse, error) {
+	ctx, span := startSpan(ctx, "MeasurementService.GetRelativeStrength", attribute.String("user_id", userID), attribute.String("exercise_id", exerciseID.String()))
+	defer span.End()
+
+	if weeks <= 0 {
+		weeks = defaultRelativeStrengthWeeks
+	}
+
+	exercise, err := s.queries.GetExercise(ctx, exerciseID)
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return nil, httpError.NewExerciseNotFoundError("Exercise not found", err)
+		}
+		s.logger.ErrorContext(ctx, "Failed to execute GetExercise query", slog.Any("error", err), slog.String("exercise_id", exerciseID.String()))
+		return nil, err
+	}
+
+	fromDate := time.Now().In(jst).AddDate(0, 0, -7*int(weeks))
+	rows, err := s.queries.ListRelativeStrengthByWeek(ctx, sqlc.ListRelativeStrengthByWeekParams{
+		UserID:     userID,
+		ExerciseID: exerciseID,
+		FromDate:   pgtype.Date{Time: fromDate, Valid: true},
+	})
+	if err != nil {
+		s.logger.ErrorContext(ctx, "Failed to execute ListRelativeStrengthByWeek query", slog.Any("error", err), slog.String("user_id", userID), slog.String("exercise_id", exerciseID.String()))
+		return nil, fmt.Errorf("failed to get relative strength: %w", err)
+	}
+
+	points := make([]dto.RelativeStrengthPoint, 0, len(rows))
+	for _, row := range rows {
+		point := dto.RelativeStrengthPoint{
+			Week:     row.WeekStartDate.Time.Format(dateLayout),
+			EstOneRM: numericToFloat64(ctx, s.logger, row.EstOneRm),
+		}
+		if row.BodyWeightKg.Valid {
+			bodyWeight := numericToFloat64(ctx, s.logger, row.BodyWeightKg)
+			point.BodyWeightKg = &bodyWeight
+			if bodyWeight > 0 {
+				ratio := point.EstOneRM / bodyWeight
+				point.Ratio = &ratio
+			}
+		}
+		points = append(points, point)
+	}
+
+	return &dto.RelativeStrengthResponse{
+		ExerciseID:   exercise.ID,
+		ExerciseName: exercise.Name,
+		Points:       points,
+	}, nil
+}
+
+// getMetric は計測項目を取得する (存在しない場合は検証エラー)
+func (s *MeasurementService) getMetric(ctx context.Context, code string) (sqlc.MeasurementMetric, error) {
+	metric, err := s.queries.GetMeasurementMetric(ctx, code)
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return sqlc.MeasurementMetric{}, httpError.NewValidationError("Unknown metric: "+code, []httpError.ValidationDetail{
+				{Field: "metric", Reason: "INVALID_VALUE"},
+			})
+		}
+		s.logger.ErrorContext(ctx, "Failed to execute GetMeasurementMetric query", slog.Any("error", err), slog.String("metric", code))
+		return sqlc.MeasurementMetric{}, err
+	}
+	return metric, nil
+}
+
+// toMeasurementView は sqlc の身体計測記録を DTO に変換する
+func (s *MeasurementService) toMeasurementView(ctx context.Context, measurement sqlc.BodyMeasurement) dto.MeasurementView {
+	return dto.MeasurementView{
+		ID:         measurement.ID,
+		Metric:     measurement.MetricCode,
+		Value:      numericToFloat64(ctx, s.logger, measurement.Value),
+		MeasuredAt: measurement.MeasuredAt.Format(time.RFC3339),
+		Note:       pgtypeTextToPtrString(measurement.Note),
+	}
+}
+
+// measurementValueToNumeric は計測値を検証し pgtype.Numeric に変換する
+func measurementValueToNumeric(value float64) (pgtype.Numeric, error) {
+	var numeric pgtype.Numeric
+	if value <= 0 || value > maxMeasurementValue {
+		return numeric, httpError.NewValidationError("Value must be greater than 0 and at most 99999.99", []httpError.ValidationDetail{
+			{Field: "value", Reason: "RANGE"},
+		})
+	}
+	if err := numeric.Scan(strconv.FormatFloat(value, 'f', 2, 64)); err != nil {
+		return numeric, fmt.Errorf("value conversion error: %w", err)
+	}
+	return numeric, nil
+}
+
+// parseMeasuredAt は RFC3339 形式の計測日時をパースする
+func parseMeasuredAt(value string) (time.Time, error) {
+	measuredAt, err := time.Parse(time.RFC3339, value)
+	if err != nil {
+		return time.Time{}, httpError.NewValidationError("Invalid measured_at format. Expected RFC3339 format (e.g. 2025-05-05T07:00:00+09:00)", []httpError.ValidationDetail{
+			{Field: "measured_at", Reason: "INVALID_FORMAT"},
+		})
+	}
+	return measuredAt, nil
+}
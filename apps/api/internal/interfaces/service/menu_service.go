@@ -2,14 +2,19 @@ package service
 
 import (
 	"context"
+	"errors"
 	"log/slog"
 	"time"
 
+	"github.com/aiirononeko/bulktrack/apps/api/internal/cache"
+	httpError "github.com/aiirononeko/bulktrack/apps/api/internal/http"
 	"github.com/aiirononeko/bulktrack/apps/api/internal/infrastructure/sqlc"
 	"github.com/aiirononeko/bulktrack/apps/api/internal/interfaces/http/dto"
 	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5"
 	"github.com/jackc/pgx/v5/pgtype"
 	"github.com/jackc/pgx/v5/pgxpool"
+	"go.opentelemetry.io/otel/attribute"
 )
 
 // --- ヘルパー関数 (ファイルスコープなどに追加) ---
@@ -51,11 +56,21 @@ func NewMenuService(pool *pgxpool.Pool, logger *slog.Logger) *MenuService {
 
 // CreateMenu は新しいメニューを作成する
 func (s *MenuService) CreateMenu(ctx context.Context, req dto.CreateMenuRequest, userID string) (*dto.MenuResponse, error) {
+	ctx, span := startSpan(ctx, "MenuService.CreateMenu", attribute.String("user_id", userID))
+	defer span.End()
+
 	// --- 追加: DTO から pgtype.Text への変換 ---
 	pgDescription := ptrStringToPgtypeText(req.Description)
 	// s.logger.Debug("Converted description", slog.Any("pgtype_text", pgDescription)) // デバッグログ削除
 	// -----------------------------------------
 
+	// グループキーの検証 (トランザクション開始前に行う)
+	for _, item := range req.Items {
+		if err := validateGroupKey(item.GroupKey); err != nil {
+			return nil, err
+		}
+	}
+
 	// トランザクション開始
 	tx, err := s.pool.Begin(ctx)
 	if err != nil {
@@ -121,6 +136,7 @@ func (s *MenuService) CreateMenu(ctx context.Context, req dto.CreateMenuRequest,
 			PlannedSets:            pgPlannedSets,
 			PlannedReps:            pgPlannedReps,
 			PlannedIntervalSeconds: pgPlannedIntervalSeconds,
+			GroupKey:               ptrStringToPgtypeText(item.GroupKey),
 		})
 		if err != nil {
 			s.logger.ErrorContext(ctx, "Failed to execute CreateMenuItem query", slog.Any("error", err), slog.String("menu_id", menu.ID.String()), slog.Int("item_index", i), slog.Any("item", item))
@@ -143,6 +159,7 @@ func (s *MenuService) CreateMenu(ctx context.Context, req dto.CreateMenuRequest,
 			ExerciseID:   menuItem.ExerciseID.Bytes,
 			ExerciseName: exerciseName,
 			SetOrder:     menuItem.SetOrder,
+			GroupKey:     pgtypeTextToPtrString(menuItem.GroupKey),
 		}
 		if menuItem.PlannedSets.Valid {
 			val := menuItem.PlannedSets.Int32
@@ -178,8 +195,38 @@ func (s *MenuService) CreateMenu(ctx context.Context, req dto.CreateMenuRequest,
 	// ------------------------------------------------
 }
 
+// AuthorizeMenu はメニューがユーザー (コーチのアクセスの場合はアスリート) のものかを確認する
+// 他のユーザーのメニューは存在を知られないよう NotFound とする
+func (s *MenuService) AuthorizeMenu(ctx context.Context, menuID uuid.UUID, userID string) error {
+	ctx, span := startSpan(ctx, "MenuService.AuthorizeMenu", attribute.String("user_id", userID), attribute.String("menu_id", menuID.String()))
+	defer span.End()
+
+	menu, err := s.queries.GetMenu(ctx, menuID)
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return httpError.NewNotFoundError("Menu not found", err)
+		}
+		s.logger.ErrorContext(ctx, "Failed to execute GetMenu query", slog.Any("error", err), slog.String("menu_id", menuID.String()))
+		return err
+	}
+	if menu.UserID != userID {
+		return httpError.NewNotFoundError("Menu not found", nil)
+	}
+	return nil
+}
+
 // GetMenuWithItems はメニューとその項目を取得する
 func (s *MenuService) GetMenuWithItems(ctx context.Context, menuID uuid.UUID) (*dto.MenuResponse, error) {
+	ctx, span := startSpan(ctx, "MenuService.GetMenuWithItems", attribute.String("menu_id", menuID.String()))
+	defer span.End()
+
+	return cache.Load(ctx, responseCache, menuCacheScope(menuID), "", menuCacheTTL, func(ctx context.Context) (*dto.MenuResponse, error) {
+		return s.loadMenuWithItems(ctx, menuID)
+	})
+}
+
+// loadMenuWithItems はメニューとその項目を DB から読み込む
+func (s *MenuService) loadMenuWithItems(ctx context.Context, menuID uuid.UUID) (*dto.MenuResponse, error) {
 	// メニュー情報の取得
 	menu, err := s.queries.GetMenu(ctx, menuID)
 	if err != nil {
@@ -205,6 +252,7 @@ func (s *MenuService) GetMenuWithItems(ctx context.Context, menuID uuid.UUID) (*
 			ExerciseID:   item.ExerciseID.Bytes,
 			ExerciseName: item.ExerciseName,
 			SetOrder:     item.SetOrder,
+			GroupKey:     pgtypeTextToPtrString(item.GroupKey),
 		}
 		// Nullable フィールドの変換 (pgtype.Int4 -> *int32)
 		if item.PlannedSets.Valid {
@@ -234,6 +282,9 @@ func (s *MenuService) GetMenuWithItems(ctx context.Context, menuID uuid.UUID) (*
 
 // DeleteMenu はメニューを削除する
 func (s *MenuService) DeleteMenu(ctx context.Context, menuID uuid.UUID) (err error) {
+	ctx, span := startSpan(ctx, "MenuService.DeleteMenu", attribute.String("menu_id", menuID.String()))
+	defer span.End()
+
 	// トランザクション開始
 	tx, err := s.pool.Begin(ctx)
 	if err != nil {
@@ -273,6 +324,7 @@ func (s *MenuService) DeleteMenu(ctx context.Context, menuID uuid.UUID) (err err
 		s.logger.ErrorContext(ctx, "Failed to commit transaction for DeleteMenu", slog.Any("error", err), slog.String("menu_id", menuID.String()))
 		return err
 	}
+	responseCache.Invalidate(ctx, menuCacheScope(menuID))
 
 	s.logger.InfoContext(ctx, "Menu deleted successfully", slog.String("menu_id", menuID.String()))
 	return nil
@@ -280,6 +332,9 @@ func (s *MenuService) DeleteMenu(ctx context.Context, menuID uuid.UUID) (err err
 
 // ListMenusByUser はユーザーに紐づくメニュー一覧を取得する
 func (s *MenuService) ListMenusByUser(ctx context.Context, userID string) ([]dto.MenuResponse, error) {
+	ctx, span := startSpan(ctx, "MenuService.ListMenusByUser", attribute.String("user_id", userID))
+	defer span.End()
+
 	// メニュー一覧の取得
 	menus, err := s.queries.ListMenusByUser(ctx, userID)
 	if err != nil {
@@ -308,6 +363,7 @@ func (s *MenuService) ListMenusByUser(ctx context.Context, userID string) ([]dto
 				ExerciseID:   item.ExerciseID.Bytes,
 				ExerciseName: item.ExerciseName,
 				SetOrder:     item.SetOrder,
+				GroupKey:     pgtypeTextToPtrString(item.GroupKey),
 			}
 			// Nullable フィールドの変換 (pgtype.Int4 -> *int32)
 			if item.PlannedSets.Valid {
@@ -340,6 +396,16 @@ func (s *MenuService) ListMenusByUser(ctx context.Context, userID string) ([]dto
 
 // UpdateMenu はメニューを更新する
 func (s *MenuService) UpdateMenu(ctx context.Context, menuID uuid.UUID, req dto.MenuUpdateRequest) (*dto.MenuResponse, error) {
+	ctx, span := startSpan(ctx, "MenuService.UpdateMenu", attribute.String("menu_id", menuID.String()))
+	defer span.End()
+
+	// グループキーの検証 (トランザクション開始前に行う)
+	for _, item := range req.Items {
+		if err := validateGroupKey(item.GroupKey); err != nil {
+			return nil, err
+		}
+	}
+
 	// トランザクション開始
 	tx, err := s.pool.Begin(ctx)
 	if err != nil {
@@ -410,6 +476,7 @@ func (s *MenuService) UpdateMenu(ctx context.Context, menuID uuid.UUID, req dto.
 				PlannedSets:            pgPlannedSets,
 				PlannedReps:            pgPlannedReps,
 				PlannedIntervalSeconds: pgPlannedIntervalSeconds,
+				GroupKey:               ptrStringToPgtypeText(item.GroupKey),
 			})
 			if err != nil {
 				s.logger.ErrorContext(ctx, "Failed to execute CreateMenuItem query during update", slog.Any("error", err), slog.String("menu_id", menuID.String()), slog.Int("item_index", i))
@@ -432,6 +499,7 @@ func (s *MenuService) UpdateMenu(ctx context.Context, menuID uuid.UUID, req dto.
 				ExerciseID:   menuItem.ExerciseID.Bytes,
 				ExerciseName: exerciseName,
 				SetOrder:     menuItem.SetOrder,
+				GroupKey:     pgtypeTextToPtrString(menuItem.GroupKey),
 			}
 			if menuItem.PlannedSets.Valid {
 				val := menuItem.PlannedSets.Int32
@@ -463,6 +531,7 @@ func (s *MenuService) UpdateMenu(ctx context.Context, menuID uuid.UUID, req dto.
 				ExerciseID:   item.ExerciseID.Bytes,
 				ExerciseName: item.ExerciseName,
 				SetOrder:     item.SetOrder,
+				GroupKey:     pgtypeTextToPtrString(item.GroupKey),
 			}
 			if item.PlannedSets.Valid {
 				val := item.PlannedSets.Int32
@@ -485,6 +554,7 @@ func (s *MenuService) UpdateMenu(ctx context.Context, menuID uuid.UUID, req dto.
 		s.logger.ErrorContext(ctx, "Failed to commit transaction for UpdateMenu", slog.Any("error", err), slog.String("menu_id", menuID.String()))
 		return nil, err
 	}
+	responseCache.Invalidate(ctx, menuCacheScope(menuID))
 
 	// レスポンス作成
 	return &dto.MenuResponse{
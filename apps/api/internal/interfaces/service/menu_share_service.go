@@ -0,0 +1,364 @@
+package service
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/base32"
+	"errors"
+	"fmt"
+	"log/slog"
+	"strings"
+	"time"
+	"unicode/utf8"
+
+	httpError "github.com/aiirononeko/bulktrack/apps/api/internal/http"
+	"github.com/aiirononeko/bulktrack/apps/api/internal/infrastructure/sqlc"
+	"github.com/aiirononeko/bulktrack/apps/api/internal/interfaces/http/dto"
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgtype"
+	"github.com/jackc/pgx/v5/pgxpool"
+	"go.opentelemetry.io/otel/attribute"
+)
+
+// maxMenuNameLength はメニュー名の最大文字数 (validation.ValidateMenu と同じ)
+const maxMenuNameLength = 50
+
+// menuShareCodeBytes は共有コードのランダム部分のバイト数 (base32 で13文字)
+const menuShareCodeBytes = 8
+
+// MenuShareService はメニューの共有 (共有コードとテンプレートライブラリ) と複製を提供する
+type MenuShareService struct {
+	pool    *pgxpool.Pool
+	queries *sqlc.Queries
+	logger  *slog.Logger
+}
+
+// NewMenuShareService は新しい MenuShareService を作成する
+func NewMenuShareService(pool *pgxpool.Pool, logger *slog.Logger) *MenuShareService {
+	return &MenuShareService{
+		pool:    pool,
+		queries: sqlc.New(pool),
+		logger:  logger,
+	}
+}
+
+// ShareMenu はメニューの共有コードを発行する。既に共有中の場合はその共有コードを返す (created が false)
+func (s *MenuShareService) ShareMenu(ctx context.Context, userID string, menuID uuid.UUID) (view *dto.MenuShareView, created bool, err error) {
+	ctx, span := startSpan(ctx, "MenuShareService.ShareMenu", attribute.String("user_id", userID), attribute.String("menu_id", menuID.String()))
+	defer span.End()
+
+	if err := s.authorizeMenuOwner(ctx, userID, menuID); err != nil {
+		return nil, false, err
+	}
+
+	share, err := s.queries.GetActiveMenuShare(ctx, menuID)
+	if err == nil {
+		v := toMenuShareView(share)
+		return &v, false, nil
+	}
+	if !errors.Is(err, pgx.ErrNoRows) {
+		s.logger.ErrorContext(ctx, "Failed to execute GetActiveMenuShare query", slog.Any("error", err), slog.String("menu_id", menuID.String()))
+		return nil, false, fmt.Errorf("failed to get menu share: %w", err)
+	}
+
+	code := make([]byte, menuShareCodeBytes)
+	if _, err := rand.Read(code); err != nil {
+		return nil, false, fmt.Errorf("failed to generate share code: %w", err)
+	}
+	newShare, err := s.queries.CreateMenuShare(ctx, sqlc.CreateMenuShareParams{
+		MenuID:    menuID,
+		ShareCode: base32.StdEncoding.WithPadding(base32.NoPadding).EncodeToString(code),
+	})
+	if err != nil {
+		s.logger.ErrorContext(ctx, "Failed to execute CreateMenuShare query", slog.Any("error", err), slog.String("menu_id", menuID.String()))
+		return nil, false, fmt.Errorf("failed to create menu share: %w", err)
+	}
+
+	s.logger.InfoContext(ctx, "Shared menu", slog.String("user_id", userID), slog.String("menu_id", menuID.String()))
+	v := toMenuShareView(sqlc.GetActiveMenuShareRow(newShare))
+	return &v, true, nil
+}
+
+// UnshareMenu はメニューの共有をやめる (共有コードは使えなくなる。複製済みのメニューには影響しない)
+func (s *MenuShareService) UnshareMenu(ctx context.Context, userID string, menuID uuid.UUID) error {
+	ctx, span := startSpan(ctx, "MenuShareService.UnshareMenu", attribute.String("user_id", userID), attribute.String("menu_id", menuID.String()))
+	defer span.End()
+
+	if err := s.authorizeMenuOwner(ctx, userID, menuID); err != nil {
+		return err
+	}
+
+	revoked, err := s.queries.RevokeMenuShare(ctx, menuID)
+	if err != nil {
+		s.logger.ErrorContext(ctx, "Failed to execute RevokeMenuShare query", slog.Any("error", err), slog.String("menu_id", menuID.String()))
+		return fmt.Errorf("failed to revoke menu share: %w", err)
+	}
+	if revoked == 0 {
+		return httpError.NewNotFoundError("Menu is not shared", nil)
+	}
+
+	s.logger.InfoContext(ctx, "Unshared menu", slog.String("user_id", userID), slog.String("menu_id", menuID.String()))
+	return nil
+}
+
+// GetSharedMenu は共有コードのメニューをプレビューする
+func (s *MenuShareService) GetSharedMenu(ctx context.Context, shareCode string) (*dto.SharedMenuView, error) {
+	ctx, span := startSpan(ctx, "MenuShareService.GetSharedMenu")
+	defer span.End()
+
+	shared, err := s.getSharedMenu(ctx, s.queries, shareCode)
+	if err != nil {
+		return nil, err
+	}
+	items, err := s.queries.ListSharedMenuItems(ctx, shared.MenuID)
+	if err != nil {
+		s.logger.ErrorContext(ctx, "Failed to execute ListSharedMenuItems query", slog.Any("error", err), slog.String("menu_id", shared.MenuID.String()))
+		return nil, fmt.Errorf("failed to list shared menu items: %w", err)
+	}
+
+	view := &dto.SharedMenuView{
+		ShareCode:   shared.ShareCode,
+		Name:        shared.Name,
+		Description: pgtypeTextToPtrString(shared.Description),
+		IsTemplate:  shared.IsPublic,
+		CloneCount:  shared.CloneCount,
+		Items:       make([]dto.SharedMenuItemView, 0, len(items)),
+	}
+	for _, item := range items {
+		view.Items = append(view.Items, dto.SharedMenuItemView{
+			SetOrder:               item.SetOrder,
+			ExerciseName:           item.ExerciseName,
+			MetricType:             item.MetricType,
+			IsCustomExercise:       item.CreatedByUserID.Valid,
+			PlannedSets:            pgtypeInt4ToPtrInt32(item.PlannedSets),
+			PlannedReps:            pgtypeInt4ToPtrInt32(item.PlannedReps),
+			PlannedIntervalSeconds: pgtypeInt4ToPtrInt32(item.PlannedIntervalSeconds),
+			GroupKey:               pgtypeTextToPtrString(item.GroupKey),
+		})
+	}
+	return view, nil
+}
+
+// ListTemplates はテンプレートライブラリのメニューの一覧を取得する
+func (s *MenuShareService) ListTemplates(ctx context.Context) ([]dto.MenuTemplateView, error) {
+	ctx, span := startSpan(ctx, "MenuShareService.ListTemplates")
+	defer span.End()
+
+	rows, err := s.queries.ListPublicMenuTemplates(ctx)
+	if err != nil {
+		s.logger.ErrorContext(ctx, "Failed to execute ListPublicMenuTemplates query", slog.Any("error", err))
+		return nil, fmt.Errorf("failed to list menu templates: %w", err)
+	}
+
+	templates := make([]dto.MenuTemplateView, 0, len(rows))
+	for _, row := range rows {
+		templates = append(templates, dto.MenuTemplateView{
+			ShareCode:   row.ShareCode,
+			Name:        row.Name,
+			Description: pgtypeTextToPtrString(row.Description),
+			ItemCount:   row.ItemCount,
+			CloneCount:  row.CloneCount,
+		})
+	}
+	return templates, nil
+}
+
+// CloneMenu は共有コードのメニューをユーザーのアカウントに複製し、作成したメニューのIDを返す
+//
+// 同じ名前のメニューがある場合は "名前 (2)" のように番号を付ける。
+// 他のユーザーのカスタム種目は、ユーザーの同じ名前の種目 (組み込み種目を優先) に置き換え、ない場合はカスタム種目として作成する
+func (s *MenuShareService) CloneMenu(ctx context.Context, userID, shareCode string, req dto.CloneMenuRequest) (menuID uuid.UUID, createdExercises []string, err error) {
+	ctx, span := startSpan(ctx, "MenuShareService.CloneMenu", attribute.String("user_id", userID))
+	defer span.End()
+
+	requestedName := strings.TrimSpace(req.Name)
+	if utf8.RuneCountInString(requestedName) > maxMenuNameLength {
+		return uuid.Nil, nil, httpError.NewValidationError("Invalid clone request", []httpError.ValidationDetail{
+			{Field: "name", Reason: "MAX_LENGTH"},
+		})
+	}
+
+	tx, err := s.pool.Begin(ctx)
+	if err != nil {
+		s.logger.ErrorContext(ctx, "Failed to begin transaction for CloneMenu", slog.Any("error", err), slog.String("user_id", userID))
+		return uuid.Nil, nil, err
+	}
+	defer func() {
+		if r := recover(); r != nil {
+			s.logger.ErrorContext(ctx, "Recovered in CloneMenu, rolling back transaction", slog.Any("panic_value", r), slog.String("user_id", userID))
+			tx.Rollback(ctx)
+			panic(r)
+		} else if err != nil {
+			rollErr := tx.Rollback(ctx)
+			if rollErr != nil {
+				s.logger.ErrorContext(ctx, "Failed to rollback transaction for CloneMenu", slog.Any("rollback_error", rollErr), slog.Any("original_error", err), slog.String("user_id", userID))
+			}
+		}
+	}()
+
+	qtx := sqlc.New(tx)
+
+	shared, err := s.getSharedMenu(ctx, qtx, shareCode)
+	if err != nil {
+		return uuid.Nil, nil, err
+	}
+	items, err := qtx.ListSharedMenuItems(ctx, shared.MenuID)
+	if err != nil {
+		s.logger.ErrorContext(ctx, "Failed to execute ListSharedMenuItems query", slog.Any("error", err), slog.String("menu_id", shared.MenuID.String()))
+		return uuid.Nil, nil, fmt.Errorf("failed to list shared menu items: %w", err)
+	}
+
+	// メニュー名 (重複する場合は番号を付ける)
+	menus, err := qtx.ListMenusByUser(ctx, userID)
+	if err != nil {
+		s.logger.ErrorContext(ctx, "Failed to execute ListMenusByUser query", slog.Any("error", err), slog.String("user_id", userID))
+		return uuid.Nil, nil, fmt.Errorf("failed to list menus: %w", err)
+	}
+	taken := make(map[string]bool, len(menus))
+	for _, m := range menus {
+		taken[m.Name] = true
+	}
+	name := requestedName
+	if name == "" {
+		name = shared.Name
+	}
+	name = uniqueMenuName(name, taken)
+
+	// ユーザーが使える種目 (組み込み種目とユーザーのカスタム種目) を名前で引けるようにする
+	exercises, err := qtx.ListExercisesForUser(ctx, userID)
+	if err != nil {
+		s.logger.ErrorContext(ctx, "Failed to execute ListExercisesForUser query", slog.Any("error", err), slog.String("user_id", userID))
+		return uuid.Nil, nil, fmt.Errorf("failed to list exercises: %w", err)
+	}
+	exerciseIDs := make(map[string]uuid.UUID, len(exercises))
+	for _, e := range exercises {
+		if _, ok := exerciseIDs[e.Name]; !ok { // 組み込み種目が先に返る
+			exerciseIDs[e.Name] = e.ID
+		}
+	}
+
+	menu, err := qtx.CreateMenu(ctx, sqlc.CreateMenuParams{
+		UserID:      userID,
+		Name:        name,
+		Description: shared.Description,
+	})
+	if err != nil {
+		s.logger.ErrorContext(ctx, "Failed to execute CreateMenu query", slog.Any("error", err), slog.String("user_id", userID))
+		return uuid.Nil, nil, fmt.Errorf("failed to create menu: %w", err)
+	}
+
+	createdExercises = []string{} // 作成しなかった場合も空の配列で返す
+	for _, item := range items {
+		exerciseID := item.ExerciseID
+		if item.CreatedByUserID.Valid && item.CreatedByUserID.String != userID {
+			// 他のユーザーのカスタム種目
+			if id, ok := exerciseIDs[item.ExerciseName]; ok {
+				exerciseID = id
+			} else {
+				var created sqlc.Exercise
+				created, err = qtx.CreateExercise(ctx, sqlc.CreateExerciseParams{
+					Name:                    item.ExerciseName,
+					MainTargetMuscleGroupID: item.MainTargetMuscleGroupID,
+					IsCustom:                pgtype.Bool{Bool: true, Valid: true},
+					CreatedByUserID:         pgtype.Text{String: userID, Valid: true},
+					LoadType:                item.LoadType,
+					MetricType:              item.MetricType,
+				})
+				if err != nil {
+					s.logger.ErrorContext(ctx, "Failed to execute CreateExercise query", slog.Any("error", err), slog.String("user_id", userID), slog.String("name", item.ExerciseName))
+					return uuid.Nil, nil, fmt.Errorf("failed to create exercise %q: %w", item.ExerciseName, err)
+				}
+				exerciseID = created.ID
+				exerciseIDs[item.ExerciseName] = created.ID
+				createdExercises = append(createdExercises, item.ExerciseName)
+			}
+		}
+
+		if _, err = qtx.CreateMenuItem(ctx, sqlc.CreateMenuItemParams{
+			MenuID:                 pgtype.UUID{Bytes: menu.ID, Valid: true},
+			ExerciseID:             pgtype.UUID{Bytes: exerciseID, Valid: true},
+			SetOrder:               item.SetOrder,
+			PlannedSets:            item.PlannedSets,
+			PlannedReps:            item.PlannedReps,
+			PlannedIntervalSeconds: item.PlannedIntervalSeconds,
+			GroupKey:               item.GroupKey,
+		}); err != nil {
+			s.logger.ErrorContext(ctx, "Failed to execute CreateMenuItem query", slog.Any("error", err), slog.String("menu_id", menu.ID.String()))
+			return uuid.Nil, nil, fmt.Errorf("failed to create menu item: %w", err)
+		}
+	}
+
+	if err = qtx.IncrementMenuShareCloneCount(ctx, shared.ID); err != nil {
+		s.logger.ErrorContext(ctx, "Failed to execute IncrementMenuShareCloneCount query", slog.Any("error", err), slog.String("share_id", shared.ID.String()))
+		return uuid.Nil, nil, fmt.Errorf("failed to count menu clone: %w", err)
+	}
+
+	if err = tx.Commit(ctx); err != nil {
+		s.logger.ErrorContext(ctx, "Failed to commit transaction for CloneMenu", slog.Any("error", err), slog.String("user_id", userID))
+		return uuid.Nil, nil, fmt.Errorf("failed to commit menu clone: %w", err)
+	}
+	if len(createdExercises) > 0 {
+		invalidateExerciseCatalog(ctx)
+	}
+
+	s.logger.InfoContext(ctx, "Cloned shared menu", slog.String("user_id", userID), slog.String("menu_id", menu.ID.String()), slog.String("share_id", shared.ID.String()), slog.Int("created_exercises", len(createdExercises)))
+	return menu.ID, createdExercises, nil
+}
+
+// getSharedMenu は共有コードのメニューを取得する (存在しない・共有をやめた場合は NotFound)
+func (s *MenuShareService) getSharedMenu(ctx context.Context, q *sqlc.Queries, shareCode string) (sqlc.GetSharedMenuRow, error) {
+	shared, err := q.GetSharedMenu(ctx, strings.ToUpper(strings.TrimSpace(shareCode)))
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return shared, httpError.NewNotFoundError("Shared menu not found", err)
+		}
+		s.logger.ErrorContext(ctx, "Failed to execute GetSharedMenu query", slog.Any("error", err))
+		return shared, fmt.Errorf("failed to get shared menu: %w", err)
+	}
+	return shared, nil
+}
+
+// authorizeMenuOwner はメニューがユーザーのものかを確認する (他のユーザーのメニューは NotFound)
+func (s *MenuShareService) authorizeMenuOwner(ctx context.Context, userID string, menuID uuid.UUID) error {
+	menu, err := s.queries.GetMenu(ctx, menuID)
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return httpError.NewNotFoundError("Menu not found", err)
+		}
+		s.logger.ErrorContext(ctx, "Failed to execute GetMenu query", slog.Any("error", err), slog.String("menu_id", menuID.String()))
+		return fmt.Errorf("failed to get menu: %w", err)
+	}
+	if menu.UserID != userID {
+		return httpError.NewNotFoundError("Menu not found", nil)
+	}
+	return nil
+}
+
+// uniqueMenuName は taken と重複しないメニュー名を返す ("名前 (2)", "名前 (3)", ...)
+func uniqueMenuName(name string, taken map[string]bool) string {
+	if !taken[name] {
+		return name
+	}
+	base := []rune(name)
+	for n := 2; ; n++ {
+		suffix := fmt.Sprintf(" (%d)", n)
+		if limit := maxMenuNameLength - utf8.RuneCountInString(suffix); len(base) > limit {
+			base = base[:limit]
+		}
+		if candidate := string(base) + suffix; !taken[candidate] {
+			return candidate
+		}
+	}
+}
+
+// toMenuShareView は sqlc の型をレスポンスの型に変換する
+func toMenuShareView(share sqlc.GetActiveMenuShareRow) dto.MenuShareView {
+	return dto.MenuShareView{
+		ShareCode:  share.ShareCode,
+		MenuID:     share.MenuID,
+		IsPublic:   share.IsPublic,
+		CloneCount: share.CloneCount,
+		CreatedAt:  share.CreatedAt.Format(time.RFC3339),
+	}
+}
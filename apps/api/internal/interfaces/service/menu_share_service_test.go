@@ -0,0 +1,36 @@
+package service
+
+import (
+	"strings"
+	"testing"
+	"unicode/utf8"
+)
+
+func TestUniqueMenuName(t *testing.T) {
+	long := strings.Repeat("胸", maxMenuNameLength)
+	tests := []struct {
+		name  string
+		taken []string
+		want  string
+	}{
+		{name: "Push", want: "Push"},
+		{name: "Push", taken: []string{"Push"}, want: "Push (2)"},
+		{name: "Push", taken: []string{"Push", "Push (2)", "Push (3)"}, want: "Push (4)"},
+		{name: "Push", taken: []string{"Push (2)"}, want: "Push"},
+		// 番号を付けても最大文字数を超えないように名前を切り詰める
+		{name: long, taken: []string{long}, want: strings.Repeat("胸", maxMenuNameLength-4) + " (2)"},
+	}
+	for _, tt := range tests {
+		taken := make(map[string]bool, len(tt.taken))
+		for _, name := range tt.taken {
+			taken[name] = true
+		}
+		got := uniqueMenuName(tt.name, taken)
+		if got != tt.want {
+			t.Errorf("uniqueMenuName(%q, %v) = %q, want %q", tt.name, tt.taken, got, tt.want)
+		}
+		if n := utf8.RuneCountInString(got); n > maxMenuNameLength {
+			t.Errorf("uniqueMenuName(%q, %v) has %d characters, want at most %d", tt.name, tt.taken, n, maxMenuNameLength)
+		}
+	}
+}
@@ -0,0 +1,178 @@
+package service
+
+import (
+	"fmt"
+	"strconv"
+
+	httpError "github.com/aiirononeko/bulktrack/apps/api/internal/http"
+	"github.com/aiirononeko/bulktrack/apps/api/internal/volume"
+	"github.com/jackc/pgx/v5/pgtype"
+)
+
+// 種目の記録指標 (exercises.metric_type)
+const (
+	MetricTypeWeightReps   = "weight_reps"   // 重量 x 回数 (デフォルト)
+	MetricTypeReps         = "reps"          // 回数のみ
+	MetricTypeTime         = "time"          // 時間 (プランクなど)
+	MetricTypeDistanceTime = "distance_time" // 距離 + 時間 (ローイング・バイク・ランニングなど)
+)
+
+// 平均心拍数の許容範囲 (sets.avg_heart_rate の CHECK 制約と同じ)
+const (
+	minAvgHeartRate = 30
+	maxAvgHeartRate = 250
+)
+
+// maxDistanceM はセットの距離 (メートル) の上限 (NUMERIC(9,2))
+const maxDistanceM = 9999999.99
+
+// isCardioMetricType は有酸素・時間計測の種目 (ボリューム・推定1RMの集計対象外) かどうかを返す
+func isCardioMetricType(metricType string) bool {
+	return metricType == MetricTypeTime || metricType == MetricTypeDistanceTime
+}
+
+// setMetrics はセットに記録する値を表す (記録指標ごとの検証に使う)
+type setMetrics struct {
+	LoadType        string   // 種目の負荷の種類 (空の場合は external)
+	WeightKg        *float64 // nil は重量の記録なし (自重系の種目のみ可)
+	Reps            int32
+	DurationSeconds *int32
+	DistanceM       *float64
+	AvgHeartRate    *int32
+}
+
+// validateSetMetrics は種目の記録指標に応じてセットの値を検証する
+//
+//	weight_reps:   weight_kg >= 0, reps >= 0 (時間・距離は記録不可。weight_kg は外部負荷の種目のみ必須)
+//	reps:          reps >= 0 (重量・時間・距離は記録不可)
+//	time:          duration_seconds 必須 (回数・距離は記録不可、加重の重量は可)
+//	distance_time: distance_m と duration_seconds 必須 (重量・回数は記録不可)
+//
+// avg_heart_rate はどの記録指標でも任意
+func validateSetMetrics(metricType string, m setMetrics) error {
+	var details []httpError.ValidationDetail
+	notAllowed := func(field string) {
+		details = append(details, httpError.ValidationDetail{Field: field, Reason: "NOT_ALLOWED"})
+	}
+	required := func(field string) {
+		details = append(details, httpError.ValidationDetail{Field: field, Reason: "REQUIRED"})
+	}
+
+	if m.WeightKg != nil && *m.WeightKg < 0 {
+		details = append(details, httpError.ValidationDetail{Field: "weight_kg", Reason: "RANGE"})
+	}
+	if m.Reps < 0 {
+		details = append(details, httpError.ValidationDetail{Field: "reps", Reason: "RANGE"})
+	}
+	if m.DurationSeconds != nil && *m.DurationSeconds <= 0 {
+		details = append(details, httpError.ValidationDetail{Field: "duration_seconds", Reason: "RANGE"})
+	}
+	if m.DistanceM != nil && (*m.DistanceM <= 0 || *m.DistanceM > maxDistanceM) {
+		details = append(details, httpError.ValidationDetail{Field: "distance_m", Reason: "RANGE"})
+	}
+	if m.AvgHeartRate != nil && (*m.AvgHeartRate < minAvgHeartRate || *m.AvgHeartRate > maxAvgHeartRate) {
+		details = append(details, httpError.ValidationDetail{Field: "avg_heart_rate", Reason: "RANGE"})
+	}
+
+	switch metricType {
+	case MetricTypeWeightReps, "":
+		if m.WeightKg == nil && (m.LoadType == "" || m.LoadType == volume.LoadTypeExternal) {
+			required("weight_kg")
+		}
+		if m.DurationSeconds != nil {
+			notAllowed("duration_seconds")
+		}
+		if m.DistanceM != nil {
+			notAllowed("distance_m")
+		}
+	case MetricTypeReps:
+		if m.WeightKg != nil && *m.WeightKg != 0 {
+			notAllowed("weight_kg")
+		}
+		if m.DurationSeconds != nil {
+			notAllowed("duration_seconds")
+		}
+		if m.DistanceM != nil {
+			notAllowed("distance_m")
+		}
+	case MetricTypeTime:
+		if m.DurationSeconds == nil {
+			required("duration_seconds")
+		}
+		if m.Reps != 0 {
+			notAllowed("reps")
+		}
+		if m.DistanceM != nil {
+			notAllowed("distance_m")
+		}
+	case MetricTypeDistanceTime:
+		if m.DistanceM == nil {
+			required("distance_m")
+		}
+		if m.DurationSeconds == nil {
+			required("duration_seconds")
+		}
+		if m.WeightKg != nil && *m.WeightKg != 0 {
+			notAllowed("weight_kg")
+		}
+		if m.Reps != 0 {
+			notAllowed("reps")
+		}
+	}
+
+	if len(details) > 0 {
+		return httpError.NewValidationError(fmt.Sprintf("Invalid set values for metric type %s", metricType), details)
+	}
+	return nil
+}
+
+// paceSecondsPerKm は距離と時間から 1km あたりのペース (秒) を算出する (算出できない場合は nil)
+func paceSecondsPerKm(durationSeconds pgtype.Int4, distanceM *float64) *float64 {
+	if !durationSeconds.Valid || distanceM == nil || *distanceM <= 0 {
+		return nil
+	}
+	pace := float64(durationSeconds.Int32) / (*distanceM / 1000)
+	return &pace
+}
+
+// ptrInt32ToPgtypeInt4 は *int32 を pgtype.Int4 に変換する (nil は NULL)
+func ptrInt32ToPgtypeInt4(v *int32) pgtype.Int4 {
+	if v == nil {
+		return pgtype.Int4{}
+	}
+	return pgtype.Int4{Int32: *v, Valid: true}
+}
+
+// pgtypeInt4ToPtrInt32 は pgtype.Int4 を *int32 に変換する (NULL は nil)
+func pgtypeInt4ToPtrInt32(v pgtype.Int4) *int32 {
+	if !v.Valid {
+		return nil
+	}
+	i := v.Int32
+	return &i
+}
+
+// ptrFloat64ToPgtypeNumeric は *float64 を小数点以下2桁の pgtype.Numeric に変換する (nil は NULL)
+func ptrFloat64ToPgtypeNumeric(v *float64) (pgtype.Numeric, error) {
+	var n pgtype.Numeric
+	if v == nil {
+		return n, nil
+	}
+	if err := n.Scan(strconv.FormatFloat(*v, 'f', 2, 64)); err != nil {
+		return n, err
+	}
+	return n, nil
+}
+
+// pgtypeNumericToPtrFloat64 は pgtype.Numeric を *float64 に変換する (NULL は nil)
+func pgtypeNumericToPtrFloat64(n pgtype.Numeric) *float64 {
+	if !n.Valid {
+		return nil
+	}
+	v, err := n.Float64Value()
+	if err != nil || !v.Valid {
+		return nil
+	}
+	f := v.Float64
+	return &f
+}
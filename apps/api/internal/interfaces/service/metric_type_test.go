@@ -0,0 +1,243 @@
+package service
+
+import (
+	"errors"
+	"reflect"
+	"testing"
+
+	httpError "github.com/aiirononeko/bulktrack/apps/api/internal/http"
+	"github.com/aiirononeko/bulktrack/apps/api/internal/volume"
+	"github.com/jackc/pgx/v5/pgtype"
+)
+
+// validationDetails はバリデーションエラーの詳細を返す (エラーがない場合は nil)
+func validationDetails(t *testing.T, err error) []httpError.ValidationDetail {
+	t.Helper()
+	if err == nil {
+		return nil
+	}
+	var appErr *httpError.AppError
+	if !errors.As(err, &appErr) || appErr.Code != httpError.ErrorValidationError {
+		t.Fatalf("error = %v, want a validation error", err)
+	}
+	return appErr.Details
+}
+
+func TestValidateSetMetricsWeightByLoadType(t *testing.T) {
+	zero, weight, negative := 0.0, 20.0, -5.0
+	tests := []struct {
+		name       string
+		metricType string
+		metrics    setMetrics
+		want       []httpError.ValidationDetail
+	}{
+		{
+			name:       "external requires weight",
+			metricType: MetricTypeWeightReps,
+			metrics:    setMetrics{LoadType: volume.LoadTypeExternal, Reps: 10},
+			want:       []httpError.ValidationDetail{{Field: "weight_kg", Reason: "REQUIRED"}},
+		},
+		{
+			name:       "unknown load type is treated as external",
+			metricType: MetricTypeWeightReps,
+			metrics:    setMetrics{Reps: 10},
+			want:       []httpError.ValidationDetail{{Field: "weight_kg", Reason: "REQUIRED"}},
+		},
+		{
+			name:       "unknown metric type is treated as weight_reps",
+			metricType: "",
+			metrics:    setMetrics{LoadType: volume.LoadTypeExternal, Reps: 10},
+			want:       []httpError.ValidationDetail{{Field: "weight_kg", Reason: "REQUIRED"}},
+		},
+		{
+			name:       "external with zero weight",
+			metricType: MetricTypeWeightReps,
+			metrics:    setMetrics{LoadType: volume.LoadTypeExternal, WeightKg: &zero, Reps: 10},
+		},
+		{
+			name:       "bodyweight without weight",
+			metricType: MetricTypeWeightReps,
+			metrics:    setMetrics{LoadType: volume.LoadTypeBodyweight, Reps: 10},
+		},
+		{
+			name:       "bodyweight_plus without added weight",
+			metricType: MetricTypeWeightReps,
+			metrics:    setMetrics{LoadType: volume.LoadTypeBodyweightPlus, Reps: 10},
+		},
+		{
+			name:       "bodyweight_plus with added weight",
+			metricType: MetricTypeWeightReps,
+			metrics:    setMetrics{LoadType: volume.LoadTypeBodyweightPlus, WeightKg: &weight, Reps: 10},
+		},
+		{
+			name:       "assisted without assisting weight",
+			metricType: MetricTypeWeightReps,
+			metrics:    setMetrics{LoadType: volume.LoadTypeAssisted, Reps: 10},
+		},
+		{
+			name:       "negative weight is out of range for any load type",
+			metricType: MetricTypeWeightReps,
+			metrics:    setMetrics{LoadType: volume.LoadTypeAssisted, WeightKg: &negative, Reps: 10},
+			want:       []httpError.ValidationDetail{{Field: "weight_kg", Reason: "RANGE"}},
+		},
+		{
+			name:       "reps exercise without weight",
+			metricType: MetricTypeReps,
+			metrics:    setMetrics{LoadType: volume.LoadTypeExternal, Reps: 10},
+		},
+		{
+			name:       "reps exercise with weight",
+			metricType: MetricTypeReps,
+			metrics:    setMetrics{LoadType: volume.LoadTypeBodyweight, WeightKg: &weight, Reps: 10},
+			want:       []httpError.ValidationDetail{{Field: "weight_kg", Reason: "NOT_ALLOWED"}},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := validationDetails(t, validateSetMetrics(tt.metricType, tt.metrics))
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("validateSetMetrics() details = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestValidateSetMetricsByMetricType(t *testing.T) {
+	weight := 20.0
+	tests := []struct {
+		name       string
+		metricType string
+		metrics    setMetrics
+		want       []httpError.ValidationDetail
+	}{
+		{
+			name:       "weight_reps",
+			metricType: MetricTypeWeightReps,
+			metrics:    setMetrics{WeightKg: &weight, Reps: 10, AvgHeartRate: ptrTo[int32](120)},
+		},
+		{
+			name:       "weight_reps with duration and distance",
+			metricType: MetricTypeWeightReps,
+			metrics:    setMetrics{WeightKg: &weight, Reps: 10, DurationSeconds: ptrTo[int32](60), DistanceM: ptrTo(100.0)},
+			want:       []httpError.ValidationDetail{{Field: "duration_seconds", Reason: "NOT_ALLOWED"}, {Field: "distance_m", Reason: "NOT_ALLOWED"}},
+		},
+		{
+			name:       "weight_reps with negative reps",
+			metricType: MetricTypeWeightReps,
+			metrics:    setMetrics{WeightKg: &weight, Reps: -1},
+			want:       []httpError.ValidationDetail{{Field: "reps", Reason: "RANGE"}},
+		},
+		{
+			name:       "reps with zero weight",
+			metricType: MetricTypeReps,
+			metrics:    setMetrics{WeightKg: ptrTo(0.0), Reps: 15},
+		},
+		{
+			name:       "reps with duration",
+			metricType: MetricTypeReps,
+			metrics:    setMetrics{Reps: 15, DurationSeconds: ptrTo[int32](30)},
+			want:       []httpError.ValidationDetail{{Field: "duration_seconds", Reason: "NOT_ALLOWED"}},
+		},
+		{
+			name:       "time with added weight",
+			metricType: MetricTypeTime,
+			metrics:    setMetrics{WeightKg: &weight, DurationSeconds: ptrTo[int32](60)},
+		},
+		{
+			name:       "time without duration",
+			metricType: MetricTypeTime,
+			metrics:    setMetrics{Reps: 10, DistanceM: ptrTo(100.0)},
+			want: []httpError.ValidationDetail{
+				{Field: "duration_seconds", Reason: "REQUIRED"},
+				{Field: "reps", Reason: "NOT_ALLOWED"},
+				{Field: "distance_m", Reason: "NOT_ALLOWED"},
+			},
+		},
+		{
+			name:       "time with zero duration",
+			metricType: MetricTypeTime,
+			metrics:    setMetrics{DurationSeconds: ptrTo[int32](0)},
+			want:       []httpError.ValidationDetail{{Field: "duration_seconds", Reason: "RANGE"}},
+		},
+		{
+			name:       "distance_time",
+			metricType: MetricTypeDistanceTime,
+			metrics:    setMetrics{DurationSeconds: ptrTo[int32](1500), DistanceM: ptrTo(5000.0), AvgHeartRate: ptrTo[int32](150)},
+		},
+		{
+			name:       "distance_time without distance and duration",
+			metricType: MetricTypeDistanceTime,
+			metrics:    setMetrics{},
+			want:       []httpError.ValidationDetail{{Field: "distance_m", Reason: "REQUIRED"}, {Field: "duration_seconds", Reason: "REQUIRED"}},
+		},
+		{
+			name:       "distance_time with weight and reps",
+			metricType: MetricTypeDistanceTime,
+			metrics:    setMetrics{WeightKg: &weight, Reps: 10, DurationSeconds: ptrTo[int32](1500), DistanceM: ptrTo(5000.0)},
+			want:       []httpError.ValidationDetail{{Field: "weight_kg", Reason: "NOT_ALLOWED"}, {Field: "reps", Reason: "NOT_ALLOWED"}},
+		},
+		{
+			name:       "distance_time with distance out of range",
+			metricType: MetricTypeDistanceTime,
+			metrics:    setMetrics{DurationSeconds: ptrTo[int32](1500), DistanceM: ptrTo(maxDistanceM + 0.01)},
+			want:       []httpError.ValidationDetail{{Field: "distance_m", Reason: "RANGE"}},
+		},
+		{
+			name:       "heart rate below the range",
+			metricType: MetricTypeDistanceTime,
+			metrics:    setMetrics{DurationSeconds: ptrTo[int32](1500), DistanceM: ptrTo(5000.0), AvgHeartRate: ptrTo[int32](minAvgHeartRate - 1)},
+			want:       []httpError.ValidationDetail{{Field: "avg_heart_rate", Reason: "RANGE"}},
+		},
+		{
+			name:       "heart rate above the range",
+			metricType: MetricTypeWeightReps,
+			metrics:    setMetrics{WeightKg: &weight, Reps: 10, AvgHeartRate: ptrTo[int32](maxAvgHeartRate + 1)},
+			want:       []httpError.ValidationDetail{{Field: "avg_heart_rate", Reason: "RANGE"}},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := validationDetails(t, validateSetMetrics(tt.metricType, tt.metrics))
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("validateSetMetrics() details = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestIsCardioMetricType(t *testing.T) {
+	tests := map[string]bool{
+		MetricTypeWeightReps:   false,
+		MetricTypeReps:         false,
+		MetricTypeTime:         true,
+		MetricTypeDistanceTime: true,
+		"":                     false,
+	}
+	for metricType, want := range tests {
+		if got := isCardioMetricType(metricType); got != want {
+			t.Errorf("isCardioMetricType(%q) = %v, want %v", metricType, got, want)
+		}
+	}
+}
+
+func TestPaceSecondsPerKm(t *testing.T) {
+	tests := []struct {
+		name            string
+		durationSeconds pgtype.Int4
+		distanceM       *float64
+		want            *float64
+	}{
+		{name: "5km in 25 minutes", durationSeconds: pgtype.Int4{Int32: 1500, Valid: true}, distanceM: ptrTo(5000.0), want: ptrTo(300.0)},
+		{name: "400m in 90 seconds", durationSeconds: pgtype.Int4{Int32: 90, Valid: true}, distanceM: ptrTo(400.0), want: ptrTo(225.0)},
+		{name: "no duration", distanceM: ptrTo(5000.0)},
+		{name: "no distance", durationSeconds: pgtype.Int4{Int32: 1500, Valid: true}},
+		{name: "zero distance", durationSeconds: pgtype.Int4{Int32: 1500, Valid: true}, distanceM: ptrTo(0.0)},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := paceSecondsPerKm(tt.durationSeconds, tt.distanceM); !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("paceSecondsPerKm() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
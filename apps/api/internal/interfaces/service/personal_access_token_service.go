@@ -0,0 +1,206 @@
+package service
+
+import (
+	"context"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/base64"
+	"errors"
+	"fmt"
+	"log/slog"
+	"slices"
+	"strings"
+	"time"
+	"unicode/utf8"
+
+	"github.com/aiirononeko/bulktrack/apps/api/internal/auth"
+	httpError "github.com/aiirononeko/bulktrack/apps/api/internal/http"
+	"github.com/aiirononeko/bulktrack/apps/api/internal/infrastructure/sqlc"
+	"github.com/aiirononeko/bulktrack/apps/api/internal/interfaces/http/dto"
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgtype"
+	"github.com/jackc/pgx/v5/pgxpool"
+	"go.opentelemetry.io/otel/attribute"
+)
+
+// パーソナルアクセストークンの制限
+const (
+	maxPersonalAccessTokensPerUser   = 20
+	maxPersonalAccessTokenNameLength = 100
+	maxPersonalAccessTokenDays       = 365
+	personalAccessTokenPrefixLength  = len(auth.PersonalAccessTokenPrefix) + 6 // 一覧で表示するトークンの先頭部分
+	personalAccessTokenSecretBytes   = 32
+)
+
+// PersonalAccessTokenService はパーソナルアクセストークン (スクリプトや外部連携用の API トークン) を提供する
+type PersonalAccessTokenService struct {
+	pool    *pgxpool.Pool
+	queries *sqlc.Queries
+	logger  *slog.Logger
+}
+
+// NewPersonalAccessTokenService は新しい PersonalAccessTokenService を作成する
+func NewPersonalAccessTokenService(pool *pgxpool.Pool, logger *slog.Logger) *PersonalAccessTokenService {
+	return &PersonalAccessTokenService{
+		pool:    pool,
+		queries: sqlc.New(pool),
+		logger:  logger,
+	}
+}
+
+// CreateToken はパーソナルアクセストークンを作成する
+// トークンはハッシュのみ保存するため、レスポンスでのみ返す
+func (s *PersonalAccessTokenService) CreateToken(ctx context.Context, userID string, req dto.CreatePersonalAccessTokenRequest) (*dto.CreatedPersonalAccessToken, error) {
+	ctx, span := startSpan(ctx, "PersonalAccessTokenService.CreateToken", attribute.String("user_id", userID))
+	defer span.End()
+
+	name := strings.TrimSpace(req.Name)
+	var details []httpError.ValidationDetail
+	if name == "" {
+		details = append(details, httpError.ValidationDetail{Field: "name", Reason: "REQUIRED"})
+	} else if utf8.RuneCountInString(name) > maxPersonalAccessTokenNameLength {
+		details = append(details, httpError.ValidationDetail{Field: "name", Reason: "MAX_LENGTH"})
+	}
+	if len(req.Scopes) == 0 {
+		details = append(details, httpError.ValidationDetail{Field: "scopes", Reason: "REQUIRED"})
+	}
+	for i, scope := range req.Scopes {
+		if !auth.IsValidScope(scope) {
+			details = append(details, httpError.ValidationDetail{Field: fmt.Sprintf("scopes[%d]", i), Reason: "INVALID_VALUE"})
+		}
+	}
+	if req.ExpiresInDays != nil && (*req.ExpiresInDays < 1 || *req.ExpiresInDays > maxPersonalAccessTokenDays) {
+		details = append(details, httpError.ValidationDetail{Field: "expires_in_days", Reason: "RANGE"})
+	}
+	if len(details) > 0 {
+		return nil, httpError.NewValidationError("Invalid personal access token request", details)
+	}
+
+	count, err := s.queries.CountActivePersonalAccessTokens(ctx, userID)
+	if err != nil {
+		s.logger.ErrorContext(ctx, "Failed to execute CountActivePersonalAccessTokens query", slog.Any("error", err), slog.String("user_id", userID))
+		return nil, fmt.Errorf("failed to count personal access tokens: %w", err)
+	}
+	if count >= maxPersonalAccessTokensPerUser {
+		return nil, httpError.NewItemsTooManyError(fmt.Sprintf("Up to %d personal access tokens can be active", maxPersonalAccessTokensPerUser), nil)
+	}
+
+	secret := make([]byte, personalAccessTokenSecretBytes)
+	if _, err := rand.Read(secret); err != nil {
+		return nil, fmt.Errorf("failed to generate personal access token: %w", err)
+	}
+	token := auth.PersonalAccessTokenPrefix + base64.RawURLEncoding.EncodeToString(secret)
+
+	scopes := slices.Clone(req.Scopes)
+	slices.Sort(scopes)
+	scopes = slices.Compact(scopes)
+
+	var expiresAt pgtype.Timestamptz
+	if req.ExpiresInDays != nil {
+		expiresAt = pgtype.Timestamptz{Time: time.Now().AddDate(0, 0, *req.ExpiresInDays), Valid: true}
+	}
+
+	created, err := s.queries.CreatePersonalAccessToken(ctx, sqlc.CreatePersonalAccessTokenParams{
+		UserID:      userID,
+		Name:        name,
+		TokenHash:   hashPersonalAccessToken(token),
+		TokenPrefix: token[:personalAccessTokenPrefixLength],
+		Scopes:      scopes,
+		ExpiresAt:   expiresAt,
+	})
+	if err != nil {
+		s.logger.ErrorContext(ctx, "Failed to execute CreatePersonalAccessToken query", slog.Any("error", err), slog.String("user_id", userID))
+		return nil, fmt.Errorf("failed to create personal access token: %w", err)
+	}
+
+	s.logger.InfoContext(ctx, "Created personal access token", slog.String("user_id", userID), slog.String("token_id", created.ID.String()), slog.Any("scopes", scopes))
+	return &dto.CreatedPersonalAccessToken{
+		PersonalAccessTokenView: toPersonalAccessTokenView(created),
+		Token:                   token,
+	}, nil
+}
+
+// ListTokens はユーザーの (失効していない) パーソナルアクセストークンの一覧を取得する
+func (s *PersonalAccessTokenService) ListTokens(ctx context.Context, userID string) ([]dto.PersonalAccessTokenView, error) {
+	ctx, span := startSpan(ctx, "PersonalAccessTokenService.ListTokens", attribute.String("user_id", userID))
+	defer span.End()
+
+	rows, err := s.queries.ListPersonalAccessTokens(ctx, userID)
+	if err != nil {
+		s.logger.ErrorContext(ctx, "Failed to execute ListPersonalAccessTokens query", slog.Any("error", err), slog.String("user_id", userID))
+		return nil, fmt.Errorf("failed to list personal access tokens: %w", err)
+	}
+
+	tokens := make([]dto.PersonalAccessTokenView, 0, len(rows))
+	for _, row := range rows {
+		tokens = append(tokens, toPersonalAccessTokenView(sqlc.CreatePersonalAccessTokenRow(row)))
+	}
+	return tokens, nil
+}
+
+// RevokeToken はパーソナルアクセストークンを失効させる
+func (s *PersonalAccessTokenService) RevokeToken(ctx context.Context, userID string, tokenID uuid.UUID) error {
+	ctx, span := startSpan(ctx, "PersonalAccessTokenService.RevokeToken", attribute.String("user_id", userID), attribute.String("token_id", tokenID.String()))
+	defer span.End()
+
+	revoked, err := s.queries.RevokePersonalAccessToken(ctx, sqlc.RevokePersonalAccessTokenParams{ID: tokenID, UserID: userID})
+	if err != nil {
+		s.logger.ErrorContext(ctx, "Failed to execute RevokePersonalAccessToken query", slog.Any("error", err), slog.String("user_id", userID), slog.String("token_id", tokenID.String()))
+		return fmt.Errorf("failed to revoke personal access token: %w", err)
+	}
+	if revoked == 0 {
+		return httpError.NewNotFoundError("Personal access token not found", nil)
+	}
+
+	s.logger.InfoContext(ctx, "Revoked personal access token", slog.String("user_id", userID), slog.String("token_id", tokenID.String()))
+	return nil
+}
+
+// AuthenticateToken はパーソナルアクセストークンを検証し、ユーザーIDとスコープを返す
+// 存在しない・失効した・期限切れのトークンは auth.ErrInvalidPersonalAccessToken を返す
+func (s *PersonalAccessTokenService) AuthenticateToken(ctx context.Context, token string) (string, []string, error) {
+	ctx, span := startSpan(ctx, "PersonalAccessTokenService.AuthenticateToken")
+	defer span.End()
+
+	if !strings.HasPrefix(token, auth.PersonalAccessTokenPrefix) {
+		return "", nil, auth.ErrInvalidPersonalAccessToken
+	}
+
+	row, err := s.queries.GetActivePersonalAccessToken(ctx, hashPersonalAccessToken(token))
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return "", nil, auth.ErrInvalidPersonalAccessToken
+		}
+		s.logger.ErrorContext(ctx, "Failed to execute GetActivePersonalAccessToken query", slog.Any("error", err))
+		return "", nil, fmt.Errorf("failed to get personal access token: %w", err)
+	}
+
+	// 最終利用日時はおおよそで良いため、1分以内に記録済みの場合は更新しない
+	if !row.LastUsedAt.Valid || time.Since(row.LastUsedAt.Time) > time.Minute {
+		if err := s.queries.TouchPersonalAccessToken(ctx, row.ID); err != nil {
+			s.logger.WarnContext(ctx, "Failed to record personal access token usage", slog.Any("error", err), slog.String("token_id", row.ID.String()))
+		}
+	}
+	return row.UserID, row.Scopes, nil
+}
+
+// hashPersonalAccessToken はトークンを保存・検索するためのハッシュを返す
+// トークンは十分な長さのランダム値のため、ソルトなしの SHA-256 で良い
+func hashPersonalAccessToken(token string) []byte {
+	sum := sha256.Sum256([]byte(token))
+	return sum[:]
+}
+
+// toPersonalAccessTokenView は sqlc の型をレスポンスの型に変換する
+func toPersonalAccessTokenView(token sqlc.CreatePersonalAccessTokenRow) dto.PersonalAccessTokenView {
+	return dto.PersonalAccessTokenView{
+		ID:          token.ID,
+		Name:        token.Name,
+		TokenPrefix: token.TokenPrefix,
+		Scopes:      token.Scopes,
+		ExpiresAt:   pgtypeTimestamptzToPtrString(token.ExpiresAt),
+		LastUsedAt:  pgtypeTimestamptzToPtrString(token.LastUsedAt),
+		CreatedAt:   token.CreatedAt.Format(time.RFC3339),
+	}
+}
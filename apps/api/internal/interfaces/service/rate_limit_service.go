@@ -0,0 +1,80 @@
+package service
+
+import (
+	"context"
+	"fmt"
+	"log/slog"
+	"time"
+
+	"github.com/aiirononeko/bulktrack/apps/api/internal/infrastructure/sqlc"
+	"github.com/aiirononeko/bulktrack/apps/api/internal/jobs"
+	"github.com/aiirononeko/bulktrack/apps/api/internal/ratelimit"
+	"github.com/jackc/pgx/v5/pgxpool"
+)
+
+// レート制限のバケットの削除
+const (
+	rateLimitCleanupInterval = 15 * time.Minute
+	rateLimitBucketIdleTTL   = 24 * time.Hour // 最も長い制限の期間より長くする
+)
+
+// RateLimitService はレート制限のトークンバケットを Postgres に保存する (ratelimit.Store の実装)
+// 複数のインスタンス (Fly Machines) で同じ制限を共有する
+type RateLimitService struct {
+	pool    *pgxpool.Pool
+	queries *sqlc.Queries
+	logger  *slog.Logger
+}
+
+// NewRateLimitService は新しい RateLimitService を作成する
+func NewRateLimitService(pool *pgxpool.Pool, logger *slog.Logger) *RateLimitService {
+	return &RateLimitService{
+		pool:    pool,
+		queries: sqlc.New(pool),
+		logger:  logger,
+	}
+}
+
+// Take は key のバケットからトークンを1つ取り出す
+func (s *RateLimitService) Take(ctx context.Context, key string, limit ratelimit.Limit) (ratelimit.Result, error) {
+	row, err := s.queries.TakeRateLimitToken(ctx, sqlc.TakeRateLimitTokenParams{
+		Key:             key,
+		Capacity:        float64(limit.Requests),
+		RefillPerSecond: limit.RefillPerSecond(),
+	})
+	if err != nil {
+		s.logger.ErrorContext(ctx, "Failed to execute TakeRateLimitToken query", slog.Any("error", err), slog.String("key", key))
+		return ratelimit.Result{}, fmt.Errorf("failed to take rate limit token: %w", err)
+	}
+	return ratelimit.NewResult(limit, row.Tokens, row.Allowed), nil
+}
+
+// RateLimitCleanupArgs は使われていないバケットを削除する定期実行のジョブの引数
+type RateLimitCleanupArgs struct{}
+
+// Kind はジョブの種類を返す
+func (RateLimitCleanupArgs) Kind() string { return "rate_limit.cleanup" }
+
+// RegisterJobs は使われていないバケットを rateLimitCleanupInterval ごとに削除する定期実行のジョブを登録する
+func (s *RateLimitService) RegisterJobs(runner *jobs.Runner) {
+	jobs.Register(runner, s.runCleanupJob, jobs.HandlerOptions{})
+	runner.AddPeriodic(jobs.PeriodicJob{
+		Name:     "rate_limit.cleanup",
+		Schedule: jobs.Every(rateLimitCleanupInterval),
+		Args:     RateLimitCleanupArgs{},
+		Options:  &jobs.InsertOptions{MaxAttempts: 1}, // 失敗した場合は次の実行日時に削除する
+	})
+}
+
+// runCleanupJob は使われていないバケットを削除する
+func (s *RateLimitService) runCleanupJob(ctx context.Context, _ *jobs.Job[RateLimitCleanupArgs]) error {
+	deleted, err := s.queries.DeleteIdleRateLimitBuckets(ctx, time.Now().Add(-rateLimitBucketIdleTTL))
+	if err != nil {
+		s.logger.ErrorContext(ctx, "Failed to delete idle rate limit buckets", slog.Any("error", err))
+		return fmt.Errorf("failed to delete idle rate limit buckets: %w", err)
+	}
+	if deleted > 0 {
+		s.logger.DebugContext(ctx, "Deleted idle rate limit buckets", slog.Int64("deleted", deleted))
+	}
+	return nil
+}
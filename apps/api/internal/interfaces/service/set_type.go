@@ -0,0 +1,43 @@
+package service
+
+import (
+	"unicode/utf8"
+
+	httpError "github.com/aiirononeko/bulktrack/apps/api/internal/http"
+)
+
+// セット種別 (sets.set_type)
+const (
+	SetTypeWarmup  = "warmup"  // ウォームアップ (ボリューム・推定1RMの集計対象外)
+	SetTypeWorking = "working" // メインセット
+	SetTypeDrop    = "drop"    // ドロップセット
+	SetTypeFailure = "failure" // 限界まで行うセット
+	SetTypeAMRAP   = "amrap"   // AMRAP (As Many Reps As Possible)
+)
+
+// maxGroupKeyLength はスーパーセット/サーキットのグループキーの最大文字数
+const maxGroupKeyLength = 20
+
+// normalizeSetType はセット種別を検証し、未指定の場合は working を返す
+func normalizeSetType(setType string) (string, error) {
+	switch setType {
+	case "":
+		return SetTypeWorking, nil
+	case SetTypeWarmup, SetTypeWorking, SetTypeDrop, SetTypeFailure, SetTypeAMRAP:
+		return setType, nil
+	default:
+		return "", httpError.NewValidationError("Invalid set type: "+setType, []httpError.ValidationDetail{
+			{Field: "set_type", Reason: "INVALID_VALUE"},
+		})
+	}
+}
+
+// validateGroupKey はグループキーの長さを検証する (nil は未指定として許可)
+func validateGroupKey(groupKey *string) error {
+	if groupKey != nil && utf8.RuneCountInString(*groupKey) > maxGroupKeyLength {
+		return httpError.NewValidationError("Group key is too long", []httpError.ValidationDetail{
+			{Field: "group_key", Reason: "MAX_LENGTH"},
+		})
+	}
+	return nil
+}
@@ -0,0 +1,55 @@
+package service
+
+import (
+	"reflect"
+	"strings"
+	"testing"
+
+	httpError "github.com/aiirononeko/bulktrack/apps/api/internal/http"
+)
+
+func TestNormalizeSetType(t *testing.T) {
+	tests := []struct {
+		setType string
+		want    string
+		details []httpError.ValidationDetail
+	}{
+		{setType: "", want: SetTypeWorking},
+		{setType: SetTypeWarmup, want: SetTypeWarmup},
+		{setType: SetTypeWorking, want: SetTypeWorking},
+		{setType: SetTypeDrop, want: SetTypeDrop},
+		{setType: SetTypeFailure, want: SetTypeFailure},
+		{setType: SetTypeAMRAP, want: SetTypeAMRAP},
+		{setType: "Warmup", details: []httpError.ValidationDetail{{Field: "set_type", Reason: "INVALID_VALUE"}}},
+		{setType: "superset", details: []httpError.ValidationDetail{{Field: "set_type", Reason: "INVALID_VALUE"}}},
+	}
+	for _, tt := range tests {
+		got, err := normalizeSetType(tt.setType)
+		if details := validationDetails(t, err); got != tt.want || !reflect.DeepEqual(details, tt.details) {
+			t.Errorf("normalizeSetType(%q) = %q, %v, want %q, %v", tt.setType, got, details, tt.want, tt.details)
+		}
+	}
+}
+
+func TestValidateGroupKey(t *testing.T) {
+	ptr := func(s string) *string { return &s }
+	tests := []struct {
+		name     string
+		groupKey *string
+		details  []httpError.ValidationDetail
+	}{
+		{name: "not specified", groupKey: nil},
+		{name: "empty clears the group", groupKey: ptr("")},
+		{name: "superset", groupKey: ptr("A")},
+		// 文字数はバイト数ではなく文字で数える
+		{name: "20 characters", groupKey: ptr(strings.Repeat("胸", maxGroupKeyLength))},
+		{name: "21 characters", groupKey: ptr(strings.Repeat("a", maxGroupKeyLength+1)), details: []httpError.ValidationDetail{{Field: "group_key", Reason: "MAX_LENGTH"}}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if details := validationDetails(t, validateGroupKey(tt.groupKey)); !reflect.DeepEqual(details, tt.details) {
+				t.Errorf("validateGroupKey() details = %v, want %v", details, tt.details)
+			}
+		})
+	}
+}
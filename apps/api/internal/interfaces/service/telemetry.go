@@ -0,0 +1,45 @@
+package service
+
+import (
+	"context"
+
+	"github.com/aiirononeko/bulktrack/apps/api/internal/telemetry"
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/metric"
+	"go.opentelemetry.io/otel/metric/noop"
+	"go.opentelemetry.io/otel/trace"
+)
+
+var (
+	tracer = otel.Tracer(telemetry.InstrumentationName + "/service")
+	meter  = otel.Meter(telemetry.InstrumentationName + "/service")
+)
+
+// startSpan はサービスのメソッドのスパンを開始する (name は "MenuService.UpdateMenu" の形式)
+// エラーは ErrorContext のログとしてスパンに記録される (telemetry.LogHandler)
+func startSpan(ctx context.Context, name string, attrs ...attribute.KeyValue) (context.Context, trace.Span) {
+	return tracer.Start(ctx, name, trace.WithAttributes(attrs...))
+}
+
+// ビジネスメトリクス (Prometheus では bulktrack_workouts_started_total などになる)
+// source 属性は経路 (workout: アプリでの記録 / import: 他のアプリからのインポート / manual: 再計算 API / admin: 管理コマンド / reconcile: 週間ボリュームの照合 / aggregation: 週間ボリュームの集計キュー)
+var (
+	workoutsStarted      = newInt64Counter("bulktrack.workouts.started", "Number of workouts started", "{workout}")
+	setsLogged           = newInt64Counter("bulktrack.sets.logged", "Number of sets logged", "{set}")
+	volumeRecalculations = newInt64Counter("bulktrack.weekly_volume.recalculations", "Number of weekly volume recalculations", "{recalculation}")
+	volumeDrifts         = newInt64Counter("bulktrack.weekly_volume.drifts", "Number of user-weeks whose weekly volume differed from the sets when reconciled", "{week}")
+)
+
+func newInt64Counter(name, description, unit string) metric.Int64Counter {
+	counter, err := meter.Int64Counter(name, metric.WithDescription(description), metric.WithUnit(unit))
+	if err != nil {
+		otel.Handle(err)
+		return noop.Int64Counter{}
+	}
+	return counter
+}
+
+func sourceAttr(source string) metric.AddOption {
+	return metric.WithAttributes(attribute.String("source", source))
+}
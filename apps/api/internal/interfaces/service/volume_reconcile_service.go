@@ -0,0 +1,199 @@
+package service
+
+import (
+	"context"
+	"fmt"
+	"log/slog"
+	"time"
+
+	"github.com/aiirononeko/bulktrack/apps/api/internal/infrastructure/sqlc"
+	"github.com/aiirononeko/bulktrack/apps/api/internal/jobs"
+	"github.com/jackc/pgx/v5/pgtype"
+	"github.com/jackc/pgx/v5/pgxpool"
+	"go.opentelemetry.io/otel/attribute"
+)
+
+// volumeReconcileUserPageSize は全ユーザーの照合で一度に取得するユーザー数
+const volumeReconcileUserPageSize = 500
+
+// volumeReconcileJobTimeout は定期実行の照合のジョブのタイムアウト
+const volumeReconcileJobTimeout = time.Hour
+
+// VolumeReconcileService は週間ボリューム (weekly_volumes) をセットとワークアウトから集計し直した値と照合し、差分を修復する
+// 集計キューの取りこぼし (キューを止めた一括処理の再集計漏れなど) で実際のセットとずれた週を見つける
+type VolumeReconcileService struct {
+	pool       *pgxpool.Pool
+	queries    *sqlc.Queries
+	aggregator *WeeklyVolumeAggregator
+	logger     *slog.Logger
+}
+
+// NewVolumeReconcileService は新しい VolumeReconcileService を作成する
+func NewVolumeReconcileService(pool *pgxpool.Pool, logger *slog.Logger) *VolumeReconcileService {
+	return &VolumeReconcileService{
+		pool:       pool,
+		queries:    sqlc.New(pool),
+		aggregator: NewWeeklyVolumeAggregator(pool, logger),
+		logger:     logger,
+	}
+}
+
+// WeeklyVolumeTotals は週間ボリュームの集計値を表す
+type WeeklyVolumeTotals struct {
+	TotalVolume   float64 `json:"total_volume"`
+	EstOneRM      float64 `json:"est_one_rm"`
+	ExerciseCount int32   `json:"exercise_count"`
+	SetCount      int32   `json:"set_count"`
+}
+
+// WeeklyVolumeDrift は weekly_volumes とセットからの集計が一致しないユーザー週を表す
+type WeeklyVolumeDrift struct {
+	UserID        string             `json:"user_id"`
+	WeekStartDate string             `json:"week_start_date"` // YYYY-MM-DD
+	Stored        WeeklyVolumeTotals `json:"stored"`          // weekly_volumes の値 (行がない場合は 0)
+	Fresh         WeeklyVolumeTotals `json:"fresh"`           // セットとワークアウトから集計し直した値
+}
+
+// VolumeReconcileSummary は全ユーザーの照合結果を表す
+type VolumeReconcileSummary struct {
+	CheckedUsers  int64 `json:"checked_users"`
+	DriftedWeeks  int64 `json:"drifted_weeks"`
+	RepairedWeeks int64 `json:"repaired_weeks"`
+}
+
+// ReconcileUser はユーザーの週間ボリュームを照合し、差分のある週を返す (repair の場合は修復する)
+//
+// 集計キューに残っている週は集計前の差分として報告しないよう、照合の前に集計する
+// 修復は差分のある週を集計キューに入れて集計するため、集計キューの処理と同じ結果になり競合もしない
+func (s *VolumeReconcileService) ReconcileUser(ctx context.Context, userID string, repair bool) ([]WeeklyVolumeDrift, error) {
+	ctx, span := startSpan(ctx, "VolumeReconcileService.ReconcileUser", attribute.String("user_id", userID), attribute.Bool("repair", repair))
+	defer span.End()
+
+	if err := s.aggregator.FlushUser(ctx, userID); err != nil {
+		return nil, fmt.Errorf("failed to aggregate weekly volumes: %w", err)
+	}
+
+	rows, err := s.queries.ListWeeklyVolumeDrift(ctx, userID)
+	if err != nil {
+		s.logger.ErrorContext(ctx, "Failed to execute ListWeeklyVolumeDrift query", slog.Any("error", err), slog.String("user_id", userID))
+		return nil, fmt.Errorf("failed to compare weekly volumes: %w", err)
+	}
+	if len(rows) == 0 {
+		return nil, nil
+	}
+
+	drifts := make([]WeeklyVolumeDrift, 0, len(rows))
+	for _, row := range rows {
+		drifts = append(drifts, WeeklyVolumeDrift{
+			UserID:        userID,
+			WeekStartDate: row.WeekStartDate.Time.Format(dateLayout),
+			Stored: WeeklyVolumeTotals{
+				TotalVolume:   numericToFloat64(ctx, s.logger, row.StoredTotalVolume),
+				EstOneRM:      numericToFloat64(ctx, s.logger, row.StoredEstOneRm),
+				ExerciseCount: row.StoredExerciseCount,
+				SetCount:      row.StoredSetCount,
+			},
+			Fresh: WeeklyVolumeTotals{
+				TotalVolume:   numericToFloat64(ctx, s.logger, row.FreshTotalVolume),
+				EstOneRM:      numericToFloat64(ctx, s.logger, row.FreshEstOneRm),
+				ExerciseCount: row.FreshExerciseCount,
+				SetCount:      row.FreshSetCount,
+			},
+		})
+	}
+	volumeDrifts.Add(ctx, int64(len(drifts)))
+
+	if !repair {
+		return drifts, nil
+	}
+	if err := s.repairWeeks(ctx, userID, rows); err != nil {
+		return drifts, err
+	}
+	s.logger.WarnContext(ctx, "Repaired weekly volume drift", slog.String("user_id", userID), slog.Int("weeks", len(drifts)))
+	return drifts, nil
+}
+
+// repairWeeks は差分のある週を集計キューに入れ、集計キューと同じ処理で集計し直す
+// (セットのなくなった週は 0 の行を残さずに削除し、集計中の週はその集計のコミットを待つ)
+func (s *VolumeReconcileService) repairWeeks(ctx context.Context, userID string, rows []sqlc.ListWeeklyVolumeDriftRow) error {
+	weekStartDates := make([]pgtype.Date, len(rows))
+	for i, row := range rows {
+		weekStartDates[i] = row.WeekStartDate
+	}
+	if err := s.queries.QueueWeeklyVolumes(ctx, sqlc.QueueWeeklyVolumesParams{
+		UserID:         userID,
+		WeekStartDates: weekStartDates,
+	}); err != nil {
+		s.logger.ErrorContext(ctx, "Failed to execute QueueWeeklyVolumes query", slog.Any("error", err), slog.String("user_id", userID), slog.Int("weeks", len(rows)))
+		return fmt.Errorf("failed to queue weekly volumes: %w", err)
+	}
+	if err := s.aggregator.FlushUser(ctx, userID); err != nil {
+		return fmt.Errorf("failed to aggregate weekly volumes: %w", err)
+	}
+	return nil
+}
+
+// ReconcileAll は全ユーザーの週間ボリュームを1ユーザーずつ照合する (repair の場合は修復する)
+// onDrift は差分のあったユーザーごとに呼び出す (nil の場合は呼び出さない)
+func (s *VolumeReconcileService) ReconcileAll(ctx context.Context, repair bool, onDrift func([]WeeklyVolumeDrift)) (VolumeReconcileSummary, error) {
+	ctx, span := startSpan(ctx, "VolumeReconcileService.ReconcileAll", attribute.Bool("repair", repair))
+	defer span.End()
+
+	var summary VolumeReconcileSummary
+	params := sqlc.ListWeeklyVolumeUserIDsParams{PageLimit: volumeReconcileUserPageSize}
+	for {
+		userIDs, err := s.queries.ListWeeklyVolumeUserIDs(ctx, params)
+		if err != nil {
+			s.logger.ErrorContext(ctx, "Failed to execute ListWeeklyVolumeUserIDs query", slog.Any("error", err))
+			return summary, fmt.Errorf("failed to list users: %w", err)
+		}
+		for _, userID := range userIDs {
+			drifts, err := s.ReconcileUser(ctx, userID, repair)
+			if err != nil {
+				return summary, err
+			}
+			summary.CheckedUsers++
+			summary.DriftedWeeks += int64(len(drifts))
+			if repair {
+				summary.RepairedWeeks += int64(len(drifts))
+			}
+			if len(drifts) > 0 && onDrift != nil {
+				onDrift(drifts)
+			}
+		}
+		if len(userIDs) < volumeReconcileUserPageSize {
+			return summary, nil
+		}
+		params.CursorUserID = pgtype.Text{String: userIDs[len(userIDs)-1], Valid: true}
+	}
+}
+
+// VolumeReconcileArgs は全ユーザーの週間ボリュームを照合して修復する定期実行のジョブの引数
+type VolumeReconcileArgs struct{}
+
+// Kind はジョブの種類を返す
+func (VolumeReconcileArgs) Kind() string { return "volume.reconcile" }
+
+// RegisterJobs は interval ごとに全ユーザーの週間ボリュームを照合して修復する定期実行のジョブを登録する
+// 複数のインスタンスで動かしても、実行日時ごとに1つのインスタンスのみが実行する
+func (s *VolumeReconcileService) RegisterJobs(runner *jobs.Runner, interval time.Duration) {
+	jobs.Register(runner, s.runReconcileJob, jobs.HandlerOptions{Timeout: volumeReconcileJobTimeout})
+	runner.AddPeriodic(jobs.PeriodicJob{
+		Name:     "volume.reconcile",
+		Schedule: jobs.Every(interval),
+		Args:     VolumeReconcileArgs{},
+		// 失敗した場合は次の実行日時に照合し直すため再試行しない
+		Options: &jobs.InsertOptions{MaxAttempts: 1},
+	})
+}
+
+// runReconcileJob は全ユーザーの週間ボリュームを照合して修復する
+func (s *VolumeReconcileService) runReconcileJob(ctx context.Context, _ *jobs.Job[VolumeReconcileArgs]) error {
+	summary, err := s.ReconcileAll(ctx, true, nil)
+	if err != nil {
+		s.logger.ErrorContext(ctx, "Failed to reconcile weekly volumes", slog.Any("error", err), slog.Any("summary", summary))
+		return err
+	}
+	s.logger.InfoContext(ctx, "Reconciled weekly volumes", slog.Any("summary", summary))
+	return nil
+}
@@ -6,26 +6,30 @@ import (
 	"log/slog"
 	"time"
 
+	"github.com/aiirononeko/bulktrack/apps/api/internal/cache"
 	"github.com/aiirononeko/bulktrack/apps/api/internal/infrastructure/sqlc"
 	"github.com/aiirononeko/bulktrack/apps/api/internal/interfaces/http/dto"
 	"github.com/jackc/pgx/v5/pgtype"
 	"github.com/jackc/pgx/v5/pgxpool"
 	"github.com/shopspring/decimal"
+	"go.opentelemetry.io/otel/attribute"
 )
 
 // VolumeService は週間トレーニングボリューム関連のサービスを提供する
 type VolumeService struct {
-	pool    *pgxpool.Pool
-	queries *sqlc.Queries
-	logger  *slog.Logger
+	pool       *pgxpool.Pool
+	queries    *sqlc.Queries
+	aggregator *WeeklyVolumeAggregator
+	logger     *slog.Logger
 }
 
 // NewVolumeService は新しいVolumeServiceを作成する
 func NewVolumeService(pool *pgxpool.Pool, logger *slog.Logger) *VolumeService {
 	return &VolumeService{
-		pool:    pool,
-		queries: sqlc.New(pool),
-		logger:  logger,
+		pool:       pool,
+		queries:    sqlc.New(pool),
+		aggregator: NewWeeklyVolumeAggregator(pool, logger),
+		logger:     logger,
 	}
 }
 
@@ -49,10 +53,28 @@ func numericToFloat64(ctx context.Context, logger *slog.Logger, n pgtype.Numeric
 // GetWeeklyVolumes は指定されたユーザーの週間トレーニングボリュームを取得する
 // weeksCount で指定された週数分のデータを返す（デフォルトは12週）
 func (s *VolumeService) GetWeeklyVolumes(ctx context.Context, userID string, weeksCount int32) (*dto.WeeklyVolumeSummaryResponse, error) {
+	ctx, span := startSpan(ctx, "VolumeService.GetWeeklyVolumes", attribute.String("user_id", userID))
+	defer span.End()
+
 	if weeksCount <= 0 {
 		weeksCount = 12 // デフォルトは12週
 	}
 
+	// 記録した直後の変更を反映するため、集計キューに残っている週を先に集計する
+	if err := s.aggregator.FlushUser(ctx, userID); err != nil {
+		return nil, fmt.Errorf("failed to aggregate weekly volumes: %w", err)
+	}
+
+	// 直近の週は日本時間の今週で決まるため、今週の開始日をキーに含める
+	currentWeek := truncateToPeriod(time.Now().In(jst), HistoryIntervalWeek).Format(dateLayout)
+	key := fmt.Sprintf("weeks:%d:%s", weeksCount, currentWeek)
+	return cache.Load(ctx, responseCache, volumeCacheScope(userID), key, weeklyVolumeCacheTTL, func(ctx context.Context) (*dto.WeeklyVolumeSummaryResponse, error) {
+		return s.loadWeeklyVolumes(ctx, userID, weeksCount)
+	})
+}
+
+// loadWeeklyVolumes は直近 weeksCount 週の週間トレーニングボリュームを DB から読み込む
+func (s *VolumeService) loadWeeklyVolumes(ctx context.Context, userID string, weeksCount int32) (*dto.WeeklyVolumeSummaryResponse, error) {
 	// 週間ボリュームデータの取得
 	volumes, err := s.queries.GetWeeklyVolumes(ctx, sqlc.GetWeeklyVolumesParams{
 		UserID:     userID,
@@ -66,6 +88,15 @@ func (s *VolumeService) GetWeeklyVolumes(ctx context.Context, userID string, wee
 		return nil, fmt.Errorf("failed to get weekly volumes: %w", err)
 	}
 
+	// 有酸素・時間計測の種目の週ごとの合計を取得 (total_volume とは別に集計)
+	var cardioTotals map[string]sqlc.ListWeeklyCardioTotalsRow
+	if len(volumes) > 0 {
+		cardioTotals, err = s.listWeeklyCardioTotals(ctx, userID, volumes[0].WeekStartDate, volumes[len(volumes)-1].WeekStartDate)
+		if err != nil {
+			return nil, err
+		}
+	}
+
 	// レスポンスの作成
 	summaries := make([]dto.WeeklySummaryResponse, 0, len(volumes))
 	for _, volume := range volumes {
@@ -85,6 +116,9 @@ func (s *VolumeService) GetWeeklyVolumes(ctx context.Context, userID string, wee
 			ExerciseCount: int(volume.ExerciseCount),
 			SetCount:      int(volume.SetCount),
 		}
+		if volume.WeekStartDate.Valid {
+			s.applyCardioTotals(ctx, &summary, cardioTotals[volume.WeekStartDate.Time.Format(dateLayout)])
+		}
 		summaries = append(summaries, summary)
 	}
 
@@ -95,6 +129,9 @@ func (s *VolumeService) GetWeeklyVolumes(ctx context.Context, userID string, wee
 
 // GetWeeklyVolumeForWeek は指定されたユーザーと週の週間トレーニングボリュームを取得する
 func (s *VolumeService) GetWeeklyVolumeForWeek(ctx context.Context, userID string, weekStartDate time.Time) (*dto.WeeklySummaryResponse, error) {
+	ctx, span := startSpan(ctx, "VolumeService.GetWeeklyVolumeForWeek", attribute.String("user_id", userID), attribute.String("week_start_date", weekStartDate.Format(time.DateOnly)))
+	defer span.End()
+
 	// 週の開始日を計算（月曜日）
 	year, month, day := weekStartDate.Date()
 	weekStart := time.Date(year, month, day, 0, 0, 0, 0, time.UTC)
@@ -107,10 +144,19 @@ func (s *VolumeService) GetWeeklyVolumeForWeek(ctx context.Context, userID strin
 		weekStart = weekStart.AddDate(0, 0, -int(weekday)+1) // 今週の月曜日に移動
 	}
 
-	// time.Time を pgtype.Date に変換
-	var pgDate pgtype.Date
-	pgDate.Valid = true
-	pgDate.Time = weekStart
+	// 記録した直後の変更を反映するため、集計キューに残っている週を先に集計する
+	if err := s.aggregator.FlushUser(ctx, userID); err != nil {
+		return nil, fmt.Errorf("failed to aggregate weekly volumes: %w", err)
+	}
+
+	return cache.Load(ctx, responseCache, volumeCacheScope(userID), "week:"+weekStart.Format(dateLayout), weeklyVolumeCacheTTL, func(ctx context.Context) (*dto.WeeklySummaryResponse, error) {
+		return s.loadWeeklyVolumeForWeek(ctx, userID, weekStart)
+	})
+}
+
+// loadWeeklyVolumeForWeek は weekStart (月曜日) の週の週間トレーニングボリュームを DB から読み込む
+func (s *VolumeService) loadWeeklyVolumeForWeek(ctx context.Context, userID string, weekStart time.Time) (*dto.WeeklySummaryResponse, error) {
+	pgDate := pgtype.Date{Time: weekStart, Valid: true}
 
 	// 週間ボリュームデータの取得
 	volume, err := s.queries.GetWeeklyVolumeForWeek(ctx, sqlc.GetWeeklyVolumeForWeekParams{
@@ -129,18 +175,59 @@ func (s *VolumeService) GetWeeklyVolumeForWeek(ctx context.Context, userID strin
 	totalVolume := numericToFloat64(ctx, s.logger, volume.TotalVolume)
 	estOneRM := numericToFloat64(ctx, s.logger, volume.EstOneRm)
 
+	// 有酸素・時間計測の種目の合計を取得
+	cardioTotals, err := s.listWeeklyCardioTotals(ctx, userID, pgDate, pgDate)
+	if err != nil {
+		return nil, err
+	}
+
 	// レスポンスの作成
-	return &dto.WeeklySummaryResponse{
+	summary := &dto.WeeklySummaryResponse{
 		Week:          weekStart.Format(time.RFC3339),
 		TotalVolume:   totalVolume,
 		EstOneRM:      estOneRM,
 		ExerciseCount: int(volume.ExerciseCount),
 		SetCount:      int(volume.SetCount),
-	}, nil
+	}
+	s.applyCardioTotals(ctx, summary, cardioTotals[weekStart.Format(dateLayout)])
+	return summary, nil
+}
+
+// listWeeklyCardioTotals は期間内の有酸素・時間計測の種目の週ごとの合計を取得する (キーは週の開始日 YYYY-MM-DD)
+func (s *VolumeService) listWeeklyCardioTotals(ctx context.Context, userID string, startDate, endDate pgtype.Date) (map[string]sqlc.ListWeeklyCardioTotalsRow, error) {
+	rows, err := s.queries.ListWeeklyCardioTotals(ctx, sqlc.ListWeeklyCardioTotalsParams{
+		UserID:    userID,
+		StartDate: startDate,
+		EndDate:   endDate,
+	})
+	if err != nil {
+		s.logger.ErrorContext(ctx, "Failed to execute ListWeeklyCardioTotals query",
+			slog.Any("error", err),
+			slog.String("user_id", userID),
+			slog.Time("start_date", startDate.Time),
+			slog.Time("end_date", endDate.Time))
+		return nil, fmt.Errorf("failed to get weekly cardio totals: %w", err)
+	}
+
+	totals := make(map[string]sqlc.ListWeeklyCardioTotalsRow, len(rows))
+	for _, row := range rows {
+		totals[row.WeekStartDate.Time.Format(dateLayout)] = row
+	}
+	return totals, nil
+}
+
+// applyCardioTotals は有酸素・時間計測の種目の合計 (分・km) を週間サマリーに設定する
+func (s *VolumeService) applyCardioTotals(ctx context.Context, summary *dto.WeeklySummaryResponse, totals sqlc.ListWeeklyCardioTotalsRow) {
+	summary.CardioMinutes = float64(totals.TotalDurationSeconds) / 60
+	summary.CardioDistanceKm = numericToFloat64(ctx, s.logger, totals.TotalDistanceM) / 1000
+	summary.CardioSetCount = int(totals.SetCount)
 }
 
 // RecalculateWeeklyVolume は指定されたユーザーと週の週間トレーニングボリュームを再計算する
 func (s *VolumeService) RecalculateWeeklyVolume(ctx context.Context, userID string, weekStartDate time.Time) error {
+	ctx, span := startSpan(ctx, "VolumeService.RecalculateWeeklyVolume", attribute.String("user_id", userID), attribute.String("week_start_date", weekStartDate.Format(time.DateOnly)))
+	defer span.End()
+
 	// 週の開始日を計算（月曜日）
 	year, month, day := weekStartDate.Date()
 	weekStart := time.Date(year, month, day, 0, 0, 0, 0, time.UTC)
@@ -170,12 +257,30 @@ func (s *VolumeService) RecalculateWeeklyVolume(ctx context.Context, userID stri
 			slog.Time("week_start_date", weekStart))
 		return fmt.Errorf("failed to recalculate weekly volume: %w", err)
 	}
+	volumeRecalculations.Add(ctx, 1, sourceAttr("manual"))
+	responseCache.Invalidate(ctx, volumeCacheScope(userID))
 
 	return nil
 }
 
 // GetWeeklyVolumeStats は指定されたユーザーと期間の週間トレーニングボリューム統計を取得する
 func (s *VolumeService) GetWeeklyVolumeStats(ctx context.Context, userID string, startDate, endDate time.Time) (*dto.WeeklyVolumeStatsResponse, error) {
+	ctx, span := startSpan(ctx, "VolumeService.GetWeeklyVolumeStats", attribute.String("user_id", userID))
+	defer span.End()
+
+	// 記録した直後の変更を反映するため、集計キューに残っている週を先に集計する
+	if err := s.aggregator.FlushUser(ctx, userID); err != nil {
+		return nil, fmt.Errorf("failed to aggregate weekly volumes: %w", err)
+	}
+
+	key := "stats:" + startDate.Format(dateLayout) + ":" + endDate.Format(dateLayout)
+	return cache.Load(ctx, responseCache, volumeCacheScope(userID), key, weeklyVolumeCacheTTL, func(ctx context.Context) (*dto.WeeklyVolumeStatsResponse, error) {
+		return s.loadWeeklyVolumeStats(ctx, userID, startDate, endDate)
+	})
+}
+
+// loadWeeklyVolumeStats は期間の週間トレーニングボリューム統計を DB から読み込む
+func (s *VolumeService) loadWeeklyVolumeStats(ctx context.Context, userID string, startDate, endDate time.Time) (*dto.WeeklyVolumeStatsResponse, error) {
 	// time.Time を pgtype.Date に変換
 	var pgStartDate, pgEndDate pgtype.Date
 	pgStartDate.Valid = true
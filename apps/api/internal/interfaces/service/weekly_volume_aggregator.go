@@ -0,0 +1,261 @@
+package service
+
+import (
+	"context"
+	"fmt"
+	"log/slog"
+	"time"
+
+	"github.com/aiirononeko/bulktrack/apps/api/internal/infrastructure/sqlc"
+	"github.com/aiirononeko/bulktrack/apps/api/internal/jobs"
+	"github.com/aiirononeko/bulktrack/apps/api/internal/volume"
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5/pgtype"
+	"github.com/jackc/pgx/v5/pgxpool"
+	"go.opentelemetry.io/otel/attribute"
+)
+
+const (
+	// 週間ボリュームを読む API はその前にユーザーのキューを集計するため、定期実行の間隔は短くなくてよい
+	// (定期実行のジョブを確認する間隔 (10 秒) より短くしても早くはならない)
+	weeklyVolumeAggregationInterval   = 10 * time.Second
+	weeklyVolumeAggregationDelay      = 2 * time.Second // 続けて記録されるセットをまとめて集計するため、キューに入ってから待つ時間
+	weeklyVolumeAggregationBatchSize  = 1000
+	weeklyVolumeAggregationJobTimeout = 5 * time.Minute
+)
+
+// WeeklyVolumeAggregator は集計キュー (weekly_volume_dirty_weeks) のユーザー週の週間ボリュームを集計して weekly_volumes に保存する
+//
+// セット・ワークアウト・体重の変更は DB のトリガーが同じトランザクションで週をキューに入れ (何度変更しても1件)、
+// 定期実行のジョブ (RegisterJobs) がまとめて集計する。キューから取り出した行は集計をコミットするまでロックしているため、
+// その間にコミットされた変更は集計の後に週をキューに入れ直し、次の集計に含まれる
+// 週間ボリュームのキャッシュは集計のコミットの後に無効にする (セット・ワークアウトの変更による無効化はこの集計を経由する)
+type WeeklyVolumeAggregator struct {
+	pool    *pgxpool.Pool
+	queries *sqlc.Queries
+	logger  *slog.Logger
+}
+
+// NewWeeklyVolumeAggregator は新しい WeeklyVolumeAggregator を作成する
+func NewWeeklyVolumeAggregator(pool *pgxpool.Pool, logger *slog.Logger) *WeeklyVolumeAggregator {
+	return &WeeklyVolumeAggregator{
+		pool:    pool,
+		queries: sqlc.New(pool),
+		logger:  logger,
+	}
+}
+
+// WeeklyVolumeAggregateArgs は集計キューの週を集計する定期実行のジョブの引数
+type WeeklyVolumeAggregateArgs struct{}
+
+// Kind はジョブの種類を返す
+func (WeeklyVolumeAggregateArgs) Kind() string { return "weekly_volume.aggregate" }
+
+// RegisterJobs は集計キューの週を weeklyVolumeAggregationInterval ごとに集計する定期実行のジョブを登録する
+// 複数のインスタンスで動かしても、同じ週を重複して集計しない (ロック中の行は他のインスタンスが読み飛ばす)
+func (a *WeeklyVolumeAggregator) RegisterJobs(runner *jobs.Runner) {
+	jobs.Register(runner, a.runAggregateJob, jobs.HandlerOptions{Timeout: weeklyVolumeAggregationJobTimeout})
+	runner.AddPeriodic(jobs.PeriodicJob{
+		Name:     "weekly_volume.aggregate",
+		Schedule: jobs.Every(weeklyVolumeAggregationInterval),
+		Args:     WeeklyVolumeAggregateArgs{},
+		// 失敗した週はキューに残り、次の実行日時に集計し直すため再試行しない
+		Options: &jobs.InsertOptions{MaxAttempts: 1},
+	})
+}
+
+// runAggregateJob はキューが空になるまで集計キューの週をバッチで集計する
+func (a *WeeklyVolumeAggregator) runAggregateJob(ctx context.Context, _ *jobs.Job[WeeklyVolumeAggregateArgs]) error {
+	for {
+		n, err := a.aggregateBatch(ctx, time.Now().Add(-weeklyVolumeAggregationDelay))
+		if err != nil {
+			if ctx.Err() == nil {
+				a.logger.ErrorContext(ctx, "Failed to aggregate weekly volumes", slog.Any("error", err))
+			}
+			return err
+		}
+		if n < weeklyVolumeAggregationBatchSize {
+			return nil
+		}
+	}
+}
+
+// FlushUser はユーザーの集計キューの週をすぐに集計する (週間ボリュームを読む前に呼び出し、記録した直後の変更を反映する)
+// 他のインスタンスが集計中の週はその集計のコミットを待つ
+func (a *WeeklyVolumeAggregator) FlushUser(ctx context.Context, userID string) (err error) {
+	ctx, span := startSpan(ctx, "WeeklyVolumeAggregator.FlushUser", attribute.String("user_id", userID))
+	defer span.End()
+
+	tx, err := a.pool.Begin(ctx)
+	if err != nil {
+		a.logger.ErrorContext(ctx, "Failed to begin transaction for FlushUser", slog.Any("error", err), slog.String("user_id", userID))
+		return err
+	}
+	defer func() {
+		if err != nil {
+			if rollErr := tx.Rollback(ctx); rollErr != nil {
+				a.logger.ErrorContext(ctx, "Failed to rollback transaction for FlushUser", slog.Any("rollback_error", rollErr), slog.Any("original_error", err), slog.String("user_id", userID))
+			}
+		}
+	}()
+
+	qtx := sqlc.New(tx)
+	rows, err := qtx.ClaimUserDirtyWeeklyVolumes(ctx, userID)
+	if err != nil {
+		a.logger.ErrorContext(ctx, "Failed to execute ClaimUserDirtyWeeklyVolumes query", slog.Any("error", err), slog.String("user_id", userID))
+		return fmt.Errorf("failed to claim queued weekly volumes: %w", err)
+	}
+	weeks := make([]volume.Week, len(rows))
+	for i, row := range rows {
+		weeks[i] = volume.Week{UserID: row.UserID, StartDate: row.WeekStartDate.Time}
+	}
+	if err = a.aggregate(ctx, qtx, weeks); err != nil {
+		return err
+	}
+	if err = tx.Commit(ctx); err != nil {
+		a.logger.ErrorContext(ctx, "Failed to commit transaction for FlushUser", slog.Any("error", err), slog.String("user_id", userID))
+		return fmt.Errorf("failed to commit weekly volume aggregation: %w", err)
+	}
+	invalidateWeeklyVolumes(ctx, weeks)
+	return nil
+}
+
+// aggregateBatch は markedBefore より前にキューに入った週を最大 weeklyVolumeAggregationBatchSize 件集計し、集計した週の数を返す
+func (a *WeeklyVolumeAggregator) aggregateBatch(ctx context.Context, markedBefore time.Time) (n int, err error) {
+	tx, err := a.pool.Begin(ctx)
+	if err != nil {
+		return 0, fmt.Errorf("failed to begin transaction: %w", err)
+	}
+	defer func() {
+		if err != nil {
+			if rollErr := tx.Rollback(ctx); rollErr != nil {
+				a.logger.ErrorContext(ctx, "Failed to rollback transaction for AggregateWeeklyVolumes", slog.Any("rollback_error", rollErr), slog.Any("original_error", err))
+			}
+		}
+	}()
+
+	qtx := sqlc.New(tx)
+	rows, err := qtx.ClaimDirtyWeeklyVolumes(ctx, sqlc.ClaimDirtyWeeklyVolumesParams{
+		MarkedBefore: markedBefore,
+		BatchSize:    weeklyVolumeAggregationBatchSize,
+	})
+	if err != nil {
+		return 0, fmt.Errorf("failed to claim queued weekly volumes: %w", err)
+	}
+	weeks := make([]volume.Week, len(rows))
+	for i, row := range rows {
+		weeks[i] = volume.Week{UserID: row.UserID, StartDate: row.WeekStartDate.Time}
+	}
+	if err = a.aggregate(ctx, qtx, weeks); err != nil {
+		return 0, err
+	}
+	if err = tx.Commit(ctx); err != nil {
+		return 0, fmt.Errorf("failed to commit weekly volume aggregation: %w", err)
+	}
+	invalidateWeeklyVolumes(ctx, weeks)
+	return len(weeks), nil
+}
+
+// aggregate はユーザー週のセットと体重を読み込んで集計し、weekly_volumes に保存する (セットがなくなった週の行は削除する)
+func (a *WeeklyVolumeAggregator) aggregate(ctx context.Context, qtx *sqlc.Queries, weeks []volume.Week) error {
+	if len(weeks) == 0 {
+		return nil
+	}
+	ctx, span := startSpan(ctx, "WeeklyVolumeAggregator.aggregate", attribute.Int("weeks", len(weeks)))
+	defer span.End()
+
+	userIDs := make([]string, len(weeks))
+	weekStartDates := make([]pgtype.Date, len(weeks))
+	var users []string
+	seenUsers := make(map[string]bool)
+	for i, week := range weeks {
+		userIDs[i] = week.UserID
+		weekStartDates[i] = pgtype.Date{Time: week.StartDate, Valid: true}
+		if !seenUsers[week.UserID] {
+			seenUsers[week.UserID] = true
+			users = append(users, week.UserID)
+		}
+	}
+
+	setRows, err := qtx.ListWeeklyVolumeSets(ctx, sqlc.ListWeeklyVolumeSetsParams{
+		UserIds:        userIDs,
+		WeekStartDates: weekStartDates,
+	})
+	if err != nil {
+		a.logger.ErrorContext(ctx, "Failed to execute ListWeeklyVolumeSets query", slog.Any("error", err), slog.Int("weeks", len(weeks)))
+		return fmt.Errorf("failed to list sets for weekly volumes: %w", err)
+	}
+	bodyWeightRows, err := qtx.ListWeeklyVolumeBodyWeights(ctx, users)
+	if err != nil {
+		a.logger.ErrorContext(ctx, "Failed to execute ListWeeklyVolumeBodyWeights query", slog.Any("error", err), slog.Int("users", len(users)))
+		return fmt.Errorf("failed to list body weights for weekly volumes: %w", err)
+	}
+
+	sets := make([]volume.Set, len(setRows))
+	for i, row := range setRows {
+		sets[i] = volume.Set{
+			Week:        volume.Week{UserID: row.UserID, StartDate: row.WeekStartDate.Time},
+			WorkoutDate: row.WorkoutDate.Time,
+			ExerciseID:  uuid.NullUUID{UUID: row.ExerciseID.Bytes, Valid: row.ExerciseID.Valid},
+			LoadType:    row.LoadType,
+			WeightCenti: row.WeightCenti,
+			Reps:        row.Reps,
+		}
+	}
+	bodyWeights := make(map[string][]volume.BodyWeight)
+	for _, row := range bodyWeightRows {
+		bodyWeights[row.UserID] = append(bodyWeights[row.UserID], volume.BodyWeight{
+			MeasuredDate: row.MeasuredDate.Time,
+			ValueCenti:   row.ValueCenti,
+		})
+	}
+	totals := volume.Aggregate(sets, bodyWeights)
+
+	upsert := sqlc.UpsertWeeklyVolumesParams{
+		UserIds:           make([]string, len(totals)),
+		WeekStartDates:    make([]pgtype.Date, len(totals)),
+		TotalVolumeCentis: make([]int64, len(totals)),
+		EstOneRmCentis:    make([]int64, len(totals)),
+		ExerciseCounts:    make([]int32, len(totals)),
+		SetCounts:         make([]int32, len(totals)),
+	}
+	aggregated := make(map[string]bool, len(totals))
+	for i, t := range totals {
+		upsert.UserIds[i] = t.UserID
+		upsert.WeekStartDates[i] = pgtype.Date{Time: t.StartDate, Valid: true}
+		upsert.TotalVolumeCentis[i] = t.TotalVolumeCenti
+		upsert.EstOneRmCentis[i] = t.EstOneRMCenti
+		upsert.ExerciseCounts[i] = t.ExerciseCount
+		upsert.SetCounts[i] = t.SetCount
+		aggregated[weekID(t.Week)] = true
+	}
+	if len(totals) > 0 {
+		if err := qtx.UpsertWeeklyVolumes(ctx, upsert); err != nil {
+			a.logger.ErrorContext(ctx, "Failed to execute UpsertWeeklyVolumes query", slog.Any("error", err), slog.Int("weeks", len(totals)))
+			return fmt.Errorf("failed to save weekly volumes: %w", err)
+		}
+	}
+
+	// セットがすべて削除された (またはワークアウトが他の週に移動した) 週の行を削除する
+	var empty sqlc.DeleteWeeklyVolumesParams
+	for _, week := range weeks {
+		if !aggregated[weekID(week)] {
+			empty.UserIds = append(empty.UserIds, week.UserID)
+			empty.WeekStartDates = append(empty.WeekStartDates, pgtype.Date{Time: week.StartDate, Valid: true})
+		}
+	}
+	if len(empty.UserIds) > 0 {
+		if err := qtx.DeleteWeeklyVolumes(ctx, empty); err != nil {
+			a.logger.ErrorContext(ctx, "Failed to execute DeleteWeeklyVolumes query", slog.Any("error", err), slog.Int("weeks", len(empty.UserIds)))
+			return fmt.Errorf("failed to delete empty weekly volumes: %w", err)
+		}
+	}
+
+	volumeRecalculations.Add(ctx, int64(len(weeks)), sourceAttr("aggregation"))
+	return nil
+}
+
+// weekID はユーザー週を識別する文字列を返す
+func weekID(week volume.Week) string {
+	return week.UserID + "/" + week.StartDate.Format(dateLayout)
+}
@@ -0,0 +1,64 @@
+package service
+
+import (
+	"encoding/base64"
+	"strings"
+	"time"
+
+	httpError "github.com/aiirononeko/bulktrack/apps/api/internal/http"
+	"github.com/google/uuid"
+)
+
+// ワークアウト一覧の並び順 (started_at 基準)
+const (
+	WorkoutOrderDesc = "desc" // 新しい順 (デフォルト)
+	WorkoutOrderAsc  = "asc"  // 古い順
+)
+
+// DefaultWorkoutPageLimit はワークアウト一覧の1ページあたりのデフォルト件数
+const DefaultWorkoutPageLimit = 20
+
+// ListWorkoutsOptions はワークアウト一覧のページング・絞り込み条件を表す
+type ListWorkoutsOptions struct {
+	Limit      int        // 1ページあたりの件数 (validation.ValidatePagination で検証済みであること)
+	Cursor     string     // 前ページのレスポンスの next_cursor
+	From       string     // 開始日 (YYYY-MM-DD, JST)
+	To         string     // 終了日 (YYYY-MM-DD, JST, 当日を含む)
+	MenuID     *uuid.UUID // メニューで絞り込む
+	ExerciseID *uuid.UUID // 種目を含むワークアウトに絞り込む
+	Order      string     // desc / asc
+}
+
+// workoutCursorSeparator はカーソル内の started_at と id の区切り文字
+const workoutCursorSeparator = "|"
+
+// encodeWorkoutCursor はページ末尾のワークアウトの started_at と id をカーソル (Base64URL) に変換する
+func encodeWorkoutCursor(startedAt time.Time, id uuid.UUID) string {
+	raw := startedAt.UTC().Format(time.RFC3339Nano) + workoutCursorSeparator + id.String()
+	return base64.RawURLEncoding.EncodeToString([]byte(raw))
+}
+
+// decodeWorkoutCursor はカーソルを started_at と id に変換する
+func decodeWorkoutCursor(cursor string) (time.Time, uuid.UUID, error) {
+	invalid := httpError.NewValidationError("Invalid cursor", []httpError.ValidationDetail{
+		{Field: "cursor", Reason: "INVALID_FORMAT"},
+	})
+
+	raw, err := base64.RawURLEncoding.DecodeString(cursor)
+	if err != nil {
+		return time.Time{}, uuid.Nil, invalid
+	}
+	startedAtStr, idStr, ok := strings.Cut(string(raw), workoutCursorSeparator)
+	if !ok {
+		return time.Time{}, uuid.Nil, invalid
+	}
+	startedAt, err := time.Parse(time.RFC3339Nano, startedAtStr)
+	if err != nil {
+		return time.Time{}, uuid.Nil, invalid
+	}
+	id, err := uuid.Parse(idStr)
+	if err != nil {
+		return time.Time{}, uuid.Nil, invalid
+	}
+	return startedAt, id, nil
+}
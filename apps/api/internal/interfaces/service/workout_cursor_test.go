@@ -0,0 +1,58 @@
+package service
+
+import (
+	"encoding/base64"
+	"errors"
+	"testing"
+	"time"
+
+	httpError "github.com/aiirononeko/bulktrack/apps/api/internal/http"
+	"github.com/google/uuid"
+)
+
+func TestWorkoutCursorRoundTrip(t *testing.T) {
+	id := uuid.MustParse("0196a3c2-7b1e-7c3a-9f51-2d4e8b6a1c90")
+	tests := []struct {
+		name      string
+		startedAt time.Time
+	}{
+		{name: "JST is encoded in UTC", startedAt: time.Date(2025, 5, 5, 7, 30, 0, 0, time.FixedZone("JST", 9*60*60))},
+		// timestamptz のマイクロ秒まで保持しないと同じ秒のワークアウトを読み飛ばす
+		{name: "microseconds", startedAt: time.Date(2025, 5, 5, 7, 30, 0, 123456000, time.UTC)},
+		{name: "zero time", startedAt: time.Time{}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			cursor := encodeWorkoutCursor(tt.startedAt, id)
+			startedAt, gotID, err := decodeWorkoutCursor(cursor)
+			if err != nil {
+				t.Fatalf("decodeWorkoutCursor(%q) error = %v", cursor, err)
+			}
+			if !startedAt.Equal(tt.startedAt) || gotID != id {
+				t.Errorf("decodeWorkoutCursor() = %v, %s, want %v, %s", startedAt, gotID, tt.startedAt, id)
+			}
+		})
+	}
+}
+
+func TestDecodeWorkoutCursorRejectsInvalidCursor(t *testing.T) {
+	encode := func(raw string) string { return base64.RawURLEncoding.EncodeToString([]byte(raw)) }
+	tests := []struct {
+		name   string
+		cursor string
+	}{
+		{name: "not base64url", cursor: "!!!"},
+		{name: "no separator", cursor: encode("2025-05-05T00:00:00Z")},
+		{name: "invalid time", cursor: encode("2025-05-05|0196a3c2-7b1e-7c3a-9f51-2d4e8b6a1c90")},
+		{name: "invalid id", cursor: encode("2025-05-05T00:00:00Z|42")},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, _, err := decodeWorkoutCursor(tt.cursor)
+			var appErr *httpError.AppError
+			if !errors.As(err, &appErr) || len(appErr.Details) != 1 || appErr.Details[0] != (httpError.ValidationDetail{Field: "cursor", Reason: "INVALID_FORMAT"}) {
+				t.Errorf("decodeWorkoutCursor(%q) error = %v, want an INVALID_FORMAT error of cursor", tt.cursor, err)
+			}
+		})
+	}
+}
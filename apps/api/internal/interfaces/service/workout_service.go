@@ -2,16 +2,21 @@ package service
 
 import (
 	"context"
+	"errors"
 	"fmt"
 	"log/slog"
 	"strconv"
 	"time"
 
+	httpError "github.com/aiirononeko/bulktrack/apps/api/internal/http"
 	"github.com/aiirononeko/bulktrack/apps/api/internal/infrastructure/sqlc"
 	"github.com/aiirononeko/bulktrack/apps/api/internal/interfaces/http/dto"
+	"github.com/aiirononeko/bulktrack/apps/api/internal/volume"
 	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5"
 	"github.com/jackc/pgx/v5/pgtype"
 	"github.com/jackc/pgx/v5/pgxpool"
+	"go.opentelemetry.io/otel/attribute"
 )
 
 // WorkoutService はワークアウト関連のサービスを提供する
@@ -30,62 +35,115 @@ func NewWorkoutService(pool *pgxpool.Pool, logger *slog.Logger) *WorkoutService
 	}
 }
 
-// ListWorkoutsByUser はユーザーのワークアウト一覧を取得する
-func (s *WorkoutService) ListWorkoutsByUser(ctx context.Context, userID string) ([]dto.WorkoutSummary, error) {
-	// ワークアウト一覧の取得
-	workouts, err := s.queries.ListWorkoutsByUser(ctx, userID)
+// ListWorkouts はユーザーのワークアウト一覧をカーソルページングで取得する
+// メニュー名は JOIN で取得する (メニューが削除済みの場合は "不明なメニュー")
+func (s *WorkoutService) ListWorkouts(ctx context.Context, userID string, opts ListWorkoutsOptions) (*dto.WorkoutListResponse, error) {
+	ctx, span := startSpan(ctx, "WorkoutService.ListWorkouts", attribute.String("user_id", userID))
+	defer span.End()
+
+	params := sqlc.ListWorkoutsPageDescParams{
+		UserID:    userID,
+		PageLimit: int32(opts.Limit + 1), // 次ページの有無を判定するため1件多く取得する
+	}
+
+	if opts.From != "" {
+		from, err := parseDate("from", opts.From)
+		if err != nil {
+			return nil, err
+		}
+		params.FromTime = pgtype.Timestamptz{Time: from, Valid: true}
+	}
+	if opts.To != "" {
+		to, err := parseDate("to", opts.To)
+		if err != nil {
+			return nil, err
+		}
+		params.ToTime = pgtype.Timestamptz{Time: to.AddDate(0, 0, 1), Valid: true} // 終了日を含む
+	}
+	if params.FromTime.Valid && params.ToTime.Valid && !params.FromTime.Time.Before(params.ToTime.Time) {
+		return nil, httpError.NewValidationError("from must be on or before to", []httpError.ValidationDetail{
+			{Field: "from", Reason: "RANGE"},
+		})
+	}
+	if opts.MenuID != nil {
+		params.MenuID = pgtype.UUID{Bytes: *opts.MenuID, Valid: true}
+	}
+	if opts.ExerciseID != nil {
+		params.ExerciseID = pgtype.UUID{Bytes: *opts.ExerciseID, Valid: true}
+	}
+	if opts.Cursor != "" {
+		cursorStartedAt, cursorID, err := decodeWorkoutCursor(opts.Cursor)
+		if err != nil {
+			return nil, err
+		}
+		params.CursorStartedAt = pgtype.Timestamptz{Time: cursorStartedAt, Valid: true}
+		params.CursorID = pgtype.UUID{Bytes: cursorID, Valid: true}
+	}
+
+	var rows []sqlc.ListWorkoutsPageDescRow
+	var err error
+	switch opts.Order {
+	case WorkoutOrderDesc, "":
+		rows, err = s.queries.ListWorkoutsPageDesc(ctx, params)
+	case WorkoutOrderAsc:
+		var ascRows []sqlc.ListWorkoutsPageAscRow
+		ascRows, err = s.queries.ListWorkoutsPageAsc(ctx, sqlc.ListWorkoutsPageAscParams(params))
+		for _, row := range ascRows {
+			rows = append(rows, sqlc.ListWorkoutsPageDescRow(row))
+		}
+	default:
+		return nil, httpError.NewValidationError("Invalid order: "+opts.Order, []httpError.ValidationDetail{
+			{Field: "order", Reason: "INVALID_VALUE"},
+		})
+	}
 	if err != nil {
-		s.logger.ErrorContext(ctx, "Failed to execute ListWorkoutsByUser query", slog.Any("error", err), slog.String("user_id", userID))
-		return nil, err
+		s.logger.ErrorContext(ctx, "Failed to execute ListWorkoutsPage query", slog.Any("error", err), slog.String("user_id", userID), slog.String("order", opts.Order))
+		return nil, fmt.Errorf("failed to list workouts: %w", err)
 	}
 
-	// メニュー情報をキャッシュするためのマップ
-	menuCache := make(map[uuid.UUID]string)
+	// 次ページの有無を判定
+	var nextCursor *string
+	if len(rows) > opts.Limit {
+		rows = rows[:opts.Limit]
+		last := rows[len(rows)-1]
+		cursor := encodeWorkoutCursor(last.StartedAt.Time, last.ID)
+		nextCursor = &cursor
+	}
 
 	// ワークアウト一覧をDTOに変換
-	summaries := make([]dto.WorkoutSummary, 0, len(workouts))
-	for i, workout := range workouts {
-		var menuName string
-		menuID := workout.MenuID.Bytes
-
-		// メニュー名をキャッシュから取得するか、DBから取得してキャッシュする
-		if name, ok := menuCache[menuID]; ok {
-			menuName = name
-		} else {
-			menu, err := s.queries.GetMenu(ctx, menuID)
-			if err != nil {
-				// メニューが見つからない場合はログに残す (Warnレベル)
-				s.logger.WarnContext(ctx, "Failed to get menu for workout summary", slog.Any("error", err), slog.String("menu_id", uuid.UUID(menuID).String()), slog.String("workout_id", workout.ID.String()), slog.Int("workout_index", i))
-				menuName = "不明なメニュー"
-			} else {
-				menuName = menu.Name
-				menuCache[menuID] = menuName
-			}
+	summaries := make([]dto.WorkoutSummary, 0, len(rows))
+	for _, row := range rows {
+		menuName := row.MenuName
+		if !row.MenuID.Valid || menuName == "" {
+			menuName = "不明なメニュー"
 		}
 
 		// ノートの変換
 		var noteStr string
-		if workout.Note.Valid {
-			noteStr = workout.Note.String
+		if row.Note.Valid {
+			noteStr = row.Note.String
 		}
 
-		// サマリの作成
-		summary := dto.WorkoutSummary{
-			ID:        workout.ID,
-			MenuID:    menuID,
+		summaries = append(summaries, dto.WorkoutSummary{
+			ID:        row.ID,
+			MenuID:    row.MenuID.Bytes,
 			MenuName:  menuName,
-			StartedAt: workout.StartedAt.Time.Format(time.RFC3339),
+			StartedAt: row.StartedAt.Time.Format(time.RFC3339),
 			Note:      noteStr,
-		}
-
-		summaries = append(summaries, summary)
+		})
 	}
 
-	return summaries, nil
+	return &dto.WorkoutListResponse{
+		Data:       summaries,
+		NextCursor: nextCursor,
+	}, nil
 }
 
 // StartWorkout は新しいワークアウトを開始する
 func (s *WorkoutService) StartWorkout(ctx context.Context, req dto.CreateWorkoutRequest, userID string) (resp *dto.WorkoutResponse, err error) {
+	ctx, span := startSpan(ctx, "WorkoutService.StartWorkout", attribute.String("user_id", userID))
+	defer span.End()
+
 	// デバッグログ: リクエスト情報
 	s.logger.InfoContext(ctx, "StartWorkout requested",
 		slog.String("user_id", userID),
@@ -93,6 +151,20 @@ func (s *WorkoutService) StartWorkout(ctx context.Context, req dto.CreateWorkout
 		slog.Int("exercises_count", len(req.Exercises)),
 		slog.Int("note_length", len(req.Note)))
 
+	// セット種別・グループキーの検証 (トランザクション開始前に行う)
+	setTypes := make([][]string, len(req.Exercises))
+	for exerciseIndex, exercise := range req.Exercises {
+		if err = validateGroupKey(exercise.GroupKey); err != nil {
+			return nil, err
+		}
+		setTypes[exerciseIndex] = make([]string, len(exercise.Sets))
+		for setIndex, set := range exercise.Sets {
+			if setTypes[exerciseIndex][setIndex], err = normalizeSetType(set.SetType); err != nil {
+				return nil, err
+			}
+		}
+	}
+
 	// トランザクション開始
 	tx, err := s.pool.Begin(ctx)
 	if err != nil {
@@ -170,8 +242,10 @@ func (s *WorkoutService) StartWorkout(ctx context.Context, req dto.CreateWorkout
 			slog.Int("exercise_count", len(req.Exercises)),
 			slog.String("workout_id", workout.ID.String()))
 
-		// エクササイズIDと名前のマップを作成(N+1問題を防ぐ)
+		// エクササイズIDと名前・記録指標・負荷の種類のマップを作成(N+1問題を防ぐ)
 		exerciseMap := make(map[string]string)
+		metricTypeMap := make(map[string]string)
+		loadTypeMap := make(map[string]string)
 
 		for exerciseIndex, exercise := range req.Exercises {
 			// エクササイズIDをUUIDに変換
@@ -205,6 +279,8 @@ func (s *WorkoutService) StartWorkout(ctx context.Context, req dto.CreateWorkout
 				} else {
 					exerciseName = exerciseObj.Name
 					exerciseMap[exercise.ExerciseID] = exerciseName
+					metricTypeMap[exercise.ExerciseID] = exerciseObj.MetricType
+					loadTypeMap[exercise.ExerciseID] = exerciseObj.LoadType
 					s.logger.InfoContext(ctx, "Exercise name retrieved",
 						slog.String("exercise_id", exercise.ExerciseID),
 						slog.String("exercise_name", exerciseName),
@@ -212,27 +288,47 @@ func (s *WorkoutService) StartWorkout(ctx context.Context, req dto.CreateWorkout
 				}
 			}
 
+			// 記録指標 (種目が取得できなかった場合は weight_reps として扱う)
+			metricType, ok := metricTypeMap[exercise.ExerciseID]
+			if !ok {
+				metricType = MetricTypeWeightReps
+			}
+
 			// 各セットを作成
 			pgExerciseID := pgtype.UUID{Bytes: exerciseID, Valid: true}
+			pgGroupKey := ptrStringToPgtypeText(exercise.GroupKey)
 
 			for setIndex, set := range exercise.Sets {
 				// デバッグログ: セット情報
 				s.logger.InfoContext(ctx, "Processing set",
 					slog.Int("exercise_index", exerciseIndex),
 					slog.Int("set_index", setIndex),
-					slog.Float64("weight_kg", set.WeightKg),
+					slog.Any("weight_kg", set.WeightKg),
 					slog.Int("reps", int(set.Reps)),
 					slog.Any("rir", set.RIR),
 					slog.Any("rpe", set.RPE),
+					slog.Any("duration_seconds", set.DurationSeconds),
+					slog.Any("distance_m", set.DistanceM),
 					slog.String("workout_id", workout.ID.String()))
 
-				// 重量をNumericに変換
-				var weightKg pgtype.Numeric
-				weightKgStr := strconv.FormatFloat(set.WeightKg, 'f', 2, 64)
-				if err := weightKg.Scan(weightKgStr); err != nil {
+				// 記録指標に応じたセットの値の検証
+				if err := validateSetMetrics(metricType, setMetrics{
+					LoadType:        loadTypeMap[exercise.ExerciseID],
+					WeightKg:        set.WeightKg,
+					Reps:            set.Reps,
+					DurationSeconds: set.DurationSeconds,
+					DistanceM:       set.DistanceM,
+					AvgHeartRate:    set.AvgHeartRate,
+				}); err != nil {
+					return nil, err
+				}
+
+				// 重量をNumericに変換 (自重系の種目で未指定の場合は NULL)
+				weightKg, err := ptrFloat64ToPgtypeNumeric(set.WeightKg)
+				if err != nil {
 					s.logger.WarnContext(ctx, "Weight conversion error",
 						slog.Any("error", err),
-						slog.Float64("weight", set.WeightKg),
+						slog.Any("weight", set.WeightKg),
 						slog.String("workout_id", workout.ID.String()),
 						slog.Int("exercise_index", exerciseIndex),
 						slog.Int("set_index", setIndex))
@@ -265,6 +361,18 @@ func (s *WorkoutService) StartWorkout(ctx context.Context, req dto.CreateWorkout
 					}
 				}
 
+				// 距離をNumericに変換
+				distanceM, errConv := ptrFloat64ToPgtypeNumeric(set.DistanceM)
+				if errConv != nil {
+					s.logger.WarnContext(ctx, "Distance conversion error",
+						slog.Any("error", errConv),
+						slog.Any("distance_m", set.DistanceM),
+						slog.String("workout_id", workout.ID.String()),
+						slog.Int("exercise_index", exerciseIndex),
+						slog.Int("set_index", setIndex))
+					continue
+				}
+
 				// セットの順番 (Use global counter)
 				// setOrder := int32(setIndex + 1) // Remove local calculation
 
@@ -285,6 +393,12 @@ func (s *WorkoutService) StartWorkout(ctx context.Context, req dto.CreateWorkout
 					Reps:       set.Reps,
 					Rir:        rir,
 					Rpe:        rpe,
+					SetType:    setTypes[exerciseIndex][setIndex],
+					GroupKey:   pgGroupKey,
+					// 有酸素・時間計測の種目のみ
+					DurationSeconds: ptrInt32ToPgtypeInt4(set.DurationSeconds),
+					DistanceM:       distanceM,
+					AvgHeartRate:    ptrInt32ToPgtypeInt4(set.AvgHeartRate),
 				})
 				if err != nil {
 					s.logger.ErrorContext(ctx, "Failed to create set",
@@ -300,10 +414,16 @@ func (s *WorkoutService) StartWorkout(ctx context.Context, req dto.CreateWorkout
 					ID:       createdSet.ID,
 					Exercise: exerciseName,
 					SetOrder: globalSetOrder,
-					WeightKg: set.WeightKg, // 直接代入
-					Reps:     set.Reps,     // 直接代入
-					RIR:      0.0,          // 初期化
-					RPE:      0.0,          // 初期化
+					WeightKg: 0.0,      // 未指定の場合は 0
+					Reps:     set.Reps, // 直接代入
+					RIR:      0.0,      // 初期化
+					RPE:      0.0,      // 初期化
+					SetType:  createdSet.SetType,
+					GroupKey: pgtypeTextToPtrString(createdSet.GroupKey),
+				}
+				setCardioFields(&setDto, metricType, createdSet.DurationSeconds, createdSet.DistanceM, createdSet.AvgHeartRate)
+				if set.WeightKg != nil {
+					setDto.WeightKg = *set.WeightKg
 				}
 				// RIR が nil でなければ値を代入
 				if set.RIR != nil {
@@ -357,6 +477,8 @@ func (s *WorkoutService) StartWorkout(ctx context.Context, req dto.CreateWorkout
 				Reps:       0,
 				Rir:        rir,
 				Rpe:        rpe,
+				SetType:    SetTypeWorking,
+				GroupKey:   item.GroupKey,
 			})
 			if err != nil {
 				s.logger.ErrorContext(ctx, "Failed to execute CreateSet query", slog.Any("error", err), slog.String("workout_id", workout.ID.String()), slog.Int("item_index", i), slog.Any("item", item))
@@ -372,6 +494,10 @@ func (s *WorkoutService) StartWorkout(ctx context.Context, req dto.CreateWorkout
 				Reps:     set.Reps,
 				RIR:      0.0, // nil の代わりに 0.0 を代入
 				RPE:      0.0, // nil の代わりに 0.0 を代入
+				SetType:  set.SetType,
+				GroupKey: pgtypeTextToPtrString(set.GroupKey),
+				// 有酸素・時間計測の種目の値は未入力 (UpdateSet で記録する)
+				MetricType: item.MetricType,
 			})
 		}
 	}
@@ -386,6 +512,8 @@ func (s *WorkoutService) StartWorkout(ctx context.Context, req dto.CreateWorkout
 	s.logger.InfoContext(ctx, "Transaction committed successfully for StartWorkout",
 		slog.String("user_id", userID),
 		slog.String("workout_id", workout.ID.String()))
+	workoutsStarted.Add(ctx, 1, sourceAttr("workout"))
+	setsLogged.Add(ctx, int64(len(sets)), sourceAttr("workout"))
 
 	// ノートの変換
 	var noteStr string
@@ -420,27 +548,84 @@ func (s *WorkoutService) StartWorkout(ctx context.Context, req dto.CreateWorkout
 
 // UpdateSet はセットを更新する
 func (s *WorkoutService) UpdateSet(ctx context.Context, setID uuid.UUID, req dto.UpdateSetRequest) (*dto.SetView, error) {
+	ctx, span := startSpan(ctx, "WorkoutService.UpdateSet", attribute.String("set_id", setID.String()))
+	defer span.End()
+
+	// 現在のセットと種目 (記録指標) を取得
+	currentSet, err := s.queries.GetSet(ctx, setID)
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return nil, httpError.NewNotFoundError("Set not found", err)
+		}
+		s.logger.ErrorContext(ctx, "Failed to execute GetSet query", slog.Any("error", err), slog.String("set_id", setID.String()))
+		return nil, fmt.Errorf("failed to get set (ID: %s): %w", setID, err)
+	}
+
+	exerciseName := "不明な種目"
+	metricType := MetricTypeWeightReps
+	loadType := volume.LoadTypeExternal
+	if currentSet.ExerciseID.Valid {
+		exercise, err := s.queries.GetExercise(ctx, currentSet.ExerciseID.Bytes)
+		if err != nil {
+			s.logger.WarnContext(ctx, "Failed to get exercise for set", slog.Any("error", err), slog.String("exercise_id", uuid.UUID(currentSet.ExerciseID.Bytes).String()), slog.String("set_id", setID.String()))
+		} else {
+			exerciseName = exercise.Name
+			metricType = exercise.MetricType
+			loadType = exercise.LoadType
+		}
+	}
+
+	// 未指定の値は現在の値を引き継ぎ、記録指標に応じて検証する
+	metrics := setMetrics{
+		LoadType:        loadType,
+		WeightKg:        pgtypeNumericToPtrFloat64(currentSet.WeightKg),
+		Reps:            currentSet.Reps,
+		DurationSeconds: pgtypeInt4ToPtrInt32(currentSet.DurationSeconds),
+		DistanceM:       pgtypeNumericToPtrFloat64(currentSet.DistanceM),
+		AvgHeartRate:    pgtypeInt4ToPtrInt32(currentSet.AvgHeartRate),
+	}
+	if req.WeightKg != nil {
+		metrics.WeightKg = req.WeightKg
+	}
+	if req.Reps != nil {
+		metrics.Reps = *req.Reps
+	}
+	if req.DurationSeconds != nil {
+		metrics.DurationSeconds = req.DurationSeconds
+	}
+	if req.DistanceM != nil {
+		metrics.DistanceM = req.DistanceM
+	}
+	if req.AvgHeartRate != nil {
+		metrics.AvgHeartRate = req.AvgHeartRate
+	}
+	if err := validateSetMetrics(metricType, metrics); err != nil {
+		return nil, err
+	}
+
 	// リクエストから値を取得し、pgtypeに変換
 	params := sqlc.UpdateSetParams{ID: setID}
 
-	// WeightKg
-	var weightKg pgtype.Numeric
-	if req.WeightKg != nil {
-		weightKgStr := strconv.FormatFloat(*req.WeightKg, 'f', 2, 64)
-		if err := weightKg.Scan(weightKgStr); err != nil {
-			s.logger.ErrorContext(ctx, "weight_kg conversion error during UpdateSet", slog.Any("error", err), slog.String("set_id", setID.String()), slog.String("input", weightKgStr))
-			return nil, fmt.Errorf("weight_kg conversion error: %w", err)
-		}
+	// WeightKg (自重系の種目で未記録の場合は NULL のまま)
+	weightKg, err := ptrFloat64ToPgtypeNumeric(metrics.WeightKg)
+	if err != nil {
+		s.logger.ErrorContext(ctx, "weight_kg conversion error during UpdateSet", slog.Any("error", err), slog.String("set_id", setID.String()), slog.Any("input", metrics.WeightKg))
+		return nil, fmt.Errorf("weight_kg conversion error: %w", err)
 	}
 	params.WeightKg = weightKg
 
-	// Reps (SetReps は使わない)
-	if req.Reps != nil {
-		// params.SetReps = true // コメントアウト
-		params.Reps = *req.Reps // sqlcクエリ側で NULL を考慮する必要あり
-	} // else { // コメントアウト
-	// 	params.SetReps = false // コメントアウト
-	// } // コメントアウト
+	// Reps
+	params.Reps = metrics.Reps
+
+	// 有酸素・時間計測の種目の値
+	params.DurationSeconds = ptrInt32ToPgtypeInt4(metrics.DurationSeconds)
+	params.AvgHeartRate = ptrInt32ToPgtypeInt4(metrics.AvgHeartRate)
+	distanceM, err := ptrFloat64ToPgtypeNumeric(metrics.DistanceM)
+	if err != nil {
+		s.logger.ErrorContext(ctx, "distance_m conversion error during UpdateSet", slog.Any("error", err), slog.String("set_id", setID.String()), slog.Any("input", metrics.DistanceM))
+		return nil, fmt.Errorf("distance_m conversion error: %w", err)
+	}
+	params.DistanceM = distanceM
 
 	// RIR
 	var rir pgtype.Numeric
@@ -464,6 +649,23 @@ func (s *WorkoutService) UpdateSet(ctx context.Context, setID uuid.UUID, req dto
 	}
 	params.Rpe = rpe
 
+	// SetType (未指定の場合は既存値を維持)
+	if req.SetType != nil {
+		setType, err := normalizeSetType(*req.SetType)
+		if err != nil {
+			return nil, err
+		}
+		params.SetType = pgtype.Text{String: setType, Valid: true}
+	}
+
+	// GroupKey (未指定の場合は既存値を維持、空文字列でグループ解除)
+	if req.GroupKey != nil {
+		if err := validateGroupKey(req.GroupKey); err != nil {
+			return nil, err
+		}
+		params.GroupKey = pgtype.Text{String: *req.GroupKey, Valid: true}
+	}
+
 	// セット更新
 	updatedSet, err := s.queries.UpdateSet(ctx, params)
 	if err != nil {
@@ -471,24 +673,6 @@ func (s *WorkoutService) UpdateSet(ctx context.Context, setID uuid.UUID, req dto
 		return nil, fmt.Errorf("failed to update set (ID: %s): %w", setID, err)
 	}
 
-	// 更新後のセット情報を取得 (GetSetView の代わりに updatedSet と GetExercise を使う)
-	// setView, err := s.queries.GetSetView(ctx, updatedSet.ID) // コメントアウト
-	// if err != nil {
-	// 	s.logger.ErrorContext(ctx, "Failed to execute GetSetView query after UpdateSet", slog.Any("error", err), slog.String("set_id", updatedSet.ID.String()))
-	// 	return nil, fmt.Errorf("failed to retrieve updated set view (ID: %s): %w", updatedSet.ID, err)
-	// }
-
-	// Exercise Name を取得
-	exerciseName := "不明な種目"
-	if updatedSet.ExerciseID.Valid {
-		exercise, err := s.queries.GetExercise(ctx, updatedSet.ExerciseID.Bytes)
-		if err != nil {
-			s.logger.WarnContext(ctx, "Failed to get exercise name after updating set", slog.Any("error", err), slog.String("exercise_id", uuid.UUID(updatedSet.ExerciseID.Bytes).String()), slog.String("set_id", updatedSet.ID.String()))
-		} else {
-			exerciseName = exercise.Name
-		}
-	}
-
 	// DTOに変換
 	result := dto.SetView{
 		ID:       updatedSet.ID,
@@ -498,7 +682,10 @@ func (s *WorkoutService) UpdateSet(ctx context.Context, setID uuid.UUID, req dto
 		Reps:     updatedSet.Reps,
 		RIR:      0.0, // 0.0 で初期化
 		RPE:      0.0, // 0.0 で初期化
+		SetType:  updatedSet.SetType,
+		GroupKey: pgtypeTextToPtrString(updatedSet.GroupKey),
 	}
+	setCardioFields(&result, metricType, updatedSet.DurationSeconds, updatedSet.DistanceM, updatedSet.AvgHeartRate)
 	// WeightKg の変換
 	if updatedSet.WeightKg.Valid {
 		wVal, err := updatedSet.WeightKg.Float64Value()
@@ -532,8 +719,63 @@ func (s *WorkoutService) UpdateSet(ctx context.Context, setID uuid.UUID, req dto
 	return &result, nil
 }
 
+// AuthorizeWorkout はワークアウトがユーザー (コーチのアクセスの場合はアスリート) のものかを確認する
+// 他のユーザーのワークアウトは存在を知られないよう NotFound とする
+func (s *WorkoutService) AuthorizeWorkout(ctx context.Context, workoutID uuid.UUID, userID string) error {
+	ctx, span := startSpan(ctx, "WorkoutService.AuthorizeWorkout", attribute.String("user_id", userID), attribute.String("workout_id", workoutID.String()))
+	defer span.End()
+
+	workout, err := s.queries.GetWorkout(ctx, workoutID)
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return httpError.NewNotFoundError("Workout not found", err)
+		}
+		s.logger.ErrorContext(ctx, "Failed to execute GetWorkout query", slog.Any("error", err), slog.String("workout_id", workoutID.String()))
+		return err
+	}
+	if workout.UserID != userID {
+		return httpError.NewNotFoundError("Workout not found", nil)
+	}
+	return nil
+}
+
+// AuthorizeSet はセットがユーザー (コーチのアクセスの場合はアスリート) のワークアウトのものかを確認する
+// 他のユーザーのセットは存在を知られないよう NotFound とする
+func (s *WorkoutService) AuthorizeSet(ctx context.Context, setID uuid.UUID, userID string) error {
+	ctx, span := startSpan(ctx, "WorkoutService.AuthorizeSet", attribute.String("user_id", userID), attribute.String("set_id", setID.String()))
+	defer span.End()
+
+	set, err := s.queries.GetSet(ctx, setID)
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return httpError.NewNotFoundError("Set not found", err)
+		}
+		s.logger.ErrorContext(ctx, "Failed to execute GetSet query", slog.Any("error", err), slog.String("set_id", setID.String()))
+		return err
+	}
+	if !set.WorkoutID.Valid {
+		return httpError.NewNotFoundError("Set not found", nil)
+	}
+	workoutID := uuid.UUID(set.WorkoutID.Bytes)
+	workout, err := s.queries.GetWorkout(ctx, workoutID)
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return httpError.NewNotFoundError("Set not found", err)
+		}
+		s.logger.ErrorContext(ctx, "Failed to execute GetWorkout query", slog.Any("error", err), slog.String("workout_id", workoutID.String()))
+		return err
+	}
+	if workout.UserID != userID {
+		return httpError.NewNotFoundError("Set not found", nil)
+	}
+	return nil
+}
+
 // GetWorkoutWithSets はワークアウトとそのセットを取得する
 func (s *WorkoutService) GetWorkoutWithSets(ctx context.Context, workoutID uuid.UUID) (*dto.WorkoutResponse, error) {
+	ctx, span := startSpan(ctx, "WorkoutService.GetWorkoutWithSets", attribute.String("workout_id", workoutID.String()))
+	defer span.End()
+
 	// ワークアウト情報の取得
 	workout, err := s.queries.GetWorkout(ctx, workoutID)
 	if err != nil {
@@ -577,7 +819,10 @@ func (s *WorkoutService) GetWorkoutWithSets(ctx context.Context, workoutID uuid.
 			Reps:     setRow.Reps,
 			RIR:      0.0, // 初期化
 			RPE:      0.0, // 初期化
+			SetType:  setRow.SetType,
+			GroupKey: pgtypeTextToPtrString(setRow.GroupKey),
 		}
+		setCardioFields(&setDTO, setRow.MetricType, setRow.DurationSeconds, setRow.DistanceM, setRow.AvgHeartRate)
 		// WeightKg の変換
 		if setRow.WeightKg.Valid {
 			wVal, errConv := setRow.WeightKg.Float64Value()
@@ -626,3 +871,12 @@ func (s *WorkoutService) GetWorkoutWithSets(ctx context.Context, workoutID uuid.
 		Sets:      sets,
 	}, nil
 }
+
+// setCardioFields はセットの記録指標と有酸素・時間計測の値 (時間・距離・ペース・心拍数) を SetView に設定する
+func setCardioFields(view *dto.SetView, metricType string, durationSeconds pgtype.Int4, distanceM pgtype.Numeric, avgHeartRate pgtype.Int4) {
+	view.MetricType = metricType
+	view.DurationSeconds = pgtypeInt4ToPtrInt32(durationSeconds)
+	view.DistanceM = pgtypeNumericToPtrFloat64(distanceM)
+	view.PaceSecondsPerKm = paceSecondsPerKm(durationSeconds, view.DistanceM)
+	view.AvgHeartRate = pgtypeInt4ToPtrInt32(avgHeartRate)
+}
@@ -0,0 +1,163 @@
+// Package jobs は Postgres に保存するジョブキューでバックグラウンド処理を実行する
+//
+// ジョブの種類 (Kind) ごとに型付きの引数とハンドラーを登録する。失敗したジョブは指数バックオフで再試行し、
+// 試行回数を使い切ると failed として残す (管理用ポートの GET /jobs/failed で確認できる)。
+// 定期実行のジョブはスケジュール (cron 式または @every) の実行日時ごとに、複数のインスタンスのうち1つが1件追加する
+package jobs
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"time"
+)
+
+// DefaultMaxAttempts はジョブの試行回数の既定値
+const DefaultMaxAttempts = 5
+
+// Args はジョブの引数 (JSON で保存する)
+// Kind はジョブの種類を返す。ゼロ値でも呼び出せるよう、値のレシーバーで定数を返すこと
+type Args interface {
+	Kind() string
+}
+
+// Job はハンドラーに渡す実行中のジョブ
+type Job[T Args] struct {
+	ID          int64
+	Attempt     int // 何回目の実行か (1 から)
+	MaxAttempts int
+	Args        T
+}
+
+// LastAttempt は最後の試行か (失敗すると再試行せず failed になる) を返す
+func (j *Job[T]) LastAttempt() bool {
+	return j.Attempt >= j.MaxAttempts
+}
+
+// InsertOptions はジョブを追加する際の設定
+type InsertOptions struct {
+	RunAt       time.Time // この日時以降に実行する (ゼロ値の場合はすぐに実行する)
+	MaxAttempts int       // 0 の場合は DefaultMaxAttempts
+	UniqueKey   string    // 同じキーの未完了 (実行待ち・実行中) のジョブがある場合は追加しない (空の場合は制限しない)
+}
+
+// InsertParams は Store に保存するジョブ
+type InsertParams struct {
+	Kind        string
+	Args        []byte
+	RunAt       time.Time
+	MaxAttempts int
+	UniqueKey   string
+}
+
+// ClaimedJob は Store から取り出した実行中のジョブ (Attempt は今回の実行を含む)
+type ClaimedJob struct {
+	ID          int64
+	Kind        string
+	Args        []byte
+	Attempt     int
+	MaxAttempts int
+}
+
+// Store はジョブを保存する (Postgres の実装は service.JobService)
+type Store interface {
+	// Insert はジョブを保存する。同じ UniqueKey の未完了のジョブがある場合は保存せず、inserted が false
+	Insert(ctx context.Context, job InsertParams) (id int64, inserted bool, err error)
+	// Claim は実行日時を過ぎた kinds のジョブを最大 limit 件取り出し、実行中にする (他のインスタンスが実行中のジョブは取り出さない)
+	Claim(ctx context.Context, workerID string, kinds []string, limit int) ([]ClaimedJob, error)
+	// Complete はジョブを完了にする
+	Complete(ctx context.Context, id int64) error
+	// Retry はジョブを runAt に再試行する
+	Retry(ctx context.Context, id int64, runAt time.Time, errMsg string) error
+	// Fail はジョブを再試行せずに失敗にする
+	Fail(ctx context.Context, id int64, errMsg string) error
+	// Release はシャットダウンで中断したジョブを、試行回数に数えずに実行待ちに戻す
+	Release(ctx context.Context, ids []int64) error
+	// RescueStale は lockedBefore より前から実行中のままの kind のジョブ (停止したインスタンスのジョブ) を実行待ちに戻す
+	RescueStale(ctx context.Context, kind string, lockedBefore time.Time) (int64, error)
+	// DeleteFinished は completedBefore より前に完了したジョブと failedBefore より前に失敗したジョブを削除する
+	DeleteFinished(ctx context.Context, completedBefore, failedBefore time.Time) (int64, error)
+	// EnqueueScheduled は定期実行のジョブ name の実行日時が now を過ぎていれば次の実行日時を next にしてジョブを追加する
+	// (初めての場合は next を最初の実行日時として登録する)。複数のインスタンスが同時に呼び出しても1つのみが追加する
+	EnqueueScheduled(ctx context.Context, name string, now, next time.Time, job InsertParams) (bool, error)
+}
+
+// Client はジョブを追加する
+type Client struct {
+	store Store
+}
+
+// NewClient は新しい Client を作成する
+func NewClient(store Store) *Client {
+	return &Client{store: store}
+}
+
+// Enqueue はジョブを追加する (opts は nil でもよい)
+// 同じ UniqueKey の未完了のジョブがある場合は追加せず、inserted が false
+func (c *Client) Enqueue(ctx context.Context, args Args, opts *InsertOptions) (id int64, inserted bool, err error) {
+	params, err := NewInsertParams(args, opts)
+	if err != nil {
+		return 0, false, err
+	}
+	return c.store.Insert(ctx, params)
+}
+
+// NewInsertParams はジョブの引数を JSON にして、設定の既定値を埋める
+// 他の更新と同じトランザクションでジョブを追加する場合に使う (追加はコミットされた場合のみ有効になる)
+func NewInsertParams(args Args, opts *InsertOptions) (InsertParams, error) {
+	encoded, err := json.Marshal(args)
+	if err != nil {
+		return InsertParams{}, fmt.Errorf("failed to encode %s job args: %w", args.Kind(), err)
+	}
+	params := InsertParams{
+		Kind:        args.Kind(),
+		Args:        encoded,
+		RunAt:       time.Now(),
+		MaxAttempts: DefaultMaxAttempts,
+	}
+	if opts != nil {
+		if !opts.RunAt.IsZero() {
+			params.RunAt = opts.RunAt
+		}
+		if opts.MaxAttempts > 0 {
+			params.MaxAttempts = opts.MaxAttempts
+		}
+		params.UniqueKey = opts.UniqueKey
+	}
+	return params, nil
+}
+
+// permanentError は再試行しても成功しないエラー
+type permanentError struct {
+	err error
+}
+
+func (e *permanentError) Error() string { return e.err.Error() }
+func (e *permanentError) Unwrap() error { return e.err }
+
+// Permanent はハンドラーが返すと再試行せずにジョブを失敗にするエラーを返す (引数の不正など)
+func Permanent(err error) error {
+	return &permanentError{err: err}
+}
+
+// IsPermanent は Permanent のエラーか
+func IsPermanent(err error) bool {
+	var permanent *permanentError
+	return errors.As(err, &permanent)
+}
+
+// DefaultBackoff は attempt 回目の失敗の後、次に実行するまでの時間 (30 秒から倍にして最大 1 時間)
+func DefaultBackoff(attempt int) time.Duration {
+	const (
+		base    = 30 * time.Second
+		maximum = time.Hour
+	)
+	if attempt < 1 {
+		attempt = 1
+	}
+	if attempt > 8 { // 30s * 2^7 で最大を超える
+		return maximum
+	}
+	return min(base<<(attempt-1), maximum)
+}
@@ -0,0 +1,393 @@
+package jobs
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log/slog"
+	"os"
+	"runtime/debug"
+	"sync"
+	"time"
+)
+
+const (
+	// DefaultTimeout はハンドラーの実行時間の既定の上限
+	DefaultTimeout = 10 * time.Minute
+
+	defaultWorkers      = 4
+	defaultPollInterval = time.Second
+
+	scheduleInterval    = 10 * time.Second // 定期実行のジョブを確認する間隔
+	maintenanceInterval = time.Minute      // 停止したインスタンスのジョブの回収と古いジョブの削除の間隔
+	staleMargin         = time.Minute      // タイムアウトを過ぎてから停止したインスタンスのジョブとみなすまでの猶予
+	storeTimeout        = 10 * time.Second // ジョブの結果を保存する時間の上限 (シャットダウン中も保存する)
+	completedRetention  = 7 * 24 * time.Hour
+	failedRetention     = 30 * 24 * time.Hour
+)
+
+// HandlerOptions はジョブの種類ごとの実行の設定
+type HandlerOptions struct {
+	Timeout time.Duration                   // 0 の場合は DefaultTimeout
+	Backoff func(attempt int) time.Duration // nil の場合は DefaultBackoff
+}
+
+// handler は登録されたジョブの種類のハンドラー
+type handler struct {
+	timeout time.Duration
+	backoff func(attempt int) time.Duration
+	work    func(ctx context.Context, job ClaimedJob) error
+}
+
+// PeriodicJob は定期実行のジョブ
+type PeriodicJob struct {
+	Name     string // スケジュールを保存するキー (インスタンス間で同じ名前にする)
+	Schedule Schedule
+	Args     Args
+	Options  *InsertOptions // UniqueKey が空の場合は "periodic:" + Name (前回のジョブが終わるまで追加しない)
+}
+
+// RunnerConfig は Runner の設定
+type RunnerConfig struct {
+	ID           string        // ジョブを実行中にしたインスタンスの識別子 (空の場合はホスト名とプロセス ID)
+	Workers      int           // 同時に実行するジョブの数 (0 の場合は 4)
+	PollInterval time.Duration // 実行待ちのジョブを確認する間隔 (0 の場合は 1 秒)
+}
+
+// Runner は登録されたジョブを Store から取り出して実行する
+//
+// 複数のインスタンスで動かしても、同じジョブを重複して実行しない。ハンドラーは ctx の終了 (タイムアウト・シャットダウン) で
+// 処理を中断すること。途中で停止したインスタンスのジョブはタイムアウトの後に他のインスタンスが実行し直すため、
+// ハンドラーは同じジョブを再び実行しても結果が変わらないようにする
+type Runner struct {
+	store    Store
+	logger   *slog.Logger
+	id       string
+	workers  int
+	interval time.Duration
+
+	handlers map[string]*handler
+	kinds    []string
+	periodic []PeriodicJob
+
+	mu         sync.Mutex
+	started    bool
+	running    int
+	stopFetch  context.CancelFunc
+	cancelJobs context.CancelFunc
+	loopDone   chan struct{}
+	jobs       sync.WaitGroup
+	wake       chan struct{}
+}
+
+// NewRunner は新しい Runner を作成する
+func NewRunner(store Store, logger *slog.Logger, cfg RunnerConfig) *Runner {
+	id := cfg.ID
+	if id == "" {
+		host, _ := os.Hostname()
+		id = fmt.Sprintf("%s-%d", host, os.Getpid())
+	}
+	workers := cfg.Workers
+	if workers <= 0 {
+		workers = defaultWorkers
+	}
+	interval := cfg.PollInterval
+	if interval <= 0 {
+		interval = defaultPollInterval
+	}
+	return &Runner{
+		store:    store,
+		logger:   logger,
+		id:       id,
+		workers:  workers,
+		interval: interval,
+		handlers: make(map[string]*handler),
+		wake:     make(chan struct{}, 1),
+	}
+}
+
+// Register はジョブの種類 T のハンドラーを登録する (Start の前に呼び出す)
+// ハンドラーがエラーを返すとバックオフの後に再試行し、Permanent のエラーまたは最後の試行の場合は失敗にする
+func Register[T Args](r *Runner, fn func(ctx context.Context, job *Job[T]) error, opts HandlerOptions) {
+	var zero T
+	kind := zero.Kind()
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	if r.started {
+		panic("jobs: Register called after Start")
+	}
+	if _, ok := r.handlers[kind]; ok {
+		panic("jobs: handler for " + kind + " registered twice")
+	}
+
+	h := &handler{
+		timeout: opts.Timeout,
+		backoff: opts.Backoff,
+		work: func(ctx context.Context, c ClaimedJob) error {
+			var args T
+			if err := json.Unmarshal(c.Args, &args); err != nil {
+				return Permanent(fmt.Errorf("failed to decode %s job args: %w", kind, err))
+			}
+			return fn(ctx, &Job[T]{ID: c.ID, Attempt: c.Attempt, MaxAttempts: c.MaxAttempts, Args: args})
+		},
+	}
+	if h.timeout <= 0 {
+		h.timeout = DefaultTimeout
+	}
+	if h.backoff == nil {
+		h.backoff = DefaultBackoff
+	}
+	r.handlers[kind] = h
+	r.kinds = append(r.kinds, kind)
+}
+
+// AddPeriodic は定期実行のジョブを追加する (Start の前に呼び出す。ジョブの種類のハンドラーも登録すること)
+func (r *Runner) AddPeriodic(job PeriodicJob) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	if r.started {
+		panic("jobs: AddPeriodic called after Start")
+	}
+	r.periodic = append(r.periodic, job)
+}
+
+// Start はジョブの実行を開始する。ctx が終了するか Stop を呼び出すと新しいジョブを取り出さなくなる
+func (r *Runner) Start(ctx context.Context) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	if r.started {
+		return
+	}
+	r.started = true
+
+	fetchCtx, stopFetch := context.WithCancel(ctx)
+	// 実行中のジョブは ctx が終了しても Stop の猶予の間は続ける
+	jobCtx, cancelJobs := context.WithCancel(context.WithoutCancel(ctx))
+	r.stopFetch = stopFetch
+	r.cancelJobs = cancelJobs
+	r.loopDone = make(chan struct{})
+	go r.loop(fetchCtx, jobCtx)
+}
+
+// Stop は新しいジョブの取り出しを止め、実行中のジョブが終わるのを ctx が終了するまで待つ
+// ctx が終了した場合は実行中のジョブの ctx をキャンセルし、ジョブを試行回数に数えずに実行待ちに戻す
+func (r *Runner) Stop(ctx context.Context) error {
+	r.mu.Lock()
+	started := r.started
+	r.mu.Unlock()
+	if !started {
+		return nil
+	}
+
+	r.stopFetch()
+	<-r.loopDone
+
+	done := make(chan struct{})
+	go func() {
+		r.jobs.Wait()
+		close(done)
+	}()
+	select {
+	case <-done:
+		r.cancelJobs()
+		return nil
+	case <-ctx.Done():
+	}
+
+	r.cancelJobs()
+	select {
+	case <-done:
+	case <-time.After(storeTimeout):
+		// ctx を無視するハンドラーのジョブは実行中のまま残り、タイムアウトの後に他のインスタンスが実行し直す
+	}
+	return fmt.Errorf("stopped job runner before running jobs finished: %w", ctx.Err())
+}
+
+// loop は fetchCtx が終了するまで、ジョブの取り出し・定期実行のジョブの追加・メンテナンスを行う
+func (r *Runner) loop(fetchCtx, jobCtx context.Context) {
+	defer close(r.loopDone)
+
+	poll := time.NewTicker(r.interval)
+	defer poll.Stop()
+	schedule := time.NewTicker(scheduleInterval)
+	defer schedule.Stop()
+	maintenance := time.NewTicker(maintenanceInterval)
+	defer maintenance.Stop()
+
+	r.enqueuePeriodic(fetchCtx)
+	r.maintain(fetchCtx)
+	r.fetch(fetchCtx, jobCtx)
+	for {
+		select {
+		case <-fetchCtx.Done():
+			return
+		case <-poll.C:
+		case <-r.wake:
+		case <-schedule.C:
+			r.enqueuePeriodic(fetchCtx)
+		case <-maintenance.C:
+			r.maintain(fetchCtx)
+		}
+		r.fetch(fetchCtx, jobCtx)
+	}
+}
+
+// fetch は空いているワーカーの数だけジョブを取り出して実行を開始する
+func (r *Runner) fetch(fetchCtx, jobCtx context.Context) {
+	if len(r.kinds) == 0 {
+		return
+	}
+	for fetchCtx.Err() == nil {
+		r.mu.Lock()
+		free := r.workers - r.running
+		r.mu.Unlock()
+		if free <= 0 {
+			return
+		}
+
+		claimed, err := r.store.Claim(fetchCtx, r.id, r.kinds, free)
+		if err != nil {
+			if fetchCtx.Err() == nil {
+				r.logger.ErrorContext(fetchCtx, "Failed to claim jobs", slog.Any("error", err))
+			}
+			return
+		}
+		for _, job := range claimed {
+			r.mu.Lock()
+			r.running++
+			r.mu.Unlock()
+			r.jobs.Add(1)
+			go r.run(jobCtx, job)
+		}
+		// 取り出せた数が空きより少なければ実行待ちのジョブはない
+		if len(claimed) < free {
+			return
+		}
+	}
+}
+
+// run はジョブを実行し、結果を保存する
+func (r *Runner) run(jobCtx context.Context, job ClaimedJob) {
+	defer func() {
+		r.mu.Lock()
+		r.running--
+		r.mu.Unlock()
+		r.jobs.Done()
+		// 空いたワーカーで次のジョブを取り出す
+		select {
+		case r.wake <- struct{}{}:
+		default:
+		}
+	}()
+
+	logger := r.logger.With(slog.Int64("job_id", job.ID), slog.String("kind", job.Kind), slog.Int("attempt", job.Attempt))
+	h := r.handlers[job.Kind]
+	started := time.Now()
+	var err error
+	if h == nil {
+		err = Permanent(errors.New("no handler registered for " + job.Kind))
+	} else {
+		ctx, cancel := context.WithTimeout(jobCtx, h.timeout)
+		err = work(ctx, h, job)
+		cancel()
+	}
+
+	ctx, cancel := context.WithTimeout(context.WithoutCancel(jobCtx), storeTimeout)
+	defer cancel()
+	switch {
+	case err == nil:
+		if err := r.store.Complete(ctx, job.ID); err != nil {
+			logger.ErrorContext(ctx, "Failed to complete job", slog.Any("error", err))
+			return
+		}
+		logger.InfoContext(ctx, "Completed job", slog.Duration("duration", time.Since(started)))
+	case jobCtx.Err() != nil:
+		if err := r.store.Release(ctx, []int64{job.ID}); err != nil {
+			logger.ErrorContext(ctx, "Failed to release job", slog.Any("error", err))
+			return
+		}
+		logger.InfoContext(ctx, "Released job interrupted by shutdown")
+	case IsPermanent(err) || job.Attempt >= job.MaxAttempts:
+		if err := r.store.Fail(ctx, job.ID, err.Error()); err != nil {
+			logger.ErrorContext(ctx, "Failed to mark job as failed", slog.Any("error", err))
+			return
+		}
+		logger.ErrorContext(ctx, "Job failed", slog.Any("error", err), slog.Int("max_attempts", job.MaxAttempts))
+	default:
+		runAt := time.Now().Add(h.backoff(job.Attempt))
+		if err := r.store.Retry(ctx, job.ID, runAt, err.Error()); err != nil {
+			logger.ErrorContext(ctx, "Failed to schedule job retry", slog.Any("error", err))
+			return
+		}
+		logger.WarnContext(ctx, "Job returned an error, retrying", slog.Any("error", err), slog.Time("run_at", runAt))
+	}
+}
+
+// work はハンドラーを呼び出す (panic はエラーにして再試行する)
+func work(ctx context.Context, h *handler, job ClaimedJob) (err error) {
+	defer func() {
+		if p := recover(); p != nil {
+			err = fmt.Errorf("job panicked: %v\n%s", p, debug.Stack())
+		}
+	}()
+	return h.work(ctx, job)
+}
+
+// enqueuePeriodic は実行日時を過ぎた定期実行のジョブを追加する
+func (r *Runner) enqueuePeriodic(ctx context.Context) {
+	now := time.Now()
+	for _, p := range r.periodic {
+		opts := InsertOptions{}
+		if p.Options != nil {
+			opts = *p.Options
+		}
+		if opts.UniqueKey == "" {
+			opts.UniqueKey = "periodic:" + p.Name
+		}
+		next := p.Schedule.Next(now)
+		params, err := NewInsertParams(p.Args, &opts)
+		if err != nil {
+			r.logger.ErrorContext(ctx, "Failed to build periodic job", slog.Any("error", err), slog.String("name", p.Name))
+			continue
+		}
+		enqueued, err := r.store.EnqueueScheduled(ctx, p.Name, now, next, params)
+		if err != nil {
+			if ctx.Err() == nil {
+				r.logger.ErrorContext(ctx, "Failed to enqueue periodic job", slog.Any("error", err), slog.String("name", p.Name))
+			}
+			continue
+		}
+		if enqueued {
+			r.logger.InfoContext(ctx, "Enqueued periodic job", slog.String("name", p.Name), slog.Time("next_run_at", next))
+		}
+	}
+}
+
+// maintain は停止したインスタンスが実行中のまま残したジョブを実行待ちに戻し、古い完了・失敗のジョブを削除する
+func (r *Runner) maintain(ctx context.Context) {
+	now := time.Now()
+	for _, kind := range r.kinds {
+		rescued, err := r.store.RescueStale(ctx, kind, now.Add(-r.handlers[kind].timeout-staleMargin))
+		if err != nil {
+			if ctx.Err() == nil {
+				r.logger.ErrorContext(ctx, "Failed to rescue stale jobs", slog.Any("error", err), slog.String("kind", kind))
+			}
+			return
+		}
+		if rescued > 0 {
+			r.logger.WarnContext(ctx, "Rescued jobs left running by a stopped worker", slog.String("kind", kind), slog.Int64("jobs", rescued))
+		}
+	}
+
+	deleted, err := r.store.DeleteFinished(ctx, now.Add(-completedRetention), now.Add(-failedRetention))
+	if err != nil {
+		if ctx.Err() == nil {
+			r.logger.ErrorContext(ctx, "Failed to delete finished jobs", slog.Any("error", err))
+		}
+		return
+	}
+	if deleted > 0 {
+		r.logger.InfoContext(ctx, "Deleted finished jobs", slog.Int64("jobs", deleted))
+	}
+}
@@ -0,0 +1,312 @@
+package jobs
+
+import (
+	"context"
+	"errors"
+	"io"
+	"log/slog"
+	"sync"
+	"testing"
+	"time"
+)
+
+// memStore はテスト用のメモリ上の Store
+type memStore struct {
+	mu        sync.Mutex
+	nextID    int64
+	jobs      map[int64]*memJob
+	schedules map[string]time.Time
+}
+
+type memJob struct {
+	InsertParams
+	state     string
+	attempt   int
+	lastError string
+	lockedAt  time.Time
+}
+
+func newMemStore() *memStore {
+	return &memStore{jobs: make(map[int64]*memJob), schedules: make(map[string]time.Time)}
+}
+
+func (s *memStore) Insert(_ context.Context, job InsertParams) (int64, bool, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.insertLocked(job)
+}
+
+func (s *memStore) insertLocked(job InsertParams) (int64, bool, error) {
+	if job.UniqueKey != "" {
+		for _, j := range s.jobs {
+			if j.UniqueKey == job.UniqueKey && (j.state == "available" || j.state == "running") {
+				return 0, false, nil
+			}
+		}
+	}
+	s.nextID++
+	s.jobs[s.nextID] = &memJob{InsertParams: job, state: "available"}
+	return s.nextID, true, nil
+}
+
+func (s *memStore) Claim(_ context.Context, _ string, kinds []string, limit int) ([]ClaimedJob, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	var claimed []ClaimedJob
+	for id := int64(1); id <= s.nextID && len(claimed) < limit; id++ {
+		j, ok := s.jobs[id]
+		if !ok || j.state != "available" || j.RunAt.After(time.Now()) {
+			continue
+		}
+		for _, kind := range kinds {
+			if j.Kind == kind {
+				j.state = "running"
+				j.attempt++
+				j.lockedAt = time.Now()
+				claimed = append(claimed, ClaimedJob{ID: id, Kind: j.Kind, Args: j.Args, Attempt: j.attempt, MaxAttempts: j.MaxAttempts})
+				break
+			}
+		}
+	}
+	return claimed, nil
+}
+
+func (s *memStore) update(id int64, fn func(j *memJob)) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if j, ok := s.jobs[id]; ok && j.state == "running" {
+		fn(j)
+	}
+}
+
+func (s *memStore) Complete(_ context.Context, id int64) error {
+	s.update(id, func(j *memJob) { j.state = "completed" })
+	return nil
+}
+
+func (s *memStore) Retry(_ context.Context, id int64, runAt time.Time, errMsg string) error {
+	s.update(id, func(j *memJob) { j.state, j.RunAt, j.lastError = "available", runAt, errMsg })
+	return nil
+}
+
+func (s *memStore) Fail(_ context.Context, id int64, errMsg string) error {
+	s.update(id, func(j *memJob) { j.state, j.lastError = "failed", errMsg })
+	return nil
+}
+
+func (s *memStore) Release(_ context.Context, ids []int64) error {
+	for _, id := range ids {
+		s.update(id, func(j *memJob) { j.state, j.attempt = "available", j.attempt-1 })
+	}
+	return nil
+}
+
+func (s *memStore) RescueStale(_ context.Context, kind string, lockedBefore time.Time) (int64, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	var n int64
+	for _, j := range s.jobs {
+		if j.state == "running" && j.Kind == kind && j.lockedAt.Before(lockedBefore) {
+			j.state = "available"
+			n++
+		}
+	}
+	return n, nil
+}
+
+func (s *memStore) DeleteFinished(context.Context, time.Time, time.Time) (int64, error) {
+	return 0, nil
+}
+
+func (s *memStore) EnqueueScheduled(_ context.Context, name string, now, next time.Time, job InsertParams) (bool, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	nextRunAt, ok := s.schedules[name]
+	if !ok || nextRunAt.After(next) {
+		s.schedules[name] = next
+		nextRunAt = next
+	}
+	if nextRunAt.After(now) {
+		return false, nil
+	}
+	s.schedules[name] = next
+	_, inserted, err := s.insertLocked(job)
+	return inserted, err
+}
+
+func (s *memStore) job(id int64) memJob {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return *s.jobs[id]
+}
+
+// waitFor は cond が true になるまで待つ
+func waitFor(t *testing.T, what string, cond func() bool) {
+	t.Helper()
+	deadline := time.Now().Add(5 * time.Second)
+	for !cond() {
+		if time.Now().After(deadline) {
+			t.Fatalf("timed out waiting for %s", what)
+		}
+		time.Sleep(5 * time.Millisecond)
+	}
+}
+
+type greetArgs struct {
+	Name string `json:"name"`
+}
+
+func (greetArgs) Kind() string { return "test.greet" }
+
+func newTestRunner(store Store) *Runner {
+	logger := slog.New(slog.NewTextHandler(io.Discard, nil))
+	return NewRunner(store, logger, RunnerConfig{ID: "test", Workers: 2, PollInterval: 10 * time.Millisecond})
+}
+
+func TestRunner_CompletesJob(t *testing.T) {
+	store := newMemStore()
+	runner := newTestRunner(store)
+	got := make(chan string, 1)
+	Register(runner, func(_ context.Context, job *Job[greetArgs]) error {
+		got <- job.Args.Name
+		return nil
+	}, HandlerOptions{})
+
+	id, inserted, err := NewClient(store).Enqueue(context.Background(), greetArgs{Name: "alice"}, nil)
+	if err != nil || !inserted {
+		t.Fatalf("Enqueue() = %d, %v, %v", id, inserted, err)
+	}
+	runner.Start(context.Background())
+	defer runner.Stop(context.Background())
+
+	if name := <-got; name != "alice" {
+		t.Errorf("handler got args %q, want alice", name)
+	}
+	waitFor(t, "job to complete", func() bool { return store.job(id).state == "completed" })
+}
+
+func TestRunner_RetriesThenFails(t *testing.T) {
+	store := newMemStore()
+	runner := newTestRunner(store)
+	var mu sync.Mutex
+	var attempts []int
+	Register(runner, func(_ context.Context, job *Job[greetArgs]) error {
+		mu.Lock()
+		attempts = append(attempts, job.Attempt)
+		mu.Unlock()
+		return errors.New("boom")
+	}, HandlerOptions{Backoff: func(int) time.Duration { return 0 }})
+
+	id, _, err := NewClient(store).Enqueue(context.Background(), greetArgs{}, &InsertOptions{MaxAttempts: 3})
+	if err != nil {
+		t.Fatal(err)
+	}
+	runner.Start(context.Background())
+	defer runner.Stop(context.Background())
+
+	waitFor(t, "job to fail", func() bool { return store.job(id).state == "failed" })
+	mu.Lock()
+	defer mu.Unlock()
+	if len(attempts) != 3 || attempts[0] != 1 || attempts[2] != 3 {
+		t.Errorf("handler ran attempts %v, want [1 2 3]", attempts)
+	}
+	if got := store.job(id).lastError; got != "boom" {
+		t.Errorf("last error = %q, want boom", got)
+	}
+}
+
+func TestRunner_PermanentErrorAndPanic(t *testing.T) {
+	store := newMemStore()
+	runner := newTestRunner(store)
+	Register(runner, func(_ context.Context, job *Job[greetArgs]) error {
+		if job.Args.Name == "panic" {
+			panic("unexpected")
+		}
+		return Permanent(errors.New("invalid name"))
+	}, HandlerOptions{Backoff: func(int) time.Duration { return time.Hour }})
+
+	client := NewClient(store)
+	permanentID, _, _ := client.Enqueue(context.Background(), greetArgs{Name: "x"}, nil)
+	panicID, _, _ := client.Enqueue(context.Background(), greetArgs{Name: "panic"}, nil)
+	runner.Start(context.Background())
+	defer runner.Stop(context.Background())
+
+	waitFor(t, "permanent error to fail the job", func() bool { return store.job(permanentID).state == "failed" })
+	if got := store.job(permanentID).attempt; got != 1 {
+		t.Errorf("permanent error ran %d attempts, want 1", got)
+	}
+	// panic は再試行する (バックオフが 1 時間のため実行待ちのまま)
+	waitFor(t, "panicked job to be retried", func() bool {
+		j := store.job(panicID)
+		return j.state == "available" && j.attempt == 1
+	})
+}
+
+func TestRunner_StopReleasesInterruptedJobs(t *testing.T) {
+	store := newMemStore()
+	runner := newTestRunner(store)
+	started := make(chan struct{})
+	Register(runner, func(ctx context.Context, _ *Job[greetArgs]) error {
+		close(started)
+		<-ctx.Done()
+		return ctx.Err()
+	}, HandlerOptions{})
+
+	id, _, _ := NewClient(store).Enqueue(context.Background(), greetArgs{}, nil)
+	runner.Start(context.Background())
+	<-started
+
+	ctx, cancel := context.WithTimeout(context.Background(), 20*time.Millisecond)
+	defer cancel()
+	if err := runner.Stop(ctx); err == nil {
+		t.Error("Stop() returned no error while a job was running")
+	}
+	j := store.job(id)
+	if j.state != "available" || j.attempt != 0 {
+		t.Errorf("interrupted job state = %s, attempt = %d, want available, 0", j.state, j.attempt)
+	}
+}
+
+func TestClient_UniqueKey(t *testing.T) {
+	store := newMemStore()
+	client := NewClient(store)
+	opts := &InsertOptions{UniqueKey: "greet:alice"}
+	if _, inserted, _ := client.Enqueue(context.Background(), greetArgs{Name: "alice"}, opts); !inserted {
+		t.Fatal("first Enqueue() was not inserted")
+	}
+	if _, inserted, _ := client.Enqueue(context.Background(), greetArgs{Name: "alice"}, opts); inserted {
+		t.Error("Enqueue() with the same unique key was inserted")
+	}
+}
+
+func TestRunner_EnqueuesPeriodicJobOncePerRun(t *testing.T) {
+	store := newMemStore()
+	// 2 つのインスタンスが同じスケジュールを確認しても、実行日時ごとに1件のみ追加する
+	runners := []*Runner{newTestRunner(store), newTestRunner(store)}
+	for _, r := range runners {
+		r.AddPeriodic(PeriodicJob{Name: "greet", Schedule: Every(time.Hour), Args: greetArgs{Name: "periodic"}})
+	}
+
+	now := time.Now()
+	for _, r := range runners {
+		r.enqueuePeriodic(context.Background())
+	}
+	if len(store.jobs) != 0 {
+		t.Fatalf("enqueued %d jobs before the first run, want 0", len(store.jobs))
+	}
+
+	// 実行日時を過ぎたことにする
+	store.schedules["greet"] = now.Add(-time.Second)
+	for _, r := range runners {
+		r.enqueuePeriodic(context.Background())
+	}
+	if len(store.jobs) != 1 {
+		t.Fatalf("enqueued %d jobs, want 1", len(store.jobs))
+	}
+	if got := store.job(1).UniqueKey; got != "periodic:greet" {
+		t.Errorf("periodic job unique key = %q, want periodic:greet", got)
+	}
+	if next := store.schedules["greet"]; !next.After(now) {
+		t.Errorf("next run = %s, want after %s", next, now)
+	}
+}
@@ -0,0 +1,75 @@
+package jobs
+
+import (
+	"testing"
+	"time"
+)
+
+func TestParseSchedule_Next(t *testing.T) {
+	jst := time.FixedZone("Asia/Tokyo", 9*60*60)
+	after := time.Date(2025, 5, 17, 10, 30, 15, 0, jst) // 土曜日
+
+	tests := []struct {
+		spec string
+		want time.Time
+	}{
+		{spec: "@every 1h", want: time.Date(2025, 5, 17, 11, 0, 0, 0, jst)},
+		{spec: "@every 15m", want: time.Date(2025, 5, 17, 10, 45, 0, 0, jst)},
+		{spec: "@hourly", want: time.Date(2025, 5, 17, 11, 0, 0, 0, jst)},
+		{spec: "@daily", want: time.Date(2025, 5, 18, 0, 0, 0, 0, jst)},
+		{spec: "@weekly", want: time.Date(2025, 5, 18, 0, 0, 0, 0, jst)},
+		{spec: "@monthly", want: time.Date(2025, 6, 1, 0, 0, 0, 0, jst)},
+		{spec: "* * * * *", want: time.Date(2025, 5, 17, 10, 31, 0, 0, jst)},
+		{spec: "*/20 * * * *", want: time.Date(2025, 5, 17, 10, 40, 0, 0, jst)},
+		{spec: "5/20 * * * *", want: time.Date(2025, 5, 17, 10, 45, 0, 0, jst)},
+		{spec: "0 3 * * *", want: time.Date(2025, 5, 18, 3, 0, 0, 0, jst)},
+		{spec: "30 9 * * 1-5", want: time.Date(2025, 5, 19, 9, 30, 0, 0, jst)},
+		{spec: "0 0 * * 7", want: time.Date(2025, 5, 18, 0, 0, 0, 0, jst)},
+		{spec: "0 12 1,15 * *", want: time.Date(2025, 6, 1, 12, 0, 0, 0, jst)},
+		// 日と曜日の両方を指定した場合はどちらかに一致する日
+		{spec: "0 12 1 * 1", want: time.Date(2025, 5, 19, 12, 0, 0, 0, jst)},
+		{spec: "0 0 29 2 *", want: time.Date(2028, 2, 29, 0, 0, 0, 0, jst)},
+		{spec: "0 0 30 2 *", want: time.Time{}},
+	}
+	for _, tt := range tests {
+		schedule, err := ParseSchedule(tt.spec, jst)
+		if err != nil {
+			t.Errorf("ParseSchedule(%q) returned error: %v", tt.spec, err)
+			continue
+		}
+		if got := schedule.Next(after); !got.Equal(tt.want) {
+			t.Errorf("ParseSchedule(%q).Next(%s) = %s, want %s", tt.spec, after, got, tt.want)
+		}
+	}
+}
+
+func TestParseSchedule_Invalid(t *testing.T) {
+	for _, spec := range []string{
+		"",
+		"@every",
+		"@every -1m",
+		"@every soon",
+		"* * * *",
+		"60 * * * *",
+		"* 24 * * *",
+		"* * 0 * *",
+		"* * * 13 *",
+		"* * * * 8",
+		"5-1 * * * *",
+		"*/0 * * * *",
+		"a * * * *",
+	} {
+		if _, err := ParseSchedule(spec, time.UTC); err == nil {
+			t.Errorf("ParseSchedule(%q) returned no error", spec)
+		}
+	}
+}
+
+func TestEvery_SameAcrossInstances(t *testing.T) {
+	schedule := Every(24 * time.Hour)
+	a := schedule.Next(time.Date(2025, 5, 17, 1, 0, 0, 0, time.UTC))
+	b := schedule.Next(time.Date(2025, 5, 17, 23, 59, 0, 0, time.UTC))
+	if !a.Equal(b) || !a.Equal(time.Date(2025, 5, 18, 0, 0, 0, 0, time.UTC)) {
+		t.Errorf("Every(24h).Next() = %s and %s, want both 2025-05-18T00:00:00Z", a, b)
+	}
+}
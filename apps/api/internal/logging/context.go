@@ -0,0 +1,74 @@
+// Package logging はリクエスト単位の属性の付与・機密情報の伏字化・パッケージごとのログレベルを扱う slog.Handler を提供する
+package logging
+
+import (
+	"context"
+	"log/slog"
+	"sync"
+)
+
+type contextKey struct{}
+
+// requestAttrs はリクエストの間にログに付ける属性 (リクエストID・ユーザーIDなど)
+// 認証などのミドルウェアが後から追加した属性を、外側のアクセスログでも参照できるよう共有する
+type requestAttrs struct {
+	mu    sync.Mutex
+	attrs []slog.Attr
+}
+
+// WithRequestAttrs はログに付ける属性を保持するコンテキストを返す (リクエストの開始時に一度だけ呼び出す)
+func WithRequestAttrs(ctx context.Context, attrs ...slog.Attr) context.Context {
+	return context.WithValue(ctx, contextKey{}, &requestAttrs{attrs: attrs})
+}
+
+// AddAttrs はリクエストのログに付ける属性を追加する (同じキーの属性は置き換える)
+// WithRequestAttrs で作成したコンテキストでない場合は何もしない
+func AddAttrs(ctx context.Context, attrs ...slog.Attr) {
+	ra, ok := ctx.Value(contextKey{}).(*requestAttrs)
+	if !ok {
+		return
+	}
+	ra.mu.Lock()
+	defer ra.mu.Unlock()
+	for _, attr := range attrs {
+		replaced := false
+		for i := range ra.attrs {
+			if ra.attrs[i].Key == attr.Key {
+				ra.attrs[i] = attr
+				replaced = true
+				break
+			}
+		}
+		if !replaced {
+			ra.attrs = append(ra.attrs, attr)
+		}
+	}
+}
+
+// Attrs はリクエストのログに付ける属性を返す
+func Attrs(ctx context.Context) []slog.Attr {
+	ra, ok := ctx.Value(contextKey{}).(*requestAttrs)
+	if !ok {
+		return nil
+	}
+	ra.mu.Lock()
+	defer ra.mu.Unlock()
+	return append([]slog.Attr(nil), ra.attrs...)
+}
+
+// RequestID はリクエストIDを返す (リクエストの外では空文字列)
+func RequestID(ctx context.Context) string {
+	for _, attr := range Attrs(ctx) {
+		if attr.Key == RequestIDKey {
+			return attr.Value.String()
+		}
+	}
+	return ""
+}
+
+// ログの属性のキー
+const (
+	RequestIDKey   = "request_id"
+	UserIDKey      = "user_id"       // データの所有者 (コーチのアクセスではアスリート)
+	CoachUserIDKey = "coach_user_id" // アスリートのデータにアクセスしているコーチ
+)
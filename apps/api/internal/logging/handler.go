@@ -0,0 +1,57 @@
+package logging
+
+import (
+	"context"
+	"log/slog"
+)
+
+// Handler はリクエストの属性 (リクエストID・ユーザーID) をログに付け、パッケージごとのレベルで絞り込み、
+// 機密情報や大きな値を伏字にする slog.Handler
+// 伏字にした後のログを h に渡すため、h がスパンに記録する場合 (telemetry.LogHandler) も伏字になる
+type Handler struct {
+	handler slog.Handler
+	levels  *Levels
+	policy  *RedactPolicy
+}
+
+// NewHandler は h をラップした Handler を作成する (h のレベルは levels の最も低いレベル以下にしておくこと)
+func NewHandler(h slog.Handler, levels *Levels, policy *RedactPolicy) *Handler {
+	return &Handler{handler: h, levels: levels, policy: policy}
+}
+
+// Enabled はいずれかのパッケージでそのレベルのログが出力される場合に true を返す
+func (h *Handler) Enabled(ctx context.Context, level slog.Level) bool {
+	return level >= h.levels.Minimum() && h.handler.Enabled(ctx, level)
+}
+
+// Handle はログを出力したパッケージのレベル以上の場合に、リクエストの属性を付けて出力する
+func (h *Handler) Handle(ctx context.Context, r slog.Record) error {
+	if r.Level < h.levels.forPC(r.PC) {
+		return nil
+	}
+	// 属性を伏字にした新しいレコードを作成する
+	// ログ自体に同じキーの属性がある場合はリクエストの属性より優先する (サービスのログの user_id など)
+	keys := make(map[string]bool, r.NumAttrs())
+	record := slog.NewRecord(r.Time, r.Level, r.Message, r.PC)
+	r.Attrs(func(a slog.Attr) bool {
+		keys[a.Key] = true
+		record.AddAttrs(h.policy.redact(a))
+		return true
+	})
+	for _, attr := range Attrs(ctx) {
+		if !keys[attr.Key] {
+			record.AddAttrs(attr)
+		}
+	}
+	return h.handler.Handle(ctx, record)
+}
+
+// WithAttrs は属性を追加した Handler を返す
+func (h *Handler) WithAttrs(attrs []slog.Attr) slog.Handler {
+	return &Handler{handler: h.handler.WithAttrs(h.policy.redactAll(attrs)), levels: h.levels, policy: h.policy}
+}
+
+// WithGroup はグループを追加した Handler を返す
+func (h *Handler) WithGroup(name string) slog.Handler {
+	return &Handler{handler: h.handler.WithGroup(name), levels: h.levels, policy: h.policy}
+}
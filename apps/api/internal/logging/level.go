@@ -0,0 +1,89 @@
+package logging
+
+import (
+	"fmt"
+	"log/slog"
+	"runtime"
+	"strings"
+	"sync"
+)
+
+// Levels はパッケージごとのログレベル
+// パッケージは import パスの末尾で指定する (例: "service" は internal/service と internal/interfaces/service の両方、
+// "interfaces/service" は後者のみ)。複数一致する場合は長い方を使う
+type Levels struct {
+	Default  slog.Level
+	Packages map[string]slog.Level
+
+	cache sync.Map // PC -> slog.Level
+}
+
+// ParseLevels は LOG_LEVEL (既定のレベル) と LOG_LEVELS (パッケージごとのレベル、例: "service=DEBUG,middleware=WARN") を読み込む
+func ParseLevels(defaultLevel, packageLevels string) (*Levels, error) {
+	levels := &Levels{Default: slog.LevelInfo, Packages: map[string]slog.Level{}}
+	if defaultLevel != "" {
+		if err := levels.Default.UnmarshalText([]byte(defaultLevel)); err != nil {
+			return nil, fmt.Errorf("invalid LOG_LEVEL %q: %w", defaultLevel, err)
+		}
+	}
+	for _, entry := range strings.Split(packageLevels, ",") {
+		entry = strings.TrimSpace(entry)
+		if entry == "" {
+			continue
+		}
+		pkg, level, ok := strings.Cut(entry, "=")
+		pkg = strings.Trim(strings.TrimSpace(pkg), "/")
+		if !ok || pkg == "" {
+			return nil, fmt.Errorf("invalid LOG_LEVELS entry %q: want <package>=<level>", entry)
+		}
+		var l slog.Level
+		if err := l.UnmarshalText([]byte(strings.TrimSpace(level))); err != nil {
+			return nil, fmt.Errorf("invalid LOG_LEVELS entry %q: %w", entry, err)
+		}
+		levels.Packages[pkg] = l
+	}
+	return levels, nil
+}
+
+// Minimum はいずれかのパッケージで出力される最も低いレベルを返す
+func (l *Levels) Minimum() slog.Level {
+	minimum := l.Default
+	for _, level := range l.Packages {
+		minimum = min(minimum, level)
+	}
+	return minimum
+}
+
+// forPC はログを出力した関数のパッケージのレベルを返す
+func (l *Levels) forPC(pc uintptr) slog.Level {
+	if len(l.Packages) == 0 || pc == 0 {
+		return l.Default
+	}
+	if level, ok := l.cache.Load(pc); ok {
+		return level.(slog.Level)
+	}
+	frame, _ := runtime.CallersFrames([]uintptr{pc}).Next()
+	level := l.forPackage(packagePath(frame.Function))
+	l.cache.Store(pc, level)
+	return level
+}
+
+// forPackage はパッケージの import パスに一致するレベルを返す
+func (l *Levels) forPackage(path string) slog.Level {
+	level, matched := l.Default, ""
+	for pkg, pkgLevel := range l.Packages {
+		if (path == pkg || strings.HasSuffix(path, "/"+pkg)) && len(pkg) > len(matched) {
+			level, matched = pkgLevel, pkg
+		}
+	}
+	return level
+}
+
+// packagePath は関数名 (例: "github.com/x/api/internal/service.(*VolumeService).Get") から import パスを取り出す
+func packagePath(function string) string {
+	slash := strings.LastIndex(function, "/")
+	if dot := strings.Index(function[slash+1:], "."); dot >= 0 {
+		return function[:slash+1+dot]
+	}
+	return function
+}
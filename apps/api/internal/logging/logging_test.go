@@ -0,0 +1,154 @@
+package logging
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"log/slog"
+	"strings"
+	"testing"
+)
+
+func newTestLogger(t *testing.T, levels *Levels, policy *RedactPolicy) (*slog.Logger, *bytes.Buffer) {
+	t.Helper()
+	var buf bytes.Buffer
+	h := slog.NewJSONHandler(&buf, &slog.HandlerOptions{Level: levels.Minimum()})
+	return slog.New(NewHandler(h, levels, policy)), &buf
+}
+
+func decodeEntries(t *testing.T, buf *bytes.Buffer) []map[string]any {
+	t.Helper()
+	var entries []map[string]any
+	for _, line := range bytes.Split(bytes.TrimSpace(buf.Bytes()), []byte("\n")) {
+		if len(line) == 0 {
+			continue
+		}
+		var entry map[string]any
+		if err := json.Unmarshal(line, &entry); err != nil {
+			t.Fatalf("invalid log output %q: %v", line, err)
+		}
+		entries = append(entries, entry)
+	}
+	return entries
+}
+
+func TestHandlerRequestAttrs(t *testing.T) {
+	logger, buf := newTestLogger(t, &Levels{}, nil)
+
+	ctx := WithRequestAttrs(context.Background(), slog.String(RequestIDKey, "req-1"))
+	// 認証のミドルウェアは内側のコンテキストで属性を追加する
+	AddAttrs(context.WithValue(ctx, struct{}{}, nil), slog.String(UserIDKey, "user_a"))
+
+	logger.InfoContext(ctx, "Request processed")
+	logger.InfoContext(ctx, "Recalculated", slog.String(UserIDKey, "user_b"))
+	logger.Info("No context")
+
+	entries := decodeEntries(t, buf)
+	if len(entries) != 3 {
+		t.Fatalf("entries = %d, want 3", len(entries))
+	}
+	if entries[0][RequestIDKey] != "req-1" || entries[0][UserIDKey] != "user_a" {
+		t.Errorf("entry = %v, want request_id req-1 and user_id user_a", entries[0])
+	}
+	if entries[1][UserIDKey] != "user_b" {
+		t.Errorf("user_id = %v, want the record's own value user_b", entries[1][UserIDKey])
+	}
+	if _, ok := entries[2][RequestIDKey]; ok {
+		t.Errorf("entry without request context has request_id: %v", entries[2])
+	}
+	if got := RequestID(ctx); got != "req-1" {
+		t.Errorf("RequestID() = %q, want %q", got, "req-1")
+	}
+}
+
+func TestHandlerRedact(t *testing.T) {
+	logger, buf := newTestLogger(t, &Levels{}, NewRedactPolicy(nil, 8))
+
+	logger.With(slog.String("Authorization", "Bearer abc")).Info("StartWorkout request body",
+		slog.String("body", `{"note":"private"}`),
+		slog.String("menu_name", "0123456789"),
+		slog.Group("req", slog.String("token", "bt_pat_x")),
+	)
+
+	entry := decodeEntries(t, buf)[0]
+	if got := entry["Authorization"]; got != "[REDACTED 10 bytes]" {
+		t.Errorf("Authorization = %v, want redacted", got)
+	}
+	if got := entry["body"]; got != "[REDACTED 18 bytes]" {
+		t.Errorf("body = %v, want redacted", got)
+	}
+	if got := entry["menu_name"]; got != "01234567...[TRUNCATED 2 bytes]" {
+		t.Errorf("menu_name = %v, want truncated", got)
+	}
+	if got := entry["req"].(map[string]any)["token"]; got != "[REDACTED 8 bytes]" {
+		t.Errorf("req.token = %v, want redacted", got)
+	}
+	if strings.Contains(buf.String(), "private") {
+		t.Errorf("log output contains a redacted value: %s", buf.String())
+	}
+}
+
+func TestParseLevels(t *testing.T) {
+	levels, err := ParseLevels("WARN", "service=DEBUG, interfaces/service=ERROR")
+	if err != nil {
+		t.Fatalf("ParseLevels() error = %v", err)
+	}
+	tests := []struct {
+		path string
+		want slog.Level
+	}{
+		{path: "github.com/aiirononeko/bulktrack/apps/api/internal/service", want: slog.LevelDebug},
+		{path: "github.com/aiirononeko/bulktrack/apps/api/internal/interfaces/service", want: slog.LevelError},
+		{path: "github.com/aiirononeko/bulktrack/apps/api/internal/myservice", want: slog.LevelWarn},
+		{path: "github.com/aiirononeko/bulktrack/apps/api/internal/handler", want: slog.LevelWarn},
+	}
+	for _, tt := range tests {
+		if got := levels.forPackage(tt.path); got != tt.want {
+			t.Errorf("forPackage(%q) = %v, want %v", tt.path, got, tt.want)
+		}
+	}
+	if got := levels.Minimum(); got != slog.LevelDebug {
+		t.Errorf("Minimum() = %v, want %v", got, slog.LevelDebug)
+	}
+
+	for _, invalid := range []string{"service", "service=LOUD", "=DEBUG"} {
+		if _, err := ParseLevels("", invalid); err == nil {
+			t.Errorf("ParseLevels(%q) error = nil, want error", invalid)
+		}
+	}
+}
+
+func TestHandlerPackageLevels(t *testing.T) {
+	// このテストのパッケージ (internal/logging) のみ Debug にする
+	levels, err := ParseLevels("INFO", "logging=DEBUG,service=ERROR")
+	if err != nil {
+		t.Fatalf("ParseLevels() error = %v", err)
+	}
+	logger, buf := newTestLogger(t, levels, nil)
+	logger.Debug("debug from logging")
+
+	if entries := decodeEntries(t, buf); len(entries) != 1 {
+		t.Fatalf("entries = %d, want 1", len(entries))
+	}
+
+	levels.Packages = map[string]slog.Level{"logging": slog.LevelWarn}
+	levels.cache.Clear()
+	buf.Reset()
+	logger.Info("info from logging")
+	if buf.Len() != 0 {
+		t.Errorf("info log was written with logging=WARN: %s", buf.String())
+	}
+}
+
+func TestPackagePath(t *testing.T) {
+	tests := map[string]string{
+		"github.com/aiirononeko/bulktrack/apps/api/internal/interfaces/service.(*WorkoutService).StartWorkout": "github.com/aiirononeko/bulktrack/apps/api/internal/interfaces/service",
+		"github.com/aiirononeko/bulktrack/apps/api/internal/logging.TestPackagePath.func1":                     "github.com/aiirononeko/bulktrack/apps/api/internal/logging",
+		"main.main": "main",
+	}
+	for function, want := range tests {
+		if got := packagePath(function); got != want {
+			t.Errorf("packagePath(%q) = %q, want %q", function, got, want)
+		}
+	}
+}
@@ -0,0 +1,69 @@
+package logging
+
+import (
+	"fmt"
+	"log/slog"
+	"strings"
+)
+
+// DefaultRedactKeys は値を出力しない属性のキー (認証情報・個人情報・リクエストやレスポンスの本文)
+var DefaultRedactKeys = []string{
+	"authorization", "cookie", "password", "secret", "token", "api_key", "email",
+	"body", "request_body", "response_json",
+}
+
+// RedactPolicy はログに出力しない値の方針 (伏字にした値は長さのみ出力する、例: "[REDACTED 120 bytes]")
+type RedactPolicy struct {
+	MaxValueBytes int // 文字列の値の最大バイト数 (0 以下の場合は切り詰めない)
+
+	keys map[string]bool // 値を伏字にするキー (小文字)
+}
+
+// NewRedactPolicy は keys (LOG_REDACT_KEYS) の値を伏字にし、文字列を maxValueBytes で切り詰める方針を返す
+// keys が空の場合は DefaultRedactKeys を使う (ローカルで本文を確認したい場合は LOG_REDACT_KEYS=authorization,token などにする)
+func NewRedactPolicy(keys []string, maxValueBytes int) *RedactPolicy {
+	if len(keys) == 0 {
+		keys = DefaultRedactKeys
+	}
+	p := &RedactPolicy{MaxValueBytes: maxValueBytes, keys: make(map[string]bool, len(keys))}
+	for _, key := range keys {
+		p.keys[strings.ToLower(key)] = true // 大文字小文字は区別しない
+	}
+	return p
+}
+
+// redact は属性の値を方針に従って伏字にする (グループの中の属性も対象にする)
+func (p *RedactPolicy) redact(a slog.Attr) slog.Attr {
+	if p == nil {
+		return a
+	}
+	a.Value = a.Value.Resolve()
+	if p.keys[strings.ToLower(a.Key)] {
+		if a.Value.Kind() == slog.KindString {
+			return slog.String(a.Key, fmt.Sprintf("[REDACTED %d bytes]", len(a.Value.String())))
+		}
+		return slog.String(a.Key, "[REDACTED]")
+	}
+	switch a.Value.Kind() {
+	case slog.KindString:
+		if s := a.Value.String(); p.MaxValueBytes > 0 && len(s) > p.MaxValueBytes {
+			return slog.String(a.Key, fmt.Sprintf("%s...[TRUNCATED %d bytes]", strings.ToValidUTF8(s[:p.MaxValueBytes], ""), len(s)-p.MaxValueBytes))
+		}
+	case slog.KindGroup:
+		group := a.Value.Group()
+		attrs := make([]slog.Attr, len(group))
+		for i, ga := range group {
+			attrs[i] = p.redact(ga)
+		}
+		return slog.Attr{Key: a.Key, Value: slog.GroupValue(attrs...)}
+	}
+	return a
+}
+
+func (p *RedactPolicy) redactAll(attrs []slog.Attr) []slog.Attr {
+	redacted := make([]slog.Attr, len(attrs))
+	for i, a := range attrs {
+		redacted[i] = p.redact(a)
+	}
+	return redacted
+}
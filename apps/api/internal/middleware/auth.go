@@ -3,18 +3,14 @@ package middleware
 import (
 	"context"
 	"crypto/rsa"
-	"crypto/x509"
 	"encoding/base64"
 	"encoding/json"
-	"encoding/pem"
 	"errors"
 	"fmt"
 	"log/slog"
 	"net/http"
 	"strings"
 	"time"
-
-	"github.com/aiirononeko/bulktrack/apps/api/internal/config"
 )
 
 // contextKey は文字列をラップしたコンテキストキー型
@@ -147,56 +143,6 @@ func verifySignature(signedData, signature string, publicKey *rsa.PublicKey) boo
 	return true
 }
 
-// getPublicKey は公開鍵を取得する
-// 注: 本番環境では環境変数やJWKSエンドポイントから取得するなど適切に設定する
-func getPublicKey() *rsa.PublicKey {
-	// テスト用のダミー公開鍵
-	publicKeyPEM := `
------BEGIN PUBLIC KEY-----
-MIIBIjANBgkqhkiG9w0BAQEFAAOCAQ8AMIIBCgKCAQEAnzyis1ZjfNB0bBgKFMSv
-vkTtwlvBsaJq7S5wA+kzeVOVpVWwkWdVha4s38XM/pa/yr47av7+z3VTmvDRyAHc
-aT92whREFpLv9cj5lTeJSibyr/Mrm/YtjCZVWgaOYIhwrXwKLqPr/11inWsAkfIy
-tvHWTxZYEcXLgAXFuUuaS3uF9gEiNQwzGTU1v0FqkqTBr4B8nW3HCN47XUu0t8Y0
-e+lf4s4OxQawWD79J9/5d3Ry0vbV3Am1FtGJiJvOwRsIfVChDpYStTcHTCMqtvWb
-V6L11BWkpzGXSW4Hv43qa+GSYOD2QU68Mb59oSk2OB+BtOLpJofmbGEGgvmwyCI9
-MwIDAQAB
------END PUBLIC KEY-----`
-
-	block, _ := pem.Decode([]byte(publicKeyPEM))
-	if block == nil {
-		return nil
-	}
-
-	publicKey, err := x509.ParsePKIXPublicKey(block.Bytes)
-	if err != nil {
-		return nil
-	}
-
-	rsaPublicKey, ok := publicKey.(*rsa.PublicKey)
-	if !ok {
-		return nil
-	}
-
-	return rsaPublicKey
-}
-
-// Auth はJWTによる認証を行うミドルウェア
-func Auth(cfg *config.Config, logger *slog.Logger) func(next http.Handler) http.Handler {
-	// JWTの設定
-	jwtConfig := &JWTConfig{
-		// 本番環境では環境変数から公開鍵を取得するなど適切に設定する
-		PublicKey: getPublicKey(),
-		Issuer:    "https://clerk.bulktrack.example.com",
-		Audience:  "bulktrack-api",
-		ClockSkew: 2 * time.Minute,
-	}
-
-	// デフォルトのトークン検証器
-	verifier := &DefaultTokenVerifier{}
-
-	return AuthWithVerifier(jwtConfig, verifier, logger)
-}
-
 // AuthWithVerifier はカスタムのトークン検証器を使用する認証ミドルウェア
 func AuthWithVerifier(jwtConfig *JWTConfig, verifier TokenVerifier, logger *slog.Logger) func(next http.Handler) http.Handler {
 	return func(next http.Handler) http.Handler {
@@ -0,0 +1,287 @@
+// Package migrate はバージョン付きの SQL マイグレーションを適用する
+//
+// 適用済みのバージョンは schema_migrations テーブルに記録する。各マイグレーションは1つのトランザクションで適用し、
+// 複数のインスタンスが同時に実行しても競合しないよう、実行中は Postgres のアドバイザリーロックを保持する
+package migrate
+
+import (
+	"cmp"
+	"context"
+	"errors"
+	"fmt"
+	"io/fs"
+	"log/slog"
+	"regexp"
+	"slices"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgxpool"
+)
+
+// lockKey はマイグレーションの実行中に保持するアドバイザリーロックのキー
+const lockKey int64 = 0x62756c6b74726b // "bulktrk"
+
+// ErrNoDownMigration はロールバックする SQL がないマイグレーションを戻そうとした場合のエラー
+var ErrNoDownMigration = errors.New("migration has no down migration")
+
+// Migration は1つのバージョンのマイグレーション
+type Migration struct {
+	Version int64
+	Name    string
+	Up      string
+	Down    string // 空の場合はロールバックできない
+}
+
+// Status はマイグレーションの適用状況
+type Status struct {
+	Migration
+	AppliedAt *time.Time // 未適用の場合は nil
+}
+
+var fileNamePattern = regexp.MustCompile(`^(\d+)_([a-z0-9_]+)\.(up|down)\.sql$`)
+
+// Load は fsys の直下のマイグレーションのファイル (<バージョン>_<名前>.up.sql / .down.sql) をバージョン順に読み込む
+func Load(fsys fs.FS) ([]Migration, error) {
+	entries, err := fs.ReadDir(fsys, ".")
+	if err != nil {
+		return nil, fmt.Errorf("failed to read migrations: %w", err)
+	}
+
+	byVersion := map[int64]*Migration{}
+	for _, entry := range entries {
+		if entry.IsDir() {
+			continue
+		}
+		match := fileNamePattern.FindStringSubmatch(entry.Name())
+		if match == nil {
+			if strings.HasSuffix(entry.Name(), ".sql") {
+				return nil, fmt.Errorf("invalid migration file name %q: want <version>_<name>.up.sql or .down.sql", entry.Name())
+			}
+			continue
+		}
+		version, err := strconv.ParseInt(match[1], 10, 64)
+		if err != nil {
+			return nil, fmt.Errorf("invalid migration version in %q: %w", entry.Name(), err)
+		}
+		body, err := fs.ReadFile(fsys, entry.Name())
+		if err != nil {
+			return nil, fmt.Errorf("failed to read migration %q: %w", entry.Name(), err)
+		}
+
+		m, ok := byVersion[version]
+		if !ok {
+			m = &Migration{Version: version, Name: match[2]}
+			byVersion[version] = m
+		} else if m.Name != match[2] {
+			return nil, fmt.Errorf("duplicate migration version %d: %s and %s", version, m.Name, match[2])
+		}
+		if match[3] == "up" {
+			m.Up = string(body)
+		} else {
+			m.Down = string(body)
+		}
+	}
+
+	migrations := make([]Migration, 0, len(byVersion))
+	for _, m := range byVersion {
+		if m.Up == "" {
+			return nil, fmt.Errorf("migration %d_%s has no up migration", m.Version, m.Name)
+		}
+		migrations = append(migrations, *m)
+	}
+	slices.SortFunc(migrations, func(a, b Migration) int { return cmp.Compare(a.Version, b.Version) })
+	return migrations, nil
+}
+
+// Migrator はマイグレーションをデータベースに適用する
+type Migrator struct {
+	pool       *pgxpool.Pool
+	migrations []Migration
+	logger     *slog.Logger
+}
+
+// New は新しい Migrator を作成する (migrations はバージョン順であること)
+func New(pool *pgxpool.Pool, migrations []Migration, logger *slog.Logger) *Migrator {
+	return &Migrator{pool: pool, migrations: migrations, logger: logger}
+}
+
+// Up は未適用のマイグレーションをすべて適用し、適用した件数を返す
+func (m *Migrator) Up(ctx context.Context) (applied int, err error) {
+	err = m.withLock(ctx, func(conn *pgx.Conn) error {
+		done, err := appliedVersions(ctx, conn)
+		if err != nil {
+			return err
+		}
+		for _, migration := range m.migrations {
+			if _, ok := done[migration.Version]; ok {
+				continue
+			}
+			if err := m.apply(ctx, conn, migration, true); err != nil {
+				return err
+			}
+			applied++
+		}
+		return nil
+	})
+	return applied, err
+}
+
+// Down は適用済みのマイグレーションを新しい方から steps 件ロールバックし、ロールバックした件数を返す
+func (m *Migrator) Down(ctx context.Context, steps int) (reverted int, err error) {
+	err = m.withLock(ctx, func(conn *pgx.Conn) error {
+		done, err := appliedVersions(ctx, conn)
+		if err != nil {
+			return err
+		}
+		for i := len(m.migrations) - 1; i >= 0 && reverted < steps; i-- {
+			migration := m.migrations[i]
+			if _, ok := done[migration.Version]; !ok {
+				continue
+			}
+			if migration.Down == "" {
+				return fmt.Errorf("%d_%s: %w", migration.Version, migration.Name, ErrNoDownMigration)
+			}
+			if err := m.apply(ctx, conn, migration, false); err != nil {
+				return err
+			}
+			reverted++
+		}
+		return nil
+	})
+	return reverted, err
+}
+
+// Baseline は version 以下のマイグレーションを実行せずに適用済みとして記録する
+// マイグレーションの導入前に schema.sql で作成したデータベースで一度だけ使う
+func (m *Migrator) Baseline(ctx context.Context, version int64) (marked int, err error) {
+	if !slices.ContainsFunc(m.migrations, func(migration Migration) bool { return migration.Version == version }) {
+		return 0, fmt.Errorf("unknown migration version %d", version)
+	}
+	err = m.withLock(ctx, func(conn *pgx.Conn) error {
+		for _, migration := range m.migrations {
+			if migration.Version > version {
+				break
+			}
+			tag, err := conn.Exec(ctx, "INSERT INTO schema_migrations (version, name) VALUES ($1, $2) ON CONFLICT (version) DO NOTHING", migration.Version, migration.Name)
+			if err != nil {
+				return fmt.Errorf("failed to mark migration %d_%s as applied: %w", migration.Version, migration.Name, err)
+			}
+			marked += int(tag.RowsAffected())
+		}
+		return nil
+	})
+	return marked, err
+}
+
+// Status はマイグレーションごとの適用状況を返す
+func (m *Migrator) Status(ctx context.Context) ([]Status, error) {
+	var statuses []Status
+	err := m.withLock(ctx, func(conn *pgx.Conn) error {
+		done, err := appliedVersions(ctx, conn)
+		if err != nil {
+			return err
+		}
+		for _, migration := range m.migrations {
+			status := Status{Migration: migration}
+			if appliedAt, ok := done[migration.Version]; ok {
+				status.AppliedAt = &appliedAt
+			}
+			statuses = append(statuses, status)
+		}
+		return nil
+	})
+	return statuses, err
+}
+
+// withLock はアドバイザリーロックを取得した接続で fn を実行する (他のインスタンスの実行が終わるまで待つ)
+func (m *Migrator) withLock(ctx context.Context, fn func(conn *pgx.Conn) error) error {
+	conn, err := m.pool.Acquire(ctx)
+	if err != nil {
+		return fmt.Errorf("failed to acquire connection: %w", err)
+	}
+	defer conn.Release()
+
+	if _, err := conn.Exec(ctx, "SELECT pg_advisory_lock($1)", lockKey); err != nil {
+		return fmt.Errorf("failed to acquire migration lock: %w", err)
+	}
+	defer func() {
+		// 取得したロックは接続を返す前に解放する (キャンセルされていても解放する)
+		if _, err := conn.Exec(context.WithoutCancel(ctx), "SELECT pg_advisory_unlock($1)", lockKey); err != nil {
+			m.logger.ErrorContext(ctx, "Failed to release migration lock", slog.Any("error", err))
+		}
+	}()
+
+	if _, err := conn.Exec(ctx, `CREATE TABLE IF NOT EXISTS schema_migrations (
+  version    BIGINT PRIMARY KEY,
+  name       TEXT NOT NULL,
+  applied_at TIMESTAMPTZ NOT NULL DEFAULT now()
+)`); err != nil {
+		return fmt.Errorf("failed to create schema_migrations: %w", err)
+	}
+	return fn(conn.Conn())
+}
+
+// apply はマイグレーションを1つのトランザクションで適用 (up) またはロールバック (down) する
+func (m *Migrator) apply(ctx context.Context, conn *pgx.Conn, migration Migration, up bool) (err error) {
+	direction, sql := "up", migration.Up
+	if !up {
+		direction, sql = "down", migration.Down
+	}
+	start := time.Now()
+
+	tx, err := conn.Begin(ctx)
+	if err != nil {
+		return fmt.Errorf("failed to begin transaction: %w", err)
+	}
+	defer func() {
+		if err != nil {
+			tx.Rollback(ctx)
+		}
+	}()
+
+	// 引数なしの Exec は simple protocol で実行されるため、複数の文を含む SQL をそのまま実行できる
+	if _, err = tx.Exec(ctx, sql); err != nil {
+		return fmt.Errorf("failed to apply migration %d_%s (%s): %w", migration.Version, migration.Name, direction, err)
+	}
+	if up {
+		_, err = tx.Exec(ctx, "INSERT INTO schema_migrations (version, name) VALUES ($1, $2)", migration.Version, migration.Name)
+	} else {
+		_, err = tx.Exec(ctx, "DELETE FROM schema_migrations WHERE version = $1", migration.Version)
+	}
+	if err != nil {
+		return fmt.Errorf("failed to record migration %d_%s: %w", migration.Version, migration.Name, err)
+	}
+	if err = tx.Commit(ctx); err != nil {
+		return fmt.Errorf("failed to commit migration %d_%s: %w", migration.Version, migration.Name, err)
+	}
+
+	m.logger.InfoContext(ctx, "Applied migration",
+		slog.Int64("version", migration.Version),
+		slog.String("name", migration.Name),
+		slog.String("direction", direction),
+		slog.Duration("duration", time.Since(start)))
+	return nil
+}
+
+// appliedVersions は適用済みのバージョンと適用日時を返す
+func appliedVersions(ctx context.Context, conn *pgx.Conn) (map[int64]time.Time, error) {
+	rows, err := conn.Query(ctx, "SELECT version, applied_at FROM schema_migrations")
+	if err != nil {
+		return nil, fmt.Errorf("failed to list applied migrations: %w", err)
+	}
+	applied := map[int64]time.Time{}
+	var (
+		version   int64
+		appliedAt time.Time
+	)
+	if _, err := pgx.ForEachRow(rows, []any{&version, &appliedAt}, func() error {
+		applied[version] = appliedAt
+		return nil
+	}); err != nil {
+		return nil, fmt.Errorf("failed to list applied migrations: %w", err)
+	}
+	return applied, nil
+}
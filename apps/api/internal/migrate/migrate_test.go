@@ -0,0 +1,232 @@
+package migrate
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"log/slog"
+	"os"
+	"regexp"
+	"slices"
+	"strings"
+	"testing"
+	"testing/fstest"
+	"time"
+
+	"github.com/aiirononeko/bulktrack/apps/api/migrations"
+	"github.com/jackc/pgx/v5/pgxpool"
+)
+
+func TestLoad(t *testing.T) {
+	fsys := fstest.MapFS{
+		"20250502_second.up.sql":   {Data: []byte("CREATE TABLE b ();")},
+		"20250501_first.up.sql":    {Data: []byte("CREATE TABLE a ();")},
+		"20250501_first.down.sql":  {Data: []byte("DROP TABLE a;")},
+		"README.md":                {Data: []byte("not a migration")},
+		"20250503_third.up.sql":    {Data: []byte("CREATE TABLE c ();")},
+		"20250503_third.down.sql":  {Data: []byte("DROP TABLE c;")},
+		"20250502_second.down.sql": {Data: []byte("DROP TABLE b;")},
+	}
+	got, err := Load(fsys)
+	if err != nil {
+		t.Fatalf("Load() error = %v", err)
+	}
+	want := []Migration{
+		{Version: 20250501, Name: "first", Up: "CREATE TABLE a ();", Down: "DROP TABLE a;"},
+		{Version: 20250502, Name: "second", Up: "CREATE TABLE b ();", Down: "DROP TABLE b;"},
+		{Version: 20250503, Name: "third", Up: "CREATE TABLE c ();", Down: "DROP TABLE c;"},
+	}
+	if !slices.Equal(got, want) {
+		t.Errorf("Load() = %+v, want %+v", got, want)
+	}
+}
+
+func TestLoadInvalid(t *testing.T) {
+	tests := map[string]fstest.MapFS{
+		"invalid name":      {"create_menus.sql": {Data: []byte("SELECT 1;")}},
+		"duplicate version": {"20250501_a.up.sql": {Data: []byte("SELECT 1;")}, "20250501_b.up.sql": {Data: []byte("SELECT 1;")}},
+		"down without up":   {"20250501_a.down.sql": {Data: []byte("SELECT 1;")}},
+	}
+	for name, fsys := range tests {
+		if _, err := Load(fsys); err == nil {
+			t.Errorf("%s: Load() error = nil, want error", name)
+		}
+	}
+}
+
+// TestEmbeddedMigrations は埋め込んだマイグレーションがすべて読み込め、ロールバックできることを確認する
+func TestEmbeddedMigrations(t *testing.T) {
+	all, err := Load(migrations.FS)
+	if err != nil {
+		t.Fatalf("Load() error = %v", err)
+	}
+	if len(all) == 0 {
+		t.Fatal("no migrations are embedded")
+	}
+	for _, m := range all {
+		if strings.TrimSpace(m.Down) == "" {
+			t.Errorf("migration %d_%s has no down migration", m.Version, m.Name)
+		}
+	}
+}
+
+// TestMigrationsMatchSchema はすべてのマイグレーションを適用したスキーマが schema.sql と一致することを確認する
+// (sqlc は schema.sql からコードを生成するため)。すべてロールバックしてから再適用できることも確認する
+//
+// TEST_DATABASE_URL (データベースを作成できるユーザー) が設定されている場合のみ実行する
+func TestMigrationsMatchSchema(t *testing.T) {
+	databaseURL := os.Getenv("TEST_DATABASE_URL")
+	if databaseURL == "" {
+		t.Skip("TEST_DATABASE_URL is not set")
+	}
+	ctx := context.Background()
+	logger := slog.New(slog.NewTextHandler(io.Discard, nil))
+
+	all, err := Load(migrations.FS)
+	if err != nil {
+		t.Fatalf("Load() error = %v", err)
+	}
+	schemaSQL, err := os.ReadFile("../infrastructure/db/schema.sql")
+	if err != nil {
+		t.Fatalf("failed to read schema.sql: %v", err)
+	}
+
+	migrated := createTestDatabase(t, databaseURL, "migrated")
+	migrator := New(migrated, all, logger)
+	if applied, err := migrator.Up(ctx); err != nil || applied != len(all) {
+		t.Fatalf("Up() = %d, %v, want %d, nil", applied, err, len(all))
+	}
+	migratedSchema := schemaSnapshot(t, migrated)
+
+	fromSchema := createTestDatabase(t, databaseURL, "schema")
+	if _, err := fromSchema.Exec(ctx, string(schemaSQL)); err != nil {
+		t.Fatalf("failed to apply schema.sql: %v", err)
+	}
+	if diff := diffSnapshots(migratedSchema, schemaSnapshot(t, fromSchema)); diff != "" {
+		t.Errorf("schema after migrations differs from schema.sql (- migrations, + schema.sql):\n%s", diff)
+	}
+
+	// すべてロールバックしてから再適用する
+	if reverted, err := migrator.Down(ctx, len(all)); err != nil || reverted != len(all) {
+		t.Fatalf("Down() = %d, %v, want %d, nil", reverted, err, len(all))
+	}
+	if applied, err := migrator.Up(ctx); err != nil || applied != len(all) {
+		t.Fatalf("Up() after Down() = %d, %v, want %d, nil", applied, err, len(all))
+	}
+	if diff := diffSnapshots(migratedSchema, schemaSnapshot(t, migrated)); diff != "" {
+		t.Errorf("schema after down and up differs (- first up, + second up):\n%s", diff)
+	}
+
+	// 二度目の Up は何もしない
+	if applied, err := migrator.Up(ctx); err != nil || applied != 0 {
+		t.Errorf("Up() when up to date = %d, %v, want 0, nil", applied, err)
+	}
+}
+
+// createTestDatabase はテスト用のデータベースを作成し、テストの終了時に削除する
+func createTestDatabase(t *testing.T, databaseURL, suffix string) *pgxpool.Pool {
+	t.Helper()
+	ctx := context.Background()
+	admin, err := pgxpool.New(ctx, databaseURL)
+	if err != nil {
+		t.Fatalf("failed to connect to TEST_DATABASE_URL: %v", err)
+	}
+	t.Cleanup(admin.Close)
+
+	name := fmt.Sprintf("bulktrack_test_%d_%s", time.Now().UnixNano(), suffix)
+	if _, err := admin.Exec(ctx, "CREATE DATABASE "+name); err != nil {
+		t.Fatalf("failed to create database: %v", err)
+	}
+	config, err := pgxpool.ParseConfig(databaseURL)
+	if err != nil {
+		t.Fatalf("failed to parse TEST_DATABASE_URL: %v", err)
+	}
+	config.ConnConfig.Database = name
+	pool, err := pgxpool.NewWithConfig(ctx, config)
+	if err != nil {
+		t.Fatalf("failed to connect to %s: %v", name, err)
+	}
+	t.Cleanup(func() {
+		pool.Close()
+		if _, err := admin.Exec(ctx, "DROP DATABASE IF EXISTS "+name); err != nil {
+			t.Errorf("failed to drop database %s: %v", name, err)
+		}
+	})
+	return pool
+}
+
+// snapshotQueries は public スキーマのテーブル・列・制約・インデックス・関数・トリガーの定義を1行ずつ返すクエリ
+// 列の順番と制約の名前は比較しない (ALTER TABLE で追加した列は末尾になり、名前は定義の書き方によって変わるため)
+var snapshotQueries = []string{
+	`SELECT 'table ' || c.relname || ' persistence=' || c.relpersistence
+	 FROM pg_class c
+	 WHERE c.relnamespace = 'public'::regnamespace AND c.relkind = 'r' AND c.relname <> 'schema_migrations'`,
+	`SELECT 'column ' || c.relname || '.' || a.attname || ' ' || format_type(a.atttypid, a.atttypmod) ||
+	        CASE WHEN a.attnotnull THEN ' NOT NULL' ELSE '' END ||
+	        COALESCE(' DEFAULT ' || pg_get_expr(d.adbin, d.adrelid), '')
+	 FROM pg_attribute a
+	 JOIN pg_class c ON c.oid = a.attrelid
+	 LEFT JOIN pg_attrdef d ON d.adrelid = a.attrelid AND d.adnum = a.attnum
+	 WHERE c.relnamespace = 'public'::regnamespace AND c.relkind = 'r' AND c.relname <> 'schema_migrations'
+	 AND a.attnum > 0 AND NOT a.attisdropped`,
+	`SELECT 'constraint ' || conrelid::regclass::text || ' ' || pg_get_constraintdef(oid)
+	 FROM pg_constraint
+	 WHERE connamespace = 'public'::regnamespace AND conrelid::regclass::text <> 'schema_migrations'`,
+	`SELECT 'index ' || indexdef
+	 FROM pg_indexes
+	 WHERE schemaname = 'public' AND tablename <> 'schema_migrations'`,
+	`SELECT 'function ' || p.proname || '(' || pg_get_function_identity_arguments(p.oid) || ') ' ||
+	        pg_get_function_result(p.oid) || ' ' || l.lanname || ' volatile=' || p.provolatile || ' ' || p.prosrc
+	 FROM pg_proc p
+	 JOIN pg_language l ON l.oid = p.prolang
+	 WHERE p.pronamespace = 'public'::regnamespace`,
+	`SELECT 'trigger ' || pg_get_triggerdef(oid)
+	 FROM pg_trigger
+	 WHERE NOT tgisinternal`,
+}
+
+var (
+	sqlCommentPattern = regexp.MustCompile(`--[^\n]*`)
+	whitespacePattern = regexp.MustCompile(`\s+`)
+)
+
+// schemaSnapshot はスキーマの定義を並べ替えて返す (関数の本体のコメントと空白は無視する)
+func schemaSnapshot(t *testing.T, pool *pgxpool.Pool) []string {
+	t.Helper()
+	var lines []string
+	for _, query := range snapshotQueries {
+		rows, err := pool.Query(context.Background(), query)
+		if err != nil {
+			t.Fatalf("failed to query schema: %v", err)
+		}
+		for rows.Next() {
+			var line string
+			if err := rows.Scan(&line); err != nil {
+				t.Fatalf("failed to scan schema: %v", err)
+			}
+			line = sqlCommentPattern.ReplaceAllString(line, "")
+			lines = append(lines, strings.TrimSpace(whitespacePattern.ReplaceAllString(line, " ")))
+		}
+		if err := rows.Err(); err != nil {
+			t.Fatalf("failed to query schema: %v", err)
+		}
+	}
+	slices.Sort(lines)
+	return lines
+}
+
+// diffSnapshots は一方にしかない行を返す (一致する場合は空文字列)
+func diffSnapshots(a, b []string) string {
+	var diff strings.Builder
+	for _, line := range a {
+		if _, found := slices.BinarySearch(b, line); !found {
+			fmt.Fprintf(&diff, "- %s\n", line)
+		}
+	}
+	for _, line := range b {
+		if _, found := slices.BinarySearch(a, line); !found {
+			fmt.Fprintf(&diff, "+ %s\n", line)
+		}
+	}
+	return diff.String()
+}
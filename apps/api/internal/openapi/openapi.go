@@ -0,0 +1,199 @@
+// Package openapi は OpenAPI 3.1 のドキュメントを表し、Go の型からスキーマを生成する
+//
+// スキーマは DTO の構造体から encoding/json と同じ規則 (json タグ・omitempty・埋め込み) で生成するため、
+// DTO を変更するとドキュメントも変わる。Validate と ValidateResponse はレスポンスがドキュメントに従っているかを確認する (契約テスト用)
+package openapi
+
+import (
+	"encoding/json"
+	"reflect"
+	"slices"
+	"strings"
+)
+
+// Version は生成するドキュメントの OpenAPI のバージョン
+const Version = "3.1.0"
+
+// Document は OpenAPI のドキュメントを表す
+type Document struct {
+	OpenAPI    string              `json:"openapi"`
+	Info       Info                `json:"info"`
+	Servers    []Server            `json:"servers,omitempty"`
+	Tags       []Tag               `json:"tags,omitempty"`
+	Paths      map[string]PathItem `json:"paths"`
+	Components Components          `json:"components"`
+
+	// schemaNames は components の schemas に追加した型と名前 (SchemaOf が使う)
+	schemaNames map[reflect.Type]string
+}
+
+// Info はドキュメントの API の情報を表す
+type Info struct {
+	Title       string `json:"title"`
+	Version     string `json:"version"`
+	Description string `json:"description,omitempty"`
+}
+
+// Server は API の URL を表す
+type Server struct {
+	URL         string `json:"url"`
+	Description string `json:"description,omitempty"`
+}
+
+// Tag は操作の分類を表す
+type Tag struct {
+	Name        string `json:"name"`
+	Description string `json:"description,omitempty"`
+}
+
+// PathItem はパスの操作を HTTP メソッド (小文字) ごとに表す
+type PathItem map[string]*Operation
+
+// Operation はパスとメソッドの組 (ServeMux のパターン) の操作を表す
+type Operation struct {
+	OperationID string                `json:"operationId"`
+	Summary     string                `json:"summary,omitempty"`
+	Description string                `json:"description,omitempty"`
+	Tags        []string              `json:"tags,omitempty"`
+	Parameters  []*Parameter          `json:"parameters,omitempty"`
+	RequestBody *RequestBody          `json:"requestBody,omitempty"`
+	Responses   map[string]*Response  `json:"responses"`
+	Security    []SecurityRequirement `json:"security,omitempty"`
+	Deprecated  bool                  `json:"deprecated,omitempty"`
+}
+
+// Parameter はパス・クエリ・ヘッダーのパラメーターを表す
+type Parameter struct {
+	Name        string  `json:"name"`
+	In          string  `json:"in"` // path / query / header
+	Description string  `json:"description,omitempty"`
+	Required    bool    `json:"required,omitempty"`
+	Schema      *Schema `json:"schema"`
+}
+
+// RequestBody はリクエストのボディを表す
+type RequestBody struct {
+	Description string                `json:"description,omitempty"`
+	Required    bool                  `json:"required,omitempty"`
+	Content     map[string]*MediaType `json:"content"`
+}
+
+// Response はステータスコードごとのレスポンスを表す
+type Response struct {
+	Description string                `json:"description"`
+	Headers     map[string]*Header    `json:"headers,omitempty"`
+	Content     map[string]*MediaType `json:"content,omitempty"`
+}
+
+// Header はレスポンスのヘッダーを表す
+type Header struct {
+	Description string  `json:"description,omitempty"`
+	Schema      *Schema `json:"schema"`
+}
+
+// MediaType はメディアタイプごとのボディのスキーマを表す
+type MediaType struct {
+	Schema *Schema `json:"schema,omitempty"`
+}
+
+// Components はドキュメントから参照するスキーマと認証方式を表す
+type Components struct {
+	Schemas         map[string]*Schema         `json:"schemas,omitempty"`
+	SecuritySchemes map[string]*SecurityScheme `json:"securitySchemes,omitempty"`
+}
+
+// SecurityScheme は認証方式を表す
+type SecurityScheme struct {
+	Type         string `json:"type"` // http / apiKey など
+	Scheme       string `json:"scheme,omitempty"`
+	BearerFormat string `json:"bearerFormat,omitempty"`
+	Description  string `json:"description,omitempty"`
+}
+
+// SecurityRequirement は操作に必要な認証方式と、そのスコープ (OpenAPI 3.1 では http の認証方式にも指定できる) を表す
+type SecurityRequirement map[string][]string
+
+// Schema は JSON Schema (OpenAPI 3.1 で使うもの) を表す
+type Schema struct {
+	Ref                  string             `json:"$ref,omitempty"`
+	Type                 Types              `json:"type,omitempty"`
+	Format               string             `json:"format,omitempty"`
+	Description          string             `json:"description,omitempty"`
+	Enum                 []string           `json:"enum,omitempty"`
+	Minimum              *float64           `json:"minimum,omitempty"`
+	Maximum              *float64           `json:"maximum,omitempty"`
+	Properties           map[string]*Schema `json:"properties,omitempty"`
+	Required             []string           `json:"required,omitempty"`
+	AdditionalProperties *Schema            `json:"additionalProperties,omitempty"`
+	Items                *Schema            `json:"items,omitempty"`
+	AnyOf                []*Schema          `json:"anyOf,omitempty"`
+}
+
+// Types は JSON Schema の type を表す (1つの場合は文字列、null を許す場合などは配列で出力する)
+type Types []string
+
+// MarshalJSON は type を1つの場合は文字列、複数の場合は配列で出力する
+func (t Types) MarshalJSON() ([]byte, error) {
+	if len(t) == 1 {
+		return json.Marshal(t[0])
+	}
+	return json.Marshal([]string(t))
+}
+
+// UnmarshalJSON は文字列と配列のどちらの type も読み込む
+func (t *Types) UnmarshalJSON(data []byte) error {
+	var single string
+	if err := json.Unmarshal(data, &single); err == nil {
+		*t = Types{single}
+		return nil
+	}
+	var multiple []string
+	if err := json.Unmarshal(data, &multiple); err != nil {
+		return err
+	}
+	*t = multiple
+	return nil
+}
+
+// New は空のドキュメントを作成する
+func New(info Info) *Document {
+	return &Document{
+		OpenAPI: Version,
+		Info:    info,
+		Paths:   make(map[string]PathItem),
+		Components: Components{
+			Schemas:         make(map[string]*Schema),
+			SecuritySchemes: make(map[string]*SecurityScheme),
+		},
+		schemaNames: make(map[reflect.Type]string),
+	}
+}
+
+// AddOperation はパターン ("GET /menus/{id}" のような ServeMux のパターン) の操作を追加する
+func (d *Document) AddOperation(pattern string, op *Operation) {
+	method, path, _ := strings.Cut(pattern, " ")
+	item, ok := d.Paths[path]
+	if !ok {
+		item = make(PathItem)
+		d.Paths[path] = item
+	}
+	item[strings.ToLower(method)] = op
+}
+
+// Operation はパターンの操作を返す (ない場合は nil)
+func (d *Document) Operation(pattern string) *Operation {
+	method, path, _ := strings.Cut(pattern, " ")
+	return d.Paths[path][strings.ToLower(method)]
+}
+
+// Patterns はドキュメントのすべての操作のパターン ("GET /menus/{id}" の形式) を並べ替えて返す
+func (d *Document) Patterns() []string {
+	var patterns []string
+	for path, item := range d.Paths {
+		for method := range item {
+			patterns = append(patterns, strings.ToUpper(method)+" "+path)
+		}
+	}
+	slices.Sort(patterns)
+	return patterns
+}
@@ -0,0 +1,194 @@
+package openapi
+
+import (
+	"encoding/json"
+	"net/http"
+	"reflect"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+type testItem struct {
+	Name string `json:"name"`
+}
+
+type testBase struct {
+	ID        uuid.UUID `json:"id"`
+	CreatedAt string    `json:"created_at" format:"date-time"`
+}
+
+type testView struct {
+	testBase
+	Kind       string              `json:"kind" enum:"a,b"`
+	Count      int32               `json:"count"`
+	Total      int64               `json:"total"`
+	Ratio      float64             `json:"ratio"`
+	Done       bool                `json:"done"`
+	Note       *string             `json:"note,omitempty"`
+	Parent     *testItem           `json:"parent"`
+	Items      []testItem          `json:"items"`
+	Days       []string            `json:"days" format:"date"`
+	Labels     map[string]testItem `json:"labels,omitempty"`
+	UpdatedAt  time.Time           `json:"updated_at"`
+	Args       json.RawMessage     `json:"args"`
+	Ignored    string              `json:"-"`
+	unexported string
+}
+
+func TestSchemaOf(t *testing.T) {
+	d := New(Info{Title: "test", Version: "1"})
+	got := d.SchemaOf(reflect.TypeFor[testView]())
+	if got.Ref != "#/components/schemas/testView" {
+		t.Fatalf("SchemaOf() = %+v, want a reference to testView", got)
+	}
+
+	view := d.Components.Schemas["testView"]
+	wantRequired := []string{"id", "created_at", "kind", "count", "total", "ratio", "done", "parent", "items", "days", "updated_at", "args"}
+	if !reflect.DeepEqual(view.Required, wantRequired) {
+		t.Errorf("Required = %v, want %v", view.Required, wantRequired)
+	}
+	if _, ok := view.Properties["Ignored"]; ok {
+		t.Error(`json:"-" field is documented`)
+	}
+
+	tests := map[string]string{
+		"id":         `{"type":"string","format":"uuid"}`,
+		"created_at": `{"type":"string","format":"date-time"}`,
+		"kind":       `{"type":"string","enum":["a","b"]}`,
+		"count":      `{"type":"integer","format":"int32"}`,
+		"total":      `{"type":"integer","format":"int64"}`,
+		"ratio":      `{"type":"number"}`,
+		"done":       `{"type":"boolean"}`,
+		"note":       `{"type":["string","null"]}`,
+		"parent":     `{"anyOf":[{"$ref":"#/components/schemas/testItem"},{"type":"null"}]}`,
+		"items":      `{"type":"array","items":{"$ref":"#/components/schemas/testItem"}}`,
+		"days":       `{"type":"array","items":{"type":"string","format":"date"}}`,
+		"labels":     `{"type":"object","additionalProperties":{"$ref":"#/components/schemas/testItem"}}`,
+		"updated_at": `{"type":"string","format":"date-time"}`,
+		"args":       `{}`,
+	}
+	for name, want := range tests {
+		data, err := json.Marshal(view.Properties[name])
+		if err != nil {
+			t.Fatalf("failed to marshal %s: %v", name, err)
+		}
+		if string(data) != want {
+			t.Errorf("property %s = %s, want %s", name, data, want)
+		}
+	}
+	if len(view.Properties) != len(tests) {
+		t.Errorf("got %d properties, want %d", len(view.Properties), len(tests))
+	}
+	if d.Components.Schemas["testItem"] == nil {
+		t.Error("testItem is not added to components")
+	}
+}
+
+func TestValidate(t *testing.T) {
+	d := New(Info{Title: "test", Version: "1"})
+	schema := d.SchemaOf(reflect.TypeFor[testView]())
+	valid := `{"id":"0b9c1f0e-2a3b-4c5d-8e9f-0a1b2c3d4e5f","created_at":"2025-05-12T09:00:00+09:00","kind":"a","count":3,"total":10,
+		"ratio":1,"done":false,"parent":null,"items":[{"name":"x"}],"days":["2025-05-12"],"updated_at":"2025-05-12T00:00:00Z","args":{"any":[1]}}`
+	if err := d.Validate(schema, []byte(valid)); err != nil {
+		t.Fatalf("Validate(valid) error = %v", err)
+	}
+
+	tests := map[string]struct {
+		replace [2]string
+		want    string
+	}{
+		"date-time as date":   {[2]string{`"2025-05-12T09:00:00+09:00"`, `"2025-05-12"`}, "$.created_at"},
+		"invalid uuid":        {[2]string{`"0b9c1f0e-2a3b-4c5d-8e9f-0a1b2c3d4e5f"`, `"menu-1"`}, "$.id"},
+		"not in enum":         {[2]string{`"kind":"a"`, `"kind":"c"`}, "$.kind"},
+		"number as integer":   {[2]string{`"count":3`, `"count":3.5`}, "$.count"},
+		"null array":          {[2]string{`"items":[{"name":"x"}]`, `"items":null`}, "$.items"},
+		"nested type":         {[2]string{`{"name":"x"}`, `{"name":1}`}, "$.items[0].name"},
+		"missing required":    {[2]string{`"done":false,`, ``}, "required property done"},
+		"undocumented":        {[2]string{`"done":false`, `"done":false,"extra":1`}, "$.extra"},
+		"invalid nested date": {[2]string{`["2025-05-12"]`, `["2025/05/12"]`}, "$.days[0]"},
+	}
+	for name, tt := range tests {
+		data := strings.Replace(valid, tt.replace[0], tt.replace[1], 1)
+		err := d.Validate(schema, []byte(data))
+		if err == nil || !strings.Contains(err.Error(), tt.want) {
+			t.Errorf("%s: Validate() error = %v, want an error about %s", name, err, tt.want)
+		}
+	}
+}
+
+func TestValidateResponse(t *testing.T) {
+	d := New(Info{Title: "test", Version: "1"})
+	d.AddOperation("GET /items/{id}", &Operation{
+		OperationID: "getItem",
+		Parameters:  []*Parameter{{Name: "id", In: "path", Required: true, Schema: &Schema{Type: Types{"string"}}}},
+		Responses: map[string]*Response{
+			"200": {Description: "OK", Content: map[string]*MediaType{"application/json": {Schema: d.SchemaOf(reflect.TypeFor[testItem]())}}},
+			"204": {Description: "No Content"},
+			"401": {Description: "Unauthorized", Content: map[string]*MediaType{"text/plain": {Schema: &Schema{Type: Types{"string"}}}}},
+		},
+	})
+	d.AddOperation("DELETE /items/{id}", &Operation{
+		OperationID: "deleteItem",
+		Parameters:  []*Parameter{{Name: "id", In: "path", Required: true, Schema: &Schema{Type: Types{"string"}}}},
+		Responses: map[string]*Response{
+			"204":     {Description: "No Content"},
+			"default": {Description: "Error", Content: map[string]*MediaType{"text/plain": {Schema: &Schema{Type: Types{"string"}}}}},
+		},
+	})
+	if err := d.Check(); err != nil {
+		t.Fatalf("Check() error = %v", err)
+	}
+
+	jsonHeader := http.Header{"Content-Type": {"application/json; charset=utf-8"}}
+	textHeader := http.Header{"Content-Type": {"text/plain; charset=utf-8"}}
+	tests := []struct {
+		name    string
+		pattern string
+		status  int
+		header  http.Header
+		body    string
+		wantErr bool
+	}{
+		{"json", "GET /items/{id}", 200, jsonHeader, `{"name":"x"}` + "\n", false},
+		{"text", "GET /items/{id}", 401, textHeader, "Unauthorized\n", false},
+		{"no content", "GET /items/{id}", 204, http.Header{}, "", false},
+		{"invalid body", "GET /items/{id}", 200, jsonHeader, `{"name":null}`, true},
+		{"undocumented content type", "GET /items/{id}", 401, jsonHeader, `{}`, true},
+		{"undocumented status", "GET /items/{id}", 500, textHeader, "error", true},
+		{"body without content", "GET /items/{id}", 204, textHeader, "unexpected", true},
+		{"default error", "DELETE /items/{id}", 500, textHeader, "error", false},
+		{"default for success", "DELETE /items/{id}", 200, textHeader, "ok", true},
+		{"undocumented operation", "PUT /items/{id}", 204, http.Header{}, "", true},
+	}
+	for _, tt := range tests {
+		err := d.ValidateResponse(tt.pattern, tt.status, tt.header, []byte(tt.body))
+		if (err != nil) != tt.wantErr {
+			t.Errorf("%s: ValidateResponse() error = %v, wantErr %v", tt.name, err, tt.wantErr)
+		}
+	}
+}
+
+func TestCheck(t *testing.T) {
+	d := New(Info{Title: "test", Version: "1"})
+	d.AddOperation("GET /items/{id}", &Operation{
+		OperationID: "getItem",
+		Responses: map[string]*Response{
+			"200": {Description: "OK", Content: map[string]*MediaType{"application/json": {Schema: &Schema{Ref: schemaRefPrefix + "missing"}}}},
+		},
+		Security: []SecurityRequirement{{"bearer": nil}},
+	})
+	d.AddOperation("DELETE /items/{id}", &Operation{OperationID: "getItem"})
+
+	err := d.Check()
+	if err == nil {
+		t.Fatal("Check() error = nil, want errors")
+	}
+	for _, want := range []string{"path parameter id", "unresolved reference", "security scheme bearer", "operationId getItem", "no responses"} {
+		if !strings.Contains(err.Error(), want) {
+			t.Errorf("Check() error = %v, want an error about %s", err, want)
+		}
+	}
+}
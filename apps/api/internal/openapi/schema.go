@@ -0,0 +1,178 @@
+package openapi
+
+import (
+	"encoding"
+	"encoding/json"
+	"fmt"
+	"path"
+	"reflect"
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// schemaRefPrefix は components の schemas への参照の接頭辞
+const schemaRefPrefix = "#/components/schemas/"
+
+var (
+	timeType          = reflect.TypeFor[time.Time]()
+	uuidType          = reflect.TypeFor[uuid.UUID]()
+	rawMessageType    = reflect.TypeFor[json.RawMessage]()
+	jsonMarshalerType = reflect.TypeFor[json.Marshaler]()
+	textMarshalerType = reflect.TypeFor[encoding.TextMarshaler]()
+)
+
+// SchemaOf は t の値を encoding/json で出力した JSON のスキーマを返す
+//
+// 名前のある構造体は components の schemas に追加して参照 ($ref) を返す。
+// フィールドは json タグに従い、omitempty のないフィールドを required とする (ポインタのフィールドは null を許す)。
+// 文字列の形式と値は format タグ (例: format:"date-time") と enum タグ (例: enum:"day,week") で指定する
+func (d *Document) SchemaOf(t reflect.Type) *Schema {
+	switch t {
+	case timeType:
+		return &Schema{Type: Types{"string"}, Format: "date-time"}
+	case uuidType:
+		return &Schema{Type: Types{"string"}, Format: "uuid"}
+	case rawMessageType:
+		return &Schema{} // 任意の JSON
+	}
+
+	switch t.Kind() {
+	case reflect.Pointer:
+		return nullable(d.SchemaOf(t.Elem()))
+	case reflect.Interface:
+		return &Schema{}
+	}
+	if t.Implements(jsonMarshalerType) || reflect.PointerTo(t).Implements(jsonMarshalerType) {
+		return &Schema{}
+	}
+	if t.Implements(textMarshalerType) || reflect.PointerTo(t).Implements(textMarshalerType) {
+		return &Schema{Type: Types{"string"}}
+	}
+
+	switch t.Kind() {
+	case reflect.Bool:
+		return &Schema{Type: Types{"boolean"}}
+	case reflect.Int8, reflect.Int16, reflect.Int32, reflect.Uint8, reflect.Uint16, reflect.Uint32:
+		return &Schema{Type: Types{"integer"}, Format: "int32"}
+	case reflect.Int, reflect.Int64, reflect.Uint, reflect.Uint64:
+		return &Schema{Type: Types{"integer"}, Format: "int64"}
+	case reflect.Float32, reflect.Float64:
+		return &Schema{Type: Types{"number"}}
+	case reflect.String:
+		return &Schema{Type: Types{"string"}}
+	case reflect.Slice, reflect.Array:
+		if t.Elem().Kind() == reflect.Uint8 {
+			return &Schema{Type: Types{"string"}, Format: "byte"} // encoding/json は []byte を base64 で出力する
+		}
+		return &Schema{Type: Types{"array"}, Items: d.SchemaOf(t.Elem())}
+	case reflect.Map:
+		if t.Key().Kind() != reflect.String {
+			panic(fmt.Sprintf("openapi: unsupported map key type %s", t.Key()))
+		}
+		return &Schema{Type: Types{"object"}, AdditionalProperties: d.SchemaOf(t.Elem())}
+	case reflect.Struct:
+		if t.Name() == "" {
+			return d.structSchema(t)
+		}
+		return d.ref(t)
+	}
+	panic(fmt.Sprintf("openapi: unsupported type %s", t))
+}
+
+// ref は構造体を components の schemas に追加し (追加済みの場合は追加しない)、参照を返す
+func (d *Document) ref(t reflect.Type) *Schema {
+	name, ok := d.schemaNames[t]
+	if !ok {
+		name = t.Name()
+		if _, taken := d.Components.Schemas[name]; taken {
+			// 他のパッケージの同じ名前の型
+			name = path.Base(t.PkgPath()) + "." + name
+		}
+		d.schemaNames[t] = name
+		d.Components.Schemas[name] = nil // 自身を参照する型のため、フィールドより先に登録する
+		d.Components.Schemas[name] = d.structSchema(t)
+	}
+	return &Schema{Ref: schemaRefPrefix + name}
+}
+
+// structSchema は構造体のスキーマを返す
+func (d *Document) structSchema(t reflect.Type) *Schema {
+	s := &Schema{Type: Types{"object"}, Properties: make(map[string]*Schema)}
+	d.addFields(s, t)
+	return s
+}
+
+// addFields は構造体のフィールドを encoding/json と同じ規則でスキーマのプロパティに追加する (埋め込んだ構造体のフィールドは展開する)
+func (d *Document) addFields(s *Schema, t reflect.Type) {
+	for i := range t.NumField() {
+		field := t.Field(i)
+		tag := field.Tag.Get("json")
+		if tag == "-" {
+			continue
+		}
+		name, options, _ := strings.Cut(tag, ",")
+		if field.Anonymous && name == "" {
+			embedded := field.Type
+			if embedded.Kind() == reflect.Pointer {
+				embedded = embedded.Elem()
+			}
+			if embedded.Kind() == reflect.Struct {
+				d.addFields(s, embedded)
+				continue
+			}
+		}
+		if !field.IsExported() {
+			continue
+		}
+		if name == "" {
+			name = field.Name
+		}
+
+		property := d.SchemaOf(field.Type)
+		applyStringTags(property, field.Tag)
+		s.Properties[name] = property
+		if !hasOption(options, "omitempty") && !hasOption(options, "omitzero") {
+			s.Required = append(s.Required, name)
+		}
+	}
+}
+
+// applyStringTags はフィールドの format タグと enum タグを文字列 (文字列の配列の場合は要素) のスキーマに設定する
+func applyStringTags(s *Schema, tag reflect.StructTag) {
+	if s.Items != nil {
+		s = s.Items
+	}
+	if format, ok := tag.Lookup("format"); ok {
+		s.Format = format
+	}
+	if enum, ok := tag.Lookup("enum"); ok {
+		s.Enum = strings.Split(enum, ",")
+	}
+}
+
+// nullable は s に null を加えたスキーマを返す
+func nullable(s *Schema) *Schema {
+	switch {
+	case s.Ref != "":
+		return &Schema{AnyOf: []*Schema{s, {Type: Types{"null"}}}}
+	case len(s.Type) == 0:
+		return s // 任意の JSON (null を含む)
+	}
+	n := *s
+	n.Type = append(Types{}, s.Type...)
+	n.Type = append(n.Type, "null")
+	return &n
+}
+
+func hasOption(options, option string) bool {
+	for options != "" {
+		var o string
+		o, options, _ = strings.Cut(options, ",")
+		if o == option {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,296 @@
+package openapi
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"mime"
+	"net/http"
+	"regexp"
+	"slices"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// Validate は JSON の data が schema に従っているかを確認する
+// スキーマにないプロパティも違反とする (DTO の変更がドキュメントに反映されていることを確認するため)
+func (d *Document) Validate(schema *Schema, data []byte) error {
+	decoder := json.NewDecoder(bytes.NewReader(data))
+	decoder.UseNumber()
+	var v any
+	if err := decoder.Decode(&v); err != nil {
+		return fmt.Errorf("invalid JSON: %w", err)
+	}
+	if decoder.More() {
+		return errors.New("invalid JSON: multiple values")
+	}
+	return d.validate(schema, v, "$")
+}
+
+// ValidateResponse はパターンの操作のレスポンス (ステータスコード・Content-Type・ボディ) がドキュメントに従っているかを確認する
+// default のレスポンスはエラー (400 以上) のステータスコードにのみ使う (成功のステータスコードは明示したものだけを許す)
+func (d *Document) ValidateResponse(pattern string, status int, header http.Header, body []byte) error {
+	op := d.Operation(pattern)
+	if op == nil {
+		return fmt.Errorf("%s is not documented", pattern)
+	}
+	response, ok := op.Responses[strconv.Itoa(status)]
+	if !ok && status >= http.StatusBadRequest {
+		response, ok = op.Responses["default"]
+	}
+	if !ok {
+		return fmt.Errorf("%s: status %d is not documented", pattern, status)
+	}
+
+	if len(response.Content) == 0 {
+		if len(body) > 0 {
+			return fmt.Errorf("%s: status %d is documented without a body, got %q", pattern, status, body)
+		}
+		return nil
+	}
+	mediaType, _, err := mime.ParseMediaType(header.Get("Content-Type"))
+	if err != nil {
+		return fmt.Errorf("%s: status %d: invalid Content-Type %q: %w", pattern, status, header.Get("Content-Type"), err)
+	}
+	content, ok := response.Content[mediaType]
+	if !ok {
+		return fmt.Errorf("%s: status %d: Content-Type %s is not documented", pattern, status, mediaType)
+	}
+	if content.Schema != nil && isJSON(mediaType) {
+		if err := d.Validate(content.Schema, body); err != nil {
+			return fmt.Errorf("%s: status %d: %w", pattern, status, err)
+		}
+	}
+	return nil
+}
+
+// pathParamPattern はパスのパラメーター ({id} など) に一致する
+var pathParamPattern = regexp.MustCompile(`\{([^}]+)\}`)
+
+// Check はドキュメント自体の誤り (解決できない参照・宣言のないパスのパラメーター・重複した operationId など) を返す
+func (d *Document) Check() error {
+	var errs []error
+	operationIDs := make(map[string]string)
+	for _, pattern := range d.Patterns() {
+		op := d.Operation(pattern)
+		if op.OperationID == "" {
+			errs = append(errs, fmt.Errorf("%s: operationId is empty", pattern))
+		} else if other, ok := operationIDs[op.OperationID]; ok {
+			errs = append(errs, fmt.Errorf("%s: operationId %s is also used by %s", pattern, op.OperationID, other))
+		}
+		operationIDs[op.OperationID] = pattern
+
+		if len(op.Responses) == 0 {
+			errs = append(errs, fmt.Errorf("%s: no responses", pattern))
+		}
+		for _, match := range pathParamPattern.FindAllStringSubmatch(pattern, -1) {
+			if !slices.ContainsFunc(op.Parameters, func(p *Parameter) bool { return p.In == "path" && p.Name == match[1] && p.Required }) {
+				errs = append(errs, fmt.Errorf("%s: path parameter %s is not declared", pattern, match[1]))
+			}
+		}
+		for _, requirement := range op.Security {
+			for name := range requirement {
+				if _, ok := d.Components.SecuritySchemes[name]; !ok {
+					errs = append(errs, fmt.Errorf("%s: security scheme %s is not defined", pattern, name))
+				}
+			}
+		}
+
+		var schemas []*Schema
+		for _, p := range op.Parameters {
+			schemas = append(schemas, p.Schema)
+		}
+		if op.RequestBody != nil {
+			for _, content := range op.RequestBody.Content {
+				schemas = append(schemas, content.Schema)
+			}
+		}
+		for _, response := range op.Responses {
+			for _, content := range response.Content {
+				schemas = append(schemas, content.Schema)
+			}
+			for _, header := range response.Headers {
+				schemas = append(schemas, header.Schema)
+			}
+		}
+		for _, s := range schemas {
+			if err := d.checkRefs(s); err != nil {
+				errs = append(errs, fmt.Errorf("%s: %w", pattern, err))
+			}
+		}
+	}
+	for name, s := range d.Components.Schemas {
+		if err := d.checkRefs(s); err != nil {
+			errs = append(errs, fmt.Errorf("schema %s: %w", name, err))
+		}
+	}
+	return errors.Join(errs...)
+}
+
+// checkRefs はスキーマ (プロパティなどを含む) の参照がすべて解決できるかを確認する
+func (d *Document) checkRefs(s *Schema) error {
+	if s == nil {
+		return nil
+	}
+	if s.Ref != "" {
+		_, err := d.resolve(s.Ref)
+		return err
+	}
+	children := []*Schema{s.Items, s.AdditionalProperties}
+	children = append(children, s.AnyOf...)
+	for _, property := range s.Properties {
+		children = append(children, property)
+	}
+	for _, child := range children {
+		if err := d.checkRefs(child); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// resolve は components の schemas への参照を解決する
+func (d *Document) resolve(ref string) (*Schema, error) {
+	name, ok := strings.CutPrefix(ref, schemaRefPrefix)
+	if !ok {
+		return nil, fmt.Errorf("unsupported reference %s", ref)
+	}
+	s := d.Components.Schemas[name]
+	if s == nil {
+		return nil, fmt.Errorf("unresolved reference %s", ref)
+	}
+	return s, nil
+}
+
+// validate は値 v (json.Number を使ってデコードしたもの) がスキーマに従っているかを確認する (path はエラーに含める値の位置)
+func (d *Document) validate(s *Schema, v any, path string) error {
+	if s.Ref != "" {
+		target, err := d.resolve(s.Ref)
+		if err != nil {
+			return fmt.Errorf("%s: %w", path, err)
+		}
+		return d.validate(target, v, path)
+	}
+	if len(s.AnyOf) > 0 {
+		var errs []error
+		for _, candidate := range s.AnyOf {
+			err := d.validate(candidate, v, path)
+			if err == nil {
+				return nil
+			}
+			errs = append(errs, err)
+		}
+		return fmt.Errorf("%s: does not match any schema: %w", path, errors.Join(errs...))
+	}
+
+	if got := jsonType(v); len(s.Type) > 0 && !slices.Contains(s.Type, got) {
+		// 整数は number にも一致する
+		if got != "integer" || !slices.Contains(s.Type, "number") {
+			return fmt.Errorf("%s: got %s, want %s", path, got, strings.Join(s.Type, " or "))
+		}
+	}
+
+	switch v := v.(type) {
+	case string:
+		if len(s.Enum) > 0 && !slices.Contains(s.Enum, v) {
+			return fmt.Errorf("%s: %q is not one of %s", path, v, strings.Join(s.Enum, ", "))
+		}
+		if err := checkFormat(s.Format, v); err != nil {
+			return fmt.Errorf("%s: %q is not a valid %s: %w", path, v, s.Format, err)
+		}
+	case json.Number:
+		n, err := v.Float64()
+		if err != nil {
+			return fmt.Errorf("%s: %w", path, err)
+		}
+		if s.Minimum != nil && n < *s.Minimum {
+			return fmt.Errorf("%s: %s is less than the minimum %v", path, v, *s.Minimum)
+		}
+		if s.Maximum != nil && n > *s.Maximum {
+			return fmt.Errorf("%s: %s is greater than the maximum %v", path, v, *s.Maximum)
+		}
+	case []any:
+		if s.Items != nil {
+			for i, item := range v {
+				if err := d.validate(s.Items, item, fmt.Sprintf("%s[%d]", path, i)); err != nil {
+					return err
+				}
+			}
+		}
+	case map[string]any:
+		for _, name := range s.Required {
+			if _, ok := v[name]; !ok {
+				return fmt.Errorf("%s: required property %s is missing", path, name)
+			}
+		}
+		names := make([]string, 0, len(v))
+		for name := range v {
+			names = append(names, name)
+		}
+		slices.Sort(names)
+		for _, name := range names {
+			propertyPath := path + "." + name
+			switch property, ok := s.Properties[name]; {
+			case ok:
+				if err := d.validate(property, v[name], propertyPath); err != nil {
+					return err
+				}
+			case s.AdditionalProperties != nil:
+				if err := d.validate(s.AdditionalProperties, v[name], propertyPath); err != nil {
+					return err
+				}
+			case s.Properties != nil:
+				return fmt.Errorf("%s: property is not documented", propertyPath)
+			}
+		}
+	}
+	return nil
+}
+
+// jsonType は値の JSON Schema の type を返す (小数部のない数値は integer)
+func jsonType(v any) string {
+	switch v := v.(type) {
+	case nil:
+		return "null"
+	case bool:
+		return "boolean"
+	case json.Number:
+		if !strings.ContainsAny(v.String(), ".eE") {
+			return "integer"
+		}
+		return "number"
+	case string:
+		return "string"
+	case []any:
+		return "array"
+	case map[string]any:
+		return "object"
+	}
+	return fmt.Sprintf("%T", v)
+}
+
+// checkFormat は文字列が format (date-time / date / uuid) の形式かを確認する (それ以外の format は確認しない)
+func checkFormat(format, v string) error {
+	var err error
+	switch format {
+	case "date-time":
+		_, err = time.Parse(time.RFC3339, v)
+	case "date":
+		_, err = time.Parse(time.DateOnly, v)
+	case "uuid":
+		if len(v) != 36 {
+			return errors.New("uuid must be 36 characters")
+		}
+		_, err = uuid.Parse(v)
+	}
+	return err
+}
+
+// isJSON はメディアタイプが JSON (application/json や application/problem+json など) かを返す
+func isJSON(mediaType string) bool {
+	return mediaType == "application/json" || strings.HasSuffix(mediaType, "+json")
+}
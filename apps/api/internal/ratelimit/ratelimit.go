@@ -0,0 +1,181 @@
+// Package ratelimit はトークンバケットによるリクエストのレート制限を提供する
+package ratelimit
+
+import (
+	"context"
+	"fmt"
+	"math"
+	"net"
+	"net/http"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+)
+
+// Limit はトークンバケットの設定 (Window ごとに Requests 回まで。バケットの容量は Requests で、Window の間に満杯まで補充される)
+type Limit struct {
+	Requests int
+	Window   time.Duration
+}
+
+// RefillPerSecond は1秒あたりに補充されるトークン数を返す
+func (l Limit) RefillPerSecond() float64 {
+	return float64(l.Requests) / l.Window.Seconds()
+}
+
+// String は "100/15m0s" の形式で返す
+func (l Limit) String() string {
+	return fmt.Sprintf("%d/%s", l.Requests, l.Window)
+}
+
+// ParseLimit は "100/15m" の形式 (回数/time.ParseDuration の期間) の設定を読み込む
+func ParseLimit(s string) (Limit, error) {
+	requests, window, ok := strings.Cut(strings.TrimSpace(s), "/")
+	if !ok {
+		return Limit{}, fmt.Errorf("rate limit %q must be in the form <requests>/<window>", s)
+	}
+	n, err := strconv.Atoi(requests)
+	if err != nil || n < 1 {
+		return Limit{}, fmt.Errorf("rate limit %q must have a positive number of requests", s)
+	}
+	d, err := time.ParseDuration(window)
+	if err != nil || d <= 0 {
+		return Limit{}, fmt.Errorf("rate limit %q must have a positive window", s)
+	}
+	return Limit{Requests: n, Window: d}, nil
+}
+
+// Result はトークンを取り出した結果
+type Result struct {
+	Allowed    bool
+	Limit      int
+	Remaining  int
+	RetryAfter time.Duration // 拒否された場合に次のトークンが補充されるまでの時間
+	ResetAfter time.Duration // バケットが満杯になるまでの時間
+}
+
+// NewResult はトークンを取り出した後の残りのトークン数 (tokens) から結果を作成する
+func NewResult(limit Limit, tokens float64, allowed bool) Result {
+	rate := limit.RefillPerSecond()
+	result := Result{
+		Allowed:    allowed,
+		Limit:      limit.Requests,
+		Remaining:  max(int(math.Floor(tokens)), 0),
+		ResetAfter: time.Duration((float64(limit.Requests) - tokens) / rate * float64(time.Second)),
+	}
+	if !allowed {
+		result.RetryAfter = time.Duration((1 - tokens) / rate * float64(time.Second))
+	}
+	return result
+}
+
+// Store はトークンバケットの状態を保存する
+// 複数のインスタンスで制限を共有する場合は、共有のストア (Postgres) を使う
+type Store interface {
+	// Take は key のバケットからトークンを1つ取り出す (足りない場合は Allowed が false)
+	Take(ctx context.Context, key string, limit Limit) (Result, error)
+}
+
+// refill は前回から経過した時間分のトークンを補充し、トークンを1つ取り出す
+func refill(tokens float64, elapsed time.Duration, limit Limit) (float64, bool) {
+	tokens = math.Min(float64(limit.Requests), tokens+elapsed.Seconds()*limit.RefillPerSecond())
+	if tokens < 1 {
+		return tokens, false
+	}
+	return tokens - 1, true
+}
+
+// memoryStoreSweepInterval はメモリ上のストアが満杯になったバケットを削除する間隔
+const memoryStoreSweepInterval = 10 * time.Minute
+
+type bucket struct {
+	tokens    float64
+	updatedAt time.Time
+	limit     Limit
+}
+
+// MemoryStore はプロセスのメモリ上にトークンバケットを保存する (インスタンスごとの制限になる)
+type MemoryStore struct {
+	mu        sync.Mutex
+	buckets   map[string]*bucket
+	now       func() time.Time
+	lastSweep time.Time
+}
+
+// NewMemoryStore は新しい MemoryStore を作成する
+func NewMemoryStore() *MemoryStore {
+	return &MemoryStore{
+		buckets:   make(map[string]*bucket),
+		now:       time.Now,
+		lastSweep: time.Now(),
+	}
+}
+
+// Take は key のバケットからトークンを1つ取り出す
+func (s *MemoryStore) Take(_ context.Context, key string, limit Limit) (Result, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	now := s.now()
+	b, ok := s.buckets[key]
+	if !ok {
+		b = &bucket{tokens: float64(limit.Requests), updatedAt: now}
+		s.buckets[key] = b
+	}
+	var allowed bool
+	b.tokens, allowed = refill(b.tokens, now.Sub(b.updatedAt), limit)
+	b.updatedAt = now
+	b.limit = limit
+
+	if now.Sub(s.lastSweep) > memoryStoreSweepInterval {
+		s.sweep(now)
+	}
+	return NewResult(limit, b.tokens, allowed), nil
+}
+
+// sweep は満杯まで補充されたバケット (削除しても結果が変わらない) を削除する
+func (s *MemoryStore) sweep(now time.Time) {
+	for key, b := range s.buckets {
+		if now.Sub(b.updatedAt) >= b.limit.Window {
+			delete(s.buckets, key)
+		}
+	}
+	s.lastSweep = now
+}
+
+// heavyRoutes は通常の制限に加えて、負荷の高い処理用の制限を適用するルート (ServeMux のパターン)
+var heavyRoutes = map[string]bool{
+	"GET /me/export":                     true,
+	"POST /me/exports":                   true,
+	"POST /imports":                      true,
+	"POST /v1/weekly-volume/recalculate": true,
+}
+
+// IsHeavyRoute はルートが負荷の高い処理用の制限の対象かを返す
+func IsHeavyRoute(pattern string) bool {
+	return heavyRoutes[pattern]
+}
+
+// ClientIP はリクエストの送信元のIPアドレスを返す
+// trustedHeader (Fly.io の場合は Fly-Client-IP) はプロキシが設定するヘッダーで、空の場合は接続元のアドレスを使う。
+// X-Forwarded-For のように複数のアドレスを持つヘッダーは、クライアントが任意の値を先頭に入れられるため、
+// 信頼するプロキシが最後に追加したアドレス (右端) を使う。IPアドレスでない場合は接続元のアドレスを使う
+func ClientIP(r *http.Request, trustedHeader string) string {
+	if trustedHeader != "" {
+		if values := r.Header.Values(trustedHeader); len(values) > 0 {
+			last := values[len(values)-1]
+			if i := strings.LastIndexByte(last, ','); i >= 0 {
+				last = last[i+1:]
+			}
+			if ip := net.ParseIP(strings.TrimSpace(last)); ip != nil {
+				return ip.String()
+			}
+		}
+	}
+	host, _, err := net.SplitHostPort(r.RemoteAddr)
+	if err != nil {
+		return r.RemoteAddr
+	}
+	return host
+}
@@ -0,0 +1,119 @@
+package ratelimit
+
+import (
+	"context"
+	"net/http/httptest"
+	"testing"
+	"time"
+)
+
+func TestParseLimit(t *testing.T) {
+	tests := []struct {
+		in      string
+		want    Limit
+		wantErr bool
+	}{
+		{in: "100/15m", want: Limit{Requests: 100, Window: 15 * time.Minute}},
+		{in: " 10/1h ", want: Limit{Requests: 10, Window: time.Hour}},
+		{in: "100", wantErr: true},
+		{in: "0/1m", wantErr: true},
+		{in: "-1/1m", wantErr: true},
+		{in: "10/0s", wantErr: true},
+		{in: "10/fortnight", wantErr: true},
+	}
+	for _, tt := range tests {
+		got, err := ParseLimit(tt.in)
+		if (err != nil) != tt.wantErr {
+			t.Errorf("ParseLimit(%q) error = %v, wantErr %v", tt.in, err, tt.wantErr)
+			continue
+		}
+		if got != tt.want {
+			t.Errorf("ParseLimit(%q) = %v, want %v", tt.in, got, tt.want)
+		}
+	}
+}
+
+func TestMemoryStore_Take(t *testing.T) {
+	ctx := context.Background()
+	now := time.Date(2025, 5, 15, 12, 0, 0, 0, time.UTC)
+	s := NewMemoryStore()
+	s.now = func() time.Time { return now }
+	limit := Limit{Requests: 3, Window: 3 * time.Minute} // 1分ごとに1つ補充
+
+	for i, wantRemaining := range []int{2, 1, 0} {
+		res, err := s.Take(ctx, "ip:192.0.2.1", limit)
+		if err != nil {
+			t.Fatalf("Take() error = %v", err)
+		}
+		if !res.Allowed || res.Remaining != wantRemaining {
+			t.Fatalf("request %d: Allowed = %v, Remaining = %d, want true, %d", i+1, res.Allowed, res.Remaining, wantRemaining)
+		}
+	}
+
+	res, _ := s.Take(ctx, "ip:192.0.2.1", limit)
+	if res.Allowed {
+		t.Fatal("request over the limit was allowed")
+	}
+	if res.RetryAfter != time.Minute {
+		t.Errorf("RetryAfter = %v, want %v", res.RetryAfter, time.Minute)
+	}
+	if res.ResetAfter != 3*time.Minute {
+		t.Errorf("ResetAfter = %v, want %v", res.ResetAfter, 3*time.Minute)
+	}
+
+	// 別のキーは影響を受けない
+	if res, _ := s.Take(ctx, "ip:192.0.2.2", limit); !res.Allowed {
+		t.Error("request for another key was denied")
+	}
+
+	// 1分後に1つ補充される
+	now = now.Add(time.Minute)
+	if res, _ := s.Take(ctx, "ip:192.0.2.1", limit); !res.Allowed || res.Remaining != 0 {
+		t.Errorf("after refill: Allowed = %v, Remaining = %d, want true, 0", res.Allowed, res.Remaining)
+	}
+
+	// 長時間経過しても容量までしか補充されない
+	now = now.Add(time.Hour)
+	if res, _ := s.Take(ctx, "ip:192.0.2.1", limit); res.Remaining != 2 {
+		t.Errorf("after idle: Remaining = %d, want 2", res.Remaining)
+	}
+}
+
+func TestClientIP(t *testing.T) {
+	tests := []struct {
+		name          string
+		remoteAddr    string
+		headers       []string // trustedHeader (なければ Fly-Client-IP) の値 (複数行)
+		trustedHeader string
+		want          string
+	}{
+		{name: "remote address", remoteAddr: "192.0.2.1:54321", want: "192.0.2.1"},
+		{name: "ipv6 remote address", remoteAddr: "[2001:db8::1]:54321", want: "2001:db8::1"},
+		{name: "untrusted header is ignored", remoteAddr: "192.0.2.1:54321", headers: []string{"203.0.113.9"}, want: "192.0.2.1"},
+		{name: "trusted header", remoteAddr: "10.0.0.1:54321", headers: []string{"203.0.113.9"}, trustedHeader: "Fly-Client-IP", want: "203.0.113.9"},
+		{name: "trusted header missing", remoteAddr: "10.0.0.1:54321", trustedHeader: "Fly-Client-IP", want: "10.0.0.1"},
+		{name: "ipv6 in trusted header", remoteAddr: "10.0.0.1:54321", headers: []string{"2001:db8::9"}, trustedHeader: "Fly-Client-IP", want: "2001:db8::9"},
+		// クライアントが X-Forwarded-For を送っても、プロキシが右端に追加したアドレスを使う
+		{name: "spoofed forwarded address", remoteAddr: "10.0.0.1:54321", headers: []string{"198.51.100.7, 203.0.113.9"}, trustedHeader: "X-Forwarded-For", want: "203.0.113.9"},
+		{name: "spoofed forwarded header line", remoteAddr: "10.0.0.1:54321", headers: []string{"198.51.100.7", "203.0.113.9"}, trustedHeader: "X-Forwarded-For", want: "203.0.113.9"},
+		{name: "spoofed addresses change nothing", remoteAddr: "10.0.0.1:54321", headers: []string{"198.51.100.8, 198.51.100.7, 203.0.113.9"}, trustedHeader: "X-Forwarded-For", want: "203.0.113.9"},
+		{name: "not an address", remoteAddr: "10.0.0.1:54321", headers: []string{"203.0.113.9, unknown"}, trustedHeader: "X-Forwarded-For", want: "10.0.0.1"},
+		{name: "empty value", remoteAddr: "10.0.0.1:54321", headers: []string{""}, trustedHeader: "Fly-Client-IP", want: "10.0.0.1"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			r := httptest.NewRequest("GET", "/workouts", nil)
+			r.RemoteAddr = tt.remoteAddr
+			name := tt.trustedHeader
+			if name == "" {
+				name = "Fly-Client-IP"
+			}
+			for _, value := range tt.headers {
+				r.Header.Add(name, value)
+			}
+			if got := ClientIP(r, tt.trustedHeader); got != tt.want {
+				t.Errorf("ClientIP() = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
@@ -13,10 +13,18 @@ import (
 	"github.com/shopspring/decimal"
 )
 
+// volumeQueries は VolumeService が利用する週間ボリューム関連のクエリのインターフェース
+type volumeQueries interface {
+	GetWeeklyVolumes(ctx context.Context, arg sqlc.GetWeeklyVolumesParams) ([]sqlc.GetWeeklyVolumesRow, error)
+	GetWeeklyVolumeForWeek(ctx context.Context, arg sqlc.GetWeeklyVolumeForWeekParams) (sqlc.WeeklyVolume, error)
+	GetWeeklyVolumeStats(ctx context.Context, arg sqlc.GetWeeklyVolumeStatsParams) (sqlc.GetWeeklyVolumeStatsRow, error)
+	RecalculateWeeklyVolume(ctx context.Context, arg sqlc.RecalculateWeeklyVolumeParams) error
+}
+
 // VolumeService は週間トレーニングボリューム関連のサービスを提供する
 type VolumeService struct {
 	pool    *pgxpool.Pool
-	queries *sqlc.Queries
+	queries volumeQueries
 	logger  *slog.Logger
 }
 
@@ -63,7 +71,7 @@ func (s *VolumeService) GetWeeklyVolumes(ctx context.Context, userID string, wee
 			s.logger.WarnContext(ctx, "Failed to parse decimal from pgtype.Numeric Int", slog.Any("numeric", n), slog.Any("error", err))
 			return 0
 		}
-		f, _ := d.Shift(-n.Exp).Float64()
+		f, _ := d.Shift(n.Exp).Float64()
 		return f
 	}
 
@@ -137,7 +145,7 @@ func (s *VolumeService) GetWeeklyVolumeForWeek(ctx context.Context, userID strin
 			s.logger.WarnContext(ctx, "Failed to parse decimal from pgtype.Numeric Int", slog.Any("numeric", n), slog.Any("error", err))
 			return 0
 		}
-		f, _ := d.Shift(-n.Exp).Float64()
+		f, _ := d.Shift(n.Exp).Float64()
 		return f
 	}
 	totalVolume := numericToFloat64(volume.TotalVolume)
@@ -226,7 +234,7 @@ func (s *VolumeService) GetWeeklyVolumeStats(ctx context.Context, userID string,
 			s.logger.WarnContext(ctx, "Failed to parse decimal from pgtype.Numeric Int", slog.Any("numeric", n), slog.Any("error", err))
 			return 0
 		}
-		f, _ := d.Shift(-n.Exp).Float64()
+		f, _ := d.Shift(n.Exp).Float64()
 		return f
 	}
 
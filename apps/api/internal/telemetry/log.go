@@ -0,0 +1,52 @@
+package telemetry
+
+import (
+	"context"
+	"log/slog"
+
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/codes"
+	"go.opentelemetry.io/otel/trace"
+)
+
+// LogHandler はログにトレースIDとスパンIDを付け、エラーログをスパンに記録する slog.Handler
+// サービスはエラーを ErrorContext でログに出力しているため、スパンのエラーの記録を兼ねる
+type LogHandler struct {
+	slog.Handler
+}
+
+// NewLogHandler は h をラップした LogHandler を作成する
+func NewLogHandler(h slog.Handler) *LogHandler {
+	return &LogHandler{Handler: h}
+}
+
+// Handle はログを出力する
+func (h *LogHandler) Handle(ctx context.Context, r slog.Record) error {
+	span := trace.SpanFromContext(ctx)
+	if sc := span.SpanContext(); sc.IsValid() {
+		r.AddAttrs(
+			slog.String("trace_id", sc.TraceID().String()),
+			slog.String("span_id", sc.SpanID().String()),
+		)
+	}
+	if r.Level >= slog.LevelError && span.IsRecording() {
+		attrs := []attribute.KeyValue{attribute.String("log.message", r.Message)}
+		r.Attrs(func(a slog.Attr) bool {
+			attrs = append(attrs, attribute.String("log."+a.Key, a.Value.String()))
+			return true
+		})
+		span.AddEvent("log.error", trace.WithAttributes(attrs...))
+		span.SetStatus(codes.Error, r.Message)
+	}
+	return h.Handler.Handle(ctx, r)
+}
+
+// WithAttrs は属性を追加した LogHandler を返す
+func (h *LogHandler) WithAttrs(attrs []slog.Attr) slog.Handler {
+	return &LogHandler{Handler: h.Handler.WithAttrs(attrs)}
+}
+
+// WithGroup はグループを追加した LogHandler を返す
+func (h *LogHandler) WithGroup(name string) slog.Handler {
+	return &LogHandler{Handler: h.Handler.WithGroup(name)}
+}
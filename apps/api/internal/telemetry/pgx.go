@@ -0,0 +1,132 @@
+package telemetry
+
+import (
+	"context"
+	"strings"
+
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgxpool"
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/codes"
+	"go.opentelemetry.io/otel/metric"
+	"go.opentelemetry.io/otel/trace"
+)
+
+// PgxTracer は pgx のクエリごとにスパンを作成する (pgx.QueryTracer / pgx.CopyFromTracer の実装)
+// スパン名は sqlc のクエリ名 (-- name: の行) で、ない場合は SQL の最初の単語
+type PgxTracer struct {
+	tracer trace.Tracer
+}
+
+// NewPgxTracer は新しい PgxTracer を作成する
+func NewPgxTracer() *PgxTracer {
+	return &PgxTracer{tracer: otel.Tracer(InstrumentationName + "/pgx")}
+}
+
+// TraceQueryStart はクエリのスパンを開始する
+func (t *PgxTracer) TraceQueryStart(ctx context.Context, _ *pgx.Conn, data pgx.TraceQueryStartData) context.Context {
+	ctx, _ = t.tracer.Start(ctx, QueryName(data.SQL),
+		trace.WithSpanKind(trace.SpanKindClient),
+		trace.WithAttributes(
+			attribute.String("db.system", "postgresql"),
+			attribute.String("db.query.text", data.SQL),
+		),
+	)
+	return ctx
+}
+
+// TraceQueryEnd はクエリのスパンを終了する
+func (t *PgxTracer) TraceQueryEnd(ctx context.Context, _ *pgx.Conn, data pgx.TraceQueryEndData) {
+	endQuerySpan(trace.SpanFromContext(ctx), data.CommandTag.RowsAffected(), data.Err)
+}
+
+// TraceCopyFromStart は COPY のスパンを開始する
+func (t *PgxTracer) TraceCopyFromStart(ctx context.Context, _ *pgx.Conn, data pgx.TraceCopyFromStartData) context.Context {
+	ctx, _ = t.tracer.Start(ctx, "COPY "+data.TableName.Sanitize(),
+		trace.WithSpanKind(trace.SpanKindClient),
+		trace.WithAttributes(
+			attribute.String("db.system", "postgresql"),
+			attribute.String("db.collection.name", data.TableName.Sanitize()),
+		),
+	)
+	return ctx
+}
+
+// TraceCopyFromEnd は COPY のスパンを終了する
+func (t *PgxTracer) TraceCopyFromEnd(ctx context.Context, _ *pgx.Conn, data pgx.TraceCopyFromEndData) {
+	endQuerySpan(trace.SpanFromContext(ctx), data.CommandTag.RowsAffected(), data.Err)
+}
+
+func endQuerySpan(span trace.Span, rows int64, err error) {
+	span.SetAttributes(attribute.Int64("db.response.rows_affected", rows))
+	if err != nil {
+		span.RecordError(err)
+		span.SetStatus(codes.Error, err.Error())
+	}
+	span.End()
+}
+
+// QueryName はスパン名に使うクエリ名を返す (sqlc のクエリは "-- name: GetMenu :one" の GetMenu)
+func QueryName(sql string) string {
+	sql = strings.TrimSpace(sql)
+	if rest, ok := strings.CutPrefix(sql, "-- name: "); ok {
+		if name, _, ok := strings.Cut(rest, " "); ok {
+			return name
+		}
+	}
+	if op, _, _ := strings.Cut(sql, " "); op != "" {
+		return strings.ToUpper(op)
+	}
+	return "query"
+}
+
+// RegisterPoolMetrics は pgxpool の接続プールの統計をメトリクスとして登録する
+func RegisterPoolMetrics(pool *pgxpool.Pool) error {
+	meter := otel.Meter(InstrumentationName + "/pgxpool")
+
+	connections, err := meter.Int64ObservableGauge("db.client.connection.count",
+		metric.WithDescription("Number of connections in the pool by state"),
+		metric.WithUnit("{connection}"))
+	if err != nil {
+		return err
+	}
+	maxConnections, err := meter.Int64ObservableGauge("db.client.connection.max",
+		metric.WithDescription("Maximum number of connections allowed in the pool"),
+		metric.WithUnit("{connection}"))
+	if err != nil {
+		return err
+	}
+	acquires, err := meter.Int64ObservableCounter("db.client.connection.acquires",
+		metric.WithDescription("Number of connections acquired from the pool"),
+		metric.WithUnit("{acquire}"))
+	if err != nil {
+		return err
+	}
+	emptyAcquires, err := meter.Int64ObservableCounter("db.client.connection.empty_acquires",
+		metric.WithDescription("Number of acquires that waited for a connection because the pool was empty"),
+		metric.WithUnit("{acquire}"))
+	if err != nil {
+		return err
+	}
+	waitTime, err := meter.Float64ObservableCounter("db.client.connection.wait_time",
+		metric.WithDescription("Total time spent waiting to acquire a connection"),
+		metric.WithUnit("s"))
+	if err != nil {
+		return err
+	}
+
+	idle := metric.WithAttributes(attribute.String("db.client.connection.state", "idle"))
+	used := metric.WithAttributes(attribute.String("db.client.connection.state", "used"))
+	_, err = meter.RegisterCallback(func(_ context.Context, o metric.Observer) error {
+		stat := pool.Stat()
+		o.ObserveInt64(connections, int64(stat.IdleConns()), idle)
+		o.ObserveInt64(connections, int64(stat.AcquiredConns()), used)
+		o.ObserveInt64(maxConnections, int64(stat.MaxConns()))
+		o.ObserveInt64(acquires, stat.AcquireCount())
+		o.ObserveInt64(emptyAcquires, stat.EmptyAcquireCount())
+		o.ObserveFloat64(waitTime, stat.AcquireDuration().Seconds())
+		return nil
+	}, connections, maxConnections, acquires, emptyAcquires, waitTime)
+	return err
+}
@@ -0,0 +1,121 @@
+// Package telemetry は OpenTelemetry のトレースとメトリクスの設定を提供する
+package telemetry
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log/slog"
+
+	"github.com/prometheus/client_golang/prometheus"
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/exporters/otlp/otlpmetric/otlpmetrichttp"
+	"go.opentelemetry.io/otel/exporters/otlp/otlptrace/otlptracehttp"
+	otelprometheus "go.opentelemetry.io/otel/exporters/prometheus"
+	"go.opentelemetry.io/otel/exporters/stdout/stdoutmetric"
+	"go.opentelemetry.io/otel/exporters/stdout/stdouttrace"
+	"go.opentelemetry.io/otel/propagation"
+	sdkmetric "go.opentelemetry.io/otel/sdk/metric"
+	"go.opentelemetry.io/otel/sdk/resource"
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+)
+
+// InstrumentationName はこのアプリケーションの計装の名前 (Tracer / Meter の名前)
+const InstrumentationName = "github.com/aiirononeko/bulktrack/apps/api"
+
+// Options はテレメトリの出力先の設定
+type Options struct {
+	ServiceName  string
+	OTLPEndpoint string // 空の場合は標準出力に出力する
+	Disabled     bool   // true の場合は何も出力しない (計装は no-op になる)
+
+	// Prometheus が設定されている場合はメトリクスをこのレジストリにも登録する (Disabled でも有効)
+	// OTLPEndpoint が空の場合、メトリクスは標準出力には出力しない
+	Prometheus *prometheus.Registry
+}
+
+// Setup はトレースとメトリクスのプロバイダーをグローバルに設定し、終了時に呼び出す関数を返す
+//
+// OTLPEndpoint が設定されている場合は OTLP (HTTP) で送信する。ヘッダーなどの詳細は
+// OpenTelemetry の標準の環境変数 (OTEL_EXPORTER_OTLP_HEADERS など) で設定できる
+func Setup(ctx context.Context, opts Options, logger *slog.Logger) (shutdown func(context.Context) error, err error) {
+	otel.SetTextMapPropagator(propagation.NewCompositeTextMapPropagator(propagation.TraceContext{}, propagation.Baggage{}))
+	if opts.Disabled && opts.Prometheus == nil {
+		logger.Info("Telemetry is disabled")
+		return func(context.Context) error { return nil }, nil
+	}
+
+	// OTEL_SERVICE_NAME / OTEL_RESOURCE_ATTRIBUTES が設定されている場合はそちらを優先する
+	res, err := resource.New(ctx,
+		resource.WithAttributes(attribute.String("service.name", opts.ServiceName)),
+		resource.WithFromEnv(),
+		resource.WithTelemetrySDK(),
+		resource.WithHost(),
+	)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create telemetry resource: %w", err)
+	}
+
+	var metricReaders []sdkmetric.Reader
+	if opts.Prometheus != nil {
+		promExporter, err := otelprometheus.New(otelprometheus.WithRegisterer(opts.Prometheus))
+		if err != nil {
+			return nil, fmt.Errorf("failed to create Prometheus exporter: %w", err)
+		}
+		metricReaders = append(metricReaders, promExporter)
+	}
+	if opts.Disabled {
+		// Prometheus のメトリクスのみ有効にする
+		logger.Info("Telemetry is disabled except for Prometheus metrics")
+		meterProvider := newMeterProvider(res, metricReaders)
+		otel.SetMeterProvider(meterProvider)
+		return meterProvider.Shutdown, nil
+	}
+
+	var spanExporter sdktrace.SpanExporter
+	if opts.OTLPEndpoint != "" {
+		// エンドポイントは OTEL_EXPORTER_OTLP_ENDPOINT から読み込まれる (/v1/traces などのパスが付く)
+		if spanExporter, err = otlptracehttp.New(ctx); err != nil {
+			return nil, fmt.Errorf("failed to create OTLP trace exporter: %w", err)
+		}
+		metricExporter, err := otlpmetrichttp.New(ctx)
+		if err != nil {
+			return nil, fmt.Errorf("failed to create OTLP metric exporter: %w", err)
+		}
+		metricReaders = append(metricReaders, sdkmetric.NewPeriodicReader(metricExporter))
+		logger.Info("Exporting telemetry via OTLP", slog.String("endpoint", opts.OTLPEndpoint))
+	} else {
+		if spanExporter, err = stdouttrace.New(); err != nil {
+			return nil, fmt.Errorf("failed to create stdout trace exporter: %w", err)
+		}
+		if opts.Prometheus == nil {
+			metricExporter, err := stdoutmetric.New()
+			if err != nil {
+				return nil, fmt.Errorf("failed to create stdout metric exporter: %w", err)
+			}
+			metricReaders = append(metricReaders, sdkmetric.NewPeriodicReader(metricExporter))
+		}
+		logger.Info("Exporting telemetry to stdout (OTEL_EXPORTER_OTLP_ENDPOINT is not set)")
+	}
+
+	tracerProvider := sdktrace.NewTracerProvider(
+		sdktrace.WithBatcher(spanExporter),
+		sdktrace.WithResource(res),
+	)
+	meterProvider := newMeterProvider(res, metricReaders)
+	otel.SetTracerProvider(tracerProvider)
+	otel.SetMeterProvider(meterProvider)
+
+	return func(ctx context.Context) error {
+		return errors.Join(tracerProvider.Shutdown(ctx), meterProvider.Shutdown(ctx))
+	}, nil
+}
+
+func newMeterProvider(res *resource.Resource, readers []sdkmetric.Reader) *sdkmetric.MeterProvider {
+	opts := []sdkmetric.Option{sdkmetric.WithResource(res)}
+	for _, reader := range readers {
+		opts = append(opts, sdkmetric.WithReader(reader))
+	}
+	return sdkmetric.NewMeterProvider(opts...)
+}
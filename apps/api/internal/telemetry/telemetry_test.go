@@ -0,0 +1,98 @@
+package telemetry
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"io"
+	"log/slog"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/codes"
+	"go.opentelemetry.io/otel/metric"
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+	"go.opentelemetry.io/otel/sdk/trace/tracetest"
+)
+
+func TestQueryName(t *testing.T) {
+	tests := []struct {
+		sql  string
+		want string
+	}{
+		{sql: "-- name: GetMenu :one\nSELECT id FROM menus WHERE id = $1", want: "GetMenu"},
+		{sql: "\n-- name: ListWorkouts :many\nSELECT 1", want: "ListWorkouts"},
+		{sql: "select 1", want: "SELECT"},
+		{sql: "", want: "query"},
+	}
+	for _, tt := range tests {
+		if got := QueryName(tt.sql); got != tt.want {
+			t.Errorf("QueryName(%q) = %q, want %q", tt.sql, got, tt.want)
+		}
+	}
+}
+
+func TestLogHandler(t *testing.T) {
+	recorder := tracetest.NewSpanRecorder()
+	provider := sdktrace.NewTracerProvider(sdktrace.WithSpanProcessor(recorder))
+	ctx, span := provider.Tracer("test").Start(context.Background(), "MenuService.UpdateMenu")
+
+	var buf bytes.Buffer
+	logger := slog.New(NewLogHandler(slog.NewJSONHandler(&buf, nil)))
+	logger.InfoContext(ctx, "Updated menu")
+	logger.ErrorContext(ctx, "Failed to execute UpdateMenu query", slog.String("menu_id", "m1"))
+	span.End()
+
+	var entry map[string]any
+	if err := json.Unmarshal(bytes.SplitN(buf.Bytes(), []byte("\n"), 2)[0], &entry); err != nil {
+		t.Fatalf("invalid log output: %v", err)
+	}
+	if entry["trace_id"] != span.SpanContext().TraceID().String() {
+		t.Errorf("trace_id = %v, want %v", entry["trace_id"], span.SpanContext().TraceID())
+	}
+
+	ended := recorder.Ended()
+	if len(ended) != 1 {
+		t.Fatalf("ended spans = %d, want 1", len(ended))
+	}
+	if got := ended[0].Status().Code; got != codes.Error {
+		t.Errorf("span status = %v, want %v", got, codes.Error)
+	}
+	if events := ended[0].Events(); len(events) != 1 || events[0].Name != "log.error" {
+		t.Errorf("span events = %v, want one log.error event", events)
+	}
+}
+
+func TestPrometheusMetrics(t *testing.T) {
+	registry := NewPrometheusRegistry()
+	shutdown, err := Setup(context.Background(), Options{ServiceName: "test", Disabled: true, Prometheus: registry}, slog.New(slog.NewTextHandler(io.Discard, nil)))
+	if err != nil {
+		t.Fatalf("Setup() error = %v", err)
+	}
+	defer shutdown(context.Background())
+
+	duration, err := otel.Meter("test").Float64Histogram("http.server.request.duration", metric.WithUnit("s"))
+	if err != nil {
+		t.Fatalf("Float64Histogram() error = %v", err)
+	}
+	duration.Record(context.Background(), 0.12, metric.WithAttributes(attribute.String("http.route", "GET /v1/dashboard")))
+
+	rec := httptest.NewRecorder()
+	NewAdminHandler(registry).ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/metrics", nil))
+	if rec.Code != http.StatusOK {
+		t.Fatalf("GET /metrics status = %d, want %d", rec.Code, http.StatusOK)
+	}
+	body := rec.Body.String()
+	for _, want := range []string{
+		`http_server_request_duration_seconds_bucket{http_route="GET /v1/dashboard"`,
+		"go_goroutines",
+	} {
+		if !strings.Contains(body, want) {
+			t.Errorf("GET /metrics does not contain %q", want)
+		}
+	}
+}
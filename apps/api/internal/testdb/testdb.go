@@ -0,0 +1,70 @@
+// Package testdb は Postgres を使うテストのために、マイグレーションを適用したデータベースを作成する
+//
+// TEST_DATABASE_URL (データベースを作成できるユーザー) が設定されている場合のみ作成し、設定されていない場合はテストをスキップする
+package testdb
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"log/slog"
+	"os"
+	"testing"
+	"time"
+
+	"github.com/aiirononeko/bulktrack/apps/api/internal/migrate"
+	"github.com/aiirononeko/bulktrack/apps/api/migrations"
+	"github.com/jackc/pgx/v5/pgxpool"
+)
+
+// New はマイグレーションを適用したテスト用のデータベースを作成し、テストの終了時に削除する
+// suffix はデータベース名の末尾 (どのテストが作成したかの区別に使う)
+func New(t testing.TB, suffix string) *pgxpool.Pool {
+	t.Helper()
+	databaseURL := os.Getenv("TEST_DATABASE_URL")
+	if databaseURL == "" {
+		t.Skip("TEST_DATABASE_URL is not set")
+	}
+	pool := create(t, databaseURL, suffix)
+	all, err := migrate.Load(migrations.FS)
+	if err != nil {
+		t.Fatalf("migrate.Load() error = %v", err)
+	}
+	logger := slog.New(slog.NewTextHandler(io.Discard, nil))
+	if _, err := migrate.New(pool, all, logger).Up(context.Background()); err != nil {
+		t.Fatalf("migrate Up() error = %v", err)
+	}
+	return pool
+}
+
+// create は空のデータベースを作成し、テストの終了時に削除する
+func create(t testing.TB, databaseURL, suffix string) *pgxpool.Pool {
+	t.Helper()
+	ctx := context.Background()
+	admin, err := pgxpool.New(ctx, databaseURL)
+	if err != nil {
+		t.Fatalf("failed to connect to TEST_DATABASE_URL: %v", err)
+	}
+	t.Cleanup(admin.Close)
+
+	name := fmt.Sprintf("bulktrack_test_%d_%s", time.Now().UnixNano(), suffix)
+	if _, err := admin.Exec(ctx, "CREATE DATABASE "+name); err != nil {
+		t.Fatalf("failed to create database: %v", err)
+	}
+	config, err := pgxpool.ParseConfig(databaseURL)
+	if err != nil {
+		t.Fatalf("failed to parse TEST_DATABASE_URL: %v", err)
+	}
+	config.ConnConfig.Database = name
+	pool, err := pgxpool.NewWithConfig(ctx, config)
+	if err != nil {
+		t.Fatalf("failed to connect to %s: %v", name, err)
+	}
+	t.Cleanup(func() {
+		pool.Close()
+		if _, err := admin.Exec(ctx, "DROP DATABASE IF EXISTS "+name); err != nil {
+			t.Errorf("failed to drop database %s: %v", name, err)
+		}
+	})
+	return pool
+}
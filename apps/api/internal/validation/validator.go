@@ -86,7 +86,11 @@ func (v *Validator) getFieldValue(val reflect.Value, fieldPath []string) (reflec
 			return reflect.Value{}, false
 		}
 
-		current = current.FieldByName(field)
+		// Rule fields (e.g. "limit") are matched against struct fields (e.g. "Limit") case-insensitively
+		name := field
+		current = current.FieldByNameFunc(func(fieldName string) bool {
+			return strings.EqualFold(fieldName, name)
+		})
 		if !current.IsValid() {
 			return reflect.Value{}, false
 		}
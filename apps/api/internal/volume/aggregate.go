@@ -0,0 +1,158 @@
+// Package volume は週間ボリューム (weekly_volumes) の集計を提供する
+//
+// 重量はすべて 0.01kg 単位の整数で扱い、DB (NUMERIC(10,2)) に保存したときと同じ値になるよう丸める
+package volume
+
+import (
+	"sort"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// 種目の負荷の種類 (exercises.load_type)
+const (
+	LoadTypeExternal       = "external"
+	LoadTypeBodyweight     = "bodyweight"
+	LoadTypeBodyweightPlus = "bodyweight_plus"
+	LoadTypeAssisted       = "assisted"
+)
+
+// Week はユーザーの週 (JST 月曜始まり) を表す
+type Week struct {
+	UserID    string
+	StartDate time.Time // 週の開始日 (UTC の 0 時で表した日付)
+}
+
+// Set は集計対象のセット (ウォームアップと有酸素・時間計測の種目を除いたもの) を表す
+type Set struct {
+	Week
+	WorkoutDate time.Time     // ワークアウトの日付 (JST、UTC の 0 時で表した日付)
+	ExerciseID  uuid.NullUUID // 種目 (削除された種目のセットは無効)
+	LoadType    string        // 種目の負荷の種類 (空の場合は external)
+	WeightCenti int64         // 記録した重量 (0.01kg 単位)
+	Reps        int32
+}
+
+// BodyWeight は体重の記録を表す
+type BodyWeight struct {
+	MeasuredDate time.Time // 計測日 (JST、UTC の 0 時で表した日付)
+	ValueCenti   int64     // 体重 (0.01kg 単位)
+}
+
+// Totals は1週間の集計値を表す
+type Totals struct {
+	Week
+	TotalVolumeCenti int64 // 実効負荷 x 回数の合計 (0.01kg 単位)
+	EstOneRMCenti    int64 // Epley 式の推定 1RM の最大値 (0.01kg 単位)
+	ExerciseCount    int32 // 種目数
+	SetCount         int32 // セット数
+}
+
+// weekKey は集計の週のキー (time.Time は == で比較できないため日数にする)
+type weekKey struct {
+	userID string
+	day    int64
+}
+
+func dayOf(t time.Time) int64 {
+	return t.Unix() / 86400
+}
+
+// Aggregate はセットを週ごとに集計する (セットのない週は返さない)
+//
+// bodyWeights はユーザーごとの体重の記録 (計測日時の昇順) で、自重種目の実効負荷の計算に使う
+// 返す集計値の順番はセットに最初に現れた週の順番になる
+func Aggregate(sets []Set, bodyWeights map[string][]BodyWeight) []Totals {
+	index := make(map[weekKey]int)
+	exercises := make(map[weekKey]map[uuid.UUID]struct{})
+	var totals []Totals
+
+	for i := range sets {
+		set := &sets[i]
+		key := weekKey{userID: set.UserID, day: dayOf(set.StartDate)}
+		n, ok := index[key]
+		if !ok {
+			n = len(totals)
+			index[key] = n
+			totals = append(totals, Totals{Week: set.Week})
+		}
+		t := &totals[n]
+
+		load := EffectiveLoadCenti(set.LoadType, set.WeightCenti, bodyWeightOn(bodyWeights[set.UserID], set.WorkoutDate))
+		t.TotalVolumeCenti += load * int64(set.Reps)
+		if est := EstimateOneRMCenti(load, set.Reps); est > t.EstOneRMCenti || t.SetCount == 0 {
+			t.EstOneRMCenti = est
+		}
+		t.SetCount++
+
+		if set.ExerciseID.Valid {
+			ids, ok := exercises[key]
+			if !ok {
+				ids = make(map[uuid.UUID]struct{})
+				exercises[key] = ids
+			}
+			if _, ok := ids[set.ExerciseID.UUID]; !ok {
+				ids[set.ExerciseID.UUID] = struct{}{}
+				t.ExerciseCount++
+			}
+		}
+	}
+	return totals
+}
+
+// EffectiveLoadCenti は種目の負荷の種類と体重からセットの実効負荷を返す (DB の set_effective_load_kg と同じ規則)
+//
+//	external:        記録した重量
+//	bodyweight:      体重 (体重の記録がない場合は記録した重量)
+//	bodyweight_plus: 体重 + 記録した重量
+//	assisted:        体重 - 記録した重量 (0 未満にはしない)
+func EffectiveLoadCenti(loadType string, weightCenti int64, bodyWeightCenti *int64) int64 {
+	var bw int64
+	if bodyWeightCenti != nil {
+		bw = *bodyWeightCenti
+	}
+	switch loadType {
+	case LoadTypeBodyweight:
+		if bodyWeightCenti == nil {
+			return weightCenti
+		}
+		return bw
+	case LoadTypeBodyweightPlus:
+		return bw + weightCenti
+	case LoadTypeAssisted:
+		return max(bw-weightCenti, 0)
+	default:
+		return weightCenti
+	}
+}
+
+// EstimateOneRMCenti は Epley 式 (負荷 x (1 + 回数 / 30)) の推定 1RM を 0.01kg 単位に四捨五入して返す
+// DB の集計 (負荷 x (30 + 回数) / 30.0 を NUMERIC(10,2) に丸める) と同じ値になる
+func EstimateOneRMCenti(loadCenti int64, reps int32) int64 {
+	return divRound(loadCenti*(30+int64(reps)), 30)
+}
+
+// divRound は a / b (b > 0) を四捨五入 (0 から遠い方に丸める) して返す
+func divRound(a, b int64) int64 {
+	if a < 0 {
+		return -((-a*2 + b) / (2 * b))
+	}
+	return (a*2 + b) / (2 * b)
+}
+
+// bodyWeightOn はワークアウトの日の体重を返す (DB の get_body_weight_on と同じ規則)
+// その日以前の最新の記録を使い、なければ最も古い記録を使う (記録がない場合は nil)
+func bodyWeightOn(bodyWeights []BodyWeight, date time.Time) *int64 {
+	if len(bodyWeights) == 0 {
+		return nil
+	}
+	// 計測日時の昇順のため、計測日も昇順に並んでいる
+	i := sort.Search(len(bodyWeights), func(i int) bool {
+		return bodyWeights[i].MeasuredDate.After(date)
+	})
+	if i == 0 {
+		return &bodyWeights[0].ValueCenti
+	}
+	return &bodyWeights[i-1].ValueCenti
+}
@@ -0,0 +1,183 @@
+package volume
+
+import (
+	"fmt"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+func date(s string) time.Time {
+	t, err := time.Parse("2006-01-02", s)
+	if err != nil {
+		panic(err)
+	}
+	return t
+}
+
+func exercise(id uuid.UUID) uuid.NullUUID {
+	return uuid.NullUUID{UUID: id, Valid: true}
+}
+
+func TestEstimateOneRMCenti(t *testing.T) {
+	tests := []struct {
+		load int64
+		reps int32
+		want int64
+	}{
+		{load: 10000, reps: 0, want: 10000},
+		{load: 10000, reps: 10, want: 13333}, // 133.333...
+		{load: 10000, reps: 5, want: 11667},  // 116.666...
+		{load: 9, reps: 0, want: 9},
+		{load: 3, reps: 15, want: 5}, // 0.045 は 0 から遠い方に丸める
+		{load: 0, reps: 8, want: 0},
+	}
+	for _, tt := range tests {
+		if got := EstimateOneRMCenti(tt.load, tt.reps); got != tt.want {
+			t.Errorf("EstimateOneRMCenti(%d, %d) = %d, want %d", tt.load, tt.reps, got, tt.want)
+		}
+	}
+}
+
+func TestEffectiveLoadCenti(t *testing.T) {
+	bw := int64(7000)
+	tests := []struct {
+		loadType string
+		weight   int64
+		bw       *int64
+		want     int64
+	}{
+		{loadType: LoadTypeExternal, weight: 10000, bw: &bw, want: 10000},
+		{loadType: "", weight: 10000, bw: &bw, want: 10000},
+		{loadType: LoadTypeBodyweight, weight: 500, bw: &bw, want: 7000},
+		{loadType: LoadTypeBodyweight, weight: 500, bw: nil, want: 500},
+		{loadType: LoadTypeBodyweightPlus, weight: 2000, bw: &bw, want: 9000},
+		{loadType: LoadTypeBodyweightPlus, weight: 2000, bw: nil, want: 2000},
+		{loadType: LoadTypeAssisted, weight: 3000, bw: &bw, want: 4000},
+		{loadType: LoadTypeAssisted, weight: 8000, bw: &bw, want: 0},
+		{loadType: LoadTypeAssisted, weight: 3000, bw: nil, want: 0},
+	}
+	for _, tt := range tests {
+		if got := EffectiveLoadCenti(tt.loadType, tt.weight, tt.bw); got != tt.want {
+			t.Errorf("EffectiveLoadCenti(%q, %d, %v) = %d, want %d", tt.loadType, tt.weight, tt.bw, got, tt.want)
+		}
+	}
+}
+
+func TestAggregate(t *testing.T) {
+	squat, pullUp := uuid.New(), uuid.New()
+	week1 := Week{UserID: "user_a", StartDate: date("2025-05-05")}
+	week2 := Week{UserID: "user_a", StartDate: date("2025-05-12")}
+	other := Week{UserID: "user_b", StartDate: date("2025-05-05")}
+
+	sets := []Set{
+		{Week: week1, WorkoutDate: date("2025-05-05"), ExerciseID: exercise(squat), WeightCenti: 10000, Reps: 5},
+		{Week: week1, WorkoutDate: date("2025-05-05"), ExerciseID: exercise(squat), WeightCenti: 11000, Reps: 3},
+		// 5/7 の体重は 5/6 の記録 (70kg)
+		{Week: week1, WorkoutDate: date("2025-05-07"), ExerciseID: exercise(pullUp), LoadType: LoadTypeBodyweight, Reps: 10},
+		{Week: week1, WorkoutDate: date("2025-05-07"), Reps: 10, WeightCenti: 100}, // 削除された種目
+		{Week: other, WorkoutDate: date("2025-05-05"), ExerciseID: exercise(pullUp), LoadType: LoadTypeBodyweight, WeightCenti: 500, Reps: 8},
+		// 5/12 の体重は 5/12 の記録 (72kg)
+		{Week: week2, WorkoutDate: date("2025-05-12"), ExerciseID: exercise(pullUp), LoadType: LoadTypeBodyweightPlus, WeightCenti: 1000, Reps: 5},
+	}
+	bodyWeights := map[string][]BodyWeight{
+		"user_a": {
+			{MeasuredDate: date("2025-05-06"), ValueCenti: 7000},
+			{MeasuredDate: date("2025-05-12"), ValueCenti: 7200},
+		},
+	}
+
+	want := []Totals{
+		{Week: week1, TotalVolumeCenti: 10000*5 + 11000*3 + 7000*10 + 100*10, EstOneRMCenti: 12100, ExerciseCount: 2, SetCount: 4},
+		{Week: other, TotalVolumeCenti: 500 * 8, EstOneRMCenti: 633, ExerciseCount: 1, SetCount: 1},
+		{Week: week2, TotalVolumeCenti: 8200 * 5, EstOneRMCenti: 9567, ExerciseCount: 1, SetCount: 1},
+	}
+	got := Aggregate(sets, bodyWeights)
+	if len(got) != len(want) {
+		t.Fatalf("Aggregate() returned %d weeks, want %d: %+v", len(got), len(want), got)
+	}
+	for i := range want {
+		if got[i] != want[i] {
+			t.Errorf("Aggregate()[%d] = %+v, want %+v", i, got[i], want[i])
+		}
+	}
+}
+
+func TestBodyWeightOn(t *testing.T) {
+	bodyWeights := []BodyWeight{
+		{MeasuredDate: date("2025-05-06"), ValueCenti: 7000},
+		{MeasuredDate: date("2025-05-06"), ValueCenti: 7050},
+		{MeasuredDate: date("2025-05-12"), ValueCenti: 7200},
+	}
+	tests := []struct {
+		date string
+		want int64
+	}{
+		{date: "2025-05-01", want: 7000}, // 記録より前は最も古い記録
+		{date: "2025-05-06", want: 7050}, // 同じ日は最新の記録
+		{date: "2025-05-11", want: 7050},
+		{date: "2025-05-20", want: 7200},
+	}
+	for _, tt := range tests {
+		got := bodyWeightOn(bodyWeights, date(tt.date))
+		if got == nil || *got != tt.want {
+			t.Errorf("bodyWeightOn(%s) = %v, want %d", tt.date, got, tt.want)
+		}
+	}
+	if got := bodyWeightOn(nil, date("2025-05-06")); got != nil {
+		t.Errorf("bodyWeightOn(nil) = %d, want nil", *got)
+	}
+}
+
+// benchmarkSets は 1,000 ユーザー x 10 週 x 10 セットのセットを作る
+func benchmarkSets() ([]Set, map[string][]BodyWeight) {
+	exercises := make([]uuid.UUID, 20)
+	for i := range exercises {
+		exercises[i] = uuid.New()
+	}
+	loadTypes := []string{LoadTypeExternal, LoadTypeExternal, LoadTypeBodyweight, LoadTypeBodyweightPlus, LoadTypeAssisted}
+	start := date("2025-01-06")
+
+	sets := make([]Set, 0, 100_000)
+	bodyWeights := make(map[string][]BodyWeight, 1000)
+	for u := range 1000 {
+		userID := fmt.Sprintf("user_%04d", u)
+		for w := range 10 {
+			weekStart := start.AddDate(0, 0, 7*w)
+			bodyWeights[userID] = append(bodyWeights[userID], BodyWeight{MeasuredDate: weekStart.AddDate(0, 0, 2), ValueCenti: int64(6000 + u%40*50)})
+			for s := range 10 {
+				sets = append(sets, Set{
+					Week:        Week{UserID: userID, StartDate: weekStart},
+					WorkoutDate: weekStart.AddDate(0, 0, s%7),
+					ExerciseID:  exercise(exercises[(u+s)%len(exercises)]),
+					LoadType:    loadTypes[s%len(loadTypes)],
+					WeightCenti: int64(2000 + s*250),
+					Reps:        int32(5 + s%8),
+				})
+			}
+		}
+	}
+	return sets, bodyWeights
+}
+
+func TestAggregate_100kSets(t *testing.T) {
+	sets, bodyWeights := benchmarkSets()
+	started := time.Now()
+	totals := Aggregate(sets, bodyWeights)
+	elapsed := time.Since(started)
+
+	if len(totals) != 10_000 {
+		t.Fatalf("Aggregate() returned %d weeks, want 10000", len(totals))
+	}
+	if elapsed > time.Second {
+		t.Errorf("Aggregate() of %d sets took %s, want under 1s", len(sets), elapsed)
+	}
+}
+
+func BenchmarkAggregate(b *testing.B) {
+	sets, bodyWeights := benchmarkSets()
+	for b.Loop() {
+		Aggregate(sets, bodyWeights)
+	}
+}
@@ -0,0 +1,131 @@
+package webhook
+
+import (
+	"encoding/base64"
+	"errors"
+	"net/http"
+	"strconv"
+	"testing"
+	"time"
+)
+
+// testSecret はテスト用の署名シークレット
+var testSecret = "whsec_" + base64.StdEncoding.EncodeToString([]byte("bulktrack-test-signing-secret"))
+
+// signedHeader はローカルで署名したヘッダーを作成する
+func signedHeader(t *testing.T, v *SvixVerifier, id string, timestamp time.Time, body []byte) http.Header {
+	t.Helper()
+	header := http.Header{}
+	header.Set(SvixIDHeader, id)
+	header.Set(SvixTimestampHeader, strconv.FormatInt(timestamp.Unix(), 10))
+	header.Set(SvixSignatureHeader, "v1,"+v.Sign(id, timestamp.Unix(), body))
+	return header
+}
+
+func TestSvixVerifier_Verify(t *testing.T) {
+	now := time.Date(2025, 5, 11, 12, 0, 0, 0, time.UTC)
+	body := []byte(`{"type":"user.deleted","data":{"id":"user_123","deleted":true}}`)
+
+	v, err := NewSvixVerifier(testSecret)
+	if err != nil {
+		t.Fatalf("NewSvixVerifier() error = %v", err)
+	}
+	v.now = func() time.Time { return now }
+
+	tests := []struct {
+		name    string
+		header  func() http.Header
+		body    []byte
+		wantErr error
+	}{
+		{
+			name:   "valid signature",
+			header: func() http.Header { return signedHeader(t, v, "msg_1", now, body) },
+			body:   body,
+		},
+		{
+			name: "one of multiple signatures matches (secret rotation)",
+			header: func() http.Header {
+				h := signedHeader(t, v, "msg_1", now, body)
+				h.Set(SvixSignatureHeader, "v1,c29tZS1vbGQtc2lnbmF0dXJl "+h.Get(SvixSignatureHeader))
+				return h
+			},
+			body: body,
+		},
+		{
+			name:    "tampered body",
+			header:  func() http.Header { return signedHeader(t, v, "msg_1", now, body) },
+			body:    []byte(`{"type":"user.deleted","data":{"id":"user_456","deleted":true}}`),
+			wantErr: ErrInvalidSignature,
+		},
+		{
+			name: "signature for another message ID",
+			header: func() http.Header {
+				h := signedHeader(t, v, "msg_1", now, body)
+				h.Set(SvixIDHeader, "msg_2")
+				return h
+			},
+			body:    body,
+			wantErr: ErrInvalidSignature,
+		},
+		{
+			name: "replayed message outside the tolerance",
+			header: func() http.Header {
+				return signedHeader(t, v, "msg_1", now.Add(-SvixTimestampTolerance-time.Second), body)
+			},
+			body:    body,
+			wantErr: ErrTimestampExpired,
+		},
+		{
+			name: "timestamp in the future",
+			header: func() http.Header {
+				return signedHeader(t, v, "msg_1", now.Add(SvixTimestampTolerance+time.Second), body)
+			},
+			body:    body,
+			wantErr: ErrTimestampExpired,
+		},
+		{
+			name: "invalid timestamp",
+			header: func() http.Header {
+				h := signedHeader(t, v, "msg_1", now, body)
+				h.Set(SvixTimestampHeader, "yesterday")
+				return h
+			},
+			body:    body,
+			wantErr: ErrInvalidTimestamp,
+		},
+		{
+			name: "unsupported signature version",
+			header: func() http.Header {
+				h := signedHeader(t, v, "msg_1", now, body)
+				h.Set(SvixSignatureHeader, "v2,"+v.Sign("msg_1", now.Unix(), body))
+				return h
+			},
+			body:    body,
+			wantErr: ErrInvalidSignature,
+		},
+		{
+			name:    "missing headers",
+			header:  func() http.Header { return http.Header{} },
+			body:    body,
+			wantErr: ErrMissingHeaders,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := v.Verify(tt.header(), tt.body)
+			if !errors.Is(err, tt.wantErr) {
+				t.Errorf("Verify() error = %v, want %v", err, tt.wantErr)
+			}
+		})
+	}
+}
+
+func TestNewSvixVerifier_InvalidSecret(t *testing.T) {
+	for _, secret := range []string{"", "whsec_", "whsec_not base64!"} {
+		if _, err := NewSvixVerifier(secret); !errors.Is(err, ErrInvalidSecret) {
+			t.Errorf("NewSvixVerifier(%q) error = %v, want %v", secret, err, ErrInvalidSecret)
+		}
+	}
+}
@@ -0,0 +1,10 @@
+-- Revert 20250419_create_initial_schema.
+-- The uuid-ossp extension is kept because it may be used outside this schema.
+
+DROP TABLE IF EXISTS sets;
+DROP TABLE IF EXISTS workouts;
+DROP TABLE IF EXISTS menu_items;
+DROP TABLE IF EXISTS menus;
+DROP TABLE IF EXISTS exercise_target_muscle_groups;
+DROP TABLE IF EXISTS exercises;
+DROP TABLE IF EXISTS muscle_groups;
@@ -0,0 +1,13 @@
+-- Revert 20250425_create_weekly_volume.
+
+DROP TRIGGER IF EXISTS after_set_insert ON sets;
+DROP TRIGGER IF EXISTS after_set_delete ON sets;
+DROP TRIGGER IF EXISTS after_set_update ON sets;
+
+DROP FUNCTION IF EXISTS update_weekly_volume();
+DROP FUNCTION IF EXISTS recalculate_weekly_volume_after_delete();
+DROP FUNCTION IF EXISTS recalculate_weekly_volume_after_update();
+DROP FUNCTION IF EXISTS populate_weekly_volumes();
+DROP FUNCTION IF EXISTS get_jst_week_start(TIMESTAMPTZ);
+
+DROP TABLE IF EXISTS weekly_volumes;
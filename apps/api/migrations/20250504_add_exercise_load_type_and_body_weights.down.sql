@@ -0,0 +1,239 @@
+-- Revert 20250504_add_exercise_load_type_and_body_weights.
+-- The weekly_volumes functions are restored to the versions from 20250503_add_set_type_and_group_key.
+-- The assisted exercises added by the migration are kept because sets and menus may reference them.
+
+DROP TRIGGER IF EXISTS after_body_weight_change ON body_weights;
+DROP FUNCTION IF EXISTS recalculate_weekly_volumes_after_body_weight_change();
+
+CREATE OR REPLACE FUNCTION update_weekly_volume() 
+RETURNS TRIGGER AS $$
+DECLARE
+    workout_start TIMESTAMPTZ;
+    workout_user_id TEXT;
+    week_start DATE;
+BEGIN
+    -- Warm-up sets are excluded from volume and 1RM aggregation
+    IF NEW.set_type = 'warmup' THEN
+        RETURN NEW;
+    END IF;
+
+    -- Get workout information
+    SELECT started_at, user_id INTO workout_start, workout_user_id
+    FROM workouts
+    WHERE id = NEW.workout_id;
+    
+    -- Calculate the week start date in JST
+    week_start := get_jst_week_start(workout_start);
+    
+    -- Update or insert weekly volume record
+    INSERT INTO weekly_volumes (
+        user_id, 
+        week_start_date, 
+        total_volume,
+        est_one_rm,
+        exercise_count,
+        set_count
+    )
+    VALUES (
+        workout_user_id,
+        week_start,
+        (NEW.weight_kg * NEW.reps),
+        (NEW.weight_kg * (1 + NEW.reps / 30.0)), -- Simple Epley formula for 1RM estimation
+        1,
+        1
+    )
+    ON CONFLICT (user_id, week_start_date) DO UPDATE
+    SET 
+        total_volume = weekly_volumes.total_volume + (NEW.weight_kg * NEW.reps),
+        est_one_rm = GREATEST(weekly_volumes.est_one_rm, (NEW.weight_kg * (1 + NEW.reps / 30.0))),
+        exercise_count = (
+            SELECT COUNT(DISTINCT exercise_id) 
+            FROM sets s
+            JOIN workouts w ON s.workout_id = w.id
+            WHERE w.user_id = workout_user_id
+            AND get_jst_week_start(w.started_at) = week_start
+            AND s.set_type <> 'warmup'
+        ),
+        set_count = weekly_volumes.set_count + 1,
+        updated_at = now();
+    
+    RETURN NEW;
+END;
+$$ LANGUAGE plpgsql;
+
+CREATE OR REPLACE FUNCTION recalculate_weekly_volume_after_delete() 
+RETURNS TRIGGER AS $$
+DECLARE
+    workout_start TIMESTAMPTZ;
+    workout_user_id TEXT;
+    week_start DATE;
+    new_total_volume NUMERIC(10,2);
+    new_est_one_rm NUMERIC(10,2);
+    new_exercise_count INTEGER;
+    new_set_count INTEGER;
+BEGIN
+    -- Get workout information
+    SELECT started_at, user_id INTO workout_start, workout_user_id
+    FROM workouts
+    WHERE id = OLD.workout_id;
+    
+    -- Calculate the week start date in JST
+    week_start := get_jst_week_start(workout_start);
+    
+    -- Recalculate total volume for the week
+    SELECT COALESCE(SUM(weight_kg * reps), 0) INTO new_total_volume
+    FROM sets s
+    JOIN workouts w ON s.workout_id = w.id
+    WHERE w.user_id = workout_user_id
+    AND get_jst_week_start(w.started_at) = week_start
+    AND s.set_type <> 'warmup';
+    
+    -- Recalculate estimated 1RM for the week
+    SELECT COALESCE(MAX(weight_kg * (1 + reps / 30.0)), 0) INTO new_est_one_rm
+    FROM sets s
+    JOIN workouts w ON s.workout_id = w.id
+    WHERE w.user_id = workout_user_id
+    AND get_jst_week_start(w.started_at) = week_start
+    AND s.set_type <> 'warmup';
+    
+    -- Count unique exercises for the week
+    SELECT COUNT(DISTINCT exercise_id) INTO new_exercise_count
+    FROM sets s
+    JOIN workouts w ON s.workout_id = w.id
+    WHERE w.user_id = workout_user_id
+    AND get_jst_week_start(w.started_at) = week_start
+    AND s.set_type <> 'warmup';
+    
+    -- Count total sets for the week
+    SELECT COUNT(*) INTO new_set_count
+    FROM sets s
+    JOIN workouts w ON s.workout_id = w.id
+    WHERE w.user_id = workout_user_id
+    AND get_jst_week_start(w.started_at) = week_start
+    AND s.set_type <> 'warmup';
+    
+    -- Update weekly volume record
+    UPDATE weekly_volumes
+    SET 
+        total_volume = new_total_volume,
+        est_one_rm = new_est_one_rm,
+        exercise_count = new_exercise_count,
+        set_count = new_set_count,
+        updated_at = now()
+    WHERE user_id = workout_user_id
+    AND week_start_date = week_start;
+    
+    RETURN OLD;
+END;
+$$ LANGUAGE plpgsql;
+
+CREATE OR REPLACE FUNCTION recalculate_weekly_volume_after_update() 
+RETURNS TRIGGER AS $$
+DECLARE
+    workout_start TIMESTAMPTZ;
+    workout_user_id TEXT;
+    week_start DATE;
+    new_total_volume NUMERIC(10,2);
+    new_est_one_rm NUMERIC(10,2);
+    new_exercise_count INTEGER;
+    new_set_count INTEGER;
+BEGIN
+    -- Get workout information
+    SELECT started_at, user_id INTO workout_start, workout_user_id
+    FROM workouts
+    WHERE id = NEW.workout_id;
+    
+    -- Calculate the week start date in JST
+    week_start := get_jst_week_start(workout_start);
+    
+    -- Recalculate total volume for the week
+    SELECT COALESCE(SUM(weight_kg * reps), 0) INTO new_total_volume
+    FROM sets s
+    JOIN workouts w ON s.workout_id = w.id
+    WHERE w.user_id = workout_user_id
+    AND get_jst_week_start(w.started_at) = week_start
+    AND s.set_type <> 'warmup';
+    
+    -- Recalculate estimated 1RM for the week
+    SELECT COALESCE(MAX(weight_kg * (1 + reps / 30.0)), 0) INTO new_est_one_rm
+    FROM sets s
+    JOIN workouts w ON s.workout_id = w.id
+    WHERE w.user_id = workout_user_id
+    AND get_jst_week_start(w.started_at) = week_start
+    AND s.set_type <> 'warmup';
+    
+    -- Count unique exercises and sets for the week (set_type may have changed)
+    SELECT COUNT(DISTINCT exercise_id), COUNT(*) INTO new_exercise_count, new_set_count
+    FROM sets s
+    JOIN workouts w ON s.workout_id = w.id
+    WHERE w.user_id = workout_user_id
+    AND get_jst_week_start(w.started_at) = week_start
+    AND s.set_type <> 'warmup';
+    
+    -- Upsert weekly volume record (a week whose sets were all warm-ups has no row yet)
+    INSERT INTO weekly_volumes (
+        user_id,
+        week_start_date,
+        total_volume,
+        est_one_rm,
+        exercise_count,
+        set_count
+    )
+    VALUES (
+        workout_user_id,
+        week_start,
+        new_total_volume,
+        new_est_one_rm,
+        new_exercise_count,
+        new_set_count
+    )
+    ON CONFLICT (user_id, week_start_date) DO UPDATE
+    SET 
+        total_volume = EXCLUDED.total_volume,
+        est_one_rm = EXCLUDED.est_one_rm,
+        exercise_count = EXCLUDED.exercise_count,
+        set_count = EXCLUDED.set_count,
+        updated_at = now();
+    
+    RETURN NEW;
+END;
+$$ LANGUAGE plpgsql;
+
+CREATE OR REPLACE FUNCTION populate_weekly_volumes() 
+RETURNS void AS $$
+BEGIN
+    -- Clear existing data
+    DELETE FROM weekly_volumes;
+    
+    -- Insert aggregated data for all weeks
+    INSERT INTO weekly_volumes (
+        user_id,
+        week_start_date,
+        total_volume,
+        est_one_rm,
+        exercise_count,
+        set_count
+    )
+    SELECT 
+        w.user_id,
+        get_jst_week_start(w.started_at) AS week_start_date,
+        SUM(s.weight_kg * s.reps) AS total_volume,
+        MAX(s.weight_kg * (1 + s.reps / 30.0)) AS est_one_rm,
+        COUNT(DISTINCT s.exercise_id) AS exercise_count,
+        COUNT(s.id) AS set_count
+    FROM workouts w
+    JOIN sets s ON w.id = s.workout_id
+    WHERE s.set_type <> 'warmup'
+    GROUP BY w.user_id, week_start_date;
+END;
+$$ LANGUAGE plpgsql;
+
+DROP FUNCTION IF EXISTS set_effective_load_kg(TEXT, UUID, NUMERIC, TIMESTAMPTZ);
+DROP FUNCTION IF EXISTS get_body_weight_on(TEXT, DATE);
+
+DROP TABLE IF EXISTS body_weights;
+
+ALTER TABLE exercises DROP COLUMN IF EXISTS load_type;
+
+-- Rebuild weekly_volumes with the previous aggregation rules
+SELECT populate_weekly_volumes();
@@ -0,0 +1,347 @@
+-- Migration to add load types (external / bodyweight / bodyweight_plus / assisted) to exercises
+-- and a per-user body weight log.
+-- Volume and estimated 1RM in weekly_volumes are calculated from the effective load,
+-- which uses the body weight on the workout date for bodyweight / assisted exercises.
+
+ALTER TABLE exercises
+    ADD COLUMN load_type TEXT NOT NULL DEFAULT 'external'
+        CHECK (load_type IN ('external', 'bodyweight', 'bodyweight_plus', 'assisted'));
+
+-- Set load types for seeded bodyweight exercises
+UPDATE exercises SET load_type = 'bodyweight'
+WHERE name IN ('プッシュアップ', 'クランチ', 'レッグレイズ', 'プランク', 'ロシアンツイスト', 'アブローラー', 'バックエクステンション');
+
+UPDATE exercises SET load_type = 'bodyweight_plus'
+WHERE name IN ('懸垂', 'ディップス');
+
+INSERT INTO exercises (name, load_type) VALUES
+('アシスト懸垂', 'assisted'),
+('アシストディップス', 'assisted')
+ON CONFLICT (name) DO NOTHING;
+
+CREATE TABLE body_weights (
+  id          UUID PRIMARY KEY DEFAULT uuid_generate_v4(),
+  user_id     TEXT NOT NULL,
+  measured_on DATE NOT NULL, -- 計測日 (JST)
+  weight_kg   NUMERIC(5,2) NOT NULL CHECK (weight_kg > 0),
+  created_at  TIMESTAMPTZ NOT NULL DEFAULT now(),
+  updated_at  TIMESTAMPTZ NOT NULL DEFAULT now(),
+  UNIQUE (user_id, measured_on)
+);
+
+CREATE INDEX idx_body_weights_user_date ON body_weights (user_id, measured_on);
+
+-- Get the user's body weight on the given date:
+-- the latest record on or before the date, falling back to the earliest record after it
+CREATE OR REPLACE FUNCTION get_body_weight_on(p_user_id TEXT, p_date DATE)
+RETURNS NUMERIC AS $$
+    SELECT weight_kg
+    FROM body_weights
+    WHERE user_id = p_user_id
+    ORDER BY (measured_on <= p_date) DESC,
+             CASE WHEN measured_on <= p_date THEN p_date - measured_on ELSE measured_on - p_date END
+    LIMIT 1;
+$$ LANGUAGE sql STABLE;
+
+-- Calculate the effective load of a set from the exercise's load type and the body weight on the workout date
+--   external:        weight_kg
+--   bodyweight:      body weight (weight_kg when no body weight is recorded)
+--   bodyweight_plus: body weight + weight_kg
+--   assisted:        body weight - weight_kg (not below 0)
+CREATE OR REPLACE FUNCTION set_effective_load_kg(p_user_id TEXT, p_exercise_id UUID, p_weight_kg NUMERIC, p_started_at TIMESTAMPTZ)
+RETURNS NUMERIC AS $$
+DECLARE
+    exercise_load_type TEXT;
+    body_weight NUMERIC;
+BEGIN
+    SELECT load_type INTO exercise_load_type FROM exercises WHERE id = p_exercise_id;
+
+    IF exercise_load_type IS NULL OR exercise_load_type = 'external' THEN
+        RETURN p_weight_kg;
+    END IF;
+
+    body_weight := get_body_weight_on(p_user_id, (p_started_at AT TIME ZONE 'Asia/Tokyo')::DATE);
+
+    RETURN CASE exercise_load_type
+        WHEN 'bodyweight' THEN COALESCE(body_weight, p_weight_kg)
+        WHEN 'bodyweight_plus' THEN COALESCE(body_weight, 0) + p_weight_kg
+        WHEN 'assisted' THEN GREATEST(COALESCE(body_weight, 0) - p_weight_kg, 0)
+    END;
+END;
+$$ LANGUAGE plpgsql STABLE;
+
+CREATE OR REPLACE FUNCTION update_weekly_volume() 
+RETURNS TRIGGER AS $$
+DECLARE
+    workout_start TIMESTAMPTZ;
+    workout_user_id TEXT;
+    week_start DATE;
+    effective_load NUMERIC;
+BEGIN
+    -- Warm-up sets are excluded from volume and 1RM aggregation
+    IF NEW.set_type = 'warmup' THEN
+        RETURN NEW;
+    END IF;
+
+    -- Get workout information
+    SELECT started_at, user_id INTO workout_start, workout_user_id
+    FROM workouts
+    WHERE id = NEW.workout_id;
+    
+    -- Calculate the week start date in JST
+    week_start := get_jst_week_start(workout_start);
+
+    -- Effective load (bodyweight / assisted exercises use the body weight on the workout date)
+    effective_load := set_effective_load_kg(workout_user_id, NEW.exercise_id, NEW.weight_kg, workout_start);
+    
+    -- Update or insert weekly volume record
+    INSERT INTO weekly_volumes (
+        user_id, 
+        week_start_date, 
+        total_volume,
+        est_one_rm,
+        exercise_count,
+        set_count
+    )
+    VALUES (
+        workout_user_id,
+        week_start,
+        (effective_load * NEW.reps),
+        (effective_load * (1 + NEW.reps / 30.0)), -- Simple Epley formula for 1RM estimation
+        1,
+        1
+    )
+    ON CONFLICT (user_id, week_start_date) DO UPDATE
+    SET 
+        total_volume = weekly_volumes.total_volume + (effective_load * NEW.reps),
+        est_one_rm = GREATEST(weekly_volumes.est_one_rm, (effective_load * (1 + NEW.reps / 30.0))),
+        exercise_count = (
+            SELECT COUNT(DISTINCT exercise_id) 
+            FROM sets s
+            JOIN workouts w ON s.workout_id = w.id
+            WHERE w.user_id = workout_user_id
+            AND get_jst_week_start(w.started_at) = week_start
+            AND s.set_type <> 'warmup'
+        ),
+        set_count = weekly_volumes.set_count + 1,
+        updated_at = now();
+    
+    RETURN NEW;
+END;
+$$ LANGUAGE plpgsql;
+
+CREATE OR REPLACE FUNCTION recalculate_weekly_volume_after_delete() 
+RETURNS TRIGGER AS $$
+DECLARE
+    workout_start TIMESTAMPTZ;
+    workout_user_id TEXT;
+    week_start DATE;
+    new_total_volume NUMERIC(10,2);
+    new_est_one_rm NUMERIC(10,2);
+    new_exercise_count INTEGER;
+    new_set_count INTEGER;
+BEGIN
+    -- Get workout information
+    SELECT started_at, user_id INTO workout_start, workout_user_id
+    FROM workouts
+    WHERE id = OLD.workout_id;
+    
+    -- Calculate the week start date in JST
+    week_start := get_jst_week_start(workout_start);
+    
+    -- Recalculate total volume for the week
+    SELECT COALESCE(SUM(set_effective_load_kg(w.user_id, s.exercise_id, s.weight_kg, w.started_at) * s.reps), 0) INTO new_total_volume
+    FROM sets s
+    JOIN workouts w ON s.workout_id = w.id
+    WHERE w.user_id = workout_user_id
+    AND get_jst_week_start(w.started_at) = week_start
+    AND s.set_type <> 'warmup';
+    
+    -- Recalculate estimated 1RM for the week
+    SELECT COALESCE(MAX(set_effective_load_kg(w.user_id, s.exercise_id, s.weight_kg, w.started_at) * (1 + s.reps / 30.0)), 0) INTO new_est_one_rm
+    FROM sets s
+    JOIN workouts w ON s.workout_id = w.id
+    WHERE w.user_id = workout_user_id
+    AND get_jst_week_start(w.started_at) = week_start
+    AND s.set_type <> 'warmup';
+    
+    -- Count unique exercises for the week
+    SELECT COUNT(DISTINCT exercise_id) INTO new_exercise_count
+    FROM sets s
+    JOIN workouts w ON s.workout_id = w.id
+    WHERE w.user_id = workout_user_id
+    AND get_jst_week_start(w.started_at) = week_start
+    AND s.set_type <> 'warmup';
+    
+    -- Count total sets for the week
+    SELECT COUNT(*) INTO new_set_count
+    FROM sets s
+    JOIN workouts w ON s.workout_id = w.id
+    WHERE w.user_id = workout_user_id
+    AND get_jst_week_start(w.started_at) = week_start
+    AND s.set_type <> 'warmup';
+    
+    -- Update weekly volume record
+    UPDATE weekly_volumes
+    SET 
+        total_volume = new_total_volume,
+        est_one_rm = new_est_one_rm,
+        exercise_count = new_exercise_count,
+        set_count = new_set_count,
+        updated_at = now()
+    WHERE user_id = workout_user_id
+    AND week_start_date = week_start;
+    
+    RETURN OLD;
+END;
+$$ LANGUAGE plpgsql;
+
+CREATE OR REPLACE FUNCTION recalculate_weekly_volume_after_update() 
+RETURNS TRIGGER AS $$
+DECLARE
+    workout_start TIMESTAMPTZ;
+    workout_user_id TEXT;
+    week_start DATE;
+    new_total_volume NUMERIC(10,2);
+    new_est_one_rm NUMERIC(10,2);
+    new_exercise_count INTEGER;
+    new_set_count INTEGER;
+BEGIN
+    -- Get workout information
+    SELECT started_at, user_id INTO workout_start, workout_user_id
+    FROM workouts
+    WHERE id = NEW.workout_id;
+    
+    -- Calculate the week start date in JST
+    week_start := get_jst_week_start(workout_start);
+    
+    -- Recalculate total volume for the week
+    SELECT COALESCE(SUM(set_effective_load_kg(w.user_id, s.exercise_id, s.weight_kg, w.started_at) * s.reps), 0) INTO new_total_volume
+    FROM sets s
+    JOIN workouts w ON s.workout_id = w.id
+    WHERE w.user_id = workout_user_id
+    AND get_jst_week_start(w.started_at) = week_start
+    AND s.set_type <> 'warmup';
+    
+    -- Recalculate estimated 1RM for the week
+    SELECT COALESCE(MAX(set_effective_load_kg(w.user_id, s.exercise_id, s.weight_kg, w.started_at) * (1 + s.reps / 30.0)), 0) INTO new_est_one_rm
+    FROM sets s
+    JOIN workouts w ON s.workout_id = w.id
+    WHERE w.user_id = workout_user_id
+    AND get_jst_week_start(w.started_at) = week_start
+    AND s.set_type <> 'warmup';
+    
+    -- Count unique exercises and sets for the week (set_type may have changed)
+    SELECT COUNT(DISTINCT exercise_id), COUNT(*) INTO new_exercise_count, new_set_count
+    FROM sets s
+    JOIN workouts w ON s.workout_id = w.id
+    WHERE w.user_id = workout_user_id
+    AND get_jst_week_start(w.started_at) = week_start
+    AND s.set_type <> 'warmup';
+    
+    -- Upsert weekly volume record (a week whose sets were all warm-ups has no row yet)
+    INSERT INTO weekly_volumes (
+        user_id,
+        week_start_date,
+        total_volume,
+        est_one_rm,
+        exercise_count,
+        set_count
+    )
+    VALUES (
+        workout_user_id,
+        week_start,
+        new_total_volume,
+        new_est_one_rm,
+        new_exercise_count,
+        new_set_count
+    )
+    ON CONFLICT (user_id, week_start_date) DO UPDATE
+    SET 
+        total_volume = EXCLUDED.total_volume,
+        est_one_rm = EXCLUDED.est_one_rm,
+        exercise_count = EXCLUDED.exercise_count,
+        set_count = EXCLUDED.set_count,
+        updated_at = now();
+    
+    RETURN NEW;
+END;
+$$ LANGUAGE plpgsql;
+
+CREATE OR REPLACE FUNCTION populate_weekly_volumes() 
+RETURNS void AS $$
+BEGIN
+    -- Clear existing data
+    DELETE FROM weekly_volumes;
+    
+    -- Insert aggregated data for all weeks
+    INSERT INTO weekly_volumes (
+        user_id,
+        week_start_date,
+        total_volume,
+        est_one_rm,
+        exercise_count,
+        set_count
+    )
+    SELECT 
+        w.user_id,
+        get_jst_week_start(w.started_at) AS week_start_date,
+        SUM(set_effective_load_kg(w.user_id, s.exercise_id, s.weight_kg, w.started_at) * s.reps) AS total_volume,
+        MAX(set_effective_load_kg(w.user_id, s.exercise_id, s.weight_kg, w.started_at) * (1 + s.reps / 30.0)) AS est_one_rm,
+        COUNT(DISTINCT s.exercise_id) AS exercise_count,
+        COUNT(s.id) AS set_count
+    FROM workouts w
+    JOIN sets s ON w.id = s.workout_id
+    WHERE s.set_type <> 'warmup'
+    GROUP BY w.user_id, week_start_date;
+END;
+$$ LANGUAGE plpgsql;
+
+-- Create function to recalculate all weekly volumes of a user when the body weight log changes
+-- (effective load of bodyweight / assisted exercises depends on the body weight)
+CREATE OR REPLACE FUNCTION recalculate_weekly_volumes_after_body_weight_change() 
+RETURNS TRIGGER AS $$
+DECLARE
+    target_user_id TEXT;
+BEGIN
+    IF TG_OP = 'DELETE' THEN
+        target_user_id := OLD.user_id;
+    ELSE
+        target_user_id := NEW.user_id;
+    END IF;
+
+    DELETE FROM weekly_volumes WHERE user_id = target_user_id;
+
+    INSERT INTO weekly_volumes (
+        user_id,
+        week_start_date,
+        total_volume,
+        est_one_rm,
+        exercise_count,
+        set_count
+    )
+    SELECT 
+        w.user_id,
+        get_jst_week_start(w.started_at) AS week_start_date,
+        SUM(set_effective_load_kg(w.user_id, s.exercise_id, s.weight_kg, w.started_at) * s.reps) AS total_volume,
+        MAX(set_effective_load_kg(w.user_id, s.exercise_id, s.weight_kg, w.started_at) * (1 + s.reps / 30.0)) AS est_one_rm,
+        COUNT(DISTINCT s.exercise_id) AS exercise_count,
+        COUNT(s.id) AS set_count
+    FROM workouts w
+    JOIN sets s ON w.id = s.workout_id
+    WHERE w.user_id = target_user_id
+    AND s.set_type <> 'warmup'
+    GROUP BY w.user_id, week_start_date;
+
+    RETURN NULL;
+END;
+$$ LANGUAGE plpgsql;
+
+-- Create trigger to recalculate weekly_volumes when a body weight record is changed
+CREATE TRIGGER after_body_weight_change
+AFTER INSERT OR UPDATE OR DELETE ON body_weights
+FOR EACH ROW
+EXECUTE FUNCTION recalculate_weekly_volumes_after_body_weight_change();
+
+-- Recalculate historical data with effective loads
+SELECT populate_weekly_volumes();
@@ -0,0 +1,81 @@
+-- Revert 20250505_add_body_measurements.
+-- Body weight measurements are moved back to body_weights (the latest measurement of each JST date);
+-- other measurements are discarded.
+
+CREATE TABLE body_weights (
+  id          UUID PRIMARY KEY DEFAULT uuid_generate_v4(),
+  user_id     TEXT NOT NULL,
+  measured_on DATE NOT NULL, -- 計測日 (JST)
+  weight_kg   NUMERIC(5,2) NOT NULL CHECK (weight_kg > 0),
+  created_at  TIMESTAMPTZ NOT NULL DEFAULT now(),
+  updated_at  TIMESTAMPTZ NOT NULL DEFAULT now(),
+  UNIQUE (user_id, measured_on)
+);
+
+CREATE INDEX idx_body_weights_user_date ON body_weights (user_id, measured_on);
+
+INSERT INTO body_weights (user_id, measured_on, weight_kg, created_at, updated_at)
+SELECT DISTINCT ON (user_id, (measured_at AT TIME ZONE 'Asia/Tokyo')::DATE)
+    user_id, (measured_at AT TIME ZONE 'Asia/Tokyo')::DATE, value, created_at, updated_at
+FROM body_measurements
+WHERE metric_code = 'body_weight'
+AND value < 1000 -- body_weights.weight_kg is NUMERIC(5,2)
+ORDER BY user_id, (measured_at AT TIME ZONE 'Asia/Tokyo')::DATE, measured_at DESC;
+
+DROP TRIGGER IF EXISTS after_body_weight_change ON body_measurements;
+
+CREATE OR REPLACE FUNCTION get_body_weight_on(p_user_id TEXT, p_date DATE)
+RETURNS NUMERIC AS $$
+    SELECT weight_kg
+    FROM body_weights
+    WHERE user_id = p_user_id
+    ORDER BY (measured_on <= p_date) DESC,
+             CASE WHEN measured_on <= p_date THEN p_date - measured_on ELSE measured_on - p_date END
+    LIMIT 1;
+$$ LANGUAGE sql STABLE;
+
+CREATE OR REPLACE FUNCTION recalculate_weekly_volumes_after_body_weight_change() 
+RETURNS TRIGGER AS $$
+DECLARE
+    target_user_id TEXT;
+BEGIN
+    IF TG_OP = 'DELETE' THEN
+        target_user_id := OLD.user_id;
+    ELSE
+        target_user_id := NEW.user_id;
+    END IF;
+
+    DELETE FROM weekly_volumes WHERE user_id = target_user_id;
+
+    INSERT INTO weekly_volumes (
+        user_id,
+        week_start_date,
+        total_volume,
+        est_one_rm,
+        exercise_count,
+        set_count
+    )
+    SELECT 
+        w.user_id,
+        get_jst_week_start(w.started_at) AS week_start_date,
+        SUM(set_effective_load_kg(w.user_id, s.exercise_id, s.weight_kg, w.started_at) * s.reps) AS total_volume,
+        MAX(set_effective_load_kg(w.user_id, s.exercise_id, s.weight_kg, w.started_at) * (1 + s.reps / 30.0)) AS est_one_rm,
+        COUNT(DISTINCT s.exercise_id) AS exercise_count,
+        COUNT(s.id) AS set_count
+    FROM workouts w
+    JOIN sets s ON w.id = s.workout_id
+    WHERE w.user_id = target_user_id
+    AND s.set_type <> 'warmup'
+    GROUP BY w.user_id, week_start_date;
+
+    RETURN NULL;
+END;
+$$ LANGUAGE plpgsql;
+
+CREATE TRIGGER after_body_weight_change
+AFTER INSERT OR UPDATE OR DELETE ON body_weights
+FOR EACH ROW
+EXECUTE FUNCTION recalculate_weekly_volumes_after_body_weight_change();
+
+DROP TABLE IF EXISTS body_measurements;
+DROP TABLE IF EXISTS measurement_metrics;
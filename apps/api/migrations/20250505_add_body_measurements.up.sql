@@ -0,0 +1,113 @@
+-- Migration to add a body measurement subsystem (body weight, body-fat percentage, circumferences).
+-- body_weights is replaced by body_measurements (metric_code = 'body_weight'),
+-- which is also used for the effective load of bodyweight / assisted exercises.
+
+-- 計測項目マスター (体重・体脂肪率・周囲径など)
+CREATE TABLE measurement_metrics (
+  code       TEXT PRIMARY KEY, -- 例: body_weight, body_fat_percentage, waist
+  name       TEXT NOT NULL,
+  unit       TEXT NOT NULL, -- kg / % / cm
+  sort_order INT  NOT NULL DEFAULT 0
+);
+
+
+INSERT INTO measurement_metrics (code, name, unit, sort_order) VALUES
+('body_weight', '体重', 'kg', 10),
+('body_fat_percentage', '体脂肪率', '%', 20),
+('skeletal_muscle_mass', '骨格筋量', 'kg', 30),
+('neck', '首囲', 'cm', 40),
+('chest', '胸囲', 'cm', 50),
+('waist', 'ウエスト', 'cm', 60),
+('hip', 'ヒップ', 'cm', 70),
+('arm', '上腕囲', 'cm', 80),
+('thigh', '太もも囲', 'cm', 90),
+('calf', 'ふくらはぎ囲', 'cm', 100)
+ON CONFLICT (code) DO NOTHING;
+
+-- body_measurements: ユーザーごとの身体計測記録
+CREATE TABLE body_measurements (
+  id          UUID PRIMARY KEY DEFAULT uuid_generate_v4(),
+  user_id     TEXT NOT NULL,
+  metric_code TEXT NOT NULL REFERENCES measurement_metrics(code) ON DELETE RESTRICT,
+  value       NUMERIC(7,2) NOT NULL CHECK (value > 0),
+  measured_at TIMESTAMPTZ NOT NULL DEFAULT now(),
+  note        TEXT,
+  created_at  TIMESTAMPTZ NOT NULL DEFAULT now(),
+  updated_at  TIMESTAMPTZ NOT NULL DEFAULT now()
+);
+
+
+CREATE INDEX idx_body_measurements_user_metric_time ON body_measurements (user_id, metric_code, measured_at);
+
+-- Move body weight records (measured at 00:00 JST of the recorded date)
+INSERT INTO body_measurements (user_id, metric_code, value, measured_at, created_at, updated_at)
+SELECT user_id, 'body_weight', weight_kg, measured_on::TIMESTAMP AT TIME ZONE 'Asia/Tokyo', created_at, updated_at
+FROM body_weights;
+
+DROP TABLE body_weights;
+DROP FUNCTION IF EXISTS recalculate_weekly_volumes_after_body_weight_change();
+
+-- Get the user's body weight (body_measurements.metric_code = 'body_weight') on the given date (JST):
+-- the latest record on or before the date, falling back to the earliest record after it
+CREATE OR REPLACE FUNCTION get_body_weight_on(p_user_id TEXT, p_date DATE)
+RETURNS NUMERIC AS $$
+    SELECT value
+    FROM body_measurements
+    WHERE user_id = p_user_id
+    AND metric_code = 'body_weight'
+    ORDER BY ((measured_at AT TIME ZONE 'Asia/Tokyo')::DATE <= p_date) DESC,
+             CASE WHEN (measured_at AT TIME ZONE 'Asia/Tokyo')::DATE <= p_date THEN -EXTRACT(EPOCH FROM measured_at) ELSE EXTRACT(EPOCH FROM measured_at) END
+    LIMIT 1;
+$$ LANGUAGE sql STABLE;
+
+-- Create function to recalculate all weekly volumes of a user when a body weight measurement changes
+-- (effective load of bodyweight / assisted exercises depends on the body weight)
+CREATE OR REPLACE FUNCTION recalculate_weekly_volumes_after_body_weight_change() 
+RETURNS TRIGGER AS $$
+DECLARE
+    target_user_id TEXT;
+BEGIN
+    IF TG_OP = 'DELETE' THEN
+        IF OLD.metric_code <> 'body_weight' THEN
+            RETURN NULL;
+        END IF;
+        target_user_id := OLD.user_id;
+    ELSE
+        IF NEW.metric_code <> 'body_weight' AND (TG_OP = 'INSERT' OR OLD.metric_code <> 'body_weight') THEN
+            RETURN NULL;
+        END IF;
+        target_user_id := NEW.user_id;
+    END IF;
+
+    DELETE FROM weekly_volumes WHERE user_id = target_user_id;
+
+    INSERT INTO weekly_volumes (
+        user_id,
+        week_start_date,
+        total_volume,
+        est_one_rm,
+        exercise_count,
+        set_count
+    )
+    SELECT 
+        w.user_id,
+        get_jst_week_start(w.started_at) AS week_start_date,
+        SUM(set_effective_load_kg(w.user_id, s.exercise_id, s.weight_kg, w.started_at) * s.reps) AS total_volume,
+        MAX(set_effective_load_kg(w.user_id, s.exercise_id, s.weight_kg, w.started_at) * (1 + s.reps / 30.0)) AS est_one_rm,
+        COUNT(DISTINCT s.exercise_id) AS exercise_count,
+        COUNT(s.id) AS set_count
+    FROM workouts w
+    JOIN sets s ON w.id = s.workout_id
+    WHERE w.user_id = target_user_id
+    AND s.set_type <> 'warmup'
+    GROUP BY w.user_id, week_start_date;
+
+    RETURN NULL;
+END;
+$$ LANGUAGE plpgsql;
+
+-- Create trigger to recalculate weekly_volumes when a body weight measurement is changed
+CREATE TRIGGER after_body_weight_change
+AFTER INSERT OR UPDATE OR DELETE ON body_measurements
+FOR EACH ROW
+EXECUTE FUNCTION recalculate_weekly_volumes_after_body_weight_change();
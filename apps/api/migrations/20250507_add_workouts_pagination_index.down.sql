@@ -0,0 +1,3 @@
+-- Revert 20250507_add_workouts_pagination_index.
+
+DROP INDEX IF EXISTS idx_workouts_user_started_at;
@@ -0,0 +1,4 @@
+-- Migration to add an index for cursor pagination of workout history (GET /workouts).
+-- Workouts are paged by (started_at, id) per user in both ascending and descending order.
+
+CREATE INDEX IF NOT EXISTS idx_workouts_user_started_at ON workouts (user_id, started_at, id);
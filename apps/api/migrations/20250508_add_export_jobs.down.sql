@@ -0,0 +1,3 @@
+-- Revert 20250508_add_export_jobs.
+
+DROP TABLE IF EXISTS export_jobs;
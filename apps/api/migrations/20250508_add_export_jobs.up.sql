@@ -0,0 +1,20 @@
+-- Migration to add asynchronous data export jobs (POST /me/exports).
+-- A completed job keeps its ZIP archive (JSON + CSV files) for 7 days so that it can be downloaded.
+
+-- export_jobs: データエクスポートの非同期ジョブ (完了すると ZIP アーカイブをダウンロードできる)
+CREATE TABLE IF NOT EXISTS export_jobs (
+  id           UUID PRIMARY KEY DEFAULT uuid_generate_v4(),
+  user_id      TEXT NOT NULL,
+  status       TEXT NOT NULL DEFAULT 'pending' CHECK (status IN ('pending', 'running', 'completed', 'failed')),
+  from_date    DATE, -- NULL は期間の指定なし
+  to_date      DATE,
+  archive      BYTEA, -- 完了したジョブの ZIP アーカイブ
+  archive_size BIGINT,
+  error        TEXT,
+  created_at   TIMESTAMPTZ NOT NULL DEFAULT now(),
+  started_at   TIMESTAMPTZ,
+  completed_at TIMESTAMPTZ,
+  expires_at   TIMESTAMPTZ -- アーカイブの保持期限 (完了から7日)
+);
+
+CREATE INDEX IF NOT EXISTS idx_export_jobs_user_created_at ON export_jobs (user_id, created_at);
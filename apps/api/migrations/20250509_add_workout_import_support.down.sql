@@ -0,0 +1,69 @@
+-- Revert 20250509_add_workout_import_support.
+-- Restoring the unique constraint on exercises.name fails if users have custom exercises
+-- with the same name as another exercise; rename or merge them first.
+
+CREATE OR REPLACE FUNCTION update_weekly_volume() 
+RETURNS TRIGGER AS $$
+DECLARE
+    workout_start TIMESTAMPTZ;
+    workout_user_id TEXT;
+    week_start DATE;
+    effective_load NUMERIC;
+BEGIN
+    -- Warm-up sets and cardio / timed sets are excluded from volume and 1RM aggregation
+    IF NEW.set_type = 'warmup' OR NOT is_strength_exercise(NEW.exercise_id) THEN
+        RETURN NEW;
+    END IF;
+
+    -- Get workout information
+    SELECT started_at, user_id INTO workout_start, workout_user_id
+    FROM workouts
+    WHERE id = NEW.workout_id;
+    
+    -- Calculate the week start date in JST
+    week_start := get_jst_week_start(workout_start);
+
+    -- Effective load (bodyweight / assisted exercises use the body weight on the workout date)
+    effective_load := set_effective_load_kg(workout_user_id, NEW.exercise_id, NEW.weight_kg, workout_start);
+    
+    -- Update or insert weekly volume record
+    INSERT INTO weekly_volumes (
+        user_id, 
+        week_start_date, 
+        total_volume,
+        est_one_rm,
+        exercise_count,
+        set_count
+    )
+    VALUES (
+        workout_user_id,
+        week_start,
+        (effective_load * NEW.reps),
+        (effective_load * (1 + NEW.reps / 30.0)), -- Simple Epley formula for 1RM estimation
+        1,
+        1
+    )
+    ON CONFLICT (user_id, week_start_date) DO UPDATE
+    SET 
+        total_volume = weekly_volumes.total_volume + (effective_load * NEW.reps),
+        est_one_rm = GREATEST(weekly_volumes.est_one_rm, (effective_load * (1 + NEW.reps / 30.0))),
+        exercise_count = (
+            SELECT COUNT(DISTINCT exercise_id) 
+            FROM sets s
+            JOIN workouts w ON s.workout_id = w.id
+            WHERE w.user_id = workout_user_id
+            AND get_jst_week_start(w.started_at) = week_start
+            AND s.set_type <> 'warmup'
+            AND is_strength_exercise(s.exercise_id)
+        ),
+        set_count = weekly_volumes.set_count + 1,
+        updated_at = now();
+    
+    RETURN NEW;
+END;
+$$ LANGUAGE plpgsql;
+
+DROP INDEX IF EXISTS idx_exercises_custom_name;
+DROP INDEX IF EXISTS idx_exercises_builtin_name;
+
+ALTER TABLE exercises ADD CONSTRAINT exercises_name_key UNIQUE (name);
@@ -0,0 +1,79 @@
+-- Migration to support importing workout history from other apps (Strong, Hevy, FitNotes).
+-- 1. Exercise names are unique among built-in exercises and per user among custom exercises,
+--    so that importing users can create custom exercises with the same name.
+-- 2. The per-row weekly_volumes insert trigger can be skipped for a transaction;
+--    imports recalculate each affected week once at the end instead.
+
+ALTER TABLE exercises DROP CONSTRAINT IF EXISTS exercises_name_key;
+
+-- 種目名は組み込み種目の間、ユーザーごとのカスタム種目の間でそれぞれ一意 (他のユーザーのカスタム種目とは重複してよい)
+CREATE UNIQUE INDEX IF NOT EXISTS idx_exercises_builtin_name ON exercises (name) WHERE created_by_user_id IS NULL;
+CREATE UNIQUE INDEX IF NOT EXISTS idx_exercises_custom_name ON exercises (created_by_user_id, name) WHERE created_by_user_id IS NOT NULL;
+
+-- Create function to update weekly_volumes when a new set is added or updated
+CREATE OR REPLACE FUNCTION update_weekly_volume() 
+RETURNS TRIGGER AS $$
+DECLARE
+    workout_start TIMESTAMPTZ;
+    workout_user_id TEXT;
+    week_start DATE;
+    effective_load NUMERIC;
+BEGIN
+    -- Bulk imports skip the per-row update and recalculate each affected week once at the end
+    -- (enabled for the transaction with set_config('bulktrack.skip_weekly_volume_trigger', 'on', true))
+    IF current_setting('bulktrack.skip_weekly_volume_trigger', true) = 'on' THEN
+        RETURN NEW;
+    END IF;
+
+    -- Warm-up sets and cardio / timed sets are excluded from volume and 1RM aggregation
+    IF NEW.set_type = 'warmup' OR NOT is_strength_exercise(NEW.exercise_id) THEN
+        RETURN NEW;
+    END IF;
+
+    -- Get workout information
+    SELECT started_at, user_id INTO workout_start, workout_user_id
+    FROM workouts
+    WHERE id = NEW.workout_id;
+    
+    -- Calculate the week start date in JST
+    week_start := get_jst_week_start(workout_start);
+
+    -- Effective load (bodyweight / assisted exercises use the body weight on the workout date)
+    effective_load := set_effective_load_kg(workout_user_id, NEW.exercise_id, NEW.weight_kg, workout_start);
+    
+    -- Update or insert weekly volume record
+    INSERT INTO weekly_volumes (
+        user_id, 
+        week_start_date, 
+        total_volume,
+        est_one_rm,
+        exercise_count,
+        set_count
+    )
+    VALUES (
+        workout_user_id,
+        week_start,
+        (effective_load * NEW.reps),
+        (effective_load * (1 + NEW.reps / 30.0)), -- Simple Epley formula for 1RM estimation
+        1,
+        1
+    )
+    ON CONFLICT (user_id, week_start_date) DO UPDATE
+    SET 
+        total_volume = weekly_volumes.total_volume + (effective_load * NEW.reps),
+        est_one_rm = GREATEST(weekly_volumes.est_one_rm, (effective_load * (1 + NEW.reps / 30.0))),
+        exercise_count = (
+            SELECT COUNT(DISTINCT exercise_id) 
+            FROM sets s
+            JOIN workouts w ON s.workout_id = w.id
+            WHERE w.user_id = workout_user_id
+            AND get_jst_week_start(w.started_at) = week_start
+            AND s.set_type <> 'warmup'
+            AND is_strength_exercise(s.exercise_id)
+        ),
+        set_count = weekly_volumes.set_count + 1,
+        updated_at = now();
+    
+    RETURN NEW;
+END;
+$$ LANGUAGE plpgsql;
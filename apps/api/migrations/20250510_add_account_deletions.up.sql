@@ -0,0 +1,146 @@
+-- Migration to support account deletion (Right-to-Delete) with a 30-day grace period.
+-- 1. account_deletions keeps the scheduled deletions and an audit record without personal data.
+-- 2. The per-row weekly_volumes triggers on set deletion and body weight changes can be skipped for a transaction
+--    (bulktrack.skip_weekly_volume_trigger); purges delete the user's weekly_volumes as well.
+
+-- account_deletions: アカウント削除 (Right-to-Delete) の予約と監査記録
+-- 完了後は user_id を消去し、削除した件数のみ残す (個人データを含めない)
+CREATE TABLE account_deletions (
+  id            UUID PRIMARY KEY DEFAULT uuid_generate_v4(),
+  user_id       TEXT, -- 完了後は NULL
+  status        TEXT NOT NULL DEFAULT 'pending' CHECK (status IN ('pending', 'cancelled', 'completed')),
+  source        TEXT NOT NULL CHECK (source IN ('api', 'clerk_webhook')),
+  requested_at  TIMESTAMPTZ NOT NULL DEFAULT now(),
+  scheduled_for TIMESTAMPTZ NOT NULL, -- この日時以降に削除する (取り消し期限)
+  cancelled_at  TIMESTAMPTZ,
+  completed_at  TIMESTAMPTZ,
+  purged_counts JSONB -- テーブルごとの削除件数
+);
+
+-- 予約中の削除はユーザーごとに1件
+CREATE UNIQUE INDEX idx_account_deletions_pending_user ON account_deletions (user_id) WHERE status = 'pending';
+CREATE INDEX idx_account_deletions_pending_scheduled_for ON account_deletions (scheduled_for) WHERE status = 'pending';
+
+CREATE OR REPLACE FUNCTION recalculate_weekly_volume_after_delete() 
+RETURNS TRIGGER AS $$
+DECLARE
+    workout_start TIMESTAMPTZ;
+    workout_user_id TEXT;
+    week_start DATE;
+    new_total_volume NUMERIC(10,2);
+    new_est_one_rm NUMERIC(10,2);
+    new_exercise_count INTEGER;
+    new_set_count INTEGER;
+BEGIN
+    -- Account deletion purges skip the per-row recalculation (the user's weekly_volumes are deleted as well)
+    IF current_setting('bulktrack.skip_weekly_volume_trigger', true) = 'on' THEN
+        RETURN OLD;
+    END IF;
+
+    -- Get workout information
+    SELECT started_at, user_id INTO workout_start, workout_user_id
+    FROM workouts
+    WHERE id = OLD.workout_id;
+    
+    -- Calculate the week start date in JST
+    week_start := get_jst_week_start(workout_start);
+    
+    -- Recalculate total volume for the week
+    SELECT COALESCE(SUM(set_effective_load_kg(w.user_id, s.exercise_id, s.weight_kg, w.started_at) * s.reps), 0) INTO new_total_volume
+    FROM sets s
+    JOIN workouts w ON s.workout_id = w.id
+    WHERE w.user_id = workout_user_id
+    AND get_jst_week_start(w.started_at) = week_start
+    AND s.set_type <> 'warmup'
+    AND is_strength_exercise(s.exercise_id);
+    
+    -- Recalculate estimated 1RM for the week
+    SELECT COALESCE(MAX(set_effective_load_kg(w.user_id, s.exercise_id, s.weight_kg, w.started_at) * (1 + s.reps / 30.0)), 0) INTO new_est_one_rm
+    FROM sets s
+    JOIN workouts w ON s.workout_id = w.id
+    WHERE w.user_id = workout_user_id
+    AND get_jst_week_start(w.started_at) = week_start
+    AND s.set_type <> 'warmup'
+    AND is_strength_exercise(s.exercise_id);
+    
+    -- Count unique exercises for the week
+    SELECT COUNT(DISTINCT exercise_id) INTO new_exercise_count
+    FROM sets s
+    JOIN workouts w ON s.workout_id = w.id
+    WHERE w.user_id = workout_user_id
+    AND get_jst_week_start(w.started_at) = week_start
+    AND s.set_type <> 'warmup'
+    AND is_strength_exercise(s.exercise_id);
+    
+    -- Count total sets for the week
+    SELECT COUNT(*) INTO new_set_count
+    FROM sets s
+    JOIN workouts w ON s.workout_id = w.id
+    WHERE w.user_id = workout_user_id
+    AND get_jst_week_start(w.started_at) = week_start
+    AND s.set_type <> 'warmup'
+    AND is_strength_exercise(s.exercise_id);
+    
+    -- Update weekly volume record
+    UPDATE weekly_volumes
+    SET 
+        total_volume = new_total_volume,
+        est_one_rm = new_est_one_rm,
+        exercise_count = new_exercise_count,
+        set_count = new_set_count,
+        updated_at = now()
+    WHERE user_id = workout_user_id
+    AND week_start_date = week_start;
+    
+    RETURN OLD;
+END;
+$$ LANGUAGE plpgsql;
+
+CREATE OR REPLACE FUNCTION recalculate_weekly_volumes_after_body_weight_change() 
+RETURNS TRIGGER AS $$
+DECLARE
+    target_user_id TEXT;
+BEGIN
+    IF current_setting('bulktrack.skip_weekly_volume_trigger', true) = 'on' THEN
+        RETURN NULL;
+    END IF;
+
+    IF TG_OP = 'DELETE' THEN
+        IF OLD.metric_code <> 'body_weight' THEN
+            RETURN NULL;
+        END IF;
+        target_user_id := OLD.user_id;
+    ELSE
+        IF NEW.metric_code <> 'body_weight' AND (TG_OP = 'INSERT' OR OLD.metric_code <> 'body_weight') THEN
+            RETURN NULL;
+        END IF;
+        target_user_id := NEW.user_id;
+    END IF;
+
+    DELETE FROM weekly_volumes WHERE user_id = target_user_id;
+
+    INSERT INTO weekly_volumes (
+        user_id,
+        week_start_date,
+        total_volume,
+        est_one_rm,
+        exercise_count,
+        set_count
+    )
+    SELECT 
+        w.user_id,
+        get_jst_week_start(w.started_at) AS week_start_date,
+        SUM(set_effective_load_kg(w.user_id, s.exercise_id, s.weight_kg, w.started_at) * s.reps) AS total_volume,
+        MAX(set_effective_load_kg(w.user_id, s.exercise_id, s.weight_kg, w.started_at) * (1 + s.reps / 30.0)) AS est_one_rm,
+        COUNT(DISTINCT s.exercise_id) AS exercise_count,
+        COUNT(s.id) AS set_count
+    FROM workouts w
+    JOIN sets s ON w.id = s.workout_id
+    WHERE w.user_id = target_user_id
+    AND s.set_type <> 'warmup'
+    AND is_strength_exercise(s.exercise_id)
+    GROUP BY w.user_id, week_start_date;
+
+    RETURN NULL;
+END;
+$$ LANGUAGE plpgsql;
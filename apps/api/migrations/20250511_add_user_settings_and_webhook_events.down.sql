@@ -0,0 +1,4 @@
+-- Revert 20250511_add_user_settings_and_webhook_events.
+
+DROP TABLE IF EXISTS webhook_events;
+DROP TABLE IF EXISTS user_settings;
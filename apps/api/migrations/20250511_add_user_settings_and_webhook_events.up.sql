@@ -0,0 +1,31 @@
+-- Migration to receive Clerk webhooks for user lifecycle events.
+-- 1. user_settings holds per-user settings provisioned on user.created.
+-- 2. webhook_events records received events for idempotent handling and retries.
+
+-- user_settings: ユーザーごとの設定 (Clerk の user.created Webhook で作成する)
+CREATE TABLE user_settings (
+  user_id     TEXT PRIMARY KEY,
+  time_zone   TEXT NOT NULL DEFAULT 'Asia/Tokyo',
+  weight_unit TEXT NOT NULL DEFAULT 'kg' CHECK (weight_unit IN ('kg', 'lb')),
+  created_at  TIMESTAMPTZ NOT NULL DEFAULT now(),
+  updated_at  TIMESTAMPTZ NOT NULL DEFAULT now()
+);
+
+-- webhook_events: 受け取った Webhook イベント (重複排除と失敗時の再試行に使う)
+-- 個人データを保持しないよう、処理に必要な項目 (種類と対象のユーザーID) のみ保存する
+CREATE TABLE webhook_events (
+  id              UUID PRIMARY KEY DEFAULT uuid_generate_v4(),
+  source          TEXT NOT NULL CHECK (source IN ('clerk')),
+  event_id        TEXT NOT NULL, -- 配信元のイベントID (Svix の svix-id)
+  type            TEXT NOT NULL, -- user.created など
+  user_id         TEXT,
+  status          TEXT NOT NULL DEFAULT 'received' CHECK (status IN ('received', 'processed', 'failed')),
+  attempts        INT  NOT NULL DEFAULT 0,
+  last_error      TEXT,
+  received_at     TIMESTAMPTZ NOT NULL DEFAULT now(),
+  processed_at    TIMESTAMPTZ,
+  next_attempt_at TIMESTAMPTZ, -- 失敗したイベントの次の再試行日時
+  UNIQUE (source, event_id)
+);
+
+CREATE INDEX idx_webhook_events_retry ON webhook_events (next_attempt_at) WHERE status = 'failed';
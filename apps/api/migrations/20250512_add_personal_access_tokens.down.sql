@@ -0,0 +1,3 @@
+-- Revert 20250512_add_personal_access_tokens.
+
+DROP TABLE IF EXISTS personal_access_tokens;
@@ -0,0 +1,18 @@
+-- Migration to add personal access tokens for scripts and integrations.
+
+-- personal_access_tokens: スクリプトや外部連携用のパーソナルアクセストークン
+-- トークンそのものは保存せず、SHA-256 のハッシュのみ保存する
+CREATE TABLE personal_access_tokens (
+  id           UUID PRIMARY KEY DEFAULT uuid_generate_v4(),
+  user_id      TEXT NOT NULL,
+  name         TEXT NOT NULL,
+  token_hash   BYTEA NOT NULL UNIQUE,
+  token_prefix TEXT NOT NULL, -- 一覧で見分けるためのトークンの先頭部分
+  scopes       TEXT[] NOT NULL,
+  expires_at   TIMESTAMPTZ, -- NULL の場合は無期限
+  last_used_at TIMESTAMPTZ,
+  created_at   TIMESTAMPTZ NOT NULL DEFAULT now(),
+  revoked_at   TIMESTAMPTZ
+);
+
+CREATE INDEX idx_personal_access_tokens_user ON personal_access_tokens (user_id, created_at) WHERE revoked_at IS NULL;
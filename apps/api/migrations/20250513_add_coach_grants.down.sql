@@ -0,0 +1,4 @@
+-- Revert 20250513_add_coach_grants.
+
+DROP TABLE IF EXISTS coach_audit_logs;
+DROP TABLE IF EXISTS coach_grants;
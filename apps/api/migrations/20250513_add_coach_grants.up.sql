@@ -0,0 +1,36 @@
+-- Migration to let athletes share their data with coaches.
+-- 1. coach_grants holds invitations and read/write grants from an athlete to a coach.
+-- 2. coach_audit_logs records every request a coach makes on behalf of an athlete.
+
+-- coach_grants: アスリートがコーチに与えたデータへのアクセス権
+-- アスリートが招待コードを発行し (pending)、コーチが受け入れると有効になる (active)
+CREATE TABLE coach_grants (
+  id                UUID PRIMARY KEY DEFAULT uuid_generate_v4(),
+  athlete_user_id   TEXT NOT NULL,
+  coach_user_id     TEXT, -- 招待を受け入れたコーチ (pending の間は NULL)
+  permission        TEXT NOT NULL CHECK (permission IN ('read', 'write')),
+  status            TEXT NOT NULL DEFAULT 'pending' CHECK (status IN ('pending', 'active', 'revoked')),
+  invite_code       TEXT UNIQUE, -- 受け入れ後は NULL
+  invite_expires_at TIMESTAMPTZ NOT NULL,
+  created_at        TIMESTAMPTZ NOT NULL DEFAULT now(),
+  accepted_at       TIMESTAMPTZ,
+  revoked_at        TIMESTAMPTZ
+);
+
+CREATE UNIQUE INDEX idx_coach_grants_active_pair ON coach_grants (athlete_user_id, coach_user_id) WHERE status = 'active';
+CREATE INDEX idx_coach_grants_coach ON coach_grants (coach_user_id) WHERE status = 'active';
+
+-- coach_audit_logs: コーチがアスリートのデータに対して行った操作の記録
+CREATE TABLE coach_audit_logs (
+  id              UUID PRIMARY KEY DEFAULT uuid_generate_v4(),
+  grant_id        UUID NOT NULL REFERENCES coach_grants(id) ON DELETE CASCADE,
+  coach_user_id   TEXT NOT NULL,
+  athlete_user_id TEXT NOT NULL,
+  method          TEXT NOT NULL,
+  route           TEXT NOT NULL, -- ServeMux のパターン (例: PUT /menus/{id})
+  path            TEXT NOT NULL,
+  status_code     INT  NOT NULL,
+  created_at      TIMESTAMPTZ NOT NULL DEFAULT now()
+);
+
+CREATE INDEX idx_coach_audit_logs_grant_created_at ON coach_audit_logs (grant_id, created_at);
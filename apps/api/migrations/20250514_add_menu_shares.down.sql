@@ -0,0 +1,6 @@
+-- Revert 20250514_add_menu_shares.
+-- The curated template menus owned by the reserved user 'bulktrack' are deleted as well.
+
+DROP TABLE IF EXISTS menu_shares;
+
+DELETE FROM menus WHERE user_id = 'bulktrack';
@@ -0,0 +1,101 @@
+-- Migration to share menus as templates.
+-- 1. menu_shares holds share codes that let other users preview and clone a menu.
+-- 2. Curated templates are public shares of menus owned by the reserved user 'bulktrack'.
+
+-- menu_shares: メニューの共有コード (受け取ったユーザーはプレビューして自分のアカウントに複製できる)
+-- is_public のものはテンプレートライブラリに表示する
+CREATE TABLE menu_shares (
+  id          UUID PRIMARY KEY DEFAULT uuid_generate_v4(),
+  menu_id     UUID NOT NULL REFERENCES menus(id) ON DELETE CASCADE,
+  share_code  TEXT NOT NULL UNIQUE,
+  is_public   BOOLEAN NOT NULL DEFAULT false,
+  clone_count INT NOT NULL DEFAULT 0,
+  created_at  TIMESTAMPTZ NOT NULL DEFAULT now(),
+  revoked_at  TIMESTAMPTZ
+);
+
+CREATE UNIQUE INDEX idx_menu_shares_active_menu ON menu_shares (menu_id) WHERE revoked_at IS NULL;
+
+-- テンプレートライブラリ (予約ユーザー bulktrack のメニューを公開する)
+WITH menu AS (
+    INSERT INTO menus (user_id, name, description)
+    VALUES ('bulktrack', '全身 (初心者向け)', '主要な複合種目で全身を鍛える週2〜3回のメニュー')
+    ON CONFLICT (user_id, name) DO NOTHING
+    RETURNING id
+), items AS (
+    INSERT INTO menu_items (menu_id, exercise_id, set_order, planned_sets, planned_reps, planned_interval_seconds)
+    SELECT menu.id, e.id, item.set_order, item.planned_sets, item.planned_reps, item.planned_interval_seconds
+    FROM menu
+    CROSS JOIN (VALUES
+        (1, 'スクワット', 3, 8, 180),
+        (2, 'ベンチプレス', 3, 8, 180),
+        (3, 'ベントオーバーロウ', 3, 8, 120),
+        (4, 'ショルダープレス', 3, 10, 90)
+    ) AS item (set_order, exercise_name, planned_sets, planned_reps, planned_interval_seconds)
+    JOIN exercises e ON e.name = item.exercise_name AND e.created_by_user_id IS NULL
+)
+INSERT INTO menu_shares (menu_id, share_code, is_public)
+SELECT id, 'TPL-FULLBODY', true FROM menu;
+
+WITH menu AS (
+    INSERT INTO menus (user_id, name, description)
+    VALUES ('bulktrack', 'PPL - プッシュ', '胸・肩・上腕三頭筋 (Push / Pull / Legs の分割)')
+    ON CONFLICT (user_id, name) DO NOTHING
+    RETURNING id
+), items AS (
+    INSERT INTO menu_items (menu_id, exercise_id, set_order, planned_sets, planned_reps, planned_interval_seconds)
+    SELECT menu.id, e.id, item.set_order, item.planned_sets, item.planned_reps, item.planned_interval_seconds
+    FROM menu
+    CROSS JOIN (VALUES
+        (1, 'ベンチプレス', 4, 6, 180),
+        (2, 'インクラインベンチプレス', 3, 10, 120),
+        (3, 'ショルダープレス', 3, 10, 90),
+        (4, 'サイドレイズ', 3, 15, 60),
+        (5, 'トライセプスエクステンション', 3, 12, 60)
+    ) AS item (set_order, exercise_name, planned_sets, planned_reps, planned_interval_seconds)
+    JOIN exercises e ON e.name = item.exercise_name AND e.created_by_user_id IS NULL
+)
+INSERT INTO menu_shares (menu_id, share_code, is_public)
+SELECT id, 'TPL-PPL-PUSH', true FROM menu;
+
+WITH menu AS (
+    INSERT INTO menus (user_id, name, description)
+    VALUES ('bulktrack', 'PPL - プル', '背中・上腕二頭筋 (Push / Pull / Legs の分割)')
+    ON CONFLICT (user_id, name) DO NOTHING
+    RETURNING id
+), items AS (
+    INSERT INTO menu_items (menu_id, exercise_id, set_order, planned_sets, planned_reps, planned_interval_seconds)
+    SELECT menu.id, e.id, item.set_order, item.planned_sets, item.planned_reps, item.planned_interval_seconds
+    FROM menu
+    CROSS JOIN (VALUES
+        (1, 'デッドリフト', 3, 5, 180),
+        (2, '懸垂', 3, 8, 120),
+        (3, 'ベントオーバーロウ', 3, 10, 120),
+        (4, 'ラットプルダウン', 3, 12, 90),
+        (5, 'アームカール', 3, 12, 60)
+    ) AS item (set_order, exercise_name, planned_sets, planned_reps, planned_interval_seconds)
+    JOIN exercises e ON e.name = item.exercise_name AND e.created_by_user_id IS NULL
+)
+INSERT INTO menu_shares (menu_id, share_code, is_public)
+SELECT id, 'TPL-PPL-PULL', true FROM menu;
+
+WITH menu AS (
+    INSERT INTO menus (user_id, name, description)
+    VALUES ('bulktrack', 'PPL - レッグ', '脚・臀部 (Push / Pull / Legs の分割)')
+    ON CONFLICT (user_id, name) DO NOTHING
+    RETURNING id
+), items AS (
+    INSERT INTO menu_items (menu_id, exercise_id, set_order, planned_sets, planned_reps, planned_interval_seconds)
+    SELECT menu.id, e.id, item.set_order, item.planned_sets, item.planned_reps, item.planned_interval_seconds
+    FROM menu
+    CROSS JOIN (VALUES
+        (1, 'スクワット', 4, 6, 180),
+        (2, 'ルーマニアンデッドリフト', 3, 10, 120),
+        (3, 'レッグプレス', 3, 12, 90),
+        (4, 'レッグカール', 3, 12, 60),
+        (5, 'カーフレイズ', 4, 15, 60)
+    ) AS item (set_order, exercise_name, planned_sets, planned_reps, planned_interval_seconds)
+    JOIN exercises e ON e.name = item.exercise_name AND e.created_by_user_id IS NULL
+)
+INSERT INTO menu_shares (menu_id, share_code, is_public)
+SELECT id, 'TPL-PPL-LEGS', true FROM menu;
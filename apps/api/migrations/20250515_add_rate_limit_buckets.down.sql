@@ -0,0 +1,3 @@
+-- Revert 20250515_add_rate_limit_buckets.
+
+DROP TABLE IF EXISTS rate_limit_buckets;
@@ -0,0 +1,12 @@
+-- Migration to add token buckets for rate limiting shared across instances.
+
+-- rate_limit_buckets: レート制限のトークンバケット (RATE_LIMIT_STORE=postgres の場合に使う)
+-- 失っても制限がリセットされるだけのため、WAL を書かない UNLOGGED テーブルにする
+CREATE UNLOGGED TABLE rate_limit_buckets (
+  key        TEXT PRIMARY KEY, -- ip:<address> / user:<user_id> / heavy:<user_id>
+  tokens     DOUBLE PRECISION NOT NULL,
+  allowed    BOOLEAN NOT NULL, -- 最後のリクエストを許可したか
+  updated_at TIMESTAMPTZ NOT NULL DEFAULT now()
+);
+
+CREATE INDEX idx_rate_limit_buckets_updated_at ON rate_limit_buckets (updated_at);
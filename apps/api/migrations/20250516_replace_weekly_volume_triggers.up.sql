@@ -0,0 +1,202 @@
+-- Migration to replace the row-level weekly_volumes triggers with a dirty-weeks queue.
+-- The statement-level triggers only queue the affected user-weeks; the API aggregates them in Go.
+
+DROP TRIGGER IF EXISTS after_set_insert ON sets;
+DROP TRIGGER IF EXISTS after_set_delete ON sets;
+DROP TRIGGER IF EXISTS after_set_update ON sets;
+DROP TRIGGER IF EXISTS after_body_weight_change ON body_measurements;
+DROP FUNCTION IF EXISTS update_weekly_volume();
+DROP FUNCTION IF EXISTS recalculate_weekly_volume_after_delete();
+DROP FUNCTION IF EXISTS recalculate_weekly_volume_after_update();
+DROP FUNCTION IF EXISTS recalculate_weekly_volumes_after_body_weight_change();
+
+-- weekly_volume_dirty_weeks: queue of user-weeks whose weekly_volumes must be aggregated again
+-- Written by the statement-level triggers below in the same transaction as the set / workout / body weight changes
+-- and consumed by the aggregation worker of the API (a user-week is queued once however often it changes)
+CREATE TABLE weekly_volume_dirty_weeks (
+    user_id TEXT NOT NULL,
+    week_start_date DATE NOT NULL, -- Monday 00:00 JST of the week
+    marked_at TIMESTAMPTZ NOT NULL DEFAULT now(), -- When the week was first queued
+    PRIMARY KEY (user_id, week_start_date)
+);
+
+CREATE INDEX idx_weekly_volume_dirty_weeks_marked_at ON weekly_volume_dirty_weeks (marked_at);
+
+-- Queue the weeks of the workouts of inserted, updated and deleted sets (once per statement)
+CREATE OR REPLACE FUNCTION queue_weekly_volumes_for_sets()
+RETURNS TRIGGER AS $$
+BEGIN
+    -- Bulk imports and account deletion purges recalculate (or delete) the affected weeks themselves
+    -- (enabled for the transaction with set_config('bulktrack.skip_weekly_volume_trigger', 'on', true))
+    IF current_setting('bulktrack.skip_weekly_volume_trigger', true) = 'on' THEN
+        RETURN NULL;
+    END IF;
+
+    IF TG_OP IN ('INSERT', 'UPDATE') THEN
+        INSERT INTO weekly_volume_dirty_weeks (user_id, week_start_date)
+        SELECT DISTINCT w.user_id, get_jst_week_start(w.started_at)
+        FROM new_sets s
+        JOIN workouts w ON w.id = s.workout_id
+        WHERE w.started_at IS NOT NULL
+        ON CONFLICT (user_id, week_start_date) DO NOTHING;
+    END IF;
+
+    -- Sets deleted by the cascade of a workout deletion have no workout here (the workout trigger queues the week)
+    IF TG_OP IN ('UPDATE', 'DELETE') THEN
+        INSERT INTO weekly_volume_dirty_weeks (user_id, week_start_date)
+        SELECT DISTINCT w.user_id, get_jst_week_start(w.started_at)
+        FROM old_sets s
+        JOIN workouts w ON w.id = s.workout_id
+        WHERE w.started_at IS NOT NULL
+        ON CONFLICT (user_id, week_start_date) DO NOTHING;
+    END IF;
+
+    RETURN NULL;
+END;
+$$ LANGUAGE plpgsql;
+
+CREATE TRIGGER queue_weekly_volumes_after_set_insert
+AFTER INSERT ON sets
+REFERENCING NEW TABLE AS new_sets
+FOR EACH STATEMENT
+EXECUTE FUNCTION queue_weekly_volumes_for_sets();
+
+CREATE TRIGGER queue_weekly_volumes_after_set_update
+AFTER UPDATE ON sets
+REFERENCING OLD TABLE AS old_sets NEW TABLE AS new_sets
+FOR EACH STATEMENT
+EXECUTE FUNCTION queue_weekly_volumes_for_sets();
+
+CREATE TRIGGER queue_weekly_volumes_after_set_delete
+AFTER DELETE ON sets
+REFERENCING OLD TABLE AS old_sets
+FOR EACH STATEMENT
+EXECUTE FUNCTION queue_weekly_volumes_for_sets();
+
+-- Queue the old and new weeks of workouts whose start time or user changed, and the weeks of deleted workouts
+CREATE OR REPLACE FUNCTION queue_weekly_volumes_for_workouts()
+RETURNS TRIGGER AS $$
+BEGIN
+    IF current_setting('bulktrack.skip_weekly_volume_trigger', true) = 'on' THEN
+        RETURN NULL;
+    END IF;
+
+    IF TG_OP = 'UPDATE' THEN
+        INSERT INTO weekly_volume_dirty_weeks (user_id, week_start_date)
+        SELECT weeks.user_id, weeks.week_start_date
+        FROM (
+            SELECT o.user_id, get_jst_week_start(o.started_at) AS week_start_date
+            FROM old_workouts o
+            JOIN new_workouts n ON n.id = o.id
+            WHERE (o.user_id, o.started_at) IS DISTINCT FROM (n.user_id, n.started_at)
+            UNION
+            SELECT n.user_id, get_jst_week_start(n.started_at)
+            FROM old_workouts o
+            JOIN new_workouts n ON n.id = o.id
+            WHERE (o.user_id, o.started_at) IS DISTINCT FROM (n.user_id, n.started_at)
+        ) AS weeks
+        WHERE weeks.week_start_date IS NOT NULL
+        ON CONFLICT (user_id, week_start_date) DO NOTHING;
+    ELSE
+        INSERT INTO weekly_volume_dirty_weeks (user_id, week_start_date)
+        SELECT DISTINCT o.user_id, get_jst_week_start(o.started_at)
+        FROM old_workouts o
+        WHERE o.started_at IS NOT NULL
+        ON CONFLICT (user_id, week_start_date) DO NOTHING;
+    END IF;
+
+    RETURN NULL;
+END;
+$$ LANGUAGE plpgsql;
+
+CREATE TRIGGER queue_weekly_volumes_after_workout_update
+AFTER UPDATE ON workouts
+REFERENCING OLD TABLE AS old_workouts NEW TABLE AS new_workouts
+FOR EACH STATEMENT
+EXECUTE FUNCTION queue_weekly_volumes_for_workouts();
+
+CREATE TRIGGER queue_weekly_volumes_after_workout_delete
+AFTER DELETE ON workouts
+REFERENCING OLD TABLE AS old_workouts
+FOR EACH STATEMENT
+EXECUTE FUNCTION queue_weekly_volumes_for_workouts();
+
+-- Queue all weeks of users whose body weight changed
+-- (effective load of bodyweight / assisted exercises depends on the body weight)
+CREATE OR REPLACE FUNCTION queue_weekly_volumes_for_body_weights()
+RETURNS TRIGGER AS $$
+BEGIN
+    IF current_setting('bulktrack.skip_weekly_volume_trigger', true) = 'on' THEN
+        RETURN NULL;
+    END IF;
+
+    IF TG_OP IN ('INSERT', 'UPDATE') THEN
+        INSERT INTO weekly_volume_dirty_weeks (user_id, week_start_date)
+        SELECT DISTINCT w.user_id, get_jst_week_start(w.started_at)
+        FROM workouts w
+        WHERE w.started_at IS NOT NULL
+        AND w.user_id IN (SELECT m.user_id FROM new_measurements m WHERE m.metric_code = 'body_weight')
+        ON CONFLICT (user_id, week_start_date) DO NOTHING;
+    END IF;
+
+    IF TG_OP IN ('UPDATE', 'DELETE') THEN
+        INSERT INTO weekly_volume_dirty_weeks (user_id, week_start_date)
+        SELECT DISTINCT w.user_id, get_jst_week_start(w.started_at)
+        FROM workouts w
+        WHERE w.started_at IS NOT NULL
+        AND w.user_id IN (SELECT m.user_id FROM old_measurements m WHERE m.metric_code = 'body_weight')
+        ON CONFLICT (user_id, week_start_date) DO NOTHING;
+    END IF;
+
+    RETURN NULL;
+END;
+$$ LANGUAGE plpgsql;
+
+CREATE TRIGGER queue_weekly_volumes_after_body_measurement_insert
+AFTER INSERT ON body_measurements
+REFERENCING NEW TABLE AS new_measurements
+FOR EACH STATEMENT
+EXECUTE FUNCTION queue_weekly_volumes_for_body_weights();
+
+CREATE TRIGGER queue_weekly_volumes_after_body_measurement_update
+AFTER UPDATE ON body_measurements
+REFERENCING OLD TABLE AS old_measurements NEW TABLE AS new_measurements
+FOR EACH STATEMENT
+EXECUTE FUNCTION queue_weekly_volumes_for_body_weights();
+
+CREATE TRIGGER queue_weekly_volumes_after_body_measurement_delete
+AFTER DELETE ON body_measurements
+REFERENCING OLD TABLE AS old_measurements
+FOR EACH STATEMENT
+EXECUTE FUNCTION queue_weekly_volumes_for_body_weights();
+
+CREATE OR REPLACE FUNCTION populate_weekly_volumes() 
+RETURNS void AS $$
+BEGIN
+    -- Clear existing data
+    DELETE FROM weekly_volumes;
+    
+    -- Insert aggregated data for all weeks
+    INSERT INTO weekly_volumes (
+        user_id,
+        week_start_date,
+        total_volume,
+        est_one_rm,
+        exercise_count,
+        set_count
+    )
+    SELECT 
+        w.user_id,
+        get_jst_week_start(w.started_at) AS week_start_date,
+        SUM(set_effective_load_kg(w.user_id, s.exercise_id, s.weight_kg, w.started_at) * s.reps) AS total_volume,
+        -- Epley formula multiplied before dividing so that the rounded value matches the aggregation in Go
+        MAX(set_effective_load_kg(w.user_id, s.exercise_id, s.weight_kg, w.started_at) * (30 + s.reps) / 30.0) AS est_one_rm,
+        COUNT(DISTINCT s.exercise_id) AS exercise_count,
+        COUNT(s.id) AS set_count
+    FROM workouts w
+    JOIN sets s ON w.id = s.workout_id
+    WHERE s.set_type <> 'warmup'
+    AND is_strength_exercise(s.exercise_id)
+    GROUP BY w.user_id, week_start_date;
+END;
+$$ LANGUAGE plpgsql;
@@ -0,0 +1,4 @@
+-- Revert 20250517_add_jobs.
+
+DROP TABLE IF EXISTS job_schedules;
+DROP TABLE IF EXISTS jobs;
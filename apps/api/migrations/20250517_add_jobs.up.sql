@@ -0,0 +1,33 @@
+-- Migration to add the background job queue and the schedules of periodic jobs.
+
+-- jobs: バックグラウンドジョブのキュー (各インスタンスのジョブランナーが FOR UPDATE SKIP LOCKED で取り出す)
+-- 完了したジョブは 7 日、失敗したジョブは 30 日で削除する
+CREATE TABLE jobs (
+  id           BIGSERIAL PRIMARY KEY,
+  kind         TEXT NOT NULL, -- export.archive など (ジョブの種類ごとにハンドラーを登録する)
+  args         JSONB NOT NULL DEFAULT '{}',
+  state        TEXT NOT NULL DEFAULT 'available' CHECK (state IN ('available', 'running', 'completed', 'failed')),
+  attempt      INT  NOT NULL DEFAULT 0, -- 実行した回数 (実行中の回を含む)
+  max_attempts INT  NOT NULL CHECK (max_attempts > 0),
+  run_at       TIMESTAMPTZ NOT NULL DEFAULT now(), -- この日時以降に実行する (再試行の場合は次の実行日時)
+  unique_key   TEXT, -- 同じキーの未完了 (available / running) のジョブは1件のみ
+  last_error   TEXT,
+  locked_by    TEXT, -- 実行中のインスタンス
+  locked_at    TIMESTAMPTZ,
+  created_at   TIMESTAMPTZ NOT NULL DEFAULT now(),
+  updated_at   TIMESTAMPTZ NOT NULL DEFAULT now(),
+  completed_at TIMESTAMPTZ,
+  failed_at    TIMESTAMPTZ
+);
+
+CREATE INDEX idx_jobs_available_run_at ON jobs (run_at, id) WHERE state = 'available';
+CREATE INDEX idx_jobs_running_locked_at ON jobs (locked_at) WHERE state = 'running';
+CREATE UNIQUE INDEX idx_jobs_unique_key ON jobs (unique_key) WHERE state IN ('available', 'running');
+
+-- job_schedules: 定期実行のジョブの次の実行日時 (複数のインスタンスのうち1つのみがジョブを追加する)
+CREATE TABLE job_schedules (
+  name        TEXT PRIMARY KEY,
+  next_run_at TIMESTAMPTZ NOT NULL,
+  last_run_at TIMESTAMPTZ,
+  updated_at  TIMESTAMPTZ NOT NULL DEFAULT now()
+);
@@ -0,0 +1,12 @@
+-- Revert 20250518_store_export_archives_as_large_objects.
+
+DROP TRIGGER IF EXISTS unlink_export_job_archive_after_update ON export_jobs;
+DROP TRIGGER IF EXISTS unlink_export_job_archive_after_delete ON export_jobs;
+DROP FUNCTION IF EXISTS unlink_export_job_archive();
+
+ALTER TABLE export_jobs ADD COLUMN IF NOT EXISTS archive BYTEA; -- 完了したジョブの ZIP アーカイブ
+
+UPDATE export_jobs SET archive = lo_get(archive_oid) WHERE archive_oid IS NOT NULL;
+SELECT lo_unlink(archive_oid) FROM export_jobs WHERE archive_oid IS NOT NULL;
+
+ALTER TABLE export_jobs DROP COLUMN IF EXISTS archive_oid;
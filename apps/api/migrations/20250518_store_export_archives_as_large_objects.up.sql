@@ -0,0 +1,31 @@
+-- Migration to store the ZIP archives of export jobs as large objects instead of BYTEA.
+-- The export job streams the archive into a large object and the download streams it back,
+-- so neither the job nor the API has to hold a whole archive in memory.
+
+ALTER TABLE export_jobs ADD COLUMN IF NOT EXISTS archive_oid OID; -- 完了したジョブの ZIP アーカイブ (ラージオブジェクト)
+
+-- Move the archives of completed jobs into large objects
+UPDATE export_jobs SET archive_oid = lo_from_bytea(0, archive) WHERE archive IS NOT NULL;
+
+ALTER TABLE export_jobs DROP COLUMN IF EXISTS archive;
+
+-- Unlink the large object when its job is deleted (expiry or account deletion) or its archive is replaced
+CREATE OR REPLACE FUNCTION unlink_export_job_archive()
+RETURNS TRIGGER AS $$
+BEGIN
+    IF OLD.archive_oid IS NOT NULL AND (TG_OP = 'DELETE' OR NEW.archive_oid IS DISTINCT FROM OLD.archive_oid) THEN
+        PERFORM lo_unlink(OLD.archive_oid);
+    END IF;
+    RETURN NULL;
+END;
+$$ LANGUAGE plpgsql;
+
+CREATE TRIGGER unlink_export_job_archive_after_update
+AFTER UPDATE OF archive_oid ON export_jobs
+FOR EACH ROW
+EXECUTE FUNCTION unlink_export_job_archive();
+
+CREATE TRIGGER unlink_export_job_archive_after_delete
+AFTER DELETE ON export_jobs
+FOR EACH ROW
+EXECUTE FUNCTION unlink_export_job_archive();
@@ -0,0 +1,61 @@
+-- Revert 20250519_allow_null_weight_for_bodyweight_sets.
+
+-- NULL and 0 give the same effective load, so the weekly volumes do not need to be recalculated
+SELECT set_config('bulktrack.skip_weekly_volume_trigger', 'on', true);
+UPDATE sets SET weight_kg = 0 WHERE weight_kg IS NULL;
+SELECT set_config('bulktrack.skip_weekly_volume_trigger', 'off', true);
+
+ALTER TABLE sets ALTER COLUMN weight_kg SET NOT NULL;
+
+CREATE OR REPLACE FUNCTION set_effective_load_kg(p_user_id TEXT, p_exercise_id UUID, p_weight_kg NUMERIC, p_started_at TIMESTAMPTZ)
+RETURNS NUMERIC AS $$
+DECLARE
+    exercise_load_type TEXT;
+    body_weight NUMERIC;
+BEGIN
+    SELECT load_type INTO exercise_load_type FROM exercises WHERE id = p_exercise_id;
+
+    IF exercise_load_type IS NULL OR exercise_load_type = 'external' THEN
+        RETURN p_weight_kg;
+    END IF;
+
+    body_weight := get_body_weight_on(p_user_id, (p_started_at AT TIME ZONE 'Asia/Tokyo')::DATE);
+
+    RETURN CASE exercise_load_type
+        WHEN 'bodyweight' THEN COALESCE(body_weight, p_weight_kg)
+        WHEN 'bodyweight_plus' THEN COALESCE(body_weight, 0) + p_weight_kg
+        WHEN 'assisted' THEN GREATEST(COALESCE(body_weight, 0) - p_weight_kg, 0)
+    END;
+END;
+$$ LANGUAGE plpgsql STABLE;
+
+CREATE OR REPLACE FUNCTION queue_weekly_volumes_for_body_weights()
+RETURNS TRIGGER AS $$
+BEGIN
+    IF current_setting('bulktrack.skip_weekly_volume_trigger', true) = 'on' THEN
+        RETURN NULL;
+    END IF;
+
+    IF TG_OP IN ('INSERT', 'UPDATE') THEN
+        INSERT INTO weekly_volume_dirty_weeks (user_id, week_start_date)
+        SELECT DISTINCT w.user_id, get_jst_week_start(w.started_at)
+        FROM workouts w
+        WHERE w.started_at IS NOT NULL
+        AND w.user_id IN (SELECT m.user_id FROM new_measurements m WHERE m.metric_code = 'body_weight')
+        ON CONFLICT (user_id, week_start_date) DO NOTHING;
+    END IF;
+
+    IF TG_OP IN ('UPDATE', 'DELETE') THEN
+        INSERT INTO weekly_volume_dirty_weeks (user_id, week_start_date)
+        SELECT DISTINCT w.user_id, get_jst_week_start(w.started_at)
+        FROM workouts w
+        WHERE w.started_at IS NOT NULL
+        AND w.user_id IN (SELECT m.user_id FROM old_measurements m WHERE m.metric_code = 'body_weight')
+        ON CONFLICT (user_id, week_start_date) DO NOTHING;
+    END IF;
+
+    RETURN NULL;
+END;
+$$ LANGUAGE plpgsql;
+
+DROP FUNCTION IF EXISTS queue_weekly_volumes_for_body_weight(TEXT, UUID, TIMESTAMPTZ);
@@ -0,0 +1,96 @@
+-- Migration to allow sets of bodyweight exercises to be recorded without a weight
+-- and to queue only the weeks whose effective load depends on a changed body weight.
+--   1. sets.weight_kg becomes nullable. NULL means no added / assisting weight and is only
+--      accepted by the API for non-external load types (external sets still require a weight)
+--   2. set_effective_load_kg treats a NULL weight as 0
+--   3. Body weight changes queue the weeks of the workouts that resolve to the changed record
+--      in get_body_weight_on, instead of every week the user has trained
+
+ALTER TABLE sets ALTER COLUMN weight_kg DROP NOT NULL;
+
+-- Calculate the effective load of a set from the exercise's load type and the body weight on the workout date
+--   external:        weight_kg
+--   bodyweight:      body weight (weight_kg when no body weight is recorded)
+--   bodyweight_plus: body weight + weight_kg
+--   assisted:        body weight - weight_kg (not below 0)
+-- A NULL weight_kg (bodyweight exercises recorded without a weight) counts as 0
+CREATE OR REPLACE FUNCTION set_effective_load_kg(p_user_id TEXT, p_exercise_id UUID, p_weight_kg NUMERIC, p_started_at TIMESTAMPTZ)
+RETURNS NUMERIC AS $$
+DECLARE
+    exercise_load_type TEXT;
+    body_weight NUMERIC;
+BEGIN
+    SELECT load_type INTO exercise_load_type FROM exercises WHERE id = p_exercise_id;
+
+    IF exercise_load_type IS NULL OR exercise_load_type = 'external' THEN
+        RETURN COALESCE(p_weight_kg, 0);
+    END IF;
+
+    body_weight := get_body_weight_on(p_user_id, (p_started_at AT TIME ZONE 'Asia/Tokyo')::DATE);
+
+    RETURN CASE exercise_load_type
+        WHEN 'bodyweight' THEN COALESCE(body_weight, p_weight_kg, 0)
+        WHEN 'bodyweight_plus' THEN COALESCE(body_weight, 0) + COALESCE(p_weight_kg, 0)
+        WHEN 'assisted' THEN GREATEST(COALESCE(body_weight, 0) - COALESCE(p_weight_kg, 0), 0)
+    END;
+END;
+$$ LANGUAGE plpgsql STABLE;
+
+-- Queue the weeks of the user's workouts whose body weight (get_body_weight_on) resolves to the given record.
+-- Called for both the old and the new version of a changed record, so the weeks that switch from or to it are covered.
+-- The other records are read from the current table; records with the same measured_at are treated as competing
+-- for the same dates and queue those weeks as well.
+CREATE OR REPLACE FUNCTION queue_weekly_volumes_for_body_weight(p_user_id TEXT, p_measurement_id UUID, p_measured_at TIMESTAMPTZ)
+RETURNS void AS $$
+    INSERT INTO weekly_volume_dirty_weeks (user_id, week_start_date)
+    SELECT DISTINCT w.user_id, get_jst_week_start(w.started_at)
+    FROM workouts w
+    WHERE w.user_id = p_user_id
+    AND w.started_at IS NOT NULL
+    AND CASE
+        -- The record is on or before the workout date: used unless a later record is also on or before the date
+        WHEN (p_measured_at AT TIME ZONE 'Asia/Tokyo')::DATE <= (w.started_at AT TIME ZONE 'Asia/Tokyo')::DATE THEN
+            NOT EXISTS (
+                SELECT 1 FROM body_measurements m
+                WHERE m.user_id = p_user_id
+                AND m.metric_code = 'body_weight'
+                AND m.id <> p_measurement_id
+                AND m.measured_at > p_measured_at
+                AND (m.measured_at AT TIME ZONE 'Asia/Tokyo')::DATE <= (w.started_at AT TIME ZONE 'Asia/Tokyo')::DATE
+            )
+        -- The record is after the workout date: used only as the earliest record when none is on or before the date
+        ELSE
+            NOT EXISTS (
+                SELECT 1 FROM body_measurements m
+                WHERE m.user_id = p_user_id
+                AND m.metric_code = 'body_weight'
+                AND m.id <> p_measurement_id
+                AND (m.measured_at < p_measured_at
+                    OR (m.measured_at AT TIME ZONE 'Asia/Tokyo')::DATE <= (w.started_at AT TIME ZONE 'Asia/Tokyo')::DATE)
+            )
+    END
+    ON CONFLICT (user_id, week_start_date) DO NOTHING;
+$$ LANGUAGE sql;
+
+CREATE OR REPLACE FUNCTION queue_weekly_volumes_for_body_weights()
+RETURNS TRIGGER AS $$
+BEGIN
+    IF current_setting('bulktrack.skip_weekly_volume_trigger', true) = 'on' THEN
+        RETURN NULL;
+    END IF;
+
+    IF TG_OP IN ('INSERT', 'UPDATE') THEN
+        PERFORM queue_weekly_volumes_for_body_weight(m.user_id, m.id, m.measured_at)
+        FROM new_measurements m
+        WHERE m.metric_code = 'body_weight';
+    END IF;
+
+    IF TG_OP IN ('UPDATE', 'DELETE') THEN
+        PERFORM queue_weekly_volumes_for_body_weight(m.user_id, m.id, m.measured_at)
+        FROM old_measurements m
+        WHERE m.metric_code = 'body_weight';
+    END IF;
+
+    RETURN NULL;
+END;
+$$ LANGUAGE plpgsql;
@@ -0,0 +1,4 @@
+-- Revert 20250520_queue_webhook_event_retry_jobs.
+
+-- Events of the removed jobs keep their status and next_attempt_at, so the old retry loop picks them up again
+DELETE FROM jobs WHERE kind = 'clerk_webhook.retry' AND state = 'available';
@@ -0,0 +1,14 @@
+-- Migration to move the retries of failed Clerk webhook events to the job queue.
+-- The API no longer polls webhook_events for retries: a failed event enqueues a clerk_webhook.retry job instead.
+-- Events that were waiting for a retry (or left unprocessed by a restart) get a job here, so they are still retried.
+
+INSERT INTO jobs (kind, args, max_attempts, run_at, unique_key)
+SELECT
+    'clerk_webhook.retry',
+    jsonb_build_object('webhook_event_id', id),
+    8 - attempts, -- webhookMaxAttempts minus the attempts already made
+    COALESCE(next_attempt_at, now()),
+    'webhook:' || id
+FROM webhook_events
+WHERE status IN ('received', 'failed') AND attempts < 8
+ON CONFLICT DO NOTHING;
@@ -0,0 +1,12 @@
+// Package migrations はデータベースのマイグレーション (SQL) をサーバーのバイナリに埋め込む
+//
+// ファイル名は <バージョン>_<名前>.up.sql / <バージョン>_<名前>.down.sql (バージョンは YYYYMMDD)。
+// 適用後のスキーマは internal/infrastructure/db/schema.sql と一致させる (internal/migrate のテストで確認する)
+package migrations
+
+import "embed"
+
+// FS は埋め込んだマイグレーションのファイル
+//
+//go:embed *.sql
+var FS embed.FS
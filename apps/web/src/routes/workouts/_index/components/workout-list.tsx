@@ -1,5 +1,7 @@
-import { Link } from "react-router";
-import { useLoaderData } from "react-router";
+import { useEffect, useState } from "react";
+import { Link, useFetcher, useLoaderData } from "react-router";
+
+import { Button } from "~/components/ui/button";
 
 type Workout = {
   id: string;
@@ -7,8 +9,37 @@ type Workout = {
   date: string;
 };
 
+type WorkoutPage = {
+  workouts: Workout[];
+  nextCursor: string | null;
+};
+
 export function WorkoutList() {
-  const { workouts } = useLoaderData() as { workouts: Workout[] };
+  const firstPage = useLoaderData() as WorkoutPage;
+  const fetcher = useFetcher<WorkoutPage>();
+  // 「さらに読み込む」で取得した2ページ目以降
+  const [morePages, setMorePages] = useState<WorkoutPage[]>([]);
+
+  // ローダーの再実行で1ページ目が変わった場合は読み込み直す
+  useEffect(() => {
+    setMorePages([]);
+  }, [firstPage]);
+
+  useEffect(() => {
+    if (fetcher.state === "idle" && fetcher.data) {
+      const page = fetcher.data;
+      setMorePages((pages) => [...pages, page]);
+    }
+  }, [fetcher.state, fetcher.data]);
+
+  const workouts = [firstPage, ...morePages].flatMap((page) => page.workouts);
+  const nextCursor =
+    morePages.length > 0 ? morePages[morePages.length - 1].nextCursor : firstPage.nextCursor;
+
+  // index ルートのローダーを呼ぶため ?index を付ける
+  const loadMore = (cursor: string) => {
+    fetcher.load(`/workouts?index&cursor=${encodeURIComponent(cursor)}`);
+  };
 
   if (workouts.length === 0) {
     return (
@@ -27,17 +58,30 @@ export function WorkoutList() {
   }
 
   return (
-    <div className="grid grid-cols-1 md:grid-cols-2 lg:grid-cols-3 gap-4">
-      {workouts.map((workout) => (
-        <Link
-          key={workout.id}
-          to={`/workouts/${workout.id}`}
-          className="block p-6 bg-card rounded-lg border hover:shadow-md transition-shadow duration-200"
-        >
-          <h3 className="text-lg font-semibold mb-2">{workout.title}</h3>
-          <p className="text-sm text-muted-foreground">{workout.date}</p>
-        </Link>
-      ))}
+    <div>
+      <div className="grid grid-cols-1 md:grid-cols-2 lg:grid-cols-3 gap-4">
+        {workouts.map((workout) => (
+          <Link
+            key={workout.id}
+            to={`/workouts/${workout.id}`}
+            className="block p-6 bg-card rounded-lg border hover:shadow-md transition-shadow duration-200"
+          >
+            <h3 className="text-lg font-semibold mb-2">{workout.title}</h3>
+            <p className="text-sm text-muted-foreground">{workout.date}</p>
+          </Link>
+        ))}
+      </div>
+      {nextCursor && (
+        <div className="mt-6 flex justify-center">
+          <Button
+            variant="outline"
+            disabled={fetcher.state !== "idle"}
+            onClick={() => loadMore(nextCursor)}
+          >
+            {fetcher.state !== "idle" ? "読み込み中..." : "さらに読み込む"}
+          </Button>
+        </div>
+      )}
     </div>
   );
 }
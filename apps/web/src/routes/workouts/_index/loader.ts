@@ -14,8 +14,10 @@ export async function loader(args: Route.LoaderArgs) {
   }
 
   try {
-    // APIからワークアウト一覧を取得
-    const response = await apiFetch(args, "/workouts");
+    // APIからワークアウト一覧を1ページ分取得 (cursor は前ページの next_cursor。「さらに読み込む」で指定する)
+    const cursor = new URL(args.request.url).searchParams.get("cursor");
+    const apiPath = cursor ? `/workouts?cursor=${encodeURIComponent(cursor)}` : "/workouts";
+    const response = await apiFetch(args, apiPath);
 
     if (!response.ok) {
       console.error(`Failed to fetch workouts: ${response.status} ${response.statusText}`);
@@ -33,16 +35,21 @@ export async function loader(args: Route.LoaderArgs) {
 
     // APIレスポンスを取得して検証
     const data = await response.json();
-    const workoutsResult = z.array(WorkoutApiSchema).safeParse(data);
+    const workoutsResult = z
+      .object({
+        data: z.array(WorkoutApiSchema),
+        next_cursor: z.string().nullable(),
+      })
+      .safeParse(data);
 
     if (!workoutsResult.success) {
       console.error("API response validation failed:", workoutsResult.error);
       throw new APIError("APIレスポンスの形式が正しくありません");
     }
 
-    // フォーマット済みのワークアウト一覧を返す
-    const formattedWorkouts = formatWorkoutsFromApi(workoutsResult.data);
-    return { workouts: formattedWorkouts };
+    // フォーマット済みのワークアウト一覧と次ページのカーソル (最後のページは null) を返す
+    const formattedWorkouts = formatWorkoutsFromApi(workoutsResult.data.data);
+    return { workouts: formattedWorkouts, nextCursor: workoutsResult.data.next_cursor };
   } catch (error) {
     console.error("Error fetching workouts:", error);
 